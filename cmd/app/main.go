@@ -8,14 +8,18 @@ import (
 	"os/signal"
 	"syscall"
 	"time"
+	_ "time/tzdata" // часовой пояс операционного дня доступен и в образах без tzdata
 
 	"github.com/vterdunov/learn-bank-app/internal/config"
 	"github.com/vterdunov/learn-bank-app/internal/database"
 	"github.com/vterdunov/learn-bank-app/internal/domain"
+	"github.com/vterdunov/learn-bank-app/internal/gateway"
+	"github.com/vterdunov/learn-bank-app/internal/middleware"
 	"github.com/vterdunov/learn-bank-app/internal/repository"
 	"github.com/vterdunov/learn-bank-app/internal/router"
 	"github.com/vterdunov/learn-bank-app/internal/service"
 	"github.com/vterdunov/learn-bank-app/internal/utils"
+	"github.com/vterdunov/learn-bank-app/templates"
 )
 
 func main() {
@@ -32,13 +36,31 @@ func main() {
 		os.Exit(1)
 	}
 
-	// Инициализация JWT
-	utils.InitJWT(cfg.JWT.Secret)
-
 	// Создание контекста с отменой
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
+	// Инициализация JWT
+	jwtKeys := utils.NewHMACKeyRing(cfg.JWT.Secret)
+	if cfg.JWT.KeysDir != "" {
+		// JWT_SECRET проверяет старые HS256 токены, только если это явно включено на время миграции
+		legacySecret := ""
+		if cfg.JWT.LegacyHMAC {
+			legacySecret = cfg.JWT.Secret
+		}
+		jwtKeys, err = utils.LoadKeyRing(cfg.JWT.KeysDir, cfg.JWT.SigningKID, legacySecret, cfg.JWT.LegacyHMACUntil)
+		if err != nil {
+			slog.Error("Failed to load JWT keys", slog.String("error", err.Error()))
+			os.Exit(1)
+		}
+
+		// Периодически перечитываем ключи для ротации без перезапуска
+		if cfg.JWT.KeysReloadInterval > 0 {
+			go reloadJWTKeys(ctx, jwtKeys, cfg.JWT.KeysReloadInterval)
+		}
+	}
+	utils.InitJWTWithKeyRing(jwtKeys)
+
 	// Подключение к базе данных
 	dbCfg := database.Config{
 		Host:     cfg.Database.Host,
@@ -69,48 +91,247 @@ func main() {
 	transactionRepo := repository.NewTransactionRepository(db.Pool)
 	creditRepo := repository.NewCreditRepository(db.Pool)
 	paymentScheduleRepo := repository.NewPaymentScheduleRepository(db.Pool)
+	auditRepo := repository.NewAuditRepository(db.Pool)
+	outboxRepo := repository.NewOutboxRepository(db.Pool)
+	notificationRepo := repository.NewNotificationRepository(db.Pool)
+	jobRepo := repository.NewJobRepository(db.Pool)
+	eodRepo := repository.NewEndOfDayRepository(db.Pool)
+	depositRepo := repository.NewDepositRepository(db.Pool)
+	standingOrderRepo := repository.NewStandingOrderRepository(db.Pool)
+	paymentAliasRepo := repository.NewPaymentAliasRepository(db.Pool)
+	holdRepo := repository.NewHoldRepository(db.Pool)
+	merchantRepo := repository.NewMerchantRepository(db.Pool)
+	cashbackRepo := repository.NewCashbackRepository(db.Pool)
+	cardRevealRepo := repository.NewCardRevealRepository(db.Pool)
+	accountHolderRepo := repository.NewAccountHolderRepository(db.Pool)
+	fraudRepo := repository.NewFraudRepository(db.Pool)
+	sanctionsRepo := repository.NewSanctionsRepository(db.Pool)
+	kycRepo := repository.NewKYCRepository(db.Pool)
+	gatewayMessageRepo := repository.NewGatewayMessageRepository(db.Pool)
+	txManager := repository.NewTxManager(db.Pool)
 
 	// Инициализация внешних сервисов
 	cbrService := service.NewCBRService(cfg, lg)
 
 	// Инициализация access control
-	accessControl := domain.NewAccessControlDomain(accountRepo, cardRepo, creditRepo)
+	accessControl := domain.NewAccessControlDomain(accountRepo, cardRepo, creditRepo, accountHolderRepo)
+
+	// Инициализация журнала аудита
+	auditService := service.NewAuditService(auditRepo, lg)
+
+	// Инициализация уведомлений (письма, webhook и SMS ставятся в outbox)
+	emailTemplates, err := service.LoadEmailTemplates(templates.Email, cfg.Notify.EmailTemplateVersion)
+	if err != nil {
+		slog.Error("Failed to load email templates", slog.String("error", err.Error()))
+		os.Exit(1)
+	}
+	emailService := service.NewEmailService(cfg, outboxRepo, emailTemplates, lg)
+	notificationService := service.NewNotificationService(cfg, notificationRepo, outboxRepo, userRepo, emailService, lg)
 
 	// Инициализация основных сервисов
-	authService := service.NewAuthService(userRepo, lg)
-	accountService := service.NewAccountService(accountRepo, transactionRepo, accessControl, lg)
-	cardService := service.NewCardService(cardRepo, accountRepo, transactionRepo, lg)
-	creditService := service.NewCreditService(creditRepo, paymentScheduleRepo, accountRepo, transactionRepo, cbrService, lg)
+	sanctionsService, err := service.NewSanctionsService(cfg, sanctionsRepo, userRepo, auditService, lg)
+	if err != nil {
+		slog.Error("Failed to init sanctions service", slog.String("error", err.Error()))
+		os.Exit(1)
+	}
+	authService := service.NewAuthService(userRepo, sanctionsService, auditService, lg)
+	cashbackService, err := service.NewCashbackService(cfg, cashbackRepo, accessControl, txManager, auditService, utils.SystemClock{}, lg)
+	if err != nil {
+		slog.Error("Failed to init cashback service", slog.String("error", err.Error()))
+		os.Exit(1)
+	}
+	fraudService, err := service.NewFraudService(cfg, fraudRepo, transactionRepo, auditService, utils.SystemClock{}, lg)
+	if err != nil {
+		slog.Error("Failed to init fraud service", slog.String("error", err.Error()))
+		os.Exit(1)
+	}
+	kycService, err := service.NewKYCService(cfg, kycRepo, transactionRepo, auditService, utils.SystemClock{}, lg)
+	if err != nil {
+		slog.Error("Failed to init KYC service", slog.String("error", err.Error()))
+		os.Exit(1)
+	}
+	cardService := service.NewCardService(cfg, cardRepo, accountRepo, transactionRepo, holdRepo, merchantRepo, accessControl, txManager, notificationService, cashbackService, fraudService, kycService, auditService, utils.SystemClock{}, lg)
+	accountService := service.NewAccountService(cfg, accountRepo, transactionRepo, cardRepo, creditRepo, accountHolderRepo, userRepo, accessControl, txManager, notificationService, cardService, fraudService, sanctionsService, kycService, auditService, lg)
+	recipientService := service.NewRecipientService(cfg, accountRepo, userRepo, paymentAliasRepo, transactionRepo, accessControl, accountService, auditService, utils.SystemClock{}, lg)
+	cardRevealService := service.NewCardRevealService(cfg, cardRepo, cardRevealRepo, userRepo, accessControl, cardService, txManager, auditService, utils.SystemClock{}, lg)
+	// Хеши номеров для поиска карты по реквизитам; карты, зашифрованные другим ключом, пропускаются
+	if filled, err := cardService.BackfillPANHashes(ctx); err != nil {
+		slog.Error("Failed to backfill card PAN hashes", slog.String("error", err.Error()))
+	} else if filled > 0 {
+		slog.Info("Card PAN hashes backfilled", slog.Int("cards", filled))
+	}
+	creditService := service.NewCreditService(creditRepo, paymentScheduleRepo, accountRepo, transactionRepo, txManager, cbrService, notificationService, auditService, lg)
+	depositService, err := service.NewDepositService(cfg, depositRepo, accountRepo, accessControl, txManager, cbrService, auditService, utils.SystemClock{}, lg)
+	if err != nil {
+		slog.Error("Failed to init deposit service", slog.String("error", err.Error()))
+		os.Exit(1)
+	}
+	standingOrderService, err := service.NewStandingOrderService(cfg, standingOrderRepo, accessControl, recipientService, notificationService, txManager, auditService, utils.SystemClock{}, lg)
+	if err != nil {
+		slog.Error("Failed to init standing order service", slog.String("error", err.Error()))
+		os.Exit(1)
+	}
+	merchantService, err := service.NewMerchantService(cfg, merchantRepo, accountRepo, auditService, utils.SystemClock{}, lg)
+	if err != nil {
+		slog.Error("Failed to init merchant service", slog.String("error", err.Error()))
+		os.Exit(1)
+	}
+	cardGatewayService := service.NewCardGatewayService(cardService, cardRepo, accountRepo, merchantRepo, gatewayMessageRepo, lg)
 	analyticsService := service.NewAnalyticsService(accountRepo, transactionRepo, creditRepo)
 
-	// Инициализация email сервиса для шедулера
-	emailService := service.NewEmailService(cfg, lg)
+	outboxService := service.NewOutboxService(outboxRepo, auditService, lg)
 
-	// Инициализация шедулера
-	scheduler := service.NewSchedulerService(cfg, creditRepo, paymentScheduleRepo, accountRepo, transactionRepo, userRepo, emailService, lg)
+	// Инициализация фоновых задач (выполняются только репликой-лидером)
+	scheduler := service.NewSchedulerService(cfg, creditRepo, paymentScheduleRepo, accountRepo, transactionRepo, txManager, notificationService, lg)
+	jobRunner := service.NewJobRunner(cfg, jobRepo, repository.NewAdvisoryLock(db.Pool, service.JobRunnerLockKey), auditService, lg)
+	if err := jobRunner.Register(service.JobDefinition{
+		Name:     service.JobOverduePayments,
+		Schedule: cfg.Scheduler.OverdueSchedule,
+		Run:      scheduler.ProcessOverduePayments,
+	}); err != nil {
+		slog.Error("Failed to register job", slog.String("error", err.Error()))
+		os.Exit(1)
+	}
+
+	endOfDay, err := service.NewEndOfDayService(cfg, eodRepo, depositRepo, txManager, utils.SystemClock{}, lg)
+	if err != nil {
+		slog.Error("Failed to init end of day service", slog.String("error", err.Error()))
+		os.Exit(1)
+	}
+	if err := jobRunner.Register(service.JobDefinition{
+		Name:     service.JobEndOfDay,
+		Schedule: cfg.EOD.Schedule,
+		Run:      endOfDay.RunEndOfDay,
+	}); err != nil {
+		slog.Error("Failed to register job", slog.String("error", err.Error()))
+		os.Exit(1)
+	}
+
+	if err := jobRunner.Register(service.JobDefinition{
+		Name:     service.JobStandingOrders,
+		Schedule: cfg.Standing.Schedule,
+		Run:      standingOrderService.ExecuteDue,
+	}); err != nil {
+		slog.Error("Failed to register job", slog.String("error", err.Error()))
+		os.Exit(1)
+	}
+
+	if err := jobRunner.Register(service.JobDefinition{
+		Name:     service.JobExpireCardHolds,
+		Schedule: cfg.Card.HoldExpirySchedule,
+		Run:      cardService.ExpireHolds,
+	}); err != nil {
+		slog.Error("Failed to register job", slog.String("error", err.Error()))
+		os.Exit(1)
+	}
+
+	if err := jobRunner.Register(service.JobDefinition{
+		Name:     service.JobMerchantSettlement,
+		Schedule: cfg.Merchant.SettlementSchedule,
+		Run:      merchantService.RunSettlement,
+	}); err != nil {
+		slog.Error("Failed to register job", slog.String("error", err.Error()))
+		os.Exit(1)
+	}
+
+	if err := jobRunner.Register(service.JobDefinition{
+		Name:     service.JobCashbackPayout,
+		Schedule: cfg.Cashback.PayoutSchedule,
+		Run:      cashbackService.PayoutCashback,
+	}); err != nil {
+		slog.Error("Failed to register job", slog.String("error", err.Error()))
+		os.Exit(1)
+	}
+
+	if err := jobRunner.Register(service.JobDefinition{
+		Name:     service.JobSanctionsRefresh,
+		Schedule: cfg.Sanctions.RefreshSchedule,
+		Run:      sanctionsService.RefreshLists,
+	}); err != nil {
+		slog.Error("Failed to register job", slog.String("error", err.Error()))
+		os.Exit(1)
+	}
+
+	// Инициализация диспетчера outbox
+	smsProvider, err := service.NewSMSProvider(cfg.Notify.SMSProvider, lg)
+	if err != nil {
+		slog.Error("Failed to init SMS provider", slog.String("error", err.Error()))
+		os.Exit(1)
+	}
+	outboxDispatcher := service.NewOutboxDispatcher(cfg, outboxRepo, map[string]service.ChannelSender{
+		domain.OutboxChannelEmail:   service.NewSMTPEmailSender(cfg, lg),
+		domain.OutboxChannelWebhook: service.NewWebhookSender(cfg, notificationRepo, lg),
+		domain.OutboxChannelSMS:     service.NewSMSSender(smsProvider),
+	}, lg)
+
+	// Инициализация ограничителя запросов: postgres нужен, когда запущено несколько экземпляров
+	var rateLimitStore middleware.RateLimitStore
+	switch cfg.RateLimit.Store {
+	case "memory":
+		rateLimitStore = middleware.NewMemoryRateLimitStore()
+	case "postgres":
+		rateLimitStore = repository.NewRateLimitRepository(db.Pool)
+	default:
+		slog.Error("Unknown rate limit store", slog.String("store", cfg.RateLimit.Store))
+		os.Exit(1)
+	}
+	rateLimiter := middleware.NewRateLimiter(cfg.RateLimit, cfg.Server.TrustedProxies, rateLimitStore, utils.SystemClock{}, lg)
 
 	// Инициализация роутера со всеми сервисами
 	routerConfig := router.Config{
-		Logger:    lg,
-		JWTSecret: cfg.JWT.Secret,
+		Logger:      lg,
+		JWTKeys:     jwtKeys,
+		Users:       userRepo,
+		RateLimiter: rateLimiter,
+		Server:      cfg.Server,
 		Services: &router.Services{
-			Auth:      authService,
-			Account:   accountService,
-			Card:      cardService,
-			Credit:    creditService,
-			Analytics: analyticsService,
-			CBR:       cbrService,
+			Auth:         authService,
+			Account:      accountService,
+			Recipient:    recipientService,
+			Card:         cardService,
+			Credit:       creditService,
+			Deposit:      depositService,
+			Standing:     standingOrderService,
+			Analytics:    analyticsService,
+			CBR:          cbrService,
+			Audit:        auditService,
+			Outbox:       outboxService,
+			Notification: notificationService,
+			Email:        emailService,
+			Jobs:         jobRunner,
+			Merchant:     merchantService,
+			Cashback:     cashbackService,
+			CardReveal:   cardRevealService,
+			Fraud:        fraudService,
+			Sanctions:    sanctionsService,
+			KYC:          kycService,
 		},
 	}
 
 	appRouter := router.New(routerConfig)
 
-	// Запуск шедулера
-	if err := scheduler.Start(ctx); err != nil {
-		slog.Error("Failed to start scheduler", slog.String("error", err.Error()))
+	// Запуск фоновых задач
+	if err := jobRunner.Start(ctx); err != nil {
+		slog.Error("Failed to start job runner", slog.String("error", err.Error()))
 		os.Exit(1)
 	}
-	slog.Info("Scheduler started")
+	slog.Info("Job runner started")
+
+	// Запуск диспетчера outbox
+	if err := outboxDispatcher.Start(ctx); err != nil {
+		slog.Error("Failed to start outbox dispatcher", slog.String("error", err.Error()))
+		os.Exit(1)
+	}
+	slog.Info("Outbox dispatcher started")
+
+	// Запуск шлюза карточных сообщений ISO 8583
+	gatewayServer := gateway.NewServer(cfg, cardGatewayService, lg)
+	if gatewayServer.Enabled() {
+		if err := gatewayServer.Start(ctx); err != nil {
+			slog.Error("Failed to start card gateway", slog.String("error", err.Error()))
+			os.Exit(1)
+		}
+	}
 
 	// Настройка HTTP сервера
 	server := &http.Server{
@@ -127,14 +348,28 @@ func main() {
 		signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
 		<-sigChan
 
-		slog.Info("Shutting down server and scheduler...")
+		slog.Info("Shutting down server and background jobs...")
 
 		shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), 30*time.Second)
 		defer shutdownCancel()
 
-		// Останавливаем шедулер
-		scheduler.Stop()
-		slog.Info("Scheduler stopped")
+		// Останавливаем фоновые задачи (дожидается выполняющихся запусков)
+		if err := jobRunner.Stop(shutdownCtx); err != nil {
+			slog.Error("Job runner shutdown error", slog.String("error", err.Error()))
+		}
+		slog.Info("Job runner stopped")
+
+		// Останавливаем диспетчер outbox (дожидается текущей пачки)
+		outboxDispatcher.Stop()
+		slog.Info("Outbox dispatcher stopped")
+
+		// Останавливаем шлюз (дожидается ответов на полученные сообщения)
+		if gatewayServer.Enabled() {
+			if err := gatewayServer.Stop(shutdownCtx); err != nil {
+				slog.Error("Card gateway shutdown error", slog.String("error", err.Error()))
+			}
+			slog.Info("Card gateway stopped")
+		}
 
 		// Останавливаем HTTP сервер
 		if err := server.Shutdown(shutdownCtx); err != nil {
@@ -157,3 +392,20 @@ func main() {
 
 	slog.Info("Server stopped")
 }
+
+// reloadJWTKeys периодически перечитывает директорию ключей JWT
+func reloadJWTKeys(ctx context.Context, keys *utils.KeyRing, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := keys.Reload(); err != nil {
+				slog.Error("Failed to reload JWT keys", slog.String("error", err.Error()))
+			}
+		}
+	}
+}
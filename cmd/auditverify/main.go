@@ -0,0 +1,61 @@
+// Команда auditverify проверяет целостность цепочки хешей журнала аудита.
+// Код выхода 0 - цепочка цела, 1 - ошибка запуска, 2 - обнаружено изменение записей.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"os"
+
+	"github.com/vterdunov/learn-bank-app/internal/config"
+	"github.com/vterdunov/learn-bank-app/internal/database"
+	"github.com/vterdunov/learn-bank-app/internal/repository"
+	"github.com/vterdunov/learn-bank-app/internal/service"
+)
+
+func main() {
+	lg := slog.New(slog.NewJSONHandler(os.Stderr, &slog.HandlerOptions{
+		Level: slog.LevelInfo,
+	}))
+	slog.SetDefault(lg)
+
+	os.Exit(run(lg))
+}
+
+func run(lg *slog.Logger) int {
+	ctx := context.Background()
+
+	dbCfg := config.LoadDatabase()
+	db, err := database.NewConnection(ctx, database.Config{
+		Host:     dbCfg.Host,
+		Port:     dbCfg.Port,
+		Database: dbCfg.Database,
+		Username: dbCfg.Username,
+		Password: dbCfg.Password,
+		SSLMode:  dbCfg.SSLMode,
+	})
+	if err != nil {
+		lg.Error("Failed to connect to database", slog.String("error", err.Error()))
+		return 1
+	}
+	defer db.Close()
+
+	auditService := service.NewAuditService(repository.NewAuditRepository(db.Pool), lg)
+
+	result, err := auditService.VerifyChain(ctx, nil)
+	if err != nil {
+		lg.Error("Failed to verify audit chain", slog.String("error", err.Error()))
+		return 1
+	}
+
+	// Результат в stdout: head_hash стоит сохранять вне БД, чтобы обнаружить удаление последних записей
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	_ = encoder.Encode(result)
+
+	if !result.Valid {
+		return 2
+	}
+	return 0
+}
@@ -0,0 +1,176 @@
+// Команда iso8583client воспроизводит фикстуры сообщений ISO 8583 против шлюза карточных операций
+// (по TCP или в JSON по HTTP) и сверяет поля ответов с ожидаемыми.
+// Код выхода 0 - все ответы совпали, 1 - ошибка запуска или связи, 2 - есть расхождения.
+//
+//	GATEWAY_MAC_KEY=... go run ./cmd/iso8583client -addr localhost:8583 \
+//		-fixtures internal/service/testdata/iso8583/authorization.json -pan 4000001234567899 -expiry 2803 -cvv 123 -fresh
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/vterdunov/learn-bank-app/pkg/iso8583"
+)
+
+type options struct {
+	addr     string
+	httpURL  string
+	fixtures string
+	pan      string
+	expiry   string
+	cvv      string
+	fresh    bool
+	timeout  time.Duration
+	macKey   string
+}
+
+func main() {
+	lg := slog.New(slog.NewJSONHandler(os.Stderr, &slog.HandlerOptions{
+		Level: slog.LevelInfo,
+	}))
+	slog.SetDefault(lg)
+
+	var opts options
+	flag.StringVar(&opts.addr, "addr", "localhost:8583", "TCP address of the gateway")
+	flag.StringVar(&opts.httpURL, "http", "", "JSON endpoint of the gateway (e.g. http://localhost:8584/iso8583); overrides -addr")
+	flag.StringVar(&opts.fixtures, "fixtures", "", "path to a JSON file with fixtures")
+	flag.StringVar(&opts.pan, "pan", "", "card number to substitute into field 2")
+	flag.StringVar(&opts.expiry, "expiry", "", "card expiry (YYMM) to substitute into field 14")
+	flag.StringVar(&opts.cvv, "cvv", "", "CVV2 to substitute into field 48")
+	flag.BoolVar(&opts.fresh, "fresh", false, "rewrite RRNs so fixtures can be replayed more than once")
+	flag.DurationVar(&opts.timeout, "timeout", 10*time.Second, "response timeout")
+	flag.StringVar(&opts.macKey, "mac-key", os.Getenv("GATEWAY_MAC_KEY"), "gateway MAC key (default $GATEWAY_MAC_KEY)")
+	flag.Parse()
+
+	os.Exit(run(lg, opts))
+}
+
+func run(lg *slog.Logger, opts options) int {
+	if opts.fixtures == "" {
+		lg.Error("Fixtures file is required (-fixtures)")
+		return 1
+	}
+	if opts.macKey == "" {
+		lg.Error("Gateway MAC key is required (-mac-key or GATEWAY_MAC_KEY)")
+		return 1
+	}
+
+	fixtures, err := iso8583.LoadFixtures(opts.fixtures)
+	if err != nil {
+		lg.Error("Failed to load fixtures", slog.String("error", err.Error()))
+		return 1
+	}
+
+	send := sendHTTP(opts)
+	if opts.httpURL == "" {
+		client, err := iso8583.Dial(opts.addr, opts.timeout, []byte(opts.macKey))
+		if err != nil {
+			lg.Error("Failed to connect to gateway", slog.String("address", opts.addr), slog.String("error", err.Error()))
+			return 1
+		}
+		defer client.Close()
+		send = client.Send
+	}
+
+	// Одинаковый исходный RRN переписывается одинаково: реверс находит свою авторизацию
+	rrnPrefix := fmt.Sprintf("%06d", time.Now().Unix()%1000000)
+
+	failed := 0
+	for i := range fixtures {
+		fixture := &fixtures[i]
+		request := prepare(fixture.Request, opts, rrnPrefix)
+
+		response, err := send(request)
+		if err != nil {
+			lg.Error("Failed to send fixture", slog.String("fixture", fixture.Name), slog.String("error", err.Error()))
+			return 1
+		}
+
+		status := "OK"
+		if err := fixture.Check(response); err != nil {
+			status = "FAIL: " + err.Error()
+			failed++
+		}
+		fmt.Printf("%-40s %s -> %s %s %s\n", fixture.Name, request.MTI, response.MTI,
+			response.Get(iso8583.FieldResponseCode), status)
+	}
+
+	if failed > 0 {
+		return 2
+	}
+	return 0
+}
+
+// prepare подставляет реквизиты карты и при -fresh переписывает RRN
+func prepare(fixture *iso8583.Message, opts options, rrnPrefix string) *iso8583.Message {
+	request := iso8583.NewMessage(fixture.MTI)
+	for field, value := range fixture.Fields {
+		request.Set(field, value)
+	}
+
+	overrides := map[int]string{
+		iso8583.FieldPAN:            opts.pan,
+		iso8583.FieldExpiryDate:     opts.expiry,
+		iso8583.FieldAdditionalData: opts.cvv,
+	}
+	for field, value := range overrides {
+		if value != "" && request.Has(field) {
+			request.Set(field, value)
+		}
+	}
+
+	if rrn := request.Get(iso8583.FieldRRN); opts.fresh && rrn != "" {
+		if len(rrn) > 6 {
+			rrn = rrn[len(rrn)-6:]
+		}
+		request.Set(iso8583.FieldRRN, rrnPrefix+rrn)
+	}
+
+	return request
+}
+
+// sendHTTP отправляет сообщение в JSON-эндпоинт шлюза
+func sendHTTP(opts options) func(*iso8583.Message) (*iso8583.Message, error) {
+	httpClient := &http.Client{Timeout: opts.timeout}
+	macKey := []byte(opts.macKey)
+
+	return func(request *iso8583.Message) (*iso8583.Message, error) {
+		if _, err := iso8583.Sign(request, macKey); err != nil {
+			return nil, err
+		}
+		body, err := json.Marshal(request)
+		if err != nil {
+			return nil, err
+		}
+
+		resp, err := httpClient.Post(opts.httpURL, "application/json", bytes.NewReader(body))
+		if err != nil {
+			return nil, err
+		}
+		defer resp.Body.Close()
+
+		var envelope struct {
+			Success bool             `json:"success"`
+			Data    *iso8583.Message `json:"data"`
+			Error   json.RawMessage  `json:"error"`
+		}
+		if err := json.NewDecoder(resp.Body).Decode(&envelope); err != nil {
+			return nil, fmt.Errorf("failed to decode response: %w", err)
+		}
+		if !envelope.Success || envelope.Data == nil {
+			return nil, fmt.Errorf("gateway returned %s: %s", resp.Status, envelope.Error)
+		}
+		if err := iso8583.Verify(envelope.Data, macKey); err != nil {
+			return nil, fmt.Errorf("failed to verify response: %w", err)
+		}
+
+		return envelope.Data, nil
+	}
+}
@@ -3,6 +3,8 @@ module github.com/vterdunov/learn-bank-app
 go 1.24.4
 
 require (
+	github.com/beevik/etree v1.5.1
+	github.com/go-mail/mail/v2 v2.3.0
 	github.com/golang-jwt/jwt/v5 v5.2.2
 	github.com/golang-migrate/migrate/v4 v4.18.3
 	github.com/jackc/pgx/v5 v5.7.5
@@ -10,8 +12,6 @@ require (
 )
 
 require (
-	github.com/beevik/etree v1.5.1 // indirect
-	github.com/go-mail/mail/v2 v2.3.0 // indirect
 	github.com/hashicorp/errwrap v1.1.0 // indirect
 	github.com/hashicorp/go-multierror v1.1.1 // indirect
 	github.com/jackc/pgpassfile v1.0.0 // indirect
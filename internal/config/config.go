@@ -2,8 +2,10 @@ package config
 
 import (
 	"fmt"
+	"net/netip"
 	"os"
 	"strconv"
+	"strings"
 	"time"
 )
 
@@ -14,12 +16,56 @@ type Config struct {
 	SMTP      SMTPConfig
 	CBR       CBRConfig
 	Scheduler SchedulerConfig
+	EOD       EODConfig
+	Standing  StandingOrderConfig
+	Transfer  TransferConfig
+	Card      CardConfig
+	Gateway   GatewayConfig
+	Merchant  MerchantConfig
+	Cashback  CashbackConfig
+	Overdraft OverdraftConfig
+	Fraud     FraudConfig
+	Sanctions SanctionsConfig
+	KYC       KYCConfig
+	RateLimit RateLimitConfig
+	Outbox    OutboxConfig
+	Notify    NotificationConfig
 	Logger    LoggerConfig
 }
 
 type ServerConfig struct {
 	Port string
 	Host string
+	// MaxBodySize лимит тела запроса в байтах
+	MaxBodySize int64
+	// MaxUploadSize лимит тела запроса загрузки документа; должен превышать KYC_DOCUMENT_MAX_SIZE на размер полей формы
+	MaxUploadSize int64
+	// Timeouts время обработки запроса по группам маршрутов
+	Timeouts RouteTimeouts
+	// HSTSMaxAge срок заголовка Strict-Transport-Security; 0 отключает заголовок
+	HSTSMaxAge time.Duration
+	// ContentSecurityPolicy значение заголовка Content-Security-Policy
+	ContentSecurityPolicy string
+	CORS                  CORSConfig
+	// TrustedProxies подсети балансировщиков, заголовкам X-Geo-Country и X-Forwarded-For от которых можно доверять;
+	// от остальных клиентов заголовки игнорируются
+	TrustedProxies []netip.Prefix
+}
+
+// RouteTimeouts время обработки запроса по группам маршрутов; 0 отключает ограничение
+type RouteTimeouts struct {
+	Default time.Duration // Большинство маршрутов
+	CBR     time.Duration // Запрос ключевой ставки ЦБ РФ
+	File    time.Duration // Загрузка и скачивание документов
+}
+
+type CORSConfig struct {
+	// AllowedOrigins разрешенные источники; пустой список отключает CORS, "*" разрешает любой
+	AllowedOrigins []string
+	AllowedMethods []string
+	AllowedHeaders []string
+	// MaxAge срок кеширования ответа на preflight запрос
+	MaxAge time.Duration
 }
 
 type DatabaseConfig struct {
@@ -33,6 +79,16 @@ type DatabaseConfig struct {
 
 type JWTConfig struct {
 	Secret string
+	// KeysDir директория с ключами RS256/EdDSA (<kid>.pem, <kid>.pub.pem)
+	KeysDir string
+	// SigningKID активный ключ подписи (по умолчанию последний по имени)
+	SigningKID string
+	// KeysReloadInterval период перечитывания директории ключей
+	KeysReloadInterval time.Duration
+	// LegacyHMAC включает на время миграции на KeysDir проверку старых HS256 токенов секретом Secret
+	LegacyHMAC bool
+	// LegacyHMACUntil момент, с которого HS256 токены и токены без kid больше не принимаются
+	LegacyHMACUntil time.Time
 }
 
 type SMTPConfig struct {
@@ -40,6 +96,8 @@ type SMTPConfig struct {
 	Port     int
 	Username string
 	Password string
+	// From адрес отправителя (по умолчанию Username)
+	From string
 }
 
 type CBRConfig struct {
@@ -48,8 +106,180 @@ type CBRConfig struct {
 }
 
 type SchedulerConfig struct {
-	Interval    time.Duration
-	PenaltyRate float64
+	// OverdueSchedule расписание обработки просрочек: cron из пяти полей или @every <интервал>
+	OverdueSchedule string
+	PenaltyRate     float64
+	// TickInterval период проверки лидерства и наступивших задач
+	TickInterval time.Duration
+}
+
+type EODConfig struct {
+	// Schedule расписание проверки закрытия дня; пропущенные дни закрываются по порядку от текущих остатков
+	Schedule string
+	// Timezone часовой пояс, в котором определяется операционная дата
+	Timezone string
+	// SavingsRate годовая ставка на остаток счетов в процентах (0 — не начислять)
+	SavingsRate float64
+}
+
+type StandingOrderConfig struct {
+	// Schedule расписание исполнения наступивших платежей по постоянным поручениям
+	Schedule string
+	// MaxAttempts число попыток платежа при нехватке средств, после которого платеж считается неисполненным
+	MaxAttempts int
+	// RetryInterval пауза между попытками платежа
+	RetryInterval time.Duration
+}
+
+type TransferConfig struct {
+	// NewRecipientLimit сумма переводов новому получателю за NewRecipientPeriod (0 — без ограничения)
+	NewRecipientLimit float64
+	// NewRecipientPeriod срок с первого перевода, в течение которого получатель считается новым
+	NewRecipientPeriod time.Duration
+}
+
+type CardConfig struct {
+	// HoldTTL срок действия авторизации: не списанный к этому времени холд снимается автоматически
+	HoldTTL time.Duration
+	// HoldExpirySchedule расписание снятия просроченных холдов
+	HoldExpirySchedule string
+	// EncryptionKey ключ шифрования данных карт в hex (не менее 32 байт); без него ключ
+	// генерируется при старте, и карты, выпущенные до перезапуска, нельзя расшифровать
+	EncryptionKey string
+	// CVVMaxAttempts число неверных CVV подряд, после которого карта блокируется
+	CVVMaxAttempts int
+	// PINMaxAttempts число неверных PIN подряд, после которого карта блокируется
+	PINMaxAttempts int
+	// RevealTokenTTL время, за которое нужно показать реквизиты по одноразовому токену
+	RevealTokenTTL time.Duration
+	// RevealLimit число попыток получить токен показа реквизитов карты за RevealWindow, включая неверный пароль (0 — без ограничения)
+	RevealLimit  int
+	RevealWindow time.Duration
+}
+
+type GatewayConfig struct {
+	// TCPAddr адрес приема кадров ISO 8583 (пусто — не запускать)
+	TCPAddr string
+	// HTTPAddr адрес приема тех же сообщений в JSON (пусто — не запускать)
+	HTTPAddr string
+	// IdleTimeout время простоя TCP-соединения до его закрытия
+	IdleTimeout time.Duration
+	// MACKey общий с терминалами ключ MAC (поле 128); обязателен, если включен хотя бы один listener
+	MACKey string
+}
+
+type MerchantConfig struct {
+	// InterchangeFeePercent комиссия в процентах, удерживаемая с платежа картой при зачислении ТСП
+	InterchangeFeePercent float64
+	// SettlementSchedule расписание формирования дневных отчетов по расчетам с ТСП
+	SettlementSchedule string
+}
+
+type CashbackConfig struct {
+	// MonthlyCap лимит кэшбэка по одной карте за месяц
+	MonthlyCap float64
+	// PayoutSchedule расписание выплаты кэшбэка за прошедшие месяцы
+	PayoutSchedule string
+}
+
+type OverdraftConfig struct {
+	// Products условия овердрафта по продуктам счетов (checking, savings)
+	Products map[string]OverdraftProductConfig
+}
+
+// OverdraftProductConfig условия овердрафта продукта; MaxLimit 0 — овердрафт по продукту не предоставляется
+type OverdraftProductConfig struct {
+	// MaxLimit максимальный лимит, который владелец может установить на счет
+	MaxLimit float64
+	// AnnualRate годовая ставка в процентах на использованный овердрафт; проценты списываются ежедневно
+	AnnualRate float64
+}
+
+type FraudConfig struct {
+	// MandatoryControlAmount сумма операции, подлежащей обязательному контролю по 115-ФЗ (0 — не проверять)
+	MandatoryControlAmount float64
+	// RejectAmount сумма, начиная с которой операция отклоняется без проверки оператором (0 — без ограничения)
+	RejectAmount float64
+	// VelocityWindow окно подсчета исходящих операций клиента
+	VelocityWindow time.Duration
+	// VelocityMaxCount число операций за окно, после которого операции уходят на проверку (0 — не проверять)
+	VelocityMaxCount int
+	// VelocityMaxAmount сумма операций за окно, после которой операции уходят на проверку (0 — не проверять)
+	VelocityMaxAmount float64
+	// NewRecipientAmount сумма перевода новому получателю, с которой перевод уходит на проверку (0 — не проверять)
+	NewRecipientAmount float64
+	// NightStartHour и NightEndHour ночные часы по часовому поясу EOD_TIMEZONE
+	NightStartHour int
+	NightEndHour   int
+	// NightAmount сумма операции в ночные часы, с которой операция уходит на проверку (0 — не проверять)
+	NightAmount float64
+	// AllowedCountries страны, операции из которых не считаются необычными (пусто — не проверять)
+	AllowedCountries []string
+	// BlockedCountries страны, операции из которых отклоняются
+	BlockedCountries []string
+	// ApprovalTTL срок, в течение которого клиент может повторить одобренную оператором операцию
+	ApprovalTTL time.Duration
+}
+
+type SanctionsConfig struct {
+	// ListFiles файлы санкционных списков в формате CSV или XML; имя файла без расширения — имя списка
+	ListFiles []string
+	// MatchThreshold минимальная оценка похожести имени (0..1), с которой совпадение попадает на проверку
+	MatchThreshold float64
+	// RefreshSchedule расписание проверки файлов списков; при изменении списка все клиенты проверяются заново
+	RefreshSchedule string
+}
+
+type KYCConfig struct {
+	// DocumentDir каталог для сканов документов клиентов
+	DocumentDir string
+	// MaxDocumentSize максимальный размер файла документа в байтах
+	MaxDocumentSize int64
+	// Limits лимиты исходящих операций по уровням идентификации (none, simplified, full)
+	Limits map[string]KYCLimitConfig
+}
+
+// KYCLimitConfig лимиты уровня идентификации; 0 — без ограничения
+type KYCLimitConfig struct {
+	// SingleOperation максимальная сумма одной операции
+	SingleOperation float64
+	// Monthly сумма исходящих операций за календарный месяц по часовому поясу EOD_TIMEZONE;
+	// переводы между своими счетами не учитываются
+	Monthly float64
+}
+
+type RateLimitConfig struct {
+	// Store хранилище корзин: memory (в памяти экземпляра) или postgres (общее для нескольких экземпляров)
+	Store string
+	// CleanupInterval как часто удалять полностью восполненные корзины
+	CleanupInterval time.Duration
+	// Routes лимиты групп маршрутов (login, register, cbr, public, api, admin);
+	// корзина заводится на каждый маршрут группы и клиента
+	Routes map[string]RateLimitRouteConfig
+}
+
+// RateLimitRouteConfig лимит token bucket: Requests запросов подряд, полное восполнение за Period; 0 — без ограничения
+type RateLimitRouteConfig struct {
+	Requests int
+	Period   time.Duration
+}
+
+type OutboxConfig struct {
+	PollInterval time.Duration
+	BatchSize    int
+	MaxAttempts  int
+	BaseBackoff  time.Duration
+	MaxBackoff   time.Duration
+	// Lease время, на которое диспетчер забирает сообщение
+	Lease time.Duration
+}
+
+type NotificationConfig struct {
+	WebhookTimeout time.Duration
+	// SMSProvider имя SMS шлюза; "log" только пишет сообщения в лог (для разработки)
+	SMSProvider string
+	// EmailTemplateVersion версия шаблонов писем для новых сообщений
+	EmailTemplateVersion string
 }
 
 type LoggerConfig struct {
@@ -61,33 +291,179 @@ type LoggerConfig struct {
 func Load() (*Config, error) {
 	cfg := &Config{
 		Server: ServerConfig{
-			Host: getEnvString("SERVER_HOST", "localhost"),
-			Port: getEnvString("SERVER_PORT", "8080"),
-		},
-		Database: DatabaseConfig{
-			Host:     getEnvString("DB_HOST", "localhost"),
-			Port:     getEnvString("DB_PORT", "5432"),
-			Database: getEnvString("DB_NAME", "bankapp"),
-			Username: getEnvString("DB_USER", "postgres"),
-			Password: getEnvString("DB_PASSWORD", "postgres"),
-			SSLMode:  getEnvString("DB_SSL_MODE", "disable"),
+			Host:          getEnvString("SERVER_HOST", "localhost"),
+			Port:          getEnvString("SERVER_PORT", "8080"),
+			MaxBodySize:   int64(getEnvInt("HTTP_MAX_BODY_SIZE", 1<<20)),
+			MaxUploadSize: int64(getEnvInt("HTTP_MAX_UPLOAD_SIZE", 11<<20)),
+			Timeouts: RouteTimeouts{
+				Default: getEnvDuration("HTTP_TIMEOUT_DEFAULT", 10*time.Second),
+				// Запрос к ЦБ РФ ограничен 30 секундами на стороне клиента
+				CBR:  getEnvDuration("HTTP_TIMEOUT_CBR", 35*time.Second),
+				File: getEnvDuration("HTTP_TIMEOUT_FILE", 2*time.Minute),
+			},
+			HSTSMaxAge:            getEnvDuration("HTTP_HSTS_MAX_AGE", 365*24*time.Hour),
+			ContentSecurityPolicy: getEnvString("HTTP_CONTENT_SECURITY_POLICY", "default-src 'none'; frame-ancestors 'none'"),
+			CORS: CORSConfig{
+				AllowedOrigins: getEnvList("CORS_ALLOWED_ORIGINS", nil),
+				AllowedMethods: getEnvList("CORS_ALLOWED_METHODS", []string{"GET", "POST", "PUT", "DELETE"}),
+				AllowedHeaders: getEnvList("CORS_ALLOWED_HEADERS", []string{"Authorization", "Content-Type"}),
+				MaxAge:         getEnvDuration("CORS_MAX_AGE", 10*time.Minute),
+			},
 		},
+		Database: LoadDatabase(),
 		JWT: JWTConfig{
-			Secret: getEnvString("JWT_SECRET", ""),
+			Secret:             getEnvString("JWT_SECRET", ""),
+			KeysDir:            getEnvString("JWT_KEYS_DIR", ""),
+			SigningKID:         getEnvString("JWT_SIGNING_KID", ""),
+			KeysReloadInterval: getEnvDuration("JWT_KEYS_RELOAD_INTERVAL", 5*time.Minute),
+			LegacyHMAC:         getEnvBool("JWT_LEGACY_HMAC", false),
 		},
 		SMTP: SMTPConfig{
 			Host:     getEnvString("SMTP_HOST", ""),
 			Port:     getEnvInt("SMTP_PORT", 587),
 			Username: getEnvString("SMTP_USERNAME", ""),
 			Password: getEnvString("SMTP_PASSWORD", ""),
+			From:     getEnvString("SMTP_FROM", ""),
 		},
 		CBR: CBRConfig{
 			ServiceURL: getEnvString("CBR_SERVICE_URL", "https://www.cbr.ru/DailyInfoWebServ/DailyInfo.asmx"),
 			BankMargin: getEnvFloat("CBR_BANK_MARGIN", 5.0),
 		},
 		Scheduler: SchedulerConfig{
-			Interval:    getEnvDuration("SCHEDULER_INTERVAL", 12*time.Hour),
-			PenaltyRate: getEnvFloat("SCHEDULER_PENALTY_RATE", 10.0),
+			OverdueSchedule: getEnvString("SCHEDULER_OVERDUE_SCHEDULE", "@every 12h"),
+			PenaltyRate:     getEnvFloat("SCHEDULER_PENALTY_RATE", 10.0),
+			TickInterval:    getEnvDuration("SCHEDULER_TICK_INTERVAL", 15*time.Second),
+		},
+		EOD: EODConfig{
+			Schedule:    getEnvString("EOD_SCHEDULE", "@hourly"),
+			Timezone:    getEnvString("EOD_TIMEZONE", "Europe/Moscow"),
+			SavingsRate: getEnvFloat("EOD_SAVINGS_RATE", 0),
+		},
+		Standing: StandingOrderConfig{
+			Schedule:      getEnvString("STANDING_ORDERS_SCHEDULE", "@every 15m"),
+			MaxAttempts:   getEnvInt("STANDING_ORDERS_MAX_ATTEMPTS", 3),
+			RetryInterval: getEnvDuration("STANDING_ORDERS_RETRY_INTERVAL", 4*time.Hour),
+		},
+		Transfer: TransferConfig{
+			NewRecipientLimit:  getEnvFloat("TRANSFER_NEW_RECIPIENT_LIMIT", 15000),
+			NewRecipientPeriod: getEnvDuration("TRANSFER_NEW_RECIPIENT_PERIOD", 24*time.Hour),
+		},
+		Card: CardConfig{
+			HoldTTL:            getEnvDuration("CARD_HOLD_TTL", 7*24*time.Hour),
+			HoldExpirySchedule: getEnvString("CARD_HOLD_EXPIRY_SCHEDULE", "@every 15m"),
+			EncryptionKey:      getEnvString("CARD_ENCRYPTION_KEY", ""),
+			CVVMaxAttempts:     getEnvInt("CARD_CVV_MAX_ATTEMPTS", 3),
+			PINMaxAttempts:     getEnvInt("CARD_PIN_MAX_ATTEMPTS", 3),
+			RevealTokenTTL:     getEnvDuration("CARD_REVEAL_TOKEN_TTL", 60*time.Second),
+			RevealLimit:        getEnvInt("CARD_REVEAL_LIMIT", 5),
+			RevealWindow:       getEnvDuration("CARD_REVEAL_WINDOW", 24*time.Hour),
+		},
+		Gateway: GatewayConfig{
+			TCPAddr:     getEnvString("GATEWAY_TCP_ADDR", ""),
+			HTTPAddr:    getEnvString("GATEWAY_HTTP_ADDR", ""),
+			IdleTimeout: getEnvDuration("GATEWAY_IDLE_TIMEOUT", 5*time.Minute),
+			MACKey:      getEnvString("GATEWAY_MAC_KEY", ""),
+		},
+		Merchant: MerchantConfig{
+			InterchangeFeePercent: getEnvFloat("MERCHANT_INTERCHANGE_FEE_PERCENT", 1.5),
+			SettlementSchedule:    getEnvString("MERCHANT_SETTLEMENT_SCHEDULE", "@hourly"),
+		},
+		Cashback: CashbackConfig{
+			MonthlyCap:     getEnvFloat("CASHBACK_MONTHLY_CAP", 3000),
+			PayoutSchedule: getEnvString("CASHBACK_PAYOUT_SCHEDULE", "@daily"),
+		},
+		Overdraft: OverdraftConfig{
+			Products: map[string]OverdraftProductConfig{
+				"checking": {
+					MaxLimit:   getEnvFloat("OVERDRAFT_CHECKING_MAX_LIMIT", 50000),
+					AnnualRate: getEnvFloat("OVERDRAFT_CHECKING_RATE", 29.9),
+				},
+				"savings": {
+					MaxLimit:   getEnvFloat("OVERDRAFT_SAVINGS_MAX_LIMIT", 0),
+					AnnualRate: getEnvFloat("OVERDRAFT_SAVINGS_RATE", 0),
+				},
+			},
+		},
+		Fraud: FraudConfig{
+			MandatoryControlAmount: getEnvFloat("FRAUD_MANDATORY_CONTROL_AMOUNT", 600000),
+			RejectAmount:           getEnvFloat("FRAUD_REJECT_AMOUNT", 0),
+			VelocityWindow:         getEnvDuration("FRAUD_VELOCITY_WINDOW", time.Hour),
+			VelocityMaxCount:       getEnvInt("FRAUD_VELOCITY_MAX_COUNT", 10),
+			VelocityMaxAmount:      getEnvFloat("FRAUD_VELOCITY_MAX_AMOUNT", 300000),
+			NewRecipientAmount:     getEnvFloat("FRAUD_NEW_RECIPIENT_AMOUNT", 100000),
+			NightStartHour:         getEnvInt("FRAUD_NIGHT_START_HOUR", 1),
+			NightEndHour:           getEnvInt("FRAUD_NIGHT_END_HOUR", 5),
+			NightAmount:            getEnvFloat("FRAUD_NIGHT_AMOUNT", 50000),
+			AllowedCountries:       getEnvList("FRAUD_ALLOWED_COUNTRIES", []string{"RU"}),
+			BlockedCountries:       getEnvList("FRAUD_BLOCKED_COUNTRIES", nil),
+			ApprovalTTL:            getEnvDuration("FRAUD_APPROVAL_TTL", 24*time.Hour),
+		},
+		Sanctions: SanctionsConfig{
+			ListFiles:       getEnvList("SANCTIONS_LIST_FILES", nil),
+			MatchThreshold:  getEnvFloat("SANCTIONS_MATCH_THRESHOLD", 0.88),
+			RefreshSchedule: getEnvString("SANCTIONS_REFRESH_SCHEDULE", "@hourly"),
+		},
+		KYC: KYCConfig{
+			DocumentDir:     getEnvString("KYC_DOCUMENT_DIR", "./data/kyc"),
+			MaxDocumentSize: int64(getEnvInt("KYC_DOCUMENT_MAX_SIZE", 10<<20)),
+			// Лимиты по умолчанию соответствуют 115-ФЗ для неидентифицированных клиентов и упрощенной идентификации
+			Limits: map[string]KYCLimitConfig{
+				"none": {
+					SingleOperation: getEnvFloat("KYC_LIMIT_NONE_SINGLE", 15000),
+					Monthly:         getEnvFloat("KYC_LIMIT_NONE_MONTHLY", 40000),
+				},
+				"simplified": {
+					SingleOperation: getEnvFloat("KYC_LIMIT_SIMPLIFIED_SINGLE", 60000),
+					Monthly:         getEnvFloat("KYC_LIMIT_SIMPLIFIED_MONTHLY", 200000),
+				},
+				"full": {
+					SingleOperation: getEnvFloat("KYC_LIMIT_FULL_SINGLE", 0),
+					Monthly:         getEnvFloat("KYC_LIMIT_FULL_MONTHLY", 0),
+				},
+			},
+		},
+		RateLimit: RateLimitConfig{
+			Store:           getEnvString("RATE_LIMIT_STORE", "memory"),
+			CleanupInterval: getEnvDuration("RATE_LIMIT_CLEANUP_INTERVAL", time.Minute),
+			Routes: map[string]RateLimitRouteConfig{
+				"login": {
+					Requests: getEnvInt("RATE_LIMIT_LOGIN_REQUESTS", 10),
+					Period:   getEnvDuration("RATE_LIMIT_LOGIN_PERIOD", time.Minute),
+				},
+				"register": {
+					Requests: getEnvInt("RATE_LIMIT_REGISTER_REQUESTS", 5),
+					Period:   getEnvDuration("RATE_LIMIT_REGISTER_PERIOD", 10*time.Minute),
+				},
+				"cbr": {
+					Requests: getEnvInt("RATE_LIMIT_CBR_REQUESTS", 30),
+					Period:   getEnvDuration("RATE_LIMIT_CBR_PERIOD", time.Minute),
+				},
+				"public": {
+					Requests: getEnvInt("RATE_LIMIT_PUBLIC_REQUESTS", 60),
+					Period:   getEnvDuration("RATE_LIMIT_PUBLIC_PERIOD", time.Minute),
+				},
+				"api": {
+					Requests: getEnvInt("RATE_LIMIT_API_REQUESTS", 300),
+					Period:   getEnvDuration("RATE_LIMIT_API_PERIOD", time.Minute),
+				},
+				"admin": {
+					Requests: getEnvInt("RATE_LIMIT_ADMIN_REQUESTS", 120),
+					Period:   getEnvDuration("RATE_LIMIT_ADMIN_PERIOD", time.Minute),
+				},
+			},
+		},
+		Outbox: OutboxConfig{
+			PollInterval: getEnvDuration("OUTBOX_POLL_INTERVAL", 10*time.Second),
+			BatchSize:    getEnvInt("OUTBOX_BATCH_SIZE", 20),
+			MaxAttempts:  getEnvInt("OUTBOX_MAX_ATTEMPTS", 8),
+			BaseBackoff:  getEnvDuration("OUTBOX_BASE_BACKOFF", 30*time.Second),
+			MaxBackoff:   getEnvDuration("OUTBOX_MAX_BACKOFF", time.Hour),
+			Lease:        getEnvDuration("OUTBOX_LEASE", 2*time.Minute),
+		},
+		Notify: NotificationConfig{
+			WebhookTimeout:       getEnvDuration("NOTIFY_WEBHOOK_TIMEOUT", 10*time.Second),
+			SMSProvider:          getEnvString("NOTIFY_SMS_PROVIDER", "log"),
+			EmailTemplateVersion: getEnvString("EMAIL_TEMPLATE_VERSION", "v1"),
 		},
 		Logger: LoggerConfig{
 			Level:  getEnvString("LOG_LEVEL", "info"),
@@ -96,13 +472,47 @@ func Load() (*Config, error) {
 		},
 	}
 
-	if cfg.JWT.Secret == "" {
-		return nil, fmt.Errorf("JWT_SECRET environment variable is required")
+	if cfg.JWT.Secret == "" && cfg.JWT.KeysDir == "" {
+		return nil, fmt.Errorf("JWT_SECRET or JWT_KEYS_DIR environment variable is required")
+	}
+	if cfg.JWT.LegacyHMAC {
+		if cfg.JWT.KeysDir == "" || cfg.JWT.Secret == "" {
+			return nil, fmt.Errorf("JWT_LEGACY_HMAC requires both JWT_KEYS_DIR and JWT_SECRET")
+		}
+		until, err := time.Parse(time.RFC3339, os.Getenv("JWT_LEGACY_HMAC_UNTIL"))
+		if err != nil {
+			return nil, fmt.Errorf("JWT_LEGACY_HMAC_UNTIL must be an RFC 3339 time when JWT_LEGACY_HMAC is enabled: %w", err)
+		}
+		cfg.JWT.LegacyHMACUntil = until
+	}
+
+	if (cfg.Gateway.TCPAddr != "" || cfg.Gateway.HTTPAddr != "") && cfg.Gateway.MACKey == "" {
+		return nil, fmt.Errorf("GATEWAY_MAC_KEY environment variable is required when the card gateway is enabled")
+	}
+
+	for _, value := range getEnvList("HTTP_TRUSTED_PROXIES", nil) {
+		prefix, err := netip.ParsePrefix(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid HTTP_TRUSTED_PROXIES entry %q: %w", value, err)
+		}
+		cfg.Server.TrustedProxies = append(cfg.Server.TrustedProxies, prefix)
 	}
 
 	return cfg, nil
 }
 
+// LoadDatabase загружает только настройки БД (для служебных утилит без JWT и SMTP)
+func LoadDatabase() DatabaseConfig {
+	return DatabaseConfig{
+		Host:     getEnvString("DB_HOST", "localhost"),
+		Port:     getEnvString("DB_PORT", "5432"),
+		Database: getEnvString("DB_NAME", "bankapp"),
+		Username: getEnvString("DB_USER", "postgres"),
+		Password: getEnvString("DB_PASSWORD", "postgres"),
+		SSLMode:  getEnvString("DB_SSL_MODE", "disable"),
+	}
+}
+
 func getEnvString(key, defaultValue string) string {
 	if value := os.Getenv(key); value != "" {
 		return value
@@ -128,6 +538,15 @@ func getEnvFloat(key string, defaultValue float64) float64 {
 	return defaultValue
 }
 
+func getEnvBool(key string, defaultValue bool) bool {
+	if value := os.Getenv(key); value != "" {
+		if boolValue, err := strconv.ParseBool(value); err == nil {
+			return boolValue
+		}
+	}
+	return defaultValue
+}
+
 func getEnvDuration(key string, defaultValue time.Duration) time.Duration {
 	if value := os.Getenv(key); value != "" {
 		if duration, err := time.ParseDuration(value); err == nil {
@@ -136,3 +555,19 @@ func getEnvDuration(key string, defaultValue time.Duration) time.Duration {
 	}
 	return defaultValue
 }
+
+// getEnvList разбирает список значений через запятую; пустые элементы пропускаются
+func getEnvList(key string, defaultValue []string) []string {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue
+	}
+
+	var list []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			list = append(list, item)
+		}
+	}
+	return list
+}
@@ -0,0 +1,10 @@
+-- Удаление журнала аудита
+DROP TRIGGER IF EXISTS prevent_audit_events_truncate ON audit_events;
+DROP TRIGGER IF EXISTS prevent_audit_events_update_delete ON audit_events;
+DROP TABLE IF EXISTS audit_events;
+DROP FUNCTION IF EXISTS prevent_audit_events_modification();
+
+-- Удаление ролей пользователей
+ALTER TABLE users
+DROP CONSTRAINT chk_users_role_valid,
+DROP COLUMN role;
@@ -0,0 +1,55 @@
+-- Роли пользователей (admin - доступ к журналу аудита)
+ALTER TABLE users
+ADD COLUMN role VARCHAR(20) NOT NULL DEFAULT 'user',
+ADD CONSTRAINT chk_users_role_valid CHECK (role IN ('user', 'admin'));
+
+-- Создание журнала аудита (только добавление, цепочка хешей)
+CREATE TABLE IF NOT EXISTS audit_events (
+    id BIGSERIAL PRIMARY KEY,
+    seq BIGINT NOT NULL UNIQUE,
+    actor_user_id INTEGER,
+    actor_type VARCHAR(20) NOT NULL,
+    action VARCHAR(64) NOT NULL,
+    resource_type VARCHAR(32) NOT NULL DEFAULT '',
+    resource_id VARCHAR(64) NOT NULL DEFAULT '',
+    request_id VARCHAR(128) NOT NULL DEFAULT '',
+    ip VARCHAR(64) NOT NULL DEFAULT '',
+    -- JSON (не JSONB), чтобы текст хранился байт в байт и хеш можно было пересчитать
+    before_state JSON,
+    after_state JSON,
+    metadata JSON,
+    created_at TIMESTAMPTZ NOT NULL,
+    prev_hash CHAR(64) NOT NULL,
+    hash CHAR(64) NOT NULL UNIQUE,
+
+    CONSTRAINT chk_audit_actor_type_valid CHECK (actor_type IN ('user', 'admin', 'system', 'anonymous')),
+    CONSTRAINT chk_audit_seq_positive CHECK (seq > 0)
+);
+
+-- Создание индексов для запросов администратора
+CREATE INDEX IF NOT EXISTS idx_audit_events_actor ON audit_events(actor_user_id);
+CREATE INDEX IF NOT EXISTS idx_audit_events_action ON audit_events(action);
+CREATE INDEX IF NOT EXISTS idx_audit_events_resource ON audit_events(resource_type, resource_id);
+CREATE INDEX IF NOT EXISTS idx_audit_events_created_at ON audit_events(created_at);
+
+-- Запрет изменения и удаления записей аудита
+CREATE OR REPLACE FUNCTION prevent_audit_events_modification()
+RETURNS TRIGGER AS $$
+BEGIN
+    RAISE EXCEPTION 'audit_events is append-only';
+END;
+$$ language 'plpgsql';
+
+CREATE TRIGGER prevent_audit_events_update_delete
+    BEFORE UPDATE OR DELETE ON audit_events
+    FOR EACH ROW
+    EXECUTE FUNCTION prevent_audit_events_modification();
+
+CREATE TRIGGER prevent_audit_events_truncate
+    BEFORE TRUNCATE ON audit_events
+    FOR EACH STATEMENT
+    EXECUTE FUNCTION prevent_audit_events_modification();
+
+COMMENT ON COLUMN audit_events.seq IS 'Позиция записи в цепочке (без пропусков)';
+COMMENT ON COLUMN audit_events.prev_hash IS 'Хеш предыдущей записи цепочки';
+COMMENT ON COLUMN audit_events.hash IS 'SHA-256 от содержимого записи и prev_hash';
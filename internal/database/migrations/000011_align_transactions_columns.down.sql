@@ -0,0 +1,22 @@
+-- Возврат исходных имен полей таблицы transactions
+DROP TRIGGER IF EXISTS update_transactions_updated_at ON transactions;
+
+ALTER TABLE transactions DROP CONSTRAINT chk_accounts_not_same;
+ALTER TABLE transactions DROP CONSTRAINT chk_transaction_type_valid;
+
+ALTER TABLE transactions DROP COLUMN updated_at;
+
+ALTER TABLE transactions RENAME COLUMN type TO transaction_type;
+ALTER TABLE transactions RENAME COLUMN to_account TO to_account_id;
+ALTER TABLE transactions RENAME COLUMN from_account TO from_account_id;
+
+ALTER TABLE transactions
+ADD CONSTRAINT chk_transaction_type_valid CHECK (
+    transaction_type IN ('deposit', 'withdrawal', 'transfer', 'payment', 'credit_payment', 'penalty')
+);
+ALTER TABLE transactions
+ADD CONSTRAINT chk_accounts_not_same CHECK (
+    (from_account_id IS NULL) OR
+    (to_account_id IS NULL) OR
+    (from_account_id != to_account_id)
+);
@@ -0,0 +1,28 @@
+-- Приведение таблицы transactions к полям, которые использует TransactionRepository
+ALTER TABLE transactions RENAME COLUMN from_account_id TO from_account;
+ALTER TABLE transactions RENAME COLUMN to_account_id TO to_account;
+ALTER TABLE transactions RENAME COLUMN transaction_type TO type;
+
+ALTER TABLE transactions
+ADD COLUMN updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP;
+
+-- Выдача кредита записывается с типом credit
+ALTER TABLE transactions DROP CONSTRAINT chk_transaction_type_valid;
+ALTER TABLE transactions
+ADD CONSTRAINT chk_transaction_type_valid CHECK (
+    type IN ('deposit', 'withdrawal', 'transfer', 'payment', 'credit', 'credit_payment', 'penalty')
+);
+
+ALTER TABLE transactions DROP CONSTRAINT chk_accounts_not_same;
+ALTER TABLE transactions
+ADD CONSTRAINT chk_accounts_not_same CHECK (
+    (from_account IS NULL) OR
+    (to_account IS NULL) OR
+    (from_account != to_account)
+);
+
+-- Триггер для автоматического обновления updated_at
+CREATE TRIGGER update_transactions_updated_at
+    BEFORE UPDATE ON transactions
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
@@ -0,0 +1,3 @@
+-- Удаление таблицы outbox
+DROP TRIGGER IF EXISTS update_outbox_messages_updated_at ON outbox_messages;
+DROP TABLE IF EXISTS outbox_messages;
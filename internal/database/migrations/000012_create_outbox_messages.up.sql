@@ -0,0 +1,37 @@
+-- Создание таблицы outbox для надежной доставки уведомлений.
+-- Запись добавляется в той же транзакции, что и бизнес-изменение, отправку выполняет фоновый диспетчер.
+CREATE TABLE IF NOT EXISTS outbox_messages (
+    id BIGSERIAL PRIMARY KEY,
+    channel VARCHAR(20) NOT NULL DEFAULT 'email',
+    event_type VARCHAR(64) NOT NULL,
+    recipient VARCHAR(255) NOT NULL,
+    subject VARCHAR(255) NOT NULL DEFAULT '',
+    body TEXT NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending',
+    attempts INTEGER NOT NULL DEFAULT 0,
+    max_attempts INTEGER NOT NULL,
+    next_attempt_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    locked_until TIMESTAMPTZ NULL, -- Аренда сообщения диспетчером
+    last_error TEXT NOT NULL DEFAULT '',
+    sent_at TIMESTAMPTZ NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+
+    CONSTRAINT chk_outbox_status_valid CHECK (status IN ('pending', 'processing', 'sent', 'dead')),
+    CONSTRAINT chk_outbox_attempts_non_negative CHECK (attempts >= 0),
+    CONSTRAINT chk_outbox_max_attempts_positive CHECK (max_attempts > 0)
+);
+
+-- Создание индексов
+CREATE INDEX IF NOT EXISTS idx_outbox_messages_status ON outbox_messages(status);
+
+-- Индекс для выборки сообщений, готовых к отправке
+CREATE INDEX IF NOT EXISTS idx_outbox_messages_due ON outbox_messages(
+    next_attempt_at
+) WHERE status IN ('pending', 'processing');
+
+-- Триггер для автоматического обновления updated_at
+CREATE TRIGGER update_outbox_messages_updated_at
+    BEFORE UPDATE ON outbox_messages
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
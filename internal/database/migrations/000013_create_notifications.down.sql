@@ -0,0 +1,9 @@
+-- Удаление каналов уведомлений
+ALTER TABLE outbox_messages
+DROP CONSTRAINT chk_outbox_channel_valid,
+DROP COLUMN user_id;
+
+DROP TRIGGER IF EXISTS update_notification_endpoints_updated_at ON notification_endpoints;
+DROP TABLE IF EXISTS notification_endpoints;
+DROP TABLE IF EXISTS notification_preferences;
+DROP TABLE IF EXISTS notifications;
@@ -0,0 +1,52 @@
+-- Входящие уведомления пользователя (in-app inbox)
+CREATE TABLE IF NOT EXISTS notifications (
+    id BIGSERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    event_type VARCHAR(32) NOT NULL,
+    title VARCHAR(255) NOT NULL,
+    message TEXT NOT NULL,
+    read_at TIMESTAMPTZ NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+
+    CONSTRAINT chk_notifications_event_type_valid CHECK (event_type IN ('deposit', 'card_payment', 'overdue', 'credit_issued'))
+);
+
+CREATE INDEX IF NOT EXISTS idx_notifications_user_id ON notifications(user_id, id DESC);
+CREATE INDEX IF NOT EXISTS idx_notifications_unread ON notifications(user_id) WHERE read_at IS NULL;
+
+-- Настройки каналов уведомлений по типам событий.
+-- Отсутствие строки означает настройку по умолчанию (email и inbox включены).
+CREATE TABLE IF NOT EXISTS notification_preferences (
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    event_type VARCHAR(32) NOT NULL,
+    channel VARCHAR(20) NOT NULL,
+    enabled BOOLEAN NOT NULL,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+
+    PRIMARY KEY (user_id, event_type, channel),
+    CONSTRAINT chk_notification_preferences_event_type_valid CHECK (event_type IN ('deposit', 'card_payment', 'overdue', 'credit_issued')),
+    CONSTRAINT chk_notification_preferences_channel_valid CHECK (channel IN ('email', 'webhook', 'sms', 'inbox'))
+);
+
+-- Адреса доставки для внешних каналов: URL webhook (с секретом подписи) или номер телефона
+CREATE TABLE IF NOT EXISTS notification_endpoints (
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    channel VARCHAR(20) NOT NULL,
+    address VARCHAR(2048) NOT NULL,
+    secret VARCHAR(128) NOT NULL DEFAULT '',
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+
+    PRIMARY KEY (user_id, channel),
+    CONSTRAINT chk_notification_endpoints_channel_valid CHECK (channel IN ('webhook', 'sms'))
+);
+
+CREATE TRIGGER update_notification_endpoints_updated_at
+    BEFORE UPDATE ON notification_endpoints
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+-- Outbox доставляет webhook и SMS; получатель нужен для поиска секрета подписи
+ALTER TABLE outbox_messages
+ADD COLUMN user_id INTEGER NULL REFERENCES users(id) ON DELETE SET NULL,
+ADD CONSTRAINT chk_outbox_channel_valid CHECK (channel IN ('email', 'webhook', 'sms'));
@@ -0,0 +1,8 @@
+-- Удаление локализации писем
+ALTER TABLE outbox_messages
+DROP COLUMN template_version,
+DROP COLUMN text_body;
+
+ALTER TABLE users
+DROP CONSTRAINT chk_users_locale_valid,
+DROP COLUMN locale;
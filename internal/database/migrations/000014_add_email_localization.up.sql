@@ -0,0 +1,9 @@
+-- Язык уведомлений пользователя
+ALTER TABLE users
+ADD COLUMN locale VARCHAR(5) NOT NULL DEFAULT 'ru',
+ADD CONSTRAINT chk_users_locale_valid CHECK (locale IN ('ru', 'en'));
+
+-- Текстовая альтернатива письма и версия шаблона, по которому оно сформировано
+ALTER TABLE outbox_messages
+ADD COLUMN text_body TEXT NOT NULL DEFAULT '',
+ADD COLUMN template_version VARCHAR(16) NOT NULL DEFAULT '';
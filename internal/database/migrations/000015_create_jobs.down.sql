@@ -0,0 +1,4 @@
+-- Удаление реестра фоновых задач
+DROP TABLE IF EXISTS job_runs;
+DROP TRIGGER IF EXISTS update_jobs_updated_at ON jobs;
+DROP TABLE IF EXISTS jobs;
@@ -0,0 +1,38 @@
+-- Реестр фоновых задач. Состояние хранится в БД, чтобы пауза, ручной запуск
+-- и время следующего запуска были общими для всех реплик приложения.
+CREATE TABLE IF NOT EXISTS jobs (
+    name VARCHAR(64) PRIMARY KEY,
+    schedule VARCHAR(64) NOT NULL,
+    paused BOOLEAN NOT NULL DEFAULT FALSE,
+    next_run_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    trigger_requested_at TIMESTAMPTZ NULL, -- Ручной запуск, ожидающий выполнения лидером
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- История запусков задач
+CREATE TABLE IF NOT EXISTS job_runs (
+    id BIGSERIAL PRIMARY KEY,
+    job_name VARCHAR(64) NOT NULL REFERENCES jobs(name) ON DELETE CASCADE,
+    trigger VARCHAR(20) NOT NULL,
+    instance_id VARCHAR(128) NOT NULL, -- Реплика, выполнившая запуск
+    status VARCHAR(20) NOT NULL DEFAULT 'running',
+    started_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    finished_at TIMESTAMPTZ NULL,
+    processed INTEGER NOT NULL DEFAULT 0,
+    failed INTEGER NOT NULL DEFAULT 0,
+    error TEXT NOT NULL DEFAULT '',
+
+    CONSTRAINT chk_job_runs_trigger_valid CHECK (trigger IN ('schedule', 'manual')),
+    CONSTRAINT chk_job_runs_status_valid CHECK (status IN ('running', 'succeeded', 'failed', 'interrupted')),
+    CONSTRAINT chk_job_runs_counts_non_negative CHECK (processed >= 0 AND failed >= 0)
+);
+
+CREATE INDEX IF NOT EXISTS idx_job_runs_job_name ON job_runs(job_name, id DESC);
+CREATE INDEX IF NOT EXISTS idx_job_runs_running ON job_runs(status) WHERE status = 'running';
+
+-- Триггер для автоматического обновления updated_at
+CREATE TRIGGER update_jobs_updated_at
+    BEFORE UPDATE ON jobs
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
@@ -0,0 +1,13 @@
+-- Удаление закрытия операционного дня
+DELETE FROM transactions WHERE type = 'interest';
+ALTER TABLE transactions DROP CONSTRAINT chk_transaction_type_valid;
+ALTER TABLE transactions
+ADD CONSTRAINT chk_transaction_type_valid CHECK (
+    type IN ('deposit', 'withdrawal', 'transfer', 'payment', 'credit', 'credit_payment', 'penalty')
+);
+
+ALTER TABLE accounts DROP COLUMN accrued_interest;
+ALTER TABLE credits DROP COLUMN accrued_interest;
+
+DROP TABLE IF EXISTS interest_accruals;
+DROP TABLE IF EXISTS business_days;
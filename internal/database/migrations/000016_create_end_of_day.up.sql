@@ -0,0 +1,47 @@
+-- Закрытые операционные дни. Строка добавляется в той же транзакции, что и начисления дня,
+-- поэтому повторное закрытие дня ничего не делает.
+CREATE TABLE IF NOT EXISTS business_days (
+    business_date DATE PRIMARY KEY,
+    credits_accrued INTEGER NOT NULL DEFAULT 0,
+    accounts_accrued INTEGER NOT NULL DEFAULT 0,
+    credit_interest NUMERIC(18,6) NOT NULL DEFAULT 0,
+    savings_interest NUMERIC(18,6) NOT NULL DEFAULT 0,
+    savings_posted NUMERIC(15,2) NOT NULL DEFAULT 0, -- Проценты, зачисленные на счета (в конце месяца)
+    closed_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Ежедневные начисления процентов по кредитам и остаткам на счетах
+CREATE TABLE IF NOT EXISTS interest_accruals (
+    id BIGSERIAL PRIMARY KEY,
+    business_date DATE NOT NULL,
+    kind VARCHAR(20) NOT NULL,
+    credit_id INTEGER NULL REFERENCES credits(id) ON DELETE CASCADE,
+    account_id INTEGER NOT NULL REFERENCES accounts(id) ON DELETE CASCADE,
+    base_amount NUMERIC(15,2) NOT NULL, -- Остаток долга или баланс счета на конец дня
+    annual_rate NUMERIC(7,4) NOT NULL,
+    amount NUMERIC(18,6) NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+
+    CONSTRAINT chk_interest_accruals_kind_valid CHECK (kind IN ('credit', 'savings')),
+    CONSTRAINT chk_interest_accruals_credit CHECK ((kind = 'credit') = (credit_id IS NOT NULL)),
+    CONSTRAINT chk_interest_accruals_amount_non_negative CHECK (amount >= 0)
+);
+
+-- Не более одного начисления на кредит или счет за день
+CREATE UNIQUE INDEX IF NOT EXISTS idx_interest_accruals_credit_day ON interest_accruals(credit_id, business_date) WHERE kind = 'credit';
+CREATE UNIQUE INDEX IF NOT EXISTS idx_interest_accruals_savings_day ON interest_accruals(account_id, business_date) WHERE kind = 'savings';
+CREATE INDEX IF NOT EXISTS idx_interest_accruals_business_date ON interest_accruals(business_date);
+
+-- Накопленные, но еще не выплаченные проценты
+ALTER TABLE credits
+ADD COLUMN accrued_interest NUMERIC(18,6) NOT NULL DEFAULT 0;
+
+ALTER TABLE accounts
+ADD COLUMN accrued_interest NUMERIC(18,6) NOT NULL DEFAULT 0;
+
+-- Зачисление процентов на остаток записывается с типом interest
+ALTER TABLE transactions DROP CONSTRAINT chk_transaction_type_valid;
+ALTER TABLE transactions
+ADD CONSTRAINT chk_transaction_type_valid CHECK (
+    type IN ('deposit', 'withdrawal', 'transfer', 'payment', 'credit', 'credit_payment', 'penalty', 'interest')
+);
@@ -0,0 +1,23 @@
+-- Удаление вкладов
+DELETE FROM transactions WHERE type IN ('deposit_open', 'deposit_payout');
+ALTER TABLE transactions DROP CONSTRAINT chk_transaction_type_valid;
+ALTER TABLE transactions
+ADD CONSTRAINT chk_transaction_type_valid CHECK (
+    type IN ('deposit', 'withdrawal', 'transfer', 'payment', 'credit', 'credit_payment', 'penalty', 'interest')
+);
+
+ALTER TABLE business_days
+DROP COLUMN deposits_matured,
+DROP COLUMN deposit_interest,
+DROP COLUMN deposits_accrued;
+
+DELETE FROM interest_accruals WHERE kind = 'deposit';
+DROP INDEX IF EXISTS idx_interest_accruals_deposit_day;
+ALTER TABLE interest_accruals DROP CONSTRAINT chk_interest_accruals_deposit;
+ALTER TABLE interest_accruals DROP CONSTRAINT chk_interest_accruals_kind_valid;
+ALTER TABLE interest_accruals
+ADD CONSTRAINT chk_interest_accruals_kind_valid CHECK (kind IN ('credit', 'savings'));
+ALTER TABLE interest_accruals DROP COLUMN deposit_id;
+
+DROP TRIGGER IF EXISTS update_deposits_updated_at ON deposits;
+DROP TABLE IF EXISTS deposits;
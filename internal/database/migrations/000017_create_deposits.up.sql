@@ -0,0 +1,59 @@
+-- Вклады и накопительные счета. Ставка фиксируется при открытии от ключевой ставки ЦБ РФ.
+CREATE TABLE IF NOT EXISTS deposits (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    account_id INTEGER NOT NULL REFERENCES accounts(id) ON DELETE CASCADE, -- Счет списания и выплаты
+    product VARCHAR(30) NOT NULL,
+    kind VARCHAR(20) NOT NULL,
+    amount NUMERIC(15,2) NOT NULL, -- Первоначальная сумма
+    balance NUMERIC(15,2) NOT NULL, -- Сумма с капитализированными процентами
+    interest_rate NUMERIC(7,4) NOT NULL,
+    capitalization VARCHAR(10) NOT NULL,
+    term_months INTEGER NOT NULL DEFAULT 0,
+    opened_on DATE NOT NULL,
+    maturity_date DATE NULL, -- Только для срочных вкладов
+    accrued_interest NUMERIC(18,6) NOT NULL DEFAULT 0,
+    interest_paid NUMERIC(15,2) NOT NULL DEFAULT 0,
+    payout NUMERIC(15,2) NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'active',
+    closed_at TIMESTAMPTZ NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+
+    CONSTRAINT chk_deposits_kind_valid CHECK (kind IN ('term', 'savings')),
+    CONSTRAINT chk_deposits_capitalization_valid CHECK (capitalization IN ('monthly', 'end')),
+    CONSTRAINT chk_deposits_status_valid CHECK (status IN ('active', 'matured', 'closed')),
+    CONSTRAINT chk_deposits_amount_positive CHECK (amount > 0 AND balance >= 0),
+    CONSTRAINT chk_deposits_maturity CHECK ((kind = 'term') = (maturity_date IS NOT NULL))
+);
+
+CREATE INDEX IF NOT EXISTS idx_deposits_user_id ON deposits(user_id);
+CREATE INDEX IF NOT EXISTS idx_deposits_active_maturity ON deposits(maturity_date) WHERE status = 'active';
+
+-- Триггер для автоматического обновления updated_at
+CREATE TRIGGER update_deposits_updated_at
+    BEFORE UPDATE ON deposits
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+-- Ежедневные начисления по вкладам
+ALTER TABLE interest_accruals ADD COLUMN deposit_id INTEGER NULL REFERENCES deposits(id) ON DELETE CASCADE;
+ALTER TABLE interest_accruals DROP CONSTRAINT chk_interest_accruals_kind_valid;
+ALTER TABLE interest_accruals
+ADD CONSTRAINT chk_interest_accruals_kind_valid CHECK (kind IN ('credit', 'savings', 'deposit'));
+ALTER TABLE interest_accruals
+ADD CONSTRAINT chk_interest_accruals_deposit CHECK ((kind = 'deposit') = (deposit_id IS NOT NULL));
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_interest_accruals_deposit_day ON interest_accruals(deposit_id, business_date) WHERE kind = 'deposit';
+
+ALTER TABLE business_days
+ADD COLUMN deposits_accrued INTEGER NOT NULL DEFAULT 0,
+ADD COLUMN deposit_interest NUMERIC(18,6) NOT NULL DEFAULT 0,
+ADD COLUMN deposits_matured INTEGER NOT NULL DEFAULT 0;
+
+-- Открытие вклада и выплата по нему
+ALTER TABLE transactions DROP CONSTRAINT chk_transaction_type_valid;
+ALTER TABLE transactions
+ADD CONSTRAINT chk_transaction_type_valid CHECK (
+    type IN ('deposit', 'withdrawal', 'transfer', 'payment', 'credit', 'credit_payment', 'penalty', 'interest', 'deposit_open', 'deposit_payout')
+);
@@ -0,0 +1,18 @@
+-- Удаление постоянных поручений
+DELETE FROM notification_preferences WHERE event_type = 'standing_order_failed';
+ALTER TABLE notification_preferences DROP CONSTRAINT chk_notification_preferences_event_type_valid;
+ALTER TABLE notification_preferences
+ADD CONSTRAINT chk_notification_preferences_event_type_valid CHECK (
+    event_type IN ('deposit', 'card_payment', 'overdue', 'credit_issued')
+);
+
+DELETE FROM notifications WHERE event_type = 'standing_order_failed';
+ALTER TABLE notifications DROP CONSTRAINT chk_notifications_event_type_valid;
+ALTER TABLE notifications
+ADD CONSTRAINT chk_notifications_event_type_valid CHECK (
+    event_type IN ('deposit', 'card_payment', 'overdue', 'credit_issued')
+);
+
+DROP TABLE IF EXISTS standing_order_executions;
+DROP TRIGGER IF EXISTS update_standing_orders_updated_at ON standing_orders;
+DROP TABLE IF EXISTS standing_orders;
@@ -0,0 +1,70 @@
+-- Регулярные и отложенные переводы (постоянные поручения).
+-- next_run_date - дата ближайшего платежа; attempts и retry_at описывают повторы при нехватке средств.
+CREATE TABLE IF NOT EXISTS standing_orders (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    from_account_id INTEGER NOT NULL REFERENCES accounts(id) ON DELETE CASCADE,
+    to_account_id INTEGER NOT NULL REFERENCES accounts(id) ON DELETE CASCADE,
+    amount NUMERIC(15,2) NOT NULL,
+    description VARCHAR(255) NOT NULL DEFAULT '',
+    schedule VARCHAR(10) NOT NULL,
+    day_of_week SMALLINT NOT NULL DEFAULT 0, -- 1 - понедельник ... 7 - воскресенье
+    day_of_month SMALLINT NOT NULL DEFAULT 0, -- В коротких месяцах платеж в последний день
+    start_date DATE NOT NULL,
+    end_date DATE NULL,
+    next_run_date DATE NULL,
+    attempts INTEGER NOT NULL DEFAULT 0,
+    retry_at TIMESTAMPTZ NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'active',
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+
+    CONSTRAINT chk_standing_orders_schedule_valid CHECK (schedule IN ('once', 'weekly', 'monthly')),
+    CONSTRAINT chk_standing_orders_status_valid CHECK (status IN ('active', 'paused', 'completed', 'cancelled')),
+    CONSTRAINT chk_standing_orders_amount_positive CHECK (amount > 0),
+    CONSTRAINT chk_standing_orders_accounts_differ CHECK (from_account_id <> to_account_id),
+    CONSTRAINT chk_standing_orders_day_of_week CHECK (day_of_week BETWEEN 0 AND 7),
+    CONSTRAINT chk_standing_orders_day_of_month CHECK (day_of_month BETWEEN 0 AND 31),
+    CONSTRAINT chk_standing_orders_attempts_non_negative CHECK (attempts >= 0)
+);
+
+CREATE INDEX IF NOT EXISTS idx_standing_orders_user_id ON standing_orders(user_id);
+CREATE INDEX IF NOT EXISTS idx_standing_orders_due ON standing_orders(next_run_date) WHERE status = 'active';
+
+-- Триггер для автоматического обновления updated_at
+CREATE TRIGGER update_standing_orders_updated_at
+    BEFORE UPDATE ON standing_orders
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+-- История исполнения: каждая попытка платежа по поручению
+CREATE TABLE IF NOT EXISTS standing_order_executions (
+    id BIGSERIAL PRIMARY KEY,
+    standing_order_id INTEGER NOT NULL REFERENCES standing_orders(id) ON DELETE CASCADE,
+    scheduled_date DATE NOT NULL,
+    attempt INTEGER NOT NULL,
+    status VARCHAR(20) NOT NULL,
+    amount NUMERIC(15,2) NOT NULL,
+    error TEXT NOT NULL DEFAULT '',
+    executed_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    CONSTRAINT chk_standing_order_executions_status_valid CHECK (status IN ('succeeded', 'retrying', 'failed')),
+    CONSTRAINT chk_standing_order_executions_attempt_positive CHECK (attempt > 0)
+);
+
+-- Не более одной записи на попытку платежа
+CREATE UNIQUE INDEX IF NOT EXISTS idx_standing_order_executions_attempt
+    ON standing_order_executions(standing_order_id, scheduled_date, attempt);
+
+-- Уведомление о неисполненном платеже по поручению
+ALTER TABLE notifications DROP CONSTRAINT chk_notifications_event_type_valid;
+ALTER TABLE notifications
+ADD CONSTRAINT chk_notifications_event_type_valid CHECK (
+    event_type IN ('deposit', 'card_payment', 'overdue', 'credit_issued', 'standing_order_failed')
+);
+
+ALTER TABLE notification_preferences DROP CONSTRAINT chk_notification_preferences_event_type_valid;
+ALTER TABLE notification_preferences
+ADD CONSTRAINT chk_notification_preferences_event_type_valid CHECK (
+    event_type IN ('deposit', 'card_payment', 'overdue', 'credit_issued', 'standing_order_failed')
+);
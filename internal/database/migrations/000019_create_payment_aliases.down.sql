@@ -0,0 +1,3 @@
+-- Удаление псевдонимов для переводов
+DROP INDEX IF EXISTS idx_transactions_transfer_to;
+DROP TABLE IF EXISTS payment_aliases;
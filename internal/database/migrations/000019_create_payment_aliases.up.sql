@@ -0,0 +1,19 @@
+-- Псевдонимы для входящих переводов: телефон или email клиента, привязанный к счету зачисления.
+-- Один телефон или email может указывать только на один счет в банке.
+CREATE TABLE IF NOT EXISTS payment_aliases (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    alias_type VARCHAR(10) NOT NULL,
+    value VARCHAR(255) NOT NULL,
+    account_id INTEGER NOT NULL REFERENCES accounts(id) ON DELETE CASCADE,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+
+    CONSTRAINT chk_payment_aliases_type_valid CHECK (alias_type IN ('phone', 'email'))
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_payment_aliases_value ON payment_aliases(alias_type, value);
+CREATE INDEX IF NOT EXISTS idx_payment_aliases_user_id ON payment_aliases(user_id);
+
+-- Поиск переводов клиента конкретному получателю для лимитов новых получателей
+CREATE INDEX IF NOT EXISTS idx_transactions_transfer_to ON transactions(to_account, created_at)
+    WHERE type = 'transfer' AND status = 'completed';
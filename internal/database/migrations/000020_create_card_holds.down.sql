@@ -0,0 +1,13 @@
+-- Удаление холдов по картам
+DELETE FROM transactions WHERE type = 'refund';
+ALTER TABLE transactions DROP CONSTRAINT chk_transaction_type_valid;
+ALTER TABLE transactions
+ADD CONSTRAINT chk_transaction_type_valid CHECK (
+    type IN ('deposit', 'withdrawal', 'transfer', 'payment', 'credit', 'credit_payment', 'penalty', 'interest', 'deposit_open', 'deposit_payout')
+);
+
+DROP TRIGGER IF EXISTS update_card_holds_updated_at ON card_holds;
+DROP TABLE IF EXISTS card_holds;
+
+ALTER TABLE accounts DROP CONSTRAINT chk_accounts_held_amount;
+ALTER TABLE accounts DROP COLUMN held_amount;
@@ -0,0 +1,43 @@
+-- Блокировки средств (холды) по авторизациям карт.
+-- balance - учетный остаток, held_amount - сумма активных холдов; доступный остаток = balance - held_amount.
+ALTER TABLE accounts ADD COLUMN held_amount NUMERIC(15,2) NOT NULL DEFAULT 0;
+ALTER TABLE accounts
+ADD CONSTRAINT chk_accounts_held_amount CHECK (held_amount >= 0);
+
+CREATE TABLE IF NOT EXISTS card_holds (
+    id SERIAL PRIMARY KEY,
+    card_id INTEGER NOT NULL REFERENCES cards(id) ON DELETE CASCADE,
+    account_id INTEGER NOT NULL REFERENCES accounts(id) ON DELETE CASCADE,
+    transaction_id INTEGER NOT NULL REFERENCES transactions(id) ON DELETE CASCADE, -- Платеж в статусе pending до списания
+    merchant_id VARCHAR(100) NOT NULL,
+    description VARCHAR(255) NOT NULL DEFAULT '',
+    amount NUMERIC(15,2) NOT NULL, -- Авторизованная сумма
+    captured_amount NUMERIC(15,2) NOT NULL DEFAULT 0,
+    refunded_amount NUMERIC(15,2) NOT NULL DEFAULT 0,
+    status VARCHAR(20) NOT NULL DEFAULT 'authorized',
+    expires_at TIMESTAMPTZ NOT NULL,
+    captured_at TIMESTAMPTZ NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+
+    CONSTRAINT chk_card_holds_status_valid CHECK (status IN ('authorized', 'captured', 'voided', 'expired')),
+    CONSTRAINT chk_card_holds_amount_positive CHECK (amount > 0),
+    CONSTRAINT chk_card_holds_captured CHECK (captured_amount >= 0 AND captured_amount <= amount),
+    CONSTRAINT chk_card_holds_refunded CHECK (refunded_amount >= 0 AND refunded_amount <= captured_amount)
+);
+
+CREATE INDEX IF NOT EXISTS idx_card_holds_account_id ON card_holds(account_id, id DESC);
+CREATE INDEX IF NOT EXISTS idx_card_holds_expiring ON card_holds(expires_at) WHERE status = 'authorized';
+
+-- Триггер для автоматического обновления updated_at
+CREATE TRIGGER update_card_holds_updated_at
+    BEFORE UPDATE ON card_holds
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+-- Возврат по списанному платежу
+ALTER TABLE transactions DROP CONSTRAINT chk_transaction_type_valid;
+ALTER TABLE transactions
+ADD CONSTRAINT chk_transaction_type_valid CHECK (
+    type IN ('deposit', 'withdrawal', 'transfer', 'payment', 'credit', 'credit_payment', 'penalty', 'interest', 'deposit_open', 'deposit_payout', 'refund')
+);
@@ -0,0 +1,2 @@
+-- Удаление журнала сообщений шлюза карточных операций
+DROP TABLE IF EXISTS card_gateway_messages;
@@ -0,0 +1,28 @@
+-- Сообщения шлюза карточных операций (ISO 8583): запрос и код ответа.
+-- Уникальность (mti, rrn, terminal_id) отсекает повторную обработку ретрансляций;
+-- по rrn и терминалу реверс находит исходную операцию. Номер карты не сохраняется.
+CREATE TABLE IF NOT EXISTS card_gateway_messages (
+    id BIGSERIAL PRIMARY KEY,
+    mti VARCHAR(4) NOT NULL,
+    stan VARCHAR(6) NOT NULL DEFAULT '',
+    rrn VARCHAR(12) NOT NULL,
+    terminal_id VARCHAR(8) NOT NULL,
+    merchant_id VARCHAR(15) NOT NULL DEFAULT '',
+    amount NUMERIC(15,2) NOT NULL DEFAULT 0,
+    card_id INTEGER NULL REFERENCES cards(id) ON DELETE SET NULL,
+    hold_id INTEGER NULL REFERENCES card_holds(id) ON DELETE SET NULL,
+    response_code VARCHAR(2) NOT NULL DEFAULT '', -- Пусто, пока запрос обрабатывается
+    auth_code VARCHAR(6) NOT NULL DEFAULT '',
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_card_gateway_messages_reference ON card_gateway_messages(mti, rrn, terminal_id);
+CREATE INDEX IF NOT EXISTS idx_card_gateway_messages_original ON card_gateway_messages(rrn, terminal_id)
+    WHERE response_code = '00';
+
+-- Триггер для автоматического обновления updated_at
+CREATE TRIGGER update_card_gateway_messages_updated_at
+    BEFORE UPDATE ON card_gateway_messages
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
@@ -0,0 +1,4 @@
+-- Удаление поиска карт по номеру и счетчика неверных CVV
+ALTER TABLE cards DROP COLUMN IF EXISTS cvv_failed_attempts;
+DROP INDEX IF EXISTS idx_cards_pan_hash;
+ALTER TABLE cards DROP COLUMN IF EXISTS pan_hash;
@@ -0,0 +1,7 @@
+-- Поиск карты по номеру: номера хранятся зашифрованными, поэтому ищем по HMAC номера (ключ — из ключа шифрования карт).
+-- Для карт, выпущенных до миграции, хеш заполняется при старте приложения.
+ALTER TABLE cards ADD COLUMN pan_hash VARCHAR(64) NOT NULL DEFAULT '';
+CREATE UNIQUE INDEX IF NOT EXISTS idx_cards_pan_hash ON cards(pan_hash) WHERE pan_hash <> '';
+
+-- Неверные CVV подряд: при достижении лимита карта блокируется
+ALTER TABLE cards ADD COLUMN cvv_failed_attempts INTEGER NOT NULL DEFAULT 0;
@@ -0,0 +1,16 @@
+-- Удаление ТСП и расчетов с ними
+DROP TABLE IF EXISTS merchant_settlements;
+
+DELETE FROM transactions WHERE type = 'interchange_fee';
+ALTER TABLE transactions DROP CONSTRAINT chk_transaction_type_valid;
+ALTER TABLE transactions
+ADD CONSTRAINT chk_transaction_type_valid CHECK (
+    type IN ('deposit', 'withdrawal', 'transfer', 'payment', 'credit', 'credit_payment', 'penalty', 'interest', 'deposit_open', 'deposit_payout', 'refund')
+);
+
+DROP INDEX IF EXISTS idx_transactions_merchant;
+ALTER TABLE transactions DROP COLUMN IF EXISTS mcc;
+ALTER TABLE transactions DROP COLUMN IF EXISTS merchant_id;
+
+DROP TRIGGER IF EXISTS update_merchants_updated_at ON merchants;
+DROP TABLE IF EXISTS merchants;
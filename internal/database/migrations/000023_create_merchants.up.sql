@@ -0,0 +1,50 @@
+-- Торгово-сервисные предприятия (ТСП), принимающие карты банка.
+-- code совпадает с merchant_id платежей картой и полем 42 сообщений ISO 8583.
+CREATE TABLE IF NOT EXISTS merchants (
+    id SERIAL PRIMARY KEY,
+    code VARCHAR(100) NOT NULL UNIQUE,
+    name VARCHAR(255) NOT NULL,
+    mcc CHAR(4) NOT NULL,
+    settlement_account_id INTEGER NOT NULL REFERENCES accounts(id),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+
+    CONSTRAINT chk_merchants_mcc_valid CHECK (mcc ~ '^[0-9]{4}$')
+);
+
+-- Триггер для автоматического обновления updated_at
+CREATE TRIGGER update_merchants_updated_at
+    BEFORE UPDATE ON merchants
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+-- Платежи, возвраты и комиссии по ТСП; MCC копируется в транзакцию для аналитики расходов
+ALTER TABLE transactions ADD COLUMN merchant_id INTEGER NULL REFERENCES merchants(id);
+ALTER TABLE transactions ADD COLUMN mcc CHAR(4) NULL;
+
+CREATE INDEX IF NOT EXISTS idx_transactions_merchant ON transactions(merchant_id, updated_at)
+    WHERE merchant_id IS NOT NULL;
+
+-- Межбанковская комиссия списывается с расчетного счета ТСП
+ALTER TABLE transactions DROP CONSTRAINT chk_transaction_type_valid;
+ALTER TABLE transactions
+ADD CONSTRAINT chk_transaction_type_valid CHECK (
+    type IN ('deposit', 'withdrawal', 'transfer', 'payment', 'credit', 'credit_payment', 'penalty', 'interest', 'deposit_open', 'deposit_payout', 'refund', 'interchange_fee')
+);
+
+-- Ежедневные итоги расчетов с ТСП; повторный расчет дня ничего не меняет
+CREATE TABLE IF NOT EXISTS merchant_settlements (
+    id BIGSERIAL PRIMARY KEY,
+    merchant_id INTEGER NOT NULL REFERENCES merchants(id) ON DELETE CASCADE,
+    business_date DATE NOT NULL,
+    payments_count INTEGER NOT NULL DEFAULT 0,
+    gross_amount NUMERIC(15,2) NOT NULL DEFAULT 0,
+    refund_amount NUMERIC(15,2) NOT NULL DEFAULT 0,
+    fee_amount NUMERIC(15,2) NOT NULL DEFAULT 0,
+    net_amount NUMERIC(15,2) NOT NULL DEFAULT 0,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    CONSTRAINT uq_merchant_settlements_day UNIQUE (merchant_id, business_date)
+);
+
+CREATE INDEX IF NOT EXISTS idx_merchant_settlements_business_date ON merchant_settlements(business_date);
@@ -0,0 +1,13 @@
+-- Удаление программы кэшбэка
+DROP TABLE IF EXISTS cashback_payouts;
+DROP TABLE IF EXISTS cashback_entries;
+
+DELETE FROM transactions WHERE type = 'cashback';
+ALTER TABLE transactions DROP CONSTRAINT chk_transaction_type_valid;
+ALTER TABLE transactions
+ADD CONSTRAINT chk_transaction_type_valid CHECK (
+    type IN ('deposit', 'withdrawal', 'transfer', 'payment', 'credit', 'credit_payment', 'penalty', 'interest', 'deposit_open', 'deposit_payout', 'refund', 'interchange_fee')
+);
+
+DROP TRIGGER IF EXISTS update_cashback_rules_updated_at ON cashback_rules;
+DROP TABLE IF EXISTS cashback_rules;
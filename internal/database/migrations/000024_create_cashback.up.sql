@@ -0,0 +1,66 @@
+-- Правила кэшбэка по MCC или категории расходов; платежи без правила получают базовую ставку
+CREATE TABLE IF NOT EXISTS cashback_rules (
+    id SERIAL PRIMARY KEY,
+    mcc CHAR(4) NULL,
+    category VARCHAR(32) NULL,
+    rate NUMERIC(5,2) NOT NULL,
+    monthly_cap NUMERIC(15,2) NOT NULL DEFAULT 0,
+    active BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+
+    CONSTRAINT chk_cashback_rules_target CHECK ((mcc IS NULL) <> (category IS NULL)),
+    CONSTRAINT chk_cashback_rules_rate CHECK (rate >= 0 AND rate <= 100),
+    CONSTRAINT chk_cashback_rules_cap CHECK (monthly_cap >= 0)
+);
+
+-- Одно действующее правило на MCC и на категорию
+CREATE UNIQUE INDEX IF NOT EXISTS uq_cashback_rules_mcc ON cashback_rules(mcc) WHERE active AND mcc IS NOT NULL;
+CREATE UNIQUE INDEX IF NOT EXISTS uq_cashback_rules_category ON cashback_rules(category) WHERE active AND category IS NOT NULL;
+
+CREATE TRIGGER update_cashback_rules_updated_at
+    BEFORE UPDATE ON cashback_rules
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+-- Начисления кэшбэка по списанным платежам и их сторно при возвратах (отрицательная сумма).
+-- period - первый день месяца, за который кэшбэк будет выплачен.
+CREATE TABLE IF NOT EXISTS cashback_entries (
+    id BIGSERIAL PRIMARY KEY,
+    card_id INTEGER NOT NULL REFERENCES cards(id) ON DELETE CASCADE,
+    account_id INTEGER NOT NULL REFERENCES accounts(id),
+    transaction_id INTEGER NOT NULL REFERENCES transactions(id),
+    rule_id INTEGER NULL REFERENCES cashback_rules(id),
+    kind VARCHAR(20) NOT NULL,
+    period DATE NOT NULL,
+    base_amount NUMERIC(15,2) NOT NULL,
+    rate NUMERIC(5,2) NOT NULL,
+    amount NUMERIC(15,2) NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    CONSTRAINT chk_cashback_entries_kind CHECK (kind IN ('accrual', 'reversal'))
+);
+
+-- Кэшбэк за платеж начисляется один раз
+CREATE UNIQUE INDEX IF NOT EXISTS uq_cashback_entries_accrual ON cashback_entries(transaction_id) WHERE kind = 'accrual';
+CREATE INDEX IF NOT EXISTS idx_cashback_entries_card_period ON cashback_entries(card_id, period);
+
+-- Ежемесячные выплаты кэшбэка на счет карты
+CREATE TABLE IF NOT EXISTS cashback_payouts (
+    id BIGSERIAL PRIMARY KEY,
+    card_id INTEGER NOT NULL REFERENCES cards(id) ON DELETE CASCADE,
+    account_id INTEGER NOT NULL REFERENCES accounts(id),
+    period DATE NOT NULL,
+    amount NUMERIC(15,2) NOT NULL,
+    transaction_id INTEGER NULL REFERENCES transactions(id),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    CONSTRAINT uq_cashback_payouts_period UNIQUE (card_id, period)
+);
+
+-- Выплата кэшбэка зачисляется транзакцией отдельного типа
+ALTER TABLE transactions DROP CONSTRAINT chk_transaction_type_valid;
+ALTER TABLE transactions
+ADD CONSTRAINT chk_transaction_type_valid CHECK (
+    type IN ('deposit', 'withdrawal', 'transfer', 'payment', 'credit', 'credit_payment', 'penalty', 'interest', 'deposit_open', 'deposit_payout', 'refund', 'interchange_fee', 'cashback')
+);
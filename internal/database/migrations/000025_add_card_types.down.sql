@@ -0,0 +1,4 @@
+-- Удаление типов карт и привязки к ТСП
+ALTER TABLE cards DROP COLUMN IF EXISTS merchant_lock;
+ALTER TABLE cards DROP CONSTRAINT IF EXISTS chk_card_type_valid;
+ALTER TABLE cards DROP COLUMN IF EXISTS card_type;
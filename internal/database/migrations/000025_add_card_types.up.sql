@@ -0,0 +1,7 @@
+-- Тип карты: физическая, виртуальная или одноразовая (закрывается после первого списания).
+-- Номера разных типов выпускаются из разных BIN.
+ALTER TABLE cards ADD COLUMN card_type VARCHAR(20) NOT NULL DEFAULT 'physical';
+ALTER TABLE cards ADD CONSTRAINT chk_card_type_valid CHECK (card_type IN ('physical', 'virtual', 'single_use'));
+
+-- Привязка карты к одному ТСП (код merchant_id платежа); NULL — платежи в любое ТСП
+ALTER TABLE cards ADD COLUMN merchant_lock VARCHAR(100);
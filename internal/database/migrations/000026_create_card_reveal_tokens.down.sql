@@ -0,0 +1,2 @@
+-- Удаление токенов показа реквизитов карты
+DROP TABLE IF EXISTS card_reveal_tokens;
@@ -0,0 +1,14 @@
+-- Одноразовые токены показа реквизитов карты: выдаются после повторной проверки пароля,
+-- действуют несколько секунд и погашаются при первом показе. Хранится только SHA-256 токена.
+CREATE TABLE IF NOT EXISTS card_reveal_tokens (
+    id BIGSERIAL PRIMARY KEY,
+    card_id INTEGER NOT NULL REFERENCES cards(id) ON DELETE CASCADE,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    token_hash VARCHAR(64) NOT NULL UNIQUE,
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    used_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Лимит показов считается по токенам карты за окно
+CREATE INDEX IF NOT EXISTS idx_card_reveal_tokens_card_created ON card_reveal_tokens(card_id, created_at);
@@ -0,0 +1,4 @@
+-- Удаление PIN карт и счетчика неверных PIN
+ALTER TABLE cards DROP COLUMN IF EXISTS pin_failed_attempts;
+ALTER TABLE cards DROP COLUMN IF EXISTS pin_salt;
+ALTER TABLE cards DROP COLUMN IF EXISTS pin_hash;
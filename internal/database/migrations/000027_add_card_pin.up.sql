@@ -0,0 +1,5 @@
+-- PIN карты: хранится только bcrypt от соли карты и PIN-блока ISO 9564 (формат 0), PIN не восстанавливается.
+-- Неверные PIN подряд: при достижении лимита карта блокируется до разблокировки оператором
+ALTER TABLE cards ADD COLUMN pin_hash VARCHAR(255) NOT NULL DEFAULT '';
+ALTER TABLE cards ADD COLUMN pin_salt VARCHAR(64) NOT NULL DEFAULT '';
+ALTER TABLE cards ADD COLUMN pin_failed_attempts INTEGER NOT NULL DEFAULT 0;
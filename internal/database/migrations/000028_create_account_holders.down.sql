@@ -0,0 +1,2 @@
+-- Удаление совладельцев счетов
+DROP TABLE IF EXISTS account_holders;
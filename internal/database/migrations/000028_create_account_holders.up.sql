@@ -0,0 +1,17 @@
+-- Совладельцы счетов: держатели совместного счета (joint) имеют все права, кроме закрытия счета
+-- и управления совладельцами; дополнительные держатели (secondary) — только выданные права.
+CREATE TABLE IF NOT EXISTS account_holders (
+    id SERIAL PRIMARY KEY,
+    account_id INTEGER NOT NULL REFERENCES accounts(id) ON DELETE CASCADE,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    role VARCHAR(20) NOT NULL,
+    permissions TEXT[] NOT NULL DEFAULT '{}',
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    CONSTRAINT uq_account_holders_account_user UNIQUE (account_id, user_id),
+    CONSTRAINT chk_account_holder_role CHECK (role IN ('joint', 'secondary')),
+    CONSTRAINT chk_account_holder_permissions CHECK (permissions <@ ARRAY['view', 'operate', 'manage']::TEXT[])
+);
+
+-- Счета, доступные пользователю как совладельцу
+CREATE INDEX IF NOT EXISTS idx_account_holders_user_id ON account_holders(user_id);
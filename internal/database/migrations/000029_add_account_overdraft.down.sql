@@ -0,0 +1,25 @@
+-- Удаление овердрафта
+ALTER TABLE business_days
+DROP COLUMN overdraft_charged,
+DROP COLUMN overdraft_interest,
+DROP COLUMN overdrafts_accrued;
+
+DELETE FROM interest_accruals WHERE kind = 'overdraft';
+DROP INDEX IF EXISTS idx_interest_accruals_overdraft_day;
+ALTER TABLE interest_accruals DROP CONSTRAINT chk_interest_accruals_kind_valid;
+ALTER TABLE interest_accruals
+ADD CONSTRAINT chk_interest_accruals_kind_valid CHECK (kind IN ('credit', 'savings', 'deposit'));
+
+-- Отрицательный остаток без овердрафта недопустим: задолженность по овердрафту обнуляется
+UPDATE accounts SET balance = 0 WHERE balance < 0;
+
+ALTER TABLE accounts DROP CONSTRAINT chk_accounts_overdraft_limit;
+ALTER TABLE accounts DROP CONSTRAINT chk_accounts_product_valid;
+ALTER TABLE accounts DROP CONSTRAINT chk_balance_positive;
+ALTER TABLE accounts
+ADD CONSTRAINT chk_balance_positive CHECK (balance >= 0);
+
+ALTER TABLE accounts
+DROP COLUMN overdraft_interest,
+DROP COLUMN overdraft_limit,
+DROP COLUMN product;
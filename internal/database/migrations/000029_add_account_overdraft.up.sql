@@ -0,0 +1,24 @@
+-- Продукт счета и лимит овердрафта. Отрицательный остаток допускается только на счетах с подключенным
+-- овердрафтом; проценты за пользование овердрафтом списываются ежедневно в пределах лимита (см. 000035).
+ALTER TABLE accounts
+ADD COLUMN product VARCHAR(20) NOT NULL DEFAULT 'checking',
+ADD COLUMN overdraft_limit NUMERIC(15,2) NOT NULL DEFAULT 0,
+ADD COLUMN overdraft_interest NUMERIC(18,6) NOT NULL DEFAULT 0; -- Начислено, но еще не списано (доли копейки)
+
+ALTER TABLE accounts DROP CONSTRAINT chk_balance_positive;
+ALTER TABLE accounts
+ADD CONSTRAINT chk_balance_positive CHECK (balance >= 0 OR overdraft_limit > 0),
+ADD CONSTRAINT chk_accounts_product_valid CHECK (product IN ('checking', 'savings')),
+ADD CONSTRAINT chk_accounts_overdraft_limit CHECK (overdraft_limit >= 0);
+
+-- Ежедневные начисления процентов за овердрафт
+ALTER TABLE interest_accruals DROP CONSTRAINT chk_interest_accruals_kind_valid;
+ALTER TABLE interest_accruals
+ADD CONSTRAINT chk_interest_accruals_kind_valid CHECK (kind IN ('credit', 'savings', 'deposit', 'overdraft'));
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_interest_accruals_overdraft_day ON interest_accruals(account_id, business_date) WHERE kind = 'overdraft';
+
+ALTER TABLE business_days
+ADD COLUMN overdrafts_accrued INTEGER NOT NULL DEFAULT 0,
+ADD COLUMN overdraft_interest NUMERIC(18,6) NOT NULL DEFAULT 0,
+ADD COLUMN overdraft_charged NUMERIC(15,2) NOT NULL DEFAULT 0;
@@ -0,0 +1,3 @@
+-- Удаление очереди проверки операций антифрода
+DROP TRIGGER IF EXISTS update_fraud_reviews_updated_at ON fraud_reviews;
+DROP TABLE IF EXISTS fraud_reviews;
@@ -0,0 +1,37 @@
+-- Очередь проверки операций, отложенных правилами антифрода. Одобренная оператором
+-- проверка разрешает клиенту один раз повторить ту же операцию (consumed_at).
+CREATE TABLE IF NOT EXISTS fraud_reviews (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    operation_type VARCHAR(20) NOT NULL,
+    account_id INTEGER NOT NULL REFERENCES accounts(id) ON DELETE CASCADE,
+    to_account_id INTEGER REFERENCES accounts(id) ON DELETE SET NULL,
+    card_id INTEGER REFERENCES cards(id) ON DELETE SET NULL,
+    merchant_id VARCHAR(100) NOT NULL DEFAULT '',
+    amount DECIMAL(15,2) NOT NULL,
+    ip VARCHAR(64) NOT NULL DEFAULT '',
+    country VARCHAR(2) NOT NULL DEFAULT '',
+    rules JSONB NOT NULL DEFAULT '[]',
+    status VARCHAR(20) NOT NULL DEFAULT 'pending',
+    reviewer_id INTEGER REFERENCES users(id) ON DELETE SET NULL,
+    comment TEXT NOT NULL DEFAULT '',
+    reviewed_at TIMESTAMP WITH TIME ZONE,
+    consumed_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    CONSTRAINT chk_fraud_review_operation CHECK (operation_type IN ('transfer', 'withdrawal', 'card_payment')),
+    CONSTRAINT chk_fraud_review_status CHECK (status IN ('pending', 'approved', 'declined')),
+    CONSTRAINT chk_fraud_review_amount CHECK (amount > 0)
+);
+
+-- Очередь оператора
+CREATE INDEX IF NOT EXISTS idx_fraud_reviews_status ON fraud_reviews(status, created_at);
+-- Поиск отложенной или одобренной проверки той же операции клиента
+CREATE INDEX IF NOT EXISTS idx_fraud_reviews_user_id ON fraud_reviews(user_id, status);
+
+-- Триггер для автоматического обновления updated_at
+CREATE TRIGGER update_fraud_reviews_updated_at
+    BEFORE UPDATE ON fraud_reviews
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
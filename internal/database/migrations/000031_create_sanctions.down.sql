@@ -0,0 +1,6 @@
+-- Удаление санкционных списков и очереди проверки совпадений
+DROP TRIGGER IF EXISTS update_sanctions_hits_updated_at ON sanctions_hits;
+DROP TRIGGER IF EXISTS update_sanctions_lists_updated_at ON sanctions_lists;
+DROP TABLE IF EXISTS sanctions_hits;
+DROP TABLE IF EXISTS sanctions_entries;
+DROP TABLE IF EXISTS sanctions_lists;
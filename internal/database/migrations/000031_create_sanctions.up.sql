@@ -0,0 +1,61 @@
+-- Санкционные списки, загруженные из локальных файлов, и очередь проверки возможных совпадений клиентов
+CREATE TABLE IF NOT EXISTS sanctions_lists (
+    id SERIAL PRIMARY KEY,
+    name VARCHAR(100) NOT NULL UNIQUE,
+    source VARCHAR(500) NOT NULL,
+    checksum VARCHAR(64) NOT NULL,
+    entries_count INTEGER NOT NULL DEFAULT 0,
+    loaded_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS sanctions_entries (
+    id SERIAL PRIMARY KEY,
+    list_id INTEGER NOT NULL REFERENCES sanctions_lists(id) ON DELETE CASCADE,
+    external_id VARCHAR(100) NOT NULL,
+    name VARCHAR(500) NOT NULL,
+    aliases TEXT[] NOT NULL DEFAULT '{}',
+    birth_date VARCHAR(20) NOT NULL DEFAULT '',
+    country VARCHAR(100) NOT NULL DEFAULT '',
+    program VARCHAR(255) NOT NULL DEFAULT '',
+
+    CONSTRAINT uq_sanctions_entries_external_id UNIQUE (list_id, external_id)
+);
+
+-- Совпадение хранится по записи списка, а не по ID строки: при перезагрузке списка записи пересоздаются,
+-- а решение оператора по клиенту и записи сохраняется
+CREATE TABLE IF NOT EXISTS sanctions_hits (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    list_name VARCHAR(100) NOT NULL,
+    entry_external_id VARCHAR(100) NOT NULL,
+    entry_name VARCHAR(500) NOT NULL,
+    matched_name VARCHAR(500) NOT NULL,
+    score DECIMAL(5,4) NOT NULL,
+    source VARCHAR(20) NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending',
+    reviewer_id INTEGER REFERENCES users(id) ON DELETE SET NULL,
+    comment TEXT NOT NULL DEFAULT '',
+    reviewed_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    CONSTRAINT uq_sanctions_hits_entry UNIQUE (user_id, list_name, entry_external_id),
+    CONSTRAINT chk_sanctions_hit_source CHECK (source IN ('registration', 'transfer', 'rescreen')),
+    CONSTRAINT chk_sanctions_hit_status CHECK (status IN ('pending', 'confirmed', 'cleared'))
+);
+
+-- Очередь оператора
+CREATE INDEX IF NOT EXISTS idx_sanctions_hits_status ON sanctions_hits(status, created_at);
+
+-- Триггеры для автоматического обновления updated_at
+CREATE TRIGGER update_sanctions_lists_updated_at
+    BEFORE UPDATE ON sanctions_lists
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+CREATE TRIGGER update_sanctions_hits_updated_at
+    BEFORE UPDATE ON sanctions_hits
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
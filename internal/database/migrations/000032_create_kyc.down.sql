@@ -0,0 +1,4 @@
+-- Удаление анкет клиентов и документов KYC
+DROP TRIGGER IF EXISTS update_kyc_profiles_updated_at ON kyc_profiles;
+DROP TABLE IF EXISTS kyc_documents;
+DROP TABLE IF EXISTS kyc_profiles;
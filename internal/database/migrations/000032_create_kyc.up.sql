@@ -0,0 +1,61 @@
+-- Анкеты клиентов для идентификации (KYC) и сканы документов, хранящиеся в локальном каталоге
+CREATE TABLE IF NOT EXISTS kyc_profiles (
+    user_id INTEGER PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    last_name VARCHAR(100) NOT NULL DEFAULT '',
+    first_name VARCHAR(100) NOT NULL DEFAULT '',
+    middle_name VARCHAR(100) NOT NULL DEFAULT '',
+    birth_date DATE,
+    birth_place VARCHAR(255) NOT NULL DEFAULT '',
+    passport_series VARCHAR(4) NOT NULL DEFAULT '',
+    passport_number VARCHAR(6) NOT NULL DEFAULT '',
+    passport_issued_by VARCHAR(255) NOT NULL DEFAULT '',
+    passport_issue_date DATE,
+    passport_division_code VARCHAR(7) NOT NULL DEFAULT '',
+    inn VARCHAR(12) NOT NULL DEFAULT '',
+    snils VARCHAR(11) NOT NULL DEFAULT '',
+    address VARCHAR(500) NOT NULL DEFAULT '',
+    phone VARCHAR(12) NOT NULL DEFAULT '',
+    status VARCHAR(20) NOT NULL DEFAULT 'unverified',
+    level VARCHAR(20) NOT NULL DEFAULT 'none',
+    rejection_reason TEXT NOT NULL DEFAULT '',
+    reviewer_id INTEGER REFERENCES users(id) ON DELETE SET NULL,
+    submitted_at TIMESTAMP WITH TIME ZONE,
+    reviewed_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    CONSTRAINT chk_kyc_profile_status CHECK (status IN ('unverified', 'pending', 'verified', 'rejected')),
+    CONSTRAINT chk_kyc_profile_level CHECK (level IN ('none', 'simplified', 'full')),
+    CONSTRAINT chk_kyc_profile_verified_level CHECK ((status = 'verified') = (level <> 'none'))
+);
+
+-- Один паспорт, ИНН и СНИЛС не могут принадлежать разным клиентам
+CREATE UNIQUE INDEX IF NOT EXISTS uq_kyc_profiles_passport ON kyc_profiles(passport_series, passport_number)
+    WHERE passport_number <> '';
+CREATE UNIQUE INDEX IF NOT EXISTS uq_kyc_profiles_inn ON kyc_profiles(inn) WHERE inn <> '';
+CREATE UNIQUE INDEX IF NOT EXISTS uq_kyc_profiles_snils ON kyc_profiles(snils) WHERE snils <> '';
+-- Очередь оператора
+CREATE INDEX IF NOT EXISTS idx_kyc_profiles_status ON kyc_profiles(status, submitted_at);
+
+CREATE TABLE IF NOT EXISTS kyc_documents (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    type VARCHAR(20) NOT NULL,
+    file_name VARCHAR(255) NOT NULL,
+    content_type VARCHAR(100) NOT NULL,
+    size BIGINT NOT NULL,
+    sha256 VARCHAR(64) NOT NULL,
+    storage_path VARCHAR(500) NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    CONSTRAINT chk_kyc_document_type CHECK (type IN ('passport', 'inn', 'snils', 'address_proof')),
+    CONSTRAINT chk_kyc_document_size CHECK (size > 0)
+);
+
+CREATE INDEX IF NOT EXISTS idx_kyc_documents_user_id ON kyc_documents(user_id, type);
+
+-- Триггер для автоматического обновления updated_at
+CREATE TRIGGER update_kyc_profiles_updated_at
+    BEFORE UPDATE ON kyc_profiles
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
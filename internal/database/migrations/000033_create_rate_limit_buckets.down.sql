@@ -0,0 +1,2 @@
+-- Удаление корзин ограничителя запросов
+DROP TABLE IF EXISTS rate_limit_buckets;
@@ -0,0 +1,12 @@
+-- Корзины token bucket ограничителя запросов, общие для всех экземпляров приложения.
+-- UNLOGGED: состояние лимитов не критично и не должно нагружать WAL; после сбоя таблица очищается,
+-- что равносильно полным корзинам
+CREATE UNLOGGED TABLE IF NOT EXISTS rate_limit_buckets (
+    key TEXT PRIMARY KEY,
+    tokens DOUBLE PRECISION NOT NULL,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    -- момент полного восполнения корзины, после которого строку можно удалить
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_rate_limit_buckets_expires_at ON rate_limit_buckets(expires_at);
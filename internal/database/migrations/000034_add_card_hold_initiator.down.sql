@@ -0,0 +1,2 @@
+-- Удаление инициатора авторизации
+ALTER TABLE card_holds DROP COLUMN IF EXISTS initiated_by;
@@ -0,0 +1,3 @@
+-- Пользователь, создавший авторизацию через API: только он может отменить ее сам.
+-- У авторизаций из шлюза эквайринга поле пустое, их списывает и отменяет только эквайер
+ALTER TABLE card_holds ADD COLUMN IF NOT EXISTS initiated_by INTEGER REFERENCES users(id) ON DELETE SET NULL;
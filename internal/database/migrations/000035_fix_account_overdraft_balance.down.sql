@@ -0,0 +1,4 @@
+-- Возврат прежнего ограничения остатка
+ALTER TABLE accounts DROP CONSTRAINT chk_balance_positive;
+ALTER TABLE accounts
+ADD CONSTRAINT chk_balance_positive CHECK (balance >= 0 OR overdraft_limit > 0);
@@ -0,0 +1,5 @@
+-- Остаток не может опускаться ниже лимита овердрафта: прежнее условие допускало любой минус при подключенном овердрафте.
+-- NOT VALID: счета, уже ушедшие за лимит, не блокируют миграцию, но новые изменения остатка проверяются
+ALTER TABLE accounts DROP CONSTRAINT chk_balance_positive;
+ALTER TABLE accounts
+ADD CONSTRAINT chk_balance_positive CHECK (balance + overdraft_limit >= 0) NOT VALID;
@@ -0,0 +1,3 @@
+-- Удаление маскированных данных получателя поручения
+ALTER TABLE standing_orders DROP COLUMN IF EXISTS to_masked_account_number;
+ALTER TABLE standing_orders DROP COLUMN IF EXISTS to_masked_name;
@@ -0,0 +1,18 @@
+-- Маскированные данные получателя поручения: клиенту не отдается внутренний ID чужого счета
+ALTER TABLE standing_orders ADD COLUMN IF NOT EXISTS to_masked_name VARCHAR(100) NOT NULL DEFAULT '';
+ALTER TABLE standing_orders ADD COLUMN IF NOT EXISTS to_masked_account_number VARCHAR(20) NOT NULL DEFAULT '';
+
+-- Заполнение для существующих поручений по тем же правилам, что domain.MaskName и domain.MaskAccountNumber
+UPDATE standing_orders so
+SET to_masked_name = CASE
+        WHEN char_length(btrim(u.username)) = 0 THEN ''
+        WHEN char_length(btrim(u.username)) < 4 THEN upper(left(btrim(u.username), 1)) || '***'
+        ELSE upper(left(btrim(u.username), 1)) || '***' || right(btrim(u.username), 1)
+    END,
+    to_masked_account_number = CASE
+        WHEN length(a.number) <= 9 THEN a.number
+        ELSE left(a.number, 5) || repeat('*', length(a.number) - 9) || right(a.number, 4)
+    END
+FROM accounts a
+JOIN users u ON u.id = a.user_id
+WHERE a.id = so.to_account_id;
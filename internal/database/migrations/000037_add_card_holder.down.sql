@@ -0,0 +1,2 @@
+-- Удаление держателя карты
+ALTER TABLE cards DROP COLUMN IF EXISTS holder_id;
@@ -0,0 +1,5 @@
+-- Держатель карты — пользователь, выпустивший ее. PIN и реквизиты карты доступны только держателю и владельцу счета,
+-- совладельцу с правом операций — нет. Для выпущенных ранее карт держателем считается владелец счета
+ALTER TABLE cards ADD COLUMN IF NOT EXISTS holder_id INTEGER REFERENCES users(id) ON DELETE SET NULL;
+
+UPDATE cards c SET holder_id = a.user_id FROM accounts a WHERE a.id = c.account_id AND c.holder_id IS NULL;
@@ -0,0 +1,2 @@
+-- Удаление терминалов ТСП
+DROP TABLE IF EXISTS merchant_terminals;
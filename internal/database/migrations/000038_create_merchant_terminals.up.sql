@@ -0,0 +1,9 @@
+-- Терминалы ТСП (поле 41 ISO 8583). Реверс принимается шлюзом только от зарегистрированного терминала
+-- того же ТСП, что и исходная операция: реверс списывает возврат с расчетного счета ТСП
+CREATE TABLE IF NOT EXISTS merchant_terminals (
+    terminal_id VARCHAR(8) PRIMARY KEY,
+    merchant_id INTEGER NOT NULL REFERENCES merchants(id) ON DELETE CASCADE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_merchant_terminals_merchant ON merchant_terminals(merchant_id);
@@ -0,0 +1,5 @@
+-- Возврат прежнего кода накопительного вклада
+ALTER TABLE deposits DROP CONSTRAINT chk_deposits_kind_valid;
+UPDATE deposits SET product = 'savings', kind = 'savings' WHERE kind = 'flexible';
+ALTER TABLE deposits
+ADD CONSTRAINT chk_deposits_kind_valid CHECK (kind IN ('term', 'savings'));
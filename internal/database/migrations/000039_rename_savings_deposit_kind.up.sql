@@ -0,0 +1,6 @@
+-- Накопительный вклад переименован, чтобы не совпадать с продуктом счета savings.
+-- На базах, где переименование уже выполнено прежней версией 000035, миграция ничего не меняет
+ALTER TABLE deposits DROP CONSTRAINT chk_deposits_kind_valid;
+UPDATE deposits SET product = 'flexible', kind = 'flexible' WHERE kind = 'savings';
+ALTER TABLE deposits
+ADD CONSTRAINT chk_deposits_kind_valid CHECK (kind IN ('term', 'flexible'));
@@ -0,0 +1,2 @@
+-- Удаление попыток показа реквизитов карты
+DROP TABLE IF EXISTS card_reveal_attempts;
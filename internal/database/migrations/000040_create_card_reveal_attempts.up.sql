@@ -0,0 +1,10 @@
+-- Попытки получить токен показа реквизитов: записываются до проверки пароля, поэтому неверный пароль
+-- тоже расходует лимит CARD_REVEAL_LIMIT и перебор пароля через показ реквизитов ограничен
+CREATE TABLE IF NOT EXISTS card_reveal_attempts (
+    id BIGSERIAL PRIMARY KEY,
+    card_id INTEGER NOT NULL REFERENCES cards(id) ON DELETE CASCADE,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_card_reveal_attempts_card_created ON card_reveal_attempts(card_id, created_at);
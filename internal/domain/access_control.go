@@ -2,13 +2,19 @@ package domain
 
 import (
 	"context"
+	"errors"
 	"fmt"
 )
 
 // AccessControlService определяет интерфейс для контроля доступа на уровне домена
 type AccessControlService interface {
+	// CanAccessAccount проверяет право на операции со счетом (AccountPermissionOperate)
 	CanAccessAccount(ctx context.Context, userID, accountID int) error
+	// CheckAccountPermission проверяет право владельца или совладельца счета
+	CheckAccountPermission(ctx context.Context, userID, accountID int, permission string) error
 	CanAccessCard(ctx context.Context, userID, cardID int) error
+	// CheckCardholder проверяет, что пользователь — держатель карты или владелец счета (PIN и реквизиты карты)
+	CheckCardholder(ctx context.Context, userID, cardID int) error
 	CanAccessCredit(ctx context.Context, userID, creditID int) error
 }
 
@@ -18,6 +24,7 @@ type AccessControlDomain struct {
 	accountRepo AccountRepositoryInterface
 	cardRepo    CardRepositoryInterface
 	creditRepo  CreditRepositoryInterface
+	holderRepo  AccountHolderRepositoryInterface
 }
 
 // NewAccessControlDomain создает новый экземпляр domain service
@@ -25,22 +32,42 @@ func NewAccessControlDomain(
 	accountRepo AccountRepositoryInterface,
 	cardRepo CardRepositoryInterface,
 	creditRepo CreditRepositoryInterface,
+	holderRepo AccountHolderRepositoryInterface,
 ) *AccessControlDomain {
 	return &AccessControlDomain{
 		accountRepo: accountRepo,
 		cardRepo:    cardRepo,
 		creditRepo:  creditRepo,
+		holderRepo:  holderRepo,
 	}
 }
 
 // CanAccessAccount проверяет может ли пользователь работать со счетом
 func (d *AccessControlDomain) CanAccessAccount(ctx context.Context, userID, accountID int) error {
+	return d.CheckAccountPermission(ctx, userID, accountID, AccountPermissionOperate)
+}
+
+// CheckAccountPermission проверяет право пользователя на счет: владелец имеет все права,
+// совладелец — права своей роли (см. AccountHolder.HasPermission)
+func (d *AccessControlDomain) CheckAccountPermission(ctx context.Context, userID, accountID int, permission string) error {
 	account, err := d.accountRepo.GetByID(ctx, accountID)
 	if err != nil {
 		return fmt.Errorf("account not found: %w", err)
 	}
 
-	if account.UserID != userID {
+	if account.UserID == userID {
+		return nil
+	}
+
+	holder, err := d.holderRepo.Get(ctx, accountID, userID)
+	if err != nil {
+		if errors.Is(err, ErrAccountHolderNotFound) {
+			return NewAccessDeniedError("account", accountID, userID)
+		}
+		return fmt.Errorf("failed to get account holder: %w", err)
+	}
+
+	if !holder.HasPermission(permission) {
 		return NewAccessDeniedError("account", accountID, userID)
 	}
 
@@ -58,6 +85,28 @@ func (d *AccessControlDomain) CanAccessCard(ctx context.Context, userID, cardID
 	return d.CanAccessAccount(ctx, userID, card.AccountID)
 }
 
+// CheckCardholder проверяет, что пользователь — держатель карты или владелец счета.
+// Совладельцу с правом операций карта доступна для платежей, но не для смены PIN и раскрытия реквизитов
+func (d *AccessControlDomain) CheckCardholder(ctx context.Context, userID, cardID int) error {
+	card, err := d.cardRepo.GetByID(ctx, cardID)
+	if err != nil {
+		return fmt.Errorf("card not found: %w", err)
+	}
+
+	if card.HolderID == userID {
+		return nil
+	}
+
+	if err := d.CheckAccountPermission(ctx, userID, card.AccountID, AccountPermissionOwner); err != nil {
+		if IsAccessDeniedError(err) {
+			return NewAccessDeniedError("card", cardID, userID)
+		}
+		return err
+	}
+
+	return nil
+}
+
 // CanAccessCredit проверяет может ли пользователь работать с кредитом
 func (d *AccessControlDomain) CanAccessCredit(ctx context.Context, userID, creditID int) error {
 	credit, err := d.creditRepo.GetByID(ctx, creditID)
@@ -82,6 +131,10 @@ type CreditRepositoryInterface interface {
 	GetByID(ctx context.Context, id int) (*Credit, error)
 }
 
+type AccountHolderRepositoryInterface interface {
+	Get(ctx context.Context, accountID, userID int) (*AccountHolder, error)
+}
+
 // AccessDeniedError кастомная ошибка для нарушения прав доступа
 type AccessDeniedError struct {
 	ResourceType string
@@ -7,14 +7,17 @@ import (
 
 // Account представляет банковский счет
 type Account struct {
-	ID        int       `json:"id" db:"id"`
-	UserID    int       `json:"user_id" db:"user_id"`
-	Number    string    `json:"number" db:"number"`
-	Balance   float64   `json:"balance" db:"balance"`
-	Currency  string    `json:"currency" db:"currency"`
-	Status    string    `json:"status" db:"status"`
-	CreatedAt time.Time `json:"created_at" db:"created_at"`
-	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
+	ID             int       `json:"id" db:"id"`
+	UserID         int       `json:"user_id" db:"user_id"`
+	Number         string    `json:"number" db:"number"`
+	Product        string    `json:"product" db:"product"`
+	Balance        float64   `json:"balance" db:"balance"`                 // Учетный остаток; отрицательный — использованный овердрафт
+	HeldAmount     float64   `json:"held_amount" db:"held_amount"`         // Заблокировано авторизациями по картам
+	OverdraftLimit float64   `json:"overdraft_limit" db:"overdraft_limit"` // 0 — овердрафт не подключен
+	Currency       string    `json:"currency" db:"currency"`
+	Status         string    `json:"status" db:"status"`
+	CreatedAt      time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at" db:"updated_at"`
 }
 
 // CreateAccountRequest представляет запрос на создание счета
@@ -46,6 +49,12 @@ const (
 	AccountStatusClosed  = "closed"
 )
 
+// AccountProduct определяет продукты счетов; условия овердрафта задаются по продукту
+const (
+	AccountProductChecking = "checking"
+	AccountProductSavings  = "savings"
+)
+
 // Validation errors
 var (
 	ErrInvalidAccountBalance  = errors.New("invalid account balance")
@@ -54,13 +63,51 @@ var (
 	ErrInvalidDepositAmount   = errors.New("invalid deposit amount")
 	ErrInvalidWithdrawAmount  = errors.New("invalid withdraw amount")
 	ErrInvalidTransferAmount  = errors.New("invalid transfer amount")
+	ErrAccountClosed          = errors.New("account is closed")
+	ErrAccountNotActive       = errors.New("account is not active")
+	ErrAccountNotFrozen       = errors.New("account is not frozen")
+	ErrAccountHasHolds        = errors.New("account has pending card authorizations")
+	ErrAccountHasCredits      = errors.New("account has active credits")
+	ErrAccountNegativeBalance = errors.New("account balance is negative")
+	// ErrResidualAccountRequired остаток на закрываемом счете некуда перевести
+	ErrResidualAccountRequired = errors.New("transfer_to_account_id is required to move the residual balance")
+	ErrInvalidResidualAccount  = errors.New("residual balance must be moved to another active account")
+	ErrInvalidAccountProduct   = errors.New("invalid account product")
+	ErrOverdraftNotAvailable   = errors.New("overdraft is not available for this account product")
+	ErrOverdraftLimitExceeded  = errors.New("overdraft limit exceeds product maximum")
+	ErrInvalidOverdraftLimit   = errors.New("invalid overdraft limit")
+	// ErrAccountInsufficientFunds списание превышает доступный остаток с учетом овердрафта и холдов
+	ErrAccountInsufficientFunds = errors.New("insufficient available funds")
+	// ErrOverdraftLimitBelowDebt новый лимит не покрывает уже использованный овердрафт и холды
+	ErrOverdraftLimitBelowDebt = errors.New("overdraft limit is below the used overdraft")
 )
 
+// IsValidAccountProduct проверяет код продукта счета
+func IsValidAccountProduct(product string) bool {
+	return product == AccountProductChecking || product == AccountProductSavings
+}
+
+// AvailableBalance возвращает доступный остаток: учетный остаток с лимитом овердрафта за вычетом холдов
+func (a *Account) AvailableBalance() float64 {
+	return a.Balance + a.OverdraftLimit - a.HeldAmount
+}
+
+// OverdraftUsed возвращает использованную сумму овердрафта
+func (a *Account) OverdraftUsed() float64 {
+	if a.Balance >= 0 {
+		return 0
+	}
+	return -a.Balance
+}
+
 // Validate валидирует счет
 func (a *Account) Validate() error {
-	if a.Balance < 0 {
+	if a.Balance < 0 && a.OverdraftLimit <= 0 {
 		return ErrInvalidAccountBalance
 	}
+	if !IsValidAccountProduct(a.Product) || a.OverdraftLimit < 0 {
+		return ErrInvalidAccountProduct
+	}
 	if a.Currency != "RUB" {
 		return ErrInvalidAccountCurrency
 	}
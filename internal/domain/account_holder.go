@@ -0,0 +1,93 @@
+package domain
+
+import (
+	"errors"
+	"time"
+)
+
+// AccountHolder совладелец счета: держатель совместного счета или дополнительный держатель.
+// Владелец счета (accounts.user_id) в таблицу совладельцев не входит и имеет все права.
+type AccountHolder struct {
+	ID          int       `json:"id" db:"id"`
+	AccountID   int       `json:"account_id" db:"account_id"`
+	UserID      int       `json:"user_id" db:"user_id"`
+	Role        string    `json:"role" db:"role"`
+	Permissions []string  `json:"permissions" db:"permissions"` // Права дополнительного держателя
+	CreatedAt   time.Time `json:"created_at" db:"created_at"`
+}
+
+// AddAccountHolderRequest представляет запрос владельца на добавление совладельца
+type AddAccountHolderRequest struct {
+	Email       string   `json:"email"`
+	Role        string   `json:"role"`
+	Permissions []string `json:"permissions"`
+}
+
+// AccountHolderRole определяет роль совладельца счета
+const (
+	AccountHolderRoleJoint     = "joint"     // Совместный счет: все права, кроме закрытия и управления совладельцами
+	AccountHolderRoleSecondary = "secondary" // Дополнительный держатель: только выданные права
+)
+
+// AccountPermission определяет права на операции со счетом
+const (
+	AccountPermissionView    = "view"    // Просмотр счета, карт и холдов
+	AccountPermissionOperate = "operate" // Пополнения, списания, переводы и платежи картами
+	AccountPermissionManage  = "manage"  // Заморозка счета и выпуск карт
+	// AccountPermissionOwner только владелец: закрытие счета и управление совладельцами; совладельцу не выдается
+	AccountPermissionOwner = "owner"
+)
+
+// Validation errors
+var (
+	ErrInvalidHolderRole       = errors.New("holder role must be joint or secondary")
+	ErrInvalidHolderPermission = errors.New("holder permissions must be view, operate or manage")
+	ErrAccountHolderExists     = errors.New("user is already an account holder")
+	ErrAccountHolderNotFound   = errors.New("account holder not found")
+	ErrAccountHolderIsOwner    = errors.New("account owner cannot be added as a holder")
+)
+
+// HasPermission проверяет, есть ли у совладельца право permission.
+// Держатель совместного счета имеет все права совладельца; просмотр доступен любому совладельцу.
+func (h *AccountHolder) HasPermission(permission string) bool {
+	if permission == AccountPermissionOwner {
+		return false
+	}
+	if h.Role == AccountHolderRoleJoint || permission == AccountPermissionView {
+		return true
+	}
+	for _, p := range h.Permissions {
+		if p == permission {
+			return true
+		}
+	}
+	return false
+}
+
+// Validate валидирует запрос на добавление совладельца; права держателя совместного счета не задаются
+func (r *AddAccountHolderRequest) Validate() error {
+	switch r.Role {
+	case AccountHolderRoleJoint:
+		r.Permissions = nil
+		return nil
+	case AccountHolderRoleSecondary:
+	default:
+		return ErrInvalidHolderRole
+	}
+
+	seen := make(map[string]bool, len(r.Permissions))
+	permissions := make([]string, 0, len(r.Permissions)+1)
+	for _, p := range append([]string{AccountPermissionView}, r.Permissions...) {
+		switch p {
+		case AccountPermissionView, AccountPermissionOperate, AccountPermissionManage:
+		default:
+			return ErrInvalidHolderPermission
+		}
+		if !seen[p] {
+			seen[p] = true
+			permissions = append(permissions, p)
+		}
+	}
+	r.Permissions = permissions
+	return nil
+}
@@ -0,0 +1,290 @@
+package domain
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// AuditEvent запись журнала аудита.
+// Каждая запись содержит хеш предыдущей, что позволяет обнаружить изменение или удаление записей.
+type AuditEvent struct {
+	ID           int64           `json:"id" db:"id"`
+	Seq          int64           `json:"seq" db:"seq"`
+	ActorUserID  *int            `json:"actor_user_id" db:"actor_user_id"`
+	ActorType    string          `json:"actor_type" db:"actor_type"`
+	Action       string          `json:"action" db:"action"`
+	ResourceType string          `json:"resource_type" db:"resource_type"`
+	ResourceID   string          `json:"resource_id" db:"resource_id"`
+	RequestID    string          `json:"request_id" db:"request_id"`
+	IP           string          `json:"ip" db:"ip"`
+	Before       json.RawMessage `json:"before,omitempty" db:"before_state"`
+	After        json.RawMessage `json:"after,omitempty" db:"after_state"`
+	Metadata     json.RawMessage `json:"metadata,omitempty" db:"metadata"`
+	CreatedAt    time.Time       `json:"created_at" db:"created_at"`
+	PrevHash     string          `json:"prev_hash" db:"prev_hash"`
+	Hash         string          `json:"hash" db:"hash"`
+}
+
+// AuditActorType определяет типы инициаторов действий
+const (
+	AuditActorUser      = "user"
+	AuditActorAdmin     = "admin"
+	AuditActorSystem    = "system"
+	AuditActorAnonymous = "anonymous"
+)
+
+// AuditAction определяет действия, попадающие в журнал аудита
+const (
+	AuditActionLogin                   = "auth.login"
+	AuditActionLoginFailed             = "auth.login_failed"
+	AuditActionTransfer                = "account.transfer"
+	AuditActionAccountFreeze           = "account.freeze"
+	AuditActionAccountUnfreeze         = "account.unfreeze"
+	AuditActionAccountClose            = "account.close"
+	AuditActionAccountHolderAdd        = "account.holder_add"
+	AuditActionAccountHolderRemove     = "account.holder_remove"
+	AuditActionAccountOverdraftSet     = "account.overdraft_set"
+	AuditActionCardDecrypt             = "card.decrypt"
+	AuditActionCardRevealToken         = "card.reveal_token"
+	AuditActionCardRevealDenied        = "card.reveal_denied"
+	AuditActionCardBlock               = "card.block"
+	AuditActionCardUnblock             = "card.unblock"
+	AuditActionCardPINSet              = "card.pin_set"
+	AuditActionCardPINChange           = "card.pin_change"
+	AuditActionCardHoldCapture         = "card.hold_capture"
+	AuditActionCardHoldVoid            = "card.hold_void"
+	AuditActionCardRefund              = "card.refund"
+	AuditActionCreditIssue             = "credit.issue"
+	AuditActionDepositOpen             = "deposit.open"
+	AuditActionDepositClose            = "deposit.close"
+	AuditActionStandingOrderCreate     = "standing_order.create"
+	AuditActionStandingOrderUpdate     = "standing_order.update"
+	AuditActionStandingOrderCancel     = "standing_order.cancel"
+	AuditActionPaymentAliasCreate      = "payment_alias.create"
+	AuditActionPaymentAliasDelete      = "payment_alias.delete"
+	AuditActionKYCProfileUpdate        = "kyc.profile_update"
+	AuditActionKYCDocumentUpload       = "kyc.document_upload"
+	AuditActionKYCSubmit               = "kyc.submit"
+	AuditActionAdminAuditQuery         = "admin.audit_query"
+	AuditActionAdminAuditCheck         = "admin.audit_verify"
+	AuditActionAdminOutboxRetry        = "admin.outbox_retry"
+	AuditActionAdminJobTrigger         = "admin.job_trigger"
+	AuditActionAdminJobPause           = "admin.job_pause"
+	AuditActionAdminJobResume          = "admin.job_resume"
+	AuditActionAdminMerchantCreate     = "admin.merchant_create"
+	AuditActionAdminTerminalAdd        = "admin.terminal_add"
+	AuditActionAdminCashbackRuleCreate = "admin.cashback_rule_create"
+	AuditActionAdminCashbackRuleDelete = "admin.cashback_rule_delete"
+	AuditActionAdminFraudApprove       = "admin.fraud_approve"
+	AuditActionAdminFraudDecline       = "admin.fraud_decline"
+	AuditActionAdminSanctionsConfirm   = "admin.sanctions_confirm"
+	AuditActionAdminSanctionsClear     = "admin.sanctions_clear"
+	AuditActionAdminKYCVerify          = "admin.kyc_verify"
+	AuditActionAdminKYCReject          = "admin.kyc_reject"
+	AuditActionAdminKYCDocumentView    = "admin.kyc_document_view"
+)
+
+// AuditGenesisHash хеш-предшественник первой записи цепочки
+const AuditGenesisHash = "0000000000000000000000000000000000000000000000000000000000000000"
+
+// Validation errors
+var (
+	ErrEmptyAuditAction    = errors.New("audit action cannot be empty")
+	ErrInvalidAuditActor   = errors.New("invalid audit actor type")
+	ErrAuditChainBroken    = errors.New("audit chain is broken")
+	ErrInvalidAuditPayload = errors.New("audit state must be valid JSON")
+)
+
+// Validate валидирует запись аудита
+func (e *AuditEvent) Validate() error {
+	if e.Action == "" {
+		return ErrEmptyAuditAction
+	}
+
+	switch e.ActorType {
+	case AuditActorUser, AuditActorAdmin, AuditActorSystem, AuditActorAnonymous:
+	default:
+		return ErrInvalidAuditActor
+	}
+
+	for _, payload := range []json.RawMessage{e.Before, e.After, e.Metadata} {
+		if len(payload) > 0 && !json.Valid(payload) {
+			return ErrInvalidAuditPayload
+		}
+	}
+
+	return nil
+}
+
+// auditHashInput каноничное представление записи для вычисления хеша.
+// Порядок полей фиксирован, ID не входит (назначается базой данных).
+type auditHashInput struct {
+	Seq          int64           `json:"seq"`
+	ActorUserID  *int            `json:"actor_user_id"`
+	ActorType    string          `json:"actor_type"`
+	Action       string          `json:"action"`
+	ResourceType string          `json:"resource_type"`
+	ResourceID   string          `json:"resource_id"`
+	RequestID    string          `json:"request_id"`
+	IP           string          `json:"ip"`
+	Before       json.RawMessage `json:"before"`
+	After        json.RawMessage `json:"after"`
+	Metadata     json.RawMessage `json:"metadata"`
+	CreatedAt    string          `json:"created_at"`
+	PrevHash     string          `json:"prev_hash"`
+}
+
+// ComputeHash вычисляет SHA-256 хеш записи с учетом хеша предыдущей записи
+func (e *AuditEvent) ComputeHash() (string, error) {
+	input := auditHashInput{
+		Seq:          e.Seq,
+		ActorUserID:  e.ActorUserID,
+		ActorType:    e.ActorType,
+		Action:       e.Action,
+		ResourceType: e.ResourceType,
+		ResourceID:   e.ResourceID,
+		RequestID:    e.RequestID,
+		IP:           e.IP,
+		Before:       nullIfEmpty(e.Before),
+		After:        nullIfEmpty(e.After),
+		Metadata:     nullIfEmpty(e.Metadata),
+		CreatedAt:    e.CreatedAt.UTC().Format(time.RFC3339Nano),
+		PrevHash:     e.PrevHash,
+	}
+
+	data, err := json.Marshal(input)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal audit event: %w", err)
+	}
+
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// Seal связывает запись с предыдущей и вычисляет ее хеш
+func (e *AuditEvent) Seal(prev *AuditEvent) error {
+	e.Seq = 1
+	e.PrevHash = AuditGenesisHash
+	if prev != nil {
+		e.Seq = prev.Seq + 1
+		e.PrevHash = prev.Hash
+	}
+
+	// Точность PostgreSQL - микросекунды, иначе хеш не совпадет после чтения
+	e.CreatedAt = e.CreatedAt.UTC().Truncate(time.Microsecond)
+
+	hash, err := e.ComputeHash()
+	if err != nil {
+		return err
+	}
+	e.Hash = hash
+	return nil
+}
+
+// AuditChainError описывает место нарушения цепочки аудита
+type AuditChainError struct {
+	Seq    int64
+	ID     int64
+	Reason string
+}
+
+func (e *AuditChainError) Error() string {
+	return fmt.Sprintf("audit chain is broken at seq %d (id %d): %s", e.Seq, e.ID, e.Reason)
+}
+
+func (e *AuditChainError) Unwrap() error {
+	return ErrAuditChainBroken
+}
+
+// VerifyAuditChain проверяет непрерывность участка цепочки.
+// prev - последняя проверенная запись перед участком (nil для начала цепочки).
+func VerifyAuditChain(prev *AuditEvent, events []*AuditEvent) error {
+	for _, event := range events {
+		expectedSeq := int64(1)
+		expectedPrevHash := AuditGenesisHash
+		if prev != nil {
+			expectedSeq = prev.Seq + 1
+			expectedPrevHash = prev.Hash
+		}
+
+		if event.Seq != expectedSeq {
+			return &AuditChainError{Seq: event.Seq, ID: event.ID, Reason: fmt.Sprintf("expected seq %d", expectedSeq)}
+		}
+
+		if event.PrevHash != expectedPrevHash {
+			return &AuditChainError{Seq: event.Seq, ID: event.ID, Reason: "prev_hash does not match previous record"}
+		}
+
+		hash, err := event.ComputeHash()
+		if err != nil {
+			return err
+		}
+		if hash != strings.TrimSpace(event.Hash) {
+			return &AuditChainError{Seq: event.Seq, ID: event.ID, Reason: "record content does not match hash"}
+		}
+
+		prev = event
+	}
+
+	return nil
+}
+
+// AuditFilter фильтр для выборки записей аудита
+type AuditFilter struct {
+	ActorUserID  *int       `json:"actor_user_id,omitempty"`
+	Action       string     `json:"action,omitempty"`
+	ResourceType string     `json:"resource_type,omitempty"`
+	ResourceID   string     `json:"resource_id,omitempty"`
+	From         *time.Time `json:"from,omitempty"`
+	To           *time.Time `json:"to,omitempty"`
+	Limit        int        `json:"limit"`
+	Offset       int        `json:"offset"`
+}
+
+// NewAuditState сериализует состояние объекта для полей before/after
+func NewAuditState(state interface{}) json.RawMessage {
+	if state == nil {
+		return nil
+	}
+
+	data, err := json.Marshal(state)
+	if err != nil {
+		return nil
+	}
+	return data
+}
+
+func nullIfEmpty(data json.RawMessage) json.RawMessage {
+	if len(data) == 0 {
+		return json.RawMessage("null")
+	}
+	return data
+}
+
+// requestMetaKey ключ контекста для метаданных запроса
+type requestMetaKey struct{}
+
+// RequestMeta метаданные HTTP запроса для аудита и антифрода
+type RequestMeta struct {
+	RequestID string
+	IP        string
+	UserAgent string
+	Country   string // Страна клиента по GeoIP, если ее передал балансировщик
+}
+
+// ContextWithRequestMeta добавляет метаданные запроса в контекст
+func ContextWithRequestMeta(ctx context.Context, meta RequestMeta) context.Context {
+	return context.WithValue(ctx, requestMetaKey{}, meta)
+}
+
+// RequestMetaFromContext извлекает метаданные запроса из контекста
+func RequestMetaFromContext(ctx context.Context) (RequestMeta, bool) {
+	meta, ok := ctx.Value(requestMetaKey{}).(RequestMeta)
+	return meta, ok
+}
@@ -2,20 +2,29 @@ package domain
 
 import (
 	"errors"
+	"strings"
 	"time"
 )
 
 // Card представляет банковскую карту
 type Card struct {
-	ID            int       `json:"id" db:"id"`
-	AccountID     int       `json:"account_id" db:"account_id"`
-	EncryptedData string    `json:"-" db:"encrypted_data"`
-	HMAC          string    `json:"-" db:"hmac"`
-	CVVHash       string    `json:"-" db:"cvv_hash"`
-	ExpiryDate    time.Time `json:"expiry_date" db:"expiry_date"`
-	Status        string    `json:"status" db:"status"`
-	CreatedAt     time.Time `json:"created_at" db:"created_at"`
-	UpdatedAt     time.Time `json:"updated_at" db:"updated_at"`
+	ID                int       `json:"id" db:"id"`
+	AccountID         int       `json:"account_id" db:"account_id"`
+	HolderID          int       `json:"holder_id" db:"holder_id"` // Держатель карты: пользователь, выпустивший ее
+	EncryptedData     string    `json:"-" db:"encrypted_data"`
+	HMAC              string    `json:"-" db:"hmac"`
+	CVVHash           string    `json:"-" db:"cvv_hash"`
+	PANHash           string    `json:"-" db:"pan_hash"` // HMAC номера карты для поиска
+	ExpiryDate        time.Time `json:"expiry_date" db:"expiry_date"`
+	Status            string    `json:"status" db:"status"`
+	CardType          string    `json:"card_type" db:"card_type"`
+	MerchantLock      string    `json:"merchant_lock,omitempty" db:"merchant_lock"` // Код единственного разрешенного ТСП
+	CVVFailedAttempts int       `json:"-" db:"cvv_failed_attempts"`                 // Неверные CVV подряд
+	PINHash           string    `json:"-" db:"pin_hash"`                            // bcrypt от соли и PIN-блока
+	PINSalt           string    `json:"-" db:"pin_salt"`                            // Соль карты для хеша PIN-блока
+	PINFailedAttempts int       `json:"-" db:"pin_failed_attempts"`                 // Неверные PIN подряд
+	CreatedAt         time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt         time.Time `json:"updated_at" db:"updated_at"`
 }
 
 // CardData представляет расшифрованные данные карты
@@ -26,7 +35,11 @@ type CardData struct {
 
 // CreateCardRequest представляет запрос на создание карты
 type CreateCardRequest struct {
-	AccountID int `json:"account_id"`
+	AccountID    int    `json:"account_id"`
+	CardType     string `json:"card_type"`     // По умолчанию физическая
+	ValidityDays int    `json:"validity_days"` // Короткий срок действия; 0 — срок по типу карты
+	MerchantLock string `json:"merchant_lock"`
+	PIN          string `json:"-"` // Необязательный PIN при выпуске
 }
 
 // PaymentRequest представляет запрос на оплату картой
@@ -38,14 +51,24 @@ type PaymentRequest struct {
 
 // CardStatus определяет возможные статусы карты
 const (
-	CardStatusActive  = "active"
-	CardStatusBlocked = "blocked"
-	CardStatusExpired = "expired"
+	CardStatusActive    = "active"
+	CardStatusBlocked   = "blocked"
+	CardStatusExpired   = "expired"
+	CardStatusCancelled = "cancelled"
 )
 
 // CardType определяет тип карты LearnBank
 const (
 	CardTypeLearnBank = "LEARNBANK"
+	CardTypePhysical  = "physical"
+	CardTypeVirtual   = "virtual"
+	CardTypeSingleUse = "single_use" // Закрывается после первого списания
+)
+
+// Сроки действия карт, дней
+const (
+	SingleUseCardValidityDays = 30
+	MaxCardValidityDays       = 365 // Предел для карт с коротким сроком
 )
 
 // Validation errors
@@ -54,11 +77,27 @@ var (
 	ErrInvalidPaymentCVV    = errors.New("invalid CVV")
 	ErrInvalidPaymentAmount = errors.New("invalid payment amount")
 	ErrCardExpired          = errors.New("card is expired")
+	ErrInvalidCardType      = errors.New("card type must be physical, virtual or single_use")
+	ErrInvalidCardValidity  = errors.New("card validity must be between 1 and 365 days")
+	ErrInvalidMerchantLock  = errors.New("merchant lock must not exceed 100 characters")
+	ErrCardMerchantLocked   = errors.New("card is locked to another merchant")
+	ErrInvalidPIN           = errors.New("PIN must contain 4 to 6 digits and not repeat one digit")
+	ErrPINNotSet            = errors.New("card PIN is not set")
+	ErrPINAlreadySet        = errors.New("card PIN is already set")
+	ErrIncorrectPIN         = errors.New("incorrect PIN")
+	ErrCardNotBlocked       = errors.New("card is not blocked")
+)
+
+// Длина PIN карты, цифр
+const (
+	MinPINLength = 4
+	MaxPINLength = 6
 )
 
 // Validate валидирует карту
 func (c *Card) Validate() error {
-	if c.Status != CardStatusActive && c.Status != CardStatusBlocked && c.Status != CardStatusExpired {
+	if c.Status != CardStatusActive && c.Status != CardStatusBlocked && c.Status != CardStatusExpired &&
+		c.Status != CardStatusCancelled {
 		return ErrInvalidCardStatus
 	}
 	if c.ExpiryDate.Before(time.Now()) {
@@ -67,11 +106,54 @@ func (c *Card) Validate() error {
 	return nil
 }
 
-// Validate валидирует запрос на создание карты
+// AllowsMerchant проверяет, можно ли платить картой в ТСП с кодом merchantID
+func (c *Card) AllowsMerchant(merchantID string) bool {
+	return c.MerchantLock == "" || c.MerchantLock == strings.TrimSpace(merchantID)
+}
+
+// HasPIN проверяет, установлен ли PIN карты
+func (c *Card) HasPIN() bool {
+	return c.PINHash != ""
+}
+
+// ValidatePIN проверяет формат PIN: 4–6 цифр, не одна и та же цифра
+func ValidatePIN(pin string) error {
+	if len(pin) < MinPINLength || len(pin) > MaxPINLength {
+		return ErrInvalidPIN
+	}
+	for _, r := range pin {
+		if r < '0' || r > '9' {
+			return ErrInvalidPIN
+		}
+	}
+	if strings.Count(pin, pin[:1]) == len(pin) {
+		return ErrInvalidPIN
+	}
+	return nil
+}
+
+// Validate валидирует запрос на создание карты; пустой тип заменяется на физическую карту
 func (r *CreateCardRequest) Validate() error {
 	if r.AccountID <= 0 {
 		return errors.New("invalid account ID")
 	}
+	switch r.CardType {
+	case "":
+		r.CardType = CardTypePhysical
+	case CardTypePhysical, CardTypeVirtual, CardTypeSingleUse:
+	default:
+		return ErrInvalidCardType
+	}
+	if r.ValidityDays < 0 || r.ValidityDays > MaxCardValidityDays {
+		return ErrInvalidCardValidity
+	}
+	r.MerchantLock = strings.TrimSpace(r.MerchantLock)
+	if len(r.MerchantLock) > 100 {
+		return ErrInvalidMerchantLock
+	}
+	if r.PIN != "" {
+		return ValidatePIN(r.PIN)
+	}
 	return nil
 }
 
@@ -0,0 +1,33 @@
+package domain
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"time"
+)
+
+// CardRevealToken одноразовый токен на показ реквизитов карты.
+// Выдается после повторной проверки пароля; хранится только хеш токена.
+type CardRevealToken struct {
+	ID        int64      `json:"id" db:"id"`
+	CardID    int        `json:"card_id" db:"card_id"`
+	UserID    int        `json:"user_id" db:"user_id"`
+	TokenHash string     `json:"-" db:"token_hash"`
+	ExpiresAt time.Time  `json:"expires_at" db:"expires_at"`
+	UsedAt    *time.Time `json:"used_at,omitempty" db:"used_at"`
+	CreatedAt time.Time  `json:"created_at" db:"created_at"`
+}
+
+// Card reveal errors
+var (
+	ErrCardRevealTokenInvalid = errors.New("card reveal token is invalid, expired or already used")
+	ErrCardRevealRateLimited  = errors.New("too many card details reveals, try again later")
+	ErrStepUpFailed           = errors.New("password confirmation failed")
+)
+
+// HashCardRevealToken возвращает хеш токена показа реквизитов для хранения и поиска
+func HashCardRevealToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,144 @@
+package domain
+
+import (
+	"errors"
+	"math"
+	"time"
+)
+
+// CashbackRule правило кэшбэка для одного MCC или категории расходов.
+// Правило по MCC приоритетнее правила по категории; платежи без правила получают базовую ставку.
+type CashbackRule struct {
+	ID         int       `json:"id" db:"id"`
+	MCC        string    `json:"mcc,omitempty" db:"mcc"`
+	Category   string    `json:"category,omitempty" db:"category"`
+	Rate       float64   `json:"rate" db:"rate"`               // Процент от суммы платежа
+	MonthlyCap float64   `json:"monthly_cap" db:"monthly_cap"` // Лимит кэшбэка по правилу за месяц, 0 — без лимита
+	CreatedAt  time.Time `json:"created_at" db:"created_at"`
+}
+
+// CashbackEntry начисление кэшбэка за списанный платеж или его сторно при возврате
+type CashbackEntry struct {
+	ID            int64     `json:"id" db:"id"`
+	CardID        int       `json:"card_id" db:"card_id"`
+	AccountID     int       `json:"account_id" db:"account_id"`
+	TransactionID int       `json:"transaction_id" db:"transaction_id"`
+	RuleID        *int      `json:"rule_id,omitempty" db:"rule_id"`
+	Kind          string    `json:"kind" db:"kind"`
+	Period        time.Time `json:"period" db:"period"` // Первый день месяца выплаты
+	BaseAmount    float64   `json:"base_amount" db:"base_amount"`
+	Rate          float64   `json:"rate" db:"rate"`
+	Amount        float64   `json:"amount" db:"amount"` // Отрицательная для сторно
+	CreatedAt     time.Time `json:"created_at" db:"created_at"`
+}
+
+// CashbackPayout выплата кэшбэка по карте за месяц на счет карты
+type CashbackPayout struct {
+	ID            int64     `json:"id" db:"id"`
+	CardID        int       `json:"card_id" db:"card_id"`
+	AccountID     int       `json:"account_id" db:"account_id"`
+	Period        time.Time `json:"period" db:"period"`
+	Amount        float64   `json:"amount" db:"amount"`
+	TransactionID *int      `json:"transaction_id,omitempty" db:"transaction_id"`
+	CreatedAt     time.Time `json:"created_at" db:"created_at"`
+}
+
+// PaymentCashback кэшбэк, начисленный за один платеж, с учетом сторно
+type PaymentCashback struct {
+	Accrual  *CashbackEntry
+	Reversed float64 // Сумма сторно (положительная)
+}
+
+// CashbackPeriodTotals начисленный кэшбэк по карте за месяц: всего и по правилу
+type CashbackPeriodTotals struct {
+	Card float64
+	Rule float64
+}
+
+// CashbackBalance кэшбэк карты: ожидает выплаты и выплачено
+type CashbackBalance struct {
+	Pending float64
+	Paid    float64
+}
+
+// CreateCashbackRuleRequest представляет запрос на создание правила кэшбэка
+type CreateCashbackRuleRequest struct {
+	MCC        string  `json:"mcc"`
+	Category   string  `json:"category"`
+	Rate       float64 `json:"rate"`
+	MonthlyCap float64 `json:"monthly_cap"`
+}
+
+// CashbackEntryKind определяет виды записей кэшбэка
+const (
+	CashbackEntryAccrual  = "accrual"
+	CashbackEntryReversal = "reversal"
+)
+
+// Cashback errors
+var (
+	ErrCashbackRuleNotFound = errors.New("cashback rule not found")
+	ErrCashbackRuleExists   = errors.New("active cashback rule for this mcc or category already exists")
+	ErrInvalidCashbackRule  = errors.New("cashback rule must target either mcc or category")
+	ErrInvalidCashbackRate  = errors.New("cashback rate must be between 0 and 100")
+	ErrInvalidCashbackCap   = errors.New("cashback monthly cap must not be negative")
+	ErrInvalidCategory      = errors.New("unknown spending category")
+	ErrCashbackNotAccrued   = errors.New("cashback is not accrued for transaction")
+)
+
+// spendingCategories категории расходов, для которых можно задать правило
+var spendingCategories = map[string]bool{
+	SpendingCategoryGroceries:     true,
+	SpendingCategoryRestaurants:   true,
+	SpendingCategoryTransport:     true,
+	SpendingCategoryFuel:          true,
+	SpendingCategoryTravel:        true,
+	SpendingCategoryHealth:        true,
+	SpendingCategoryEntertainment: true,
+	SpendingCategoryUtilities:     true,
+	SpendingCategoryShopping:      true,
+	SpendingCategoryOther:         true,
+	SpendingCategoryUncategorized: true,
+}
+
+// Validate валидирует правило кэшбэка
+func (r *CashbackRule) Validate() error {
+	if (r.MCC == "") == (r.Category == "") {
+		return ErrInvalidCashbackRule
+	}
+	if r.MCC != "" {
+		if err := ValidateMCC(r.MCC); err != nil {
+			return err
+		}
+	}
+	if r.Category != "" && !spendingCategories[r.Category] {
+		return ErrInvalidCategory
+	}
+	if r.Rate < 0 || r.Rate > 100 {
+		return ErrInvalidCashbackRate
+	}
+	if r.MonthlyCap < 0 {
+		return ErrInvalidCashbackCap
+	}
+	return nil
+}
+
+// CashbackPeriod возвращает месяц выплаты кэшбэка (первое число месяца по дате в часовом поясе банка)
+func CashbackPeriod(t time.Time, loc *time.Location) time.Time {
+	local := t.In(loc)
+	return time.Date(local.Year(), local.Month(), 1, 0, 0, 0, 0, time.UTC)
+}
+
+// CashbackAmount рассчитывает кэшбэк по ставке с учетом оставшихся лимитов (отрицательный лимит — без ограничения)
+func CashbackAmount(base, rate float64, limits ...float64) float64 {
+	amount := math.Floor(base*rate+1e-9) / 100 // Кэшбэк округляется вниз до копеек
+	for _, limit := range limits {
+		if limit >= 0 && amount > limit {
+			amount = limit
+		}
+	}
+	if amount < 0 {
+		return 0
+	}
+	return math.Round(amount*100) / 100
+}
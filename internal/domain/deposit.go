@@ -0,0 +1,248 @@
+package domain
+
+import (
+	"errors"
+	"math"
+	"time"
+)
+
+// Deposit представляет срочный или накопительный вклад
+type Deposit struct {
+	ID              int        `json:"id" db:"id"`
+	UserID          int        `json:"user_id" db:"user_id"`
+	AccountID       int        `json:"account_id" db:"account_id"`
+	Product         string     `json:"product" db:"product"`
+	Kind            string     `json:"kind" db:"kind"`
+	Amount          float64    `json:"amount" db:"amount"`
+	Balance         float64    `json:"balance" db:"balance"`
+	InterestRate    float64    `json:"interest_rate" db:"interest_rate"`
+	Capitalization  string     `json:"capitalization" db:"capitalization"`
+	TermMonths      int        `json:"term_months" db:"term_months"`
+	OpenedOn        time.Time  `json:"opened_on" db:"opened_on"`
+	MaturityDate    *time.Time `json:"maturity_date" db:"maturity_date"`
+	AccruedInterest float64    `json:"accrued_interest" db:"accrued_interest"`
+	InterestPaid    float64    `json:"interest_paid" db:"interest_paid"`
+	Payout          *float64   `json:"payout" db:"payout"`
+	Status          string     `json:"status" db:"status"`
+	ClosedAt        *time.Time `json:"closed_at" db:"closed_at"`
+	CreatedAt       time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt       time.Time  `json:"updated_at" db:"updated_at"`
+}
+
+// DepositProduct условия депозитного продукта. Ставка задается надбавкой к ключевой ставке ЦБ РФ
+// и фиксируется при открытии.
+type DepositProduct struct {
+	Code            string   `json:"code"`
+	Name            string   `json:"name"`
+	Kind            string   `json:"kind"`
+	MinAmount       float64  `json:"min_amount"`
+	MinTermMonths   int      `json:"min_term_months"`
+	MaxTermMonths   int      `json:"max_term_months"`
+	RateSpread      float64  `json:"rate_spread"`
+	Capitalizations []string `json:"capitalizations"`
+}
+
+// DepositProjection прогноз выплаты по вкладу
+type DepositProjection struct {
+	AsOf              time.Time `json:"as_of"`
+	PayoutDate        time.Time `json:"payout_date"`
+	Balance           float64   `json:"balance"`
+	ProjectedInterest float64   `json:"projected_interest"`
+	ProjectedPayout   float64   `json:"projected_payout"`
+	EarlyRate         float64   `json:"early_rate"`
+	EarlyPayout       float64   `json:"early_payout"`
+}
+
+// OpenDepositRequest представляет запрос на открытие вклада
+type OpenDepositRequest struct {
+	AccountID      int     `json:"account_id"`
+	Product        string  `json:"product"`
+	Amount         float64 `json:"amount"`
+	TermMonths     int     `json:"term_months"`
+	Capitalization string  `json:"capitalization"`
+}
+
+// DepositKind определяет виды вкладов
+const (
+	DepositKindTerm = "term"
+	// DepositKindFlexible вклад без срока с пополнением и снятием; не путать с продуктом счета savings
+	DepositKindFlexible = "flexible"
+)
+
+// DepositCapitalization определяет порядок капитализации процентов
+const (
+	// DepositCapitalizationMonthly проценты прибавляются к вкладу в последний день месяца
+	DepositCapitalizationMonthly = "monthly"
+	// DepositCapitalizationEnd проценты выплачиваются вместе с вкладом в конце срока
+	DepositCapitalizationEnd = "end"
+)
+
+// DepositStatus определяет статусы вклада
+const (
+	DepositStatusActive  = "active"
+	DepositStatusMatured = "matured"
+	DepositStatusClosed  = "closed"
+)
+
+const (
+	// DemandDepositRate ставка «до востребования» при досрочном расторжении в первой половине срока
+	DemandDepositRate = 0.01
+	// MinDepositRate минимальная ставка продукта при низкой ключевой ставке
+	MinDepositRate = 0.01
+	// earlyRateShare доля ставки договора при досрочном расторжении во второй половине срока
+	earlyRateShare = 2.0 / 3.0
+)
+
+// Domain errors
+var (
+	ErrDepositNotFound              = errors.New("deposit not found")
+	ErrDepositProductNotFound       = errors.New("deposit product not found")
+	ErrInvalidDepositTerm           = errors.New("invalid deposit term")
+	ErrDepositAmountTooSmall        = errors.New("deposit amount is below product minimum")
+	ErrInvalidDepositCapitalization = errors.New("invalid deposit capitalization")
+	ErrDepositNotActive             = errors.New("deposit is not active")
+	ErrDepositInsufficientFunds     = errors.New("insufficient funds to open deposit")
+)
+
+// DepositProducts каталог депозитных продуктов
+var DepositProducts = []DepositProduct{
+	{
+		Code:            "term",
+		Name:            "Срочный вклад",
+		Kind:            DepositKindTerm,
+		MinAmount:       10000,
+		MinTermMonths:   3,
+		MaxTermMonths:   36,
+		RateSpread:      -1.0,
+		Capitalizations: []string{DepositCapitalizationMonthly, DepositCapitalizationEnd},
+	},
+	{
+		Code:            "flexible",
+		Name:            "Накопительный вклад",
+		Kind:            DepositKindFlexible,
+		MinAmount:       1,
+		RateSpread:      -3.0,
+		Capitalizations: []string{DepositCapitalizationMonthly},
+	},
+}
+
+// FindDepositProduct находит продукт по коду
+func FindDepositProduct(code string) (*DepositProduct, error) {
+	for i := range DepositProducts {
+		if DepositProducts[i].Code == code {
+			return &DepositProducts[i], nil
+		}
+	}
+	return nil, ErrDepositProductNotFound
+}
+
+// Rate рассчитывает ставку продукта от ключевой ставки
+func (p *DepositProduct) Rate(keyRate float64) float64 {
+	rate := math.Round((keyRate+p.RateSpread)*100) / 100
+	if rate < MinDepositRate {
+		return MinDepositRate
+	}
+	return rate
+}
+
+// Validate проверяет запрос на соответствие условиям продукта
+func (r *OpenDepositRequest) Validate(product *DepositProduct) error {
+	if r.AccountID <= 0 {
+		return ErrInvalidAccountID
+	}
+	if r.Amount <= 0 || r.Amount < product.MinAmount {
+		return ErrDepositAmountTooSmall
+	}
+
+	if product.Kind == DepositKindTerm {
+		if r.TermMonths < product.MinTermMonths || r.TermMonths > product.MaxTermMonths {
+			return ErrInvalidDepositTerm
+		}
+	} else if r.TermMonths != 0 {
+		return ErrInvalidDepositTerm
+	}
+
+	for _, capitalization := range product.Capitalizations {
+		if r.Capitalization == capitalization {
+			return nil
+		}
+	}
+	return ErrInvalidDepositCapitalization
+}
+
+// IsActive проверяет, активен ли вклад
+func (d *Deposit) IsActive() bool {
+	return d.Status == DepositStatusActive
+}
+
+// Settle закрывает вклад с выплатой; проценты, не дотянувшие до копейки, не выплачиваются
+func (d *Deposit) Settle(status string, payout float64, at time.Time) {
+	payout = math.Round(payout*100) / 100
+	d.Status = status
+	d.Payout = &payout
+	d.InterestPaid = math.Round((payout-d.Amount)*100) / 100
+	d.Balance = 0
+	d.AccruedInterest = 0
+	d.ClosedAt = &at
+}
+
+// EarlyWithdrawalRate возвращает ставку, по которой пересчитываются проценты при закрытии на дату.
+// Накопительный вклад закрывается без потери процентов; срочный вклад в первой половине срока
+// пересчитывается по ставке до востребования, во второй — по 2/3 ставки договора.
+func (d *Deposit) EarlyWithdrawalRate(date time.Time) float64 {
+	if d.Kind != DepositKindTerm || d.MaturityDate == nil || !date.Before(*d.MaturityDate) {
+		return d.InterestRate
+	}
+
+	held := date.Sub(d.OpenedOn)
+	term := d.MaturityDate.Sub(d.OpenedOn)
+	if held*2 < term {
+		return DemandDepositRate
+	}
+	return math.Round(d.InterestRate*earlyRateShare*100) / 100
+}
+
+// EarlyPayout рассчитывает сумму выплаты при закрытии вклада до окончания операционного дня date.
+// Для срочного вклада проценты пересчитываются на первоначальную сумму без капитализации
+// за закрытые дни; ранее капитализированные проценты не выплачиваются.
+func (d *Deposit) EarlyPayout(date time.Time) (payout, rate float64) {
+	rate = d.EarlyWithdrawalRate(date)
+	if rate == d.InterestRate {
+		return d.Balance + FloorKopecks(d.AccruedInterest), rate
+	}
+
+	var interest float64
+	for day := d.OpenedOn.AddDate(0, 0, 1); day.Before(date); day = day.AddDate(0, 0, 1) {
+		interest += DailyInterest(d.Amount, rate, day)
+	}
+	return d.Amount + FloorKopecks(interest), rate
+}
+
+// Project рассчитывает выплату на дату to, начисляя проценты за дни с from по to включительно
+// с капитализацией в последний день месяца
+func (d *Deposit) Project(from, to time.Time) *DepositProjection {
+	balance := d.Balance
+	accrued := d.AccruedInterest
+
+	for day := from; !day.After(to); day = day.AddDate(0, 0, 1) {
+		accrued += DailyInterest(balance, d.InterestRate, day)
+		if d.Capitalization == DepositCapitalizationMonthly && IsMonthEnd(day) && !day.Equal(to) {
+			capitalized := FloorKopecks(accrued)
+			balance += capitalized
+			accrued -= capitalized
+		}
+	}
+
+	payout := math.Round((balance+FloorKopecks(accrued))*100) / 100
+	earlyPayout, earlyRate := d.EarlyPayout(from)
+
+	return &DepositProjection{
+		AsOf:              from,
+		PayoutDate:        to,
+		Balance:           d.Balance,
+		ProjectedInterest: math.Round((payout-d.Balance)*100) / 100,
+		ProjectedPayout:   payout,
+		EarlyRate:         earlyRate,
+		EarlyPayout:       math.Round(earlyPayout*100) / 100,
+	}
+}
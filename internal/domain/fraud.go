@@ -0,0 +1,139 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// FraudOperation денежная операция, проверяемая правилами антифрода перед исполнением
+type FraudOperation struct {
+	Type        string
+	UserID      int
+	AccountID   int
+	ToAccountID *int   // Счет зачисления перевода
+	ToUserID    *int   // Владелец счета зачисления
+	CardID      *int   // Карта платежа
+	MerchantID  string // ТСП платежа
+	Amount      float64
+	IP          string // Из метаданных запроса; пусто для фоновых операций
+	Country     string // ISO 3166-1 alpha-2 страна клиента по GeoIP
+	At          time.Time
+}
+
+// OutgoingStats исходящие операции клиента за окно проверки частоты
+type OutgoingStats struct {
+	Count  int
+	Amount float64
+}
+
+// FraudOperationType определяет виды проверяемых операций
+const (
+	FraudOperationTransfer    = "transfer"
+	FraudOperationWithdrawal  = "withdrawal"
+	FraudOperationCardPayment = "card_payment"
+)
+
+// FraudDecision определяет решения по операции в порядке возрастания строгости
+const (
+	FraudDecisionAllow  = "allow"
+	FraudDecisionReview = "review"
+	FraudDecisionReject = "reject"
+)
+
+// FraudRule определяет правила антифрода
+const (
+	// FraudRuleMandatoryControl операция на сумму обязательного контроля (115-ФЗ)
+	FraudRuleMandatoryControl = "mandatory_control"
+	// FraudRuleAmountLimit сумма операции выше предельной
+	FraudRuleAmountLimit    = "amount_limit"
+	FraudRuleVelocityCount  = "velocity_count"
+	FraudRuleVelocityAmount = "velocity_amount"
+	// FraudRuleNewRecipient крупный перевод чужому счету, на который клиент раньше не переводил
+	FraudRuleNewRecipient = "new_recipient"
+	FraudRuleUnusualHour  = "unusual_hour"
+	FraudRuleUnusualGeo   = "unusual_geo"
+	FraudRuleBlockedGeo   = "blocked_geo"
+)
+
+// FraudRuleHit сработавшее правило и решение, которое оно требует
+type FraudRuleHit struct {
+	Rule     string `json:"rule"`
+	Decision string `json:"decision"`
+	Reason   string `json:"reason"`
+}
+
+// FraudDecisionFor возвращает самое строгое решение среди сработавших правил
+func FraudDecisionFor(hits []FraudRuleHit) string {
+	decision := FraudDecisionAllow
+	for _, hit := range hits {
+		switch hit.Decision {
+		case FraudDecisionReject:
+			return FraudDecisionReject
+		case FraudDecisionReview:
+			decision = FraudDecisionReview
+		}
+	}
+	return decision
+}
+
+// FraudReview операция, отложенная до решения оператора.
+// Одобренная проверка разрешает клиенту повторить ту же операцию один раз (ConsumedAt).
+type FraudReview struct {
+	ID            int            `json:"id" db:"id"`
+	UserID        int            `json:"user_id" db:"user_id"`
+	OperationType string         `json:"operation_type" db:"operation_type"`
+	AccountID     int            `json:"account_id" db:"account_id"`
+	ToAccountID   *int           `json:"to_account_id" db:"to_account_id"`
+	CardID        *int           `json:"card_id" db:"card_id"`
+	MerchantID    string         `json:"merchant_id" db:"merchant_id"`
+	Amount        float64        `json:"amount" db:"amount"`
+	IP            string         `json:"ip" db:"ip"`
+	Country       string         `json:"country" db:"country"`
+	Rules         []FraudRuleHit `json:"rules" db:"rules"`
+	Status        string         `json:"status" db:"status"`
+	ReviewerID    *int           `json:"reviewer_id" db:"reviewer_id"`
+	Comment       string         `json:"comment" db:"comment"`
+	ReviewedAt    *time.Time     `json:"reviewed_at" db:"reviewed_at"`
+	ConsumedAt    *time.Time     `json:"consumed_at" db:"consumed_at"`
+	CreatedAt     time.Time      `json:"created_at" db:"created_at"`
+	UpdatedAt     time.Time      `json:"updated_at" db:"updated_at"`
+}
+
+// FraudReviewStatus определяет статусы проверки
+const (
+	FraudReviewStatusPending  = "pending"
+	FraudReviewStatusApproved = "approved"
+	FraudReviewStatusDeclined = "declined"
+)
+
+// IsValidFraudReviewStatus проверяет статус проверки
+func IsValidFraudReviewStatus(status string) bool {
+	switch status {
+	case FraudReviewStatusPending, FraudReviewStatusApproved, FraudReviewStatusDeclined:
+		return true
+	}
+	return false
+}
+
+// Fraud errors
+var (
+	ErrOperationRejected        = errors.New("operation rejected by fraud screening")
+	ErrOperationUnderReview     = errors.New("operation is held for review")
+	ErrFraudReviewNotFound      = errors.New("fraud review not found")
+	ErrFraudReviewResolved      = errors.New("fraud review is already resolved")
+	ErrInvalidFraudReviewStatus = errors.New("invalid fraud review status")
+)
+
+// FraudReviewError операция отложена до решения оператора по проверке ReviewID
+type FraudReviewError struct {
+	ReviewID int
+}
+
+func (e *FraudReviewError) Error() string {
+	return fmt.Sprintf("%s (review %d)", ErrOperationUnderReview, e.ReviewID)
+}
+
+func (e *FraudReviewError) Unwrap() error {
+	return ErrOperationUnderReview
+}
@@ -0,0 +1,60 @@
+package domain
+
+import (
+	"errors"
+	"time"
+)
+
+// GatewayMessage запрос, полученный шлюзом карточных сообщений, и ответ на него.
+// Запись по (MTI, RRN, терминал) защищает от повторной обработки ретрансляций
+// и позволяет найти исходную операцию при реверсе.
+type GatewayMessage struct {
+	ID           int64     `json:"id" db:"id"`
+	MTI          string    `json:"mti" db:"mti"`
+	STAN         string    `json:"stan" db:"stan"`
+	RRN          string    `json:"rrn" db:"rrn"`
+	TerminalID   string    `json:"terminal_id" db:"terminal_id"`
+	MerchantID   string    `json:"merchant_id" db:"merchant_id"`
+	Amount       float64   `json:"amount" db:"amount"`
+	CardID       *int      `json:"card_id" db:"card_id"`
+	HoldID       *int      `json:"hold_id" db:"hold_id"`
+	ResponseCode string    `json:"response_code" db:"response_code"` // Пусто, пока запрос обрабатывается
+	AuthCode     string    `json:"auth_code" db:"auth_code"`
+	CreatedAt    time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// Коды ответа (поле 39)
+const (
+	GatewayResponseApproved          = "00"
+	GatewayResponseInvalidMerchant   = "03"
+	GatewayResponseInProgress        = "09"
+	GatewayResponseInvalidTxn        = "12"
+	GatewayResponseInvalidAmount     = "13"
+	GatewayResponseInvalidCard       = "14"
+	GatewayResponseOriginalNotFound  = "25"
+	GatewayResponseFormatError       = "30"
+	GatewayResponseInsufficientFunds = "51"
+	GatewayResponseExpiredCard       = "54"
+	GatewayResponseNotPermitted      = "57"
+	GatewayResponseSuspectedFraud    = "59"
+	GatewayResponseExceedsLimit      = "61"
+	GatewayResponseRestrictedCard    = "62"
+	GatewayResponseSecurityViolation = "63"
+	GatewayResponseSystemError       = "96"
+	GatewayResponseCVVFailure        = "N7"
+)
+
+// GatewayCurrencyRUB код валюты рубля по ISO 4217 (поле 49)
+const GatewayCurrencyRUB = "643"
+
+// Domain errors
+var (
+	ErrGatewayMessageNotFound  = errors.New("gateway message not found")
+	ErrGatewayMessageDuplicate = errors.New("gateway message already received")
+)
+
+// IsFinished проверяет, получен ли ответ на запрос
+func (m *GatewayMessage) IsFinished() bool {
+	return m.ResponseCode != ""
+}
@@ -0,0 +1,127 @@
+package domain
+
+import (
+	"errors"
+	"math"
+	"time"
+)
+
+// CardHold блокировка средств на счете по авторизации платежа картой.
+// Пока холд авторизован, сумма входит в Account.HeldAmount и недоступна для трат,
+// но остается на учетном остатке до списания (capture).
+type CardHold struct {
+	ID             int     `json:"id" db:"id"`
+	CardID         int     `json:"card_id" db:"card_id"`
+	AccountID      int     `json:"account_id" db:"account_id"`
+	TransactionID  int     `json:"transaction_id" db:"transaction_id"`
+	MerchantID     string  `json:"merchant_id" db:"merchant_id"`
+	Description    string  `json:"description" db:"description"`
+	Amount         float64 `json:"amount" db:"amount"`                   // Авторизованная сумма
+	CapturedAmount float64 `json:"captured_amount" db:"captured_amount"` // Списанная сумма, не больше авторизованной
+	RefundedAmount float64 `json:"refunded_amount" db:"refunded_amount"` // Возвращенная сумма, не больше списанной
+	Status         string  `json:"status" db:"status"`
+	// InitiatedBy пользователь, создавший авторизацию через API; nil — авторизация из шлюза эквайринга
+	InitiatedBy *int       `json:"initiated_by,omitempty" db:"initiated_by"`
+	ExpiresAt   time.Time  `json:"expires_at" db:"expires_at"`
+	CapturedAt  *time.Time `json:"captured_at" db:"captured_at"`
+	CreatedAt   time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at" db:"updated_at"`
+}
+
+// CardHoldStatus определяет статусы холда
+const (
+	CardHoldStatusAuthorized = "authorized"
+	CardHoldStatusCaptured   = "captured"
+	// CardHoldStatusVoided авторизация отменена, средства разблокированы
+	CardHoldStatusVoided = "voided"
+	// CardHoldStatusExpired авторизация не списана в срок и снята автоматически
+	CardHoldStatusExpired = "expired"
+)
+
+// Domain errors
+var (
+	ErrCardHoldNotFound      = errors.New("card hold not found")
+	ErrCardHoldNotAuthorized = errors.New("card hold is not authorized")
+	ErrCardHoldNotCaptured   = errors.New("card hold is not captured")
+	ErrCardHoldExpired       = errors.New("card hold is expired")
+	ErrInvalidCaptureAmount  = errors.New("capture amount must be positive and not exceed the authorized amount")
+	ErrInvalidRefundAmount   = errors.New("refund amount must be positive and not exceed the captured amount")
+	ErrInvalidMerchantID     = errors.New("merchant_id is required")
+	ErrHoldInsufficientFunds = errors.New("insufficient available funds for authorization")
+)
+
+// IsExpired проверяет, истек ли срок авторизованного холда
+func (h *CardHold) IsExpired(now time.Time) bool {
+	return h.Status == CardHoldStatusAuthorized && !now.Before(h.ExpiresAt)
+}
+
+// Capture списывает авторизованную сумму полностью (amount = 0) или частично.
+// Остаток авторизации при частичном списании разблокируется.
+func (h *CardHold) Capture(amount float64, now time.Time) error {
+	if h.Status != CardHoldStatusAuthorized {
+		return ErrCardHoldNotAuthorized
+	}
+	if h.IsExpired(now) {
+		return ErrCardHoldExpired
+	}
+	if amount == 0 {
+		amount = h.Amount
+	}
+	amount = math.Round(amount*100) / 100
+	if amount <= 0 || amount > h.Amount {
+		return ErrInvalidCaptureAmount
+	}
+
+	h.CapturedAmount = amount
+	h.Status = CardHoldStatusCaptured
+	h.CapturedAt = &now
+	return nil
+}
+
+// Void отменяет авторизацию
+func (h *CardHold) Void() error {
+	if h.Status != CardHoldStatusAuthorized {
+		return ErrCardHoldNotAuthorized
+	}
+	h.Status = CardHoldStatusVoided
+	return nil
+}
+
+// Expire снимает просроченную авторизацию
+func (h *CardHold) Expire(now time.Time) error {
+	if h.Status != CardHoldStatusAuthorized {
+		return ErrCardHoldNotAuthorized
+	}
+	if !h.IsExpired(now) {
+		return ErrCardHoldNotAuthorized
+	}
+	h.Status = CardHoldStatusExpired
+	return nil
+}
+
+// RefundableAmount возвращает сумму, которую еще можно вернуть по списанному холду
+func (h *CardHold) RefundableAmount() float64 {
+	if h.Status != CardHoldStatusCaptured {
+		return 0
+	}
+	return math.Round((h.CapturedAmount-h.RefundedAmount)*100) / 100
+}
+
+// Refund возвращает всю оставшуюся (amount = 0) или часть списанной суммы.
+// Возвратов по одному платежу может быть несколько.
+func (h *CardHold) Refund(amount float64) (float64, error) {
+	if h.Status != CardHoldStatusCaptured {
+		return 0, ErrCardHoldNotCaptured
+	}
+	refundable := h.RefundableAmount()
+	if amount == 0 {
+		amount = refundable
+	}
+	amount = math.Round(amount*100) / 100
+	if amount <= 0 || amount > refundable {
+		return 0, ErrInvalidRefundAmount
+	}
+
+	h.RefundedAmount = math.Round((h.RefundedAmount+amount)*100) / 100
+	return amount, nil
+}
@@ -0,0 +1,95 @@
+package domain
+
+import (
+	"math"
+	"time"
+)
+
+// BusinessDay закрытый операционный день с итогами начислений
+type BusinessDay struct {
+	Date              time.Time `json:"business_date" db:"business_date"`
+	CreditsAccrued    int       `json:"credits_accrued" db:"credits_accrued"`
+	AccountsAccrued   int       `json:"accounts_accrued" db:"accounts_accrued"`
+	CreditInterest    float64   `json:"credit_interest" db:"credit_interest"`
+	SavingsInterest   float64   `json:"savings_interest" db:"savings_interest"`
+	SavingsPosted     float64   `json:"savings_posted" db:"savings_posted"`
+	DepositsAccrued   int       `json:"deposits_accrued" db:"deposits_accrued"`
+	DepositInterest   float64   `json:"deposit_interest" db:"deposit_interest"`
+	DepositsMatured   int       `json:"deposits_matured" db:"deposits_matured"`
+	OverdraftsAccrued int       `json:"overdrafts_accrued" db:"overdrafts_accrued"`
+	OverdraftInterest float64   `json:"overdraft_interest" db:"overdraft_interest"`
+	OverdraftCharged  float64   `json:"overdraft_charged" db:"overdraft_charged"` // Списано со счетов целыми копейками
+	ClosedAt          time.Time `json:"closed_at" db:"closed_at"`
+}
+
+// InterestAccrual начисление процентов за один день
+type InterestAccrual struct {
+	ID           int64     `json:"id" db:"id"`
+	BusinessDate time.Time `json:"business_date" db:"business_date"`
+	Kind         string    `json:"kind" db:"kind"`
+	CreditID     *int      `json:"credit_id" db:"credit_id"`
+	DepositID    *int      `json:"deposit_id" db:"deposit_id"`
+	AccountID    int       `json:"account_id" db:"account_id"`
+	BaseAmount   float64   `json:"base_amount" db:"base_amount"`
+	AnnualRate   float64   `json:"annual_rate" db:"annual_rate"`
+	Amount       float64   `json:"amount" db:"amount"`
+	CreatedAt    time.Time `json:"created_at" db:"created_at"`
+}
+
+// AccruedSavings накопленные проценты на остаток счета, ожидающие зачисления
+type AccruedSavings struct {
+	AccountID int     `json:"account_id" db:"id"`
+	Amount    float64 `json:"amount" db:"accrued_interest"`
+}
+
+// AccruedOverdraftInterest начисленные проценты за овердрафт, еще не списанные со счета
+type AccruedOverdraftInterest struct {
+	AccountID int     `json:"account_id" db:"id"`
+	Amount    float64 `json:"amount" db:"overdraft_interest"`
+}
+
+// InterestAccrualKind определяет виды начислений
+const (
+	InterestAccrualCredit    = "credit"
+	InterestAccrualSavings   = "savings"
+	InterestAccrualDeposit   = "deposit"
+	InterestAccrualOverdraft = "overdraft"
+)
+
+// accrualPrecision точность хранения дневных начислений (знаков после запятой)
+const accrualPrecision = 1e6
+
+// DailyInterest рассчитывает проценты за день по годовой ставке (в процентах)
+// с учетом фактического числа дней в году (ACT/ACT)
+func DailyInterest(base, annualRate float64, date time.Time) float64 {
+	if base <= 0 || annualRate <= 0 {
+		return 0
+	}
+
+	interest := base * annualRate / 100 / float64(DaysInYear(date.Year()))
+	return math.Round(interest*accrualPrecision) / accrualPrecision
+}
+
+// DaysInYear возвращает количество дней в году
+func DaysInYear(year int) int {
+	if time.Date(year, time.December, 31, 0, 0, 0, 0, time.UTC).YearDay() == 366 {
+		return 366
+	}
+	return 365
+}
+
+// BusinessDate возвращает календарную дату момента в зоне банка (полночь UTC этой даты)
+func BusinessDate(t time.Time, loc *time.Location) time.Time {
+	local := t.In(loc)
+	return time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// IsMonthEnd проверяет, является ли дата последним днем месяца
+func IsMonthEnd(date time.Time) bool {
+	return date.AddDate(0, 0, 1).Day() == 1
+}
+
+// FloorKopecks округляет сумму вниз до копеек; остаток продолжает накапливаться
+func FloorKopecks(amount float64) float64 {
+	return math.Floor(amount*100+1e-6) / 100
+}
@@ -0,0 +1,68 @@
+package domain
+
+import (
+	"errors"
+	"time"
+)
+
+// Job состояние фоновой задачи, общее для всех реплик
+type Job struct {
+	Name               string     `json:"name" db:"name"`
+	Schedule           string     `json:"schedule" db:"schedule"`
+	Paused             bool       `json:"paused" db:"paused"`
+	NextRunAt          time.Time  `json:"next_run_at" db:"next_run_at"`
+	TriggerRequestedAt *time.Time `json:"trigger_requested_at" db:"trigger_requested_at"`
+	CreatedAt          time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt          time.Time  `json:"updated_at" db:"updated_at"`
+}
+
+// JobRun запись истории запуска задачи
+type JobRun struct {
+	ID         int64      `json:"id" db:"id"`
+	JobName    string     `json:"job_name" db:"job_name"`
+	Trigger    string     `json:"trigger" db:"trigger"`
+	InstanceID string     `json:"instance_id" db:"instance_id"`
+	Status     string     `json:"status" db:"status"`
+	StartedAt  time.Time  `json:"started_at" db:"started_at"`
+	FinishedAt *time.Time `json:"finished_at" db:"finished_at"`
+	Processed  int        `json:"processed" db:"processed"`
+	Failed     int        `json:"failed" db:"failed"`
+	Error      string     `json:"error" db:"error"`
+}
+
+// JobRunStatus определяет статусы запуска задачи
+const (
+	JobRunStatusRunning   = "running"
+	JobRunStatusSucceeded = "succeeded"
+	JobRunStatusFailed    = "failed"
+	// JobRunStatusInterrupted запуск не завершился: реплика остановилась или потеряла лидерство
+	JobRunStatusInterrupted = "interrupted"
+)
+
+// JobTrigger определяет причины запуска задачи
+const (
+	JobTriggerSchedule = "schedule"
+	JobTriggerManual   = "manual"
+)
+
+// Job errors
+var (
+	ErrJobNotFound = errors.New("job not found")
+)
+
+// IsDue проверяет, нужно ли запустить задачу: запрошен ручной запуск
+// или наступило время по расписанию (если задача не на паузе)
+func (j *Job) IsDue(now time.Time) bool {
+	if j.TriggerRequestedAt != nil {
+		return true
+	}
+	return !j.Paused && !j.NextRunAt.After(now)
+}
+
+// TriggerFor возвращает причину запуска задачи
+func (j *Job) TriggerFor() string {
+	if j.TriggerRequestedAt != nil {
+		return JobTriggerManual
+	}
+	return JobTriggerSchedule
+}
@@ -0,0 +1,154 @@
+package domain
+
+import (
+	"errors"
+	"strings"
+	"time"
+)
+
+// KYCProfile анкета клиента для идентификации по 115-ФЗ
+type KYCProfile struct {
+	UserID               int        `json:"user_id" db:"user_id"`
+	LastName             string     `json:"last_name" db:"last_name"`
+	FirstName            string     `json:"first_name" db:"first_name"`
+	MiddleName           string     `json:"middle_name" db:"middle_name"`
+	BirthDate            *time.Time `json:"birth_date" db:"birth_date"`
+	BirthPlace           string     `json:"birth_place" db:"birth_place"`
+	PassportSeries       string     `json:"passport_series" db:"passport_series"`
+	PassportNumber       string     `json:"passport_number" db:"passport_number"`
+	PassportIssuedBy     string     `json:"passport_issued_by" db:"passport_issued_by"`
+	PassportIssueDate    *time.Time `json:"passport_issue_date" db:"passport_issue_date"`
+	PassportDivisionCode string     `json:"passport_division_code" db:"passport_division_code"`
+	INN                  string     `json:"inn" db:"inn"`
+	SNILS                string     `json:"snils" db:"snils"` // 11 цифр без разделителей
+	Address              string     `json:"address" db:"address"`
+	Phone                string     `json:"phone" db:"phone"` // +7XXXXXXXXXX
+	Status               string     `json:"status" db:"status"`
+	Level                string     `json:"level" db:"level"`
+	RejectionReason      string     `json:"rejection_reason" db:"rejection_reason"`
+	ReviewerID           *int       `json:"reviewer_id" db:"reviewer_id"`
+	SubmittedAt          *time.Time `json:"submitted_at" db:"submitted_at"`
+	ReviewedAt           *time.Time `json:"reviewed_at" db:"reviewed_at"`
+	CreatedAt            time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt            time.Time  `json:"updated_at" db:"updated_at"`
+}
+
+// KYCDocument скан документа клиента; файл хранится локально по пути StoragePath относительно каталога документов
+type KYCDocument struct {
+	ID          int       `json:"id" db:"id"`
+	UserID      int       `json:"user_id" db:"user_id"`
+	Type        string    `json:"type" db:"type"`
+	FileName    string    `json:"file_name" db:"file_name"` // Имя файла у клиента
+	ContentType string    `json:"content_type" db:"content_type"`
+	Size        int64     `json:"size" db:"size"`
+	SHA256      string    `json:"sha256" db:"sha256"`
+	StoragePath string    `json:"-" db:"storage_path"`
+	CreatedAt   time.Time `json:"created_at" db:"created_at"`
+}
+
+// KYCStatus определяет статусы идентификации клиента
+const (
+	KYCStatusUnverified = "unverified"
+	KYCStatusPending    = "pending"
+	KYCStatusVerified   = "verified"
+	KYCStatusRejected   = "rejected"
+)
+
+// KYCLevel определяет уровни идентификации, от которых зависят лимиты операций
+const (
+	// KYCLevelNone клиент не идентифицирован
+	KYCLevelNone = "none"
+	// KYCLevelSimplified упрощенная идентификация: данные анкеты проверены без документов
+	KYCLevelSimplified = "simplified"
+	// KYCLevelFull полная идентификация: данные подтверждены сканом паспорта
+	KYCLevelFull = "full"
+)
+
+// KYCDocumentType определяет виды документов
+const (
+	KYCDocumentPassport     = "passport"
+	KYCDocumentINN          = "inn"
+	KYCDocumentSNILS        = "snils"
+	KYCDocumentAddressProof = "address_proof"
+)
+
+// NewKYCProfile возвращает пустую анкету клиента, еще не заполнявшего ее
+func NewKYCProfile(userID int) *KYCProfile {
+	return &KYCProfile{UserID: userID, Status: KYCStatusUnverified, Level: KYCLevelNone}
+}
+
+// IsValidKYCStatus проверяет статус идентификации
+func IsValidKYCStatus(status string) bool {
+	switch status {
+	case KYCStatusUnverified, KYCStatusPending, KYCStatusVerified, KYCStatusRejected:
+		return true
+	}
+	return false
+}
+
+// IsVerifiedKYCLevel проверяет уровень, который оператор может присвоить при подтверждении
+func IsVerifiedKYCLevel(level string) bool {
+	return level == KYCLevelSimplified || level == KYCLevelFull
+}
+
+// IsValidKYCDocumentType проверяет вид документа
+func IsValidKYCDocumentType(docType string) bool {
+	switch docType {
+	case KYCDocumentPassport, KYCDocumentINN, KYCDocumentSNILS, KYCDocumentAddressProof:
+		return true
+	}
+	return false
+}
+
+// FullName возвращает ФИО клиента
+func (p *KYCProfile) FullName() string {
+	return strings.Join(strings.Fields(p.LastName+" "+p.FirstName+" "+p.MiddleName), " ")
+}
+
+// MissingFields возвращает незаполненные поля, без которых анкету нельзя отправить на проверку.
+// Для упрощенной идентификации достаточно ИНН или СНИЛС.
+func (p *KYCProfile) MissingFields() []string {
+	var missing []string
+	required := []struct {
+		name   string
+		filled bool
+	}{
+		{"last_name", p.LastName != ""},
+		{"first_name", p.FirstName != ""},
+		{"birth_date", p.BirthDate != nil},
+		{"passport_series", p.PassportSeries != ""},
+		{"passport_number", p.PassportNumber != ""},
+		{"passport_issued_by", p.PassportIssuedBy != ""},
+		{"passport_issue_date", p.PassportIssueDate != nil},
+		{"passport_division_code", p.PassportDivisionCode != ""},
+		{"inn_or_snils", p.INN != "" || p.SNILS != ""},
+		{"address", p.Address != ""},
+		{"phone", p.Phone != ""},
+	}
+	for _, field := range required {
+		if !field.filled {
+			missing = append(missing, field.name)
+		}
+	}
+	return missing
+}
+
+// KYC errors
+var (
+	ErrKYCProfileIncomplete    = errors.New("KYC profile is incomplete")
+	ErrKYCUnderReview          = errors.New("KYC profile is under review")
+	ErrKYCNotPending           = errors.New("KYC profile is not pending review")
+	ErrKYCAlreadyVerified      = errors.New("KYC profile is already verified")
+	ErrKYCIdentityInUse        = errors.New("passport, INN or SNILS is already registered to another customer")
+	ErrKYCPassportDocMissing   = errors.New("passport scan is required for full verification")
+	ErrKYCLimitExceeded        = errors.New("operation exceeds the limit for the customer's KYC level")
+	ErrInvalidKYCDates         = errors.New("invalid birth date or passport issue date")
+	ErrInvalidKYCStatus        = errors.New("invalid KYC status")
+	ErrInvalidKYCLevel         = errors.New("invalid KYC level")
+	ErrInvalidKYCDocumentType  = errors.New("invalid KYC document type")
+	ErrKYCDocumentTooLarge     = errors.New("KYC document is too large")
+	ErrKYCDocumentUnsupported  = errors.New("unsupported KYC document format")
+	ErrKYCDocumentNotFound     = errors.New("KYC document not found")
+	ErrKYCProfileNotFound      = errors.New("KYC profile not found")
+	ErrKYCRejectionReasonEmpty = errors.New("rejection reason is required")
+)
@@ -0,0 +1,175 @@
+package domain
+
+import (
+	"errors"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Merchant торгово-сервисное предприятие (ТСП), принимающее карты банка.
+// Платеж картой в адрес зарегистрированного ТСП зачисляется на его расчетный счет
+// за вычетом межбанковской комиссии; платежи в незарегистрированные ТСП уходят во внешнюю систему.
+type Merchant struct {
+	ID                  int       `json:"id" db:"id"`
+	Code                string    `json:"code" db:"code"` // merchant_id в платежах картой и поле 42 ISO 8583
+	Name                string    `json:"name" db:"name"`
+	MCC                 string    `json:"mcc" db:"mcc"`
+	SettlementAccountID int       `json:"settlement_account_id" db:"settlement_account_id"`
+	CreatedAt           time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt           time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// MerchantSettlement итоги расчетов с ТСП за операционный день
+type MerchantSettlement struct {
+	ID            int64     `json:"id" db:"id"`
+	MerchantID    int       `json:"merchant_id" db:"merchant_id"`
+	BusinessDate  time.Time `json:"business_date" db:"business_date"`
+	PaymentsCount int       `json:"payments_count" db:"payments_count"`
+	GrossAmount   float64   `json:"gross_amount" db:"gross_amount"`   // Списанные платежи
+	RefundAmount  float64   `json:"refund_amount" db:"refund_amount"` // Возвраты покупателям
+	FeeAmount     float64   `json:"fee_amount" db:"fee_amount"`       // Межбанковская комиссия
+	NetAmount     float64   `json:"net_amount" db:"net_amount"`       // Зачислено на расчетный счет
+	CreatedAt     time.Time `json:"created_at" db:"created_at"`
+}
+
+// CreateMerchantRequest представляет запрос на регистрацию ТСП
+type CreateMerchantRequest struct {
+	Code                string `json:"code"`
+	Name                string `json:"name"`
+	MCC                 string `json:"mcc"`
+	SettlementAccountID int    `json:"settlement_account_id"`
+}
+
+// MCCSpending расходы по одному коду категории ТСП
+type MCCSpending struct {
+	MCC    string  `json:"mcc" db:"mcc"`
+	Amount float64 `json:"amount" db:"amount"`
+}
+
+// SpendingCategory определяет категории расходов по MCC
+const (
+	SpendingCategoryGroceries     = "groceries"
+	SpendingCategoryRestaurants   = "restaurants"
+	SpendingCategoryTransport     = "transport"
+	SpendingCategoryFuel          = "fuel"
+	SpendingCategoryTravel        = "travel"
+	SpendingCategoryHealth        = "health"
+	SpendingCategoryEntertainment = "entertainment"
+	SpendingCategoryUtilities     = "utilities"
+	SpendingCategoryShopping      = "shopping"
+	SpendingCategoryOther         = "other"
+	// SpendingCategoryUncategorized платежи без MCC (в незарегистрированные ТСП)
+	SpendingCategoryUncategorized = "uncategorized"
+)
+
+// mccCategories категории отдельных кодов; диапазоны разбираются в MCCCategory
+var mccCategories = map[int]string{
+	4111: SpendingCategoryTransport,
+	4121: SpendingCategoryTransport,
+	4131: SpendingCategoryTransport,
+	4784: SpendingCategoryTransport,
+	4789: SpendingCategoryTransport,
+	4411: SpendingCategoryTravel,
+	4511: SpendingCategoryTravel,
+	4722: SpendingCategoryTravel,
+	7011: SpendingCategoryTravel,
+	7512: SpendingCategoryTravel,
+	5541: SpendingCategoryFuel,
+	5542: SpendingCategoryFuel,
+	4812: SpendingCategoryUtilities,
+	4814: SpendingCategoryUtilities,
+	4899: SpendingCategoryUtilities,
+	4900: SpendingCategoryUtilities,
+	5411: SpendingCategoryGroceries,
+	5422: SpendingCategoryGroceries,
+	5441: SpendingCategoryGroceries,
+	5451: SpendingCategoryGroceries,
+	5462: SpendingCategoryGroceries,
+	5499: SpendingCategoryGroceries,
+	5811: SpendingCategoryRestaurants,
+	5812: SpendingCategoryRestaurants,
+	5813: SpendingCategoryRestaurants,
+	5814: SpendingCategoryRestaurants,
+	5912: SpendingCategoryHealth,
+	5122: SpendingCategoryHealth,
+	7832: SpendingCategoryEntertainment,
+	7841: SpendingCategoryEntertainment,
+}
+
+// Merchant errors
+var (
+	ErrMerchantNotFound          = errors.New("merchant not found")
+	ErrMerchantCodeTaken         = errors.New("merchant code is already registered")
+	ErrInvalidMerchantCode       = errors.New("merchant code is required and must not exceed 100 characters")
+	ErrInvalidMerchantName       = errors.New("merchant name is required and must not exceed 255 characters")
+	ErrInvalidMCC                = errors.New("mcc must contain 4 digits")
+	ErrMerchantSelfPayment       = errors.New("card account cannot pay to its own merchant settlement account")
+	ErrMerchantInsufficientFunds = errors.New("insufficient funds on merchant settlement account")
+	ErrTerminalTaken             = errors.New("terminal is already registered")
+	ErrInvalidTerminalID         = errors.New("terminal_id must contain 1 to 8 characters without spaces")
+)
+
+// MaxTerminalIDLength длина идентификатора терминала (поле 41 ISO 8583)
+const MaxTerminalIDLength = 8
+
+// ValidateTerminalID проверяет идентификатор терминала ТСП
+func ValidateTerminalID(terminalID string) error {
+	if terminalID == "" || len(terminalID) > MaxTerminalIDLength || strings.ContainsAny(terminalID, " \t\r\n") {
+		return ErrInvalidTerminalID
+	}
+	return nil
+}
+
+// ValidateMCC проверяет код категории ТСП (ISO 18245)
+func ValidateMCC(mcc string) error {
+	if len(mcc) != 4 {
+		return ErrInvalidMCC
+	}
+	if _, err := strconv.Atoi(mcc); err != nil {
+		return ErrInvalidMCC
+	}
+	return nil
+}
+
+// MCCCategory возвращает категорию расходов по MCC
+func MCCCategory(mcc string) string {
+	if mcc == "" {
+		return SpendingCategoryUncategorized
+	}
+	code, err := strconv.Atoi(mcc)
+	if err != nil {
+		return SpendingCategoryOther
+	}
+
+	if category, ok := mccCategories[code]; ok {
+		return category
+	}
+
+	switch {
+	case code >= 3000 && code <= 3999: // Авиакомпании, отели и прокат автомобилей
+		return SpendingCategoryTravel
+	case code >= 8011 && code <= 8099: // Медицинские услуги
+		return SpendingCategoryHealth
+	case code >= 7911 && code <= 7999: // Развлечения и отдых
+		return SpendingCategoryEntertainment
+	case code >= 5200 && code <= 5999: // Розничная торговля
+		return SpendingCategoryShopping
+	default:
+		return SpendingCategoryOther
+	}
+}
+
+// InterchangeFee рассчитывает комиссию с суммы платежа по ставке в процентах (с округлением до копеек)
+func InterchangeFee(amount, percent float64) float64 {
+	if amount <= 0 || percent <= 0 {
+		return 0
+	}
+	return math.Round(amount*percent) / 100
+}
+
+// Settle рассчитывает сумму зачисления на расчетный счет
+func (s *MerchantSettlement) Settle() {
+	s.NetAmount = math.Round((s.GrossAmount-s.RefundAmount-s.FeeAmount)*100) / 100
+}
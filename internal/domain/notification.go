@@ -0,0 +1,200 @@
+package domain
+
+import (
+	"errors"
+	"net/url"
+	"regexp"
+	"time"
+)
+
+// Notification уведомление во входящих пользователя (in-app inbox)
+type Notification struct {
+	ID        int64      `json:"id" db:"id"`
+	UserID    int        `json:"user_id" db:"user_id"`
+	EventType string     `json:"event_type" db:"event_type"`
+	Title     string     `json:"title" db:"title"`
+	Message   string     `json:"message" db:"message"`
+	ReadAt    *time.Time `json:"read_at" db:"read_at"`
+	CreatedAt time.Time  `json:"created_at" db:"created_at"`
+}
+
+// NotificationPreference настройка канала доставки для типа события
+type NotificationPreference struct {
+	UserID    int    `json:"user_id" db:"user_id"`
+	EventType string `json:"event_type" db:"event_type"`
+	Channel   string `json:"channel" db:"channel"`
+	Enabled   bool   `json:"enabled" db:"enabled"`
+}
+
+// NotificationEndpoint адрес доставки внешнего канала (URL webhook или номер телефона)
+type NotificationEndpoint struct {
+	UserID    int       `json:"user_id" db:"user_id"`
+	Channel   string    `json:"channel" db:"channel"`
+	Address   string    `json:"address" db:"address"`
+	Secret    string    `json:"-" db:"secret"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// NotificationEvent событие, о котором уведомляется пользователь
+type NotificationEvent struct {
+	Type    string
+	Title   string
+	Message string
+	// Data дополнительные поля для webhook payload
+	Data map[string]interface{}
+}
+
+// WebhookPayload тело запроса, отправляемого на webhook пользователя
+type WebhookPayload struct {
+	Event     string                 `json:"event"`
+	Title     string                 `json:"title"`
+	Message   string                 `json:"message"`
+	Data      map[string]interface{} `json:"data,omitempty"`
+	CreatedAt time.Time              `json:"created_at"`
+}
+
+// NotificationEventType определяет типы событий уведомлений
+const (
+	NotificationEventDeposit      = "deposit"
+	NotificationEventCardPayment  = "card_payment"
+	NotificationEventOverdue      = "overdue"
+	NotificationEventCreditIssued = "credit_issued"
+	// NotificationEventStandingOrderFailed платеж по постоянному поручению не исполнен
+	NotificationEventStandingOrderFailed = "standing_order_failed"
+)
+
+// NotificationChannel определяет каналы доставки уведомлений
+const (
+	NotificationChannelEmail   = "email"
+	NotificationChannelWebhook = "webhook"
+	NotificationChannelSMS     = "sms"
+	NotificationChannelInbox   = "inbox"
+)
+
+// NotificationEventTypes все поддерживаемые типы событий
+var NotificationEventTypes = []string{
+	NotificationEventDeposit,
+	NotificationEventCardPayment,
+	NotificationEventOverdue,
+	NotificationEventCreditIssued,
+	NotificationEventStandingOrderFailed,
+}
+
+// NotificationChannels все поддерживаемые каналы
+var NotificationChannels = []string{
+	NotificationChannelEmail,
+	NotificationChannelWebhook,
+	NotificationChannelSMS,
+	NotificationChannelInbox,
+}
+
+// Validation errors
+var (
+	ErrInvalidNotificationEvent   = errors.New("invalid notification event type")
+	ErrInvalidNotificationChannel = errors.New("invalid notification channel")
+	ErrInvalidWebhookURL          = errors.New("webhook URL must be an absolute http(s) URL")
+	ErrInvalidPhoneNumber         = errors.New("phone number must be in E.164 format")
+	ErrNotificationNotFound       = errors.New("notification not found")
+	ErrNotificationEndpointAbsent = errors.New("notification endpoint not configured")
+)
+
+var phoneNumberRegex = regexp.MustCompile(`^\+[1-9][0-9]{7,14}$`)
+
+// IsValidNotificationEvent проверяет тип события
+func IsValidNotificationEvent(eventType string) bool {
+	for _, t := range NotificationEventTypes {
+		if t == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+// IsValidNotificationChannel проверяет канал доставки
+func IsValidNotificationChannel(channel string) bool {
+	for _, c := range NotificationChannels {
+		if c == channel {
+			return true
+		}
+	}
+	return false
+}
+
+// IsEndpointChannel проверяет, требует ли канал адреса доставки
+func IsEndpointChannel(channel string) bool {
+	return channel == NotificationChannelWebhook || channel == NotificationChannelSMS
+}
+
+// DefaultChannelEnabled возвращает настройку канала по умолчанию:
+// email и inbox включены, внешние каналы требуют явного включения
+func DefaultChannelEnabled(channel string) bool {
+	return channel == NotificationChannelEmail || channel == NotificationChannelInbox
+}
+
+// Validate валидирует настройку канала
+func (p *NotificationPreference) Validate() error {
+	if !IsValidNotificationEvent(p.EventType) {
+		return ErrInvalidNotificationEvent
+	}
+	if !IsValidNotificationChannel(p.Channel) {
+		return ErrInvalidNotificationChannel
+	}
+	return nil
+}
+
+// Validate валидирует адрес доставки
+func (e *NotificationEndpoint) Validate() error {
+	switch e.Channel {
+	case NotificationChannelWebhook:
+		u, err := url.Parse(e.Address)
+		if err != nil || (u.Scheme != "https" && u.Scheme != "http") || u.Host == "" {
+			return ErrInvalidWebhookURL
+		}
+	case NotificationChannelSMS:
+		if !phoneNumberRegex.MatchString(e.Address) {
+			return ErrInvalidPhoneNumber
+		}
+	default:
+		return ErrInvalidNotificationChannel
+	}
+	return nil
+}
+
+// ResolvePreferences строит полную матрицу настроек пользователя,
+// подставляя значения по умолчанию для отсутствующих пар событие/канал
+func ResolvePreferences(userID int, saved []*NotificationPreference) []*NotificationPreference {
+	overrides := make(map[string]bool, len(saved))
+	for _, p := range saved {
+		overrides[p.EventType+"/"+p.Channel] = p.Enabled
+	}
+
+	result := make([]*NotificationPreference, 0, len(NotificationEventTypes)*len(NotificationChannels))
+	for _, eventType := range NotificationEventTypes {
+		for _, channel := range NotificationChannels {
+			enabled, ok := overrides[eventType+"/"+channel]
+			if !ok {
+				enabled = DefaultChannelEnabled(channel)
+			}
+			result = append(result, &NotificationPreference{
+				UserID:    userID,
+				EventType: eventType,
+				Channel:   channel,
+				Enabled:   enabled,
+			})
+		}
+	}
+
+	return result
+}
+
+// EnabledChannels возвращает включенные каналы для типа события
+func EnabledChannels(saved []*NotificationPreference, eventType string) []string {
+	var channels []string
+	for _, p := range ResolvePreferences(0, saved) {
+		if p.EventType == eventType && p.Enabled {
+			channels = append(channels, p.Channel)
+		}
+	}
+	return channels
+}
@@ -0,0 +1,114 @@
+package domain
+
+import (
+	"errors"
+	"time"
+)
+
+// OutboxMessage сообщение для надежной доставки через outbox
+type OutboxMessage struct {
+	ID              int64      `json:"id" db:"id"`
+	UserID          *int       `json:"user_id" db:"user_id"`
+	Channel         string     `json:"channel" db:"channel"`
+	EventType       string     `json:"event_type" db:"event_type"`
+	Recipient       string     `json:"recipient" db:"recipient"`
+	Subject         string     `json:"subject" db:"subject"`
+	Body            string     `json:"-" db:"body"`
+	TextBody        string     `json:"-" db:"text_body"` // Текстовая альтернатива HTML письма
+	TemplateVersion string     `json:"template_version,omitempty" db:"template_version"`
+	Status          string     `json:"status" db:"status"`
+	Attempts        int        `json:"attempts" db:"attempts"`
+	MaxAttempts     int        `json:"max_attempts" db:"max_attempts"`
+	NextAttemptAt   time.Time  `json:"next_attempt_at" db:"next_attempt_at"`
+	LockedUntil     *time.Time `json:"-" db:"locked_until"`
+	LastError       string     `json:"last_error" db:"last_error"`
+	SentAt          *time.Time `json:"sent_at" db:"sent_at"`
+	CreatedAt       time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt       time.Time  `json:"updated_at" db:"updated_at"`
+}
+
+// OutboxStatus определяет статусы сообщений outbox
+const (
+	OutboxStatusPending    = "pending"
+	OutboxStatusProcessing = "processing"
+	OutboxStatusSent       = "sent"
+	OutboxStatusDead       = "dead"
+)
+
+// OutboxChannel определяет каналы доставки
+const (
+	OutboxChannelEmail   = "email"
+	OutboxChannelWebhook = "webhook"
+	OutboxChannelSMS     = "sms"
+)
+
+// OutboxEventType определяет типы событий уведомлений
+const (
+	OutboxEventPayment       = "payment"
+	OutboxEventCredit        = "credit"
+	OutboxEventOverdue       = "overdue"
+	OutboxEventStandingOrder = "standing_order"
+)
+
+// Validation errors
+var (
+	ErrEmptyOutboxRecipient = errors.New("outbox recipient cannot be empty")
+	ErrEmptyOutboxBody      = errors.New("outbox body cannot be empty")
+	ErrOutboxNotDead        = errors.New("only dead messages can be retried")
+)
+
+// Validate валидирует сообщение outbox
+func (m *OutboxMessage) Validate() error {
+	if m.Recipient == "" {
+		return ErrEmptyOutboxRecipient
+	}
+	if m.Body == "" {
+		return ErrEmptyOutboxBody
+	}
+	return nil
+}
+
+// MarkSent отмечает успешную доставку
+func (m *OutboxMessage) MarkSent(now time.Time) {
+	m.Attempts++
+	m.Status = OutboxStatusSent
+	m.SentAt = &now
+	m.LockedUntil = nil
+	m.LastError = ""
+}
+
+// MarkFailed учитывает неудачную попытку: планирует повтор с экспоненциальной задержкой
+// или переводит сообщение в dead после исчерпания попыток
+func (m *OutboxMessage) MarkFailed(now time.Time, sendErr error, baseBackoff, maxBackoff time.Duration) {
+	m.Attempts++
+	m.LastError = sendErr.Error()
+	m.LockedUntil = nil
+
+	if m.Attempts >= m.MaxAttempts {
+		m.Status = OutboxStatusDead
+		return
+	}
+
+	m.Status = OutboxStatusPending
+	m.NextAttemptAt = now.Add(OutboxBackoff(m.Attempts, baseBackoff, maxBackoff))
+}
+
+// OutboxBackoff вычисляет задержку перед следующей попыткой: base * 2^(attempts-1), но не больше max
+func OutboxBackoff(attempts int, base, maxBackoff time.Duration) time.Duration {
+	if attempts < 1 {
+		return base
+	}
+
+	delay := base
+	for i := 1; i < attempts; i++ {
+		delay *= 2
+		if delay >= maxBackoff {
+			return maxBackoff
+		}
+	}
+
+	if delay > maxBackoff {
+		return maxBackoff
+	}
+	return delay
+}
@@ -0,0 +1,144 @@
+package domain
+
+import (
+	"errors"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// PaymentAlias привязывает телефон или email клиента к счету зачисления входящих переводов
+type PaymentAlias struct {
+	ID        int       `json:"id" db:"id"`
+	UserID    int       `json:"user_id" db:"user_id"`
+	Type      string    `json:"type" db:"alias_type"`
+	Value     string    `json:"value" db:"value"`
+	AccountID int       `json:"account_id" db:"account_id"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+}
+
+// PaymentAliasType определяет виды псевдонимов
+const (
+	PaymentAliasPhone = "phone"
+	PaymentAliasEmail = "email"
+)
+
+// RecipientLookup способ указания получателя перевода; заполняется ровно одно поле.
+// AccountID допускается только для переводов между своими счетами.
+type RecipientLookup struct {
+	AccountID     int    `json:"account_id"`
+	AccountNumber string `json:"account_number"`
+	Email         string `json:"email"`
+	Phone         string `json:"phone"`
+}
+
+// Recipient найденный получатель перевода. Внутренний ID счета получателя
+// наружу не отдается, клиент видит только маскированные имя и номер.
+type Recipient struct {
+	AccountID           int      `json:"-"`
+	UserID              int      `json:"-"`
+	MaskedName          string   `json:"masked_name"`
+	MaskedAccountNumber string   `json:"masked_account_number"`
+	OwnAccount          bool     `json:"own_account"`
+	NewRecipient        bool     `json:"new_recipient"`
+	LimitRemaining      *float64 `json:"limit_remaining,omitempty"` // Доступная сумма перевода новому получателю
+}
+
+// TransferStats история переводов клиента одному получателю
+type TransferStats struct {
+	FirstTransferAt *time.Time // Первый успешный перевод, nil если переводов не было
+	AmountSince     float64    // Сумма переводов с начала периода лимита
+}
+
+// Recipient errors
+var (
+	ErrRecipientNotFound         = errors.New("recipient not found")
+	ErrInvalidRecipient          = errors.New("exactly one of to_account_id, to_account_number, to_email, to_phone is required")
+	ErrInvalidAccountNumber      = errors.New("account number must contain 20 digits")
+	ErrInvalidPaymentAliasType   = errors.New("invalid payment alias type")
+	ErrPaymentAliasNotFound      = errors.New("payment alias not found")
+	ErrPaymentAliasTaken         = errors.New("payment alias is already registered")
+	ErrPaymentAliasEmailMismatch = errors.New("email alias must match the registered email")
+	ErrNewRecipientLimitExceeded = errors.New("transfer limit for new recipient exceeded")
+)
+
+// Validate валидирует и нормализует способ указания получателя
+func (l *RecipientLookup) Validate() error {
+	l.AccountNumber = strings.TrimSpace(l.AccountNumber)
+	l.Email = strings.ToLower(strings.TrimSpace(l.Email))
+	l.Phone = strings.TrimSpace(l.Phone)
+
+	filled := 0
+	for _, set := range []bool{l.AccountID != 0, l.AccountNumber != "", l.Email != "", l.Phone != ""} {
+		if set {
+			filled++
+		}
+	}
+	if filled != 1 {
+		return ErrInvalidRecipient
+	}
+
+	switch {
+	case l.AccountID < 0:
+		return ErrInvalidAccountID
+	case l.AccountNumber != "" && !isAccountNumber(l.AccountNumber):
+		return ErrInvalidAccountNumber
+	case l.Phone != "" && !phoneNumberRegex.MatchString(l.Phone):
+		return ErrInvalidPhoneNumber
+	}
+	return nil
+}
+
+// Validate валидирует и нормализует псевдоним
+func (a *PaymentAlias) Validate() error {
+	a.Value = strings.TrimSpace(a.Value)
+	if a.AccountID <= 0 {
+		return ErrInvalidAccountID
+	}
+
+	switch a.Type {
+	case PaymentAliasPhone:
+		if !phoneNumberRegex.MatchString(a.Value) {
+			return ErrInvalidPhoneNumber
+		}
+	case PaymentAliasEmail:
+		// Совпадение с email пользователя проверяет сервис
+		a.Value = strings.ToLower(a.Value)
+	default:
+		return ErrInvalidPaymentAliasType
+	}
+	return nil
+}
+
+// MaskName скрывает имя получателя, оставляя первую и последнюю буквы: "ivanov" -> "I***v"
+func MaskName(name string) string {
+	runes := []rune(strings.TrimSpace(name))
+	switch {
+	case len(runes) == 0:
+		return ""
+	case len(runes) < 4:
+		return string(unicode.ToUpper(runes[0])) + "***"
+	}
+	return string(unicode.ToUpper(runes[0])) + "***" + string(runes[len(runes)-1])
+}
+
+// MaskAccountNumber скрывает середину номера счета: "40817810123456781234" -> "40817***********1234"
+func MaskAccountNumber(number string) string {
+	if len(number) <= 9 {
+		return number
+	}
+	return number[:5] + strings.Repeat("*", len(number)-9) + number[len(number)-4:]
+}
+
+// isAccountNumber проверяет формат номера счета: 20 цифр
+func isAccountNumber(number string) bool {
+	if len(number) != 20 {
+		return false
+	}
+	for _, r := range number {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,83 @@
+package domain
+
+import (
+	"errors"
+	"time"
+)
+
+// SanctionsList загруженный санкционный список (перечень). Name - имя файла списка без расширения,
+// Checksum - SHA-256 содержимого файла: по нему определяется, что список изменился.
+type SanctionsList struct {
+	ID           int       `json:"id" db:"id"`
+	Name         string    `json:"name" db:"name"`
+	Source       string    `json:"source" db:"source"`
+	Checksum     string    `json:"checksum" db:"checksum"`
+	EntriesCount int       `json:"entries_count" db:"entries_count"`
+	LoadedAt     time.Time `json:"loaded_at" db:"loaded_at"`
+	CreatedAt    time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// SanctionsEntry лицо из санкционного списка
+type SanctionsEntry struct {
+	ID         int      `json:"id" db:"id"`
+	ListID     int      `json:"list_id" db:"list_id"`
+	ExternalID string   `json:"external_id" db:"external_id"` // ID записи в исходном списке
+	Name       string   `json:"name" db:"name"`
+	Aliases    []string `json:"aliases" db:"aliases"`
+	BirthDate  string   `json:"birth_date" db:"birth_date"` // Как в списке: дата или только год
+	Country    string   `json:"country" db:"country"`
+	Program    string   `json:"program" db:"program"` // Санкционная программа или основание включения
+}
+
+// SanctionsHit возможное совпадение клиента с записью санкционного списка.
+// Совпадение ждет решения оператора: confirmed блокирует переводы клиенту, cleared снимает подозрение.
+type SanctionsHit struct {
+	ID              int        `json:"id" db:"id"`
+	UserID          int        `json:"user_id" db:"user_id"`
+	ListName        string     `json:"list_name" db:"list_name"`
+	EntryExternalID string     `json:"entry_external_id" db:"entry_external_id"`
+	EntryName       string     `json:"entry_name" db:"entry_name"`
+	MatchedName     string     `json:"matched_name" db:"matched_name"` // Имя или псевдоним записи, давшие лучшую оценку
+	Score           float64    `json:"score" db:"score"`
+	Source          string     `json:"source" db:"source"`
+	Status          string     `json:"status" db:"status"`
+	ReviewerID      *int       `json:"reviewer_id" db:"reviewer_id"`
+	Comment         string     `json:"comment" db:"comment"`
+	ReviewedAt      *time.Time `json:"reviewed_at" db:"reviewed_at"`
+	CreatedAt       time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt       time.Time  `json:"updated_at" db:"updated_at"`
+}
+
+// SanctionsHitStatus определяет статусы совпадения
+const (
+	SanctionsHitStatusPending   = "pending"
+	SanctionsHitStatusConfirmed = "confirmed"
+	SanctionsHitStatusCleared   = "cleared"
+)
+
+// SanctionsHitSource определяет, при какой проверке найдено совпадение
+const (
+	SanctionsSourceRegistration = "registration"
+	SanctionsSourceTransfer     = "transfer"
+	SanctionsSourceRescreen     = "rescreen"
+)
+
+// IsValidSanctionsHitStatus проверяет статус совпадения
+func IsValidSanctionsHitStatus(status string) bool {
+	switch status {
+	case SanctionsHitStatusPending, SanctionsHitStatusConfirmed, SanctionsHitStatusCleared:
+		return true
+	}
+	return false
+}
+
+// Sanctions errors
+var (
+	ErrSanctionsMatch             = errors.New("counterparty is on a sanctions list")
+	ErrSanctionsReviewPending     = errors.New("counterparty sanctions screening is pending review")
+	ErrSanctionsHitNotFound       = errors.New("sanctions hit not found")
+	ErrSanctionsHitResolved       = errors.New("sanctions hit is already resolved")
+	ErrInvalidSanctionsHitStatus  = errors.New("invalid sanctions hit status")
+	ErrInvalidSanctionsListFormat = errors.New("invalid sanctions list format")
+)
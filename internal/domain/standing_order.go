@@ -0,0 +1,253 @@
+package domain
+
+import (
+	"errors"
+	"time"
+)
+
+// StandingOrder представляет регулярный (или отложенный) перевод между счетами
+type StandingOrder struct {
+	ID            int `json:"id" db:"id"`
+	UserID        int `json:"user_id" db:"user_id"`
+	FromAccountID int `json:"from_account_id" db:"from_account_id"`
+	ToAccountID   int `json:"-" db:"to_account_id"` // Внутренний ID счета получателя, клиенту не отдается
+	// Маскированные данные получателя на момент создания или изменения поручения
+	ToMaskedName          string     `json:"to_masked_name" db:"to_masked_name"`
+	ToMaskedAccountNumber string     `json:"to_masked_account_number" db:"to_masked_account_number"`
+	Amount                float64    `json:"amount" db:"amount"`
+	Description           string     `json:"description" db:"description"`
+	Schedule              string     `json:"schedule" db:"schedule"`
+	DayOfWeek             int        `json:"day_of_week" db:"day_of_week"`   // 1 - понедельник ... 7 - воскресенье
+	DayOfMonth            int        `json:"day_of_month" db:"day_of_month"` // 1..31, в коротких месяцах - последний день
+	StartDate             time.Time  `json:"start_date" db:"start_date"`
+	EndDate               *time.Time `json:"end_date" db:"end_date"`
+	NextRunDate           *time.Time `json:"next_run_date" db:"next_run_date"`
+	Attempts              int        `json:"attempts" db:"attempts"` // Неудачные попытки текущего платежа
+	RetryAt               *time.Time `json:"retry_at" db:"retry_at"`
+	Status                string     `json:"status" db:"status"`
+	CreatedAt             time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt             time.Time  `json:"updated_at" db:"updated_at"`
+}
+
+// StandingOrderExecution результат попытки исполнения платежа по поручению
+type StandingOrderExecution struct {
+	ID              int64     `json:"id" db:"id"`
+	StandingOrderID int       `json:"standing_order_id" db:"standing_order_id"`
+	ScheduledDate   time.Time `json:"scheduled_date" db:"scheduled_date"`
+	Attempt         int       `json:"attempt" db:"attempt"`
+	Status          string    `json:"status" db:"status"`
+	Amount          float64   `json:"amount" db:"amount"`
+	Error           string    `json:"error" db:"error"`
+	ExecutedAt      time.Time `json:"executed_at" db:"executed_at"`
+}
+
+// StandingOrderRequest представляет запрос на создание или изменение поручения.
+// Получатель задается так же, как при переводе; Recipient заполняет сервис после поиска получателя.
+type StandingOrderRequest struct {
+	FromAccountID int             `json:"from_account_id"`
+	To            RecipientLookup `json:"to"`
+	Recipient     *Recipient      `json:"-"`
+	Amount        float64         `json:"amount"`
+	Description   string          `json:"description"`
+	Schedule      string          `json:"schedule"`
+	StartDate     time.Time       `json:"start_date"`
+	DayOfWeek     int             `json:"day_of_week"`
+	DayOfMonth    int             `json:"day_of_month"`
+	EndDate       *time.Time      `json:"end_date"`
+}
+
+// StandingOrderSchedule определяет периодичность поручения
+const (
+	StandingOrderScheduleOnce    = "once"
+	StandingOrderScheduleWeekly  = "weekly"
+	StandingOrderScheduleMonthly = "monthly"
+)
+
+// StandingOrderStatus определяет статусы поручения
+const (
+	StandingOrderStatusActive    = "active"
+	StandingOrderStatusPaused    = "paused"
+	StandingOrderStatusCompleted = "completed"
+	StandingOrderStatusCancelled = "cancelled"
+)
+
+// StandingOrderExecutionStatus определяет статусы исполнения
+const (
+	StandingOrderExecutionSucceeded = "succeeded"
+	// StandingOrderExecutionRetrying попытка не удалась, запланирован повтор
+	StandingOrderExecutionRetrying = "retrying"
+	// StandingOrderExecutionFailed платеж не исполнен, поручение перешло к следующей дате
+	StandingOrderExecutionFailed = "failed"
+)
+
+// Domain errors
+var (
+	ErrStandingOrderNotFound      = errors.New("standing order not found")
+	ErrInvalidStandingOrderAmount = errors.New("standing order amount must be positive")
+	ErrStandingOrderSameAccount   = errors.New("cannot transfer to the same account")
+	ErrInvalidStandingOrderType   = errors.New("invalid standing order schedule")
+	ErrInvalidStandingOrderDay    = errors.New("invalid standing order day")
+	ErrInvalidStandingOrderPeriod = errors.New("standing order has no payment dates")
+	ErrStandingOrderNotActive     = errors.New("standing order is not active")
+	ErrStandingOrderNotPaused     = errors.New("standing order is not paused")
+	ErrStandingOrderFinished      = errors.New("standing order is completed or cancelled")
+)
+
+// Validate валидирует запрос и подставляет день платежа из даты начала, если он не указан
+func (r *StandingOrderRequest) Validate() error {
+	if r.FromAccountID <= 0 {
+		return ErrInvalidAccountID
+	}
+	if err := r.To.Validate(); err != nil {
+		return err
+	}
+	if r.To.AccountID == r.FromAccountID {
+		return ErrStandingOrderSameAccount
+	}
+	if r.Amount <= 0 {
+		return ErrInvalidStandingOrderAmount
+	}
+	if r.StartDate.IsZero() {
+		return ErrInvalidStandingOrderPeriod
+	}
+	r.StartDate = truncateDate(r.StartDate)
+	if r.EndDate != nil {
+		endDate := truncateDate(*r.EndDate)
+		if endDate.Before(r.StartDate) {
+			return ErrInvalidStandingOrderPeriod
+		}
+		r.EndDate = &endDate
+	}
+
+	switch r.Schedule {
+	case StandingOrderScheduleOnce:
+		if r.DayOfWeek != 0 || r.DayOfMonth != 0 || r.EndDate != nil {
+			return ErrInvalidStandingOrderDay
+		}
+	case StandingOrderScheduleWeekly:
+		if r.DayOfMonth != 0 || r.DayOfWeek < 0 || r.DayOfWeek > 7 {
+			return ErrInvalidStandingOrderDay
+		}
+		if r.DayOfWeek == 0 {
+			r.DayOfWeek = isoWeekday(r.StartDate)
+		}
+	case StandingOrderScheduleMonthly:
+		if r.DayOfWeek != 0 || r.DayOfMonth < 0 || r.DayOfMonth > 31 {
+			return ErrInvalidStandingOrderDay
+		}
+		if r.DayOfMonth == 0 {
+			r.DayOfMonth = r.StartDate.Day()
+		}
+	default:
+		return ErrInvalidStandingOrderType
+	}
+
+	return nil
+}
+
+// Apply переносит условия запроса в поручение. Получатель в запросе должен быть уже найден
+func (o *StandingOrder) Apply(r *StandingOrderRequest) {
+	o.FromAccountID = r.FromAccountID
+	o.ToAccountID = r.Recipient.AccountID
+	o.ToMaskedName = r.Recipient.MaskedName
+	o.ToMaskedAccountNumber = r.Recipient.MaskedAccountNumber
+	o.Amount = r.Amount
+	o.Description = r.Description
+	o.Schedule = r.Schedule
+	o.StartDate = r.StartDate
+	o.DayOfWeek = r.DayOfWeek
+	o.DayOfMonth = r.DayOfMonth
+	o.EndDate = r.EndDate
+}
+
+// IsActive проверяет, исполняется ли поручение
+func (o *StandingOrder) IsActive() bool {
+	return o.Status == StandingOrderStatusActive
+}
+
+// IsFinished проверяет, завершено или отменено ли поручение
+func (o *StandingOrder) IsFinished() bool {
+	return o.Status == StandingOrderStatusCompleted || o.Status == StandingOrderStatusCancelled
+}
+
+// IsDue проверяет, пора ли исполнять платеж: дата наступила и время повтора (если назначен) прошло
+func (o *StandingOrder) IsDue(today, now time.Time) bool {
+	if !o.IsActive() || o.NextRunDate == nil || o.NextRunDate.After(today) {
+		return false
+	}
+	return o.RetryAt == nil || !o.RetryAt.After(now)
+}
+
+// Reschedule назначает ближайший платеж не раньше указанной даты.
+// Если платежей больше не будет, поручение завершается.
+func (o *StandingOrder) Reschedule(from time.Time) {
+	o.Attempts = 0
+	o.RetryAt = nil
+	o.setNextRun(o.occurrenceOnOrAfter(truncateDate(from)))
+}
+
+// Advance переводит поручение к платежу, следующему за указанной датой
+func (o *StandingOrder) Advance(scheduled time.Time) {
+	o.Attempts = 0
+	o.RetryAt = nil
+	o.setNextRun(o.occurrenceOnOrAfter(truncateDate(scheduled).AddDate(0, 0, 1)))
+}
+
+func (o *StandingOrder) setNextRun(next *time.Time) {
+	o.NextRunDate = next
+	if next == nil {
+		o.Status = StandingOrderStatusCompleted
+	}
+}
+
+// occurrenceOnOrAfter возвращает первую дату платежа не раньше date или nil, если платежей больше нет
+func (o *StandingOrder) occurrenceOnOrAfter(date time.Time) *time.Time {
+	if date.Before(o.StartDate) {
+		date = o.StartDate
+	}
+
+	var next time.Time
+	switch o.Schedule {
+	case StandingOrderScheduleOnce:
+		if !date.Equal(o.StartDate) {
+			return nil
+		}
+		next = o.StartDate
+	case StandingOrderScheduleWeekly:
+		next = date.AddDate(0, 0, (o.DayOfWeek-isoWeekday(date)+7)%7)
+	case StandingOrderScheduleMonthly:
+		next = monthlyPaymentDate(date.Year(), date.Month(), o.DayOfMonth)
+		if next.Before(date) {
+			next = monthlyPaymentDate(date.Year(), date.Month()+1, o.DayOfMonth)
+		}
+	default:
+		return nil
+	}
+
+	if o.EndDate != nil && next.After(*o.EndDate) {
+		return nil
+	}
+	return &next
+}
+
+// monthlyPaymentDate возвращает день платежа в месяце; если месяц короче, платеж в последний день
+func monthlyPaymentDate(year int, month time.Month, day int) time.Time {
+	lastDay := time.Date(year, month+1, 0, 0, 0, 0, 0, time.UTC).Day()
+	if day > lastDay {
+		day = lastDay
+	}
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+// isoWeekday возвращает день недели по ISO 8601: 1 - понедельник ... 7 - воскресенье
+func isoWeekday(date time.Time) int {
+	if date.Weekday() == time.Sunday {
+		return 7
+	}
+	return int(date.Weekday())
+}
+
+// truncateDate отбрасывает время, оставляя календарную дату (полночь UTC)
+func truncateDate(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
@@ -14,6 +14,8 @@ type Transaction struct {
 	Type        string    `json:"type" db:"type"`
 	Status      string    `json:"status" db:"status"`
 	Description string    `json:"description" db:"description"`
+	MerchantID  *int      `json:"merchant_id,omitempty" db:"merchant_id"` // ТСП платежа картой, возврата или комиссии
+	MCC         string    `json:"mcc,omitempty" db:"mcc"`                 // Код категории ТСП для аналитики расходов
 	CreatedAt   time.Time `json:"created_at" db:"created_at"`
 	UpdatedAt   time.Time `json:"updated_at" db:"updated_at"`
 }
@@ -25,6 +27,17 @@ const (
 	TransactionTypeTransfer = "transfer"
 	TransactionTypePayment  = "payment"
 	TransactionTypeCredit   = "credit"
+	TransactionTypeInterest = "interest"
+	// TransactionTypeDepositOpen списание со счета на вклад
+	TransactionTypeDepositOpen = "deposit_open"
+	// TransactionTypeDepositPayout выплата вклада с процентами на счет
+	TransactionTypeDepositPayout = "deposit_payout"
+	// TransactionTypeRefund возврат по списанному платежу с карты
+	TransactionTypeRefund = "refund"
+	// TransactionTypeInterchangeFee комиссия, удержанная с расчетного счета ТСП
+	TransactionTypeInterchangeFee = "interchange_fee"
+	// TransactionTypeCashback ежемесячная выплата кэшбэка по карте
+	TransactionTypeCashback = "cashback"
 )
 
 // TransactionStatus определяет статусы транзакций
@@ -57,6 +70,12 @@ func (t *Transaction) Validate() error {
 		TransactionTypeTransfer,
 		TransactionTypePayment,
 		TransactionTypeCredit,
+		TransactionTypeInterest,
+		TransactionTypeDepositOpen,
+		TransactionTypeDepositPayout,
+		TransactionTypeRefund,
+		TransactionTypeInterchangeFee,
+		TransactionTypeCashback,
 	}
 	isValidType := false
 	for _, validType := range validTypes {
@@ -11,10 +11,47 @@ type User struct {
 	Username     string    `json:"username" db:"username"`
 	Email        string    `json:"email" db:"email"`
 	PasswordHash string    `json:"-" db:"password_hash"`
+	Role         string    `json:"role" db:"role"`
+	Locale       string    `json:"locale" db:"locale"`
 	CreatedAt    time.Time `json:"created_at" db:"created_at"`
 	UpdatedAt    time.Time `json:"updated_at" db:"updated_at"`
 }
 
+// Роли пользователей
+const (
+	UserRoleUser  = "user"
+	UserRoleAdmin = "admin"
+)
+
+// Языки уведомлений
+const (
+	LocaleRU = "ru"
+	LocaleEN = "en"
+	// DefaultLocale язык по умолчанию и язык fallback для шаблонов
+	DefaultLocale = LocaleRU
+)
+
+// ErrUnsupportedLocale язык не поддерживается
+var ErrUnsupportedLocale = errors.New("unsupported locale")
+
+// IsSupportedLocale проверяет, поддерживается ли язык
+func IsSupportedLocale(locale string) bool {
+	return locale == LocaleRU || locale == LocaleEN
+}
+
+// NormalizeLocale возвращает поддерживаемый язык или язык по умолчанию
+func NormalizeLocale(locale string) string {
+	if IsSupportedLocale(locale) {
+		return locale
+	}
+	return DefaultLocale
+}
+
+// IsAdmin проверяет наличие роли администратора
+func (u *User) IsAdmin() bool {
+	return u.Role == UserRoleAdmin
+}
+
 // RegisterRequest представляет запрос на регистрацию
 type RegisterRequest struct {
 	Username string `json:"username"`
@@ -0,0 +1,273 @@
+// Package gateway принимает карточные сообщения ISO 8583 от терминалов и процессинга:
+// по TCP кадрами с префиксом длины и по HTTP в JSON (POST /iso8583).
+// Каждое сообщение подписывается MAC на общем с терминалами ключе; ответы подписываются тем же ключом.
+// Обработка сообщений выполняется service.CardGatewayService.
+package gateway
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/vterdunov/learn-bank-app/internal/config"
+	"github.com/vterdunov/learn-bank-app/internal/domain"
+	"github.com/vterdunov/learn-bank-app/internal/handlers"
+	"github.com/vterdunov/learn-bank-app/internal/service"
+	"github.com/vterdunov/learn-bank-app/pkg/iso8583"
+	"github.com/vterdunov/learn-bank-app/pkg/logger"
+)
+
+// writeTimeout время на отправку ответа терминалу
+const writeTimeout = 10 * time.Second
+
+// Server listener'ы шлюза карточных сообщений
+type Server struct {
+	tcpAddr     string
+	httpAddr    string
+	idleTimeout time.Duration
+	macKey      []byte
+	gateway     service.CardGatewayService
+	logger      *slog.Logger
+
+	mu         sync.Mutex
+	listener   net.Listener
+	httpServer *http.Server
+	conns      map[net.Conn]struct{}
+	stopping   bool
+	wg         sync.WaitGroup
+}
+
+// NewServer создает сервер шлюза; пустой адрес в конфигурации отключает соответствующий listener
+func NewServer(cfg *config.Config, gateway service.CardGatewayService, lg *slog.Logger) *Server {
+	return &Server{
+		tcpAddr:     cfg.Gateway.TCPAddr,
+		httpAddr:    cfg.Gateway.HTTPAddr,
+		idleTimeout: cfg.Gateway.IdleTimeout,
+		macKey:      []byte(cfg.Gateway.MACKey),
+		gateway:     gateway,
+		logger:      logger.WithService(lg, "card_gateway"),
+		conns:       make(map[net.Conn]struct{}),
+	}
+}
+
+// Enabled проверяет, настроен ли хотя бы один listener
+func (s *Server) Enabled() bool {
+	return s.tcpAddr != "" || s.httpAddr != ""
+}
+
+// Start открывает listener'ы и начинает прием сообщений
+func (s *Server) Start(ctx context.Context) error {
+	if s.tcpAddr != "" {
+		listener, err := net.Listen("tcp", s.tcpAddr)
+		if err != nil {
+			return err
+		}
+		s.listener = listener
+
+		s.wg.Add(1)
+		go s.acceptLoop(ctx, listener)
+		s.logger.Info("ISO 8583 TCP listener started", "address", listener.Addr().String())
+	}
+
+	if s.httpAddr != "" {
+		listener, err := net.Listen("tcp", s.httpAddr)
+		if err != nil {
+			if s.listener != nil {
+				_ = s.listener.Close()
+			}
+			return err
+		}
+
+		mux := http.NewServeMux()
+		mux.HandleFunc("POST /iso8583", s.handleHTTP)
+		s.httpServer = &http.Server{
+			Handler:      mux,
+			ReadTimeout:  15 * time.Second,
+			WriteTimeout: 15 * time.Second,
+			IdleTimeout:  s.idleTimeout,
+			BaseContext:  func(net.Listener) context.Context { return ctx },
+		}
+
+		go func() {
+			if err := s.httpServer.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				s.logger.Error("ISO 8583 HTTP listener failed", "error", err)
+			}
+		}()
+		s.logger.Info("ISO 8583 HTTP listener started", "address", listener.Addr().String())
+	}
+
+	return nil
+}
+
+// Stop прекращает прием сообщений и дожидается ответов на уже полученные
+func (s *Server) Stop(ctx context.Context) error {
+	s.mu.Lock()
+	s.stopping = true
+	if s.listener != nil {
+		_ = s.listener.Close()
+	}
+	// Прерываем ожидание следующего кадра; начатая обработка завершится отправкой ответа
+	for conn := range s.conns {
+		_ = conn.SetReadDeadline(time.Now())
+	}
+	s.mu.Unlock()
+
+	var err error
+	if s.httpServer != nil {
+		err = s.httpServer.Shutdown(ctx)
+	}
+
+	done := make(chan struct{})
+	go func() {
+		s.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (s *Server) acceptLoop(ctx context.Context, listener net.Listener) {
+	defer s.wg.Done()
+
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			if errors.Is(err, net.ErrClosed) {
+				return
+			}
+			s.logger.Error("Failed to accept gateway connection", "error", err)
+			continue
+		}
+
+		s.mu.Lock()
+		if s.stopping {
+			s.mu.Unlock()
+			_ = conn.Close()
+			return
+		}
+		s.conns[conn] = struct{}{}
+		s.wg.Add(1)
+		s.mu.Unlock()
+
+		go s.serveConn(ctx, conn)
+	}
+}
+
+// serveConn обрабатывает кадры соединения по очереди: один запрос — один ответ
+func (s *Server) serveConn(ctx context.Context, conn net.Conn) {
+	defer func() {
+		_ = conn.Close()
+		s.mu.Lock()
+		delete(s.conns, conn)
+		s.mu.Unlock()
+		s.wg.Done()
+	}()
+
+	remote := conn.RemoteAddr().String()
+	for {
+		s.mu.Lock()
+		stopping := s.stopping
+		s.mu.Unlock()
+		if stopping {
+			return
+		}
+
+		if err := conn.SetReadDeadline(time.Now().Add(s.idleTimeout)); err != nil {
+			return
+		}
+		frame, err := iso8583.ReadFrame(conn)
+		if err != nil {
+			if !errors.Is(err, io.EOF) && !errors.Is(err, net.ErrClosed) && !isTimeout(err) {
+				s.logger.Warn("Failed to read gateway frame", "remote", remote, "error", err)
+			}
+			return
+		}
+
+		request, err := iso8583.Unpack(frame)
+		if err != nil {
+			// Без разобранного сообщения нельзя сформировать ответ: соединение закрывается
+			s.logger.Warn("Malformed gateway frame", "remote", remote, "error", err)
+			return
+		}
+
+		var response *iso8583.Message
+		if err := iso8583.Verify(request, s.macKey); err != nil {
+			s.logger.Warn("Gateway message MAC rejected", "remote", remote, "mti", request.MTI,
+				"terminal_id", request.Get(iso8583.FieldTerminalID), "error", err)
+			// Сообщение не обрабатывается; терминал получает отказ, а не обрыв соединения
+			response, err = request.Response(iso8583.FieldSTAN, iso8583.FieldRRN, iso8583.FieldTerminalID)
+			if err != nil {
+				return
+			}
+			response.Set(iso8583.FieldResponseCode, domain.GatewayResponseSecurityViolation)
+		} else {
+			response, err = s.gateway.HandleMessage(ctx, request)
+			if err != nil {
+				s.logger.Warn("Gateway message rejected", "remote", remote, "mti", request.MTI, "error", err)
+				continue
+			}
+		}
+
+		packed, err := iso8583.Sign(response, s.macKey)
+		if err != nil {
+			s.logger.Error("Failed to pack gateway response", "remote", remote, "mti", response.MTI, "error", err)
+			return
+		}
+		if err := conn.SetWriteDeadline(time.Now().Add(writeTimeout)); err != nil {
+			return
+		}
+		if err := iso8583.WriteFrame(conn, packed); err != nil {
+			s.logger.Warn("Failed to write gateway response", "remote", remote, "error", err)
+			return
+		}
+	}
+}
+
+// handleHTTP обрабатывает сообщение в JSON: {"mti": "0100", "fields": {"2": "...", ..., "128": "<MAC>"}}
+func (s *Server) handleHTTP(w http.ResponseWriter, r *http.Request) {
+	var request iso8583.Message
+	if err := handlers.ValidateJSON(r, &request); err != nil {
+		handlers.WriteErrorResponse(w, http.StatusBadRequest, err)
+		return
+	}
+
+	// Поля проверяются по тем же правилам, что и в TCP-кадре
+	if _, err := iso8583.Pack(&request); err != nil {
+		handlers.WriteErrorResponse(w, http.StatusBadRequest, err)
+		return
+	}
+	if err := iso8583.Verify(&request, s.macKey); err != nil {
+		s.logger.Warn("Gateway message MAC rejected", "remote", r.RemoteAddr, "mti", request.MTI,
+			"terminal_id", request.Get(iso8583.FieldTerminalID), "error", err)
+		handlers.WriteErrorResponse(w, http.StatusUnauthorized, err)
+		return
+	}
+
+	response, err := s.gateway.HandleMessage(r.Context(), &request)
+	if err != nil {
+		handlers.WriteErrorResponse(w, http.StatusBadRequest, err)
+		return
+	}
+	if _, err := iso8583.Sign(response, s.macKey); err != nil {
+		s.logger.Error("Failed to sign gateway response", "mti", response.MTI, "error", err)
+		handlers.WriteErrorResponse(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	handlers.WriteSuccessResponse(w, response)
+}
+
+func isTimeout(err error) bool {
+	var netErr net.Error
+	return errors.As(err, &netErr) && netErr.Timeout()
+}
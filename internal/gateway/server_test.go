@@ -0,0 +1,250 @@
+package gateway
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/vterdunov/learn-bank-app/internal/config"
+	"github.com/vterdunov/learn-bank-app/internal/domain"
+	"github.com/vterdunov/learn-bank-app/pkg/iso8583"
+)
+
+var testMACKey = []byte("test-gateway-key")
+
+// fakeGateway одобряет любое сообщение и запоминает полученные
+type fakeGateway struct {
+	mu       sync.Mutex
+	received []*iso8583.Message
+}
+
+func (g *fakeGateway) HandleMessage(ctx context.Context, request *iso8583.Message) (*iso8583.Message, error) {
+	g.mu.Lock()
+	g.received = append(g.received, request)
+	g.mu.Unlock()
+
+	response, err := request.Response(iso8583.FieldSTAN, iso8583.FieldRRN, iso8583.FieldTerminalID)
+	if err != nil {
+		return nil, err
+	}
+	response.Set(iso8583.FieldResponseCode, domain.GatewayResponseApproved)
+	return response, nil
+}
+
+func (g *fakeGateway) count() int {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return len(g.received)
+}
+
+func newTestServer(tcpAddr string) (*Server, *fakeGateway) {
+	cfg := &config.Config{Gateway: config.GatewayConfig{
+		TCPAddr:     tcpAddr,
+		IdleTimeout: time.Second,
+		MACKey:      string(testMACKey),
+	}}
+	gateway := &fakeGateway{}
+	return NewServer(cfg, gateway, slog.New(slog.NewTextHandler(io.Discard, nil))), gateway
+}
+
+func testRequest() *iso8583.Message {
+	m := iso8583.NewMessage(iso8583.MTIAuthorizationRequest)
+	m.Set(iso8583.FieldAmount, iso8583.FormatAmount(150))
+	m.Set(iso8583.FieldSTAN, "000001")
+	m.Set(iso8583.FieldRRN, "000000000001")
+	m.Set(iso8583.FieldTerminalID, "TERM0001")
+	return m
+}
+
+// startTCP запускает TCP listener на свободном порту и останавливает его после теста
+func startTCP(t *testing.T) (*Server, *fakeGateway, string) {
+	t.Helper()
+
+	server, gateway := newTestServer("127.0.0.1:0")
+	if err := server.Start(context.Background()); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	t.Cleanup(func() {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		_ = server.Stop(ctx)
+	})
+	return server, gateway, server.listener.Addr().String()
+}
+
+// exchange отправляет кадр и читает ответ
+func exchange(t *testing.T, conn net.Conn, frame []byte) (*iso8583.Message, error) {
+	t.Helper()
+
+	if err := conn.SetDeadline(time.Now().Add(time.Second)); err != nil {
+		t.Fatalf("SetDeadline failed: %v", err)
+	}
+	if err := iso8583.WriteFrame(conn, frame); err != nil {
+		t.Fatalf("WriteFrame failed: %v", err)
+	}
+	response, err := iso8583.ReadFrame(conn)
+	if err != nil {
+		return nil, err
+	}
+	return iso8583.Unpack(response)
+}
+
+func TestServer_TCPSignedMessage(t *testing.T) {
+	_, gateway, addr := startTCP(t)
+
+	client, err := iso8583.Dial(addr, time.Second, testMACKey)
+	if err != nil {
+		t.Fatalf("Dial failed: %v", err)
+	}
+	defer client.Close()
+
+	// Несколько сообщений подряд в одном соединении; ответ подписан и проверен клиентом
+	for i := range 2 {
+		response, err := client.Send(testRequest())
+		if err != nil {
+			t.Fatalf("message %d: Send failed: %v", i+1, err)
+		}
+		if response.MTI != iso8583.MTIAuthorizationResponse || response.Get(iso8583.FieldResponseCode) != domain.GatewayResponseApproved {
+			t.Errorf("message %d: unexpected response %+v", i+1, response)
+		}
+	}
+	if gateway.count() != 2 {
+		t.Errorf("expected 2 processed messages, got %d", gateway.count())
+	}
+}
+
+func TestServer_TCPRejectsInvalidMAC(t *testing.T) {
+	_, gateway, addr := startTCP(t)
+
+	conn, err := net.Dial("tcp", addr)
+	if err != nil {
+		t.Fatalf("Dial failed: %v", err)
+	}
+	defer conn.Close()
+
+	unsigned, _ := iso8583.Pack(testRequest())
+	forged, _ := iso8583.Sign(testRequest(), []byte("other-key"))
+
+	for name, frame := range map[string][]byte{"without MAC": unsigned, "wrong key": forged} {
+		response, err := exchange(t, conn, frame)
+		if err != nil {
+			t.Fatalf("%s: expected response, got %v", name, err)
+		}
+		if code := response.Get(iso8583.FieldResponseCode); code != domain.GatewayResponseSecurityViolation {
+			t.Errorf("%s: expected response code %s, got %s", name, domain.GatewayResponseSecurityViolation, code)
+		}
+		if err := iso8583.Verify(response, testMACKey); err != nil {
+			t.Errorf("%s: expected signed rejection, got %v", name, err)
+		}
+	}
+	if gateway.count() != 0 {
+		t.Errorf("expected no messages processed, got %d", gateway.count())
+	}
+}
+
+func TestServer_TCPClosesOnMalformedFrame(t *testing.T) {
+	_, gateway, addr := startTCP(t)
+
+	conn, err := net.Dial("tcp", addr)
+	if err != nil {
+		t.Fatalf("Dial failed: %v", err)
+	}
+	defer conn.Close()
+
+	if _, err := exchange(t, conn, []byte("0100garbage")); !errors.Is(err, io.EOF) {
+		t.Errorf("expected connection closed, got %v", err)
+	}
+	if gateway.count() != 0 {
+		t.Errorf("expected no messages processed, got %d", gateway.count())
+	}
+}
+
+func TestServer_Stop(t *testing.T) {
+	server, _, addr := startTCP(t)
+
+	conn, err := net.Dial("tcp", addr)
+	if err != nil {
+		t.Fatalf("Dial failed: %v", err)
+	}
+	defer conn.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := server.Stop(ctx); err != nil {
+		t.Fatalf("Stop failed: %v", err)
+	}
+
+	// Ожидающее соединение закрыто, новые не принимаются
+	_ = conn.SetReadDeadline(time.Now().Add(time.Second))
+	if _, err := conn.Read(make([]byte, 1)); !errors.Is(err, io.EOF) {
+		t.Errorf("expected idle connection closed, got %v", err)
+	}
+	if conn, err := net.DialTimeout("tcp", addr, 100*time.Millisecond); err == nil {
+		conn.Close()
+		t.Error("expected listener closed")
+	}
+}
+
+func TestServer_HTTP(t *testing.T) {
+	signed := testRequest()
+	if _, err := iso8583.Sign(signed, testMACKey); err != nil {
+		t.Fatalf("Sign failed: %v", err)
+	}
+	forged := testRequest()
+	if _, err := iso8583.Sign(forged, []byte("other-key")); err != nil {
+		t.Fatalf("Sign failed: %v", err)
+	}
+	invalid := testRequest()
+	invalid.Set(iso8583.FieldAmount, "10a")
+
+	tests := []struct {
+		name          string
+		request       *iso8583.Message
+		wantStatus    int
+		wantProcessed int
+	}{
+		{name: "signed message", request: signed, wantStatus: http.StatusOK, wantProcessed: 1},
+		{name: "unsigned message", request: testRequest(), wantStatus: http.StatusUnauthorized},
+		{name: "wrong key", request: forged, wantStatus: http.StatusUnauthorized},
+		{name: "invalid field", request: invalid, wantStatus: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server, gateway := newTestServer("")
+
+			body, _ := json.Marshal(tt.request)
+			rec := httptest.NewRecorder()
+			server.handleHTTP(rec, httptest.NewRequest(http.MethodPost, "/iso8583", bytes.NewReader(body)))
+
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("expected status %d, got %d: %s", tt.wantStatus, rec.Code, rec.Body.String())
+			}
+			if gateway.count() != tt.wantProcessed {
+				t.Errorf("expected %d processed messages, got %d", tt.wantProcessed, gateway.count())
+			}
+			if tt.wantStatus != http.StatusOK {
+				return
+			}
+
+			var envelope struct {
+				Data *iso8583.Message `json:"data"`
+			}
+			if err := json.Unmarshal(rec.Body.Bytes(), &envelope); err != nil || envelope.Data == nil {
+				t.Fatalf("failed to decode response %q: %v", rec.Body.String(), err)
+			}
+			if err := iso8583.Verify(envelope.Data, testMACKey); err != nil {
+				t.Errorf("expected signed response, got %v", err)
+			}
+		})
+	}
+}
@@ -1,6 +1,7 @@
 package handlers
 
 import (
+	"errors"
 	"fmt"
 	"log/slog"
 	"net/http"
@@ -22,15 +23,37 @@ type DepositRequest struct {
 	Amount float64 `json:"amount" validate:"required,gt=0"`
 }
 
+// WithdrawRequest снятие средств; с card_id и pin — выдача наличных по карте счета
 type WithdrawRequest struct {
 	Amount float64 `json:"amount" validate:"required,gt=0"`
+	CardID string  `json:"card_id,omitempty"`
+	PIN    string  `json:"pin,omitempty" validate:"required_with=CardID,omitempty,min=4,max=6,numeric"`
 }
 
+// TransferRequest перевод на свой счет по to_account_id или другому клиенту
+// по номеру счета, email или телефону (см. RecipientLookupRequest)
 type TransferRequest struct {
-	FromAccountID string  `json:"from_account_id" validate:"required,uuid"`
-	ToAccountID   string  `json:"to_account_id" validate:"required,uuid"`
-	Amount        float64 `json:"amount" validate:"required,gt=0"`
-	Description   string  `json:"description,omitempty" validate:"max=255"`
+	FromAccountID string `json:"from_account_id" validate:"required"`
+	RecipientLookupRequest
+	Amount      float64 `json:"amount" validate:"required,gt=0"`
+	Description string  `json:"description,omitempty" validate:"max=255"`
+}
+
+// CloseAccountRequest закрытие счета; положительный остаток переводится на transfer_to_account_id
+type CloseAccountRequest struct {
+	TransferToAccountID string `json:"transfer_to_account_id,omitempty"`
+}
+
+// SetOverdraftRequest установка лимита овердрафта; 0 отключает овердрафт
+type SetOverdraftRequest struct {
+	Limit float64 `json:"limit" validate:"gte=0"`
+}
+
+// AddAccountHolderRequest добавление совладельца счета по email
+type AddAccountHolderRequest struct {
+	Email       string   `json:"email" validate:"required,email"`
+	Role        string   `json:"role" validate:"required,oneof=joint secondary"`
+	Permissions []string `json:"permissions,omitempty"`
 }
 
 // Account Response DTOs
@@ -41,12 +64,31 @@ type AccountResponse struct {
 	Name          string    `json:"name"`
 	AccountType   string    `json:"account_type"`
 	Balance       float64   `json:"balance"`
+	HeldAmount    float64   `json:"held_amount"`
+	Overdraft     float64   `json:"overdraft_limit"`
+	OverdraftUsed float64   `json:"overdraft_used"`
+	Available     float64   `json:"available_balance"`
 	Currency      string    `json:"currency"`
 	Status        string    `json:"status"`
 	CreatedAt     time.Time `json:"created_at"`
 	UpdatedAt     time.Time `json:"updated_at"`
 }
 
+// AccountHolderResponse совладелец счета
+type AccountHolderResponse struct {
+	ID          string    `json:"id"`
+	AccountID   string    `json:"account_id"`
+	UserID      string    `json:"user_id"`
+	Role        string    `json:"role"`
+	Permissions []string  `json:"permissions"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+type TransferResponse struct {
+	Message   string             `json:"message"`
+	Recipient *RecipientResponse `json:"recipient"`
+}
+
 type TransactionResponse struct {
 	ID            string    `json:"id"`
 	FromAccountID *string   `json:"from_account_id"`
@@ -60,14 +102,16 @@ type TransactionResponse struct {
 
 // AccountHandler обрабатывает запросы управления счетами
 type AccountHandler struct {
-	accountService service.AccountService
-	logger         *slog.Logger
+	accountService   service.AccountService
+	recipientService service.RecipientService
+	logger           *slog.Logger
 }
 
-func NewAccountHandler(accountService service.AccountService, logger *slog.Logger) *AccountHandler {
+func NewAccountHandler(accountService service.AccountService, recipientService service.RecipientService, logger *slog.Logger) *AccountHandler {
 	return &AccountHandler{
-		accountService: accountService,
-		logger:         logger,
+		accountService:   accountService,
+		recipientService: recipientService,
+		logger:           logger,
 	}
 }
 
@@ -97,6 +141,7 @@ func (h *AccountHandler) CreateAccount(w http.ResponseWriter, r *http.Request) {
 	// Создание счета
 	serviceReq := service.CreateAccountRequest{
 		Currency: "RUB", // По умолчанию RUB согласно ТЗ
+		Product:  req.AccountType,
 	}
 
 	account, err := h.accountService.CreateAccount(r.Context(), userID, serviceReq)
@@ -227,8 +272,33 @@ func (h *AccountHandler) Withdraw(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Выдача наличных по карте: PIN проверяется до списания
+	if req.CardID != "" {
+		cardID, err := strconv.Atoi(req.CardID)
+		if err != nil {
+			WriteErrorResponse(w, http.StatusBadRequest, fmt.Errorf("invalid card ID"))
+			return
+		}
+
+		if err := h.accountService.WithdrawByCard(r.Context(), userID, accountID, cardID, req.PIN, req.Amount); err != nil {
+			writeCardPINError(w, h.logger, "withdraw money by card", err)
+			return
+		}
+
+		h.logger.Info("Money withdrawn by card", "account_id", accountID, "card_id", cardID, "amount", req.Amount)
+
+		WriteSuccessResponse(w, map[string]string{"message": "Withdrawal successful"})
+		return
+	}
+
 	// Списание средств (проверка прав доступа встроена в сервис)
 	if err := h.accountService.WithdrawMoney(r.Context(), userID, accountID, req.Amount); err != nil {
+		// Снятие отложено до решения оператора или отклонено антифродом
+		if writeScreeningError(w, err) {
+			h.logger.Warn("Withdrawal stopped by fraud screening", "account_id", accountID, "amount", req.Amount, "error", err.Error())
+			return
+		}
+
 		h.logger.Error("Failed to withdraw money", "account_id", accountID, "amount", req.Amount, "error", err.Error())
 
 		// Определяем статус код на основе ошибки
@@ -270,9 +340,9 @@ func (h *AccountHandler) Transfer(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	toAccountID, err := strconv.Atoi(req.ToAccountID)
+	lookup, err := parseRecipientLookup(req.RecipientLookupRequest)
 	if err != nil {
-		WriteErrorResponse(w, http.StatusBadRequest, fmt.Errorf("invalid to_account_id"))
+		WriteErrorResponse(w, http.StatusBadRequest, err)
 		return
 	}
 
@@ -283,31 +353,281 @@ func (h *AccountHandler) Transfer(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Выполнение перевода (проверка прав доступа встроена в сервис)
-	if err := h.accountService.TransferMoney(r.Context(), userID, fromAccountID, toAccountID, req.Amount); err != nil {
-		h.logger.Error("Failed to transfer money",
+	// Поиск получателя, проверка лимита нового получателя и перевод (проверка прав доступа встроена в сервис)
+	recipient, err := h.recipientService.Transfer(r.Context(), userID, fromAccountID, lookup, req.Amount)
+	if err != nil {
+		h.logger.Warn("Failed to transfer money",
 			"from_account_id", fromAccountID,
-			"to_account_id", toAccountID,
 			"amount", req.Amount,
 			"error", err.Error())
-
-		// Определяем статус код на основе ошибки
-		statusCode := http.StatusInternalServerError
-		if err.Error() == "insufficient funds" || err.Error() == "account not found" {
-			statusCode = http.StatusBadRequest
-		}
-
-		WriteErrorResponse(w, statusCode, err)
+		writeRecipientError(w, h.logger, "transfer money", err)
 		return
 	}
 
 	// Логирование
 	h.logger.Info("Money transferred",
 		"from_account_id", fromAccountID,
-		"to_account_id", toAccountID,
+		"to_account_id", recipient.AccountID,
 		"amount", req.Amount)
 
-	WriteSuccessResponse(w, map[string]string{"message": "Transfer successful"})
+	WriteSuccessResponse(w, &TransferResponse{
+		Message:   "Transfer successful",
+		Recipient: RecipientToResponse(recipient),
+	})
+}
+
+// FreezeAccount замораживает счет
+func (h *AccountHandler) FreezeAccount(w http.ResponseWriter, r *http.Request) {
+	h.changeFreezeStatus(w, r, true)
+}
+
+// UnfreezeAccount снимает заморозку счета
+func (h *AccountHandler) UnfreezeAccount(w http.ResponseWriter, r *http.Request) {
+	h.changeFreezeStatus(w, r, false)
+}
+
+func (h *AccountHandler) changeFreezeStatus(w http.ResponseWriter, r *http.Request, freeze bool) {
+	accountID, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		WriteErrorResponse(w, http.StatusBadRequest, fmt.Errorf("invalid account ID"))
+		return
+	}
+
+	userID, err := GetUserIDFromRequest(r)
+	if err != nil {
+		WriteErrorResponse(w, http.StatusUnauthorized, err)
+		return
+	}
+
+	var account *domain.Account
+	if freeze {
+		account, err = h.accountService.FreezeAccount(r.Context(), userID, accountID)
+	} else {
+		account, err = h.accountService.UnfreezeAccount(r.Context(), userID, accountID)
+	}
+	if err != nil {
+		writeAccountLifecycleError(w, h.logger, "change account freeze status", err)
+		return
+	}
+
+	WriteSuccessResponse(w, AccountToResponse(account))
+}
+
+// SetOverdraft устанавливает лимит овердрафта счета
+func (h *AccountHandler) SetOverdraft(w http.ResponseWriter, r *http.Request) {
+	var req SetOverdraftRequest
+
+	if err := ValidateJSON(r, &req); err != nil {
+		WriteErrorResponse(w, http.StatusBadRequest, err)
+		return
+	}
+
+	if validationErr := Validate(&req); validationErr != nil {
+		WriteErrorResponse(w, http.StatusBadRequest, validationErr)
+		return
+	}
+
+	accountID, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		WriteErrorResponse(w, http.StatusBadRequest, fmt.Errorf("invalid account ID"))
+		return
+	}
+
+	userID, err := GetUserIDFromRequest(r)
+	if err != nil {
+		WriteErrorResponse(w, http.StatusUnauthorized, err)
+		return
+	}
+
+	account, err := h.accountService.SetOverdraftLimit(r.Context(), userID, accountID, req.Limit)
+	if err != nil {
+		writeAccountLifecycleError(w, h.logger, "set overdraft limit", err)
+		return
+	}
+
+	h.logger.Info("Overdraft limit set", "account_id", accountID, "limit", req.Limit)
+
+	WriteSuccessResponse(w, AccountToResponse(account))
+}
+
+// CloseAccount закрывает счет
+func (h *AccountHandler) CloseAccount(w http.ResponseWriter, r *http.Request) {
+	var req CloseAccountRequest
+
+	if err := ValidateJSON(r, &req); err != nil {
+		WriteErrorResponse(w, http.StatusBadRequest, err)
+		return
+	}
+
+	if validationErr := Validate(&req); validationErr != nil {
+		WriteErrorResponse(w, http.StatusBadRequest, validationErr)
+		return
+	}
+
+	accountID, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		WriteErrorResponse(w, http.StatusBadRequest, fmt.Errorf("invalid account ID"))
+		return
+	}
+
+	var residualAccountID int
+	if req.TransferToAccountID != "" {
+		residualAccountID, err = strconv.Atoi(req.TransferToAccountID)
+		if err != nil {
+			WriteErrorResponse(w, http.StatusBadRequest, fmt.Errorf("invalid transfer_to_account_id"))
+			return
+		}
+	}
+
+	userID, err := GetUserIDFromRequest(r)
+	if err != nil {
+		WriteErrorResponse(w, http.StatusUnauthorized, err)
+		return
+	}
+
+	account, err := h.accountService.CloseAccount(r.Context(), userID, accountID, residualAccountID)
+	if err != nil {
+		writeAccountLifecycleError(w, h.logger, "close account", err)
+		return
+	}
+
+	h.logger.Info("Account closed", "account_id", accountID, "user_id", userID)
+
+	WriteSuccessResponse(w, AccountToResponse(account))
+}
+
+// GetAccountHolders возвращает совладельцев счета
+func (h *AccountHandler) GetAccountHolders(w http.ResponseWriter, r *http.Request) {
+	accountID, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		WriteErrorResponse(w, http.StatusBadRequest, fmt.Errorf("invalid account ID"))
+		return
+	}
+
+	userID, err := GetUserIDFromRequest(r)
+	if err != nil {
+		WriteErrorResponse(w, http.StatusUnauthorized, err)
+		return
+	}
+
+	holders, err := h.accountService.GetAccountHolders(r.Context(), userID, accountID)
+	if err != nil {
+		writeAccountLifecycleError(w, h.logger, "get account holders", err)
+		return
+	}
+
+	responses := make([]*AccountHolderResponse, 0, len(holders))
+	for _, holder := range holders {
+		responses = append(responses, AccountHolderToResponse(holder))
+	}
+
+	WriteSuccessResponse(w, map[string]interface{}{
+		"holders": responses,
+		"count":   len(responses),
+	})
+}
+
+// AddAccountHolder добавляет совладельца счета
+func (h *AccountHandler) AddAccountHolder(w http.ResponseWriter, r *http.Request) {
+	var req AddAccountHolderRequest
+
+	if err := ValidateJSON(r, &req); err != nil {
+		WriteErrorResponse(w, http.StatusBadRequest, err)
+		return
+	}
+
+	if validationErr := Validate(&req); validationErr != nil {
+		WriteErrorResponse(w, http.StatusBadRequest, validationErr)
+		return
+	}
+
+	accountID, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		WriteErrorResponse(w, http.StatusBadRequest, fmt.Errorf("invalid account ID"))
+		return
+	}
+
+	userID, err := GetUserIDFromRequest(r)
+	if err != nil {
+		WriteErrorResponse(w, http.StatusUnauthorized, err)
+		return
+	}
+
+	holder, err := h.accountService.AddAccountHolder(r.Context(), userID, accountID, domain.AddAccountHolderRequest{
+		Email:       req.Email,
+		Role:        req.Role,
+		Permissions: req.Permissions,
+	})
+	if err != nil {
+		writeAccountLifecycleError(w, h.logger, "add account holder", err)
+		return
+	}
+
+	h.logger.Info("Account holder added", "account_id", accountID, "holder_user_id", holder.UserID)
+
+	WriteSuccessResponse(w, AccountHolderToResponse(holder))
+}
+
+// RemoveAccountHolder удаляет совладельца счета
+func (h *AccountHandler) RemoveAccountHolder(w http.ResponseWriter, r *http.Request) {
+	accountID, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		WriteErrorResponse(w, http.StatusBadRequest, fmt.Errorf("invalid account ID"))
+		return
+	}
+
+	holderUserID, err := strconv.Atoi(r.PathValue("userId"))
+	if err != nil {
+		WriteErrorResponse(w, http.StatusBadRequest, fmt.Errorf("invalid user ID"))
+		return
+	}
+
+	userID, err := GetUserIDFromRequest(r)
+	if err != nil {
+		WriteErrorResponse(w, http.StatusUnauthorized, err)
+		return
+	}
+
+	if err := h.accountService.RemoveAccountHolder(r.Context(), userID, accountID, holderUserID); err != nil {
+		writeAccountLifecycleError(w, h.logger, "remove account holder", err)
+		return
+	}
+
+	WriteSuccessResponse(w, map[string]string{"message": "Account holder removed"})
+}
+
+// writeAccountLifecycleError преобразует ошибки закрытия, заморозки, овердрафта и совладельцев счета в HTTP-ответ
+func writeAccountLifecycleError(w http.ResponseWriter, logger *slog.Logger, action string, err error) {
+	var serviceErr *service.ServiceError
+	switch {
+	case errors.As(err, &serviceErr):
+		WriteErrorResponse(w, serviceErr.Code, err)
+	case errors.Is(err, service.ErrAccountNotFound),
+		errors.Is(err, service.ErrHolderUserNotFound),
+		errors.Is(err, domain.ErrAccountHolderNotFound):
+		WriteErrorResponse(w, http.StatusNotFound, err)
+	case errors.Is(err, domain.ErrAccountHolderExists),
+		errors.Is(err, domain.ErrAccountClosed),
+		errors.Is(err, domain.ErrAccountNotActive),
+		errors.Is(err, domain.ErrAccountNotFrozen),
+		errors.Is(err, domain.ErrAccountHasHolds),
+		errors.Is(err, domain.ErrAccountHasCredits),
+		errors.Is(err, domain.ErrAccountNegativeBalance),
+		errors.Is(err, domain.ErrOverdraftLimitBelowDebt):
+		WriteErrorResponse(w, http.StatusConflict, err)
+	case errors.Is(err, domain.ErrAccountHolderIsOwner),
+		errors.Is(err, domain.ErrInvalidHolderRole),
+		errors.Is(err, domain.ErrInvalidHolderPermission),
+		errors.Is(err, domain.ErrResidualAccountRequired),
+		errors.Is(err, domain.ErrInvalidResidualAccount),
+		errors.Is(err, domain.ErrInvalidOverdraftLimit),
+		errors.Is(err, domain.ErrOverdraftNotAvailable),
+		errors.Is(err, domain.ErrOverdraftLimitExceeded):
+		WriteErrorResponse(w, http.StatusBadRequest, err)
+	default:
+		logger.Error("Failed to "+action, "error", err.Error())
+		WriteErrorResponse(w, http.StatusInternalServerError, err)
+	}
 }
 
 // Conversion functions
@@ -317,8 +637,12 @@ func AccountToResponse(account *domain.Account) *AccountResponse {
 		UserID:        fmt.Sprintf("%d", account.UserID),
 		AccountNumber: account.Number,
 		Name:          "", // Domain doesn't have Name field
-		AccountType:   "", // Domain doesn't have AccountType field
+		AccountType:   account.Product,
 		Balance:       account.Balance,
+		HeldAmount:    account.HeldAmount,
+		Overdraft:     account.OverdraftLimit,
+		OverdraftUsed: account.OverdraftUsed(),
+		Available:     account.AvailableBalance(),
 		Currency:      account.Currency,
 		Status:        account.Status,
 		CreatedAt:     account.CreatedAt,
@@ -326,6 +650,21 @@ func AccountToResponse(account *domain.Account) *AccountResponse {
 	}
 }
 
+func AccountHolderToResponse(holder *domain.AccountHolder) *AccountHolderResponse {
+	permissions := holder.Permissions
+	if permissions == nil {
+		permissions = []string{}
+	}
+	return &AccountHolderResponse{
+		ID:          fmt.Sprintf("%d", holder.ID),
+		AccountID:   fmt.Sprintf("%d", holder.AccountID),
+		UserID:      fmt.Sprintf("%d", holder.UserID),
+		Role:        holder.Role,
+		Permissions: permissions,
+		CreatedAt:   holder.CreatedAt,
+	}
+}
+
 func TransactionToResponse(transaction *domain.Transaction) *TransactionResponse {
 	var fromAccountID, toAccountID *string
 	if transaction.FromAccount != nil {
@@ -32,6 +32,9 @@ type MonthlyStatsResponse struct {
 
 type CreditLoadResponse struct {
 	TotalDebt       float64 `json:"total_debt"`
+	CreditDebt      float64 `json:"credit_debt"`
+	OverdraftUsed   float64 `json:"overdraft_used"`
+	OverdraftLimit  float64 `json:"overdraft_limit"`
 	MonthlyPayments float64 `json:"monthly_payments"`
 	CreditRatio     float64 `json:"credit_ratio"`
 }
@@ -43,6 +46,11 @@ type BalancePredictionResponse struct {
 	ScheduledPayments float64   `json:"scheduled_payments"`
 }
 
+type SpendingCategoriesResponse struct {
+	Month      string                      `json:"month"`
+	Categories []*service.CategorySpending `json:"categories"`
+}
+
 // AnalyticsHandler обрабатывает запросы аналитики
 type AnalyticsHandler struct {
 	analyticsService service.AnalyticsService
@@ -85,6 +93,36 @@ func (h *AnalyticsHandler) GetMonthlyStats(w http.ResponseWriter, r *http.Reques
 	WriteSuccessResponse(w, response)
 }
 
+// GetSpendingCategories получает расходы по картам в разрезе категорий MCC (?month=ГГГГ-ММ, по умолчанию текущий месяц)
+func (h *AnalyticsHandler) GetSpendingCategories(w http.ResponseWriter, r *http.Request) {
+	userID, err := GetUserIDFromRequest(r)
+	if err != nil {
+		WriteErrorResponse(w, http.StatusUnauthorized, err)
+		return
+	}
+
+	month := time.Now()
+	if value := r.URL.Query().Get("month"); value != "" {
+		month, err = time.Parse("2006-01", value)
+		if err != nil {
+			WriteErrorResponse(w, http.StatusBadRequest, fmt.Errorf("invalid month"))
+			return
+		}
+	}
+
+	categories, err := h.analyticsService.GetSpendingByCategory(r.Context(), userID, month)
+	if err != nil {
+		h.logger.Error("Failed to get spending categories", "user_id", userID, "error", err.Error())
+		WriteErrorResponse(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	WriteSuccessResponse(w, &SpendingCategoriesResponse{
+		Month:      month.Format("2006-01"),
+		Categories: categories,
+	})
+}
+
 // GetCreditLoad получает кредитную нагрузку пользователя
 func (h *AnalyticsHandler) GetCreditLoad(w http.ResponseWriter, r *http.Request) {
 	userID, err := GetUserIDFromRequest(r)
@@ -102,6 +140,9 @@ func (h *AnalyticsHandler) GetCreditLoad(w http.ResponseWriter, r *http.Request)
 
 	response := &CreditLoadResponse{
 		TotalDebt:       creditLoad.TotalDebt,
+		CreditDebt:      creditLoad.CreditDebt,
+		OverdraftUsed:   creditLoad.OverdraftUsed,
+		OverdraftLimit:  creditLoad.OverdraftLimit,
 		MonthlyPayments: creditLoad.MonthlyPayments,
 		CreditRatio:     creditLoad.CreditRatio,
 	}
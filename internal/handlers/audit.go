@@ -0,0 +1,158 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/vterdunov/learn-bank-app/internal/domain"
+	"github.com/vterdunov/learn-bank-app/internal/service"
+)
+
+// Audit Response DTOs
+type AuditEventResponse struct {
+	ID           string          `json:"id"`
+	Seq          int64           `json:"seq"`
+	ActorUserID  *string         `json:"actor_user_id"`
+	ActorType    string          `json:"actor_type"`
+	Action       string          `json:"action"`
+	ResourceType string          `json:"resource_type"`
+	ResourceID   string          `json:"resource_id"`
+	RequestID    string          `json:"request_id"`
+	IP           string          `json:"ip"`
+	Before       json.RawMessage `json:"before,omitempty"`
+	After        json.RawMessage `json:"after,omitempty"`
+	Metadata     json.RawMessage `json:"metadata,omitempty"`
+	CreatedAt    time.Time       `json:"created_at"`
+	PrevHash     string          `json:"prev_hash"`
+	Hash         string          `json:"hash"`
+}
+
+// AuditHandler обрабатывает запросы администратора к журналу аудита
+type AuditHandler struct {
+	auditService service.AuditService
+	logger       *slog.Logger
+}
+
+func NewAuditHandler(auditService service.AuditService, logger *slog.Logger) *AuditHandler {
+	return &AuditHandler{
+		auditService: auditService,
+		logger:       logger,
+	}
+}
+
+// ListEvents возвращает записи аудита по фильтру из query параметров
+func (h *AuditHandler) ListEvents(w http.ResponseWriter, r *http.Request) {
+	adminID, err := GetUserIDFromRequest(r)
+	if err != nil {
+		WriteErrorResponse(w, http.StatusUnauthorized, err)
+		return
+	}
+
+	filter, err := parseAuditFilter(r)
+	if err != nil {
+		WriteErrorResponse(w, http.StatusBadRequest, err)
+		return
+	}
+
+	events, err := h.auditService.QueryEvents(r.Context(), adminID, filter)
+	if err != nil {
+		h.logger.Error("Failed to query audit events", "admin_id", adminID, "error", err.Error())
+		WriteErrorResponse(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	responses := make([]*AuditEventResponse, 0, len(events))
+	for _, event := range events {
+		responses = append(responses, AuditEventToResponse(event))
+	}
+
+	WriteSuccessResponse(w, responses)
+}
+
+// VerifyChain проверяет целостность цепочки аудита
+func (h *AuditHandler) VerifyChain(w http.ResponseWriter, r *http.Request) {
+	adminID, err := GetUserIDFromRequest(r)
+	if err != nil {
+		WriteErrorResponse(w, http.StatusUnauthorized, err)
+		return
+	}
+
+	result, err := h.auditService.VerifyChain(r.Context(), &adminID)
+	if err != nil {
+		h.logger.Error("Failed to verify audit chain", "admin_id", adminID, "error", err.Error())
+		WriteErrorResponse(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	WriteSuccessResponse(w, result)
+}
+
+// parseAuditFilter разбирает query параметры фильтра аудита
+func parseAuditFilter(r *http.Request) (domain.AuditFilter, error) {
+	query := r.URL.Query()
+	filter := domain.AuditFilter{
+		Action:       query.Get("action"),
+		ResourceType: query.Get("resource_type"),
+		ResourceID:   query.Get("resource_id"),
+	}
+
+	if value := query.Get("actor_user_id"); value != "" {
+		actorID, err := strconv.Atoi(value)
+		if err != nil {
+			return filter, fmt.Errorf("invalid actor_user_id")
+		}
+		filter.ActorUserID = &actorID
+	}
+
+	for name, target := range map[string]**time.Time{"from": &filter.From, "to": &filter.To} {
+		if value := query.Get(name); value != "" {
+			parsed, err := time.Parse(time.RFC3339, value)
+			if err != nil {
+				return filter, fmt.Errorf("invalid %s: expected RFC3339", name)
+			}
+			*target = &parsed
+		}
+	}
+
+	for name, target := range map[string]*int{"limit": &filter.Limit, "offset": &filter.Offset} {
+		if value := query.Get(name); value != "" {
+			parsed, err := strconv.Atoi(value)
+			if err != nil || parsed < 0 {
+				return filter, fmt.Errorf("invalid %s", name)
+			}
+			*target = parsed
+		}
+	}
+
+	return filter, nil
+}
+
+func AuditEventToResponse(event *domain.AuditEvent) *AuditEventResponse {
+	response := &AuditEventResponse{
+		ID:           fmt.Sprintf("%d", event.ID),
+		Seq:          event.Seq,
+		ActorType:    event.ActorType,
+		Action:       event.Action,
+		ResourceType: event.ResourceType,
+		ResourceID:   event.ResourceID,
+		RequestID:    event.RequestID,
+		IP:           event.IP,
+		Before:       event.Before,
+		After:        event.After,
+		Metadata:     event.Metadata,
+		CreatedAt:    event.CreatedAt,
+		PrevHash:     event.PrevHash,
+		Hash:         event.Hash,
+	}
+
+	if event.ActorUserID != nil {
+		actorID := fmt.Sprintf("%d", *event.ActorUserID)
+		response.ActorUserID = &actorID
+	}
+
+	return response
+}
@@ -1,6 +1,8 @@
 package handlers
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"log/slog"
 	"net/http"
@@ -14,8 +16,10 @@ import (
 // CreateCardRequest структура запроса для создания карты
 type CreateCardRequest struct {
 	AccountID    string `json:"account_id" validate:"required"`
-	CardType     string `json:"card_type" validate:"required,oneof=debit credit"`
-	PinCode      string `json:"pin_code" validate:"required,len=4,numeric"`
+	CardType     string `json:"card_type,omitempty" validate:"omitempty,oneof=physical virtual single_use"`
+	ValidityDays int    `json:"validity_days,omitempty" validate:"omitempty,min=1,max=365"`
+	MerchantLock string `json:"merchant_lock,omitempty" validate:"omitempty,max=100"`
+	PinCode      string `json:"pin_code,omitempty" validate:"omitempty,min=4,max=6,numeric"`
 	DailyLimit   int    `json:"daily_limit" validate:"required,min=1000,max=1000000"`
 	MonthlyLimit int    `json:"monthly_limit" validate:"required,min=5000,max=10000000"`
 }
@@ -28,6 +32,33 @@ type CardPaymentRequest struct {
 	CVV         string  `json:"cvv" validate:"required,len=3,numeric"`
 }
 
+// CardDetailsPaymentRequest структура запроса оплаты по реквизитам карты
+type CardDetailsPaymentRequest struct {
+	CardNumber  string  `json:"card_number" validate:"required,len=16,numeric"`
+	ExpiryDate  string  `json:"expiry_date" validate:"required"` // ММ/ГГ
+	CVV         string  `json:"cvv" validate:"required,len=3,numeric"`
+	Amount      float64 `json:"amount" validate:"required,gt=0"`
+	MerchantID  string  `json:"merchant_id" validate:"required"`
+	Description string  `json:"description,omitempty" validate:"max=255"`
+}
+
+// SetCardPINRequest структура запроса установки PIN карты
+type SetCardPINRequest struct {
+	PIN string `json:"pin" validate:"required,min=4,max=6,numeric"`
+}
+
+// ChangeCardPINRequest структура запроса смены PIN карты
+type ChangeCardPINRequest struct {
+	OldPIN string `json:"old_pin" validate:"required,min=4,max=6,numeric"`
+	NewPIN string `json:"new_pin" validate:"required,min=4,max=6,numeric"`
+}
+
+// CardHoldAmountRequest структура запроса на списание или возврат по холду.
+// Нулевая сумма означает всю авторизованную (для возврата — всю оставшуюся) сумму.
+type CardHoldAmountRequest struct {
+	Amount float64 `json:"amount" validate:"gte=0"`
+}
+
 // CardResponse структура ответа с информацией о карте
 type CardResponse struct {
 	ID           string    `json:"id"`
@@ -36,6 +67,8 @@ type CardResponse struct {
 	CardType     string    `json:"card_type"`
 	ExpiryMonth  int       `json:"expiry_month"`
 	ExpiryYear   int       `json:"expiry_year"`
+	ExpiresAt    time.Time `json:"expires_at"`
+	MerchantLock string    `json:"merchant_lock,omitempty"`
 	Status       string    `json:"status"`
 	DailyLimit   int       `json:"daily_limit"`
 	MonthlyLimit int       `json:"monthly_limit"`
@@ -45,6 +78,23 @@ type CardResponse struct {
 	UpdatedAt    time.Time `json:"updated_at"`
 }
 
+// CardHoldResponse структура ответа с информацией о холде
+type CardHoldResponse struct {
+	ID             string     `json:"id"`
+	CardID         string     `json:"card_id"`
+	AccountID      string     `json:"account_id"`
+	TransactionID  string     `json:"transaction_id"`
+	MerchantID     string     `json:"merchant_id"`
+	Description    string     `json:"description"`
+	Amount         float64    `json:"amount"`
+	CapturedAmount float64    `json:"captured_amount"`
+	RefundedAmount float64    `json:"refunded_amount"`
+	Status         string     `json:"status"`
+	ExpiresAt      time.Time  `json:"expires_at"`
+	CapturedAt     *time.Time `json:"captured_at,omitempty"`
+	CreatedAt      time.Time  `json:"created_at"`
+}
+
 // CardHandler обрабатывает запросы управления картами
 type CardHandler struct {
 	cardService service.CardService
@@ -89,10 +139,30 @@ func (h *CardHandler) CreateCard(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Создание карты
-	card, err := h.cardService.CreateCard(r.Context(), userID, accountID)
+	card, err := h.cardService.CreateCard(r.Context(), userID, domain.CreateCardRequest{
+		AccountID:    accountID,
+		CardType:     req.CardType,
+		ValidityDays: req.ValidityDays,
+		MerchantLock: req.MerchantLock,
+		PIN:          req.PinCode,
+	})
 	if err != nil {
-		h.logger.Error("Failed to create card", "account_id", accountID, "user_id", userID, "error", err.Error())
-		WriteErrorResponse(w, http.StatusInternalServerError, err)
+		var serviceErr *service.ServiceError
+		switch {
+		case errors.As(err, &serviceErr):
+			WriteErrorResponse(w, serviceErr.Code, err)
+		case errors.Is(err, service.ErrAccountNotFound):
+			WriteErrorResponse(w, http.StatusNotFound, err)
+		case errors.Is(err, service.ErrAccountBlocked),
+			errors.Is(err, domain.ErrInvalidPIN),
+			errors.Is(err, domain.ErrInvalidCardType),
+			errors.Is(err, domain.ErrInvalidCardValidity),
+			errors.Is(err, domain.ErrInvalidMerchantLock):
+			WriteErrorResponse(w, http.StatusBadRequest, err)
+		default:
+			h.logger.Error("Failed to create card", "account_id", accountID, "user_id", userID, "error", err.Error())
+			WriteErrorResponse(w, http.StatusInternalServerError, err)
+		}
 		return
 	}
 
@@ -127,8 +197,16 @@ func (h *CardHandler) GetAccountCards(w http.ResponseWriter, r *http.Request) {
 	// Получение карт
 	cards, err := h.cardService.GetAccountCards(r.Context(), userID, accountID)
 	if err != nil {
-		h.logger.Error("Failed to get account cards", "account_id", accountID, "user_id", userID, "error", err.Error())
-		WriteErrorResponse(w, http.StatusInternalServerError, err)
+		var serviceErr *service.ServiceError
+		switch {
+		case errors.As(err, &serviceErr):
+			WriteErrorResponse(w, serviceErr.Code, err)
+		case errors.Is(err, service.ErrAccountNotFound):
+			WriteErrorResponse(w, http.StatusNotFound, err)
+		default:
+			h.logger.Error("Failed to get account cards", "account_id", accountID, "user_id", userID, "error", err.Error())
+			WriteErrorResponse(w, http.StatusInternalServerError, err)
+		}
 		return
 	}
 
@@ -181,7 +259,13 @@ func (h *CardHandler) CardPayment(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Выполнение платежа
-	if err := h.cardService.ProcessPayment(r.Context(), userID, cardID, req.Amount); err != nil {
+	if err := h.cardService.ProcessPayment(r.Context(), userID, cardID, req.Amount, req.MerchantID); err != nil {
+		// Платеж отложен до решения оператора или отклонен антифродом
+		if writeScreeningError(w, err) {
+			h.logger.Warn("Card payment stopped by fraud screening", "card_id", cardID, "amount", req.Amount, "error", err.Error())
+			return
+		}
+
 		h.logger.Error("Failed to process card payment",
 			"card_id", cardID,
 			"user_id", userID,
@@ -190,7 +274,8 @@ func (h *CardHandler) CardPayment(w http.ResponseWriter, r *http.Request) {
 
 		// Определяем статус код на основе ошибки
 		statusCode := http.StatusInternalServerError
-		if err.Error() == "insufficient funds" || err.Error() == "invalid CVV" || err.Error() == "card expired" {
+		if err.Error() == "insufficient funds" || err.Error() == "invalid CVV" || err.Error() == "card expired" ||
+			errors.Is(err, domain.ErrMerchantSelfPayment) || errors.Is(err, domain.ErrCardMerchantLocked) {
 			statusCode = http.StatusBadRequest
 		}
 
@@ -207,15 +292,365 @@ func (h *CardHandler) CardPayment(w http.ResponseWriter, r *http.Request) {
 	WriteSuccessResponse(w, map[string]string{"message": "Payment successful"})
 }
 
+// PayByCardDetails выполняет оплату по номеру, сроку действия и CVV карты
+func (h *CardHandler) PayByCardDetails(w http.ResponseWriter, r *http.Request) {
+	var req CardDetailsPaymentRequest
+
+	if err := ValidateJSON(r, &req); err != nil {
+		WriteErrorResponse(w, http.StatusBadRequest, err)
+		return
+	}
+
+	if validationErr := Validate(&req); validationErr != nil {
+		WriteErrorResponse(w, http.StatusBadRequest, validationErr)
+		return
+	}
+
+	userID, err := GetUserIDFromRequest(r)
+	if err != nil {
+		WriteErrorResponse(w, http.StatusUnauthorized, err)
+		return
+	}
+
+	err = h.cardService.PayByCardDetails(r.Context(), userID, service.CardDetailsPaymentRequest{
+		Number:      req.CardNumber,
+		ExpiryDate:  req.ExpiryDate,
+		CVV:         req.CVV,
+		Amount:      req.Amount,
+		MerchantID:  req.MerchantID,
+		Description: req.Description,
+	})
+	if err != nil {
+		if errors.Is(err, service.ErrCardVerificationFailed) {
+			WriteErrorResponse(w, http.StatusBadRequest, err)
+			return
+		}
+		writeCardHoldError(w, h.logger, "process card details payment", err)
+		return
+	}
+
+	h.logger.Info("Card details payment processed", "user_id", userID, "amount", req.Amount, "merchant_id", req.MerchantID)
+
+	WriteSuccessResponse(w, map[string]string{"message": "Payment successful"})
+}
+
+// AuthorizePayment авторизует платеж картой: сумма блокируется на счете до списания
+func (h *CardHandler) AuthorizePayment(w http.ResponseWriter, r *http.Request) {
+	var req CardPaymentRequest
+
+	if err := ValidateJSON(r, &req); err != nil {
+		WriteErrorResponse(w, http.StatusBadRequest, err)
+		return
+	}
+
+	if validationErr := Validate(&req); validationErr != nil {
+		WriteErrorResponse(w, http.StatusBadRequest, validationErr)
+		return
+	}
+
+	cardID, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		WriteErrorResponse(w, http.StatusBadRequest, fmt.Errorf("invalid card ID"))
+		return
+	}
+
+	userID, err := GetUserIDFromRequest(r)
+	if err != nil {
+		WriteErrorResponse(w, http.StatusUnauthorized, err)
+		return
+	}
+
+	hold, err := h.cardService.AuthorizePayment(r.Context(), userID, cardID, req.Amount, req.MerchantID, req.Description)
+	if err != nil {
+		writeCardHoldError(w, h.logger, "authorize card payment", err)
+		return
+	}
+
+	h.logger.Info("Card payment authorized", "hold_id", hold.ID, "card_id", cardID, "amount", hold.Amount)
+
+	WriteSuccessResponse(w, CardHoldToResponse(hold))
+}
+
+// CapturePayment списывает авторизованный платеж полностью или частично (оператор)
+func (h *CardHandler) CapturePayment(w http.ResponseWriter, r *http.Request) {
+	h.changeHoldAmount(w, r, "capture card payment", h.cardService.CapturePayment)
+}
+
+// RefundPayment возвращает списанный платеж полностью или частично (оператор)
+func (h *CardHandler) RefundPayment(w http.ResponseWriter, r *http.Request) {
+	h.changeHoldAmount(w, r, "refund card payment", h.cardService.RefundPayment)
+}
+
+// VoidAuthorization отменяет авторизацию и разблокирует средства (оператор)
+func (h *CardHandler) VoidAuthorization(w http.ResponseWriter, r *http.Request) {
+	h.releaseHold(w, r, "void card authorization", h.cardService.VoidAuthorization)
+}
+
+// CancelAuthorization отменяет авторизацию, созданную самим держателем
+func (h *CardHandler) CancelAuthorization(w http.ResponseWriter, r *http.Request) {
+	h.releaseHold(w, r, "cancel card authorization", h.cardService.CancelAuthorization)
+}
+
+// releaseHold разбирает ID холда и вызывает операцию отмены авторизации
+func (h *CardHandler) releaseHold(
+	w http.ResponseWriter,
+	r *http.Request,
+	operation string,
+	release func(ctx context.Context, userID, holdID int) (*domain.CardHold, error),
+) {
+	holdID, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		WriteErrorResponse(w, http.StatusBadRequest, fmt.Errorf("invalid hold ID"))
+		return
+	}
+
+	userID, err := GetUserIDFromRequest(r)
+	if err != nil {
+		WriteErrorResponse(w, http.StatusUnauthorized, err)
+		return
+	}
+
+	hold, err := release(r.Context(), userID, holdID)
+	if err != nil {
+		writeCardHoldError(w, h.logger, operation, err)
+		return
+	}
+
+	WriteSuccessResponse(w, CardHoldToResponse(hold))
+}
+
+// SetPIN устанавливает первый PIN карты
+func (h *CardHandler) SetPIN(w http.ResponseWriter, r *http.Request) {
+	cardID, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		WriteErrorResponse(w, http.StatusBadRequest, fmt.Errorf("invalid card ID"))
+		return
+	}
+
+	var req SetCardPINRequest
+
+	if err := ValidateJSON(r, &req); err != nil {
+		WriteErrorResponse(w, http.StatusBadRequest, err)
+		return
+	}
+
+	if validationErr := Validate(&req); validationErr != nil {
+		WriteErrorResponse(w, http.StatusBadRequest, validationErr)
+		return
+	}
+
+	userID, err := GetUserIDFromRequest(r)
+	if err != nil {
+		WriteErrorResponse(w, http.StatusUnauthorized, err)
+		return
+	}
+
+	if err := h.cardService.SetPIN(r.Context(), userID, cardID, req.PIN); err != nil {
+		writeCardPINError(w, h.logger, "set card PIN", err)
+		return
+	}
+
+	WriteSuccessResponse(w, map[string]string{"message": "PIN set"})
+}
+
+// ChangePIN меняет PIN карты по текущему PIN
+func (h *CardHandler) ChangePIN(w http.ResponseWriter, r *http.Request) {
+	cardID, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		WriteErrorResponse(w, http.StatusBadRequest, fmt.Errorf("invalid card ID"))
+		return
+	}
+
+	var req ChangeCardPINRequest
+
+	if err := ValidateJSON(r, &req); err != nil {
+		WriteErrorResponse(w, http.StatusBadRequest, err)
+		return
+	}
+
+	if validationErr := Validate(&req); validationErr != nil {
+		WriteErrorResponse(w, http.StatusBadRequest, validationErr)
+		return
+	}
+
+	userID, err := GetUserIDFromRequest(r)
+	if err != nil {
+		WriteErrorResponse(w, http.StatusUnauthorized, err)
+		return
+	}
+
+	if err := h.cardService.ChangePIN(r.Context(), userID, cardID, req.OldPIN, req.NewPIN); err != nil {
+		writeCardPINError(w, h.logger, "change card PIN", err)
+		return
+	}
+
+	WriteSuccessResponse(w, map[string]string{"message": "PIN changed"})
+}
+
+// UnblockCard разблокирует карту (оператор)
+func (h *CardHandler) UnblockCard(w http.ResponseWriter, r *http.Request) {
+	cardID, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		WriteErrorResponse(w, http.StatusBadRequest, fmt.Errorf("invalid card ID"))
+		return
+	}
+
+	adminID, err := GetUserIDFromRequest(r)
+	if err != nil {
+		WriteErrorResponse(w, http.StatusUnauthorized, err)
+		return
+	}
+
+	if err := h.cardService.UnblockCard(r.Context(), adminID, cardID); err != nil {
+		writeCardPINError(w, h.logger, "unblock card", err)
+		return
+	}
+
+	WriteSuccessResponse(w, map[string]string{"message": "Card unblocked"})
+}
+
+// GetAccountHolds возвращает холды счета
+func (h *CardHandler) GetAccountHolds(w http.ResponseWriter, r *http.Request) {
+	accountID, err := strconv.Atoi(r.PathValue("accountId"))
+	if err != nil {
+		WriteErrorResponse(w, http.StatusBadRequest, fmt.Errorf("invalid account ID"))
+		return
+	}
+
+	userID, err := GetUserIDFromRequest(r)
+	if err != nil {
+		WriteErrorResponse(w, http.StatusUnauthorized, err)
+		return
+	}
+
+	holds, err := h.cardService.GetAccountHolds(r.Context(), userID, accountID)
+	if err != nil {
+		writeCardHoldError(w, h.logger, "get account holds", err)
+		return
+	}
+
+	response := make([]*CardHoldResponse, len(holds))
+	for i, hold := range holds {
+		response[i] = CardHoldToResponse(hold)
+	}
+
+	WriteSuccessResponse(w, response)
+}
+
+// changeHoldAmount выполняет списание или возврат по холду на сумму из запроса
+func (h *CardHandler) changeHoldAmount(
+	w http.ResponseWriter,
+	r *http.Request,
+	action string,
+	change func(ctx context.Context, userID, holdID int, amount float64) (*domain.CardHold, error),
+) {
+	var req CardHoldAmountRequest
+
+	if err := ValidateJSON(r, &req); err != nil {
+		WriteErrorResponse(w, http.StatusBadRequest, err)
+		return
+	}
+
+	if validationErr := Validate(&req); validationErr != nil {
+		WriteErrorResponse(w, http.StatusBadRequest, validationErr)
+		return
+	}
+
+	holdID, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		WriteErrorResponse(w, http.StatusBadRequest, fmt.Errorf("invalid hold ID"))
+		return
+	}
+
+	userID, err := GetUserIDFromRequest(r)
+	if err != nil {
+		WriteErrorResponse(w, http.StatusUnauthorized, err)
+		return
+	}
+
+	hold, err := change(r.Context(), userID, holdID, req.Amount)
+	if err != nil {
+		writeCardHoldError(w, h.logger, action, err)
+		return
+	}
+
+	WriteSuccessResponse(w, CardHoldToResponse(hold))
+}
+
+// writeCardHoldError отвечает кодом, соответствующим ошибке авторизации или операции с холдом
+func writeCardHoldError(w http.ResponseWriter, logger *slog.Logger, action string, err error) {
+	var serviceErr *service.ServiceError
+	switch {
+	case errors.As(err, &serviceErr):
+		WriteErrorResponse(w, serviceErr.Code, err)
+	case writeScreeningError(w, err):
+	case errors.Is(err, domain.ErrCardHoldNotFound),
+		errors.Is(err, service.ErrCardNotFound),
+		errors.Is(err, service.ErrAccountNotFound):
+		WriteErrorResponse(w, http.StatusNotFound, err)
+	case errors.Is(err, domain.ErrCardHoldNotAuthorized),
+		errors.Is(err, domain.ErrCardHoldNotCaptured),
+		errors.Is(err, domain.ErrCardHoldExpired):
+		WriteErrorResponse(w, http.StatusConflict, err)
+	case errors.Is(err, domain.ErrInvalidCaptureAmount),
+		errors.Is(err, domain.ErrInvalidRefundAmount),
+		errors.Is(err, domain.ErrInvalidMerchantID),
+		errors.Is(err, domain.ErrMerchantSelfPayment),
+		errors.Is(err, domain.ErrCardMerchantLocked),
+		errors.Is(err, domain.ErrMerchantInsufficientFunds),
+		errors.Is(err, service.ErrInvalidAmount),
+		errors.Is(err, service.ErrInsufficientFunds),
+		errors.Is(err, service.ErrCardBlocked),
+		errors.Is(err, service.ErrCardExpired),
+		errors.Is(err, service.ErrAccountBlocked):
+		WriteErrorResponse(w, http.StatusBadRequest, err)
+	default:
+		logger.Error("Failed to "+action, "error", err.Error())
+		WriteErrorResponse(w, http.StatusInternalServerError, err)
+	}
+}
+
+// writeCardPINError преобразует ошибку операций с PIN карты в HTTP ответ
+func writeCardPINError(w http.ResponseWriter, logger *slog.Logger, action string, err error) {
+	var serviceErr *service.ServiceError
+	switch {
+	case writeScreeningError(w, err):
+	case errors.As(err, &serviceErr):
+		WriteErrorResponse(w, serviceErr.Code, err)
+	case errors.Is(err, service.ErrCardNotFound),
+		errors.Is(err, service.ErrAccountNotFound):
+		WriteErrorResponse(w, http.StatusNotFound, err)
+	case errors.Is(err, domain.ErrIncorrectPIN):
+		WriteErrorResponse(w, http.StatusForbidden, err)
+	case errors.Is(err, domain.ErrPINAlreadySet),
+		errors.Is(err, domain.ErrCardNotBlocked):
+		WriteErrorResponse(w, http.StatusConflict, err)
+	case errors.Is(err, domain.ErrInvalidPIN),
+		errors.Is(err, domain.ErrPINNotSet),
+		errors.Is(err, service.ErrCardAccountMismatch),
+		errors.Is(err, service.ErrInvalidAmount),
+		errors.Is(err, service.ErrInsufficientFunds),
+		errors.Is(err, service.ErrCardBlocked),
+		errors.Is(err, service.ErrCardExpired),
+		errors.Is(err, service.ErrAccountBlocked):
+		WriteErrorResponse(w, http.StatusBadRequest, err)
+	default:
+		logger.Error("Failed to "+action, "error", err.Error())
+		WriteErrorResponse(w, http.StatusInternalServerError, err)
+	}
+}
+
 // Conversion functions
 func CardToResponse(card *domain.Card) *CardResponse {
 	return &CardResponse{
 		ID:           fmt.Sprintf("%d", card.ID),
 		AccountID:    fmt.Sprintf("%d", card.AccountID),
 		MaskedNumber: "****-****-****-XXXX", // Маскированный номер
-		CardType:     domain.CardTypeLearnBank,
+		CardType:     card.CardType,
 		ExpiryMonth:  int(card.ExpiryDate.Month()),
 		ExpiryYear:   card.ExpiryDate.Year(),
+		ExpiresAt:    card.ExpiryDate,
+		MerchantLock: card.MerchantLock,
 		Status:       card.Status,
 		DailyLimit:   100000,  // Пример лимита
 		MonthlyLimit: 1000000, // Пример лимита
@@ -225,3 +660,21 @@ func CardToResponse(card *domain.Card) *CardResponse {
 		UpdatedAt:    card.UpdatedAt,
 	}
 }
+
+func CardHoldToResponse(hold *domain.CardHold) *CardHoldResponse {
+	return &CardHoldResponse{
+		ID:             fmt.Sprintf("%d", hold.ID),
+		CardID:         fmt.Sprintf("%d", hold.CardID),
+		AccountID:      fmt.Sprintf("%d", hold.AccountID),
+		TransactionID:  fmt.Sprintf("%d", hold.TransactionID),
+		MerchantID:     hold.MerchantID,
+		Description:    hold.Description,
+		Amount:         hold.Amount,
+		CapturedAmount: hold.CapturedAmount,
+		RefundedAmount: hold.RefundedAmount,
+		Status:         hold.Status,
+		ExpiresAt:      hold.ExpiresAt,
+		CapturedAt:     hold.CapturedAt,
+		CreatedAt:      hold.CreatedAt,
+	}
+}
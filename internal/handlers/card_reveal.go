@@ -0,0 +1,154 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/vterdunov/learn-bank-app/internal/domain"
+	"github.com/vterdunov/learn-bank-app/internal/service"
+)
+
+// Card reveal Request DTOs
+type CardRevealTokenRequest struct {
+	Password string `json:"password" validate:"required"`
+}
+
+type CardRevealRequest struct {
+	Token string `json:"token" validate:"required"`
+}
+
+// Card reveal Response DTOs
+type CardRevealTokenResponse struct {
+	Token     string    `json:"token"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+type CardRevealResponse struct {
+	CardID     string `json:"card_id"`
+	Number     string `json:"number"`
+	ExpiryDate string `json:"expiry_date"`
+}
+
+// CardRevealHandler обрабатывает запросы показа полных реквизитов карты
+type CardRevealHandler struct {
+	revealService service.CardRevealService
+	logger        *slog.Logger
+}
+
+func NewCardRevealHandler(revealService service.CardRevealService, logger *slog.Logger) *CardRevealHandler {
+	return &CardRevealHandler{
+		revealService: revealService,
+		logger:        logger,
+	}
+}
+
+// IssueToken выдает одноразовый токен показа реквизитов после повторного ввода пароля
+func (h *CardRevealHandler) IssueToken(w http.ResponseWriter, r *http.Request) {
+	cardID, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		WriteErrorResponse(w, http.StatusBadRequest, fmt.Errorf("invalid card ID"))
+		return
+	}
+
+	var req CardRevealTokenRequest
+
+	if err := ValidateJSON(r, &req); err != nil {
+		WriteErrorResponse(w, http.StatusBadRequest, err)
+		return
+	}
+
+	if validationErr := Validate(&req); validationErr != nil {
+		WriteErrorResponse(w, http.StatusBadRequest, validationErr)
+		return
+	}
+
+	userID, err := GetUserIDFromRequest(r)
+	if err != nil {
+		WriteErrorResponse(w, http.StatusUnauthorized, err)
+		return
+	}
+
+	setNoStoreHeaders(w)
+
+	token, err := h.revealService.IssueRevealToken(r.Context(), userID, cardID, req.Password)
+	if err != nil {
+		h.writeRevealError(w, cardID, err)
+		return
+	}
+
+	WriteSuccessResponse(w, &CardRevealTokenResponse{
+		Token:     token.Token,
+		ExpiresAt: token.ExpiresAt,
+	})
+}
+
+// Reveal погашает токен и возвращает номер и срок действия карты
+func (h *CardRevealHandler) Reveal(w http.ResponseWriter, r *http.Request) {
+	cardID, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		WriteErrorResponse(w, http.StatusBadRequest, fmt.Errorf("invalid card ID"))
+		return
+	}
+
+	var req CardRevealRequest
+
+	if err := ValidateJSON(r, &req); err != nil {
+		WriteErrorResponse(w, http.StatusBadRequest, err)
+		return
+	}
+
+	if validationErr := Validate(&req); validationErr != nil {
+		WriteErrorResponse(w, http.StatusBadRequest, validationErr)
+		return
+	}
+
+	userID, err := GetUserIDFromRequest(r)
+	if err != nil {
+		WriteErrorResponse(w, http.StatusUnauthorized, err)
+		return
+	}
+
+	setNoStoreHeaders(w)
+
+	data, err := h.revealService.RevealCard(r.Context(), userID, cardID, req.Token)
+	if err != nil {
+		h.writeRevealError(w, cardID, err)
+		return
+	}
+
+	WriteSuccessResponse(w, &CardRevealResponse{
+		CardID:     strconv.Itoa(cardID),
+		Number:     data.Number,
+		ExpiryDate: data.ExpiryDate.Format("01/06"),
+	})
+}
+
+func (h *CardRevealHandler) writeRevealError(w http.ResponseWriter, cardID int, err error) {
+	var serviceErr *service.ServiceError
+	switch {
+	case errors.As(err, &serviceErr):
+		WriteErrorResponse(w, serviceErr.Code, err)
+	case errors.Is(err, service.ErrCardNotFound):
+		WriteErrorResponse(w, http.StatusNotFound, err)
+	case errors.Is(err, service.ErrCardBlocked):
+		WriteErrorResponse(w, http.StatusBadRequest, err)
+	case errors.Is(err, domain.ErrStepUpFailed),
+		errors.Is(err, domain.ErrCardRevealTokenInvalid):
+		WriteErrorResponse(w, http.StatusForbidden, err)
+	case errors.Is(err, domain.ErrCardRevealRateLimited):
+		WriteErrorResponse(w, http.StatusTooManyRequests, err)
+	default:
+		h.logger.Error("Failed to reveal card details", "card_id", cardID, "error", err.Error())
+		WriteErrorResponse(w, http.StatusInternalServerError, err)
+	}
+}
+
+// setNoStoreHeaders запрещает кэширование ответов с реквизитами карты
+func setNoStoreHeaders(w http.ResponseWriter) {
+	w.Header().Set("Cache-Control", "no-store")
+	w.Header().Set("Pragma", "no-cache")
+}
@@ -0,0 +1,243 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/vterdunov/learn-bank-app/internal/domain"
+	"github.com/vterdunov/learn-bank-app/internal/service"
+)
+
+// Cashback Request DTOs
+type CreateCashbackRuleRequest struct {
+	MCC        string  `json:"mcc,omitempty" validate:"omitempty,len=4,numeric"`
+	Category   string  `json:"category,omitempty"`
+	Rate       float64 `json:"rate" validate:"gte=0,lte=100"`
+	MonthlyCap float64 `json:"monthly_cap" validate:"gte=0"`
+}
+
+// Cashback Response DTOs
+type CashbackRuleResponse struct {
+	ID         string    `json:"id"`
+	MCC        string    `json:"mcc,omitempty"`
+	Category   string    `json:"category,omitempty"`
+	Rate       float64   `json:"rate"`
+	MonthlyCap float64   `json:"monthly_cap"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+type CashbackEntryResponse struct {
+	TransactionID string    `json:"transaction_id"`
+	Kind          string    `json:"kind"`
+	Period        string    `json:"period"`
+	BaseAmount    float64   `json:"base_amount"`
+	Rate          float64   `json:"rate"`
+	Amount        float64   `json:"amount"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+type CashbackPayoutResponse struct {
+	Period    string    `json:"period"`
+	Amount    float64   `json:"amount"`
+	AccountID string    `json:"account_id"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type CardRewardsResponse struct {
+	CardID       string                    `json:"card_id"`
+	Period       string                    `json:"period"`
+	PeriodAmount float64                   `json:"period_amount"`
+	MonthlyCap   float64                   `json:"monthly_cap"`
+	CapRemaining float64                   `json:"cap_remaining"`
+	Pending      float64                   `json:"pending"`
+	Paid         float64                   `json:"paid"`
+	Entries      []*CashbackEntryResponse  `json:"entries"`
+	Payouts      []*CashbackPayoutResponse `json:"payouts"`
+}
+
+// CashbackHandler обрабатывает запросы кэшбэка по картам и правил кэшбэка
+type CashbackHandler struct {
+	cashbackService service.CashbackService
+	logger          *slog.Logger
+}
+
+func NewCashbackHandler(cashbackService service.CashbackService, logger *slog.Logger) *CashbackHandler {
+	return &CashbackHandler{
+		cashbackService: cashbackService,
+		logger:          logger,
+	}
+}
+
+// GetCardRewards возвращает кэшбэк по карте
+func (h *CashbackHandler) GetCardRewards(w http.ResponseWriter, r *http.Request) {
+	cardID, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		WriteErrorResponse(w, http.StatusBadRequest, fmt.Errorf("invalid card ID"))
+		return
+	}
+
+	userID, err := GetUserIDFromRequest(r)
+	if err != nil {
+		WriteErrorResponse(w, http.StatusUnauthorized, err)
+		return
+	}
+
+	rewards, err := h.cashbackService.GetCardRewards(r.Context(), userID, cardID)
+	if err != nil {
+		var serviceErr *service.ServiceError
+		switch {
+		case errors.As(err, &serviceErr):
+			WriteErrorResponse(w, serviceErr.Code, err)
+		case errors.Is(err, service.ErrCardNotFound):
+			WriteErrorResponse(w, http.StatusNotFound, err)
+		default:
+			h.logger.Error("Failed to get card rewards", "card_id", cardID, "error", err.Error())
+			WriteErrorResponse(w, http.StatusInternalServerError, err)
+		}
+		return
+	}
+
+	WriteSuccessResponse(w, CardRewardsToResponse(rewards))
+}
+
+// CreateRule создает правило кэшбэка
+func (h *CashbackHandler) CreateRule(w http.ResponseWriter, r *http.Request) {
+	var req CreateCashbackRuleRequest
+
+	if err := ValidateJSON(r, &req); err != nil {
+		WriteErrorResponse(w, http.StatusBadRequest, err)
+		return
+	}
+
+	if validationErr := Validate(&req); validationErr != nil {
+		WriteErrorResponse(w, http.StatusBadRequest, validationErr)
+		return
+	}
+
+	adminID, err := GetUserIDFromRequest(r)
+	if err != nil {
+		WriteErrorResponse(w, http.StatusUnauthorized, err)
+		return
+	}
+
+	rule, err := h.cashbackService.CreateRule(r.Context(), adminID, domain.CreateCashbackRuleRequest{
+		MCC:        req.MCC,
+		Category:   req.Category,
+		Rate:       req.Rate,
+		MonthlyCap: req.MonthlyCap,
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrCashbackRuleExists):
+			WriteErrorResponse(w, http.StatusConflict, err)
+		case errors.Is(err, domain.ErrInvalidCashbackRule),
+			errors.Is(err, domain.ErrInvalidCashbackRate),
+			errors.Is(err, domain.ErrInvalidCashbackCap),
+			errors.Is(err, domain.ErrInvalidCategory),
+			errors.Is(err, domain.ErrInvalidMCC):
+			WriteErrorResponse(w, http.StatusBadRequest, err)
+		default:
+			h.logger.Error("Failed to create cashback rule", "error", err.Error())
+			WriteErrorResponse(w, http.StatusInternalServerError, err)
+		}
+		return
+	}
+
+	WriteSuccessResponse(w, CashbackRuleToResponse(rule))
+}
+
+// ListRules возвращает действующие правила кэшбэка
+func (h *CashbackHandler) ListRules(w http.ResponseWriter, r *http.Request) {
+	rules, err := h.cashbackService.ListRules(r.Context())
+	if err != nil {
+		h.logger.Error("Failed to list cashback rules", "error", err.Error())
+		WriteErrorResponse(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	responses := make([]*CashbackRuleResponse, 0, len(rules))
+	for _, rule := range rules {
+		responses = append(responses, CashbackRuleToResponse(rule))
+	}
+
+	WriteSuccessResponse(w, responses)
+}
+
+// DeleteRule отключает правило кэшбэка
+func (h *CashbackHandler) DeleteRule(w http.ResponseWriter, r *http.Request) {
+	ruleID, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		WriteErrorResponse(w, http.StatusBadRequest, fmt.Errorf("invalid rule ID"))
+		return
+	}
+
+	adminID, err := GetUserIDFromRequest(r)
+	if err != nil {
+		WriteErrorResponse(w, http.StatusUnauthorized, err)
+		return
+	}
+
+	if err := h.cashbackService.DeleteRule(r.Context(), adminID, ruleID); err != nil {
+		if errors.Is(err, domain.ErrCashbackRuleNotFound) {
+			WriteErrorResponse(w, http.StatusNotFound, err)
+			return
+		}
+		h.logger.Error("Failed to delete cashback rule", "rule_id", ruleID, "error", err.Error())
+		WriteErrorResponse(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	WriteSuccessResponse(w, map[string]string{"message": "Cashback rule deleted"})
+}
+
+// Conversion functions
+func CashbackRuleToResponse(rule *domain.CashbackRule) *CashbackRuleResponse {
+	return &CashbackRuleResponse{
+		ID:         strconv.Itoa(rule.ID),
+		MCC:        rule.MCC,
+		Category:   rule.Category,
+		Rate:       rule.Rate,
+		MonthlyCap: rule.MonthlyCap,
+		CreatedAt:  rule.CreatedAt,
+	}
+}
+
+func CardRewardsToResponse(rewards *service.CardRewards) *CardRewardsResponse {
+	response := &CardRewardsResponse{
+		CardID:       strconv.Itoa(rewards.CardID),
+		Period:       rewards.Period.Format("2006-01"),
+		PeriodAmount: rewards.PeriodAmount,
+		MonthlyCap:   rewards.MonthlyCap,
+		CapRemaining: rewards.CapRemaining,
+		Pending:      rewards.Pending,
+		Paid:         rewards.Paid,
+		Entries:      make([]*CashbackEntryResponse, 0, len(rewards.Entries)),
+		Payouts:      make([]*CashbackPayoutResponse, 0, len(rewards.Payouts)),
+	}
+
+	for _, entry := range rewards.Entries {
+		response.Entries = append(response.Entries, &CashbackEntryResponse{
+			TransactionID: strconv.Itoa(entry.TransactionID),
+			Kind:          entry.Kind,
+			Period:        entry.Period.Format("2006-01"),
+			BaseAmount:    entry.BaseAmount,
+			Rate:          entry.Rate,
+			Amount:        entry.Amount,
+			CreatedAt:     entry.CreatedAt,
+		})
+	}
+	for _, payout := range rewards.Payouts {
+		response.Payouts = append(response.Payouts, &CashbackPayoutResponse{
+			Period:    payout.Period.Format("2006-01"),
+			Amount:    payout.Amount,
+			AccountID: strconv.Itoa(payout.AccountID),
+			CreatedAt: payout.CreatedAt,
+		})
+	}
+
+	return response
+}
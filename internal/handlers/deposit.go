@@ -0,0 +1,309 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/vterdunov/learn-bank-app/internal/domain"
+	"github.com/vterdunov/learn-bank-app/internal/service"
+)
+
+// Deposit Request DTOs
+type OpenDepositRequest struct {
+	AccountID      string  `json:"account_id" validate:"required"`
+	Product        string  `json:"product" validate:"required"`
+	Amount         float64 `json:"amount" validate:"required,gt=0"`
+	TermMonths     int     `json:"term_months,omitempty"`
+	Capitalization string  `json:"capitalization,omitempty"`
+}
+
+// Deposit Response DTOs
+type DepositProductResponse struct {
+	Code            string   `json:"code"`
+	Name            string   `json:"name"`
+	Kind            string   `json:"kind"`
+	MinAmount       float64  `json:"min_amount"`
+	MinTermMonths   int      `json:"min_term_months,omitempty"`
+	MaxTermMonths   int      `json:"max_term_months,omitempty"`
+	Capitalizations []string `json:"capitalizations"`
+	KeyRate         float64  `json:"key_rate"`
+	InterestRate    float64  `json:"interest_rate"`
+}
+
+type DepositResponse struct {
+	ID              string     `json:"id"`
+	AccountID       string     `json:"account_id"`
+	Product         string     `json:"product"`
+	Kind            string     `json:"kind"`
+	Amount          float64    `json:"amount"`
+	Balance         float64    `json:"balance"`
+	InterestRate    float64    `json:"interest_rate"`
+	Capitalization  string     `json:"capitalization"`
+	TermMonths      int        `json:"term_months,omitempty"`
+	OpenedOn        string     `json:"opened_on"`
+	MaturityDate    string     `json:"maturity_date,omitempty"`
+	AccruedInterest float64    `json:"accrued_interest"`
+	InterestPaid    float64    `json:"interest_paid"`
+	Payout          *float64   `json:"payout,omitempty"`
+	Status          string     `json:"status"`
+	ClosedAt        *time.Time `json:"closed_at,omitempty"`
+	CreatedAt       time.Time  `json:"created_at"`
+}
+
+type DepositProjectionResponse struct {
+	DepositID         string  `json:"deposit_id"`
+	AsOf              string  `json:"as_of"`
+	PayoutDate        string  `json:"payout_date"`
+	Balance           float64 `json:"balance"`
+	ProjectedInterest float64 `json:"projected_interest"`
+	ProjectedPayout   float64 `json:"projected_payout"`
+	EarlyRate         float64 `json:"early_rate"`
+	EarlyPayout       float64 `json:"early_payout"`
+}
+
+// DepositHandler обрабатывает запросы по вкладам
+type DepositHandler struct {
+	depositService service.DepositService
+	logger         *slog.Logger
+}
+
+func NewDepositHandler(depositService service.DepositService, logger *slog.Logger) *DepositHandler {
+	return &DepositHandler{
+		depositService: depositService,
+		logger:         logger,
+	}
+}
+
+// ListProducts возвращает депозитные продукты с текущими ставками
+func (h *DepositHandler) ListProducts(w http.ResponseWriter, r *http.Request) {
+	offers, err := h.depositService.ListProducts(r.Context())
+	if err != nil {
+		h.logger.Error("Failed to list deposit products", "error", err.Error())
+		WriteErrorResponse(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	responses := make([]*DepositProductResponse, 0, len(offers))
+	for _, offer := range offers {
+		responses = append(responses, &DepositProductResponse{
+			Code:            offer.Product.Code,
+			Name:            offer.Product.Name,
+			Kind:            offer.Product.Kind,
+			MinAmount:       offer.Product.MinAmount,
+			MinTermMonths:   offer.Product.MinTermMonths,
+			MaxTermMonths:   offer.Product.MaxTermMonths,
+			Capitalizations: offer.Product.Capitalizations,
+			KeyRate:         offer.KeyRate,
+			InterestRate:    offer.InterestRate,
+		})
+	}
+
+	WriteSuccessResponse(w, responses)
+}
+
+// OpenDeposit открывает вклад
+func (h *DepositHandler) OpenDeposit(w http.ResponseWriter, r *http.Request) {
+	var req OpenDepositRequest
+
+	if err := ValidateJSON(r, &req); err != nil {
+		WriteErrorResponse(w, http.StatusBadRequest, err)
+		return
+	}
+
+	if validationErr := Validate(&req); validationErr != nil {
+		WriteErrorResponse(w, http.StatusBadRequest, validationErr)
+		return
+	}
+
+	accountID, err := strconv.Atoi(req.AccountID)
+	if err != nil {
+		WriteErrorResponse(w, http.StatusBadRequest, fmt.Errorf("invalid account_id"))
+		return
+	}
+
+	userID, err := GetUserIDFromRequest(r)
+	if err != nil {
+		WriteErrorResponse(w, http.StatusUnauthorized, err)
+		return
+	}
+
+	deposit, err := h.depositService.OpenDeposit(r.Context(), userID, domain.OpenDepositRequest{
+		AccountID:      accountID,
+		Product:        req.Product,
+		Amount:         req.Amount,
+		TermMonths:     req.TermMonths,
+		Capitalization: req.Capitalization,
+	})
+	if err != nil {
+		h.writeDepositError(w, "open", err)
+		return
+	}
+
+	h.logger.Info("Deposit opened", "deposit_id", deposit.ID, "account_id", accountID, "amount", req.Amount)
+
+	WriteSuccessResponse(w, DepositToResponse(deposit))
+}
+
+// GetUserDeposits возвращает вклады пользователя
+func (h *DepositHandler) GetUserDeposits(w http.ResponseWriter, r *http.Request) {
+	userID, err := GetUserIDFromRequest(r)
+	if err != nil {
+		WriteErrorResponse(w, http.StatusUnauthorized, err)
+		return
+	}
+
+	deposits, err := h.depositService.GetUserDeposits(r.Context(), userID)
+	if err != nil {
+		h.logger.Error("Failed to get user deposits", "user_id", userID, "error", err.Error())
+		WriteErrorResponse(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	responses := make([]*DepositResponse, 0, len(deposits))
+	for _, deposit := range deposits {
+		responses = append(responses, DepositToResponse(deposit))
+	}
+
+	WriteSuccessResponse(w, responses)
+}
+
+// GetDeposit возвращает вклад
+func (h *DepositHandler) GetDeposit(w http.ResponseWriter, r *http.Request) {
+	userID, depositID, ok := h.parseDepositRequest(w, r)
+	if !ok {
+		return
+	}
+
+	deposit, err := h.depositService.GetDeposit(r.Context(), userID, depositID)
+	if err != nil {
+		h.writeDepositError(w, "get", err)
+		return
+	}
+
+	WriteSuccessResponse(w, DepositToResponse(deposit))
+}
+
+// GetProjection возвращает прогноз выплаты по вкладу
+func (h *DepositHandler) GetProjection(w http.ResponseWriter, r *http.Request) {
+	userID, depositID, ok := h.parseDepositRequest(w, r)
+	if !ok {
+		return
+	}
+
+	var months int
+	if value := r.URL.Query().Get("months"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < 0 {
+			WriteErrorResponse(w, http.StatusBadRequest, fmt.Errorf("invalid months"))
+			return
+		}
+		months = parsed
+	}
+
+	projection, err := h.depositService.GetProjection(r.Context(), userID, depositID, months)
+	if err != nil {
+		h.writeDepositError(w, "project", err)
+		return
+	}
+
+	WriteSuccessResponse(w, &DepositProjectionResponse{
+		DepositID:         fmt.Sprintf("%d", depositID),
+		AsOf:              projection.AsOf.Format(time.DateOnly),
+		PayoutDate:        projection.PayoutDate.Format(time.DateOnly),
+		Balance:           projection.Balance,
+		ProjectedInterest: projection.ProjectedInterest,
+		ProjectedPayout:   projection.ProjectedPayout,
+		EarlyRate:         projection.EarlyRate,
+		EarlyPayout:       projection.EarlyPayout,
+	})
+}
+
+// CloseDeposit закрывает вклад с выплатой на счет
+func (h *DepositHandler) CloseDeposit(w http.ResponseWriter, r *http.Request) {
+	userID, depositID, ok := h.parseDepositRequest(w, r)
+	if !ok {
+		return
+	}
+
+	deposit, err := h.depositService.CloseDeposit(r.Context(), userID, depositID)
+	if err != nil {
+		h.writeDepositError(w, "close", err)
+		return
+	}
+
+	h.logger.Info("Deposit closed", "deposit_id", deposit.ID, "payout", *deposit.Payout)
+
+	WriteSuccessResponse(w, DepositToResponse(deposit))
+}
+
+// parseDepositRequest извлекает пользователя и ID вклада из запроса
+func (h *DepositHandler) parseDepositRequest(w http.ResponseWriter, r *http.Request) (userID, depositID int, ok bool) {
+	depositID, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		WriteErrorResponse(w, http.StatusBadRequest, fmt.Errorf("invalid deposit ID"))
+		return 0, 0, false
+	}
+
+	userID, err = GetUserIDFromRequest(r)
+	if err != nil {
+		WriteErrorResponse(w, http.StatusUnauthorized, err)
+		return 0, 0, false
+	}
+
+	return userID, depositID, true
+}
+
+// writeDepositError выбирает HTTP статус по ошибке сервиса вкладов
+func (h *DepositHandler) writeDepositError(w http.ResponseWriter, action string, err error) {
+	var serviceErr *service.ServiceError
+	switch {
+	case errors.As(err, &serviceErr):
+		WriteErrorResponse(w, serviceErr.Code, err)
+	case errors.Is(err, domain.ErrDepositNotFound):
+		WriteErrorResponse(w, http.StatusNotFound, err)
+	case errors.Is(err, domain.ErrDepositNotActive):
+		WriteErrorResponse(w, http.StatusConflict, err)
+	case errors.Is(err, domain.ErrDepositProductNotFound),
+		errors.Is(err, domain.ErrDepositAmountTooSmall),
+		errors.Is(err, domain.ErrInvalidDepositTerm),
+		errors.Is(err, domain.ErrInvalidDepositCapitalization),
+		errors.Is(err, domain.ErrInvalidAccountID),
+		errors.Is(err, service.ErrInvalidProjectionMonths),
+		errors.Is(err, service.ErrInsufficientFunds),
+		errors.Is(err, service.ErrAccountNotFound),
+		errors.Is(err, service.ErrAccountBlocked):
+		WriteErrorResponse(w, http.StatusBadRequest, err)
+	default:
+		h.logger.Error("Failed to "+action+" deposit", "error", err.Error())
+		WriteErrorResponse(w, http.StatusInternalServerError, err)
+	}
+}
+
+func DepositToResponse(deposit *domain.Deposit) *DepositResponse {
+	response := &DepositResponse{
+		ID:              fmt.Sprintf("%d", deposit.ID),
+		AccountID:       fmt.Sprintf("%d", deposit.AccountID),
+		Product:         deposit.Product,
+		Kind:            deposit.Kind,
+		Amount:          deposit.Amount,
+		Balance:         deposit.Balance,
+		InterestRate:    deposit.InterestRate,
+		Capitalization:  deposit.Capitalization,
+		TermMonths:      deposit.TermMonths,
+		OpenedOn:        deposit.OpenedOn.Format(time.DateOnly),
+		AccruedInterest: deposit.AccruedInterest,
+		InterestPaid:    deposit.InterestPaid,
+		Payout:          deposit.Payout,
+		Status:          deposit.Status,
+		ClosedAt:        deposit.ClosedAt,
+		CreatedAt:       deposit.CreatedAt,
+	}
+	if deposit.MaturityDate != nil {
+		response.MaturityDate = deposit.MaturityDate.Format(time.DateOnly)
+	}
+	return response
+}
@@ -0,0 +1,210 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/vterdunov/learn-bank-app/internal/domain"
+	"github.com/vterdunov/learn-bank-app/internal/service"
+)
+
+// Fraud Request DTOs
+type ResolveFraudReviewRequest struct {
+	Comment string `json:"comment"`
+}
+
+// Fraud Response DTOs
+type FraudReviewResponse struct {
+	ID            string                `json:"id"`
+	UserID        string                `json:"user_id"`
+	OperationType string                `json:"operation_type"`
+	AccountID     string                `json:"account_id"`
+	ToAccountID   *string               `json:"to_account_id,omitempty"`
+	CardID        *string               `json:"card_id,omitempty"`
+	MerchantID    string                `json:"merchant_id,omitempty"`
+	Amount        float64               `json:"amount"`
+	IP            string                `json:"ip,omitempty"`
+	Country       string                `json:"country,omitempty"`
+	Rules         []domain.FraudRuleHit `json:"rules"`
+	Status        string                `json:"status"`
+	ReviewerID    *string               `json:"reviewer_id,omitempty"`
+	Comment       string                `json:"comment,omitempty"`
+	ReviewedAt    *time.Time            `json:"reviewed_at,omitempty"`
+	ConsumedAt    *time.Time            `json:"consumed_at,omitempty"`
+	CreatedAt     time.Time             `json:"created_at"`
+}
+
+// FraudHandler обрабатывает запросы оператора к очереди проверки операций антифродом
+type FraudHandler struct {
+	fraudService service.FraudService
+	logger       *slog.Logger
+}
+
+func NewFraudHandler(fraudService service.FraudService, logger *slog.Logger) *FraudHandler {
+	return &FraudHandler{
+		fraudService: fraudService,
+		logger:       logger,
+	}
+}
+
+// ListReviews возвращает проверки по статусу (по умолчанию pending)
+func (h *FraudHandler) ListReviews(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	var limit, offset int
+	for name, target := range map[string]*int{"limit": &limit, "offset": &offset} {
+		if value := query.Get(name); value != "" {
+			parsed, err := strconv.Atoi(value)
+			if err != nil || parsed < 0 {
+				WriteErrorResponse(w, http.StatusBadRequest, fmt.Errorf("invalid %s", name))
+				return
+			}
+			*target = parsed
+		}
+	}
+
+	reviews, err := h.fraudService.ListReviews(r.Context(), query.Get("status"), limit, offset)
+	if err != nil {
+		if errors.Is(err, domain.ErrInvalidFraudReviewStatus) {
+			WriteErrorResponse(w, http.StatusBadRequest, err)
+			return
+		}
+		h.logger.Error("Failed to list fraud reviews", "error", err.Error())
+		WriteErrorResponse(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	responses := make([]*FraudReviewResponse, 0, len(reviews))
+	for _, review := range reviews {
+		responses = append(responses, FraudReviewToResponse(review))
+	}
+
+	WriteSuccessResponse(w, responses)
+}
+
+// ApproveReview одобряет отложенную операцию: клиент может повторить ее
+func (h *FraudHandler) ApproveReview(w http.ResponseWriter, r *http.Request) {
+	h.resolveReview(w, r, h.fraudService.ApproveReview)
+}
+
+// DeclineReview отклоняет отложенную операцию
+func (h *FraudHandler) DeclineReview(w http.ResponseWriter, r *http.Request) {
+	h.resolveReview(w, r, h.fraudService.DeclineReview)
+}
+
+func (h *FraudHandler) resolveReview(
+	w http.ResponseWriter,
+	r *http.Request,
+	resolve func(ctx context.Context, adminID, reviewID int, comment string) (*domain.FraudReview, error),
+) {
+	adminID, err := GetUserIDFromRequest(r)
+	if err != nil {
+		WriteErrorResponse(w, http.StatusUnauthorized, err)
+		return
+	}
+
+	reviewID, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		WriteErrorResponse(w, http.StatusBadRequest, fmt.Errorf("invalid review ID"))
+		return
+	}
+
+	var req ResolveFraudReviewRequest
+	if err := ValidateJSON(r, &req); err != nil {
+		WriteErrorResponse(w, http.StatusBadRequest, err)
+		return
+	}
+
+	if validationErr := Validate(&req); validationErr != nil {
+		WriteErrorResponse(w, http.StatusBadRequest, validationErr)
+		return
+	}
+
+	review, err := resolve(r.Context(), adminID, reviewID, req.Comment)
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrFraudReviewNotFound):
+			WriteErrorResponse(w, http.StatusNotFound, err)
+		case errors.Is(err, domain.ErrFraudReviewResolved):
+			WriteErrorResponse(w, http.StatusConflict, err)
+		default:
+			h.logger.Error("Failed to resolve fraud review", "review_id", reviewID, "error", err.Error())
+			WriteErrorResponse(w, http.StatusInternalServerError, err)
+		}
+		return
+	}
+
+	WriteSuccessResponse(w, FraudReviewToResponse(review))
+}
+
+// writeFraudReviewResponse отвечает 202 Accepted на операцию, отложенную до решения оператора:
+// операция не выполнена, после одобрения ее нужно повторить
+func writeFraudReviewResponse(w http.ResponseWriter, reviewErr *domain.FraudReviewError) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+
+	_ = json.NewEncoder(w).Encode(map[string]interface{}{
+		"success": false,
+		"error": map[string]string{
+			"code":      "operation_held_for_review",
+			"message":   domain.ErrOperationUnderReview.Error(),
+			"review_id": fmt.Sprintf("%d", reviewErr.ReviewID),
+		},
+	})
+}
+
+// writeScreeningError отвечает на операцию, отложенную или отклоненную антифродом, проверкой
+// получателя по санкционным спискам или лимитами по уровню идентификации.
+// Возвращает false, если ошибка не относится к этим проверкам.
+func writeScreeningError(w http.ResponseWriter, err error) bool {
+	var reviewErr *domain.FraudReviewError
+	switch {
+	case errors.As(err, &reviewErr):
+		writeFraudReviewResponse(w, reviewErr)
+	case errors.Is(err, domain.ErrOperationRejected), errors.Is(err, domain.ErrSanctionsMatch),
+		errors.Is(err, domain.ErrKYCLimitExceeded):
+		WriteErrorResponse(w, http.StatusForbidden, err)
+	case errors.Is(err, domain.ErrSanctionsReviewPending):
+		WriteErrorResponse(w, http.StatusConflict, err)
+	default:
+		return false
+	}
+	return true
+}
+
+func FraudReviewToResponse(review *domain.FraudReview) *FraudReviewResponse {
+	return &FraudReviewResponse{
+		ID:            fmt.Sprintf("%d", review.ID),
+		UserID:        fmt.Sprintf("%d", review.UserID),
+		OperationType: review.OperationType,
+		AccountID:     fmt.Sprintf("%d", review.AccountID),
+		ToAccountID:   optionalIDString(review.ToAccountID),
+		CardID:        optionalIDString(review.CardID),
+		MerchantID:    review.MerchantID,
+		Amount:        review.Amount,
+		IP:            review.IP,
+		Country:       review.Country,
+		Rules:         review.Rules,
+		Status:        review.Status,
+		ReviewerID:    optionalIDString(review.ReviewerID),
+		Comment:       review.Comment,
+		ReviewedAt:    review.ReviewedAt,
+		ConsumedAt:    review.ConsumedAt,
+		CreatedAt:     review.CreatedAt,
+	}
+}
+
+// optionalIDString форматирует необязательный ID для ответа
+func optionalIDString(id *int) *string {
+	if id == nil {
+		return nil
+	}
+	value := fmt.Sprintf("%d", *id)
+	return &value
+}
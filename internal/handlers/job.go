@@ -0,0 +1,179 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/vterdunov/learn-bank-app/internal/domain"
+	"github.com/vterdunov/learn-bank-app/internal/service"
+)
+
+// Job Response DTOs
+type JobRunResponse struct {
+	ID         string     `json:"id"`
+	JobName    string     `json:"job_name"`
+	Trigger    string     `json:"trigger"`
+	InstanceID string     `json:"instance_id"`
+	Status     string     `json:"status"`
+	StartedAt  time.Time  `json:"started_at"`
+	FinishedAt *time.Time `json:"finished_at,omitempty"`
+	Processed  int        `json:"processed"`
+	Failed     int        `json:"failed"`
+	Error      string     `json:"error,omitempty"`
+}
+
+type JobResponse struct {
+	Name             string          `json:"name"`
+	Schedule         string          `json:"schedule"`
+	Paused           bool            `json:"paused"`
+	NextRunAt        time.Time       `json:"next_run_at"`
+	TriggerRequested bool            `json:"trigger_requested"`
+	LastRun          *JobRunResponse `json:"last_run,omitempty"`
+}
+
+type JobListResponse struct {
+	// Leader выполняет ли задачи реплика, ответившая на запрос
+	Leader bool           `json:"leader"`
+	Jobs   []*JobResponse `json:"jobs"`
+}
+
+// JobHandler обрабатывает запросы администратора к фоновым задачам
+type JobHandler struct {
+	jobRunner service.JobRunner
+	logger    *slog.Logger
+}
+
+func NewJobHandler(jobRunner service.JobRunner, logger *slog.Logger) *JobHandler {
+	return &JobHandler{
+		jobRunner: jobRunner,
+		logger:    logger,
+	}
+}
+
+// ListJobs возвращает задачи с расписанием и последним запуском
+func (h *JobHandler) ListJobs(w http.ResponseWriter, r *http.Request) {
+	statuses, err := h.jobRunner.ListJobs(r.Context())
+	if err != nil {
+		h.logger.Error("Failed to list jobs", "error", err.Error())
+		WriteErrorResponse(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	jobs := make([]*JobResponse, 0, len(statuses))
+	for _, status := range statuses {
+		response := &JobResponse{
+			Name:             status.Job.Name,
+			Schedule:         status.Job.Schedule,
+			Paused:           status.Job.Paused,
+			NextRunAt:        status.Job.NextRunAt,
+			TriggerRequested: status.Job.TriggerRequestedAt != nil,
+		}
+		if status.LastRun != nil {
+			response.LastRun = JobRunToResponse(status.LastRun)
+		}
+		jobs = append(jobs, response)
+	}
+
+	WriteSuccessResponse(w, &JobListResponse{
+		Leader: h.jobRunner.IsLeader(),
+		Jobs:   jobs,
+	})
+}
+
+// ListRuns возвращает историю запусков задачи
+func (h *JobHandler) ListRuns(w http.ResponseWriter, r *http.Request) {
+	name := r.PathValue("name")
+	query := r.URL.Query()
+
+	var limit, offset int
+	for param, target := range map[string]*int{"limit": &limit, "offset": &offset} {
+		if value := query.Get(param); value != "" {
+			parsed, err := strconv.Atoi(value)
+			if err != nil || parsed < 0 {
+				WriteErrorResponse(w, http.StatusBadRequest, fmt.Errorf("invalid %s", param))
+				return
+			}
+			*target = parsed
+		}
+	}
+
+	runs, err := h.jobRunner.ListRuns(r.Context(), name, limit, offset)
+	if err != nil {
+		if errors.Is(err, domain.ErrJobNotFound) {
+			WriteErrorResponse(w, http.StatusNotFound, err)
+			return
+		}
+		h.logger.Error("Failed to list job runs", "job", name, "error", err.Error())
+		WriteErrorResponse(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	responses := make([]*JobRunResponse, 0, len(runs))
+	for _, run := range runs {
+		responses = append(responses, JobRunToResponse(run))
+	}
+
+	WriteSuccessResponse(w, responses)
+}
+
+// TriggerJob запрашивает внеочередной запуск задачи
+func (h *JobHandler) TriggerJob(w http.ResponseWriter, r *http.Request) {
+	h.adminAction(w, r, "trigger", h.jobRunner.Trigger)
+}
+
+// PauseJob ставит задачу на паузу
+func (h *JobHandler) PauseJob(w http.ResponseWriter, r *http.Request) {
+	h.adminAction(w, r, "pause", h.jobRunner.Pause)
+}
+
+// ResumeJob снимает задачу с паузы
+func (h *JobHandler) ResumeJob(w http.ResponseWriter, r *http.Request) {
+	h.adminAction(w, r, "resume", h.jobRunner.Resume)
+}
+
+// adminAction выполняет действие администратора над задачей из пути запроса
+func (h *JobHandler) adminAction(
+	w http.ResponseWriter,
+	r *http.Request,
+	action string,
+	fn func(ctx context.Context, adminID int, name string) error,
+) {
+	adminID, err := GetUserIDFromRequest(r)
+	if err != nil {
+		WriteErrorResponse(w, http.StatusUnauthorized, err)
+		return
+	}
+
+	name := r.PathValue("name")
+	if err := fn(r.Context(), adminID, name); err != nil {
+		if errors.Is(err, domain.ErrJobNotFound) {
+			WriteErrorResponse(w, http.StatusNotFound, err)
+			return
+		}
+		h.logger.Error("Failed to "+action+" job", "job", name, "error", err.Error())
+		WriteErrorResponse(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	WriteSuccessResponse(w, map[string]string{"job": name, "action": action})
+}
+
+func JobRunToResponse(run *domain.JobRun) *JobRunResponse {
+	return &JobRunResponse{
+		ID:         fmt.Sprintf("%d", run.ID),
+		JobName:    run.JobName,
+		Trigger:    run.Trigger,
+		InstanceID: run.InstanceID,
+		Status:     run.Status,
+		StartedAt:  run.StartedAt,
+		FinishedAt: run.FinishedAt,
+		Processed:  run.Processed,
+		Failed:     run.Failed,
+		Error:      run.Error,
+	}
+}
@@ -0,0 +1,34 @@
+package handlers
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+
+	"github.com/vterdunov/learn-bank-app/internal/utils"
+)
+
+// JWKSHandler отдает публичные ключи для проверки JWT
+type JWKSHandler struct {
+	keys   *utils.KeyRing
+	logger *slog.Logger
+}
+
+func NewJWKSHandler(keys *utils.KeyRing, logger *slog.Logger) *JWKSHandler {
+	return &JWKSHandler{
+		keys:   keys,
+		logger: logger,
+	}
+}
+
+// GetJWKS возвращает набор публичных ключей в формате JWKS (RFC 7517).
+// Ответ без обертки success/data, так как формат фиксирован стандартом.
+func (h *JWKSHandler) GetJWKS(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "public, max-age=300")
+	w.WriteHeader(http.StatusOK)
+
+	if err := json.NewEncoder(w).Encode(h.keys.JWKS()); err != nil {
+		h.logger.Error("Failed to encode JWKS", "error", err.Error())
+	}
+}
@@ -0,0 +1,479 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"mime"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/vterdunov/learn-bank-app/internal/domain"
+	"github.com/vterdunov/learn-bank-app/internal/service"
+	"github.com/vterdunov/learn-bank-app/internal/utils"
+)
+
+// KYC Request DTOs
+type UpdateKYCProfileRequest struct {
+	LastName             string `json:"last_name"`
+	FirstName            string `json:"first_name"`
+	MiddleName           string `json:"middle_name,omitempty"`
+	BirthDate            string `json:"birth_date"` // YYYY-MM-DD
+	BirthPlace           string `json:"birth_place,omitempty"`
+	PassportSeries       string `json:"passport_series"`
+	PassportNumber       string `json:"passport_number"`
+	PassportIssuedBy     string `json:"passport_issued_by"`
+	PassportIssueDate    string `json:"passport_issue_date"`    // YYYY-MM-DD
+	PassportDivisionCode string `json:"passport_division_code"` // XXX-XXX
+	INN                  string `json:"inn,omitempty"`
+	SNILS                string `json:"snils,omitempty"` // XXX-XXX-XXX YY или 11 цифр
+	Address              string `json:"address"`
+	Phone                string `json:"phone"`
+}
+
+type VerifyKYCProfileRequest struct {
+	Level string `json:"level"` // simplified или full
+}
+
+type RejectKYCProfileRequest struct {
+	Reason string `json:"reason"`
+}
+
+// KYC Response DTOs
+type KYCProfileResponse struct {
+	UserID               string     `json:"user_id"`
+	LastName             string     `json:"last_name"`
+	FirstName            string     `json:"first_name"`
+	MiddleName           string     `json:"middle_name,omitempty"`
+	BirthDate            string     `json:"birth_date,omitempty"`
+	BirthPlace           string     `json:"birth_place,omitempty"`
+	PassportSeries       string     `json:"passport_series"`
+	PassportNumber       string     `json:"passport_number"`
+	PassportIssuedBy     string     `json:"passport_issued_by"`
+	PassportIssueDate    string     `json:"passport_issue_date,omitempty"`
+	PassportDivisionCode string     `json:"passport_division_code"`
+	INN                  string     `json:"inn,omitempty"`
+	SNILS                string     `json:"snils,omitempty"`
+	Address              string     `json:"address"`
+	Phone                string     `json:"phone"`
+	Status               string     `json:"status"`
+	Level                string     `json:"level"`
+	RejectionReason      string     `json:"rejection_reason,omitempty"`
+	MissingFields        []string   `json:"missing_fields,omitempty"`
+	ReviewerID           *string    `json:"reviewer_id,omitempty"`
+	SubmittedAt          *time.Time `json:"submitted_at,omitempty"`
+	ReviewedAt           *time.Time `json:"reviewed_at,omitempty"`
+}
+
+type KYCDocumentResponse struct {
+	ID          string    `json:"id"`
+	Type        string    `json:"type"`
+	FileName    string    `json:"file_name"`
+	ContentType string    `json:"content_type"`
+	Size        int64     `json:"size"`
+	SHA256      string    `json:"sha256"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// KYCHandler обрабатывает запросы клиента по идентификации и запросы оператора к очереди анкет
+type KYCHandler struct {
+	kycService service.KYCService
+	logger     *slog.Logger
+}
+
+func NewKYCHandler(kycService service.KYCService, logger *slog.Logger) *KYCHandler {
+	return &KYCHandler{
+		kycService: kycService,
+		logger:     logger,
+	}
+}
+
+// GetProfile возвращает анкету текущего пользователя
+func (h *KYCHandler) GetProfile(w http.ResponseWriter, r *http.Request) {
+	userID, err := GetUserIDFromRequest(r)
+	if err != nil {
+		WriteErrorResponse(w, http.StatusUnauthorized, err)
+		return
+	}
+
+	profile, err := h.kycService.GetProfile(r.Context(), userID)
+	if err != nil {
+		writeKYCError(w, h.logger, "get KYC profile", err)
+		return
+	}
+
+	WriteSuccessResponse(w, KYCProfileToResponse(profile))
+}
+
+// UpdateProfile сохраняет анкету текущего пользователя
+func (h *KYCHandler) UpdateProfile(w http.ResponseWriter, r *http.Request) {
+	userID, err := GetUserIDFromRequest(r)
+	if err != nil {
+		WriteErrorResponse(w, http.StatusUnauthorized, err)
+		return
+	}
+
+	var req UpdateKYCProfileRequest
+	if err := ValidateJSON(r, &req); err != nil {
+		WriteErrorResponse(w, http.StatusBadRequest, err)
+		return
+	}
+
+	if validationErr := Validate(&req); validationErr != nil {
+		WriteErrorResponse(w, http.StatusBadRequest, validationErr)
+		return
+	}
+
+	// Формат дат проверен при валидации
+	birthDate, _ := time.Parse(time.DateOnly, req.BirthDate)
+	issueDate, _ := time.Parse(time.DateOnly, req.PassportIssueDate)
+
+	profile, err := h.kycService.UpdateProfile(r.Context(), userID, service.UpdateKYCProfileRequest{
+		LastName:             req.LastName,
+		FirstName:            req.FirstName,
+		MiddleName:           req.MiddleName,
+		BirthDate:            birthDate,
+		BirthPlace:           req.BirthPlace,
+		PassportSeries:       req.PassportSeries,
+		PassportNumber:       req.PassportNumber,
+		PassportIssuedBy:     req.PassportIssuedBy,
+		PassportIssueDate:    issueDate,
+		PassportDivisionCode: req.PassportDivisionCode,
+		INN:                  req.INN,
+		SNILS:                utils.DigitsOnly(req.SNILS),
+		Address:              req.Address,
+		Phone:                req.Phone,
+	})
+	if err != nil {
+		writeKYCError(w, h.logger, "update KYC profile", err)
+		return
+	}
+
+	h.logger.Info("KYC profile updated", "user_id", userID)
+
+	WriteSuccessResponse(w, KYCProfileToResponse(profile))
+}
+
+// UploadDocument принимает скан документа в multipart/form-data: поле type должно идти перед полем file
+func (h *KYCHandler) UploadDocument(w http.ResponseWriter, r *http.Request) {
+	userID, err := GetUserIDFromRequest(r)
+	if err != nil {
+		WriteErrorResponse(w, http.StatusUnauthorized, err)
+		return
+	}
+
+	// Файл читается потоком: размер ограничивает сервис, форма целиком в память не загружается
+	reader, err := r.MultipartReader()
+	if err != nil {
+		WriteErrorResponse(w, http.StatusBadRequest, fmt.Errorf("multipart/form-data body is required"))
+		return
+	}
+
+	var docType string
+	for {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			WriteErrorResponse(w, http.StatusBadRequest, fmt.Errorf("file is required"))
+			return
+		}
+		if err != nil {
+			WriteErrorResponse(w, http.StatusBadRequest, fmt.Errorf("invalid multipart body: %w", err))
+			return
+		}
+
+		switch part.FormName() {
+		case "type":
+			value, err := io.ReadAll(io.LimitReader(part, 64))
+			if err != nil {
+				WriteErrorResponse(w, http.StatusBadRequest, fmt.Errorf("invalid type"))
+				return
+			}
+			docType = string(value)
+		case "file":
+			if docType == "" {
+				WriteErrorResponse(w, http.StatusBadRequest, fmt.Errorf("type must precede file"))
+				return
+			}
+
+			doc, err := h.kycService.UploadDocument(r.Context(), userID, docType, part.FileName(), part)
+			if err != nil {
+				writeKYCError(w, h.logger, "upload KYC document", err)
+				return
+			}
+
+			h.logger.Info("KYC document uploaded", "user_id", userID, "document_id", doc.ID, "type", doc.Type)
+
+			WriteSuccessResponse(w, KYCDocumentToResponse(doc))
+			return
+		}
+	}
+}
+
+// ListDocuments возвращает документы текущего пользователя
+func (h *KYCHandler) ListDocuments(w http.ResponseWriter, r *http.Request) {
+	userID, err := GetUserIDFromRequest(r)
+	if err != nil {
+		WriteErrorResponse(w, http.StatusUnauthorized, err)
+		return
+	}
+
+	docs, err := h.kycService.ListDocuments(r.Context(), userID)
+	if err != nil {
+		writeKYCError(w, h.logger, "list KYC documents", err)
+		return
+	}
+
+	responses := make([]*KYCDocumentResponse, 0, len(docs))
+	for _, doc := range docs {
+		responses = append(responses, KYCDocumentToResponse(doc))
+	}
+
+	WriteSuccessResponse(w, responses)
+}
+
+// Submit отправляет анкету текущего пользователя на проверку
+func (h *KYCHandler) Submit(w http.ResponseWriter, r *http.Request) {
+	userID, err := GetUserIDFromRequest(r)
+	if err != nil {
+		WriteErrorResponse(w, http.StatusUnauthorized, err)
+		return
+	}
+
+	profile, err := h.kycService.Submit(r.Context(), userID)
+	if err != nil {
+		writeKYCError(w, h.logger, "submit KYC profile", err)
+		return
+	}
+
+	h.logger.Info("KYC profile submitted", "user_id", userID)
+
+	WriteSuccessResponse(w, KYCProfileToResponse(profile))
+}
+
+// GetLimits возвращает лимиты операций текущего пользователя по уровню идентификации
+func (h *KYCHandler) GetLimits(w http.ResponseWriter, r *http.Request) {
+	userID, err := GetUserIDFromRequest(r)
+	if err != nil {
+		WriteErrorResponse(w, http.StatusUnauthorized, err)
+		return
+	}
+
+	limits, err := h.kycService.GetLimits(r.Context(), userID)
+	if err != nil {
+		writeKYCError(w, h.logger, "get KYC limits", err)
+		return
+	}
+
+	WriteSuccessResponse(w, limits)
+}
+
+// ListProfiles возвращает анкеты по статусу (по умолчанию pending)
+func (h *KYCHandler) ListProfiles(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	var limit, offset int
+	for name, target := range map[string]*int{"limit": &limit, "offset": &offset} {
+		if value := query.Get(name); value != "" {
+			parsed, err := strconv.Atoi(value)
+			if err != nil || parsed < 0 {
+				WriteErrorResponse(w, http.StatusBadRequest, fmt.Errorf("invalid %s", name))
+				return
+			}
+			*target = parsed
+		}
+	}
+
+	profiles, err := h.kycService.ListProfiles(r.Context(), query.Get("status"), limit, offset)
+	if err != nil {
+		writeKYCError(w, h.logger, "list KYC profiles", err)
+		return
+	}
+
+	responses := make([]*KYCProfileResponse, 0, len(profiles))
+	for _, profile := range profiles {
+		responses = append(responses, KYCProfileToResponse(profile))
+	}
+
+	WriteSuccessResponse(w, responses)
+}
+
+// VerifyProfile подтверждает анкету клиента с уровнем simplified или full
+func (h *KYCHandler) VerifyProfile(w http.ResponseWriter, r *http.Request) {
+	adminID, err := GetUserIDFromRequest(r)
+	if err != nil {
+		WriteErrorResponse(w, http.StatusUnauthorized, err)
+		return
+	}
+
+	userID, err := strconv.Atoi(r.PathValue("userId"))
+	if err != nil {
+		WriteErrorResponse(w, http.StatusBadRequest, fmt.Errorf("invalid user ID"))
+		return
+	}
+
+	var req VerifyKYCProfileRequest
+	if err := ValidateJSON(r, &req); err != nil {
+		WriteErrorResponse(w, http.StatusBadRequest, err)
+		return
+	}
+
+	if validationErr := Validate(&req); validationErr != nil {
+		WriteErrorResponse(w, http.StatusBadRequest, validationErr)
+		return
+	}
+
+	profile, err := h.kycService.VerifyProfile(r.Context(), adminID, userID, req.Level)
+	if err != nil {
+		writeKYCError(w, h.logger, "verify KYC profile", err)
+		return
+	}
+
+	WriteSuccessResponse(w, KYCProfileToResponse(profile))
+}
+
+// RejectProfile отклоняет анкету клиента с причиной
+func (h *KYCHandler) RejectProfile(w http.ResponseWriter, r *http.Request) {
+	adminID, err := GetUserIDFromRequest(r)
+	if err != nil {
+		WriteErrorResponse(w, http.StatusUnauthorized, err)
+		return
+	}
+
+	userID, err := strconv.Atoi(r.PathValue("userId"))
+	if err != nil {
+		WriteErrorResponse(w, http.StatusBadRequest, fmt.Errorf("invalid user ID"))
+		return
+	}
+
+	var req RejectKYCProfileRequest
+	if err := ValidateJSON(r, &req); err != nil {
+		WriteErrorResponse(w, http.StatusBadRequest, err)
+		return
+	}
+
+	if validationErr := Validate(&req); validationErr != nil {
+		WriteErrorResponse(w, http.StatusBadRequest, validationErr)
+		return
+	}
+
+	profile, err := h.kycService.RejectProfile(r.Context(), adminID, userID, req.Reason)
+	if err != nil {
+		writeKYCError(w, h.logger, "reject KYC profile", err)
+		return
+	}
+
+	WriteSuccessResponse(w, KYCProfileToResponse(profile))
+}
+
+// GetDocumentFile отдает оператору файл документа
+func (h *KYCHandler) GetDocumentFile(w http.ResponseWriter, r *http.Request) {
+	adminID, err := GetUserIDFromRequest(r)
+	if err != nil {
+		WriteErrorResponse(w, http.StatusUnauthorized, err)
+		return
+	}
+
+	documentID, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		WriteErrorResponse(w, http.StatusBadRequest, fmt.Errorf("invalid document ID"))
+		return
+	}
+
+	doc, file, err := h.kycService.OpenDocument(r.Context(), adminID, documentID)
+	if err != nil {
+		writeKYCError(w, h.logger, "open KYC document", err)
+		return
+	}
+	defer file.Close()
+
+	w.Header().Set("Content-Type", doc.ContentType)
+	w.Header().Set("Content-Length", strconv.FormatInt(doc.Size, 10))
+	w.Header().Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": doc.FileName}))
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(http.StatusOK)
+
+	if _, err := io.Copy(w, file); err != nil {
+		h.logger.Warn("Failed to send KYC document", "document_id", documentID, "error", err)
+	}
+}
+
+// writeKYCError отвечает на ошибку идентификации с подходящим HTTP статусом
+func writeKYCError(w http.ResponseWriter, logger *slog.Logger, action string, err error) {
+	switch {
+	case errors.Is(err, domain.ErrKYCProfileNotFound),
+		errors.Is(err, domain.ErrKYCDocumentNotFound):
+		WriteErrorResponse(w, http.StatusNotFound, err)
+	case errors.Is(err, domain.ErrKYCUnderReview),
+		errors.Is(err, domain.ErrKYCNotPending),
+		errors.Is(err, domain.ErrKYCAlreadyVerified),
+		errors.Is(err, domain.ErrKYCIdentityInUse),
+		errors.Is(err, domain.ErrKYCPassportDocMissing):
+		WriteErrorResponse(w, http.StatusConflict, err)
+	case errors.Is(err, domain.ErrKYCDocumentTooLarge):
+		WriteErrorResponse(w, http.StatusRequestEntityTooLarge, err)
+	case errors.Is(err, domain.ErrKYCDocumentUnsupported):
+		WriteErrorResponse(w, http.StatusUnsupportedMediaType, err)
+	case errors.Is(err, domain.ErrKYCProfileIncomplete),
+		errors.Is(err, domain.ErrInvalidKYCDates),
+		errors.Is(err, domain.ErrInvalidKYCStatus),
+		errors.Is(err, domain.ErrInvalidKYCLevel),
+		errors.Is(err, domain.ErrInvalidKYCDocumentType),
+		errors.Is(err, domain.ErrKYCRejectionReasonEmpty),
+		errors.Is(err, utils.ErrInvalidPhone),
+		errors.Is(err, utils.ErrEmptyField):
+		WriteErrorResponse(w, http.StatusBadRequest, err)
+	default:
+		logger.Error("Failed to "+action, "error", err.Error())
+		WriteErrorResponse(w, http.StatusInternalServerError, err)
+	}
+}
+
+func KYCProfileToResponse(profile *domain.KYCProfile) *KYCProfileResponse {
+	return &KYCProfileResponse{
+		UserID:               fmt.Sprintf("%d", profile.UserID),
+		LastName:             profile.LastName,
+		FirstName:            profile.FirstName,
+		MiddleName:           profile.MiddleName,
+		BirthDate:            optionalDateString(profile.BirthDate),
+		BirthPlace:           profile.BirthPlace,
+		PassportSeries:       profile.PassportSeries,
+		PassportNumber:       profile.PassportNumber,
+		PassportIssuedBy:     profile.PassportIssuedBy,
+		PassportIssueDate:    optionalDateString(profile.PassportIssueDate),
+		PassportDivisionCode: profile.PassportDivisionCode,
+		INN:                  profile.INN,
+		SNILS:                utils.FormatSNILS(profile.SNILS),
+		Address:              profile.Address,
+		Phone:                profile.Phone,
+		Status:               profile.Status,
+		Level:                profile.Level,
+		RejectionReason:      profile.RejectionReason,
+		MissingFields:        profile.MissingFields(),
+		ReviewerID:           optionalIDString(profile.ReviewerID),
+		SubmittedAt:          profile.SubmittedAt,
+		ReviewedAt:           profile.ReviewedAt,
+	}
+}
+
+func KYCDocumentToResponse(doc *domain.KYCDocument) *KYCDocumentResponse {
+	return &KYCDocumentResponse{
+		ID:          fmt.Sprintf("%d", doc.ID),
+		Type:        doc.Type,
+		FileName:    doc.FileName,
+		ContentType: doc.ContentType,
+		Size:        doc.Size,
+		SHA256:      doc.SHA256,
+		CreatedAt:   doc.CreatedAt,
+	}
+}
+
+// optionalDateString форматирует необязательную дату как YYYY-MM-DD
+func optionalDateString(date *time.Time) string {
+	if date == nil {
+		return ""
+	}
+	return date.Format(time.DateOnly)
+}
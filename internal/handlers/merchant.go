@@ -0,0 +1,235 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/vterdunov/learn-bank-app/internal/domain"
+	"github.com/vterdunov/learn-bank-app/internal/service"
+)
+
+// Merchant Request DTOs
+type CreateMerchantRequest struct {
+	Code                string `json:"code" validate:"required,max=100"`
+	Name                string `json:"name" validate:"required,max=255"`
+	MCC                 string `json:"mcc" validate:"required,len=4,numeric"`
+	SettlementAccountID string `json:"settlement_account_id" validate:"required"`
+}
+
+type AddTerminalRequest struct {
+	TerminalID string `json:"terminal_id" validate:"required,max=8"`
+}
+
+// Merchant Response DTOs
+type MerchantResponse struct {
+	ID                  string    `json:"id"`
+	Code                string    `json:"code"`
+	Name                string    `json:"name"`
+	MCC                 string    `json:"mcc"`
+	Category            string    `json:"category"`
+	SettlementAccountID string    `json:"settlement_account_id"`
+	CreatedAt           time.Time `json:"created_at"`
+}
+
+type MerchantSettlementResponse struct {
+	BusinessDate  string  `json:"business_date"`
+	PaymentsCount int     `json:"payments_count"`
+	GrossAmount   float64 `json:"gross_amount"`
+	RefundAmount  float64 `json:"refund_amount"`
+	FeeAmount     float64 `json:"fee_amount"`
+	NetAmount     float64 `json:"net_amount"`
+}
+
+// MerchantHandler обрабатывает запросы администратора к ТСП
+type MerchantHandler struct {
+	merchantService service.MerchantService
+	logger          *slog.Logger
+}
+
+func NewMerchantHandler(merchantService service.MerchantService, logger *slog.Logger) *MerchantHandler {
+	return &MerchantHandler{
+		merchantService: merchantService,
+		logger:          logger,
+	}
+}
+
+// CreateMerchant регистрирует ТСП
+func (h *MerchantHandler) CreateMerchant(w http.ResponseWriter, r *http.Request) {
+	var req CreateMerchantRequest
+
+	if err := ValidateJSON(r, &req); err != nil {
+		WriteErrorResponse(w, http.StatusBadRequest, err)
+		return
+	}
+
+	if validationErr := Validate(&req); validationErr != nil {
+		WriteErrorResponse(w, http.StatusBadRequest, validationErr)
+		return
+	}
+
+	accountID, err := strconv.Atoi(req.SettlementAccountID)
+	if err != nil {
+		WriteErrorResponse(w, http.StatusBadRequest, fmt.Errorf("invalid settlement_account_id"))
+		return
+	}
+
+	adminID, err := GetUserIDFromRequest(r)
+	if err != nil {
+		WriteErrorResponse(w, http.StatusUnauthorized, err)
+		return
+	}
+
+	merchant, err := h.merchantService.CreateMerchant(r.Context(), adminID, domain.CreateMerchantRequest{
+		Code:                req.Code,
+		Name:                req.Name,
+		MCC:                 req.MCC,
+		SettlementAccountID: accountID,
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrMerchantCodeTaken):
+			WriteErrorResponse(w, http.StatusConflict, err)
+		case errors.Is(err, service.ErrAccountNotFound):
+			WriteErrorResponse(w, http.StatusNotFound, err)
+		case errors.Is(err, domain.ErrInvalidMerchantCode),
+			errors.Is(err, domain.ErrInvalidMerchantName),
+			errors.Is(err, domain.ErrInvalidMCC),
+			errors.Is(err, service.ErrAccountBlocked):
+			WriteErrorResponse(w, http.StatusBadRequest, err)
+		default:
+			h.logger.Error("Failed to create merchant", "error", err.Error())
+			WriteErrorResponse(w, http.StatusInternalServerError, err)
+		}
+		return
+	}
+
+	WriteSuccessResponse(w, MerchantToResponse(merchant))
+}
+
+// AddTerminal регистрирует терминал ТСП
+func (h *MerchantHandler) AddTerminal(w http.ResponseWriter, r *http.Request) {
+	merchantID, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		WriteErrorResponse(w, http.StatusBadRequest, fmt.Errorf("invalid merchant ID"))
+		return
+	}
+
+	var req AddTerminalRequest
+	if err := ValidateJSON(r, &req); err != nil {
+		WriteErrorResponse(w, http.StatusBadRequest, err)
+		return
+	}
+
+	if validationErr := Validate(&req); validationErr != nil {
+		WriteErrorResponse(w, http.StatusBadRequest, validationErr)
+		return
+	}
+
+	adminID, err := GetUserIDFromRequest(r)
+	if err != nil {
+		WriteErrorResponse(w, http.StatusUnauthorized, err)
+		return
+	}
+
+	if err := h.merchantService.AddTerminal(r.Context(), adminID, merchantID, req.TerminalID); err != nil {
+		switch {
+		case errors.Is(err, domain.ErrMerchantNotFound):
+			WriteErrorResponse(w, http.StatusNotFound, err)
+		case errors.Is(err, domain.ErrTerminalTaken):
+			WriteErrorResponse(w, http.StatusConflict, err)
+		case errors.Is(err, domain.ErrInvalidTerminalID):
+			WriteErrorResponse(w, http.StatusBadRequest, err)
+		default:
+			h.logger.Error("Failed to add merchant terminal", "merchant_id", merchantID, "error", err.Error())
+			WriteErrorResponse(w, http.StatusInternalServerError, err)
+		}
+		return
+	}
+
+	WriteSuccessResponse(w, map[string]string{
+		"merchant_id": strconv.Itoa(merchantID),
+		"terminal_id": req.TerminalID,
+	})
+}
+
+// ListMerchants возвращает зарегистрированные ТСП
+func (h *MerchantHandler) ListMerchants(w http.ResponseWriter, r *http.Request) {
+	merchants, err := h.merchantService.ListMerchants(r.Context())
+	if err != nil {
+		h.logger.Error("Failed to list merchants", "error", err.Error())
+		WriteErrorResponse(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	responses := make([]*MerchantResponse, 0, len(merchants))
+	for _, merchant := range merchants {
+		responses = append(responses, MerchantToResponse(merchant))
+	}
+
+	WriteSuccessResponse(w, responses)
+}
+
+// GetSettlements возвращает дневные расчеты с ТСП за период (?from=ГГГГ-ММ-ДД&to=ГГГГ-ММ-ДД, по умолчанию последние 30 дней)
+func (h *MerchantHandler) GetSettlements(w http.ResponseWriter, r *http.Request) {
+	merchantID, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		WriteErrorResponse(w, http.StatusBadRequest, fmt.Errorf("invalid merchant ID"))
+		return
+	}
+
+	to := time.Now().UTC()
+	from := to.AddDate(0, 0, -30)
+	query := r.URL.Query()
+	for param, target := range map[string]*time.Time{"from": &from, "to": &to} {
+		if value := query.Get(param); value != "" {
+			parsed, err := time.Parse(time.DateOnly, value)
+			if err != nil {
+				WriteErrorResponse(w, http.StatusBadRequest, fmt.Errorf("invalid %s", param))
+				return
+			}
+			*target = parsed
+		}
+	}
+
+	settlements, err := h.merchantService.GetSettlements(r.Context(), merchantID, from, to)
+	if err != nil {
+		if errors.Is(err, domain.ErrMerchantNotFound) {
+			WriteErrorResponse(w, http.StatusNotFound, err)
+			return
+		}
+		h.logger.Error("Failed to get merchant settlements", "merchant_id", merchantID, "error", err.Error())
+		WriteErrorResponse(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	responses := make([]*MerchantSettlementResponse, 0, len(settlements))
+	for _, settlement := range settlements {
+		responses = append(responses, &MerchantSettlementResponse{
+			BusinessDate:  settlement.BusinessDate.Format(time.DateOnly),
+			PaymentsCount: settlement.PaymentsCount,
+			GrossAmount:   settlement.GrossAmount,
+			RefundAmount:  settlement.RefundAmount,
+			FeeAmount:     settlement.FeeAmount,
+			NetAmount:     settlement.NetAmount,
+		})
+	}
+
+	WriteSuccessResponse(w, responses)
+}
+
+// Conversion functions
+func MerchantToResponse(merchant *domain.Merchant) *MerchantResponse {
+	return &MerchantResponse{
+		ID:                  strconv.Itoa(merchant.ID),
+		Code:                merchant.Code,
+		Name:                merchant.Name,
+		MCC:                 merchant.MCC,
+		Category:            domain.MCCCategory(merchant.MCC),
+		SettlementAccountID: strconv.Itoa(merchant.SettlementAccountID),
+		CreatedAt:           merchant.CreatedAt,
+	}
+}
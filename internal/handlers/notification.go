@@ -0,0 +1,360 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/vterdunov/learn-bank-app/internal/domain"
+	"github.com/vterdunov/learn-bank-app/internal/service"
+)
+
+// Notification Request DTOs
+type NotificationPreferenceRequest struct {
+	EventType string `json:"event_type" validate:"required"`
+	Channel   string `json:"channel" validate:"required"`
+	Enabled   bool   `json:"enabled"`
+}
+
+type UpdateNotificationPreferencesRequest struct {
+	Preferences []NotificationPreferenceRequest `json:"preferences" validate:"required,min=1"`
+}
+
+type SetNotificationEndpointRequest struct {
+	Address string `json:"address" validate:"required"`
+}
+
+type SetNotificationLocaleRequest struct {
+	Locale string `json:"locale" validate:"required"`
+}
+
+// Notification Response DTOs
+type NotificationResponse struct {
+	ID        string     `json:"id"`
+	EventType string     `json:"event_type"`
+	Title     string     `json:"title"`
+	Message   string     `json:"message"`
+	Read      bool       `json:"read"`
+	ReadAt    *time.Time `json:"read_at,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+type NotificationPreferenceResponse struct {
+	EventType string `json:"event_type"`
+	Channel   string `json:"channel"`
+	Enabled   bool   `json:"enabled"`
+}
+
+type NotificationEndpointResponse struct {
+	Channel string `json:"channel"`
+	Address string `json:"address"`
+	// Secret секрет подписи webhook, возвращается только при создании
+	Secret    string    `json:"secret,omitempty"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// NotificationHandler обрабатывает запросы к уведомлениям пользователя
+type NotificationHandler struct {
+	notificationService service.NotificationService
+	logger              *slog.Logger
+}
+
+func NewNotificationHandler(notificationService service.NotificationService, logger *slog.Logger) *NotificationHandler {
+	return &NotificationHandler{
+		notificationService: notificationService,
+		logger:              logger,
+	}
+}
+
+// ListNotifications возвращает входящие уведомления пользователя
+func (h *NotificationHandler) ListNotifications(w http.ResponseWriter, r *http.Request) {
+	userID, err := GetUserIDFromRequest(r)
+	if err != nil {
+		WriteErrorResponse(w, http.StatusUnauthorized, err)
+		return
+	}
+
+	query := r.URL.Query()
+	unreadOnly := query.Get("unread") == "true"
+
+	var limit, offset int
+	for name, target := range map[string]*int{"limit": &limit, "offset": &offset} {
+		if value := query.Get(name); value != "" {
+			parsed, err := strconv.Atoi(value)
+			if err != nil || parsed < 0 {
+				WriteErrorResponse(w, http.StatusBadRequest, fmt.Errorf("invalid %s", name))
+				return
+			}
+			*target = parsed
+		}
+	}
+
+	notifications, err := h.notificationService.ListNotifications(r.Context(), userID, unreadOnly, limit, offset)
+	if err != nil {
+		h.logger.Error("Failed to list notifications", "user_id", userID, "error", err.Error())
+		WriteErrorResponse(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	responses := make([]*NotificationResponse, 0, len(notifications))
+	for _, notification := range notifications {
+		responses = append(responses, NotificationToResponse(notification))
+	}
+
+	WriteSuccessResponse(w, responses)
+}
+
+// MarkRead отмечает уведомление прочитанным
+func (h *NotificationHandler) MarkRead(w http.ResponseWriter, r *http.Request) {
+	userID, err := GetUserIDFromRequest(r)
+	if err != nil {
+		WriteErrorResponse(w, http.StatusUnauthorized, err)
+		return
+	}
+
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		WriteErrorResponse(w, http.StatusBadRequest, fmt.Errorf("invalid notification ID"))
+		return
+	}
+
+	if err := h.notificationService.MarkRead(r.Context(), userID, id); err != nil {
+		if errors.Is(err, domain.ErrNotificationNotFound) {
+			WriteErrorResponse(w, http.StatusNotFound, err)
+			return
+		}
+		h.logger.Error("Failed to mark notification read", "user_id", userID, "notification_id", id, "error", err.Error())
+		WriteErrorResponse(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	WriteSuccessResponse(w, map[string]bool{"read": true})
+}
+
+// MarkAllRead отмечает прочитанными все уведомления пользователя
+func (h *NotificationHandler) MarkAllRead(w http.ResponseWriter, r *http.Request) {
+	userID, err := GetUserIDFromRequest(r)
+	if err != nil {
+		WriteErrorResponse(w, http.StatusUnauthorized, err)
+		return
+	}
+
+	count, err := h.notificationService.MarkAllRead(r.Context(), userID)
+	if err != nil {
+		h.logger.Error("Failed to mark notifications read", "user_id", userID, "error", err.Error())
+		WriteErrorResponse(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	WriteSuccessResponse(w, map[string]int64{"updated": count})
+}
+
+// GetPreferences возвращает настройки каналов по всем типам событий
+func (h *NotificationHandler) GetPreferences(w http.ResponseWriter, r *http.Request) {
+	userID, err := GetUserIDFromRequest(r)
+	if err != nil {
+		WriteErrorResponse(w, http.StatusUnauthorized, err)
+		return
+	}
+
+	preferences, err := h.notificationService.GetPreferences(r.Context(), userID)
+	if err != nil {
+		h.logger.Error("Failed to get notification preferences", "user_id", userID, "error", err.Error())
+		WriteErrorResponse(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	responses := make([]*NotificationPreferenceResponse, 0, len(preferences))
+	for _, p := range preferences {
+		responses = append(responses, &NotificationPreferenceResponse{
+			EventType: p.EventType,
+			Channel:   p.Channel,
+			Enabled:   p.Enabled,
+		})
+	}
+
+	WriteSuccessResponse(w, responses)
+}
+
+// UpdatePreferences включает или отключает каналы для типов событий
+func (h *NotificationHandler) UpdatePreferences(w http.ResponseWriter, r *http.Request) {
+	var req UpdateNotificationPreferencesRequest
+
+	if err := ValidateJSON(r, &req); err != nil {
+		WriteErrorResponse(w, http.StatusBadRequest, err)
+		return
+	}
+
+	if validationErr := Validate(&req); validationErr != nil {
+		WriteErrorResponse(w, http.StatusBadRequest, validationErr)
+		return
+	}
+
+	userID, err := GetUserIDFromRequest(r)
+	if err != nil {
+		WriteErrorResponse(w, http.StatusUnauthorized, err)
+		return
+	}
+
+	preferences := make([]*domain.NotificationPreference, 0, len(req.Preferences))
+	for _, p := range req.Preferences {
+		preferences = append(preferences, &domain.NotificationPreference{
+			EventType: p.EventType,
+			Channel:   p.Channel,
+			Enabled:   p.Enabled,
+		})
+	}
+
+	if err := h.notificationService.UpdatePreferences(r.Context(), userID, preferences); err != nil {
+		if errors.Is(err, domain.ErrInvalidNotificationEvent) || errors.Is(err, domain.ErrInvalidNotificationChannel) {
+			WriteErrorResponse(w, http.StatusBadRequest, err)
+			return
+		}
+		h.logger.Error("Failed to update notification preferences", "user_id", userID, "error", err.Error())
+		WriteErrorResponse(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	h.GetPreferences(w, r)
+}
+
+// ListEndpoints возвращает адреса доставки внешних каналов
+func (h *NotificationHandler) ListEndpoints(w http.ResponseWriter, r *http.Request) {
+	userID, err := GetUserIDFromRequest(r)
+	if err != nil {
+		WriteErrorResponse(w, http.StatusUnauthorized, err)
+		return
+	}
+
+	endpoints, err := h.notificationService.ListEndpoints(r.Context(), userID)
+	if err != nil {
+		h.logger.Error("Failed to list notification endpoints", "user_id", userID, "error", err.Error())
+		WriteErrorResponse(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	responses := make([]*NotificationEndpointResponse, 0, len(endpoints))
+	for _, endpoint := range endpoints {
+		responses = append(responses, &NotificationEndpointResponse{
+			Channel:   endpoint.Channel,
+			Address:   endpoint.Address,
+			UpdatedAt: endpoint.UpdatedAt,
+		})
+	}
+
+	WriteSuccessResponse(w, responses)
+}
+
+// SetEndpoint задает URL webhook или номер телефона для SMS
+func (h *NotificationHandler) SetEndpoint(w http.ResponseWriter, r *http.Request) {
+	var req SetNotificationEndpointRequest
+
+	if err := ValidateJSON(r, &req); err != nil {
+		WriteErrorResponse(w, http.StatusBadRequest, err)
+		return
+	}
+
+	if validationErr := Validate(&req); validationErr != nil {
+		WriteErrorResponse(w, http.StatusBadRequest, validationErr)
+		return
+	}
+
+	userID, err := GetUserIDFromRequest(r)
+	if err != nil {
+		WriteErrorResponse(w, http.StatusUnauthorized, err)
+		return
+	}
+
+	endpoint, err := h.notificationService.SetEndpoint(r.Context(), userID, r.PathValue("channel"), req.Address)
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrInvalidNotificationChannel),
+			errors.Is(err, domain.ErrInvalidWebhookURL),
+			errors.Is(err, domain.ErrInvalidPhoneNumber):
+			WriteErrorResponse(w, http.StatusBadRequest, err)
+		default:
+			h.logger.Error("Failed to set notification endpoint", "user_id", userID, "error", err.Error())
+			WriteErrorResponse(w, http.StatusInternalServerError, err)
+		}
+		return
+	}
+
+	WriteSuccessResponse(w, &NotificationEndpointResponse{
+		Channel:   endpoint.Channel,
+		Address:   endpoint.Address,
+		Secret:    endpoint.Secret,
+		UpdatedAt: endpoint.UpdatedAt,
+	})
+}
+
+// DeleteEndpoint удаляет адрес доставки канала
+func (h *NotificationHandler) DeleteEndpoint(w http.ResponseWriter, r *http.Request) {
+	userID, err := GetUserIDFromRequest(r)
+	if err != nil {
+		WriteErrorResponse(w, http.StatusUnauthorized, err)
+		return
+	}
+
+	if err := h.notificationService.DeleteEndpoint(r.Context(), userID, r.PathValue("channel")); err != nil {
+		switch {
+		case errors.Is(err, domain.ErrInvalidNotificationChannel):
+			WriteErrorResponse(w, http.StatusBadRequest, err)
+		case errors.Is(err, domain.ErrNotificationEndpointAbsent):
+			WriteErrorResponse(w, http.StatusNotFound, err)
+		default:
+			h.logger.Error("Failed to delete notification endpoint", "user_id", userID, "error", err.Error())
+			WriteErrorResponse(w, http.StatusInternalServerError, err)
+		}
+		return
+	}
+
+	WriteSuccessResponse(w, map[string]bool{"deleted": true})
+}
+
+// SetLocale задает язык писем пользователя
+func (h *NotificationHandler) SetLocale(w http.ResponseWriter, r *http.Request) {
+	var req SetNotificationLocaleRequest
+
+	if err := ValidateJSON(r, &req); err != nil {
+		WriteErrorResponse(w, http.StatusBadRequest, err)
+		return
+	}
+
+	if validationErr := Validate(&req); validationErr != nil {
+		WriteErrorResponse(w, http.StatusBadRequest, validationErr)
+		return
+	}
+
+	userID, err := GetUserIDFromRequest(r)
+	if err != nil {
+		WriteErrorResponse(w, http.StatusUnauthorized, err)
+		return
+	}
+
+	if err := h.notificationService.SetLocale(r.Context(), userID, req.Locale); err != nil {
+		if errors.Is(err, domain.ErrUnsupportedLocale) {
+			WriteErrorResponse(w, http.StatusBadRequest, err)
+			return
+		}
+		h.logger.Error("Failed to set notification locale", "user_id", userID, "error", err.Error())
+		WriteErrorResponse(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	WriteSuccessResponse(w, map[string]string{"locale": req.Locale})
+}
+
+func NotificationToResponse(notification *domain.Notification) *NotificationResponse {
+	return &NotificationResponse{
+		ID:        fmt.Sprintf("%d", notification.ID),
+		EventType: notification.EventType,
+		Title:     notification.Title,
+		Message:   notification.Message,
+		Read:      notification.ReadAt != nil,
+		ReadAt:    notification.ReadAt,
+		CreatedAt: notification.CreatedAt,
+	}
+}
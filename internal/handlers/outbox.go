@@ -0,0 +1,126 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/vterdunov/learn-bank-app/internal/domain"
+	"github.com/vterdunov/learn-bank-app/internal/service"
+)
+
+// Outbox Response DTOs
+type OutboxMessageResponse struct {
+	ID            string     `json:"id"`
+	Channel       string     `json:"channel"`
+	EventType     string     `json:"event_type"`
+	Recipient     string     `json:"recipient"`
+	Subject       string     `json:"subject"`
+	Status        string     `json:"status"`
+	Attempts      int        `json:"attempts"`
+	MaxAttempts   int        `json:"max_attempts"`
+	NextAttemptAt time.Time  `json:"next_attempt_at"`
+	LastError     string     `json:"last_error,omitempty"`
+	SentAt        *time.Time `json:"sent_at,omitempty"`
+	CreatedAt     time.Time  `json:"created_at"`
+}
+
+// OutboxHandler обрабатывает запросы администратора к очереди уведомлений
+type OutboxHandler struct {
+	outboxService service.OutboxService
+	logger        *slog.Logger
+}
+
+func NewOutboxHandler(outboxService service.OutboxService, logger *slog.Logger) *OutboxHandler {
+	return &OutboxHandler{
+		outboxService: outboxService,
+		logger:        logger,
+	}
+}
+
+// ListMessages возвращает сообщения outbox по статусу (по умолчанию dead)
+func (h *OutboxHandler) ListMessages(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	status := query.Get("status")
+	if status == "" {
+		status = domain.OutboxStatusDead
+	}
+
+	var limit, offset int
+	for name, target := range map[string]*int{"limit": &limit, "offset": &offset} {
+		if value := query.Get(name); value != "" {
+			parsed, err := strconv.Atoi(value)
+			if err != nil || parsed < 0 {
+				WriteErrorResponse(w, http.StatusBadRequest, fmt.Errorf("invalid %s", name))
+				return
+			}
+			*target = parsed
+		}
+	}
+
+	messages, err := h.outboxService.ListMessages(r.Context(), status, limit, offset)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidOutboxStatus) {
+			WriteErrorResponse(w, http.StatusBadRequest, err)
+			return
+		}
+		h.logger.Error("Failed to list outbox messages", "status", status, "error", err.Error())
+		WriteErrorResponse(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	responses := make([]*OutboxMessageResponse, 0, len(messages))
+	for _, message := range messages {
+		responses = append(responses, OutboxMessageToResponse(message))
+	}
+
+	WriteSuccessResponse(w, responses)
+}
+
+// RetryMessage возвращает dead сообщение в очередь доставки
+func (h *OutboxHandler) RetryMessage(w http.ResponseWriter, r *http.Request) {
+	adminID, err := GetUserIDFromRequest(r)
+	if err != nil {
+		WriteErrorResponse(w, http.StatusUnauthorized, err)
+		return
+	}
+
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		WriteErrorResponse(w, http.StatusBadRequest, fmt.Errorf("invalid message ID"))
+		return
+	}
+
+	if err := h.outboxService.RetryMessage(r.Context(), adminID, id); err != nil {
+		if errors.Is(err, domain.ErrOutboxNotDead) {
+			WriteErrorResponse(w, http.StatusConflict, err)
+			return
+		}
+		h.logger.Error("Failed to retry outbox message", "outbox_id", id, "error", err.Error())
+		WriteErrorResponse(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	WriteSuccessResponse(w, map[string]string{"status": domain.OutboxStatusPending})
+}
+
+func OutboxMessageToResponse(message *domain.OutboxMessage) *OutboxMessageResponse {
+	return &OutboxMessageResponse{
+		ID:            fmt.Sprintf("%d", message.ID),
+		Channel:       message.Channel,
+		EventType:     message.EventType,
+		Recipient:     message.Recipient,
+		Subject:       message.Subject,
+		Status:        message.Status,
+		Attempts:      message.Attempts,
+		MaxAttempts:   message.MaxAttempts,
+		NextAttemptAt: message.NextAttemptAt,
+		LastError:     message.LastError,
+		SentAt:        message.SentAt,
+		CreatedAt:     message.CreatedAt,
+	}
+}
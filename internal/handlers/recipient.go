@@ -0,0 +1,252 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/vterdunov/learn-bank-app/internal/domain"
+	"github.com/vterdunov/learn-bank-app/internal/service"
+)
+
+// Recipient Request DTOs
+
+// RecipientLookupRequest получатель перевода: ровно одно из полей.
+// to_account_id допускается только для своих счетов.
+type RecipientLookupRequest struct {
+	ToAccountID     string `json:"to_account_id,omitempty"`
+	ToAccountNumber string `json:"to_account_number,omitempty"`
+	ToEmail         string `json:"to_email,omitempty"`
+	ToPhone         string `json:"to_phone,omitempty"` // E.164
+}
+
+type CreatePaymentAliasRequest struct {
+	Type      string `json:"type" validate:"required,oneof=phone email"`
+	Value     string `json:"value" validate:"required"`
+	AccountID string `json:"account_id" validate:"required"`
+}
+
+// Recipient Response DTOs
+type RecipientResponse struct {
+	MaskedName          string   `json:"masked_name"`
+	MaskedAccountNumber string   `json:"masked_account_number"`
+	OwnAccount          bool     `json:"own_account"`
+	NewRecipient        bool     `json:"new_recipient"`
+	LimitRemaining      *float64 `json:"limit_remaining,omitempty"`
+}
+
+type PaymentAliasResponse struct {
+	ID        string    `json:"id"`
+	Type      string    `json:"type"`
+	Value     string    `json:"value"`
+	AccountID string    `json:"account_id"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// RecipientHandler обрабатывает подтверждение получателя и псевдонимы для переводов
+type RecipientHandler struct {
+	recipientService service.RecipientService
+	logger           *slog.Logger
+}
+
+func NewRecipientHandler(recipientService service.RecipientService, logger *slog.Logger) *RecipientHandler {
+	return &RecipientHandler{
+		recipientService: recipientService,
+		logger:           logger,
+	}
+}
+
+// ResolveRecipient возвращает маскированные данные получателя для подтверждения перед переводом
+func (h *RecipientHandler) ResolveRecipient(w http.ResponseWriter, r *http.Request) {
+	var req RecipientLookupRequest
+
+	if err := ValidateJSON(r, &req); err != nil {
+		WriteErrorResponse(w, http.StatusBadRequest, err)
+		return
+	}
+
+	if validationErr := Validate(&req); validationErr != nil {
+		WriteErrorResponse(w, http.StatusBadRequest, validationErr)
+		return
+	}
+
+	lookup, err := parseRecipientLookup(req)
+	if err != nil {
+		WriteErrorResponse(w, http.StatusBadRequest, err)
+		return
+	}
+
+	userID, err := GetUserIDFromRequest(r)
+	if err != nil {
+		WriteErrorResponse(w, http.StatusUnauthorized, err)
+		return
+	}
+
+	recipient, err := h.recipientService.ResolveRecipient(r.Context(), userID, lookup)
+	if err != nil {
+		writeRecipientError(w, h.logger, "resolve recipient", err)
+		return
+	}
+
+	WriteSuccessResponse(w, RecipientToResponse(recipient))
+}
+
+// GetAliases возвращает псевдонимы пользователя
+func (h *RecipientHandler) GetAliases(w http.ResponseWriter, r *http.Request) {
+	userID, err := GetUserIDFromRequest(r)
+	if err != nil {
+		WriteErrorResponse(w, http.StatusUnauthorized, err)
+		return
+	}
+
+	aliases, err := h.recipientService.GetAliases(r.Context(), userID)
+	if err != nil {
+		h.logger.Error("Failed to get payment aliases", "user_id", userID, "error", err.Error())
+		WriteErrorResponse(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	response := make([]*PaymentAliasResponse, len(aliases))
+	for i, alias := range aliases {
+		response[i] = PaymentAliasToResponse(alias)
+	}
+
+	WriteSuccessResponse(w, response)
+}
+
+// CreateAlias привязывает телефон или email к счету для входящих переводов
+func (h *RecipientHandler) CreateAlias(w http.ResponseWriter, r *http.Request) {
+	var req CreatePaymentAliasRequest
+
+	if err := ValidateJSON(r, &req); err != nil {
+		WriteErrorResponse(w, http.StatusBadRequest, err)
+		return
+	}
+
+	if validationErr := Validate(&req); validationErr != nil {
+		WriteErrorResponse(w, http.StatusBadRequest, validationErr)
+		return
+	}
+
+	accountID, err := strconv.Atoi(req.AccountID)
+	if err != nil {
+		WriteErrorResponse(w, http.StatusBadRequest, fmt.Errorf("invalid account_id"))
+		return
+	}
+
+	userID, err := GetUserIDFromRequest(r)
+	if err != nil {
+		WriteErrorResponse(w, http.StatusUnauthorized, err)
+		return
+	}
+
+	alias, err := h.recipientService.CreateAlias(r.Context(), userID, domain.PaymentAlias{
+		Type:      req.Type,
+		Value:     req.Value,
+		AccountID: accountID,
+	})
+	if err != nil {
+		writeRecipientError(w, h.logger, "create payment alias", err)
+		return
+	}
+
+	h.logger.Info("Payment alias created", "alias_id", alias.ID, "user_id", userID, "type", alias.Type)
+
+	WriteSuccessResponse(w, PaymentAliasToResponse(alias))
+}
+
+// DeleteAlias удаляет псевдоним
+func (h *RecipientHandler) DeleteAlias(w http.ResponseWriter, r *http.Request) {
+	aliasID, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		WriteErrorResponse(w, http.StatusBadRequest, fmt.Errorf("invalid alias ID"))
+		return
+	}
+
+	userID, err := GetUserIDFromRequest(r)
+	if err != nil {
+		WriteErrorResponse(w, http.StatusUnauthorized, err)
+		return
+	}
+
+	if err := h.recipientService.DeleteAlias(r.Context(), userID, aliasID); err != nil {
+		writeRecipientError(w, h.logger, "delete payment alias", err)
+		return
+	}
+
+	WriteSuccessResponse(w, map[string]string{"message": "Payment alias deleted"})
+}
+
+// parseRecipientLookup переводит DTO получателя в доменный запрос поиска
+func parseRecipientLookup(req RecipientLookupRequest) (domain.RecipientLookup, error) {
+	lookup := domain.RecipientLookup{
+		AccountNumber: req.ToAccountNumber,
+		Email:         req.ToEmail,
+		Phone:         req.ToPhone,
+	}
+
+	if req.ToAccountID != "" {
+		accountID, err := strconv.Atoi(req.ToAccountID)
+		if err != nil || accountID <= 0 {
+			return lookup, fmt.Errorf("invalid to_account_id")
+		}
+		lookup.AccountID = accountID
+	}
+
+	return lookup, nil
+}
+
+// writeRecipientError отвечает кодом, соответствующим ошибке поиска получателя или перевода
+func writeRecipientError(w http.ResponseWriter, logger *slog.Logger, action string, err error) {
+	var serviceErr *service.ServiceError
+	switch {
+	case writeScreeningError(w, err):
+	case errors.As(err, &serviceErr):
+		WriteErrorResponse(w, serviceErr.Code, err)
+	case errors.Is(err, domain.ErrRecipientNotFound),
+		errors.Is(err, domain.ErrPaymentAliasNotFound):
+		WriteErrorResponse(w, http.StatusNotFound, err)
+	case errors.Is(err, domain.ErrPaymentAliasTaken),
+		errors.Is(err, domain.ErrAccountClosed):
+		WriteErrorResponse(w, http.StatusConflict, err)
+	case errors.Is(err, domain.ErrNewRecipientLimitExceeded):
+		WriteErrorResponse(w, http.StatusForbidden, err)
+	case errors.Is(err, domain.ErrInvalidRecipient),
+		errors.Is(err, domain.ErrInvalidAccountNumber),
+		errors.Is(err, domain.ErrInvalidPhoneNumber),
+		errors.Is(err, domain.ErrInvalidAccountID),
+		errors.Is(err, domain.ErrInvalidPaymentAliasType),
+		errors.Is(err, domain.ErrPaymentAliasEmailMismatch),
+		errors.Is(err, service.ErrAccountNotFound),
+		errors.Is(err, service.ErrAccountBlocked),
+		errors.Is(err, service.ErrInsufficientFunds),
+		errors.Is(err, service.ErrInvalidAmount):
+		WriteErrorResponse(w, http.StatusBadRequest, err)
+	default:
+		logger.Error("Failed to "+action, "error", err.Error())
+		WriteErrorResponse(w, http.StatusInternalServerError, err)
+	}
+}
+
+func RecipientToResponse(recipient *domain.Recipient) *RecipientResponse {
+	return &RecipientResponse{
+		MaskedName:          recipient.MaskedName,
+		MaskedAccountNumber: recipient.MaskedAccountNumber,
+		OwnAccount:          recipient.OwnAccount,
+		NewRecipient:        recipient.NewRecipient,
+		LimitRemaining:      recipient.LimitRemaining,
+	}
+}
+
+func PaymentAliasToResponse(alias *domain.PaymentAlias) *PaymentAliasResponse {
+	return &PaymentAliasResponse{
+		ID:        fmt.Sprintf("%d", alias.ID),
+		Type:      alias.Type,
+		Value:     alias.Value,
+		AccountID: fmt.Sprintf("%d", alias.AccountID),
+		CreatedAt: alias.CreatedAt,
+	}
+}
@@ -0,0 +1,190 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/vterdunov/learn-bank-app/internal/domain"
+	"github.com/vterdunov/learn-bank-app/internal/service"
+)
+
+// Sanctions Request DTOs
+type ResolveSanctionsHitRequest struct {
+	Comment string `json:"comment"`
+}
+
+// Sanctions Response DTOs
+type SanctionsListResponse struct {
+	ID           string    `json:"id"`
+	Name         string    `json:"name"`
+	Source       string    `json:"source"`
+	Checksum     string    `json:"checksum"`
+	EntriesCount int       `json:"entries_count"`
+	LoadedAt     time.Time `json:"loaded_at"`
+}
+
+type SanctionsHitResponse struct {
+	ID              string     `json:"id"`
+	UserID          string     `json:"user_id"`
+	ListName        string     `json:"list_name"`
+	EntryExternalID string     `json:"entry_external_id"`
+	EntryName       string     `json:"entry_name"`
+	MatchedName     string     `json:"matched_name"`
+	Score           float64    `json:"score"`
+	Source          string     `json:"source"`
+	Status          string     `json:"status"`
+	ReviewerID      *string    `json:"reviewer_id,omitempty"`
+	Comment         string     `json:"comment,omitempty"`
+	ReviewedAt      *time.Time `json:"reviewed_at,omitempty"`
+	CreatedAt       time.Time  `json:"created_at"`
+}
+
+// SanctionsHandler обрабатывает запросы оператора к санкционным спискам и очереди совпадений
+type SanctionsHandler struct {
+	sanctionsService service.SanctionsService
+	logger           *slog.Logger
+}
+
+func NewSanctionsHandler(sanctionsService service.SanctionsService, logger *slog.Logger) *SanctionsHandler {
+	return &SanctionsHandler{
+		sanctionsService: sanctionsService,
+		logger:           logger,
+	}
+}
+
+// ListLists возвращает загруженные санкционные списки
+func (h *SanctionsHandler) ListLists(w http.ResponseWriter, r *http.Request) {
+	lists, err := h.sanctionsService.ListLists(r.Context())
+	if err != nil {
+		h.logger.Error("Failed to list sanctions lists", "error", err.Error())
+		WriteErrorResponse(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	responses := make([]*SanctionsListResponse, 0, len(lists))
+	for _, list := range lists {
+		responses = append(responses, &SanctionsListResponse{
+			ID:           fmt.Sprintf("%d", list.ID),
+			Name:         list.Name,
+			Source:       list.Source,
+			Checksum:     list.Checksum,
+			EntriesCount: list.EntriesCount,
+			LoadedAt:     list.LoadedAt,
+		})
+	}
+
+	WriteSuccessResponse(w, responses)
+}
+
+// ListHits возвращает совпадения по статусу (по умолчанию pending)
+func (h *SanctionsHandler) ListHits(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	var limit, offset int
+	for name, target := range map[string]*int{"limit": &limit, "offset": &offset} {
+		if value := query.Get(name); value != "" {
+			parsed, err := strconv.Atoi(value)
+			if err != nil || parsed < 0 {
+				WriteErrorResponse(w, http.StatusBadRequest, fmt.Errorf("invalid %s", name))
+				return
+			}
+			*target = parsed
+		}
+	}
+
+	hits, err := h.sanctionsService.ListHits(r.Context(), query.Get("status"), limit, offset)
+	if err != nil {
+		if errors.Is(err, domain.ErrInvalidSanctionsHitStatus) {
+			WriteErrorResponse(w, http.StatusBadRequest, err)
+			return
+		}
+		h.logger.Error("Failed to list sanctions hits", "error", err.Error())
+		WriteErrorResponse(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	responses := make([]*SanctionsHitResponse, 0, len(hits))
+	for _, hit := range hits {
+		responses = append(responses, SanctionsHitToResponse(hit))
+	}
+
+	WriteSuccessResponse(w, responses)
+}
+
+// ConfirmHit подтверждает совпадение: переводы клиенту запрещаются
+func (h *SanctionsHandler) ConfirmHit(w http.ResponseWriter, r *http.Request) {
+	h.resolveHit(w, r, h.sanctionsService.ConfirmHit)
+}
+
+// ClearHit снимает подозрение с клиента
+func (h *SanctionsHandler) ClearHit(w http.ResponseWriter, r *http.Request) {
+	h.resolveHit(w, r, h.sanctionsService.ClearHit)
+}
+
+func (h *SanctionsHandler) resolveHit(
+	w http.ResponseWriter,
+	r *http.Request,
+	resolve func(ctx context.Context, adminID, hitID int, comment string) (*domain.SanctionsHit, error),
+) {
+	adminID, err := GetUserIDFromRequest(r)
+	if err != nil {
+		WriteErrorResponse(w, http.StatusUnauthorized, err)
+		return
+	}
+
+	hitID, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		WriteErrorResponse(w, http.StatusBadRequest, fmt.Errorf("invalid hit ID"))
+		return
+	}
+
+	var req ResolveSanctionsHitRequest
+	if err := ValidateJSON(r, &req); err != nil {
+		WriteErrorResponse(w, http.StatusBadRequest, err)
+		return
+	}
+
+	if validationErr := Validate(&req); validationErr != nil {
+		WriteErrorResponse(w, http.StatusBadRequest, validationErr)
+		return
+	}
+
+	hit, err := resolve(r.Context(), adminID, hitID, req.Comment)
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrSanctionsHitNotFound):
+			WriteErrorResponse(w, http.StatusNotFound, err)
+		case errors.Is(err, domain.ErrSanctionsHitResolved):
+			WriteErrorResponse(w, http.StatusConflict, err)
+		default:
+			h.logger.Error("Failed to resolve sanctions hit", "hit_id", hitID, "error", err.Error())
+			WriteErrorResponse(w, http.StatusInternalServerError, err)
+		}
+		return
+	}
+
+	WriteSuccessResponse(w, SanctionsHitToResponse(hit))
+}
+
+func SanctionsHitToResponse(hit *domain.SanctionsHit) *SanctionsHitResponse {
+	return &SanctionsHitResponse{
+		ID:              fmt.Sprintf("%d", hit.ID),
+		UserID:          fmt.Sprintf("%d", hit.UserID),
+		ListName:        hit.ListName,
+		EntryExternalID: hit.EntryExternalID,
+		EntryName:       hit.EntryName,
+		MatchedName:     hit.MatchedName,
+		Score:           hit.Score,
+		Source:          hit.Source,
+		Status:          hit.Status,
+		ReviewerID:      optionalIDString(hit.ReviewerID),
+		Comment:         hit.Comment,
+		ReviewedAt:      hit.ReviewedAt,
+		CreatedAt:       hit.CreatedAt,
+	}
+}
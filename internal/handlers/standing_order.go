@@ -0,0 +1,380 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/vterdunov/learn-bank-app/internal/domain"
+	"github.com/vterdunov/learn-bank-app/internal/service"
+)
+
+// Standing Order Request DTOs
+
+// StandingOrderRequest условия поручения; получатель задается как при переводе:
+// to_account_id (только свой счет), to_account_number, to_email или to_phone
+type StandingOrderRequest struct {
+	FromAccountID string `json:"from_account_id" validate:"required"`
+	RecipientLookupRequest
+	Amount      float64 `json:"amount" validate:"required,gt=0"`
+	Description string  `json:"description,omitempty"`
+	Schedule    string  `json:"schedule" validate:"required"`
+	StartDate   string  `json:"start_date" validate:"required"` // YYYY-MM-DD
+	DayOfWeek   int     `json:"day_of_week,omitempty"`
+	DayOfMonth  int     `json:"day_of_month,omitempty"`
+	EndDate     string  `json:"end_date,omitempty"` // YYYY-MM-DD
+}
+
+// Standing Order Response DTOs
+type StandingOrderResponse struct {
+	ID                           string    `json:"id"`
+	FromAccountID                string    `json:"from_account_id"`
+	RecipientMaskedName          string    `json:"recipient_masked_name"`
+	RecipientMaskedAccountNumber string    `json:"recipient_masked_account_number"`
+	Amount                       float64   `json:"amount"`
+	Description                  string    `json:"description,omitempty"`
+	Schedule                     string    `json:"schedule"`
+	DayOfWeek                    int       `json:"day_of_week,omitempty"`
+	DayOfMonth                   int       `json:"day_of_month,omitempty"`
+	StartDate                    string    `json:"start_date"`
+	EndDate                      string    `json:"end_date,omitempty"`
+	NextRunDate                  string    `json:"next_run_date,omitempty"`
+	Attempts                     int       `json:"attempts"`
+	RetryAt                      string    `json:"retry_at,omitempty"`
+	Status                       string    `json:"status"`
+	CreatedAt                    time.Time `json:"created_at"`
+	UpdatedAt                    time.Time `json:"updated_at"`
+}
+
+type StandingOrderExecutionResponse struct {
+	ID            string    `json:"id"`
+	ScheduledDate string    `json:"scheduled_date"`
+	Attempt       int       `json:"attempt"`
+	Status        string    `json:"status"`
+	Amount        float64   `json:"amount"`
+	Error         string    `json:"error,omitempty"`
+	ExecutedAt    time.Time `json:"executed_at"`
+}
+
+// StandingOrderHandler обрабатывает запросы по постоянным поручениям
+type StandingOrderHandler struct {
+	standingOrderService service.StandingOrderService
+	logger               *slog.Logger
+}
+
+func NewStandingOrderHandler(standingOrderService service.StandingOrderService, logger *slog.Logger) *StandingOrderHandler {
+	return &StandingOrderHandler{
+		standingOrderService: standingOrderService,
+		logger:               logger,
+	}
+}
+
+// CreateStandingOrder создает постоянное поручение
+func (h *StandingOrderHandler) CreateStandingOrder(w http.ResponseWriter, r *http.Request) {
+	req, ok := h.parseStandingOrderBody(w, r)
+	if !ok {
+		return
+	}
+
+	userID, err := GetUserIDFromRequest(r)
+	if err != nil {
+		WriteErrorResponse(w, http.StatusUnauthorized, err)
+		return
+	}
+
+	order, err := h.standingOrderService.CreateStandingOrder(r.Context(), userID, req)
+	if err != nil {
+		h.writeStandingOrderError(w, "create", err)
+		return
+	}
+
+	h.logger.Info("Standing order created", "standing_order_id", order.ID, "user_id", userID)
+
+	WriteSuccessResponse(w, StandingOrderToResponse(order))
+}
+
+// GetUserStandingOrders возвращает поручения пользователя
+func (h *StandingOrderHandler) GetUserStandingOrders(w http.ResponseWriter, r *http.Request) {
+	userID, err := GetUserIDFromRequest(r)
+	if err != nil {
+		WriteErrorResponse(w, http.StatusUnauthorized, err)
+		return
+	}
+
+	orders, err := h.standingOrderService.GetUserStandingOrders(r.Context(), userID)
+	if err != nil {
+		h.logger.Error("Failed to get user standing orders", "user_id", userID, "error", err.Error())
+		WriteErrorResponse(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	responses := make([]*StandingOrderResponse, 0, len(orders))
+	for _, order := range orders {
+		responses = append(responses, StandingOrderToResponse(order))
+	}
+
+	WriteSuccessResponse(w, responses)
+}
+
+// GetStandingOrder возвращает поручение
+func (h *StandingOrderHandler) GetStandingOrder(w http.ResponseWriter, r *http.Request) {
+	userID, orderID, ok := h.parseStandingOrderRequest(w, r)
+	if !ok {
+		return
+	}
+
+	order, err := h.standingOrderService.GetStandingOrder(r.Context(), userID, orderID)
+	if err != nil {
+		h.writeStandingOrderError(w, "get", err)
+		return
+	}
+
+	WriteSuccessResponse(w, StandingOrderToResponse(order))
+}
+
+// UpdateStandingOrder меняет условия поручения
+func (h *StandingOrderHandler) UpdateStandingOrder(w http.ResponseWriter, r *http.Request) {
+	userID, orderID, ok := h.parseStandingOrderRequest(w, r)
+	if !ok {
+		return
+	}
+
+	req, ok := h.parseStandingOrderBody(w, r)
+	if !ok {
+		return
+	}
+
+	order, err := h.standingOrderService.UpdateStandingOrder(r.Context(), userID, orderID, req)
+	if err != nil {
+		h.writeStandingOrderError(w, "update", err)
+		return
+	}
+
+	WriteSuccessResponse(w, StandingOrderToResponse(order))
+}
+
+// PauseStandingOrder приостанавливает поручение
+func (h *StandingOrderHandler) PauseStandingOrder(w http.ResponseWriter, r *http.Request) {
+	userID, orderID, ok := h.parseStandingOrderRequest(w, r)
+	if !ok {
+		return
+	}
+
+	order, err := h.standingOrderService.PauseStandingOrder(r.Context(), userID, orderID)
+	if err != nil {
+		h.writeStandingOrderError(w, "pause", err)
+		return
+	}
+
+	WriteSuccessResponse(w, StandingOrderToResponse(order))
+}
+
+// ResumeStandingOrder возобновляет поручение
+func (h *StandingOrderHandler) ResumeStandingOrder(w http.ResponseWriter, r *http.Request) {
+	userID, orderID, ok := h.parseStandingOrderRequest(w, r)
+	if !ok {
+		return
+	}
+
+	order, err := h.standingOrderService.ResumeStandingOrder(r.Context(), userID, orderID)
+	if err != nil {
+		h.writeStandingOrderError(w, "resume", err)
+		return
+	}
+
+	WriteSuccessResponse(w, StandingOrderToResponse(order))
+}
+
+// CancelStandingOrder отменяет поручение
+func (h *StandingOrderHandler) CancelStandingOrder(w http.ResponseWriter, r *http.Request) {
+	userID, orderID, ok := h.parseStandingOrderRequest(w, r)
+	if !ok {
+		return
+	}
+
+	order, err := h.standingOrderService.CancelStandingOrder(r.Context(), userID, orderID)
+	if err != nil {
+		h.writeStandingOrderError(w, "cancel", err)
+		return
+	}
+
+	h.logger.Info("Standing order cancelled", "standing_order_id", order.ID, "user_id", userID)
+
+	WriteSuccessResponse(w, StandingOrderToResponse(order))
+}
+
+// GetExecutions возвращает историю исполнения поручения
+func (h *StandingOrderHandler) GetExecutions(w http.ResponseWriter, r *http.Request) {
+	userID, orderID, ok := h.parseStandingOrderRequest(w, r)
+	if !ok {
+		return
+	}
+
+	query := r.URL.Query()
+	var limit, offset int
+	for name, target := range map[string]*int{"limit": &limit, "offset": &offset} {
+		if value := query.Get(name); value != "" {
+			parsed, err := strconv.Atoi(value)
+			if err != nil || parsed < 0 {
+				WriteErrorResponse(w, http.StatusBadRequest, fmt.Errorf("invalid %s", name))
+				return
+			}
+			*target = parsed
+		}
+	}
+
+	executions, err := h.standingOrderService.GetExecutions(r.Context(), userID, orderID, limit, offset)
+	if err != nil {
+		h.writeStandingOrderError(w, "list executions of", err)
+		return
+	}
+
+	responses := make([]*StandingOrderExecutionResponse, 0, len(executions))
+	for _, execution := range executions {
+		responses = append(responses, &StandingOrderExecutionResponse{
+			ID:            fmt.Sprintf("%d", execution.ID),
+			ScheduledDate: execution.ScheduledDate.Format(time.DateOnly),
+			Attempt:       execution.Attempt,
+			Status:        execution.Status,
+			Amount:        execution.Amount,
+			Error:         execution.Error,
+			ExecutedAt:    execution.ExecutedAt,
+		})
+	}
+
+	WriteSuccessResponse(w, responses)
+}
+
+// parseStandingOrderBody читает и валидирует условия поручения из тела запроса
+func (h *StandingOrderHandler) parseStandingOrderBody(w http.ResponseWriter, r *http.Request) (domain.StandingOrderRequest, bool) {
+	var req StandingOrderRequest
+
+	if err := ValidateJSON(r, &req); err != nil {
+		WriteErrorResponse(w, http.StatusBadRequest, err)
+		return domain.StandingOrderRequest{}, false
+	}
+
+	if validationErr := Validate(&req); validationErr != nil {
+		WriteErrorResponse(w, http.StatusBadRequest, validationErr)
+		return domain.StandingOrderRequest{}, false
+	}
+
+	fromAccountID, err := strconv.Atoi(req.FromAccountID)
+	if err != nil {
+		WriteErrorResponse(w, http.StatusBadRequest, fmt.Errorf("invalid from_account_id"))
+		return domain.StandingOrderRequest{}, false
+	}
+
+	lookup, err := parseRecipientLookup(req.RecipientLookupRequest)
+	if err != nil {
+		WriteErrorResponse(w, http.StatusBadRequest, err)
+		return domain.StandingOrderRequest{}, false
+	}
+
+	startDate, err := time.Parse(time.DateOnly, req.StartDate)
+	if err != nil {
+		WriteErrorResponse(w, http.StatusBadRequest, fmt.Errorf("invalid start_date"))
+		return domain.StandingOrderRequest{}, false
+	}
+
+	var endDate *time.Time
+	if req.EndDate != "" {
+		parsed, err := time.Parse(time.DateOnly, req.EndDate)
+		if err != nil {
+			WriteErrorResponse(w, http.StatusBadRequest, fmt.Errorf("invalid end_date"))
+			return domain.StandingOrderRequest{}, false
+		}
+		endDate = &parsed
+	}
+
+	return domain.StandingOrderRequest{
+		FromAccountID: fromAccountID,
+		To:            lookup,
+		Amount:        req.Amount,
+		Description:   req.Description,
+		Schedule:      req.Schedule,
+		StartDate:     startDate,
+		DayOfWeek:     req.DayOfWeek,
+		DayOfMonth:    req.DayOfMonth,
+		EndDate:       endDate,
+	}, true
+}
+
+// parseStandingOrderRequest извлекает пользователя и ID поручения из запроса
+func (h *StandingOrderHandler) parseStandingOrderRequest(w http.ResponseWriter, r *http.Request) (userID, orderID int, ok bool) {
+	orderID, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		WriteErrorResponse(w, http.StatusBadRequest, fmt.Errorf("invalid standing order ID"))
+		return 0, 0, false
+	}
+
+	userID, err = GetUserIDFromRequest(r)
+	if err != nil {
+		WriteErrorResponse(w, http.StatusUnauthorized, err)
+		return 0, 0, false
+	}
+
+	return userID, orderID, true
+}
+
+// writeStandingOrderError выбирает HTTP статус по ошибке сервиса поручений
+func (h *StandingOrderHandler) writeStandingOrderError(w http.ResponseWriter, action string, err error) {
+	var serviceErr *service.ServiceError
+	switch {
+	case errors.As(err, &serviceErr):
+		WriteErrorResponse(w, serviceErr.Code, err)
+	case errors.Is(err, domain.ErrStandingOrderNotFound),
+		errors.Is(err, domain.ErrRecipientNotFound):
+		WriteErrorResponse(w, http.StatusNotFound, err)
+	case errors.Is(err, domain.ErrStandingOrderNotActive),
+		errors.Is(err, domain.ErrStandingOrderNotPaused),
+		errors.Is(err, domain.ErrStandingOrderFinished):
+		WriteErrorResponse(w, http.StatusConflict, err)
+	case errors.Is(err, domain.ErrInvalidAccountID),
+		errors.Is(err, domain.ErrInvalidStandingOrderAmount),
+		errors.Is(err, domain.ErrStandingOrderSameAccount),
+		errors.Is(err, domain.ErrInvalidStandingOrderType),
+		errors.Is(err, domain.ErrInvalidStandingOrderDay),
+		errors.Is(err, domain.ErrInvalidStandingOrderPeriod),
+		errors.Is(err, domain.ErrInvalidRecipient),
+		errors.Is(err, domain.ErrInvalidAccountNumber),
+		errors.Is(err, domain.ErrInvalidPhoneNumber),
+		errors.Is(err, service.ErrAccountNotFound):
+		WriteErrorResponse(w, http.StatusBadRequest, err)
+	default:
+		h.logger.Error("Failed to "+action+" standing order", "error", err.Error())
+		WriteErrorResponse(w, http.StatusInternalServerError, err)
+	}
+}
+
+func StandingOrderToResponse(order *domain.StandingOrder) *StandingOrderResponse {
+	response := &StandingOrderResponse{
+		ID:                           fmt.Sprintf("%d", order.ID),
+		FromAccountID:                fmt.Sprintf("%d", order.FromAccountID),
+		RecipientMaskedName:          order.ToMaskedName,
+		RecipientMaskedAccountNumber: order.ToMaskedAccountNumber,
+		Amount:                       order.Amount,
+		Description:                  order.Description,
+		Schedule:                     order.Schedule,
+		DayOfWeek:                    order.DayOfWeek,
+		DayOfMonth:                   order.DayOfMonth,
+		StartDate:                    order.StartDate.Format(time.DateOnly),
+		Attempts:                     order.Attempts,
+		Status:                       order.Status,
+		CreatedAt:                    order.CreatedAt,
+		UpdatedAt:                    order.UpdatedAt,
+	}
+	if order.EndDate != nil {
+		response.EndDate = order.EndDate.Format(time.DateOnly)
+	}
+	if order.NextRunDate != nil {
+		response.NextRunDate = order.NextRunDate.Format(time.DateOnly)
+	}
+	if order.RetryAt != nil {
+		response.RetryAt = order.RetryAt.Format(time.RFC3339)
+	}
+	return response
+}
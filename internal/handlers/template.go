@@ -0,0 +1,51 @@
+package handlers
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/vterdunov/learn-bank-app/internal/service"
+)
+
+// TemplateHandler обрабатывает запросы администратора к шаблонам писем
+type TemplateHandler struct {
+	emailService service.EmailService
+	logger       *slog.Logger
+}
+
+func NewTemplateHandler(emailService service.EmailService, logger *slog.Logger) *TemplateHandler {
+	return &TemplateHandler{
+		emailService: emailService,
+		logger:       logger,
+	}
+}
+
+// Preview рендерит шаблон письма на тестовых данных.
+// Параметры locale и version необязательны; format=html возвращает HTML версию как страницу.
+func (h *TemplateHandler) Preview(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	name := r.PathValue("name")
+
+	rendered, err := h.emailService.PreviewTemplate(name, query.Get("locale"), query.Get("version"))
+	if err != nil {
+		if errors.Is(err, service.ErrEmailTemplateNotFound) || errors.Is(err, service.ErrEmailTemplateVersion) {
+			WriteErrorResponse(w, http.StatusNotFound, err)
+			return
+		}
+		h.logger.Error("Failed to preview email template", "name", name, "error", err.Error())
+		WriteErrorResponse(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	if query.Get("format") == "html" {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		// Письма используют inline стили: политика API по умолчанию (default-src 'none') их блокирует
+		w.Header().Set("Content-Security-Policy", "default-src 'none'; style-src 'unsafe-inline'; img-src https: data:; frame-ancestors 'none'")
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte(rendered.HTML))
+		return
+	}
+
+	WriteSuccessResponse(w, rendered)
+}
@@ -4,16 +4,20 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"net/http"
 	"strconv"
 	"strings"
+	"time"
+	"unicode/utf8"
 
 	"github.com/vterdunov/learn-bank-app/internal/utils"
 )
 
 var (
-	ErrInvalidJSON      = errors.New("invalid JSON format")
-	ErrValidationFailed = errors.New("validation failed")
+	ErrInvalidJSON         = errors.New("invalid JSON format")
+	ErrValidationFailed    = errors.New("validation failed")
+	ErrRequestBodyTooLarge = errors.New("request body too large")
 )
 
 // FieldError представляет ошибку валидации поля
@@ -44,9 +48,18 @@ func ValidateJSON(r *http.Request, dst interface{}) error {
 	decoder.DisallowUnknownFields()
 
 	if err := decoder.Decode(dst); err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			return ErrRequestBodyTooLarge
+		}
 		return fmt.Errorf("%w: %v", ErrInvalidJSON, err)
 	}
 
+	// Тело должно содержать ровно один JSON объект: данные после него - признак склейки или подмены запроса
+	if err := decoder.Decode(&struct{}{}); err != io.EOF {
+		return fmt.Errorf("%w: unexpected data after JSON value", ErrInvalidJSON)
+	}
+
 	return nil
 }
 
@@ -64,19 +77,65 @@ func Validate(dto interface{}) *ValidationError {
 	case *DepositRequest:
 		errors = validateAmountRequest(v.Amount, "amount")
 	case *WithdrawRequest:
-		errors = validateAmountRequest(v.Amount, "amount")
+		errors = validateWithdrawRequest(v)
 	case *TransferRequest:
 		errors = validateTransferRequest(v)
+	case *SetOverdraftRequest:
+		errors = validateSetOverdraftRequest(v)
+	case *CloseAccountRequest:
+		errors = validateCloseAccountRequest(v)
+	case *AddAccountHolderRequest:
+		errors = validateAddAccountHolderRequest(v)
 	case *CreateCardRequest:
 		errors = validateCreateCardRequest(v)
 	case *CardPaymentRequest:
 		errors = validateCardPaymentRequest(v)
+	case *CardHoldAmountRequest:
+		errors = validateCardHoldAmountRequest(v)
+	case *CardDetailsPaymentRequest:
+		errors = validateCardDetailsPaymentRequest(v)
+	case *SetCardPINRequest:
+		errors = validatePIN(v.PIN, "pin")
+	case *ChangeCardPINRequest:
+		errors = append(validatePIN(v.OldPIN, "old_pin"), validatePIN(v.NewPIN, "new_pin")...)
+	case *CardRevealTokenRequest:
+		errors = validateCardRevealTokenRequest(v)
+	case *CardRevealRequest:
+		errors = validateCardRevealRequest(v)
 	case *CreateCreditRequest:
 		errors = validateCreateCreditRequest(v)
 	case *MonthlyStatsRequest:
 		errors = validateMonthlyStatsRequest(v)
 	case *BalancePredictionRequest:
 		errors = validateBalancePredictionRequest(v)
+	case *UpdateNotificationPreferencesRequest:
+		errors = validateUpdateNotificationPreferencesRequest(v)
+	case *SetNotificationEndpointRequest:
+		errors = validateSetNotificationEndpointRequest(v)
+	case *SetNotificationLocaleRequest:
+		errors = validateSetNotificationLocaleRequest(v)
+	case *OpenDepositRequest:
+		errors = validateOpenDepositRequest(v)
+	case *StandingOrderRequest:
+		errors = validateStandingOrderRequest(v)
+	case *RecipientLookupRequest:
+		errors = validateRecipientLookupRequest(v)
+	case *CreatePaymentAliasRequest:
+		errors = validateCreatePaymentAliasRequest(v)
+	case *CreateMerchantRequest:
+		errors = validateCreateMerchantRequest(v)
+	case *CreateCashbackRuleRequest:
+		errors = validateCreateCashbackRuleRequest(v)
+	case *ResolveFraudReviewRequest:
+		errors = validateResolveFraudReviewRequest(v)
+	case *ResolveSanctionsHitRequest:
+		errors = validateResolveSanctionsHitRequest(v)
+	case *UpdateKYCProfileRequest:
+		errors = validateUpdateKYCProfileRequest(v)
+	case *VerifyKYCProfileRequest:
+		errors = validateVerifyKYCProfileRequest(v)
+	case *RejectKYCProfileRequest:
+		errors = validateRejectKYCProfileRequest(v)
 	}
 
 	if len(errors) > 0 {
@@ -196,6 +255,109 @@ func validateAmountRequest(amount float64, fieldName string) []FieldError {
 	return errors
 }
 
+func validateWithdrawRequest(req *WithdrawRequest) []FieldError {
+	errors := validateAmountRequest(req.Amount, "amount")
+
+	if req.CardID != "" {
+		errors = append(errors, validatePIN(req.PIN, "pin")...)
+	} else if req.PIN != "" {
+		errors = append(errors, FieldError{
+			Field:   "card_id",
+			Message: "card_id is required with pin",
+		})
+	}
+
+	return errors
+}
+
+func validateSetOverdraftRequest(req *SetOverdraftRequest) []FieldError {
+	var errors []FieldError
+
+	if req.Limit < 0 {
+		errors = append(errors, FieldError{
+			Field:   "limit",
+			Message: "limit must not be negative",
+		})
+	}
+
+	return errors
+}
+
+func validateCloseAccountRequest(req *CloseAccountRequest) []FieldError {
+	var errors []FieldError
+
+	if req.TransferToAccountID != "" && !isNumeric(req.TransferToAccountID) {
+		errors = append(errors, FieldError{
+			Field:   "transfer_to_account_id",
+			Message: "transfer_to_account_id must be a numeric account ID",
+		})
+	}
+
+	return errors
+}
+
+func validateAddAccountHolderRequest(req *AddAccountHolderRequest) []FieldError {
+	var errors []FieldError
+
+	if req.Email == "" {
+		errors = append(errors, FieldError{
+			Field:   "email",
+			Message: "email is required",
+		})
+	} else if utils.ValidateEmail(req.Email) != nil {
+		errors = append(errors, FieldError{
+			Field:   "email",
+			Message: "invalid email format",
+		})
+	}
+
+	switch req.Role {
+	case "joint":
+		if len(req.Permissions) > 0 {
+			errors = append(errors, FieldError{
+				Field:   "permissions",
+				Message: "permissions are not allowed for joint holders",
+			})
+		}
+	case "secondary":
+		for _, permission := range req.Permissions {
+			if permission != "view" && permission != "operate" && permission != "manage" {
+				errors = append(errors, FieldError{
+					Field:   "permissions",
+					Message: "permissions must be one of: view, operate, manage",
+				})
+				break
+			}
+		}
+	default:
+		errors = append(errors, FieldError{
+			Field:   "role",
+			Message: "role must be one of: joint, secondary",
+		})
+	}
+
+	return errors
+}
+
+// validatePIN проверяет формат PIN карты: 4–6 цифр
+func validatePIN(pin, fieldName string) []FieldError {
+	var errors []FieldError
+
+	if pin == "" {
+		errors = append(errors, FieldError{
+			Field:   fieldName,
+			Message: fieldName + " is required",
+		})
+	} else if len(pin) < 4 || len(pin) > 6 || !isNumeric(pin) {
+		errors = append(errors, FieldError{
+			Field:   fieldName,
+			Message: fieldName + " must contain 4 to 6 digits",
+		})
+	}
+
+	return errors
+}
+
 func validateTransferRequest(req *TransferRequest) []FieldError {
 	var errors []FieldError
 
@@ -206,12 +368,7 @@ func validateTransferRequest(req *TransferRequest) []FieldError {
 		})
 	}
 
-	if req.ToAccountID == "" {
-		errors = append(errors, FieldError{
-			Field:   "to_account_id",
-			Message: "to_account_id is required",
-		})
-	}
+	errors = append(errors, validateRecipientLookupRequest(&req.RecipientLookupRequest)...)
 
 	if req.Amount <= 0 {
 		errors = append(errors, FieldError{
@@ -240,15 +397,43 @@ func validateCreateCardRequest(req *CreateCardRequest) []FieldError {
 		})
 	}
 
-	if req.CardType == "" {
+	switch req.CardType {
+	case "", "physical", "virtual", "single_use":
+	default:
 		errors = append(errors, FieldError{
 			Field:   "card_type",
-			Message: "card_type is required",
+			Message: "card_type must be 'physical', 'virtual' or 'single_use'",
 		})
-	} else if req.CardType != "debit" && req.CardType != "credit" {
+	}
+
+	if req.ValidityDays < 0 || req.ValidityDays > 365 {
 		errors = append(errors, FieldError{
-			Field:   "card_type",
-			Message: "card_type must be 'debit' or 'credit'",
+			Field:   "validity_days",
+			Message: "validity_days must be between 1 and 365",
+		})
+	}
+
+	if len(strings.TrimSpace(req.MerchantLock)) > 100 {
+		errors = append(errors, FieldError{
+			Field:   "merchant_lock",
+			Message: "merchant_lock must not exceed 100 characters",
+		})
+	}
+
+	if req.PinCode != "" {
+		errors = append(errors, validatePIN(req.PinCode, "pin_code")...)
+	}
+
+	return errors
+}
+
+func validateCardHoldAmountRequest(req *CardHoldAmountRequest) []FieldError {
+	var errors []FieldError
+
+	if req.Amount < 0 {
+		errors = append(errors, FieldError{
+			Field:   "amount",
+			Message: "amount must not be negative",
 		})
 	}
 
@@ -297,6 +482,62 @@ func validateCardPaymentRequest(req *CardPaymentRequest) []FieldError {
 	return errors
 }
 
+func validateCardDetailsPaymentRequest(req *CardDetailsPaymentRequest) []FieldError {
+	errors := validateCardPaymentRequest(&CardPaymentRequest{
+		Amount:      req.Amount,
+		MerchantID:  req.MerchantID,
+		Description: req.Description,
+		CVV:         req.CVV,
+	})
+
+	if req.CardNumber == "" {
+		errors = append(errors, FieldError{
+			Field:   "card_number",
+			Message: "card_number is required",
+		})
+	} else if len(req.CardNumber) != 16 || !isNumeric(req.CardNumber) {
+		errors = append(errors, FieldError{
+			Field:   "card_number",
+			Message: "card_number must be 16 digits",
+		})
+	}
+
+	if req.ExpiryDate == "" {
+		errors = append(errors, FieldError{
+			Field:   "expiry_date",
+			Message: "expiry_date is required",
+		})
+	}
+
+	return errors
+}
+
+func validateCardRevealTokenRequest(req *CardRevealTokenRequest) []FieldError {
+	var errors []FieldError
+
+	if req.Password == "" {
+		errors = append(errors, FieldError{
+			Field:   "password",
+			Message: "password is required",
+		})
+	}
+
+	return errors
+}
+
+func validateCardRevealRequest(req *CardRevealRequest) []FieldError {
+	var errors []FieldError
+
+	if strings.TrimSpace(req.Token) == "" {
+		errors = append(errors, FieldError{
+			Field:   "token",
+			Message: "token is required",
+		})
+	}
+
+	return errors
+}
+
 func validateCreateCreditRequest(req *CreateCreditRequest) []FieldError {
 	var errors []FieldError
 
@@ -386,6 +627,412 @@ func validateBalancePredictionRequest(req *BalancePredictionRequest) []FieldErro
 	return errors
 }
 
+func validateUpdateNotificationPreferencesRequest(req *UpdateNotificationPreferencesRequest) []FieldError {
+	var errors []FieldError
+
+	if len(req.Preferences) == 0 {
+		errors = append(errors, FieldError{
+			Field:   "preferences",
+			Message: "preferences must not be empty",
+		})
+	}
+
+	for i, p := range req.Preferences {
+		if p.EventType == "" {
+			errors = append(errors, FieldError{
+				Field:   fmt.Sprintf("preferences[%d].event_type", i),
+				Message: "event_type is required",
+			})
+		}
+		if p.Channel == "" {
+			errors = append(errors, FieldError{
+				Field:   fmt.Sprintf("preferences[%d].channel", i),
+				Message: "channel is required",
+			})
+		}
+	}
+
+	return errors
+}
+
+func validateSetNotificationEndpointRequest(req *SetNotificationEndpointRequest) []FieldError {
+	var errors []FieldError
+
+	if req.Address == "" {
+		errors = append(errors, FieldError{
+			Field:   "address",
+			Message: "address is required",
+		})
+	}
+
+	return errors
+}
+
+func validateSetNotificationLocaleRequest(req *SetNotificationLocaleRequest) []FieldError {
+	var errors []FieldError
+
+	if req.Locale == "" {
+		errors = append(errors, FieldError{
+			Field:   "locale",
+			Message: "locale is required",
+		})
+	}
+
+	return errors
+}
+
+func validateOpenDepositRequest(req *OpenDepositRequest) []FieldError {
+	var errors []FieldError
+
+	if req.AccountID == "" {
+		errors = append(errors, FieldError{
+			Field:   "account_id",
+			Message: "account_id is required",
+		})
+	}
+
+	if req.Product == "" {
+		errors = append(errors, FieldError{
+			Field:   "product",
+			Message: "product is required",
+		})
+	}
+
+	if req.Amount <= 0 {
+		errors = append(errors, FieldError{
+			Field:   "amount",
+			Message: "amount must be positive",
+		})
+	}
+
+	if req.TermMonths < 0 {
+		errors = append(errors, FieldError{
+			Field:   "term_months",
+			Message: "term_months must not be negative",
+		})
+	}
+
+	return errors
+}
+
+func validateStandingOrderRequest(req *StandingOrderRequest) []FieldError {
+	var errors []FieldError
+
+	if req.FromAccountID == "" {
+		errors = append(errors, FieldError{
+			Field:   "from_account_id",
+			Message: "from_account_id is required",
+		})
+	}
+
+	if req.ToAccountID == "" {
+		errors = append(errors, FieldError{
+			Field:   "to_account_id",
+			Message: "to_account_id is required",
+		})
+	}
+
+	if req.Amount <= 0 {
+		errors = append(errors, FieldError{
+			Field:   "amount",
+			Message: "amount must be positive",
+		})
+	}
+
+	if len(req.Description) > 255 {
+		errors = append(errors, FieldError{
+			Field:   "description",
+			Message: "description must not exceed 255 characters",
+		})
+	}
+
+	if req.Schedule == "" {
+		errors = append(errors, FieldError{
+			Field:   "schedule",
+			Message: "schedule is required",
+		})
+	}
+
+	if req.StartDate == "" {
+		errors = append(errors, FieldError{
+			Field:   "start_date",
+			Message: "start_date is required",
+		})
+	}
+
+	return errors
+}
+
+func validateRecipientLookupRequest(req *RecipientLookupRequest) []FieldError {
+	var errors []FieldError
+
+	filled := 0
+	for _, value := range []string{req.ToAccountID, req.ToAccountNumber, req.ToEmail, req.ToPhone} {
+		if strings.TrimSpace(value) != "" {
+			filled++
+		}
+	}
+
+	if filled != 1 {
+		errors = append(errors, FieldError{
+			Field:   "to_account_number",
+			Message: "exactly one of to_account_id, to_account_number, to_email, to_phone is required",
+		})
+	}
+
+	if req.ToAccountID != "" && !isNumeric(req.ToAccountID) {
+		errors = append(errors, FieldError{
+			Field:   "to_account_id",
+			Message: "to_account_id must be numeric",
+		})
+	}
+
+	if req.ToEmail != "" {
+		if err := utils.ValidateEmail(req.ToEmail); err != nil {
+			errors = append(errors, FieldError{
+				Field:   "to_email",
+				Message: "to_email must be a valid email",
+			})
+		}
+	}
+
+	return errors
+}
+
+func validateCreatePaymentAliasRequest(req *CreatePaymentAliasRequest) []FieldError {
+	var errors []FieldError
+
+	if req.Type != "phone" && req.Type != "email" {
+		errors = append(errors, FieldError{
+			Field:   "type",
+			Message: "type must be one of: phone, email",
+		})
+	}
+
+	if strings.TrimSpace(req.Value) == "" {
+		errors = append(errors, FieldError{
+			Field:   "value",
+			Message: "value is required",
+		})
+	}
+
+	if req.AccountID == "" {
+		errors = append(errors, FieldError{
+			Field:   "account_id",
+			Message: "account_id is required",
+		})
+	}
+
+	return errors
+}
+
+func validateCreateMerchantRequest(req *CreateMerchantRequest) []FieldError {
+	var errors []FieldError
+
+	if code := strings.TrimSpace(req.Code); code == "" || len(code) > 100 {
+		errors = append(errors, FieldError{
+			Field:   "code",
+			Message: "code is required and must not exceed 100 characters",
+		})
+	}
+
+	if name := strings.TrimSpace(req.Name); name == "" || len(name) > 255 {
+		errors = append(errors, FieldError{
+			Field:   "name",
+			Message: "name is required and must not exceed 255 characters",
+		})
+	}
+
+	if len(req.MCC) != 4 || !isNumeric(req.MCC) {
+		errors = append(errors, FieldError{
+			Field:   "mcc",
+			Message: "mcc must contain 4 digits",
+		})
+	}
+
+	if req.SettlementAccountID == "" {
+		errors = append(errors, FieldError{
+			Field:   "settlement_account_id",
+			Message: "settlement_account_id is required",
+		})
+	}
+
+	return errors
+}
+
+func validateCreateCashbackRuleRequest(req *CreateCashbackRuleRequest) []FieldError {
+	var errors []FieldError
+
+	if (req.MCC == "") == (req.Category == "") {
+		errors = append(errors, FieldError{
+			Field:   "mcc",
+			Message: "exactly one of mcc or category is required",
+		})
+	} else if req.MCC != "" && (len(req.MCC) != 4 || !isNumeric(req.MCC)) {
+		errors = append(errors, FieldError{
+			Field:   "mcc",
+			Message: "mcc must contain 4 digits",
+		})
+	}
+
+	if req.Rate < 0 || req.Rate > 100 {
+		errors = append(errors, FieldError{
+			Field:   "rate",
+			Message: "rate must be between 0 and 100",
+		})
+	}
+
+	if req.MonthlyCap < 0 {
+		errors = append(errors, FieldError{
+			Field:   "monthly_cap",
+			Message: "monthly_cap must not be negative",
+		})
+	}
+
+	return errors
+}
+
+func validateResolveFraudReviewRequest(req *ResolveFraudReviewRequest) []FieldError {
+	var errors []FieldError
+
+	if len(req.Comment) > 1000 {
+		errors = append(errors, FieldError{
+			Field:   "comment",
+			Message: "comment must not exceed 1000 characters",
+		})
+	}
+
+	return errors
+}
+
+func validateResolveSanctionsHitRequest(req *ResolveSanctionsHitRequest) []FieldError {
+	var errors []FieldError
+
+	if len(req.Comment) > 1000 {
+		errors = append(errors, FieldError{
+			Field:   "comment",
+			Message: "comment must not exceed 1000 characters",
+		})
+	}
+
+	return errors
+}
+
+func validateUpdateKYCProfileRequest(req *UpdateKYCProfileRequest) []FieldError {
+	var errors []FieldError
+
+	for _, field := range []struct {
+		name     string
+		value    string
+		required bool
+		maxLen   int
+	}{
+		{"last_name", req.LastName, true, 100},
+		{"first_name", req.FirstName, true, 100},
+		{"middle_name", req.MiddleName, false, 100},
+		{"birth_place", req.BirthPlace, false, 255},
+		{"passport_issued_by", req.PassportIssuedBy, true, 255},
+		{"address", req.Address, true, 500},
+	} {
+		value := strings.TrimSpace(field.value)
+		if field.required && value == "" {
+			errors = append(errors, FieldError{
+				Field:   field.name,
+				Message: field.name + " is required",
+			})
+		} else if utf8.RuneCountInString(value) > field.maxLen {
+			errors = append(errors, FieldError{
+				Field:   field.name,
+				Message: fmt.Sprintf("%s must not exceed %d characters", field.name, field.maxLen),
+			})
+		}
+	}
+
+	for _, field := range []struct{ name, value string }{
+		{"birth_date", req.BirthDate},
+		{"passport_issue_date", req.PassportIssueDate},
+	} {
+		if _, err := time.Parse(time.DateOnly, field.value); err != nil {
+			errors = append(errors, FieldError{
+				Field:   field.name,
+				Message: field.name + " must be a date in YYYY-MM-DD format",
+			})
+		}
+	}
+
+	if err := utils.ValidatePassport(req.PassportSeries, req.PassportNumber, req.PassportDivisionCode); err != nil {
+		errors = append(errors, FieldError{
+			Field:   "passport",
+			Message: "passport_series must be 4 digits, passport_number 6 digits and passport_division_code in XXX-XXX format",
+		})
+	}
+
+	if req.INN == "" && req.SNILS == "" {
+		errors = append(errors, FieldError{
+			Field:   "inn",
+			Message: "inn or snils is required",
+		})
+	}
+
+	if req.INN != "" {
+		if err := utils.ValidateINN(req.INN); err != nil || len(req.INN) != 12 {
+			errors = append(errors, FieldError{
+				Field:   "inn",
+				Message: "inn must be a valid 12-digit personal INN",
+			})
+		}
+	}
+
+	if req.SNILS != "" {
+		if err := utils.ValidateSNILS(utils.DigitsOnly(req.SNILS)); err != nil {
+			errors = append(errors, FieldError{
+				Field:   "snils",
+				Message: "snils must be 11 digits with a valid checksum",
+			})
+		}
+	}
+
+	if _, err := utils.NormalizePhone(req.Phone); err != nil {
+		errors = append(errors, FieldError{
+			Field:   "phone",
+			Message: "phone must be a Russian phone number",
+		})
+	}
+
+	return errors
+}
+
+func validateVerifyKYCProfileRequest(req *VerifyKYCProfileRequest) []FieldError {
+	var errors []FieldError
+
+	if req.Level != "simplified" && req.Level != "full" {
+		errors = append(errors, FieldError{
+			Field:   "level",
+			Message: "level must be one of: simplified, full",
+		})
+	}
+
+	return errors
+}
+
+func validateRejectKYCProfileRequest(req *RejectKYCProfileRequest) []FieldError {
+	var errors []FieldError
+
+	if strings.TrimSpace(req.Reason) == "" {
+		errors = append(errors, FieldError{
+			Field:   "reason",
+			Message: "reason is required",
+		})
+	} else if len(req.Reason) > 1000 {
+		errors = append(errors, FieldError{
+			Field:   "reason",
+			Message: "reason must not exceed 1000 characters",
+		})
+	}
+
+	return errors
+}
+
 // Helper functions
 func isNumeric(s string) bool {
 	_, err := strconv.Atoi(s)
@@ -394,6 +1041,12 @@ func isNumeric(s string) bool {
 
 // WriteErrorResponse записывает ошибку в HTTP ответ
 func WriteErrorResponse(w http.ResponseWriter, statusCode int, err error) {
+	// Превышение лимита тела запроса всегда 413, независимо от статуса, выбранного обработчиком
+	var maxBytesErr *http.MaxBytesError
+	if errors.Is(err, ErrRequestBodyTooLarge) || errors.As(err, &maxBytesErr) {
+		statusCode = http.StatusRequestEntityTooLarge
+	}
+
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(statusCode)
 
@@ -9,6 +9,8 @@ import (
 
 	"github.com/golang-jwt/jwt/v5"
 
+	"github.com/vterdunov/learn-bank-app/internal/domain"
+	"github.com/vterdunov/learn-bank-app/internal/utils"
 	"github.com/vterdunov/learn-bank-app/pkg/logger"
 )
 
@@ -23,7 +25,7 @@ const (
 )
 
 // AuthMiddleware middleware для проверки JWT токенов
-func AuthMiddleware(jwtSecret string) func(http.Handler) http.Handler {
+func AuthMiddleware(keys *utils.KeyRing) func(http.Handler) http.Handler {
 	log := logger.NewDefault()
 
 	return func(next http.Handler) http.Handler {
@@ -56,13 +58,9 @@ func AuthMiddleware(jwtSecret string) func(http.Handler) http.Handler {
 
 			// Парсим и валидируем токен
 			claims := &jwt.RegisteredClaims{}
-			token, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
-				// Проверяем алгоритм подписи
-				if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-					return nil, jwt.ErrSignatureInvalid
-				}
-				return []byte(jwtSecret), nil
-			})
+			// Ключ проверки выбирается по kid, алгоритм должен совпадать с алгоритмом ключа
+			token, err := jwt.ParseWithClaims(tokenString, claims, keys.Keyfunc(),
+				jwt.WithValidMethods(keys.ValidMethods()))
 
 			if err != nil {
 				log.Warn("Invalid JWT token",
@@ -124,6 +122,41 @@ func AuthMiddleware(jwtSecret string) func(http.Handler) http.Handler {
 	}
 }
 
+// UserRoleProvider источник данных пользователя для проверки роли
+type UserRoleProvider interface {
+	GetByID(ctx context.Context, id int) (*domain.User, error)
+}
+
+// RequireAdminMiddleware пропускает только администраторов.
+// Должен стоять после AuthMiddleware; роль читается из БД, чтобы отзыв прав действовал сразу.
+func RequireAdminMiddleware(users UserRoleProvider) func(http.Handler) http.Handler {
+	log := logger.NewDefault()
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			userID, ok := GetUserIDFromContext(r.Context())
+			if !ok {
+				http.Error(w, "Unauthorized", http.StatusUnauthorized)
+				return
+			}
+
+			user, err := users.GetByID(r.Context(), userID)
+			if err != nil || !user.IsAdmin() {
+				log.Warn("Admin access denied",
+					slog.Int("user_id", userID),
+					slog.String("method", r.Method),
+					slog.String("path", r.URL.Path),
+					slog.String("remote_addr", r.RemoteAddr),
+				)
+				http.Error(w, "Forbidden", http.StatusForbidden)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
 // GetUserIDFromContext извлекает ID пользователя из контекста
 func GetUserIDFromContext(ctx context.Context) (int, bool) {
 	userID, ok := ctx.Value(UserIDKey).(int)
@@ -3,17 +3,22 @@ package middleware
 import (
 	"context"
 	"log/slog"
+	"net"
 	"net/http"
+	"net/netip"
+	"strings"
 	"time"
 
+	"github.com/vterdunov/learn-bank-app/internal/domain"
 	"github.com/vterdunov/learn-bank-app/pkg/logger"
 )
 
 // ResponseWriter wrapper для захвата статус кода
 type responseWriter struct {
 	http.ResponseWriter
-	statusCode int
-	written    int
+	statusCode  int
+	written     int
+	wroteHeader bool
 }
 
 func newResponseWriter(w http.ResponseWriter) *responseWriter {
@@ -25,15 +30,22 @@ func newResponseWriter(w http.ResponseWriter) *responseWriter {
 
 func (rw *responseWriter) WriteHeader(code int) {
 	rw.statusCode = code
+	rw.wroteHeader = true
 	rw.ResponseWriter.WriteHeader(code)
 }
 
 func (rw *responseWriter) Write(b []byte) (int, error) {
+	rw.wroteHeader = true
 	n, err := rw.ResponseWriter.Write(b)
 	rw.written += n
 	return n, err
 }
 
+// Unwrap открывает исходный ResponseWriter для http.ResponseController
+func (rw *responseWriter) Unwrap() http.ResponseWriter {
+	return rw.ResponseWriter
+}
+
 // LoggingMiddleware логирует все HTTP запросы
 func LoggingMiddleware() func(http.Handler) http.Handler {
 	log := logger.NewDefault()
@@ -105,35 +117,13 @@ func LoggingMiddleware() func(http.Handler) http.Handler {
 	}
 }
 
-// ErrorLoggingMiddleware логирует ошибки с дополнительным контекстом
-func ErrorLoggingMiddleware(logger *slog.Logger) func(http.Handler) http.Handler {
-	return func(next http.Handler) http.Handler {
-		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			wrapped := newResponseWriter(w)
-
-			// Добавляем контекст запроса
-			ctx := r.Context()
-
-			defer func() {
-				// Логируем ошибки (статус коды >= 400)
-				if wrapped.statusCode >= 400 {
-					logger.Error("HTTP Error",
-						slog.String("method", r.Method),
-						slog.String("path", r.URL.Path),
-						slog.String("remote_addr", r.RemoteAddr),
-						slog.Int("status_code", wrapped.statusCode),
-						slog.Any("context", ctx),
-					)
-				}
-			}()
-
-			next.ServeHTTP(wrapped, r)
-		})
-	}
-}
+// GeoCountryHeader заголовок со страной клиента (ISO 3166-1 alpha-2), который выставляет балансировщик по GeoIP
+const GeoCountryHeader = "X-Geo-Country"
 
-// RequestIDMiddleware middleware для добавления ID запроса в контекст
-func RequestIDMiddleware() func(http.Handler) http.Handler {
+// RequestIDMiddleware middleware для добавления ID запроса в контекст.
+// Страна клиента берется из GeoCountryHeader, только если запрос пришел от балансировщика из trustedProxies:
+// клиент, обращающийся к API напрямую, может выставить заголовок сам
+func RequestIDMiddleware(trustedProxies []netip.Prefix) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			// Генерируем простой ID запроса на основе времени
@@ -145,7 +135,77 @@ func RequestIDMiddleware() func(http.Handler) http.Handler {
 			// Добавляем ID запроса в контекст
 			ctx := context.WithValue(r.Context(), RequestIDKey, requestID)
 
+			// Метаданные запроса для журнала аудита
+			ctx = domain.ContextWithRequestMeta(ctx, domain.RequestMeta{
+				RequestID: requestID,
+				IP:        clientIP(r),
+				UserAgent: r.UserAgent(),
+				Country:   geoCountry(r, trustedProxies),
+			})
+
 			next.ServeHTTP(w, r.WithContext(ctx))
 		})
 	}
 }
+
+// geoCountry возвращает страну из GeoCountryHeader, если непосредственный отправитель запроса — доверенный балансировщик
+func geoCountry(r *http.Request, trustedProxies []netip.Prefix) string {
+	if !fromTrustedProxy(r, trustedProxies) {
+		return ""
+	}
+	return strings.ToUpper(strings.TrimSpace(r.Header.Get(GeoCountryHeader)))
+}
+
+// ForwardedForHeader заголовок с цепочкой адресов клиента и промежуточных прокси, который дополняет балансировщик
+const ForwardedForHeader = "X-Forwarded-For"
+
+// forwardedClientIP возвращает IP клиента за доверенным балансировщиком: последний адрес ForwardedForHeader,
+// не входящий в trustedProxies. Адреса левее клиент может подставить сам, поэтому они не учитываются.
+// Если запрос пришел не от балансировщика или адрес не определить, возвращается адрес отправителя
+func forwardedClientIP(r *http.Request, trustedProxies []netip.Prefix) string {
+	if !fromTrustedProxy(r, trustedProxies) {
+		return clientIP(r)
+	}
+
+	var hops []string
+	for _, value := range r.Header.Values(ForwardedForHeader) {
+		hops = append(hops, strings.Split(value, ",")...)
+	}
+	for i := len(hops) - 1; i >= 0; i-- {
+		addr, err := netip.ParseAddr(strings.TrimSpace(hops[i]))
+		if err != nil {
+			break
+		}
+		if addr = addr.Unmap(); !isTrustedProxy(addr, trustedProxies) {
+			return addr.String()
+		}
+	}
+	return clientIP(r)
+}
+
+// fromTrustedProxy сообщает, что непосредственный отправитель запроса — балансировщик из trustedProxies
+func fromTrustedProxy(r *http.Request, trustedProxies []netip.Prefix) bool {
+	addr, err := netip.ParseAddr(clientIP(r))
+	if err != nil {
+		return false
+	}
+	return isTrustedProxy(addr.Unmap(), trustedProxies)
+}
+
+func isTrustedProxy(addr netip.Addr, trustedProxies []netip.Prefix) bool {
+	for _, prefix := range trustedProxies {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// clientIP возвращает IP адрес клиента без порта
+func clientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
@@ -1,10 +1,36 @@
 package middleware
 
 import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
 	"log/slog"
 	"net/http"
+	"runtime/debug"
 )
 
+// ErrorIDHeader заголовок с ID ошибки, по которому запрос находится в логах
+const ErrorIDHeader = "X-Error-ID"
+
+// errorResponse ответ с ошибкой в формате API, как у handlers.WriteErrorResponse
+type errorResponse struct {
+	Success bool        `json:"success"`
+	Error   errorDetail `json:"error"`
+}
+
+type errorDetail struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+	ErrorID string `json:"error_id,omitempty"`
+}
+
+// writeJSONError отвечает ошибкой в формате API
+func writeJSONError(w http.ResponseWriter, statusCode int, detail errorDetail) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+	_ = json.NewEncoder(w).Encode(errorResponse{Error: detail})
+}
+
 // Chain helper для объединения middleware
 func Chain(middlewares ...func(http.Handler) http.Handler) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
@@ -15,25 +41,56 @@ func Chain(middlewares ...func(http.Handler) http.Handler) func(http.Handler) ht
 	}
 }
 
-// RecoveryMiddleware восстанавливается после паники
+// RecoveryMiddleware восстанавливается после паники: пишет в лог стек с ID ошибки и ID запроса
+// (если RequestIDMiddleware стоит раньше) и возвращает клиенту 500 с тем же ID ошибки, не раскрывая подробностей
 func RecoveryMiddleware(logger *slog.Logger) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			wrapped := newResponseWriter(w)
+
 			defer func() {
-				if err := recover(); err != nil {
-					logger.Error("Panic recovered",
-						slog.Any("error", err),
-						slog.String("method", r.Method),
-						slog.String("path", r.URL.Path),
-						slog.String("remote_addr", r.RemoteAddr),
-					)
-
-					// Возвращаем 500 ошибку
-					http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+				err := recover()
+				if err == nil {
+					return
 				}
+				// Обрыв ответа по http.ErrAbortHandler намеренный, его обрабатывает net/http
+				if err == http.ErrAbortHandler {
+					panic(err)
+				}
+
+				errorID := newErrorID()
+				requestID, _ := GetRequestIDFromContext(r.Context())
+				logger.Error("Panic recovered",
+					slog.String("error_id", errorID),
+					slog.String("request_id", requestID),
+					slog.Any("error", err),
+					slog.String("method", r.Method),
+					slog.String("path", r.URL.Path),
+					slog.String("remote_addr", r.RemoteAddr),
+					slog.String("stack", string(debug.Stack())),
+				)
+
+				// Ответ уже начат: 500 не отправить, обрываем соединение, чтобы клиент не принял неполный ответ за успешный
+				if wrapped.wroteHeader {
+					panic(http.ErrAbortHandler)
+				}
+
+				w.Header().Set(ErrorIDHeader, errorID)
+				writeJSONError(w, http.StatusInternalServerError, errorDetail{
+					Code:    "internal_error",
+					Message: "Internal Server Error",
+					ErrorID: errorID,
+				})
 			}()
 
-			next.ServeHTTP(w, r)
+			next.ServeHTTP(wrapped, r)
 		})
 	}
 }
+
+// newErrorID генерирует случайный ID ошибки
+func newErrorID() string {
+	b := make([]byte, 8)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,125 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"testing"
+
+	"github.com/vterdunov/learn-bank-app/internal/domain"
+)
+
+func TestRecoveryMiddleware(t *testing.T) {
+	tests := []struct {
+		name       string
+		handler    http.HandlerFunc
+		wantStatus int
+		wantPanic  bool
+	}{
+		{
+			name:       "no panic",
+			handler:    okHandler,
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "panic before response",
+			handler:    func(w http.ResponseWriter, r *http.Request) { panic("boom") },
+			wantStatus: http.StatusInternalServerError,
+		},
+		{
+			name: "panic after response started",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusOK)
+				panic("boom")
+			},
+			wantPanic: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var logs bytes.Buffer
+			logger := slog.New(slog.NewJSONHandler(&logs, nil))
+			handler := Chain(RequestIDMiddleware(nil), RecoveryMiddleware(logger))(tt.handler)
+
+			rec := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/accounts", nil)
+
+			if tt.wantPanic {
+				defer func() {
+					if err := recover(); err != http.ErrAbortHandler {
+						t.Errorf("expected http.ErrAbortHandler, got %v", err)
+					}
+				}()
+			}
+			handler.ServeHTTP(rec, req)
+			if tt.wantPanic {
+				t.Fatal("expected response to be aborted")
+			}
+
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("expected status %d, got %d", tt.wantStatus, rec.Code)
+			}
+			if tt.wantStatus != http.StatusInternalServerError {
+				return
+			}
+
+			body := decodeError(t, rec)
+			errorID := rec.Header().Get(ErrorIDHeader)
+			if errorID == "" || body.Error.ErrorID != errorID || body.Error.Code != "internal_error" {
+				t.Fatalf("expected error_id %q in header and body, got %+v", errorID, body.Error)
+			}
+
+			var entry map[string]any
+			if err := json.Unmarshal(logs.Bytes(), &entry); err != nil {
+				t.Fatalf("failed to decode panic log %q: %v", logs.String(), err)
+			}
+			if entry["error_id"] != errorID {
+				t.Errorf("expected error_id %q in log, got %v", errorID, entry["error_id"])
+			}
+			if requestID := rec.Header().Get("X-Request-ID"); requestID == "" || entry["request_id"] != requestID {
+				t.Errorf("expected request_id %q in log, got %v", requestID, entry["request_id"])
+			}
+			if entry["stack"] == "" {
+				t.Error("expected stack in panic log")
+			}
+		})
+	}
+}
+
+func TestRequestIDMiddleware_GeoCountry(t *testing.T) {
+	proxies := []netip.Prefix{netip.MustParsePrefix("10.0.0.0/8")}
+
+	tests := []struct {
+		name       string
+		remoteAddr string
+		want       string
+	}{
+		{name: "trusted proxy", remoteAddr: "10.1.2.3:5000", want: "RU"},
+		{name: "direct client", remoteAddr: "203.0.113.7:5000", want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var meta domain.RequestMeta
+			handler := RequestIDMiddleware(proxies)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				meta, _ = domain.RequestMetaFromContext(r.Context())
+			}))
+
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.RemoteAddr = tt.remoteAddr
+			req.Header.Set(GeoCountryHeader, " ru ")
+			handler.ServeHTTP(httptest.NewRecorder(), req)
+
+			if meta.Country != tt.want {
+				t.Errorf("expected country %q, got %q", tt.want, meta.Country)
+			}
+			if meta.RequestID == "" {
+				t.Error("expected request ID in request metadata")
+			}
+		})
+	}
+}
@@ -0,0 +1,206 @@
+package middleware
+
+import (
+	"context"
+	"log/slog"
+	"math"
+	"net/http"
+	"net/netip"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/vterdunov/learn-bank-app/internal/config"
+	"github.com/vterdunov/learn-bank-app/internal/utils"
+)
+
+// rateLimitCleanupTimeout ограничивает фоновую очистку корзин
+const rateLimitCleanupTimeout = 30 * time.Second
+
+// RateLimitStore хранилище корзин token bucket
+type RateLimitStore interface {
+	// Take атомарно восполняет корзину key и пытается взять из нее токен
+	Take(ctx context.Context, key string, limit utils.RateLimit, now time.Time) (utils.RateLimitDecision, error)
+	// DeleteExpired удаляет корзины, полностью восполнившиеся к now: они эквивалентны отсутствующим
+	DeleteExpired(ctx context.Context, now time.Time) (int64, error)
+}
+
+// MemoryRateLimitStore хранит корзины в памяти экземпляра; при нескольких экземплярах лимит действует на каждый отдельно
+type MemoryRateLimitStore struct {
+	mu      sync.Mutex
+	buckets map[string]*memoryBucket
+}
+
+type memoryBucket struct {
+	bucket    utils.TokenBucket
+	expiresAt time.Time
+}
+
+// NewMemoryRateLimitStore создает хранилище корзин в памяти
+func NewMemoryRateLimitStore() *MemoryRateLimitStore {
+	return &MemoryRateLimitStore{buckets: make(map[string]*memoryBucket)}
+}
+
+// Take берет токен из корзины key, создавая полную корзину при первом обращении
+func (s *MemoryRateLimitStore) Take(_ context.Context, key string, limit utils.RateLimit, now time.Time) (utils.RateLimitDecision, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entry, ok := s.buckets[key]
+	if !ok {
+		entry = &memoryBucket{bucket: utils.NewTokenBucket(limit, now)}
+		s.buckets[key] = entry
+	}
+
+	decision := entry.bucket.Take(limit, now)
+	entry.expiresAt = entry.bucket.FullAt(limit)
+	return decision, nil
+}
+
+// DeleteExpired удаляет полностью восполненные корзины
+func (s *MemoryRateLimitStore) DeleteExpired(_ context.Context, now time.Time) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var deleted int64
+	for key, entry := range s.buckets {
+		if !entry.expiresAt.After(now) {
+			delete(s.buckets, key)
+			deleted++
+		}
+	}
+	return deleted, nil
+}
+
+// RateLimiter применяет лимиты групп маршрутов из конфигурации
+type RateLimiter struct {
+	store           RateLimitStore
+	routes          map[string]utils.RateLimit
+	cleanupInterval time.Duration
+	// trustedProxies балансировщики, за которыми IP клиента берется из ForwardedForHeader
+	trustedProxies []netip.Prefix
+	clock          utils.Clock
+	logger         *slog.Logger
+	// lastCleanup время последней очистки корзин в UnixNano
+	lastCleanup atomic.Int64
+}
+
+// NewRateLimiter создает ограничитель запросов по конфигурации групп маршрутов.
+// Анонимные клиенты за балансировщиками из trustedProxies различаются по адресу из ForwardedForHeader
+func NewRateLimiter(
+	cfg config.RateLimitConfig,
+	trustedProxies []netip.Prefix,
+	store RateLimitStore,
+	clock utils.Clock,
+	logger *slog.Logger,
+) *RateLimiter {
+	routes := make(map[string]utils.RateLimit, len(cfg.Routes))
+	for name, route := range cfg.Routes {
+		routes[name] = utils.RateLimit{Requests: route.Requests, Period: route.Period}
+	}
+
+	limiter := &RateLimiter{
+		store:           store,
+		routes:          routes,
+		cleanupInterval: cfg.CleanupInterval,
+		trustedProxies:  trustedProxies,
+		clock:           clock,
+		logger:          logger,
+	}
+	limiter.lastCleanup.Store(clock.Now().UnixNano())
+	return limiter
+}
+
+// RateLimitMiddleware ограничивает частоту запросов группы маршрутов group.
+// Корзина заводится на каждый маршрут и клиента: аутентифицированного — по ID пользователя,
+// иначе по IP адресу (за доверенным балансировщиком — по адресу из ForwardedForHeader). Поэтому для групп с аутентификацией middleware ставится после AuthMiddleware.
+// Ошибка хранилища не блокирует запрос: недоступность лимитера не должна останавливать API.
+func RateLimitMiddleware(limiter *RateLimiter, group string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		if limiter == nil {
+			return next
+		}
+		limit, ok := limiter.routes[group]
+		if !ok || !limit.Enabled() {
+			return next
+		}
+
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			now := limiter.clock.Now()
+			key := group + "|" + r.Pattern + "|" + limiter.clientKey(r)
+
+			decision, err := limiter.store.Take(r.Context(), key, limit, now)
+			if err != nil {
+				limiter.logger.Error("Rate limit store failed, request allowed",
+					slog.String("group", group),
+					slog.String("error", err.Error()),
+				)
+				next.ServeHTTP(w, r)
+				return
+			}
+			limiter.cleanupIfDue(now)
+
+			header := w.Header()
+			header.Set("RateLimit-Limit", strconv.Itoa(limit.Requests))
+			header.Set("RateLimit-Remaining", strconv.Itoa(decision.Remaining))
+			header.Set("RateLimit-Reset", strconv.Itoa(ceilSeconds(decision.Reset)))
+			header.Set("RateLimit-Policy", strconv.Itoa(limit.Requests)+";w="+strconv.Itoa(ceilSeconds(limit.Period)))
+
+			if !decision.Allowed {
+				header.Set("Retry-After", strconv.Itoa(ceilSeconds(decision.RetryAfter)))
+				limiter.logger.Warn("Rate limit exceeded",
+					slog.String("group", group),
+					slog.String("route", r.Pattern),
+					slog.String("remote_addr", r.RemoteAddr),
+				)
+				writeJSONError(w, http.StatusTooManyRequests, errorDetail{
+					Code:    "too_many_requests",
+					Message: "too many requests",
+				})
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// cleanupIfDue раз в CleanupInterval удаляет восполненные корзины в фоне; запускается одним запросом из всех
+func (l *RateLimiter) cleanupIfDue(now time.Time) {
+	if l.cleanupInterval <= 0 {
+		return
+	}
+	last := l.lastCleanup.Load()
+	if now.Sub(time.Unix(0, last)) < l.cleanupInterval || !l.lastCleanup.CompareAndSwap(last, now.UnixNano()) {
+		return
+	}
+
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), rateLimitCleanupTimeout)
+		defer cancel()
+
+		deleted, err := l.store.DeleteExpired(ctx, now)
+		if err != nil {
+			l.logger.Error("Failed to delete expired rate limit buckets", slog.String("error", err.Error()))
+			return
+		}
+		if deleted > 0 {
+			l.logger.Debug("Expired rate limit buckets deleted", slog.Int64("count", deleted))
+		}
+	}()
+}
+
+// clientKey идентифицирует клиента: пользователя, если запрос аутентифицирован, иначе IP адрес.
+// За доверенным балансировщиком у всех запросов один RemoteAddr, поэтому адрес берется из ForwardedForHeader
+func (l *RateLimiter) clientKey(r *http.Request) string {
+	if userID, ok := GetUserIDFromContext(r.Context()); ok {
+		return "user:" + strconv.Itoa(userID)
+	}
+	return "ip:" + forwardedClientIP(r, l.trustedProxies)
+}
+
+// ceilSeconds округляет длительность вверх до целых секунд, как требуют заголовки Retry-After и RateLimit-*
+func ceilSeconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
@@ -0,0 +1,239 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"testing"
+	"time"
+
+	"github.com/vterdunov/learn-bank-app/internal/config"
+	"github.com/vterdunov/learn-bank-app/internal/utils"
+)
+
+// fakeClock часы с ручным управлением временем
+type fakeClock struct {
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time { return c.now }
+
+// failingRateLimitStore хранилище, всегда возвращающее ошибку
+type failingRateLimitStore struct{}
+
+func (failingRateLimitStore) Take(context.Context, string, utils.RateLimit, time.Time) (utils.RateLimitDecision, error) {
+	return utils.RateLimitDecision{}, errors.New("store unavailable")
+}
+
+func (failingRateLimitStore) DeleteExpired(context.Context, time.Time) (int64, error) {
+	return 0, errors.New("store unavailable")
+}
+
+// testTrustedProxies подсеть балансировщика в тестах лимитера
+var testTrustedProxies = []netip.Prefix{netip.MustParsePrefix("10.0.0.0/8")}
+
+// newTestRateLimiter создает лимитер группы "api" на 2 запроса в минуту за балансировщиком из testTrustedProxies
+func newTestRateLimiter(store RateLimitStore, clock utils.Clock) *RateLimiter {
+	cfg := config.RateLimitConfig{
+		Routes: map[string]config.RateLimitRouteConfig{
+			"api": {Requests: 2, Period: time.Minute},
+		},
+	}
+	return NewRateLimiter(cfg, testTrustedProxies, store, clock, slog.New(slog.NewTextHandler(io.Discard, nil)))
+}
+
+// rateLimitRequest выполняет запрос к маршруту pattern от клиента remoteAddr или пользователя userID
+func rateLimitRequest(handler http.Handler, pattern, remoteAddr string, userID int) *httptest.ResponseRecorder {
+	return forwardedRateLimitRequest(handler, pattern, remoteAddr, "", userID)
+}
+
+// forwardedRateLimitRequest выполняет запрос с заголовком X-Forwarded-For, если forwardedFor не пуст
+func forwardedRateLimitRequest(handler http.Handler, pattern, remoteAddr, forwardedFor string, userID int) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/accounts", nil)
+	req.Pattern = pattern
+	req.RemoteAddr = remoteAddr
+	if forwardedFor != "" {
+		req.Header.Set(ForwardedForHeader, forwardedFor)
+	}
+	if userID != 0 {
+		req = req.WithContext(context.WithValue(req.Context(), UserIDKey, userID))
+	}
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestRateLimitMiddleware_Headers(t *testing.T) {
+	clock := &fakeClock{now: time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)}
+	handler := RateLimitMiddleware(newTestRateLimiter(NewMemoryRateLimitStore(), clock), "api")(okHandler)
+
+	tests := []struct {
+		name          string
+		advance       time.Duration
+		wantStatus    int
+		wantRemaining string
+		wantReset     string
+		wantRetry     string
+	}{
+		{name: "first request", wantStatus: http.StatusOK, wantRemaining: "1", wantReset: "30"},
+		{name: "second request", wantStatus: http.StatusOK, wantRemaining: "0", wantReset: "60"},
+		{name: "bucket empty", wantStatus: http.StatusTooManyRequests, wantRemaining: "0", wantReset: "60", wantRetry: "30"},
+		{name: "token refilled", advance: 30 * time.Second, wantStatus: http.StatusOK, wantRemaining: "0", wantReset: "60"},
+	}
+
+	for _, tt := range tests {
+		clock.now = clock.now.Add(tt.advance)
+		rec := rateLimitRequest(handler, "GET /api/v1/accounts", "203.0.113.7:5000", 0)
+
+		if rec.Code != tt.wantStatus {
+			t.Fatalf("%s: expected status %d, got %d", tt.name, tt.wantStatus, rec.Code)
+		}
+		want := map[string]string{
+			"RateLimit-Limit":     "2",
+			"RateLimit-Remaining": tt.wantRemaining,
+			"RateLimit-Reset":     tt.wantReset,
+			"RateLimit-Policy":    "2;w=60",
+			"Retry-After":         tt.wantRetry,
+		}
+		for header, value := range want {
+			if got := rec.Header().Get(header); got != value {
+				t.Errorf("%s: %s: expected %q, got %q", tt.name, header, value, got)
+			}
+		}
+	}
+}
+
+func TestRateLimitMiddleware_TooManyRequestsBody(t *testing.T) {
+	clock := &fakeClock{now: time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)}
+	handler := RateLimitMiddleware(newTestRateLimiter(NewMemoryRateLimitStore(), clock), "api")(okHandler)
+
+	for range 2 {
+		rateLimitRequest(handler, "GET /api/v1/accounts", "203.0.113.7:5000", 0)
+	}
+	rec := rateLimitRequest(handler, "GET /api/v1/accounts", "203.0.113.7:5000", 0)
+
+	if rec.Code != http.StatusTooManyRequests {
+		t.Fatalf("expected status 429, got %d", rec.Code)
+	}
+	if body := decodeError(t, rec); body.Error.Code != "too_many_requests" {
+		t.Errorf("expected too_many_requests, got %q", body.Error.Code)
+	}
+}
+
+func TestRateLimitMiddleware_Keys(t *testing.T) {
+	tests := []struct {
+		name       string
+		pattern    string
+		remoteAddr string
+		userID     int
+		wantStatus int
+	}{
+		{name: "same route and client", pattern: "GET /api/v1/accounts", remoteAddr: "203.0.113.7:5000", wantStatus: http.StatusTooManyRequests},
+		{name: "same client from other port", pattern: "GET /api/v1/accounts", remoteAddr: "203.0.113.7:6000", wantStatus: http.StatusTooManyRequests},
+		{name: "other route", pattern: "GET /api/v1/cards", remoteAddr: "203.0.113.7:5000", wantStatus: http.StatusOK},
+		{name: "other IP", pattern: "GET /api/v1/accounts", remoteAddr: "198.51.100.1:5000", wantStatus: http.StatusOK},
+		{name: "authenticated user from same IP", pattern: "GET /api/v1/accounts", remoteAddr: "203.0.113.7:5000", userID: 42, wantStatus: http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clock := &fakeClock{now: time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)}
+			handler := RateLimitMiddleware(newTestRateLimiter(NewMemoryRateLimitStore(), clock), "api")(okHandler)
+
+			// Исчерпываем корзину анонимного клиента на маршруте счетов
+			for range 2 {
+				rateLimitRequest(handler, "GET /api/v1/accounts", "203.0.113.7:5000", 0)
+			}
+
+			rec := rateLimitRequest(handler, tt.pattern, tt.remoteAddr, tt.userID)
+			if rec.Code != tt.wantStatus {
+				t.Errorf("expected status %d, got %d", tt.wantStatus, rec.Code)
+			}
+		})
+	}
+}
+
+func TestRateLimitMiddleware_ForwardedClient(t *testing.T) {
+	tests := []struct {
+		name         string
+		remoteAddr   string
+		forwardedFor string
+		wantStatus   int
+	}{
+		{name: "same client via balancer", remoteAddr: "10.0.0.2:5000", forwardedFor: "203.0.113.7", wantStatus: http.StatusTooManyRequests},
+		{name: "other client via balancer", remoteAddr: "10.0.0.1:5000", forwardedFor: "198.51.100.1", wantStatus: http.StatusOK},
+		{name: "spoofed address on the left", remoteAddr: "10.0.0.1:5000", forwardedFor: "198.51.100.1, 203.0.113.7", wantStatus: http.StatusTooManyRequests},
+		{name: "internal proxy hop on the right", remoteAddr: "10.0.0.1:5000", forwardedFor: "203.0.113.7, 10.1.0.5", wantStatus: http.StatusTooManyRequests},
+		{name: "balancer without header", remoteAddr: "10.0.0.1:5000", wantStatus: http.StatusOK},
+		// Заголовок от клиента, обращающегося напрямую, не учитывается
+		{name: "untrusted sender", remoteAddr: "198.51.100.1:5000", forwardedFor: "203.0.113.7", wantStatus: http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clock := &fakeClock{now: time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)}
+			handler := RateLimitMiddleware(newTestRateLimiter(NewMemoryRateLimitStore(), clock), "api")(okHandler)
+
+			// Исчерпываем корзину клиента 203.0.113.7 за балансировщиком
+			for range 2 {
+				forwardedRateLimitRequest(handler, "GET /api/v1/accounts", "10.0.0.1:5000", "203.0.113.7", 0)
+			}
+
+			rec := forwardedRateLimitRequest(handler, "GET /api/v1/accounts", tt.remoteAddr, tt.forwardedFor, 0)
+			if rec.Code != tt.wantStatus {
+				t.Errorf("expected status %d, got %d", tt.wantStatus, rec.Code)
+			}
+		})
+	}
+}
+
+func TestRateLimitMiddleware_Passthrough(t *testing.T) {
+	clock := &fakeClock{now: time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)}
+
+	tests := []struct {
+		name    string
+		limiter *RateLimiter
+		group   string
+	}{
+		{name: "store error", limiter: newTestRateLimiter(failingRateLimitStore{}, clock), group: "api"},
+		{name: "unknown group", limiter: newTestRateLimiter(NewMemoryRateLimitStore(), clock), group: "auth"},
+		{name: "no limiter", limiter: nil, group: "api"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := RateLimitMiddleware(tt.limiter, tt.group)(okHandler)
+			for i := range 5 {
+				rec := rateLimitRequest(handler, "GET /api/v1/accounts", "203.0.113.7:5000", 0)
+				if rec.Code != http.StatusOK {
+					t.Fatalf("request %d: expected status 200, got %d", i+1, rec.Code)
+				}
+				if got := rec.Header().Get("RateLimit-Limit"); got != "" {
+					t.Errorf("expected no RateLimit headers, got RateLimit-Limit %q", got)
+				}
+			}
+		})
+	}
+}
+
+func TestMemoryRateLimitStore_DeleteExpired(t *testing.T) {
+	store := NewMemoryRateLimitStore()
+	limit := utils.RateLimit{Requests: 2, Period: time.Minute}
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	if _, err := store.Take(context.Background(), "a", limit, now); err != nil {
+		t.Fatalf("Take failed: %v", err)
+	}
+
+	// Корзина восполнится через 30 секунд: раньше удалять ее нельзя
+	if deleted, _ := store.DeleteExpired(context.Background(), now.Add(29*time.Second)); deleted != 0 {
+		t.Fatalf("expected no buckets deleted before refill, got %d", deleted)
+	}
+	if deleted, _ := store.DeleteExpired(context.Background(), now.Add(30*time.Second)); deleted != 1 {
+		t.Fatalf("expected refilled bucket deleted, got %d", deleted)
+	}
+}
@@ -0,0 +1,189 @@
+package middleware
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/vterdunov/learn-bank-app/internal/config"
+)
+
+// corsExposedHeaders заголовки ответа, доступные скриптам с других источников
+const corsExposedHeaders = "RateLimit-Limit, RateLimit-Remaining, RateLimit-Reset, RateLimit-Policy, Retry-After, X-Request-ID, " + ErrorIDHeader
+
+// timeoutWriteGrace запас времени на запись ответа 503 после истечения таймаута обработки
+const timeoutWriteGrace = 5 * time.Second
+
+// timeoutBody ответ 503 по истечении таймаута обработки в формате API
+var timeoutBody = mustMarshal(errorResponse{Error: errorDetail{Code: "request_timeout", Message: "request timeout"}})
+
+// SecurityHeadersMiddleware добавляет заголовки безопасности. Ответы API не кешируются;
+// обработчик может переопределить Cache-Control и Content-Security-Policy для своего ответа
+func SecurityHeadersMiddleware(cfg config.ServerConfig) func(http.Handler) http.Handler {
+	hsts := ""
+	if cfg.HSTSMaxAge > 0 {
+		hsts = "max-age=" + strconv.Itoa(int(cfg.HSTSMaxAge.Seconds())) + "; includeSubDomains"
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			header := w.Header()
+			header.Set("X-Content-Type-Options", "nosniff")
+			header.Set("X-Frame-Options", "DENY")
+			header.Set("Referrer-Policy", "no-referrer")
+			header.Set("Cache-Control", "no-store")
+			if cfg.ContentSecurityPolicy != "" {
+				header.Set("Content-Security-Policy", cfg.ContentSecurityPolicy)
+			}
+			if hsts != "" {
+				header.Set("Strict-Transport-Security", hsts)
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// CORSMiddleware разрешает запросы из браузера с источников cfg.AllowedOrigins и отвечает на preflight запросы.
+// Запросы с других источников обрабатываются без CORS заголовков, и браузер не отдает ответ скрипту.
+// Cookies API не использует, поэтому Access-Control-Allow-Credentials не выставляется
+func CORSMiddleware(cfg config.CORSConfig) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		if len(cfg.AllowedOrigins) == 0 {
+			return next
+		}
+
+		allowAll := slices.Contains(cfg.AllowedOrigins, "*")
+		methods := strings.Join(cfg.AllowedMethods, ", ")
+		headers := strings.Join(cfg.AllowedHeaders, ", ")
+		maxAge := strconv.Itoa(int(cfg.MaxAge.Seconds()))
+
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			origin := r.Header.Get("Origin")
+			if origin == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			header := w.Header()
+			header.Add("Vary", "Origin")
+			if !allowAll && !slices.Contains(cfg.AllowedOrigins, origin) {
+				next.ServeHTTP(w, r)
+				return
+			}
+			header.Set("Access-Control-Allow-Origin", origin)
+
+			if r.Method == http.MethodOptions && r.Header.Get("Access-Control-Request-Method") != "" {
+				header.Add("Vary", "Access-Control-Request-Method")
+				header.Add("Vary", "Access-Control-Request-Headers")
+				header.Set("Access-Control-Allow-Methods", methods)
+				header.Set("Access-Control-Allow-Headers", headers)
+				header.Set("Access-Control-Max-Age", maxAge)
+				w.WriteHeader(http.StatusNoContent)
+				return
+			}
+
+			header.Set("Access-Control-Expose-Headers", corsExposedHeaders)
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// BodyLimitMiddleware ограничивает размер тела запроса: запрос с большим Content-Length отклоняется сразу,
+// а чтение сверх лимита возвращает *http.MaxBytesError
+func BodyLimitMiddleware(limit int64) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		if limit <= 0 {
+			return next
+		}
+
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.ContentLength > limit {
+				writeJSONError(w, http.StatusRequestEntityTooLarge, errorDetail{
+					Code:    "request_body_too_large",
+					Message: "request body too large",
+				})
+				return
+			}
+
+			r.Body = http.MaxBytesReader(w, r.Body, limit)
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// TimeoutMiddleware ограничивает время обработки запроса: по истечении timeout контекст запроса отменяется,
+// а клиент получает 503 в формате API. Ответ буферизуется до завершения обработчика, поэтому для передачи файлов
+// используется StreamingTimeoutMiddleware
+func TimeoutMiddleware(timeout time.Duration) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		if timeout <= 0 {
+			return next
+		}
+
+		handler := http.TimeoutHandler(next, timeout, string(timeoutBody))
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			extendDeadlines(w, timeout)
+			handler.ServeHTTP(&timeoutResponseWriter{ResponseWriter: w}, r)
+		})
+	}
+}
+
+// timeoutResponseWriter выставляет JSON Content-Type ответу 503 от http.TimeoutHandler.
+// По таймауту заголовки обработчика отбрасываются, поэтому ответ без Content-Type — ответ о таймауте
+type timeoutResponseWriter struct {
+	http.ResponseWriter
+}
+
+func (w *timeoutResponseWriter) WriteHeader(code int) {
+	if code == http.StatusServiceUnavailable && w.Header().Get("Content-Type") == "" {
+		w.Header().Set("Content-Type", "application/json")
+	}
+	w.ResponseWriter.WriteHeader(code)
+}
+
+// Unwrap открывает исходный ResponseWriter для http.ResponseController
+func (w *timeoutResponseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// mustMarshal кодирует в JSON значение, для которого ошибка невозможна
+func mustMarshal(v any) []byte {
+	data, err := json.Marshal(v)
+	if err != nil {
+		panic(err)
+	}
+	return data
+}
+
+// StreamingTimeoutMiddleware ограничивает время обработки запроса без буферизации ответа:
+// по истечении timeout отменяется контекст запроса и обрывается соединение
+func StreamingTimeoutMiddleware(timeout time.Duration) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		if timeout <= 0 {
+			return next
+		}
+
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx, cancel := context.WithTimeout(r.Context(), timeout)
+			defer cancel()
+
+			extendDeadlines(w, timeout)
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// extendDeadlines заменяет общие таймауты чтения и записи сервера таймаутом маршрута,
+// чтобы длинные маршруты не обрывались по ReadTimeout и WriteTimeout сервера
+func extendDeadlines(w http.ResponseWriter, timeout time.Duration) {
+	rc := http.NewResponseController(w)
+	now := time.Now()
+	// ErrNotSupported возможен только в тестовом ResponseWriter: тогда действуют таймауты сервера
+	_ = rc.SetReadDeadline(now.Add(timeout))
+	_ = rc.SetWriteDeadline(now.Add(timeout + timeoutWriteGrace))
+}
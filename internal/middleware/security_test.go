@@ -0,0 +1,253 @@
+package middleware
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/vterdunov/learn-bank-app/internal/config"
+)
+
+// okHandler отвечает 200 с JSON телом, как обработчики API
+var okHandler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	_, _ = w.Write([]byte(`{"success":true}`))
+})
+
+// decodeError разбирает ответ с ошибкой в формате API
+func decodeError(t *testing.T, rec *httptest.ResponseRecorder) errorResponse {
+	t.Helper()
+
+	if ct := rec.Header().Get("Content-Type"); ct != "application/json" {
+		t.Fatalf("expected JSON error, got Content-Type %q and body %q", ct, rec.Body.String())
+	}
+	var body errorResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatalf("failed to decode error body %q: %v", rec.Body.String(), err)
+	}
+	if body.Success {
+		t.Errorf("expected success=false in error body")
+	}
+	return body
+}
+
+func TestSecurityHeadersMiddleware(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  config.ServerConfig
+		want map[string]string
+	}{
+		{
+			name: "all headers",
+			cfg:  config.ServerConfig{HSTSMaxAge: time.Hour, ContentSecurityPolicy: "default-src 'none'"},
+			want: map[string]string{
+				"X-Content-Type-Options":    "nosniff",
+				"X-Frame-Options":           "DENY",
+				"Referrer-Policy":           "no-referrer",
+				"Cache-Control":             "no-store",
+				"Content-Security-Policy":   "default-src 'none'",
+				"Strict-Transport-Security": "max-age=3600; includeSubDomains",
+			},
+		},
+		{
+			name: "HSTS and CSP disabled",
+			cfg:  config.ServerConfig{},
+			want: map[string]string{
+				"X-Content-Type-Options":    "nosniff",
+				"Cache-Control":             "no-store",
+				"Content-Security-Policy":   "",
+				"Strict-Transport-Security": "",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			SecurityHeadersMiddleware(tt.cfg)(okHandler).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+
+			for header, want := range tt.want {
+				if got := rec.Header().Get(header); got != want {
+					t.Errorf("%s: expected %q, got %q", header, want, got)
+				}
+			}
+		})
+	}
+}
+
+func TestCORSMiddleware(t *testing.T) {
+	cfg := config.CORSConfig{
+		AllowedOrigins: []string{"https://app.example.com"},
+		AllowedMethods: []string{"GET", "POST"},
+		AllowedHeaders: []string{"Authorization", "Content-Type"},
+		MaxAge:         10 * time.Minute,
+	}
+
+	tests := []struct {
+		name        string
+		cfg         config.CORSConfig
+		method      string
+		origin      string
+		preflight   bool
+		wantStatus  int
+		wantOrigin  string
+		wantMethods string
+		wantExpose  bool
+	}{
+		{name: "preflight from allowed origin", cfg: cfg, method: http.MethodOptions, origin: "https://app.example.com", preflight: true,
+			wantStatus: http.StatusNoContent, wantOrigin: "https://app.example.com", wantMethods: "GET, POST"},
+		{name: "preflight from other origin", cfg: cfg, method: http.MethodOptions, origin: "https://evil.example.com", preflight: true,
+			wantStatus: http.StatusOK},
+		{name: "request from allowed origin", cfg: cfg, method: http.MethodGet, origin: "https://app.example.com",
+			wantStatus: http.StatusOK, wantOrigin: "https://app.example.com", wantExpose: true},
+		{name: "request without origin", cfg: cfg, method: http.MethodGet, wantStatus: http.StatusOK},
+		{name: "any origin", cfg: config.CORSConfig{AllowedOrigins: []string{"*"}}, method: http.MethodGet, origin: "https://other.example.com",
+			wantStatus: http.StatusOK, wantOrigin: "https://other.example.com", wantExpose: true},
+		{name: "CORS disabled", cfg: config.CORSConfig{}, method: http.MethodOptions, origin: "https://app.example.com", preflight: true,
+			wantStatus: http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, "/api/v1/accounts", nil)
+			if tt.origin != "" {
+				req.Header.Set("Origin", tt.origin)
+			}
+			if tt.preflight {
+				req.Header.Set("Access-Control-Request-Method", http.MethodPost)
+			}
+			rec := httptest.NewRecorder()
+			CORSMiddleware(tt.cfg)(okHandler).ServeHTTP(rec, req)
+
+			if rec.Code != tt.wantStatus {
+				t.Errorf("expected status %d, got %d", tt.wantStatus, rec.Code)
+			}
+			if got := rec.Header().Get("Access-Control-Allow-Origin"); got != tt.wantOrigin {
+				t.Errorf("expected Access-Control-Allow-Origin %q, got %q", tt.wantOrigin, got)
+			}
+			if got := rec.Header().Get("Access-Control-Allow-Methods"); got != tt.wantMethods {
+				t.Errorf("expected Access-Control-Allow-Methods %q, got %q", tt.wantMethods, got)
+			}
+			if got := rec.Header().Get("Access-Control-Expose-Headers") != ""; got != tt.wantExpose {
+				t.Errorf("expected exposed headers %v, got %q", tt.wantExpose, rec.Header().Get("Access-Control-Expose-Headers"))
+			}
+			if tt.wantStatus == http.StatusNoContent && rec.Header().Get("Access-Control-Max-Age") != "600" {
+				t.Errorf("expected Access-Control-Max-Age 600, got %q", rec.Header().Get("Access-Control-Max-Age"))
+			}
+		})
+	}
+}
+
+func TestBodyLimitMiddleware(t *testing.T) {
+	// readHandler читает тело целиком, как ValidateJSON
+	readHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, err := io.ReadAll(r.Body); err != nil {
+			var maxBytesErr *http.MaxBytesError
+			if !errors.As(err, &maxBytesErr) {
+				t.Errorf("expected *http.MaxBytesError, got %v", err)
+			}
+			w.WriteHeader(http.StatusRequestEntityTooLarge)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	})
+
+	tests := []struct {
+		name          string
+		limit         int64
+		body          string
+		hideLength    bool
+		wantStatus    int
+		wantJSONError bool
+	}{
+		{name: "within limit", limit: 10, body: "0123456789", wantStatus: http.StatusOK},
+		{name: "content length over limit", limit: 10, body: "0123456789A", wantStatus: http.StatusRequestEntityTooLarge, wantJSONError: true},
+		{name: "chunked body over limit", limit: 10, body: "0123456789A", hideLength: true, wantStatus: http.StatusRequestEntityTooLarge},
+		{name: "no limit", limit: 0, body: strings.Repeat("x", 100), wantStatus: http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(tt.body))
+			if tt.hideLength {
+				req.ContentLength = -1
+			}
+			rec := httptest.NewRecorder()
+			BodyLimitMiddleware(tt.limit)(readHandler).ServeHTTP(rec, req)
+
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("expected status %d, got %d", tt.wantStatus, rec.Code)
+			}
+			if tt.wantJSONError {
+				if body := decodeError(t, rec); body.Error.Code != "request_body_too_large" {
+					t.Errorf("expected request_body_too_large, got %q", body.Error.Code)
+				}
+			}
+		})
+	}
+}
+
+func TestTimeoutMiddleware(t *testing.T) {
+	slow := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-time.After(time.Second):
+			t.Error("expected request context to be cancelled on timeout")
+		}
+	})
+	unavailable := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain")
+		w.WriteHeader(http.StatusServiceUnavailable)
+	})
+
+	tests := []struct {
+		name            string
+		handler         http.Handler
+		wantStatus      int
+		wantContentType string
+		wantTimeoutBody bool
+	}{
+		{name: "fast handler", handler: okHandler, wantStatus: http.StatusOK, wantContentType: "application/json"},
+		{name: "slow handler", handler: slow, wantStatus: http.StatusServiceUnavailable, wantContentType: "application/json", wantTimeoutBody: true},
+		{name: "handler 503 keeps own headers", handler: unavailable, wantStatus: http.StatusServiceUnavailable, wantContentType: "text/plain"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			TimeoutMiddleware(20*time.Millisecond)(tt.handler).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("expected status %d, got %d", tt.wantStatus, rec.Code)
+			}
+			if got := rec.Header().Get("Content-Type"); got != tt.wantContentType {
+				t.Errorf("expected Content-Type %q, got %q", tt.wantContentType, got)
+			}
+			if tt.wantTimeoutBody {
+				if body := decodeError(t, rec); body.Error.Code != "request_timeout" {
+					t.Errorf("expected request_timeout, got %q", body.Error.Code)
+				}
+			}
+		})
+	}
+}
+
+func TestStreamingTimeoutMiddleware(t *testing.T) {
+	var deadlineSet bool
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, deadlineSet = r.Context().Deadline()
+		w.WriteHeader(http.StatusOK)
+	})
+
+	rec := httptest.NewRecorder()
+	StreamingTimeoutMiddleware(time.Minute)(handler).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	if rec.Code != http.StatusOK || !deadlineSet {
+		t.Errorf("expected streamed response with request deadline, got status %d deadline=%v", rec.Code, deadlineSet)
+	}
+}
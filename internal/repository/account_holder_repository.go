@@ -0,0 +1,165 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/vterdunov/learn-bank-app/internal/domain"
+	"github.com/vterdunov/learn-bank-app/internal/utils"
+)
+
+// AccountHolderRepositoryImpl реализация AccountHolderRepository
+type AccountHolderRepositoryImpl struct {
+	db *pgxpool.Pool
+}
+
+// NewAccountHolderRepository создает новый экземпляр AccountHolderRepository
+func NewAccountHolderRepository(db *pgxpool.Pool) AccountHolderRepository {
+	return &AccountHolderRepositoryImpl{db: db}
+}
+
+// Create добавляет совладельца счета; повторное добавление возвращает ErrAccountHolderExists
+func (r *AccountHolderRepositoryImpl) Create(ctx context.Context, holder *domain.AccountHolder) error {
+	query := `
+		INSERT INTO account_holders (account_id, user_id, role, permissions, created_at)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id`
+
+	holder.CreatedAt = time.Now()
+	if holder.Permissions == nil {
+		holder.Permissions = []string{}
+	}
+
+	err := conn(ctx, r.db).QueryRow(ctx, query,
+		holder.AccountID,
+		holder.UserID,
+		holder.Role,
+		holder.Permissions,
+		holder.CreatedAt,
+	).Scan(&holder.ID)
+	if err != nil {
+		if utils.IsUniqueViolation(utils.ParseDBError(err)) {
+			return domain.ErrAccountHolderExists
+		}
+		return err
+	}
+
+	return nil
+}
+
+// Get получает совладельца счета
+func (r *AccountHolderRepositoryImpl) Get(ctx context.Context, accountID, userID int) (*domain.AccountHolder, error) {
+	query := `
+		SELECT id, account_id, user_id, role, permissions, created_at
+		FROM account_holders
+		WHERE account_id = $1 AND user_id = $2`
+
+	holder := &domain.AccountHolder{}
+	err := conn(ctx, r.db).QueryRow(ctx, query, accountID, userID).Scan(
+		&holder.ID,
+		&holder.AccountID,
+		&holder.UserID,
+		&holder.Role,
+		&holder.Permissions,
+		&holder.CreatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.ErrAccountHolderNotFound
+		}
+		return nil, err
+	}
+
+	return holder, nil
+}
+
+// GetByAccountID получает совладельцев счета
+func (r *AccountHolderRepositoryImpl) GetByAccountID(ctx context.Context, accountID int) ([]*domain.AccountHolder, error) {
+	query := `
+		SELECT id, account_id, user_id, role, permissions, created_at
+		FROM account_holders
+		WHERE account_id = $1
+		ORDER BY created_at`
+
+	rows, err := conn(ctx, r.db).Query(ctx, query, accountID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var holders []*domain.AccountHolder
+	for rows.Next() {
+		holder := &domain.AccountHolder{}
+		if err := rows.Scan(
+			&holder.ID,
+			&holder.AccountID,
+			&holder.UserID,
+			&holder.Role,
+			&holder.Permissions,
+			&holder.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		holders = append(holders, holder)
+	}
+
+	return holders, rows.Err()
+}
+
+// GetAccountsByUserID получает счета, в которых пользователь является совладельцем
+func (r *AccountHolderRepositoryImpl) GetAccountsByUserID(ctx context.Context, userID int) ([]*domain.Account, error) {
+	query := `
+		SELECT a.id, a.user_id, a.number, a.product, a.balance, a.held_amount, a.overdraft_limit, a.currency, a.status, a.created_at, a.updated_at
+		FROM accounts a
+		JOIN account_holders h ON h.account_id = a.id
+		WHERE h.user_id = $1
+		ORDER BY a.created_at DESC`
+
+	rows, err := conn(ctx, r.db).Query(ctx, query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var accounts []*domain.Account
+	for rows.Next() {
+		account := &domain.Account{}
+		if err := rows.Scan(
+			&account.ID,
+			&account.UserID,
+			&account.Number,
+			&account.Product,
+			&account.Balance,
+			&account.HeldAmount,
+			&account.OverdraftLimit,
+			&account.Currency,
+			&account.Status,
+			&account.CreatedAt,
+			&account.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		accounts = append(accounts, account)
+	}
+
+	return accounts, rows.Err()
+}
+
+// Delete удаляет совладельца счета
+func (r *AccountHolderRepositoryImpl) Delete(ctx context.Context, accountID, userID int) error {
+	result, err := conn(ctx, r.db).Exec(ctx, `
+		DELETE FROM account_holders WHERE account_id = $1 AND user_id = $2`, accountID, userID)
+	if err != nil {
+		return err
+	}
+
+	if result.RowsAffected() == 0 {
+		return domain.ErrAccountHolderNotFound
+	}
+
+	return nil
+}
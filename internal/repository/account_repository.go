@@ -24,17 +24,18 @@ func NewAccountRepository(db *pgxpool.Pool) AccountRepository {
 // Create создает новый счет
 func (r *AccountRepositoryImpl) Create(ctx context.Context, account *domain.Account) error {
 	query := `
-		INSERT INTO accounts (user_id, number, balance, currency, status, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		INSERT INTO accounts (user_id, number, product, balance, currency, status, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
 		RETURNING id`
 
 	now := time.Now()
 	account.CreatedAt = now
 	account.UpdatedAt = now
 
-	err := r.db.QueryRow(ctx, query,
+	err := conn(ctx, r.db).QueryRow(ctx, query,
 		account.UserID,
 		account.Number,
+		account.Product,
 		account.Balance,
 		account.Currency,
 		account.Status,
@@ -48,16 +49,34 @@ func (r *AccountRepositoryImpl) Create(ctx context.Context, account *domain.Acco
 // GetByID получает счет по ID
 func (r *AccountRepositoryImpl) GetByID(ctx context.Context, id int) (*domain.Account, error) {
 	query := `
-		SELECT id, user_id, number, balance, currency, status, created_at, updated_at
+		SELECT id, user_id, number, product, balance, held_amount, overdraft_limit, currency, status, created_at, updated_at
 		FROM accounts
 		WHERE id = $1`
 
+	return r.get(ctx, query, id)
+}
+
+// GetByIDForUpdate получает счет по ID с блокировкой строки до конца транзакции
+func (r *AccountRepositoryImpl) GetByIDForUpdate(ctx context.Context, id int) (*domain.Account, error) {
+	query := `
+		SELECT id, user_id, number, product, balance, held_amount, overdraft_limit, currency, status, created_at, updated_at
+		FROM accounts
+		WHERE id = $1
+		FOR UPDATE`
+
+	return r.get(ctx, query, id)
+}
+
+func (r *AccountRepositoryImpl) get(ctx context.Context, query string, id int) (*domain.Account, error) {
 	account := &domain.Account{}
-	err := r.db.QueryRow(ctx, query, id).Scan(
+	err := conn(ctx, r.db).QueryRow(ctx, query, id).Scan(
 		&account.ID,
 		&account.UserID,
 		&account.Number,
+		&account.Product,
 		&account.Balance,
+		&account.HeldAmount,
+		&account.OverdraftLimit,
 		&account.Currency,
 		&account.Status,
 		&account.CreatedAt,
@@ -77,12 +96,12 @@ func (r *AccountRepositoryImpl) GetByID(ctx context.Context, id int) (*domain.Ac
 // GetByUserID получает все счета пользователя
 func (r *AccountRepositoryImpl) GetByUserID(ctx context.Context, userID int) ([]*domain.Account, error) {
 	query := `
-		SELECT id, user_id, number, balance, currency, status, created_at, updated_at
+		SELECT id, user_id, number, product, balance, held_amount, overdraft_limit, currency, status, created_at, updated_at
 		FROM accounts
 		WHERE user_id = $1
 		ORDER BY created_at DESC`
 
-	rows, err := r.db.Query(ctx, query, userID)
+	rows, err := conn(ctx, r.db).Query(ctx, query, userID)
 	if err != nil {
 		return nil, err
 	}
@@ -95,7 +114,10 @@ func (r *AccountRepositoryImpl) GetByUserID(ctx context.Context, userID int) ([]
 			&account.ID,
 			&account.UserID,
 			&account.Number,
+			&account.Product,
 			&account.Balance,
+			&account.HeldAmount,
+			&account.OverdraftLimit,
 			&account.Currency,
 			&account.Status,
 			&account.CreatedAt,
@@ -113,16 +135,19 @@ func (r *AccountRepositoryImpl) GetByUserID(ctx context.Context, userID int) ([]
 // GetByNumber получает счет по номеру
 func (r *AccountRepositoryImpl) GetByNumber(ctx context.Context, number string) (*domain.Account, error) {
 	query := `
-		SELECT id, user_id, number, balance, currency, status, created_at, updated_at
+		SELECT id, user_id, number, product, balance, held_amount, overdraft_limit, currency, status, created_at, updated_at
 		FROM accounts
 		WHERE number = $1`
 
 	account := &domain.Account{}
-	err := r.db.QueryRow(ctx, query, number).Scan(
+	err := conn(ctx, r.db).QueryRow(ctx, query, number).Scan(
 		&account.ID,
 		&account.UserID,
 		&account.Number,
+		&account.Product,
 		&account.Balance,
+		&account.HeldAmount,
+		&account.OverdraftLimit,
 		&account.Currency,
 		&account.Status,
 		&account.CreatedAt,
@@ -148,7 +173,7 @@ func (r *AccountRepositoryImpl) Update(ctx context.Context, account *domain.Acco
 
 	account.UpdatedAt = time.Now()
 
-	result, err := r.db.Exec(ctx, query,
+	result, err := conn(ctx, r.db).Exec(ctx, query,
 		account.ID,
 		account.Balance,
 		account.Currency,
@@ -167,6 +192,25 @@ func (r *AccountRepositoryImpl) Update(ctx context.Context, account *domain.Acco
 	return nil
 }
 
+// UpdateStatus обновляет статус счета, не затрагивая остаток
+func (r *AccountRepositoryImpl) UpdateStatus(ctx context.Context, id int, status string) error {
+	query := `
+		UPDATE accounts
+		SET status = $2, updated_at = $3
+		WHERE id = $1`
+
+	result, err := conn(ctx, r.db).Exec(ctx, query, id, status, time.Now())
+	if err != nil {
+		return err
+	}
+
+	if result.RowsAffected() == 0 {
+		return errors.New("account not found")
+	}
+
+	return nil
+}
+
 // UpdateBalance обновляет баланс счета
 func (r *AccountRepositoryImpl) UpdateBalance(ctx context.Context, id int, balance float64) error {
 	query := `
@@ -174,7 +218,7 @@ func (r *AccountRepositoryImpl) UpdateBalance(ctx context.Context, id int, balan
 		SET balance = $2, updated_at = $3
 		WHERE id = $1`
 
-	result, err := r.db.Exec(ctx, query, id, balance, time.Now())
+	result, err := conn(ctx, r.db).Exec(ctx, query, id, balance, time.Now())
 	if err != nil {
 		return err
 	}
@@ -186,11 +230,69 @@ func (r *AccountRepositoryImpl) UpdateBalance(ctx context.Context, id int, balan
 	return nil
 }
 
+// Debit списывает сумму с учетного остатка, если ее хватает в доступном остатке (с учетом лимита овердрафта и холдов).
+// Проверка и списание выполняются одним запросом, поэтому параллельные списания не уводят счет за лимит.
+func (r *AccountRepositoryImpl) Debit(ctx context.Context, id int, amount float64) error {
+	query := `
+		UPDATE accounts
+		SET balance = balance - $2, updated_at = $3
+		WHERE id = $1 AND balance + overdraft_limit - held_amount >= $2`
+
+	result, err := conn(ctx, r.db).Exec(ctx, query, id, amount, time.Now())
+	if err != nil {
+		return err
+	}
+
+	if result.RowsAffected() == 0 {
+		return domain.ErrAccountInsufficientFunds
+	}
+
+	return nil
+}
+
+// Credit зачисляет сумму на учетный остаток одним запросом, не перезаписывая параллельные изменения
+func (r *AccountRepositoryImpl) Credit(ctx context.Context, id int, amount float64) error {
+	query := `
+		UPDATE accounts
+		SET balance = balance + $2, updated_at = $3
+		WHERE id = $1`
+
+	result, err := conn(ctx, r.db).Exec(ctx, query, id, amount, time.Now())
+	if err != nil {
+		return err
+	}
+
+	if result.RowsAffected() == 0 {
+		return errors.New("account not found")
+	}
+
+	return nil
+}
+
+// UpdateOverdraftLimit устанавливает лимит овердрафта, если он покрывает использованный овердрафт и холды
+func (r *AccountRepositoryImpl) UpdateOverdraftLimit(ctx context.Context, id int, limit float64) error {
+	query := `
+		UPDATE accounts
+		SET overdraft_limit = $2, updated_at = $3
+		WHERE id = $1 AND balance + $2 - held_amount >= 0`
+
+	result, err := conn(ctx, r.db).Exec(ctx, query, id, limit, time.Now())
+	if err != nil {
+		return err
+	}
+
+	if result.RowsAffected() == 0 {
+		return domain.ErrOverdraftLimitBelowDebt
+	}
+
+	return nil
+}
+
 // Delete удаляет счет
 func (r *AccountRepositoryImpl) Delete(ctx context.Context, id int) error {
 	query := `DELETE FROM accounts WHERE id = $1`
 
-	result, err := r.db.Exec(ctx, query, id)
+	result, err := conn(ctx, r.db).Exec(ctx, query, id)
 	if err != nil {
 		return err
 	}
@@ -204,20 +306,20 @@ func (r *AccountRepositoryImpl) Delete(ctx context.Context, id int) error {
 
 // Transfer выполняет перевод между счетами в транзакции
 func (r *AccountRepositoryImpl) Transfer(ctx context.Context, fromID, toID int, amount float64) error {
-	tx, err := r.db.Begin(ctx)
+	tx, err := conn(ctx, r.db).Begin(ctx)
 	if err != nil {
 		return err
 	}
 	defer tx.Rollback(ctx)
 
-	// Проверяем баланс отправителя
-	var fromBalance float64
-	err = tx.QueryRow(ctx, "SELECT balance FROM accounts WHERE id = $1 FOR UPDATE", fromID).Scan(&fromBalance)
+	// Проверяем доступный остаток отправителя (с лимитом овердрафта, без заблокированных холдами средств)
+	var fromAvailable float64
+	err = tx.QueryRow(ctx, "SELECT balance + overdraft_limit - held_amount FROM accounts WHERE id = $1 FOR UPDATE", fromID).Scan(&fromAvailable)
 	if err != nil {
 		return err
 	}
 
-	if fromBalance < amount {
+	if fromAvailable < amount {
 		return errors.New("insufficient funds")
 	}
 
@@ -245,7 +347,7 @@ func (r *AccountRepositoryImpl) GetBalance(ctx context.Context, id int) (float64
 	query := `SELECT balance FROM accounts WHERE id = $1`
 
 	var balance float64
-	err := r.db.QueryRow(ctx, query, id).Scan(&balance)
+	err := conn(ctx, r.db).QueryRow(ctx, query, id).Scan(&balance)
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			return 0, errors.New("account not found")
@@ -0,0 +1,188 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/vterdunov/learn-bank-app/internal/domain"
+)
+
+// auditChainLockID ключ advisory lock, сериализующий добавление записей в цепочку
+const auditChainLockID = 727001
+
+// AuditRepositoryImpl реализация AuditRepository
+type AuditRepositoryImpl struct {
+	db *pgxpool.Pool
+}
+
+// NewAuditRepository создает новый экземпляр AuditRepository
+func NewAuditRepository(db *pgxpool.Pool) AuditRepository {
+	return &AuditRepositoryImpl{db: db}
+}
+
+// Append добавляет запись в конец цепочки аудита.
+// Предыдущая запись читается под advisory lock, поэтому параллельные вставки не разветвляют цепочку.
+func (r *AuditRepositoryImpl) Append(ctx context.Context, event *domain.AuditEvent) error {
+	tx, err := conn(ctx, r.db).Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := tx.Exec(ctx, "SELECT pg_advisory_xact_lock($1)", auditChainLockID); err != nil {
+		return fmt.Errorf("failed to lock audit chain: %w", err)
+	}
+
+	var prev *domain.AuditEvent
+	last := &domain.AuditEvent{}
+	err = tx.QueryRow(ctx, "SELECT seq, hash FROM audit_events ORDER BY seq DESC LIMIT 1").Scan(&last.Seq, &last.Hash)
+	switch {
+	case err == nil:
+		prev = last
+	case errors.Is(err, pgx.ErrNoRows):
+		// Первая запись цепочки
+	default:
+		return fmt.Errorf("failed to get last audit event: %w", err)
+	}
+
+	if err := event.Seal(prev); err != nil {
+		return err
+	}
+
+	query := `
+		INSERT INTO audit_events (seq, actor_user_id, actor_type, action, resource_type, resource_id,
+			request_id, ip, before_state, after_state, metadata, created_at, prev_hash, hash)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)
+		RETURNING id`
+
+	err = tx.QueryRow(ctx, query,
+		event.Seq,
+		event.ActorUserID,
+		event.ActorType,
+		event.Action,
+		event.ResourceType,
+		event.ResourceID,
+		event.RequestID,
+		event.IP,
+		nullableJSON(event.Before),
+		nullableJSON(event.After),
+		nullableJSON(event.Metadata),
+		event.CreatedAt,
+		event.PrevHash,
+		event.Hash,
+	).Scan(&event.ID)
+	if err != nil {
+		return fmt.Errorf("failed to insert audit event: %w", err)
+	}
+
+	return tx.Commit(ctx)
+}
+
+// List возвращает записи аудита по фильтру (новые первыми)
+func (r *AuditRepositoryImpl) List(ctx context.Context, filter domain.AuditFilter) ([]*domain.AuditEvent, error) {
+	var conditions []string
+	var args []interface{}
+
+	addCondition := func(condition string, value interface{}) {
+		args = append(args, value)
+		conditions = append(conditions, fmt.Sprintf(condition, len(args)))
+	}
+
+	if filter.ActorUserID != nil {
+		addCondition("actor_user_id = $%d", *filter.ActorUserID)
+	}
+	if filter.Action != "" {
+		addCondition("action = $%d", filter.Action)
+	}
+	if filter.ResourceType != "" {
+		addCondition("resource_type = $%d", filter.ResourceType)
+	}
+	if filter.ResourceID != "" {
+		addCondition("resource_id = $%d", filter.ResourceID)
+	}
+	if filter.From != nil {
+		addCondition("created_at >= $%d", *filter.From)
+	}
+	if filter.To != nil {
+		addCondition("created_at < $%d", *filter.To)
+	}
+
+	query := auditSelectColumns + " FROM audit_events"
+	if len(conditions) > 0 {
+		query += " WHERE " + strings.Join(conditions, " AND ")
+	}
+
+	args = append(args, filter.Limit, filter.Offset)
+	query += fmt.Sprintf(" ORDER BY seq DESC LIMIT $%d OFFSET $%d", len(args)-1, len(args))
+
+	return r.queryEvents(ctx, query, args...)
+}
+
+// ListAfterSeq возвращает записи цепочки после указанной позиции (для проверки целостности)
+func (r *AuditRepositoryImpl) ListAfterSeq(ctx context.Context, afterSeq int64, limit int) ([]*domain.AuditEvent, error) {
+	query := auditSelectColumns + `
+		FROM audit_events
+		WHERE seq > $1
+		ORDER BY seq ASC
+		LIMIT $2`
+
+	return r.queryEvents(ctx, query, afterSeq, limit)
+}
+
+// auditSelectColumns список колонок записи аудита
+const auditSelectColumns = `
+		SELECT id, seq, actor_user_id, actor_type, action, resource_type, resource_id,
+			request_id, ip, before_state, after_state, metadata, created_at, prev_hash, hash`
+
+func (r *AuditRepositoryImpl) queryEvents(ctx context.Context, query string, args ...interface{}) ([]*domain.AuditEvent, error) {
+	rows, err := conn(ctx, r.db).Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var events []*domain.AuditEvent
+	for rows.Next() {
+		event := &domain.AuditEvent{}
+		var before, after, metadata []byte
+		err := rows.Scan(
+			&event.ID,
+			&event.Seq,
+			&event.ActorUserID,
+			&event.ActorType,
+			&event.Action,
+			&event.ResourceType,
+			&event.ResourceID,
+			&event.RequestID,
+			&event.IP,
+			&before,
+			&after,
+			&metadata,
+			&event.CreatedAt,
+			&event.PrevHash,
+			&event.Hash,
+		)
+		if err != nil {
+			return nil, err
+		}
+		event.Before = before
+		event.After = after
+		event.Metadata = metadata
+		events = append(events, event)
+	}
+
+	return events, rows.Err()
+}
+
+// nullableJSON передает пустое состояние как NULL
+func nullableJSON(data []byte) interface{} {
+	if len(data) == 0 {
+		return nil
+	}
+	return string(data)
+}
@@ -24,21 +24,28 @@ func NewCardRepository(db *pgxpool.Pool) CardRepository {
 // Create создает новую карту
 func (r *CardRepositoryImpl) Create(ctx context.Context, card *domain.Card) error {
 	query := `
-		INSERT INTO cards (account_id, encrypted_data, hmac, cvv_hash, expiry_date, status, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		INSERT INTO cards (account_id, holder_id, encrypted_data, hmac, cvv_hash, pan_hash, expiry_date, status, card_type,
+			merchant_lock, pin_hash, pin_salt, created_at, updated_at)
+		VALUES ($1, NULLIF($2, 0), $3, $4, $5, $6, $7, $8, $9, NULLIF($10, ''), $11, $12, $13, $14)
 		RETURNING id`
 
 	now := time.Now()
 	card.CreatedAt = now
 	card.UpdatedAt = now
 
-	err := r.db.QueryRow(ctx, query,
+	err := conn(ctx, r.db).QueryRow(ctx, query,
 		card.AccountID,
+		card.HolderID,
 		card.EncryptedData,
 		card.HMAC,
 		card.CVVHash,
+		card.PANHash,
 		card.ExpiryDate,
 		card.Status,
+		card.CardType,
+		card.MerchantLock,
+		card.PINHash,
+		card.PINSalt,
 		card.CreatedAt,
 		card.UpdatedAt,
 	).Scan(&card.ID)
@@ -48,85 +55,44 @@ func (r *CardRepositoryImpl) Create(ctx context.Context, card *domain.Card) erro
 
 // GetByID получает карту по ID
 func (r *CardRepositoryImpl) GetByID(ctx context.Context, id int) (*domain.Card, error) {
-	query := `
-		SELECT id, account_id, encrypted_data, hmac, cvv_hash, expiry_date, status, created_at, updated_at
-		FROM cards
-		WHERE id = $1`
+	query := `SELECT ` + cardColumns + ` FROM cards WHERE id = $1`
 
-	card := &domain.Card{}
-	err := r.db.QueryRow(ctx, query, id).Scan(
-		&card.ID,
-		&card.AccountID,
-		&card.EncryptedData,
-		&card.HMAC,
-		&card.CVVHash,
-		&card.ExpiryDate,
-		&card.Status,
-		&card.CreatedAt,
-		&card.UpdatedAt,
-	)
+	return r.get(ctx, query, id)
+}
 
-	if err != nil {
-		if errors.Is(err, pgx.ErrNoRows) {
-			return nil, errors.New("card not found")
-		}
-		return nil, err
-	}
+// GetByPANHash получает карту по HMAC номера
+func (r *CardRepositoryImpl) GetByPANHash(ctx context.Context, panHash string) (*domain.Card, error) {
+	query := `SELECT ` + cardColumns + ` FROM cards WHERE pan_hash = $1`
 
-	return card, nil
+	return r.get(ctx, query, panHash)
 }
 
 // GetByAccountID получает все карты счета
 func (r *CardRepositoryImpl) GetByAccountID(ctx context.Context, accountID int) ([]*domain.Card, error) {
 	query := `
-		SELECT id, account_id, encrypted_data, hmac, cvv_hash, expiry_date, status, created_at, updated_at
+		SELECT ` + cardColumns + `
 		FROM cards
 		WHERE account_id = $1
 		ORDER BY created_at DESC`
 
-	rows, err := r.db.Query(ctx, query, accountID)
-	if err != nil {
-		return nil, err
-	}
-	defer rows.Close()
-
-	var cards []*domain.Card
-	for rows.Next() {
-		card := &domain.Card{}
-		err := rows.Scan(
-			&card.ID,
-			&card.AccountID,
-			&card.EncryptedData,
-			&card.HMAC,
-			&card.CVVHash,
-			&card.ExpiryDate,
-			&card.Status,
-			&card.CreatedAt,
-			&card.UpdatedAt,
-		)
-		if err != nil {
-			return nil, err
-		}
-		cards = append(cards, card)
-	}
-
-	return cards, nil
+	return r.list(ctx, query, accountID)
 }
 
 // Update обновляет данные карты
 func (r *CardRepositoryImpl) Update(ctx context.Context, card *domain.Card) error {
 	query := `
 		UPDATE cards
-		SET encrypted_data = $2, hmac = $3, cvv_hash = $4, expiry_date = $5, status = $6, updated_at = $7
+		SET encrypted_data = $2, hmac = $3, cvv_hash = $4, pan_hash = $5, expiry_date = $6, status = $7, updated_at = $8
 		WHERE id = $1`
 
 	card.UpdatedAt = time.Now()
 
-	result, err := r.db.Exec(ctx, query,
+	result, err := conn(ctx, r.db).Exec(ctx, query,
 		card.ID,
 		card.EncryptedData,
 		card.HMAC,
 		card.CVVHash,
+		card.PANHash,
 		card.ExpiryDate,
 		card.Status,
 		card.UpdatedAt,
@@ -147,7 +113,7 @@ func (r *CardRepositoryImpl) Update(ctx context.Context, card *domain.Card) erro
 func (r *CardRepositoryImpl) Delete(ctx context.Context, id int) error {
 	query := `DELETE FROM cards WHERE id = $1`
 
-	result, err := r.db.Exec(ctx, query, id)
+	result, err := conn(ctx, r.db).Exec(ctx, query, id)
 	if err != nil {
 		return err
 	}
@@ -166,7 +132,7 @@ func (r *CardRepositoryImpl) UpdateStatus(ctx context.Context, id int, status st
 		SET status = $2, updated_at = $3
 		WHERE id = $1`
 
-	result, err := r.db.Exec(ctx, query, id, status, time.Now())
+	result, err := conn(ctx, r.db).Exec(ctx, query, id, status, time.Now())
 	if err != nil {
 		return err
 	}
@@ -178,15 +144,169 @@ func (r *CardRepositoryImpl) UpdateStatus(ctx context.Context, id int, status st
 	return nil
 }
 
+// Cancel аннулирует карту условным обновлением и сообщает, аннулирована ли она этим вызовом.
+// false — карта уже была аннулирована, в том числе параллельной транзакцией
+func (r *CardRepositoryImpl) Cancel(ctx context.Context, id int) (bool, error) {
+	query := `
+		UPDATE cards
+		SET status = $2, updated_at = $3
+		WHERE id = $1 AND status <> $2`
+
+	result, err := conn(ctx, r.db).Exec(ctx, query, id, domain.CardStatusCancelled, time.Now())
+	if err != nil {
+		return false, err
+	}
+
+	return result.RowsAffected() == 1, nil
+}
+
 // GetActiveCardsByAccount получает активные карты счета
 func (r *CardRepositoryImpl) GetActiveCardsByAccount(ctx context.Context, accountID int) ([]*domain.Card, error) {
 	query := `
-		SELECT id, account_id, encrypted_data, hmac, cvv_hash, expiry_date, status, created_at, updated_at
+		SELECT ` + cardColumns + `
 		FROM cards
 		WHERE account_id = $1 AND status = 'active' AND expiry_date > NOW()
 		ORDER BY created_at DESC`
 
-	rows, err := r.db.Query(ctx, query, accountID)
+	return r.list(ctx, query, accountID)
+}
+
+// ListWithoutPANHash получает карты, выпущенные до появления поиска по номеру
+func (r *CardRepositoryImpl) ListWithoutPANHash(ctx context.Context) ([]*domain.Card, error) {
+	query := `SELECT ` + cardColumns + ` FROM cards WHERE pan_hash = '' ORDER BY id`
+
+	return r.list(ctx, query)
+}
+
+// SetPANHash сохраняет HMAC номера карты
+func (r *CardRepositoryImpl) SetPANHash(ctx context.Context, id int, panHash string) error {
+	result, err := conn(ctx, r.db).Exec(ctx, `UPDATE cards SET pan_hash = $2 WHERE id = $1`, id, panHash)
+	if err != nil {
+		return err
+	}
+
+	if result.RowsAffected() == 0 {
+		return errors.New("card not found")
+	}
+
+	return nil
+}
+
+// RecordCVVFailure увеличивает счетчик неверных CVV и блокирует карту, если он достиг maxAttempts.
+// Возвращает новое значение счетчика и статус карты.
+func (r *CardRepositoryImpl) RecordCVVFailure(ctx context.Context, id, maxAttempts int) (int, string, error) {
+	query := `
+		UPDATE cards
+		SET cvv_failed_attempts = cvv_failed_attempts + 1,
+			status = CASE WHEN cvv_failed_attempts + 1 >= $2 THEN 'blocked' ELSE status END,
+			updated_at = $3
+		WHERE id = $1
+		RETURNING cvv_failed_attempts, status`
+
+	var attempts int
+	var status string
+	err := conn(ctx, r.db).QueryRow(ctx, query, id, maxAttempts, time.Now()).Scan(&attempts, &status)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return 0, "", errors.New("card not found")
+		}
+		return 0, "", err
+	}
+
+	return attempts, status, nil
+}
+
+// ResetCVVFailures сбрасывает счетчик неверных CVV после успешной проверки
+func (r *CardRepositoryImpl) ResetCVVFailures(ctx context.Context, id int) error {
+	_, err := conn(ctx, r.db).Exec(ctx, `
+		UPDATE cards SET cvv_failed_attempts = 0 WHERE id = $1 AND cvv_failed_attempts > 0`, id)
+	return err
+}
+
+// SetPIN сохраняет хеш PIN-блока и соль карты и сбрасывает счетчик неверных PIN
+func (r *CardRepositoryImpl) SetPIN(ctx context.Context, id int, pinHash, pinSalt string) error {
+	query := `
+		UPDATE cards
+		SET pin_hash = $2, pin_salt = $3, pin_failed_attempts = 0, updated_at = $4
+		WHERE id = $1`
+
+	result, err := conn(ctx, r.db).Exec(ctx, query, id, pinHash, pinSalt, time.Now())
+	if err != nil {
+		return err
+	}
+
+	if result.RowsAffected() == 0 {
+		return errors.New("card not found")
+	}
+
+	return nil
+}
+
+// RecordPINFailure увеличивает счетчик неверных PIN и блокирует карту, если он достиг maxAttempts.
+// Возвращает новое значение счетчика и статус карты.
+func (r *CardRepositoryImpl) RecordPINFailure(ctx context.Context, id, maxAttempts int) (int, string, error) {
+	query := `
+		UPDATE cards
+		SET pin_failed_attempts = pin_failed_attempts + 1,
+			status = CASE WHEN pin_failed_attempts + 1 >= $2 THEN 'blocked' ELSE status END,
+			updated_at = $3
+		WHERE id = $1
+		RETURNING pin_failed_attempts, status`
+
+	var attempts int
+	var status string
+	err := conn(ctx, r.db).QueryRow(ctx, query, id, maxAttempts, time.Now()).Scan(&attempts, &status)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return 0, "", errors.New("card not found")
+		}
+		return 0, "", err
+	}
+
+	return attempts, status, nil
+}
+
+// ResetPINFailures сбрасывает счетчик неверных PIN после успешной проверки
+func (r *CardRepositoryImpl) ResetPINFailures(ctx context.Context, id int) error {
+	_, err := conn(ctx, r.db).Exec(ctx, `
+		UPDATE cards SET pin_failed_attempts = 0 WHERE id = $1 AND pin_failed_attempts > 0`, id)
+	return err
+}
+
+// Unblock активирует заблокированную карту и сбрасывает счетчики неверных PIN и CVV
+func (r *CardRepositoryImpl) Unblock(ctx context.Context, id int) error {
+	query := `
+		UPDATE cards
+		SET status = 'active', pin_failed_attempts = 0, cvv_failed_attempts = 0, updated_at = $2
+		WHERE id = $1 AND status = 'blocked'`
+
+	result, err := conn(ctx, r.db).Exec(ctx, query, id, time.Now())
+	if err != nil {
+		return err
+	}
+
+	if result.RowsAffected() == 0 {
+		return domain.ErrCardNotBlocked
+	}
+
+	return nil
+}
+
+func (r *CardRepositoryImpl) get(ctx context.Context, query string, args ...any) (*domain.Card, error) {
+	card := &domain.Card{}
+	err := scanCard(conn(ctx, r.db).QueryRow(ctx, query, args...), card)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, errors.New("card not found")
+		}
+		return nil, err
+	}
+
+	return card, nil
+}
+
+func (r *CardRepositoryImpl) list(ctx context.Context, query string, args ...any) ([]*domain.Card, error) {
+	rows, err := conn(ctx, r.db).Query(ctx, query, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -195,22 +315,37 @@ func (r *CardRepositoryImpl) GetActiveCardsByAccount(ctx context.Context, accoun
 	var cards []*domain.Card
 	for rows.Next() {
 		card := &domain.Card{}
-		err := rows.Scan(
-			&card.ID,
-			&card.AccountID,
-			&card.EncryptedData,
-			&card.HMAC,
-			&card.CVVHash,
-			&card.ExpiryDate,
-			&card.Status,
-			&card.CreatedAt,
-			&card.UpdatedAt,
-		)
-		if err != nil {
+		if err := scanCard(rows, card); err != nil {
 			return nil, err
 		}
 		cards = append(cards, card)
 	}
 
-	return cards, nil
+	return cards, rows.Err()
+}
+
+// cardColumns список колонок карты
+const cardColumns = `id, account_id, COALESCE(holder_id, 0), encrypted_data, hmac, cvv_hash, pan_hash, expiry_date,
+	status, card_type, COALESCE(merchant_lock, ''), cvv_failed_attempts, pin_hash, pin_salt, pin_failed_attempts, created_at, updated_at`
+
+func scanCard(row pgx.Row, card *domain.Card) error {
+	return row.Scan(
+		&card.ID,
+		&card.AccountID,
+		&card.HolderID,
+		&card.EncryptedData,
+		&card.HMAC,
+		&card.CVVHash,
+		&card.PANHash,
+		&card.ExpiryDate,
+		&card.Status,
+		&card.CardType,
+		&card.MerchantLock,
+		&card.CVVFailedAttempts,
+		&card.PINHash,
+		&card.PINSalt,
+		&card.PINFailedAttempts,
+		&card.CreatedAt,
+		&card.UpdatedAt,
+	)
 }
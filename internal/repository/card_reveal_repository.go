@@ -0,0 +1,92 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/vterdunov/learn-bank-app/internal/domain"
+)
+
+// CardRevealRepositoryImpl реализация CardRevealRepository
+type CardRevealRepositoryImpl struct {
+	db *pgxpool.Pool
+}
+
+// NewCardRevealRepository создает новый экземпляр CardRevealRepository
+func NewCardRevealRepository(db *pgxpool.Pool) CardRevealRepository {
+	return &CardRevealRepositoryImpl{db: db}
+}
+
+// Create сохраняет хеш выданного токена показа реквизитов
+func (r *CardRevealRepositoryImpl) Create(ctx context.Context, token *domain.CardRevealToken) error {
+	query := `
+		INSERT INTO card_reveal_tokens (card_id, user_id, token_hash, expires_at, created_at)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id`
+
+	return conn(ctx, r.db).QueryRow(ctx, query,
+		token.CardID,
+		token.UserID,
+		token.TokenHash,
+		token.ExpiresAt,
+		token.CreatedAt,
+	).Scan(&token.ID)
+}
+
+// TakeAttempt записывает попытку получить токен показа реквизитов, если с since по карте было меньше limit попыток
+// (0 — без ограничения). Строка карты блокируется до конца транзакции: параллельные попытки не превысят лимит.
+func (r *CardRevealRepositoryImpl) TakeAttempt(ctx context.Context, cardID, userID, limit int, since, now time.Time) (bool, error) {
+	tx, err := conn(ctx, r.db).Begin(ctx)
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback(ctx)
+
+	if limit > 0 {
+		if _, err := tx.Exec(ctx, `SELECT id FROM cards WHERE id = $1 FOR UPDATE`, cardID); err != nil {
+			return false, err
+		}
+
+		var count int
+		err := tx.QueryRow(ctx,
+			`SELECT COUNT(*) FROM card_reveal_attempts WHERE card_id = $1 AND created_at >= $2`,
+			cardID, since).Scan(&count)
+		if err != nil {
+			return false, err
+		}
+		if count >= limit {
+			return false, nil
+		}
+	}
+
+	_, err = tx.Exec(ctx,
+		`INSERT INTO card_reveal_attempts (card_id, user_id, created_at) VALUES ($1, $2, $3)`,
+		cardID, userID, now)
+	if err != nil {
+		return false, err
+	}
+
+	return true, tx.Commit(ctx)
+}
+
+// Consume погашает действующий токен карты и пользователя.
+// Токен, который истек, уже использован или выдан на другую карту, не погашается.
+func (r *CardRevealRepositoryImpl) Consume(ctx context.Context, tokenHash string, cardID, userID int, now time.Time) error {
+	query := `
+		UPDATE card_reveal_tokens
+		SET used_at = $4
+		WHERE token_hash = $1 AND card_id = $2 AND user_id = $3 AND used_at IS NULL AND expires_at > $4`
+
+	result, err := conn(ctx, r.db).Exec(ctx, query, tokenHash, cardID, userID, now)
+	if err != nil {
+		return err
+	}
+
+	if result.RowsAffected() == 0 {
+		return domain.ErrCardRevealTokenInvalid
+	}
+
+	return nil
+}
@@ -0,0 +1,366 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/vterdunov/learn-bank-app/internal/domain"
+	"github.com/vterdunov/learn-bank-app/internal/utils"
+)
+
+// CashbackRepositoryImpl реализация CashbackRepository
+type CashbackRepositoryImpl struct {
+	db *pgxpool.Pool
+}
+
+// NewCashbackRepository создает новый экземпляр CashbackRepository
+func NewCashbackRepository(db *pgxpool.Pool) CashbackRepository {
+	return &CashbackRepositoryImpl{db: db}
+}
+
+// CreateRule создает правило; действующее правило на тот же MCC или категорию возвращает ErrCashbackRuleExists
+func (r *CashbackRepositoryImpl) CreateRule(ctx context.Context, rule *domain.CashbackRule) error {
+	query := `
+		INSERT INTO cashback_rules (mcc, category, rate, monthly_cap)
+		VALUES (NULLIF($1, ''), NULLIF($2, ''), $3, $4)
+		RETURNING id, created_at`
+
+	err := conn(ctx, r.db).QueryRow(ctx, query, rule.MCC, rule.Category, rule.Rate, rule.MonthlyCap).
+		Scan(&rule.ID, &rule.CreatedAt)
+	if err != nil {
+		if utils.IsUniqueViolation(utils.ParseDBError(err)) {
+			return domain.ErrCashbackRuleExists
+		}
+		return err
+	}
+
+	return nil
+}
+
+// ListRules получает действующие правила
+func (r *CashbackRepositoryImpl) ListRules(ctx context.Context) ([]*domain.CashbackRule, error) {
+	query := `SELECT ` + cashbackRuleColumns + ` FROM cashback_rules WHERE active ORDER BY id`
+
+	rows, err := conn(ctx, r.db).Query(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var rules []*domain.CashbackRule
+	for rows.Next() {
+		rule := &domain.CashbackRule{}
+		if err := scanCashbackRule(rows, rule); err != nil {
+			return nil, err
+		}
+		rules = append(rules, rule)
+	}
+
+	return rules, rows.Err()
+}
+
+// FindRule получает действующее правило для MCC, а при его отсутствии — для категории
+func (r *CashbackRepositoryImpl) FindRule(ctx context.Context, mcc, category string) (*domain.CashbackRule, error) {
+	query := `
+		SELECT ` + cashbackRuleColumns + `
+		FROM cashback_rules
+		WHERE active AND (mcc = NULLIF($1, '') OR category = $2)
+		ORDER BY mcc IS NULL
+		LIMIT 1`
+
+	rule := &domain.CashbackRule{}
+	err := scanCashbackRule(conn(ctx, r.db).QueryRow(ctx, query, mcc, category), rule)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.ErrCashbackRuleNotFound
+		}
+		return nil, err
+	}
+
+	return rule, nil
+}
+
+// DeactivateRule отключает правило; начисления по нему сохраняются
+func (r *CashbackRepositoryImpl) DeactivateRule(ctx context.Context, id int) error {
+	result, err := conn(ctx, r.db).Exec(ctx, `UPDATE cashback_rules SET active = FALSE WHERE id = $1 AND active`, id)
+	if err != nil {
+		return err
+	}
+
+	if result.RowsAffected() == 0 {
+		return domain.ErrCashbackRuleNotFound
+	}
+
+	return nil
+}
+
+// AddEntry записывает начисление или сторно; повторное начисление за платеж возвращает false
+func (r *CashbackRepositoryImpl) AddEntry(ctx context.Context, entry *domain.CashbackEntry) (bool, error) {
+	query := `
+		INSERT INTO cashback_entries
+			(card_id, account_id, transaction_id, rule_id, kind, period, base_amount, rate, amount)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		ON CONFLICT (transaction_id) WHERE kind = 'accrual' DO NOTHING
+		RETURNING id, created_at`
+
+	err := conn(ctx, r.db).QueryRow(ctx, query,
+		entry.CardID,
+		entry.AccountID,
+		entry.TransactionID,
+		entry.RuleID,
+		entry.Kind,
+		entry.Period,
+		entry.BaseAmount,
+		entry.Rate,
+		entry.Amount,
+	).Scan(&entry.ID, &entry.CreatedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return false, nil
+		}
+		return false, err
+	}
+
+	return true, nil
+}
+
+// GetPaymentCashback получает начисление за платеж и сумму его сторно
+func (r *CashbackRepositoryImpl) GetPaymentCashback(ctx context.Context, transactionID int) (*domain.PaymentCashback, error) {
+	query := `
+		SELECT ` + cashbackEntryColumns + `,
+			(SELECT COALESCE(-SUM(amount), 0) FROM cashback_entries
+			 WHERE transaction_id = $1 AND kind = 'reversal')
+		FROM cashback_entries
+		WHERE transaction_id = $1 AND kind = 'accrual'`
+
+	cashback := &domain.PaymentCashback{Accrual: &domain.CashbackEntry{}}
+	row := conn(ctx, r.db).QueryRow(ctx, query, transactionID)
+	err := row.Scan(append(cashbackEntryFields(cashback.Accrual), &cashback.Reversed)...)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.ErrCashbackNotAccrued
+		}
+		return nil, err
+	}
+
+	return cashback, nil
+}
+
+// GetPeriodTotals получает кэшбэк по карте за месяц: всего и по правилу (ruleID может быть nil)
+func (r *CashbackRepositoryImpl) GetPeriodTotals(ctx context.Context, cardID int, period time.Time, ruleID *int) (*domain.CashbackPeriodTotals, error) {
+	query := `
+		SELECT COALESCE(SUM(amount), 0), COALESCE(SUM(amount) FILTER (WHERE rule_id = $3), 0)
+		FROM cashback_entries
+		WHERE card_id = $1 AND period = $2`
+
+	totals := &domain.CashbackPeriodTotals{}
+	err := conn(ctx, r.db).QueryRow(ctx, query, cardID, period, ruleID).Scan(&totals.Card, &totals.Rule)
+	if err != nil {
+		return nil, err
+	}
+
+	return totals, nil
+}
+
+// HasPayout проверяет, выплачен ли кэшбэк по карте за месяц
+func (r *CashbackRepositoryImpl) HasPayout(ctx context.Context, cardID int, period time.Time) (bool, error) {
+	var exists bool
+	err := conn(ctx, r.db).QueryRow(ctx,
+		`SELECT EXISTS (SELECT 1 FROM cashback_payouts WHERE card_id = $1 AND period = $2)`,
+		cardID, period).Scan(&exists)
+
+	return exists, err
+}
+
+// GetBalance получает кэшбэк карты, ожидающий выплаты, и сумму выплат
+func (r *CashbackRepositoryImpl) GetBalance(ctx context.Context, cardID int) (*domain.CashbackBalance, error) {
+	query := `
+		SELECT
+			(SELECT COALESCE(SUM(e.amount), 0) FROM cashback_entries e
+			 WHERE e.card_id = $1
+			   AND NOT EXISTS (SELECT 1 FROM cashback_payouts p WHERE p.card_id = e.card_id AND p.period = e.period)),
+			(SELECT COALESCE(SUM(amount), 0) FROM cashback_payouts WHERE card_id = $1)`
+
+	balance := &domain.CashbackBalance{}
+	if err := conn(ctx, r.db).QueryRow(ctx, query, cardID).Scan(&balance.Pending, &balance.Paid); err != nil {
+		return nil, err
+	}
+
+	return balance, nil
+}
+
+// ListEntries получает последние начисления и сторно по карте (новые первыми)
+func (r *CashbackRepositoryImpl) ListEntries(ctx context.Context, cardID, limit int) ([]*domain.CashbackEntry, error) {
+	query := `
+		SELECT ` + cashbackEntryColumns + `
+		FROM cashback_entries
+		WHERE card_id = $1
+		ORDER BY id DESC
+		LIMIT $2`
+
+	rows, err := conn(ctx, r.db).Query(ctx, query, cardID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var entries []*domain.CashbackEntry
+	for rows.Next() {
+		entry := &domain.CashbackEntry{}
+		if err := rows.Scan(cashbackEntryFields(entry)...); err != nil {
+			return nil, err
+		}
+		entries = append(entries, entry)
+	}
+
+	return entries, rows.Err()
+}
+
+// ListPayouts получает выплаты кэшбэка по карте (новые первыми)
+func (r *CashbackRepositoryImpl) ListPayouts(ctx context.Context, cardID int) ([]*domain.CashbackPayout, error) {
+	query := `
+		SELECT id, card_id, account_id, period, amount, transaction_id, created_at
+		FROM cashback_payouts
+		WHERE card_id = $1
+		ORDER BY period DESC`
+
+	rows, err := conn(ctx, r.db).Query(ctx, query, cardID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var payouts []*domain.CashbackPayout
+	for rows.Next() {
+		payout := &domain.CashbackPayout{}
+		err := rows.Scan(
+			&payout.ID,
+			&payout.CardID,
+			&payout.AccountID,
+			&payout.Period,
+			&payout.Amount,
+			&payout.TransactionID,
+			&payout.CreatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		payouts = append(payouts, payout)
+	}
+
+	return payouts, rows.Err()
+}
+
+// ListUnpaid получает невыплаченный кэшбэк по картам за месяцы до before (счет — текущий счет карты)
+func (r *CashbackRepositoryImpl) ListUnpaid(ctx context.Context, before time.Time) ([]*domain.CashbackPayout, error) {
+	query := `
+		SELECT e.card_id, c.account_id, e.period, SUM(e.amount)
+		FROM cashback_entries e
+		JOIN cards c ON c.id = e.card_id
+		WHERE e.period < $1
+		  AND NOT EXISTS (SELECT 1 FROM cashback_payouts p WHERE p.card_id = e.card_id AND p.period = e.period)
+		GROUP BY e.card_id, c.account_id, e.period
+		ORDER BY e.period, e.card_id`
+
+	rows, err := conn(ctx, r.db).Query(ctx, query, before)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var payouts []*domain.CashbackPayout
+	for rows.Next() {
+		payout := &domain.CashbackPayout{}
+		if err := rows.Scan(&payout.CardID, &payout.AccountID, &payout.Period, &payout.Amount); err != nil {
+			return nil, err
+		}
+		payouts = append(payouts, payout)
+	}
+
+	return payouts, rows.Err()
+}
+
+// CreatePayout фиксирует выплату за месяц и зачисляет положительную сумму на счет карты транзакцией типа cashback.
+// Возвращает false, если выплата за месяц уже была.
+func (r *CashbackRepositoryImpl) CreatePayout(ctx context.Context, payout *domain.CashbackPayout, description string) (bool, error) {
+	q := conn(ctx, r.db)
+
+	err := q.QueryRow(ctx, `
+		INSERT INTO cashback_payouts (card_id, account_id, period, amount)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (card_id, period) DO NOTHING
+		RETURNING id, created_at`,
+		payout.CardID, payout.AccountID, payout.Period, payout.Amount,
+	).Scan(&payout.ID, &payout.CreatedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return false, nil
+		}
+		return false, err
+	}
+
+	if payout.Amount <= 0 {
+		return true, nil
+	}
+
+	now := time.Now()
+	if _, err := q.Exec(ctx, `UPDATE accounts SET balance = balance + $2, updated_at = $3 WHERE id = $1`,
+		payout.AccountID, payout.Amount, now); err != nil {
+		return false, err
+	}
+
+	var transactionID int
+	err = q.QueryRow(ctx, `
+		INSERT INTO transactions (from_account, to_account, amount, type, status, description, created_at, updated_at)
+		VALUES (NULL, $1, $2, $3, $4, $5, $6, $6)
+		RETURNING id`,
+		payout.AccountID, payout.Amount, domain.TransactionTypeCashback, domain.TransactionStatusCompleted, description, now,
+	).Scan(&transactionID)
+	if err != nil {
+		return false, err
+	}
+
+	if _, err := q.Exec(ctx, `UPDATE cashback_payouts SET transaction_id = $2 WHERE id = $1`, payout.ID, transactionID); err != nil {
+		return false, err
+	}
+	payout.TransactionID = &transactionID
+
+	return true, nil
+}
+
+// cashbackRuleColumns список колонок правила кэшбэка
+const cashbackRuleColumns = `id, COALESCE(mcc, ''), COALESCE(category, ''), rate, monthly_cap, created_at`
+
+func scanCashbackRule(row pgx.Row, rule *domain.CashbackRule) error {
+	return row.Scan(
+		&rule.ID,
+		&rule.MCC,
+		&rule.Category,
+		&rule.Rate,
+		&rule.MonthlyCap,
+		&rule.CreatedAt,
+	)
+}
+
+// cashbackEntryColumns список колонок записи кэшбэка
+const cashbackEntryColumns = `id, card_id, account_id, transaction_id, rule_id, kind, period, base_amount, rate, amount, created_at`
+
+func cashbackEntryFields(entry *domain.CashbackEntry) []any {
+	return []any{
+		&entry.ID,
+		&entry.CardID,
+		&entry.AccountID,
+		&entry.TransactionID,
+		&entry.RuleID,
+		&entry.Kind,
+		&entry.Period,
+		&entry.BaseAmount,
+		&entry.Rate,
+		&entry.Amount,
+		&entry.CreatedAt,
+	}
+}
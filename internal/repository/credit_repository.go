@@ -36,7 +36,7 @@ func (r *CreditRepositoryImpl) Create(ctx context.Context, credit *domain.Credit
 	startDate := now
 	endDate := startDate.AddDate(0, credit.TermMonths, 0)
 
-	err := r.db.QueryRow(ctx, query,
+	err := conn(ctx, r.db).QueryRow(ctx, query,
 		credit.UserID,
 		credit.AccountID,
 		credit.Amount,
@@ -62,7 +62,7 @@ func (r *CreditRepositoryImpl) GetByID(ctx context.Context, id int) (*domain.Cre
 		WHERE id = $1`
 
 	credit := &domain.Credit{}
-	err := r.db.QueryRow(ctx, query, id).Scan(
+	err := conn(ctx, r.db).QueryRow(ctx, query, id).Scan(
 		&credit.ID,
 		&credit.UserID,
 		&credit.AccountID,
@@ -94,7 +94,7 @@ func (r *CreditRepositoryImpl) GetByUserID(ctx context.Context, userID int) ([]*
 		WHERE user_id = $1
 		ORDER BY created_at DESC`
 
-	rows, err := r.db.Query(ctx, query, userID)
+	rows, err := conn(ctx, r.db).Query(ctx, query, userID)
 	if err != nil {
 		return nil, err
 	}
@@ -133,7 +133,7 @@ func (r *CreditRepositoryImpl) GetByAccountID(ctx context.Context, accountID int
 		WHERE account_id = $1
 		ORDER BY created_at DESC`
 
-	rows, err := r.db.Query(ctx, query, accountID)
+	rows, err := conn(ctx, r.db).Query(ctx, query, accountID)
 	if err != nil {
 		return nil, err
 	}
@@ -173,7 +173,7 @@ func (r *CreditRepositoryImpl) Update(ctx context.Context, credit *domain.Credit
 
 	credit.UpdatedAt = time.Now()
 
-	result, err := r.db.Exec(ctx, query,
+	result, err := conn(ctx, r.db).Exec(ctx, query,
 		credit.ID,
 		credit.Amount,
 		credit.InterestRate,
@@ -199,7 +199,7 @@ func (r *CreditRepositoryImpl) Update(ctx context.Context, credit *domain.Credit
 func (r *CreditRepositoryImpl) Delete(ctx context.Context, id int) error {
 	query := `DELETE FROM credits WHERE id = $1`
 
-	result, err := r.db.Exec(ctx, query, id)
+	result, err := conn(ctx, r.db).Exec(ctx, query, id)
 	if err != nil {
 		return err
 	}
@@ -218,7 +218,7 @@ func (r *CreditRepositoryImpl) UpdateRemainingDebt(ctx context.Context, id int,
 		SET remaining_debt = $2, updated_at = $3
 		WHERE id = $1`
 
-	result, err := r.db.Exec(ctx, query, id, remainingDebt, time.Now())
+	result, err := conn(ctx, r.db).Exec(ctx, query, id, remainingDebt, time.Now())
 	if err != nil {
 		return err
 	}
@@ -238,7 +238,7 @@ func (r *CreditRepositoryImpl) GetActiveCredits(ctx context.Context) ([]*domain.
 		WHERE status = 'active' AND remaining_debt > 0
 		ORDER BY created_at ASC`
 
-	rows, err := r.db.Query(ctx, query)
+	rows, err := conn(ctx, r.db).Query(ctx, query)
 	if err != nil {
 		return nil, err
 	}
@@ -281,7 +281,7 @@ func (r *CreditRepositoryImpl) GetCreditAnalytics(ctx context.Context, userID in
 		WHERE user_id = $1 AND status IN ('active', 'overdue')`
 
 	analytics := &domain.CreditAnalytics{}
-	err := r.db.QueryRow(ctx, query, userID).Scan(
+	err := conn(ctx, r.db).QueryRow(ctx, query, userID).Scan(
 		&analytics.TotalCredits,
 		&analytics.TotalDebt,
 		&analytics.MonthlyPayments,
@@ -316,7 +316,7 @@ func (r *PaymentScheduleRepositoryImpl) Create(ctx context.Context, payment *dom
 	payment.CreatedAt = now
 	payment.UpdatedAt = now
 
-	err := r.db.QueryRow(ctx, query,
+	err := conn(ctx, r.db).QueryRow(ctx, query,
 		payment.CreditID,
 		payment.PaymentNumber,
 		payment.DueDate,
@@ -334,7 +334,7 @@ func (r *PaymentScheduleRepositoryImpl) Create(ctx context.Context, payment *dom
 
 // CreateBatch создает несколько платежей одновременно
 func (r *PaymentScheduleRepositoryImpl) CreateBatch(ctx context.Context, payments []*domain.PaymentSchedule) error {
-	tx, err := r.db.Begin(ctx)
+	tx, err := conn(ctx, r.db).Begin(ctx)
 	if err != nil {
 		return err
 	}
@@ -378,7 +378,7 @@ func (r *PaymentScheduleRepositoryImpl) GetByID(ctx context.Context, id int) (*d
 		WHERE id = $1`
 
 	payment := &domain.PaymentSchedule{}
-	err := r.db.QueryRow(ctx, query, id).Scan(
+	err := conn(ctx, r.db).QueryRow(ctx, query, id).Scan(
 		&payment.ID,
 		&payment.CreditID,
 		&payment.PaymentNumber,
@@ -441,7 +441,7 @@ func (r *PaymentScheduleRepositoryImpl) GetByCreditID(ctx context.Context, credi
 		WHERE credit_id = $1
 		ORDER BY payment_number ASC`
 
-	rows, err := r.db.Query(ctx, query, creditID)
+	rows, err := conn(ctx, r.db).Query(ctx, query, creditID)
 	if err != nil {
 		return nil, err
 	}
@@ -459,7 +459,7 @@ func (r *PaymentScheduleRepositoryImpl) Update(ctx context.Context, payment *dom
 
 	payment.UpdatedAt = time.Now()
 
-	result, err := r.db.Exec(ctx, query,
+	result, err := conn(ctx, r.db).Exec(ctx, query,
 		payment.ID,
 		payment.PaymentAmount,
 		payment.PrincipalAmount,
@@ -486,7 +486,7 @@ func (r *PaymentScheduleRepositoryImpl) Update(ctx context.Context, payment *dom
 func (r *PaymentScheduleRepositoryImpl) Delete(ctx context.Context, id int) error {
 	query := `DELETE FROM payment_schedules WHERE id = $1`
 
-	result, err := r.db.Exec(ctx, query, id)
+	result, err := conn(ctx, r.db).Exec(ctx, query, id)
 	if err != nil {
 		return err
 	}
@@ -509,7 +509,7 @@ func (r *PaymentScheduleRepositoryImpl) GetOverduePayments(ctx context.Context)
 		  AND c.status = 'active'
 		ORDER BY ps.due_date ASC`
 
-	rows, err := r.db.Query(ctx, query)
+	rows, err := conn(ctx, r.db).Query(ctx, query)
 	if err != nil {
 		return nil, err
 	}
@@ -529,7 +529,7 @@ func (r *PaymentScheduleRepositoryImpl) GetUpcomingPayments(ctx context.Context,
 		  AND c.status = 'active'
 		ORDER BY ps.due_date ASC`
 
-	rows, err := r.db.Query(ctx, query, days)
+	rows, err := conn(ctx, r.db).Query(ctx, query, days)
 	if err != nil {
 		return nil, err
 	}
@@ -545,7 +545,7 @@ func (r *PaymentScheduleRepositoryImpl) MarkAsPaid(ctx context.Context, id int,
 		SET status = 'paid', paid_date = $2, updated_at = $3
 		WHERE id = $1`
 
-	result, err := r.db.Exec(ctx, query, id, paidDate, time.Now())
+	result, err := conn(ctx, r.db).Exec(ctx, query, id, paidDate, time.Now())
 	if err != nil {
 		return err
 	}
@@ -564,7 +564,7 @@ func (r *PaymentScheduleRepositoryImpl) AddPenalty(ctx context.Context, id int,
 		SET penalty_amount = penalty_amount + $2, status = 'overdue', updated_at = $3
 		WHERE id = $1`
 
-	result, err := r.db.Exec(ctx, query, id, penaltyAmount, time.Now())
+	result, err := conn(ctx, r.db).Exec(ctx, query, id, penaltyAmount, time.Now())
 	if err != nil {
 		return err
 	}
@@ -0,0 +1,232 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/vterdunov/learn-bank-app/internal/domain"
+)
+
+// DepositRepositoryImpl реализация DepositRepository
+type DepositRepositoryImpl struct {
+	db *pgxpool.Pool
+}
+
+// NewDepositRepository создает новый экземпляр DepositRepository
+func NewDepositRepository(db *pgxpool.Pool) DepositRepository {
+	return &DepositRepositoryImpl{db: db}
+}
+
+// Open создает вклад, списывает сумму со счета и записывает транзакцию.
+// Возвращает domain.ErrDepositInsufficientFunds, если на счете недостаточно средств.
+func (r *DepositRepositoryImpl) Open(ctx context.Context, deposit *domain.Deposit, description string) error {
+	q := conn(ctx, r.db)
+
+	tag, err := q.Exec(ctx, `
+		UPDATE accounts
+		SET balance = balance - $2
+		WHERE id = $1 AND balance - held_amount >= $2`,
+		deposit.AccountID, deposit.Amount)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.ErrDepositInsufficientFunds
+	}
+
+	query := `
+		INSERT INTO deposits (user_id, account_id, product, kind, amount, balance, interest_rate, capitalization,
+			term_months, opened_on, maturity_date, status, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)
+		RETURNING id`
+
+	now := time.Now()
+	deposit.CreatedAt = now
+	deposit.UpdatedAt = now
+
+	err = q.QueryRow(ctx, query,
+		deposit.UserID,
+		deposit.AccountID,
+		deposit.Product,
+		deposit.Kind,
+		deposit.Amount,
+		deposit.Balance,
+		deposit.InterestRate,
+		deposit.Capitalization,
+		deposit.TermMonths,
+		deposit.OpenedOn,
+		deposit.MaturityDate,
+		deposit.Status,
+		deposit.CreatedAt,
+		deposit.UpdatedAt,
+	).Scan(&deposit.ID)
+	if err != nil {
+		return err
+	}
+
+	_, err = q.Exec(ctx, `
+		INSERT INTO transactions (from_account, to_account, amount, type, status, description, created_at, updated_at)
+		VALUES ($1, NULL, $2, $3, $4, $5, $6, $6)`,
+		deposit.AccountID, deposit.Amount, domain.TransactionTypeDepositOpen, domain.TransactionStatusCompleted, description, now)
+
+	return err
+}
+
+// GetByID получает вклад по ID
+func (r *DepositRepositoryImpl) GetByID(ctx context.Context, id int) (*domain.Deposit, error) {
+	query := `SELECT ` + depositColumns + ` FROM deposits WHERE id = $1`
+
+	deposit := &domain.Deposit{}
+	err := scanDeposit(conn(ctx, r.db).QueryRow(ctx, query, id), deposit)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.ErrDepositNotFound
+		}
+		return nil, err
+	}
+
+	return deposit, nil
+}
+
+// GetByUserID получает вклады пользователя (новые первыми)
+func (r *DepositRepositoryImpl) GetByUserID(ctx context.Context, userID int) ([]*domain.Deposit, error) {
+	query := `SELECT ` + depositColumns + ` FROM deposits WHERE user_id = $1 ORDER BY id DESC`
+
+	return r.list(ctx, query, userID)
+}
+
+// ListForAccrual возвращает активные вклады, по которым начисляются проценты за день.
+// Проценты начисляются со дня, следующего за открытием, по день окончания срока включительно.
+func (r *DepositRepositoryImpl) ListForAccrual(ctx context.Context, date time.Time) ([]*domain.Deposit, error) {
+	query := `
+		SELECT ` + depositColumns + `
+		FROM deposits
+		WHERE status = 'active' AND opened_on < $1 AND (maturity_date IS NULL OR maturity_date >= $1)
+		ORDER BY id`
+
+	return r.list(ctx, query, date)
+}
+
+// ListForCapitalization возвращает вклады с ежемесячной капитализацией, накопившие хотя бы копейку
+func (r *DepositRepositoryImpl) ListForCapitalization(ctx context.Context) ([]*domain.Deposit, error) {
+	query := `
+		SELECT ` + depositColumns + `
+		FROM deposits
+		WHERE status = 'active' AND capitalization = 'monthly' AND accrued_interest >= 0.01
+		ORDER BY id`
+
+	return r.list(ctx, query)
+}
+
+// Capitalize прибавляет накопленные проценты к сумме вклада
+func (r *DepositRepositoryImpl) Capitalize(ctx context.Context, id int, amount float64) error {
+	query := `
+		UPDATE deposits
+		SET balance = balance + $2, accrued_interest = accrued_interest - $2, interest_paid = interest_paid + $2
+		WHERE id = $1`
+
+	_, err := conn(ctx, r.db).Exec(ctx, query, id, amount)
+	return err
+}
+
+// ListMatured возвращает активные срочные вклады, срок которых истек к указанной дате
+func (r *DepositRepositoryImpl) ListMatured(ctx context.Context, date time.Time) ([]*domain.Deposit, error) {
+	query := `
+		SELECT ` + depositColumns + `
+		FROM deposits
+		WHERE status = 'active' AND maturity_date <= $1
+		ORDER BY id`
+
+	return r.list(ctx, query, date)
+}
+
+// Settle закрывает вклад: сохраняет итог, зачисляет выплату на счет и записывает транзакцию.
+// Возвращает domain.ErrDepositNotActive, если вклад уже закрыт.
+func (r *DepositRepositoryImpl) Settle(ctx context.Context, deposit *domain.Deposit, description string) error {
+	q := conn(ctx, r.db)
+
+	tag, err := q.Exec(ctx, `
+		UPDATE deposits
+		SET status = $2, balance = $3, accrued_interest = $4, interest_paid = $5, payout = $6, closed_at = $7
+		WHERE id = $1 AND status = 'active'`,
+		deposit.ID,
+		deposit.Status,
+		deposit.Balance,
+		deposit.AccruedInterest,
+		deposit.InterestPaid,
+		deposit.Payout,
+		deposit.ClosedAt,
+	)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.ErrDepositNotActive
+	}
+
+	if deposit.Payout == nil || *deposit.Payout <= 0 {
+		return nil
+	}
+
+	if _, err := q.Exec(ctx, `UPDATE accounts SET balance = balance + $2 WHERE id = $1`, deposit.AccountID, *deposit.Payout); err != nil {
+		return err
+	}
+
+	_, err = q.Exec(ctx, `
+		INSERT INTO transactions (from_account, to_account, amount, type, status, description, created_at, updated_at)
+		VALUES (NULL, $1, $2, $3, $4, $5, $6, $6)`,
+		deposit.AccountID, *deposit.Payout, domain.TransactionTypeDepositPayout, domain.TransactionStatusCompleted, description, time.Now())
+
+	return err
+}
+
+func (r *DepositRepositoryImpl) list(ctx context.Context, query string, args ...any) ([]*domain.Deposit, error) {
+	rows, err := conn(ctx, r.db).Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var deposits []*domain.Deposit
+	for rows.Next() {
+		deposit := &domain.Deposit{}
+		if err := scanDeposit(rows, deposit); err != nil {
+			return nil, err
+		}
+		deposits = append(deposits, deposit)
+	}
+
+	return deposits, rows.Err()
+}
+
+// depositColumns список колонок вклада
+const depositColumns = `id, user_id, account_id, product, kind, amount, balance, interest_rate, capitalization, term_months,
+	opened_on, maturity_date, accrued_interest, interest_paid, payout, status, closed_at, created_at, updated_at`
+
+func scanDeposit(row pgx.Row, deposit *domain.Deposit) error {
+	return row.Scan(
+		&deposit.ID,
+		&deposit.UserID,
+		&deposit.AccountID,
+		&deposit.Product,
+		&deposit.Kind,
+		&deposit.Amount,
+		&deposit.Balance,
+		&deposit.InterestRate,
+		&deposit.Capitalization,
+		&deposit.TermMonths,
+		&deposit.OpenedOn,
+		&deposit.MaturityDate,
+		&deposit.AccruedInterest,
+		&deposit.InterestPaid,
+		&deposit.Payout,
+		&deposit.Status,
+		&deposit.ClosedAt,
+		&deposit.CreatedAt,
+		&deposit.UpdatedAt,
+	)
+}
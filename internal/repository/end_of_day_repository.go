@@ -0,0 +1,353 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/vterdunov/learn-bank-app/internal/domain"
+)
+
+// EndOfDayRepositoryImpl реализация EndOfDayRepository
+type EndOfDayRepositoryImpl struct {
+	db *pgxpool.Pool
+}
+
+// NewEndOfDayRepository создает новый экземпляр EndOfDayRepository
+func NewEndOfDayRepository(db *pgxpool.Pool) EndOfDayRepository {
+	return &EndOfDayRepositoryImpl{db: db}
+}
+
+// CreateDay открывает закрытие дня. Возвращает false, если день уже закрыт
+// (или закрывается в параллельной транзакции, которая затем будет зафиксирована).
+func (r *EndOfDayRepositoryImpl) CreateDay(ctx context.Context, day *domain.BusinessDay) (bool, error) {
+	query := `
+		INSERT INTO business_days (business_date, closed_at)
+		VALUES ($1, $2)
+		ON CONFLICT (business_date) DO NOTHING`
+
+	day.ClosedAt = time.Now()
+
+	tag, err := conn(ctx, r.db).Exec(ctx, query, day.Date, day.ClosedAt)
+	if err != nil {
+		return false, err
+	}
+
+	return tag.RowsAffected() == 1, nil
+}
+
+// UpdateDayTotals сохраняет итоги начислений дня
+func (r *EndOfDayRepositoryImpl) UpdateDayTotals(ctx context.Context, day *domain.BusinessDay) error {
+	query := `
+		UPDATE business_days
+		SET credits_accrued = $2, accounts_accrued = $3, credit_interest = $4, savings_interest = $5, savings_posted = $6,
+			deposits_accrued = $7, deposit_interest = $8, deposits_matured = $9,
+			overdrafts_accrued = $10, overdraft_interest = $11, overdraft_charged = $12
+		WHERE business_date = $1`
+
+	_, err := conn(ctx, r.db).Exec(ctx, query,
+		day.Date,
+		day.CreditsAccrued,
+		day.AccountsAccrued,
+		day.CreditInterest,
+		day.SavingsInterest,
+		day.SavingsPosted,
+		day.DepositsAccrued,
+		day.DepositInterest,
+		day.DepositsMatured,
+		day.OverdraftsAccrued,
+		day.OverdraftInterest,
+		day.OverdraftCharged,
+	)
+
+	return err
+}
+
+// LastClosedDate возвращает последний закрытый операционный день (nil, если закрытий не было)
+func (r *EndOfDayRepositoryImpl) LastClosedDate(ctx context.Context) (*time.Time, error) {
+	query := `SELECT MAX(business_date) FROM business_days`
+
+	var date *time.Time
+	if err := conn(ctx, r.db).QueryRow(ctx, query).Scan(&date); err != nil {
+		return nil, err
+	}
+
+	return date, nil
+}
+
+// ListCreditsForAccrual возвращает кредиты с остатком долга, выданные до указанного дня.
+// Проценты начисляются со дня, следующего за выдачей.
+func (r *EndOfDayRepositoryImpl) ListCreditsForAccrual(ctx context.Context, date time.Time) ([]*domain.Credit, error) {
+	query := `
+		SELECT id, user_id, account_id, amount, interest_rate, term_months, monthly_payment, remaining_debt, status, created_at, updated_at
+		FROM credits
+		WHERE status IN ('active', 'overdue') AND remaining_debt > 0 AND created_at::date < $1
+		ORDER BY id`
+
+	rows, err := conn(ctx, r.db).Query(ctx, query, date)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var credits []*domain.Credit
+	for rows.Next() {
+		credit := &domain.Credit{}
+		err := rows.Scan(
+			&credit.ID,
+			&credit.UserID,
+			&credit.AccountID,
+			&credit.Amount,
+			&credit.InterestRate,
+			&credit.TermMonths,
+			&credit.MonthlyPayment,
+			&credit.RemainingDebt,
+			&credit.Status,
+			&credit.CreatedAt,
+			&credit.UpdatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		credits = append(credits, credit)
+	}
+
+	return credits, rows.Err()
+}
+
+// ListAccountsForAccrual возвращает активные накопительные счета (продукт savings) с положительным остатком
+func (r *EndOfDayRepositoryImpl) ListAccountsForAccrual(ctx context.Context) ([]*domain.Account, error) {
+	query := `
+		SELECT id, user_id, number, product, balance, currency, status, created_at, updated_at
+		FROM accounts
+		WHERE status = 'active' AND product = 'savings' AND balance > 0
+		ORDER BY id`
+
+	rows, err := conn(ctx, r.db).Query(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var accounts []*domain.Account
+	for rows.Next() {
+		account := &domain.Account{}
+		err := rows.Scan(
+			&account.ID,
+			&account.UserID,
+			&account.Number,
+			&account.Product,
+			&account.Balance,
+			&account.Currency,
+			&account.Status,
+			&account.CreatedAt,
+			&account.UpdatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		accounts = append(accounts, account)
+	}
+
+	return accounts, rows.Err()
+}
+
+// AddAccrual записывает начисление и увеличивает накопленные проценты кредита или счета.
+// Возвращает false, если начисление за этот день уже есть.
+func (r *EndOfDayRepositoryImpl) AddAccrual(ctx context.Context, accrual *domain.InterestAccrual) (bool, error) {
+	query := `
+		INSERT INTO interest_accruals (business_date, kind, credit_id, deposit_id, account_id, base_amount, annual_rate, amount, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		ON CONFLICT DO NOTHING
+		RETURNING id`
+
+	accrual.CreatedAt = time.Now()
+
+	q := conn(ctx, r.db)
+	err := q.QueryRow(ctx, query,
+		accrual.BusinessDate,
+		accrual.Kind,
+		accrual.CreditID,
+		accrual.DepositID,
+		accrual.AccountID,
+		accrual.BaseAmount,
+		accrual.AnnualRate,
+		accrual.Amount,
+		accrual.CreatedAt,
+	).Scan(&accrual.ID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return false, nil
+		}
+		return false, err
+	}
+
+	switch accrual.Kind {
+	case domain.InterestAccrualCredit:
+		_, err = q.Exec(ctx, `UPDATE credits SET accrued_interest = accrued_interest + $2 WHERE id = $1`, *accrual.CreditID, accrual.Amount)
+	case domain.InterestAccrualDeposit:
+		_, err = q.Exec(ctx, `UPDATE deposits SET accrued_interest = accrued_interest + $2 WHERE id = $1`, *accrual.DepositID, accrual.Amount)
+	case domain.InterestAccrualOverdraft:
+		_, err = q.Exec(ctx, `UPDATE accounts SET overdraft_interest = overdraft_interest + $2 WHERE id = $1`, accrual.AccountID, accrual.Amount)
+	default:
+		_, err = q.Exec(ctx, `UPDATE accounts SET accrued_interest = accrued_interest + $2 WHERE id = $1`, accrual.AccountID, accrual.Amount)
+	}
+	if err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+// ListAccruedSavings возвращает счета, у которых накопилась хотя бы копейка процентов
+func (r *EndOfDayRepositoryImpl) ListAccruedSavings(ctx context.Context) ([]*domain.AccruedSavings, error) {
+	query := `
+		SELECT id, accrued_interest
+		FROM accounts
+		WHERE status = 'active' AND accrued_interest >= 0.01
+		ORDER BY id`
+
+	rows, err := conn(ctx, r.db).Query(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var savings []*domain.AccruedSavings
+	for rows.Next() {
+		item := &domain.AccruedSavings{}
+		if err := rows.Scan(&item.AccountID, &item.Amount); err != nil {
+			return nil, err
+		}
+		savings = append(savings, item)
+	}
+
+	return savings, rows.Err()
+}
+
+// PostSavingsInterest зачисляет накопленные проценты на баланс счета и записывает транзакцию
+func (r *EndOfDayRepositoryImpl) PostSavingsInterest(ctx context.Context, accountID int, amount float64, description string) error {
+	q := conn(ctx, r.db)
+
+	query := `
+		UPDATE accounts
+		SET balance = balance + $2, accrued_interest = accrued_interest - $2
+		WHERE id = $1`
+	if _, err := q.Exec(ctx, query, accountID, amount); err != nil {
+		return err
+	}
+
+	now := time.Now()
+	_, err := q.Exec(ctx, `
+		INSERT INTO transactions (from_account, to_account, amount, type, status, description, created_at, updated_at)
+		VALUES (NULL, $1, $2, $3, $4, $5, $6, $6)`,
+		accountID, amount, domain.TransactionTypeInterest, domain.TransactionStatusCompleted, description, now)
+
+	return err
+}
+
+// ListOverdraftAccounts возвращает незакрытые счета с использованным овердрафтом
+func (r *EndOfDayRepositoryImpl) ListOverdraftAccounts(ctx context.Context) ([]*domain.Account, error) {
+	query := `
+		SELECT id, user_id, number, product, balance, overdraft_limit, currency, status, created_at, updated_at
+		FROM accounts
+		WHERE status <> 'closed' AND balance < 0
+		ORDER BY id`
+
+	rows, err := conn(ctx, r.db).Query(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var accounts []*domain.Account
+	for rows.Next() {
+		account := &domain.Account{}
+		err := rows.Scan(
+			&account.ID,
+			&account.UserID,
+			&account.Number,
+			&account.Product,
+			&account.Balance,
+			&account.OverdraftLimit,
+			&account.Currency,
+			&account.Status,
+			&account.CreatedAt,
+			&account.UpdatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		accounts = append(accounts, account)
+	}
+
+	return accounts, rows.Err()
+}
+
+// ListAccruedOverdraftInterest возвращает счета, у которых набралась хотя бы копейка процентов за овердрафт
+func (r *EndOfDayRepositoryImpl) ListAccruedOverdraftInterest(ctx context.Context) ([]*domain.AccruedOverdraftInterest, error) {
+	query := `
+		SELECT id, overdraft_interest
+		FROM accounts
+		WHERE status <> 'closed' AND overdraft_interest >= 0.01
+		ORDER BY id`
+
+	rows, err := conn(ctx, r.db).Query(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var accrued []*domain.AccruedOverdraftInterest
+	for rows.Next() {
+		item := &domain.AccruedOverdraftInterest{}
+		if err := rows.Scan(&item.AccountID, &item.Amount); err != nil {
+			return nil, err
+		}
+		accrued = append(accrued, item)
+	}
+
+	return accrued, rows.Err()
+}
+
+// ChargeOverdraftInterest списывает проценты за овердрафт с баланса счета в пределах лимита овердрафта
+// и записывает транзакцию. Возвращает списанную сумму: остаток процентов ждет пополнения счета.
+// Вызывается внутри транзакции закрытия дня, блокировка строки держится до ее конца.
+func (r *EndOfDayRepositoryImpl) ChargeOverdraftInterest(ctx context.Context, accountID int, amount float64, description string) (float64, error) {
+	q := conn(ctx, r.db)
+
+	var charged float64
+	err := q.QueryRow(ctx, `
+		SELECT LEAST($2::numeric, GREATEST(balance + overdraft_limit, 0))
+		FROM accounts
+		WHERE id = $1
+		FOR UPDATE`, accountID, amount).Scan(&charged)
+	if err != nil {
+		return 0, err
+	}
+	if charged <= 0 {
+		return 0, nil
+	}
+
+	query := `
+		UPDATE accounts
+		SET balance = balance - $2, overdraft_interest = overdraft_interest - $2
+		WHERE id = $1`
+	if _, err := q.Exec(ctx, query, accountID, charged); err != nil {
+		return 0, err
+	}
+
+	now := time.Now()
+	_, err = q.Exec(ctx, `
+		INSERT INTO transactions (from_account, to_account, amount, type, status, description, created_at, updated_at)
+		VALUES ($1, NULL, $2, $3, $4, $5, $6, $6)`,
+		accountID, charged, domain.TransactionTypeInterest, domain.TransactionStatusCompleted, description, now)
+	if err != nil {
+		return 0, err
+	}
+
+	return charged, nil
+}
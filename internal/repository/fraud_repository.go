@@ -0,0 +1,220 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/vterdunov/learn-bank-app/internal/domain"
+)
+
+// fraudReviewColumns колонки проверки в порядке scanFraudReview
+const fraudReviewColumns = `id, user_id, operation_type, account_id, to_account_id, card_id, merchant_id, amount,
+	ip, country, rules, status, reviewer_id, comment, reviewed_at, consumed_at, created_at, updated_at`
+
+// fraudOperationMatch условие совпадения проверки с операцией ($1..$7)
+const fraudOperationMatch = `user_id = $1 AND operation_type = $2 AND account_id = $3
+	AND to_account_id IS NOT DISTINCT FROM $4 AND card_id IS NOT DISTINCT FROM $5
+	AND merchant_id = $6 AND amount = $7`
+
+// FraudRepositoryImpl реализация FraudRepository
+type FraudRepositoryImpl struct {
+	db *pgxpool.Pool
+}
+
+// NewFraudRepository создает новый экземпляр FraudRepository
+func NewFraudRepository(db *pgxpool.Pool) FraudRepository {
+	return &FraudRepositoryImpl{db: db}
+}
+
+// CreateReview ставит операцию в очередь проверки
+func (r *FraudRepositoryImpl) CreateReview(ctx context.Context, review *domain.FraudReview) error {
+	query := `
+		INSERT INTO fraud_reviews (user_id, operation_type, account_id, to_account_id, card_id, merchant_id, amount,
+			ip, country, rules, status, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
+		RETURNING id`
+
+	rules, err := json.Marshal(review.Rules)
+	if err != nil {
+		return fmt.Errorf("failed to marshal fraud rules: %w", err)
+	}
+
+	now := time.Now()
+	review.Status = domain.FraudReviewStatusPending
+	review.CreatedAt = now
+	review.UpdatedAt = now
+
+	return conn(ctx, r.db).QueryRow(ctx, query,
+		review.UserID,
+		review.OperationType,
+		review.AccountID,
+		review.ToAccountID,
+		review.CardID,
+		review.MerchantID,
+		review.Amount,
+		review.IP,
+		review.Country,
+		rules,
+		review.Status,
+		review.CreatedAt,
+		review.UpdatedAt,
+	).Scan(&review.ID)
+}
+
+// GetReview получает проверку по ID
+func (r *FraudRepositoryImpl) GetReview(ctx context.Context, id int) (*domain.FraudReview, error) {
+	query := `SELECT ` + fraudReviewColumns + ` FROM fraud_reviews WHERE id = $1`
+
+	review, err := scanFraudReview(conn(ctx, r.db).QueryRow(ctx, query, id))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.ErrFraudReviewNotFound
+		}
+		return nil, err
+	}
+
+	return review, nil
+}
+
+// ListReviews возвращает проверки с указанным статусом, старые первыми
+func (r *FraudRepositoryImpl) ListReviews(ctx context.Context, status string, limit, offset int) ([]*domain.FraudReview, error) {
+	query := `
+		SELECT ` + fraudReviewColumns + `
+		FROM fraud_reviews
+		WHERE status = $1
+		ORDER BY created_at, id
+		LIMIT $2 OFFSET $3`
+
+	rows, err := conn(ctx, r.db).Query(ctx, query, status, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var reviews []*domain.FraudReview
+	for rows.Next() {
+		review, err := scanFraudReview(rows)
+		if err != nil {
+			return nil, err
+		}
+		reviews = append(reviews, review)
+	}
+
+	return reviews, rows.Err()
+}
+
+// ResolveReview фиксирует решение оператора по проверке в статусе pending
+func (r *FraudRepositoryImpl) ResolveReview(ctx context.Context, id int, status string, reviewerID int, comment string) (*domain.FraudReview, error) {
+	query := `
+		UPDATE fraud_reviews
+		SET status = $2, reviewer_id = $3, comment = $4, reviewed_at = $5, updated_at = $5
+		WHERE id = $1 AND status = 'pending'
+		RETURNING ` + fraudReviewColumns
+
+	review, err := scanFraudReview(conn(ctx, r.db).QueryRow(ctx, query, id, status, reviewerID, comment, time.Now()))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			// Проверка либо не существует, либо уже решена
+			if _, getErr := r.GetReview(ctx, id); getErr != nil {
+				return nil, getErr
+			}
+			return nil, domain.ErrFraudReviewResolved
+		}
+		return nil, err
+	}
+
+	return review, nil
+}
+
+// FindPendingReview ищет нерешенную проверку той же операции; nil, если ее нет
+func (r *FraudRepositoryImpl) FindPendingReview(ctx context.Context, op *domain.FraudOperation) (*domain.FraudReview, error) {
+	query := `
+		SELECT ` + fraudReviewColumns + `
+		FROM fraud_reviews
+		WHERE ` + fraudOperationMatch + ` AND status = 'pending'
+		ORDER BY id DESC
+		LIMIT 1`
+
+	review, err := scanFraudReview(conn(ctx, r.db).QueryRow(ctx, query, fraudOperationArgs(op)...))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return review, nil
+}
+
+// ConsumeApprovedReview погашает одобренную после since проверку той же операции; nil, если ее нет.
+// Одобрение разрешает операцию один раз: повторное погашение той же проверки невозможно.
+func (r *FraudRepositoryImpl) ConsumeApprovedReview(ctx context.Context, op *domain.FraudOperation, since time.Time) (*domain.FraudReview, error) {
+	query := `
+		UPDATE fraud_reviews
+		SET consumed_at = $9, updated_at = $9
+		WHERE id = (
+			SELECT id FROM fraud_reviews
+			WHERE ` + fraudOperationMatch + `
+			  AND status = 'approved' AND consumed_at IS NULL AND reviewed_at >= $8
+			ORDER BY id
+			LIMIT 1
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING ` + fraudReviewColumns
+
+	args := append(fraudOperationArgs(op), since, time.Now())
+	review, err := scanFraudReview(conn(ctx, r.db).QueryRow(ctx, query, args...))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return review, nil
+}
+
+// fraudOperationArgs параметры условия fraudOperationMatch
+func fraudOperationArgs(op *domain.FraudOperation) []interface{} {
+	return []interface{}{op.UserID, op.Type, op.AccountID, op.ToAccountID, op.CardID, op.MerchantID, op.Amount}
+}
+
+func scanFraudReview(row pgx.Row) (*domain.FraudReview, error) {
+	review := &domain.FraudReview{}
+	var rules []byte
+	err := row.Scan(
+		&review.ID,
+		&review.UserID,
+		&review.OperationType,
+		&review.AccountID,
+		&review.ToAccountID,
+		&review.CardID,
+		&review.MerchantID,
+		&review.Amount,
+		&review.IP,
+		&review.Country,
+		&rules,
+		&review.Status,
+		&review.ReviewerID,
+		&review.Comment,
+		&review.ReviewedAt,
+		&review.ConsumedAt,
+		&review.CreatedAt,
+		&review.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(rules, &review.Rules); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal fraud rules: %w", err)
+	}
+
+	return review, nil
+}
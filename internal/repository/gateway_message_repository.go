@@ -0,0 +1,135 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/vterdunov/learn-bank-app/internal/domain"
+	"github.com/vterdunov/learn-bank-app/internal/utils"
+)
+
+// GatewayMessageRepositoryImpl реализация GatewayMessageRepository
+type GatewayMessageRepositoryImpl struct {
+	db *pgxpool.Pool
+}
+
+// NewGatewayMessageRepository создает новый экземпляр GatewayMessageRepository
+func NewGatewayMessageRepository(db *pgxpool.Pool) GatewayMessageRepository {
+	return &GatewayMessageRepositoryImpl{db: db}
+}
+
+// Create регистрирует полученный запрос; повтор (mti, rrn, терминал) возвращает ErrGatewayMessageDuplicate
+func (r *GatewayMessageRepositoryImpl) Create(ctx context.Context, message *domain.GatewayMessage) error {
+	query := `
+		INSERT INTO card_gateway_messages (mti, stan, rrn, terminal_id, merchant_id, amount, card_id, hold_id,
+			response_code, auth_code, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+		RETURNING id`
+
+	now := time.Now()
+	message.CreatedAt = now
+	message.UpdatedAt = now
+
+	err := conn(ctx, r.db).QueryRow(ctx, query,
+		message.MTI,
+		message.STAN,
+		message.RRN,
+		message.TerminalID,
+		message.MerchantID,
+		message.Amount,
+		message.CardID,
+		message.HoldID,
+		message.ResponseCode,
+		message.AuthCode,
+		message.CreatedAt,
+		message.UpdatedAt,
+	).Scan(&message.ID)
+	if err != nil {
+		if utils.IsUniqueViolation(utils.ParseDBError(err)) {
+			return domain.ErrGatewayMessageDuplicate
+		}
+		return err
+	}
+
+	return nil
+}
+
+// Update сохраняет результат обработки запроса
+func (r *GatewayMessageRepositoryImpl) Update(ctx context.Context, message *domain.GatewayMessage) error {
+	query := `
+		UPDATE card_gateway_messages
+		SET card_id = $2, hold_id = $3, response_code = $4, auth_code = $5
+		WHERE id = $1`
+
+	tag, err := conn(ctx, r.db).Exec(ctx, query,
+		message.ID,
+		message.CardID,
+		message.HoldID,
+		message.ResponseCode,
+		message.AuthCode,
+	)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.ErrGatewayMessageNotFound
+	}
+
+	message.UpdatedAt = time.Now()
+	return nil
+}
+
+// GetByReference получает запрос по типу, RRN и терминалу
+func (r *GatewayMessageRepositoryImpl) GetByReference(ctx context.Context, mti, rrn, terminalID string) (*domain.GatewayMessage, error) {
+	query := `SELECT ` + gatewayMessageColumns + `
+		FROM card_gateway_messages
+		WHERE mti = $1 AND rrn = $2 AND terminal_id = $3`
+
+	return r.get(ctx, query, mti, rrn, terminalID)
+}
+
+// GetOriginal получает одобренную авторизацию или финансовую операцию, которую отменяет реверс
+func (r *GatewayMessageRepositoryImpl) GetOriginal(ctx context.Context, rrn, terminalID string) (*domain.GatewayMessage, error) {
+	query := `SELECT ` + gatewayMessageColumns + `
+		FROM card_gateway_messages
+		WHERE rrn = $1 AND terminal_id = $2 AND mti IN ('0100', '0200') AND response_code = '00'
+		ORDER BY id DESC
+		LIMIT 1`
+
+	return r.get(ctx, query, rrn, terminalID)
+}
+
+func (r *GatewayMessageRepositoryImpl) get(ctx context.Context, query string, args ...any) (*domain.GatewayMessage, error) {
+	message := &domain.GatewayMessage{}
+	err := conn(ctx, r.db).QueryRow(ctx, query, args...).Scan(
+		&message.ID,
+		&message.MTI,
+		&message.STAN,
+		&message.RRN,
+		&message.TerminalID,
+		&message.MerchantID,
+		&message.Amount,
+		&message.CardID,
+		&message.HoldID,
+		&message.ResponseCode,
+		&message.AuthCode,
+		&message.CreatedAt,
+		&message.UpdatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.ErrGatewayMessageNotFound
+		}
+		return nil, err
+	}
+
+	return message, nil
+}
+
+// gatewayMessageColumns список колонок сообщения шлюза
+const gatewayMessageColumns = `id, mti, stan, rrn, terminal_id, merchant_id, amount, card_id, hold_id, response_code,
+	auth_code, created_at, updated_at`
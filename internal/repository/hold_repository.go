@@ -0,0 +1,215 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/vterdunov/learn-bank-app/internal/domain"
+)
+
+// HoldRepositoryImpl реализация HoldRepository
+type HoldRepositoryImpl struct {
+	db *pgxpool.Pool
+}
+
+// NewHoldRepository создает новый экземпляр HoldRepository
+func NewHoldRepository(db *pgxpool.Pool) HoldRepository {
+	return &HoldRepositoryImpl{db: db}
+}
+
+// Create создает холд
+func (r *HoldRepositoryImpl) Create(ctx context.Context, hold *domain.CardHold) error {
+	query := `
+		INSERT INTO card_holds (card_id, account_id, transaction_id, merchant_id, description, amount,
+			captured_amount, refunded_amount, status, initiated_by, expires_at, captured_at, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)
+		RETURNING id`
+
+	now := time.Now()
+	hold.CreatedAt = now
+	hold.UpdatedAt = now
+
+	return conn(ctx, r.db).QueryRow(ctx, query,
+		hold.CardID,
+		hold.AccountID,
+		hold.TransactionID,
+		hold.MerchantID,
+		hold.Description,
+		hold.Amount,
+		hold.CapturedAmount,
+		hold.RefundedAmount,
+		hold.Status,
+		hold.InitiatedBy,
+		hold.ExpiresAt,
+		hold.CapturedAt,
+		hold.CreatedAt,
+		hold.UpdatedAt,
+	).Scan(&hold.ID)
+}
+
+// GetByID получает холд по ID
+func (r *HoldRepositoryImpl) GetByID(ctx context.Context, id int) (*domain.CardHold, error) {
+	query := `SELECT ` + cardHoldColumns + ` FROM card_holds WHERE id = $1`
+
+	return r.get(ctx, query, id)
+}
+
+// GetByIDForUpdate получает холд по ID с блокировкой строки до конца транзакции
+func (r *HoldRepositoryImpl) GetByIDForUpdate(ctx context.Context, id int) (*domain.CardHold, error) {
+	query := `SELECT ` + cardHoldColumns + ` FROM card_holds WHERE id = $1 FOR UPDATE`
+
+	return r.get(ctx, query, id)
+}
+
+// GetByAccountID получает холды счета (новые первыми)
+func (r *HoldRepositoryImpl) GetByAccountID(ctx context.Context, accountID int) ([]*domain.CardHold, error) {
+	query := `SELECT ` + cardHoldColumns + ` FROM card_holds WHERE account_id = $1 ORDER BY id DESC`
+
+	return r.list(ctx, query, accountID)
+}
+
+// ListExpired возвращает авторизованные холды, срок которых истек к now
+func (r *HoldRepositoryImpl) ListExpired(ctx context.Context, now time.Time) ([]*domain.CardHold, error) {
+	query := `
+		SELECT ` + cardHoldColumns + `
+		FROM card_holds
+		WHERE status = 'authorized' AND expires_at <= $1
+		ORDER BY expires_at, id`
+
+	return r.list(ctx, query, now)
+}
+
+// Update сохраняет статус и суммы холда
+func (r *HoldRepositoryImpl) Update(ctx context.Context, hold *domain.CardHold) error {
+	query := `
+		UPDATE card_holds
+		SET captured_amount = $2, refunded_amount = $3, status = $4, captured_at = $5
+		WHERE id = $1`
+
+	tag, err := conn(ctx, r.db).Exec(ctx, query,
+		hold.ID,
+		hold.CapturedAmount,
+		hold.RefundedAmount,
+		hold.Status,
+		hold.CapturedAt,
+	)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.ErrCardHoldNotFound
+	}
+
+	hold.UpdatedAt = time.Now()
+	return nil
+}
+
+// ReserveFunds блокирует сумму на счете, если ее хватает в доступном остатке (с учетом лимита овердрафта).
+// Возвращает domain.ErrHoldInsufficientFunds, если доступных средств недостаточно.
+func (r *HoldRepositoryImpl) ReserveFunds(ctx context.Context, accountID int, amount float64) error {
+	tag, err := conn(ctx, r.db).Exec(ctx, `
+		UPDATE accounts
+		SET held_amount = held_amount + $2, updated_at = $3
+		WHERE id = $1 AND balance + overdraft_limit - held_amount >= $2`,
+		accountID, amount, time.Now())
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.ErrHoldInsufficientFunds
+	}
+
+	return nil
+}
+
+// ReleaseFunds снимает блокировку суммы без списания
+func (r *HoldRepositoryImpl) ReleaseFunds(ctx context.Context, accountID int, amount float64) error {
+	return r.adjust(ctx, accountID, 0, amount)
+}
+
+// CaptureFunds снимает блокировку held и списывает captured (captured <= held) с учетного остатка
+func (r *HoldRepositoryImpl) CaptureFunds(ctx context.Context, accountID int, held, captured float64) error {
+	return r.adjust(ctx, accountID, -captured, held)
+}
+
+// RefundFunds зачисляет возврат на учетный остаток
+func (r *HoldRepositoryImpl) RefundFunds(ctx context.Context, accountID int, amount float64) error {
+	return r.adjust(ctx, accountID, amount, 0)
+}
+
+// adjust изменяет учетный остаток на balanceDelta и уменьшает заблокированную сумму на released
+func (r *HoldRepositoryImpl) adjust(ctx context.Context, accountID int, balanceDelta, released float64) error {
+	tag, err := conn(ctx, r.db).Exec(ctx, `
+		UPDATE accounts
+		SET balance = balance + $2, held_amount = held_amount - $3, updated_at = $4
+		WHERE id = $1`,
+		accountID, balanceDelta, released, time.Now())
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return errors.New("account not found")
+	}
+
+	return nil
+}
+
+func (r *HoldRepositoryImpl) get(ctx context.Context, query string, id int) (*domain.CardHold, error) {
+	hold := &domain.CardHold{}
+	err := scanCardHold(conn(ctx, r.db).QueryRow(ctx, query, id), hold)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.ErrCardHoldNotFound
+		}
+		return nil, err
+	}
+
+	return hold, nil
+}
+
+func (r *HoldRepositoryImpl) list(ctx context.Context, query string, args ...any) ([]*domain.CardHold, error) {
+	rows, err := conn(ctx, r.db).Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var holds []*domain.CardHold
+	for rows.Next() {
+		hold := &domain.CardHold{}
+		if err := scanCardHold(rows, hold); err != nil {
+			return nil, err
+		}
+		holds = append(holds, hold)
+	}
+
+	return holds, rows.Err()
+}
+
+// cardHoldColumns список колонок холда
+const cardHoldColumns = `id, card_id, account_id, transaction_id, merchant_id, description, amount, captured_amount,
+	refunded_amount, status, initiated_by, expires_at, captured_at, created_at, updated_at`
+
+func scanCardHold(row pgx.Row, hold *domain.CardHold) error {
+	return row.Scan(
+		&hold.ID,
+		&hold.CardID,
+		&hold.AccountID,
+		&hold.TransactionID,
+		&hold.MerchantID,
+		&hold.Description,
+		&hold.Amount,
+		&hold.CapturedAmount,
+		&hold.RefundedAmount,
+		&hold.Status,
+		&hold.InitiatedBy,
+		&hold.ExpiresAt,
+		&hold.CapturedAt,
+		&hold.CreatedAt,
+		&hold.UpdatedAt,
+	)
+}
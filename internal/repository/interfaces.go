@@ -5,6 +5,7 @@ import (
 	"time"
 
 	"github.com/vterdunov/learn-bank-app/internal/domain"
+	"github.com/vterdunov/learn-bank-app/internal/utils"
 )
 
 // UserRepository интерфейс для работы с пользователями
@@ -17,19 +18,38 @@ type UserRepository interface {
 	Delete(ctx context.Context, id int) error
 	EmailExists(ctx context.Context, email string) (bool, error)
 	UsernameExists(ctx context.Context, username string) (bool, error)
+	ListAfterID(ctx context.Context, afterID, limit int) ([]*domain.User, error)
 }
 
 // AccountRepository интерфейс для работы со счетами
 type AccountRepository interface {
 	Create(ctx context.Context, account *domain.Account) error
 	GetByID(ctx context.Context, id int) (*domain.Account, error)
+	// GetByIDForUpdate получает счет с блокировкой строки до конца транзакции
+	GetByIDForUpdate(ctx context.Context, id int) (*domain.Account, error)
 	GetByUserID(ctx context.Context, userID int) ([]*domain.Account, error)
 	GetByNumber(ctx context.Context, number string) (*domain.Account, error)
 	Update(ctx context.Context, account *domain.Account) error
+	UpdateStatus(ctx context.Context, id int, status string) error
 	UpdateBalance(ctx context.Context, id int, balance float64) error
+	// Debit списывает сумму в пределах доступного остатка; иначе возвращает domain.ErrAccountInsufficientFunds
+	Debit(ctx context.Context, id int, amount float64) error
+	// Credit атомарно увеличивает учетный остаток на сумму
+	Credit(ctx context.Context, id int, amount float64) error
 	Delete(ctx context.Context, id int) error
 	Transfer(ctx context.Context, fromID, toID int, amount float64) error
 	GetBalance(ctx context.Context, id int) (float64, error)
+	// UpdateOverdraftLimit возвращает domain.ErrOverdraftLimitBelowDebt, если лимит не покрывает использованный овердрафт
+	UpdateOverdraftLimit(ctx context.Context, id int, limit float64) error
+}
+
+// AccountHolderRepository интерфейс для работы с совладельцами счетов
+type AccountHolderRepository interface {
+	Create(ctx context.Context, holder *domain.AccountHolder) error
+	Get(ctx context.Context, accountID, userID int) (*domain.AccountHolder, error)
+	GetByAccountID(ctx context.Context, accountID int) ([]*domain.AccountHolder, error)
+	GetAccountsByUserID(ctx context.Context, userID int) ([]*domain.Account, error)
+	Delete(ctx context.Context, accountID, userID int) error
 }
 
 // CardRepository интерфейс для работы с картами
@@ -40,7 +60,26 @@ type CardRepository interface {
 	Update(ctx context.Context, card *domain.Card) error
 	Delete(ctx context.Context, id int) error
 	UpdateStatus(ctx context.Context, id int, status string) error
+	// Cancel аннулирует карту, если она еще не аннулирована; false — карта уже аннулирована
+	Cancel(ctx context.Context, id int) (bool, error)
 	GetActiveCardsByAccount(ctx context.Context, accountID int) ([]*domain.Card, error)
+	GetByPANHash(ctx context.Context, panHash string) (*domain.Card, error)
+	ListWithoutPANHash(ctx context.Context) ([]*domain.Card, error)
+	SetPANHash(ctx context.Context, id int, panHash string) error
+	RecordCVVFailure(ctx context.Context, id, maxAttempts int) (int, string, error)
+	ResetCVVFailures(ctx context.Context, id int) error
+	SetPIN(ctx context.Context, id int, pinHash, pinSalt string) error
+	RecordPINFailure(ctx context.Context, id, maxAttempts int) (int, string, error)
+	ResetPINFailures(ctx context.Context, id int) error
+	Unblock(ctx context.Context, id int) error
+}
+
+// CardRevealRepository интерфейс для работы с одноразовыми токенами показа реквизитов карты
+type CardRevealRepository interface {
+	Create(ctx context.Context, token *domain.CardRevealToken) error
+	// TakeAttempt атомарно проверяет лимит попыток показа и записывает попытку; false — лимит исчерпан
+	TakeAttempt(ctx context.Context, cardID, userID, limit int, since, now time.Time) (bool, error)
+	Consume(ctx context.Context, tokenHash string, cardID, userID int, now time.Time) error
 }
 
 // TransactionRepository интерфейс для работы с транзакциями
@@ -53,6 +92,10 @@ type TransactionRepository interface {
 	Delete(ctx context.Context, id int) error
 	GetTransactionsByDateRange(ctx context.Context, accountID int, startDate, endDate time.Time) ([]*domain.Transaction, error)
 	GetMonthlyStatistics(ctx context.Context, userID int, year int, month int) (*domain.MonthlyStatistics, error)
+	GetTransferStats(ctx context.Context, userID, toAccountID int, since time.Time) (*domain.TransferStats, error)
+	GetOutgoingStats(ctx context.Context, userID int, since time.Time) (*domain.OutgoingStats, error)
+	GetExternalOutgoingAmount(ctx context.Context, userID int, since time.Time) (float64, error)
+	GetSpendingByMCC(ctx context.Context, userID int, year int, month int) ([]*domain.MCCSpending, error)
 }
 
 // CreditRepository интерфейс для работы с кредитами
@@ -82,12 +125,211 @@ type PaymentScheduleRepository interface {
 	AddPenalty(ctx context.Context, id int, penaltyAmount float64) error
 }
 
+// AuditRepository интерфейс для работы с журналом аудита (только добавление)
+type AuditRepository interface {
+	Append(ctx context.Context, event *domain.AuditEvent) error
+	List(ctx context.Context, filter domain.AuditFilter) ([]*domain.AuditEvent, error)
+	ListAfterSeq(ctx context.Context, afterSeq int64, limit int) ([]*domain.AuditEvent, error)
+}
+
+// OutboxRepository интерфейс для работы с outbox сообщениями
+type OutboxRepository interface {
+	Enqueue(ctx context.Context, message *domain.OutboxMessage) error
+	ClaimDue(ctx context.Context, limit int, lease time.Duration) ([]*domain.OutboxMessage, error)
+	Update(ctx context.Context, message *domain.OutboxMessage) error
+	GetByID(ctx context.Context, id int64) (*domain.OutboxMessage, error)
+	ListByStatus(ctx context.Context, status string, limit, offset int) ([]*domain.OutboxMessage, error)
+	Requeue(ctx context.Context, id int64) error
+}
+
+// NotificationRepository интерфейс для работы с уведомлениями и настройками каналов
+type NotificationRepository interface {
+	Create(ctx context.Context, notification *domain.Notification) error
+	ListByUser(ctx context.Context, userID int, unreadOnly bool, limit, offset int) ([]*domain.Notification, error)
+	MarkRead(ctx context.Context, userID int, id int64) error
+	MarkAllRead(ctx context.Context, userID int) (int64, error)
+	GetPreferences(ctx context.Context, userID int) ([]*domain.NotificationPreference, error)
+	UpsertPreferences(ctx context.Context, preferences []*domain.NotificationPreference) error
+	GetEndpoint(ctx context.Context, userID int, channel string) (*domain.NotificationEndpoint, error)
+	ListEndpoints(ctx context.Context, userID int) ([]*domain.NotificationEndpoint, error)
+	SaveEndpoint(ctx context.Context, endpoint *domain.NotificationEndpoint) error
+	DeleteEndpoint(ctx context.Context, userID int, channel string) error
+}
+
+// JobRepository интерфейс для работы с реестром фоновых задач и историей запусков
+type JobRepository interface {
+	Register(ctx context.Context, name, schedule string, nextRunAt time.Time) error
+	Get(ctx context.Context, name string) (*domain.Job, error)
+	List(ctx context.Context) ([]*domain.Job, error)
+	SetPaused(ctx context.Context, name string, paused bool) error
+	RequestTrigger(ctx context.Context, name string) error
+	MarkStarted(ctx context.Context, name string, nextRunAt time.Time) error
+	CreateRun(ctx context.Context, run *domain.JobRun) error
+	FinishRun(ctx context.Context, run *domain.JobRun) error
+	ListRuns(ctx context.Context, name string, limit, offset int) ([]*domain.JobRun, error)
+	InterruptRunning(ctx context.Context) (int64, error)
+}
+
+// EndOfDayRepository интерфейс для закрытия операционного дня и начисления процентов
+type EndOfDayRepository interface {
+	CreateDay(ctx context.Context, day *domain.BusinessDay) (bool, error)
+	UpdateDayTotals(ctx context.Context, day *domain.BusinessDay) error
+	LastClosedDate(ctx context.Context) (*time.Time, error)
+	ListCreditsForAccrual(ctx context.Context, date time.Time) ([]*domain.Credit, error)
+	ListAccountsForAccrual(ctx context.Context) ([]*domain.Account, error)
+	AddAccrual(ctx context.Context, accrual *domain.InterestAccrual) (bool, error)
+	ListAccruedSavings(ctx context.Context) ([]*domain.AccruedSavings, error)
+	PostSavingsInterest(ctx context.Context, accountID int, amount float64, description string) error
+	ListOverdraftAccounts(ctx context.Context) ([]*domain.Account, error)
+	ListAccruedOverdraftInterest(ctx context.Context) ([]*domain.AccruedOverdraftInterest, error)
+	ChargeOverdraftInterest(ctx context.Context, accountID int, amount float64, description string) (float64, error)
+}
+
+// DepositRepository интерфейс для работы с вкладами
+type DepositRepository interface {
+	Open(ctx context.Context, deposit *domain.Deposit, description string) error
+	GetByID(ctx context.Context, id int) (*domain.Deposit, error)
+	GetByUserID(ctx context.Context, userID int) ([]*domain.Deposit, error)
+	ListForAccrual(ctx context.Context, date time.Time) ([]*domain.Deposit, error)
+	ListForCapitalization(ctx context.Context) ([]*domain.Deposit, error)
+	Capitalize(ctx context.Context, id int, amount float64) error
+	ListMatured(ctx context.Context, date time.Time) ([]*domain.Deposit, error)
+	Settle(ctx context.Context, deposit *domain.Deposit, description string) error
+}
+
+// StandingOrderRepository интерфейс для работы с постоянными поручениями
+type StandingOrderRepository interface {
+	Create(ctx context.Context, order *domain.StandingOrder) error
+	GetByID(ctx context.Context, id int) (*domain.StandingOrder, error)
+	GetByIDForUpdate(ctx context.Context, id int) (*domain.StandingOrder, error)
+	GetByUserID(ctx context.Context, userID int) ([]*domain.StandingOrder, error)
+	Update(ctx context.Context, order *domain.StandingOrder) error
+	ListDue(ctx context.Context, today, now time.Time) ([]*domain.StandingOrder, error)
+	AddExecution(ctx context.Context, execution *domain.StandingOrderExecution) error
+	GetExecutions(ctx context.Context, orderID, limit, offset int) ([]*domain.StandingOrderExecution, error)
+}
+
+// PaymentAliasRepository интерфейс для работы с псевдонимами для переводов
+type PaymentAliasRepository interface {
+	Create(ctx context.Context, alias *domain.PaymentAlias) error
+	GetByValue(ctx context.Context, aliasType, value string) (*domain.PaymentAlias, error)
+	GetByUserID(ctx context.Context, userID int) ([]*domain.PaymentAlias, error)
+	Delete(ctx context.Context, userID, id int) error
+}
+
+// HoldRepository интерфейс для работы с холдами по картам и заблокированными средствами счетов
+type HoldRepository interface {
+	Create(ctx context.Context, hold *domain.CardHold) error
+	GetByID(ctx context.Context, id int) (*domain.CardHold, error)
+	GetByIDForUpdate(ctx context.Context, id int) (*domain.CardHold, error)
+	GetByAccountID(ctx context.Context, accountID int) ([]*domain.CardHold, error)
+	ListExpired(ctx context.Context, now time.Time) ([]*domain.CardHold, error)
+	Update(ctx context.Context, hold *domain.CardHold) error
+	ReserveFunds(ctx context.Context, accountID int, amount float64) error
+	ReleaseFunds(ctx context.Context, accountID int, amount float64) error
+	CaptureFunds(ctx context.Context, accountID int, held, captured float64) error
+	RefundFunds(ctx context.Context, accountID int, amount float64) error
+}
+
+// GatewayMessageRepository интерфейс для работы с журналом сообщений шлюза карточных операций
+type GatewayMessageRepository interface {
+	Create(ctx context.Context, message *domain.GatewayMessage) error
+	Update(ctx context.Context, message *domain.GatewayMessage) error
+	GetByReference(ctx context.Context, mti, rrn, terminalID string) (*domain.GatewayMessage, error)
+	GetOriginal(ctx context.Context, rrn, terminalID string) (*domain.GatewayMessage, error)
+}
+
+// MerchantRepository интерфейс для работы с ТСП и ежедневными расчетами с ними
+type MerchantRepository interface {
+	Create(ctx context.Context, merchant *domain.Merchant) error
+	GetByID(ctx context.Context, id int) (*domain.Merchant, error)
+	GetByCode(ctx context.Context, code string) (*domain.Merchant, error)
+	GetByTerminal(ctx context.Context, terminalID string) (*domain.Merchant, error)
+	AddTerminal(ctx context.Context, merchantID int, terminalID string) error
+	List(ctx context.Context) ([]*domain.Merchant, error)
+	AdjustSettlementBalance(ctx context.Context, accountID int, delta float64) error
+	LastSettledDate(ctx context.Context) (*time.Time, error)
+	SettlementTotals(ctx context.Context, from, to time.Time) ([]*domain.MerchantSettlement, error)
+	SaveSettlement(ctx context.Context, settlement *domain.MerchantSettlement) (bool, error)
+	ListSettlements(ctx context.Context, merchantID int, from, to time.Time) ([]*domain.MerchantSettlement, error)
+}
+
+// CashbackRepository интерфейс для работы с правилами, начислениями и выплатами кэшбэка
+type CashbackRepository interface {
+	CreateRule(ctx context.Context, rule *domain.CashbackRule) error
+	ListRules(ctx context.Context) ([]*domain.CashbackRule, error)
+	FindRule(ctx context.Context, mcc, category string) (*domain.CashbackRule, error)
+	DeactivateRule(ctx context.Context, id int) error
+	AddEntry(ctx context.Context, entry *domain.CashbackEntry) (bool, error)
+	GetPaymentCashback(ctx context.Context, transactionID int) (*domain.PaymentCashback, error)
+	GetPeriodTotals(ctx context.Context, cardID int, period time.Time, ruleID *int) (*domain.CashbackPeriodTotals, error)
+	HasPayout(ctx context.Context, cardID int, period time.Time) (bool, error)
+	GetBalance(ctx context.Context, cardID int) (*domain.CashbackBalance, error)
+	ListEntries(ctx context.Context, cardID, limit int) ([]*domain.CashbackEntry, error)
+	ListPayouts(ctx context.Context, cardID int) ([]*domain.CashbackPayout, error)
+	ListUnpaid(ctx context.Context, before time.Time) ([]*domain.CashbackPayout, error)
+	CreatePayout(ctx context.Context, payout *domain.CashbackPayout, description string) (bool, error)
+}
+
 // Repositories структура содержащая все репозитории
 type Repositories struct {
 	User            UserRepository
 	Account         AccountRepository
+	AccountHolder   AccountHolderRepository
 	Card            CardRepository
 	Transaction     TransactionRepository
 	Credit          CreditRepository
 	PaymentSchedule PaymentScheduleRepository
+	Audit           AuditRepository
+	Outbox          OutboxRepository
+	Notification    NotificationRepository
+	Job             JobRepository
+	EndOfDay        EndOfDayRepository
+	Deposit         DepositRepository
+	StandingOrder   StandingOrderRepository
+	PaymentAlias    PaymentAliasRepository
+	Hold            HoldRepository
+	GatewayMessage  GatewayMessageRepository
+	Merchant        MerchantRepository
+	Cashback        CashbackRepository
+	CardReveal      CardRevealRepository
+}
+
+// FraudRepository интерфейс для работы с очередью проверки операций антифрода
+type FraudRepository interface {
+	CreateReview(ctx context.Context, review *domain.FraudReview) error
+	GetReview(ctx context.Context, id int) (*domain.FraudReview, error)
+	ListReviews(ctx context.Context, status string, limit, offset int) ([]*domain.FraudReview, error)
+	ResolveReview(ctx context.Context, id int, status string, reviewerID int, comment string) (*domain.FraudReview, error)
+	FindPendingReview(ctx context.Context, op *domain.FraudOperation) (*domain.FraudReview, error)
+	ConsumeApprovedReview(ctx context.Context, op *domain.FraudOperation, since time.Time) (*domain.FraudReview, error)
+}
+
+// SanctionsRepository интерфейс для работы с санкционными списками и совпадениями клиентов
+type SanctionsRepository interface {
+	ListLists(ctx context.Context) ([]*domain.SanctionsList, error)
+	ReplaceList(ctx context.Context, list *domain.SanctionsList, entries []*domain.SanctionsEntry) error
+	ListEntries(ctx context.Context) ([]*domain.SanctionsEntry, error)
+	CreateHit(ctx context.Context, hit *domain.SanctionsHit) (bool, error)
+	GetHit(ctx context.Context, id int) (*domain.SanctionsHit, error)
+	ListHits(ctx context.Context, status string, limit, offset int) ([]*domain.SanctionsHit, error)
+	GetUserHits(ctx context.Context, userID int) ([]*domain.SanctionsHit, error)
+	ResolveHit(ctx context.Context, id int, status string, reviewerID int, comment string) (*domain.SanctionsHit, error)
+}
+
+// KYCRepository интерфейс для работы с анкетами клиентов и документами KYC
+type KYCRepository interface {
+	GetProfile(ctx context.Context, userID int) (*domain.KYCProfile, error)
+	SaveProfile(ctx context.Context, profile *domain.KYCProfile) error
+	ListProfiles(ctx context.Context, status string, limit, offset int) ([]*domain.KYCProfile, error)
+	ReviewProfile(ctx context.Context, userID int, status, level string, reviewerID int, reason string) (*domain.KYCProfile, error)
+	CreateDocument(ctx context.Context, doc *domain.KYCDocument) error
+	GetDocument(ctx context.Context, id int) (*domain.KYCDocument, error)
+	ListDocuments(ctx context.Context, userID int) ([]*domain.KYCDocument, error)
+}
+
+// RateLimitRepository хранилище корзин ограничителя запросов, общее для нескольких экземпляров приложения
+type RateLimitRepository interface {
+	Take(ctx context.Context, key string, limit utils.RateLimit, now time.Time) (utils.RateLimitDecision, error)
+	DeleteExpired(ctx context.Context, now time.Time) (int64, error)
 }
@@ -0,0 +1,224 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/vterdunov/learn-bank-app/internal/domain"
+)
+
+// JobRepositoryImpl реализация JobRepository
+type JobRepositoryImpl struct {
+	db *pgxpool.Pool
+}
+
+// NewJobRepository создает новый экземпляр JobRepository
+func NewJobRepository(db *pgxpool.Pool) JobRepository {
+	return &JobRepositoryImpl{db: db}
+}
+
+// Register добавляет задачу в реестр. Новая задача запускается сразу;
+// при изменении расписания существующей задачи время следующего запуска пересчитывается.
+func (r *JobRepositoryImpl) Register(ctx context.Context, name, schedule string, nextRunAt time.Time) error {
+	query := `
+		INSERT INTO jobs (name, schedule, next_run_at)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (name) DO UPDATE
+		SET schedule = EXCLUDED.schedule,
+			next_run_at = CASE WHEN jobs.schedule <> EXCLUDED.schedule THEN $4 ELSE jobs.next_run_at END`
+
+	_, err := conn(ctx, r.db).Exec(ctx, query, name, schedule, time.Now(), nextRunAt)
+	return err
+}
+
+// Get получает состояние задачи
+func (r *JobRepositoryImpl) Get(ctx context.Context, name string) (*domain.Job, error) {
+	query := `SELECT ` + jobColumns + ` FROM jobs WHERE name = $1`
+
+	job := &domain.Job{}
+	err := scanJob(conn(ctx, r.db).QueryRow(ctx, query, name), job)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.ErrJobNotFound
+		}
+		return nil, err
+	}
+
+	return job, nil
+}
+
+// List возвращает все задачи реестра
+func (r *JobRepositoryImpl) List(ctx context.Context) ([]*domain.Job, error) {
+	query := `SELECT ` + jobColumns + ` FROM jobs ORDER BY name`
+
+	rows, err := conn(ctx, r.db).Query(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var jobs []*domain.Job
+	for rows.Next() {
+		job := &domain.Job{}
+		if err := scanJob(rows, job); err != nil {
+			return nil, err
+		}
+		jobs = append(jobs, job)
+	}
+
+	return jobs, rows.Err()
+}
+
+// SetPaused ставит задачу на паузу или снимает с нее
+func (r *JobRepositoryImpl) SetPaused(ctx context.Context, name string, paused bool) error {
+	query := `UPDATE jobs SET paused = $2 WHERE name = $1`
+
+	tag, err := conn(ctx, r.db).Exec(ctx, query, name, paused)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.ErrJobNotFound
+	}
+
+	return nil
+}
+
+// RequestTrigger запрашивает внеочередной запуск задачи лидером
+func (r *JobRepositoryImpl) RequestTrigger(ctx context.Context, name string) error {
+	query := `UPDATE jobs SET trigger_requested_at = COALESCE(trigger_requested_at, $2) WHERE name = $1`
+
+	tag, err := conn(ctx, r.db).Exec(ctx, query, name, time.Now())
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.ErrJobNotFound
+	}
+
+	return nil
+}
+
+// MarkStarted сбрасывает запрос ручного запуска и сохраняет время следующего запуска по расписанию
+func (r *JobRepositoryImpl) MarkStarted(ctx context.Context, name string, nextRunAt time.Time) error {
+	query := `UPDATE jobs SET next_run_at = $2, trigger_requested_at = NULL WHERE name = $1`
+
+	tag, err := conn(ctx, r.db).Exec(ctx, query, name, nextRunAt)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.ErrJobNotFound
+	}
+
+	return nil
+}
+
+// CreateRun добавляет запись о начале запуска
+func (r *JobRepositoryImpl) CreateRun(ctx context.Context, run *domain.JobRun) error {
+	query := `
+		INSERT INTO job_runs (job_name, trigger, instance_id, status, started_at)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id`
+
+	return conn(ctx, r.db).QueryRow(ctx, query,
+		run.JobName,
+		run.Trigger,
+		run.InstanceID,
+		run.Status,
+		run.StartedAt,
+	).Scan(&run.ID)
+}
+
+// FinishRun сохраняет результат запуска
+func (r *JobRepositoryImpl) FinishRun(ctx context.Context, run *domain.JobRun) error {
+	query := `
+		UPDATE job_runs
+		SET status = $2, finished_at = $3, processed = $4, failed = $5, error = $6
+		WHERE id = $1`
+
+	_, err := conn(ctx, r.db).Exec(ctx, query,
+		run.ID,
+		run.Status,
+		run.FinishedAt,
+		run.Processed,
+		run.Failed,
+		run.Error,
+	)
+
+	return err
+}
+
+// ListRuns возвращает историю запусков задачи (новые первыми)
+func (r *JobRepositoryImpl) ListRuns(ctx context.Context, name string, limit, offset int) ([]*domain.JobRun, error) {
+	query := `
+		SELECT id, job_name, trigger, instance_id, status, started_at, finished_at, processed, failed, error
+		FROM job_runs
+		WHERE job_name = $1
+		ORDER BY id DESC
+		LIMIT $2 OFFSET $3`
+
+	rows, err := conn(ctx, r.db).Query(ctx, query, name, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var runs []*domain.JobRun
+	for rows.Next() {
+		run := &domain.JobRun{}
+		err := rows.Scan(
+			&run.ID,
+			&run.JobName,
+			&run.Trigger,
+			&run.InstanceID,
+			&run.Status,
+			&run.StartedAt,
+			&run.FinishedAt,
+			&run.Processed,
+			&run.Failed,
+			&run.Error,
+		)
+		if err != nil {
+			return nil, err
+		}
+		runs = append(runs, run)
+	}
+
+	return runs, rows.Err()
+}
+
+// InterruptRunning помечает незавершенные запуски прерванными.
+// Вызывается новым лидером: запуски предыдущего лидера уже не выполняются.
+func (r *JobRepositoryImpl) InterruptRunning(ctx context.Context) (int64, error) {
+	query := `
+		UPDATE job_runs
+		SET status = 'interrupted', finished_at = $1, error = 'leader changed before run finished'
+		WHERE status = 'running'`
+
+	tag, err := conn(ctx, r.db).Exec(ctx, query, time.Now())
+	if err != nil {
+		return 0, err
+	}
+
+	return tag.RowsAffected(), nil
+}
+
+// jobColumns список колонок задачи
+const jobColumns = `name, schedule, paused, next_run_at, trigger_requested_at, created_at, updated_at`
+
+func scanJob(row pgx.Row, job *domain.Job) error {
+	return row.Scan(
+		&job.Name,
+		&job.Schedule,
+		&job.Paused,
+		&job.NextRunAt,
+		&job.TriggerRequestedAt,
+		&job.CreatedAt,
+		&job.UpdatedAt,
+	)
+}
@@ -0,0 +1,266 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/vterdunov/learn-bank-app/internal/domain"
+	"github.com/vterdunov/learn-bank-app/internal/utils"
+)
+
+// kycProfileColumns колонки анкеты в порядке scanKYCProfile
+const kycProfileColumns = `user_id, last_name, first_name, middle_name, birth_date, birth_place,
+	passport_series, passport_number, passport_issued_by, passport_issue_date, passport_division_code,
+	inn, snils, address, phone, status, level, rejection_reason, reviewer_id, submitted_at, reviewed_at,
+	created_at, updated_at`
+
+// kycDocumentColumns колонки документа в порядке scanKYCDocument
+const kycDocumentColumns = `id, user_id, type, file_name, content_type, size, sha256, storage_path, created_at`
+
+// KYCRepositoryImpl реализация KYCRepository
+type KYCRepositoryImpl struct {
+	db *pgxpool.Pool
+}
+
+// NewKYCRepository создает новый экземпляр KYCRepository
+func NewKYCRepository(db *pgxpool.Pool) KYCRepository {
+	return &KYCRepositoryImpl{db: db}
+}
+
+// GetProfile получает анкету клиента
+func (r *KYCRepositoryImpl) GetProfile(ctx context.Context, userID int) (*domain.KYCProfile, error) {
+	query := `SELECT ` + kycProfileColumns + ` FROM kyc_profiles WHERE user_id = $1`
+
+	profile, err := scanKYCProfile(conn(ctx, r.db).QueryRow(ctx, query, userID))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.ErrKYCProfileNotFound
+		}
+		return nil, err
+	}
+
+	return profile, nil
+}
+
+// SaveProfile создает или обновляет анкету клиента вместе со статусом.
+// Анкету на проверке у оператора изменить нельзя: возвращается domain.ErrKYCUnderReview.
+func (r *KYCRepositoryImpl) SaveProfile(ctx context.Context, profile *domain.KYCProfile) error {
+	query := `
+		INSERT INTO kyc_profiles (user_id, last_name, first_name, middle_name, birth_date, birth_place,
+			passport_series, passport_number, passport_issued_by, passport_issue_date, passport_division_code,
+			inn, snils, address, phone, status, level, rejection_reason, submitted_at, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20, $20)
+		ON CONFLICT (user_id) DO UPDATE
+		SET last_name = EXCLUDED.last_name, first_name = EXCLUDED.first_name, middle_name = EXCLUDED.middle_name,
+			birth_date = EXCLUDED.birth_date, birth_place = EXCLUDED.birth_place,
+			passport_series = EXCLUDED.passport_series, passport_number = EXCLUDED.passport_number,
+			passport_issued_by = EXCLUDED.passport_issued_by, passport_issue_date = EXCLUDED.passport_issue_date,
+			passport_division_code = EXCLUDED.passport_division_code,
+			inn = EXCLUDED.inn, snils = EXCLUDED.snils, address = EXCLUDED.address, phone = EXCLUDED.phone,
+			status = EXCLUDED.status, level = EXCLUDED.level, rejection_reason = EXCLUDED.rejection_reason,
+			submitted_at = EXCLUDED.submitted_at
+		WHERE kyc_profiles.status <> 'pending'
+		RETURNING created_at, updated_at`
+
+	err := conn(ctx, r.db).QueryRow(ctx, query,
+		profile.UserID,
+		profile.LastName,
+		profile.FirstName,
+		profile.MiddleName,
+		profile.BirthDate,
+		profile.BirthPlace,
+		profile.PassportSeries,
+		profile.PassportNumber,
+		profile.PassportIssuedBy,
+		profile.PassportIssueDate,
+		profile.PassportDivisionCode,
+		profile.INN,
+		profile.SNILS,
+		profile.Address,
+		profile.Phone,
+		profile.Status,
+		profile.Level,
+		profile.RejectionReason,
+		profile.SubmittedAt,
+		time.Now(),
+	).Scan(&profile.CreatedAt, &profile.UpdatedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return domain.ErrKYCUnderReview
+		}
+		if utils.IsUniqueViolation(utils.ParseDBError(err)) {
+			return domain.ErrKYCIdentityInUse
+		}
+		return err
+	}
+
+	return nil
+}
+
+// ListProfiles возвращает анкеты с указанным статусом, отправленные раньше первыми
+func (r *KYCRepositoryImpl) ListProfiles(ctx context.Context, status string, limit, offset int) ([]*domain.KYCProfile, error) {
+	query := `
+		SELECT ` + kycProfileColumns + `
+		FROM kyc_profiles
+		WHERE status = $1
+		ORDER BY submitted_at NULLS LAST, user_id
+		LIMIT $2 OFFSET $3`
+
+	rows, err := conn(ctx, r.db).Query(ctx, query, status, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var profiles []*domain.KYCProfile
+	for rows.Next() {
+		profile, err := scanKYCProfile(rows)
+		if err != nil {
+			return nil, err
+		}
+		profiles = append(profiles, profile)
+	}
+
+	return profiles, rows.Err()
+}
+
+// ReviewProfile фиксирует решение оператора по анкете в статусе pending
+func (r *KYCRepositoryImpl) ReviewProfile(ctx context.Context, userID int, status, level string, reviewerID int, reason string) (*domain.KYCProfile, error) {
+	query := `
+		UPDATE kyc_profiles
+		SET status = $2, level = $3, reviewer_id = $4, rejection_reason = $5, reviewed_at = $6
+		WHERE user_id = $1 AND status = 'pending'
+		RETURNING ` + kycProfileColumns
+
+	profile, err := scanKYCProfile(conn(ctx, r.db).QueryRow(ctx, query, userID, status, level, reviewerID, reason, time.Now()))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			// Анкета либо не существует, либо не ждет проверки
+			if _, getErr := r.GetProfile(ctx, userID); getErr != nil {
+				return nil, getErr
+			}
+			return nil, domain.ErrKYCNotPending
+		}
+		return nil, err
+	}
+
+	return profile, nil
+}
+
+// CreateDocument сохраняет сведения о загруженном документе
+func (r *KYCRepositoryImpl) CreateDocument(ctx context.Context, doc *domain.KYCDocument) error {
+	query := `
+		INSERT INTO kyc_documents (user_id, type, file_name, content_type, size, sha256, storage_path, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		RETURNING id`
+
+	doc.CreatedAt = time.Now()
+
+	return conn(ctx, r.db).QueryRow(ctx, query,
+		doc.UserID,
+		doc.Type,
+		doc.FileName,
+		doc.ContentType,
+		doc.Size,
+		doc.SHA256,
+		doc.StoragePath,
+		doc.CreatedAt,
+	).Scan(&doc.ID)
+}
+
+// GetDocument получает документ по ID
+func (r *KYCRepositoryImpl) GetDocument(ctx context.Context, id int) (*domain.KYCDocument, error) {
+	query := `SELECT ` + kycDocumentColumns + ` FROM kyc_documents WHERE id = $1`
+
+	doc, err := scanKYCDocument(conn(ctx, r.db).QueryRow(ctx, query, id))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.ErrKYCDocumentNotFound
+		}
+		return nil, err
+	}
+
+	return doc, nil
+}
+
+// ListDocuments возвращает документы клиента в порядке загрузки
+func (r *KYCRepositoryImpl) ListDocuments(ctx context.Context, userID int) ([]*domain.KYCDocument, error) {
+	query := `
+		SELECT ` + kycDocumentColumns + `
+		FROM kyc_documents
+		WHERE user_id = $1
+		ORDER BY id`
+
+	rows, err := conn(ctx, r.db).Query(ctx, query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var docs []*domain.KYCDocument
+	for rows.Next() {
+		doc, err := scanKYCDocument(rows)
+		if err != nil {
+			return nil, err
+		}
+		docs = append(docs, doc)
+	}
+
+	return docs, rows.Err()
+}
+
+func scanKYCProfile(row pgx.Row) (*domain.KYCProfile, error) {
+	profile := &domain.KYCProfile{}
+	err := row.Scan(
+		&profile.UserID,
+		&profile.LastName,
+		&profile.FirstName,
+		&profile.MiddleName,
+		&profile.BirthDate,
+		&profile.BirthPlace,
+		&profile.PassportSeries,
+		&profile.PassportNumber,
+		&profile.PassportIssuedBy,
+		&profile.PassportIssueDate,
+		&profile.PassportDivisionCode,
+		&profile.INN,
+		&profile.SNILS,
+		&profile.Address,
+		&profile.Phone,
+		&profile.Status,
+		&profile.Level,
+		&profile.RejectionReason,
+		&profile.ReviewerID,
+		&profile.SubmittedAt,
+		&profile.ReviewedAt,
+		&profile.CreatedAt,
+		&profile.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return profile, nil
+}
+
+func scanKYCDocument(row pgx.Row) (*domain.KYCDocument, error) {
+	doc := &domain.KYCDocument{}
+	err := row.Scan(
+		&doc.ID,
+		&doc.UserID,
+		&doc.Type,
+		&doc.FileName,
+		&doc.ContentType,
+		&doc.Size,
+		&doc.SHA256,
+		&doc.StoragePath,
+		&doc.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return doc, nil
+}
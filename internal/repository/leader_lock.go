@@ -0,0 +1,91 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// LeaderLock блокировка лидера среди реплик приложения
+type LeaderLock interface {
+	// TryAcquire проверяет, что блокировка удерживается, и пытается захватить ее, если нет
+	TryAcquire(ctx context.Context) (bool, error)
+	// Release освобождает блокировку
+	Release(ctx context.Context) error
+}
+
+// AdvisoryLock лидерство на основе session-level advisory lock PostgreSQL.
+// Блокировка живет, пока открыто выделенное соединение: при падении реплики
+// PostgreSQL снимает ее автоматически и лидером становится другая реплика.
+type AdvisoryLock struct {
+	db   *pgxpool.Pool
+	key  int64
+	mu   sync.Mutex
+	conn *pgxpool.Conn
+}
+
+// NewAdvisoryLock создает блокировку лидера с указанным ключом
+func NewAdvisoryLock(db *pgxpool.Pool, key int64) LeaderLock {
+	return &AdvisoryLock{db: db, key: key}
+}
+
+// TryAcquire проверяет соединение удерживаемой блокировки или пытается захватить ее
+func (l *AdvisoryLock) TryAcquire(ctx context.Context) (bool, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.conn != nil {
+		if _, err := l.conn.Exec(ctx, "SELECT 1"); err != nil {
+			// Соединение потеряно - вместе с ним потеряна и блокировка
+			l.drop(ctx)
+			return false, fmt.Errorf("leader connection lost: %w", err)
+		}
+		return true, nil
+	}
+
+	c, err := l.db.Acquire(ctx)
+	if err != nil {
+		return false, fmt.Errorf("failed to acquire connection: %w", err)
+	}
+
+	var acquired bool
+	if err := c.QueryRow(ctx, "SELECT pg_try_advisory_lock($1)", l.key).Scan(&acquired); err != nil {
+		c.Release()
+		return false, fmt.Errorf("failed to try advisory lock: %w", err)
+	}
+	if !acquired {
+		c.Release()
+		return false, nil
+	}
+
+	l.conn = c
+	return true, nil
+}
+
+// Release снимает блокировку и возвращает соединение в пул
+func (l *AdvisoryLock) Release(ctx context.Context) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.conn == nil {
+		return nil
+	}
+
+	if _, err := l.conn.Exec(ctx, "SELECT pg_advisory_unlock($1)", l.key); err != nil {
+		l.drop(ctx)
+		return fmt.Errorf("failed to release advisory lock: %w", err)
+	}
+
+	l.conn.Release()
+	l.conn = nil
+	return nil
+}
+
+// drop закрывает соединение, чтобы блокировка не осталась на соединении в пуле
+func (l *AdvisoryLock) drop(ctx context.Context) {
+	_ = l.conn.Conn().Close(ctx)
+	l.conn.Release()
+	l.conn = nil
+}
@@ -0,0 +1,279 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/vterdunov/learn-bank-app/internal/domain"
+	"github.com/vterdunov/learn-bank-app/internal/utils"
+)
+
+// MerchantRepositoryImpl реализация MerchantRepository
+type MerchantRepositoryImpl struct {
+	db *pgxpool.Pool
+}
+
+// NewMerchantRepository создает новый экземпляр MerchantRepository
+func NewMerchantRepository(db *pgxpool.Pool) MerchantRepository {
+	return &MerchantRepositoryImpl{db: db}
+}
+
+// Create регистрирует ТСП; занятый код возвращает ErrMerchantCodeTaken
+func (r *MerchantRepositoryImpl) Create(ctx context.Context, merchant *domain.Merchant) error {
+	query := `
+		INSERT INTO merchants (code, name, mcc, settlement_account_id, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id`
+
+	now := time.Now()
+	merchant.CreatedAt = now
+	merchant.UpdatedAt = now
+
+	err := conn(ctx, r.db).QueryRow(ctx, query,
+		merchant.Code,
+		merchant.Name,
+		merchant.MCC,
+		merchant.SettlementAccountID,
+		merchant.CreatedAt,
+		merchant.UpdatedAt,
+	).Scan(&merchant.ID)
+	if err != nil {
+		if utils.IsUniqueViolation(utils.ParseDBError(err)) {
+			return domain.ErrMerchantCodeTaken
+		}
+		return err
+	}
+
+	return nil
+}
+
+// GetByID получает ТСП по ID
+func (r *MerchantRepositoryImpl) GetByID(ctx context.Context, id int) (*domain.Merchant, error) {
+	query := `SELECT ` + merchantColumns + ` FROM merchants WHERE id = $1`
+
+	return r.get(ctx, query, id)
+}
+
+// GetByCode получает ТСП по коду из платежа
+func (r *MerchantRepositoryImpl) GetByCode(ctx context.Context, code string) (*domain.Merchant, error) {
+	query := `SELECT ` + merchantColumns + ` FROM merchants WHERE code = $1`
+
+	return r.get(ctx, query, code)
+}
+
+// GetByTerminal получает ТСП, за которым зарегистрирован терминал
+func (r *MerchantRepositoryImpl) GetByTerminal(ctx context.Context, terminalID string) (*domain.Merchant, error) {
+	query := `
+		SELECT m.id, m.code, m.name, m.mcc, m.settlement_account_id, m.created_at, m.updated_at
+		FROM merchant_terminals t
+		JOIN merchants m ON m.id = t.merchant_id
+		WHERE t.terminal_id = $1`
+
+	return r.get(ctx, query, terminalID)
+}
+
+// AddTerminal регистрирует терминал ТСП; занятый терминал возвращает ErrTerminalTaken
+func (r *MerchantRepositoryImpl) AddTerminal(ctx context.Context, merchantID int, terminalID string) error {
+	query := `INSERT INTO merchant_terminals (terminal_id, merchant_id) VALUES ($1, $2)`
+
+	if _, err := conn(ctx, r.db).Exec(ctx, query, terminalID, merchantID); err != nil {
+		if utils.IsUniqueViolation(utils.ParseDBError(err)) {
+			return domain.ErrTerminalTaken
+		}
+		return err
+	}
+
+	return nil
+}
+
+// List получает все ТСП
+func (r *MerchantRepositoryImpl) List(ctx context.Context) ([]*domain.Merchant, error) {
+	query := `SELECT ` + merchantColumns + ` FROM merchants ORDER BY id`
+
+	rows, err := conn(ctx, r.db).Query(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var merchants []*domain.Merchant
+	for rows.Next() {
+		merchant := &domain.Merchant{}
+		if err := scanMerchant(rows, merchant); err != nil {
+			return nil, err
+		}
+		merchants = append(merchants, merchant)
+	}
+
+	return merchants, rows.Err()
+}
+
+// AdjustSettlementBalance изменяет остаток расчетного счета ТСП на delta.
+// Если списание больше остатка, возвращает ErrMerchantInsufficientFunds.
+func (r *MerchantRepositoryImpl) AdjustSettlementBalance(ctx context.Context, accountID int, delta float64) error {
+	query := `
+		UPDATE accounts
+		SET balance = balance + $2, updated_at = $3
+		WHERE id = $1 AND balance - held_amount + $2 >= 0`
+
+	result, err := conn(ctx, r.db).Exec(ctx, query, accountID, delta, time.Now())
+	if err != nil {
+		return err
+	}
+
+	if result.RowsAffected() == 0 {
+		return domain.ErrMerchantInsufficientFunds
+	}
+
+	return nil
+}
+
+// LastSettledDate возвращает последний рассчитанный день или nil, если расчетов еще не было
+func (r *MerchantRepositoryImpl) LastSettledDate(ctx context.Context) (*time.Time, error) {
+	var date *time.Time
+	err := conn(ctx, r.db).QueryRow(ctx, `SELECT MAX(business_date) FROM merchant_settlements`).Scan(&date)
+	if err != nil {
+		return nil, err
+	}
+
+	return date, nil
+}
+
+// SettlementTotals считает итоги по каждому ТСП, зарегистрированному до конца периода [from, to):
+// списанные платежи, возвраты и комиссии по моменту их завершения
+func (r *MerchantRepositoryImpl) SettlementTotals(ctx context.Context, from, to time.Time) ([]*domain.MerchantSettlement, error) {
+	query := `
+		SELECT m.id,
+			COUNT(t.id) FILTER (WHERE t.type = 'payment'),
+			COALESCE(SUM(t.amount) FILTER (WHERE t.type = 'payment'), 0),
+			COALESCE(SUM(t.amount) FILTER (WHERE t.type = 'refund'), 0),
+			COALESCE(SUM(t.amount) FILTER (WHERE t.type = 'interchange_fee'), 0)
+		FROM merchants m
+		LEFT JOIN transactions t ON t.merchant_id = m.id
+			AND t.status = 'completed'
+			AND t.updated_at >= $1 AND t.updated_at < $2
+		WHERE m.created_at < $2
+		GROUP BY m.id
+		ORDER BY m.id`
+
+	rows, err := conn(ctx, r.db).Query(ctx, query, from, to)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var settlements []*domain.MerchantSettlement
+	for rows.Next() {
+		settlement := &domain.MerchantSettlement{}
+		err := rows.Scan(
+			&settlement.MerchantID,
+			&settlement.PaymentsCount,
+			&settlement.GrossAmount,
+			&settlement.RefundAmount,
+			&settlement.FeeAmount,
+		)
+		if err != nil {
+			return nil, err
+		}
+		settlements = append(settlements, settlement)
+	}
+
+	return settlements, rows.Err()
+}
+
+// SaveSettlement сохраняет итоги дня; возвращает false, если день по ТСП уже рассчитан
+func (r *MerchantRepositoryImpl) SaveSettlement(ctx context.Context, settlement *domain.MerchantSettlement) (bool, error) {
+	query := `
+		INSERT INTO merchant_settlements
+			(merchant_id, business_date, payments_count, gross_amount, refund_amount, fee_amount, net_amount)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		ON CONFLICT (merchant_id, business_date) DO NOTHING
+		RETURNING id, created_at`
+
+	err := conn(ctx, r.db).QueryRow(ctx, query,
+		settlement.MerchantID,
+		settlement.BusinessDate,
+		settlement.PaymentsCount,
+		settlement.GrossAmount,
+		settlement.RefundAmount,
+		settlement.FeeAmount,
+		settlement.NetAmount,
+	).Scan(&settlement.ID, &settlement.CreatedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return false, nil
+		}
+		return false, err
+	}
+
+	return true, nil
+}
+
+// ListSettlements получает итоги расчетов с ТСП за дни периода [from, to] (новые первыми)
+func (r *MerchantRepositoryImpl) ListSettlements(ctx context.Context, merchantID int, from, to time.Time) ([]*domain.MerchantSettlement, error) {
+	query := `
+		SELECT id, merchant_id, business_date, payments_count, gross_amount, refund_amount, fee_amount, net_amount, created_at
+		FROM merchant_settlements
+		WHERE merchant_id = $1 AND business_date >= $2 AND business_date <= $3
+		ORDER BY business_date DESC`
+
+	rows, err := conn(ctx, r.db).Query(ctx, query, merchantID, from, to)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var settlements []*domain.MerchantSettlement
+	for rows.Next() {
+		settlement := &domain.MerchantSettlement{}
+		err := rows.Scan(
+			&settlement.ID,
+			&settlement.MerchantID,
+			&settlement.BusinessDate,
+			&settlement.PaymentsCount,
+			&settlement.GrossAmount,
+			&settlement.RefundAmount,
+			&settlement.FeeAmount,
+			&settlement.NetAmount,
+			&settlement.CreatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		settlements = append(settlements, settlement)
+	}
+
+	return settlements, rows.Err()
+}
+
+func (r *MerchantRepositoryImpl) get(ctx context.Context, query string, arg any) (*domain.Merchant, error) {
+	merchant := &domain.Merchant{}
+	err := scanMerchant(conn(ctx, r.db).QueryRow(ctx, query, arg), merchant)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.ErrMerchantNotFound
+		}
+		return nil, err
+	}
+
+	return merchant, nil
+}
+
+// merchantColumns список колонок ТСП
+const merchantColumns = `id, code, name, mcc, settlement_account_id, created_at, updated_at`
+
+func scanMerchant(row pgx.Row, merchant *domain.Merchant) error {
+	return row.Scan(
+		&merchant.ID,
+		&merchant.Code,
+		&merchant.Name,
+		&merchant.MCC,
+		&merchant.SettlementAccountID,
+		&merchant.CreatedAt,
+		&merchant.UpdatedAt,
+	)
+}
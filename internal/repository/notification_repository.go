@@ -0,0 +1,265 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/vterdunov/learn-bank-app/internal/domain"
+	"github.com/vterdunov/learn-bank-app/internal/utils"
+)
+
+// NotificationRepositoryImpl реализация NotificationRepository
+type NotificationRepositoryImpl struct {
+	db *pgxpool.Pool
+}
+
+// NewNotificationRepository создает новый экземпляр NotificationRepository
+func NewNotificationRepository(db *pgxpool.Pool) NotificationRepository {
+	return &NotificationRepositoryImpl{db: db}
+}
+
+// Create добавляет уведомление во входящие пользователя
+func (r *NotificationRepositoryImpl) Create(ctx context.Context, notification *domain.Notification) error {
+	query := `
+		INSERT INTO notifications (user_id, event_type, title, message, created_at)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id`
+
+	notification.CreatedAt = time.Now()
+
+	err := conn(ctx, r.db).QueryRow(ctx, query,
+		notification.UserID,
+		notification.EventType,
+		notification.Title,
+		notification.Message,
+		notification.CreatedAt,
+	).Scan(&notification.ID)
+
+	if err != nil {
+		return utils.WrapDBError(err, "create notification")
+	}
+
+	return nil
+}
+
+// ListByUser возвращает уведомления пользователя (новые первыми)
+func (r *NotificationRepositoryImpl) ListByUser(ctx context.Context, userID int, unreadOnly bool, limit, offset int) ([]*domain.Notification, error) {
+	query := `
+		SELECT id, user_id, event_type, title, message, read_at, created_at
+		FROM notifications
+		WHERE user_id = $1 AND (NOT $2 OR read_at IS NULL)
+		ORDER BY id DESC
+		LIMIT $3 OFFSET $4`
+
+	rows, err := conn(ctx, r.db).Query(ctx, query, userID, unreadOnly, limit, offset)
+	if err != nil {
+		return nil, utils.WrapDBError(err, "list notifications")
+	}
+	defer rows.Close()
+
+	var notifications []*domain.Notification
+	for rows.Next() {
+		notification := &domain.Notification{}
+		err := rows.Scan(
+			&notification.ID,
+			&notification.UserID,
+			&notification.EventType,
+			&notification.Title,
+			&notification.Message,
+			&notification.ReadAt,
+			&notification.CreatedAt,
+		)
+		if err != nil {
+			return nil, utils.WrapDBError(err, "scan notification")
+		}
+		notifications = append(notifications, notification)
+	}
+
+	return notifications, rows.Err()
+}
+
+// MarkRead отмечает уведомление пользователя прочитанным
+func (r *NotificationRepositoryImpl) MarkRead(ctx context.Context, userID int, id int64) error {
+	query := `
+		UPDATE notifications
+		SET read_at = COALESCE(read_at, $3)
+		WHERE id = $1 AND user_id = $2`
+
+	tag, err := conn(ctx, r.db).Exec(ctx, query, id, userID, time.Now())
+	if err != nil {
+		return utils.WrapDBError(err, "mark notification read")
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.ErrNotificationNotFound
+	}
+
+	return nil
+}
+
+// MarkAllRead отмечает прочитанными все уведомления пользователя
+func (r *NotificationRepositoryImpl) MarkAllRead(ctx context.Context, userID int) (int64, error) {
+	query := `UPDATE notifications SET read_at = $2 WHERE user_id = $1 AND read_at IS NULL`
+
+	tag, err := conn(ctx, r.db).Exec(ctx, query, userID, time.Now())
+	if err != nil {
+		return 0, utils.WrapDBError(err, "mark all notifications read")
+	}
+
+	return tag.RowsAffected(), nil
+}
+
+// GetPreferences возвращает сохраненные настройки каналов пользователя
+func (r *NotificationRepositoryImpl) GetPreferences(ctx context.Context, userID int) ([]*domain.NotificationPreference, error) {
+	query := `
+		SELECT user_id, event_type, channel, enabled
+		FROM notification_preferences
+		WHERE user_id = $1`
+
+	rows, err := conn(ctx, r.db).Query(ctx, query, userID)
+	if err != nil {
+		return nil, utils.WrapDBError(err, "get notification preferences")
+	}
+	defer rows.Close()
+
+	var preferences []*domain.NotificationPreference
+	for rows.Next() {
+		preference := &domain.NotificationPreference{}
+		if err := rows.Scan(&preference.UserID, &preference.EventType, &preference.Channel, &preference.Enabled); err != nil {
+			return nil, utils.WrapDBError(err, "scan notification preference")
+		}
+		preferences = append(preferences, preference)
+	}
+
+	return preferences, rows.Err()
+}
+
+// UpsertPreferences сохраняет настройки каналов пользователя
+func (r *NotificationRepositoryImpl) UpsertPreferences(ctx context.Context, preferences []*domain.NotificationPreference) error {
+	query := `
+		INSERT INTO notification_preferences (user_id, event_type, channel, enabled, updated_at)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (user_id, event_type, channel)
+		DO UPDATE SET enabled = EXCLUDED.enabled, updated_at = EXCLUDED.updated_at`
+
+	batch := &pgx.Batch{}
+	now := time.Now()
+	for _, p := range preferences {
+		batch.Queue(query, p.UserID, p.EventType, p.Channel, p.Enabled, now)
+	}
+
+	tx, err := conn(ctx, r.db).Begin(ctx)
+	if err != nil {
+		return utils.WrapDBError(err, "begin preferences update")
+	}
+	defer tx.Rollback(ctx)
+
+	if err := tx.SendBatch(ctx, batch).Close(); err != nil {
+		return utils.WrapDBError(err, "upsert notification preferences")
+	}
+
+	return tx.Commit(ctx)
+}
+
+// GetEndpoint возвращает адрес доставки канала пользователя
+func (r *NotificationRepositoryImpl) GetEndpoint(ctx context.Context, userID int, channel string) (*domain.NotificationEndpoint, error) {
+	query := `
+		SELECT user_id, channel, address, secret, created_at, updated_at
+		FROM notification_endpoints
+		WHERE user_id = $1 AND channel = $2`
+
+	endpoint := &domain.NotificationEndpoint{}
+	err := conn(ctx, r.db).QueryRow(ctx, query, userID, channel).Scan(
+		&endpoint.UserID,
+		&endpoint.Channel,
+		&endpoint.Address,
+		&endpoint.Secret,
+		&endpoint.CreatedAt,
+		&endpoint.UpdatedAt,
+	)
+
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.ErrNotificationEndpointAbsent
+		}
+		return nil, utils.WrapDBError(err, "get notification endpoint")
+	}
+
+	return endpoint, nil
+}
+
+// ListEndpoints возвращает все адреса доставки пользователя
+func (r *NotificationRepositoryImpl) ListEndpoints(ctx context.Context, userID int) ([]*domain.NotificationEndpoint, error) {
+	query := `
+		SELECT user_id, channel, address, secret, created_at, updated_at
+		FROM notification_endpoints
+		WHERE user_id = $1
+		ORDER BY channel`
+
+	rows, err := conn(ctx, r.db).Query(ctx, query, userID)
+	if err != nil {
+		return nil, utils.WrapDBError(err, "list notification endpoints")
+	}
+	defer rows.Close()
+
+	var endpoints []*domain.NotificationEndpoint
+	for rows.Next() {
+		endpoint := &domain.NotificationEndpoint{}
+		err := rows.Scan(
+			&endpoint.UserID,
+			&endpoint.Channel,
+			&endpoint.Address,
+			&endpoint.Secret,
+			&endpoint.CreatedAt,
+			&endpoint.UpdatedAt,
+		)
+		if err != nil {
+			return nil, utils.WrapDBError(err, "scan notification endpoint")
+		}
+		endpoints = append(endpoints, endpoint)
+	}
+
+	return endpoints, rows.Err()
+}
+
+// SaveEndpoint создает или заменяет адрес доставки канала
+func (r *NotificationRepositoryImpl) SaveEndpoint(ctx context.Context, endpoint *domain.NotificationEndpoint) error {
+	query := `
+		INSERT INTO notification_endpoints (user_id, channel, address, secret, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $5)
+		ON CONFLICT (user_id, channel)
+		DO UPDATE SET address = EXCLUDED.address, secret = EXCLUDED.secret
+		RETURNING created_at, updated_at`
+
+	err := conn(ctx, r.db).QueryRow(ctx, query,
+		endpoint.UserID,
+		endpoint.Channel,
+		endpoint.Address,
+		endpoint.Secret,
+		time.Now(),
+	).Scan(&endpoint.CreatedAt, &endpoint.UpdatedAt)
+
+	if err != nil {
+		return utils.WrapDBError(err, "save notification endpoint")
+	}
+
+	return nil
+}
+
+// DeleteEndpoint удаляет адрес доставки канала
+func (r *NotificationRepositoryImpl) DeleteEndpoint(ctx context.Context, userID int, channel string) error {
+	query := `DELETE FROM notification_endpoints WHERE user_id = $1 AND channel = $2`
+
+	tag, err := conn(ctx, r.db).Exec(ctx, query, userID, channel)
+	if err != nil {
+		return utils.WrapDBError(err, "delete notification endpoint")
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.ErrNotificationEndpointAbsent
+	}
+
+	return nil
+}
@@ -0,0 +1,199 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/vterdunov/learn-bank-app/internal/domain"
+)
+
+// OutboxRepositoryImpl реализация OutboxRepository
+type OutboxRepositoryImpl struct {
+	db *pgxpool.Pool
+}
+
+// NewOutboxRepository создает новый экземпляр OutboxRepository
+func NewOutboxRepository(db *pgxpool.Pool) OutboxRepository {
+	return &OutboxRepositoryImpl{db: db}
+}
+
+// Enqueue добавляет сообщение в outbox (в транзакции из контекста, если она есть)
+func (r *OutboxRepositoryImpl) Enqueue(ctx context.Context, message *domain.OutboxMessage) error {
+	query := `
+		INSERT INTO outbox_messages (user_id, channel, event_type, recipient, subject, body, text_body, template_version,
+			status, attempts, max_attempts, next_attempt_at, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)
+		RETURNING id`
+
+	now := time.Now()
+	message.Status = domain.OutboxStatusPending
+	message.CreatedAt = now
+	message.UpdatedAt = now
+	if message.NextAttemptAt.IsZero() {
+		message.NextAttemptAt = now
+	}
+
+	return conn(ctx, r.db).QueryRow(ctx, query,
+		message.UserID,
+		message.Channel,
+		message.EventType,
+		message.Recipient,
+		message.Subject,
+		message.Body,
+		message.TextBody,
+		message.TemplateVersion,
+		message.Status,
+		message.Attempts,
+		message.MaxAttempts,
+		message.NextAttemptAt,
+		message.CreatedAt,
+		message.UpdatedAt,
+	).Scan(&message.ID)
+}
+
+// ClaimDue забирает сообщения, готовые к отправке, и арендует их на время lease.
+// Сообщения с истекшей арендой (диспетчер упал во время отправки) забираются повторно.
+func (r *OutboxRepositoryImpl) ClaimDue(ctx context.Context, limit int, lease time.Duration) ([]*domain.OutboxMessage, error) {
+	query := `
+		UPDATE outbox_messages
+		SET status = 'processing', locked_until = $2, updated_at = $3
+		WHERE id IN (
+			SELECT id FROM outbox_messages
+			WHERE (status = 'pending' AND next_attempt_at <= $3)
+				OR (status = 'processing' AND locked_until < $3)
+			ORDER BY next_attempt_at
+			LIMIT $1
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING ` + outboxColumns
+
+	now := time.Now()
+	rows, err := conn(ctx, r.db).Query(ctx, query, limit, now.Add(lease), now)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanOutboxMessages(rows)
+}
+
+// Update сохраняет результат попытки доставки
+func (r *OutboxRepositoryImpl) Update(ctx context.Context, message *domain.OutboxMessage) error {
+	query := `
+		UPDATE outbox_messages
+		SET status = $2, attempts = $3, next_attempt_at = $4, locked_until = $5, last_error = $6, sent_at = $7, updated_at = $8
+		WHERE id = $1`
+
+	message.UpdatedAt = time.Now()
+
+	_, err := conn(ctx, r.db).Exec(ctx, query,
+		message.ID,
+		message.Status,
+		message.Attempts,
+		message.NextAttemptAt,
+		message.LockedUntil,
+		message.LastError,
+		message.SentAt,
+		message.UpdatedAt,
+	)
+
+	return err
+}
+
+// GetByID получает сообщение по ID
+func (r *OutboxRepositoryImpl) GetByID(ctx context.Context, id int64) (*domain.OutboxMessage, error) {
+	query := `SELECT ` + outboxColumns + ` FROM outbox_messages WHERE id = $1`
+
+	rows, err := conn(ctx, r.db).Query(ctx, query, id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	messages, err := scanOutboxMessages(rows)
+	if err != nil {
+		return nil, err
+	}
+	if len(messages) == 0 {
+		return nil, errors.New("outbox message not found")
+	}
+
+	return messages[0], nil
+}
+
+// ListByStatus возвращает сообщения с указанным статусом (новые первыми)
+func (r *OutboxRepositoryImpl) ListByStatus(ctx context.Context, status string, limit, offset int) ([]*domain.OutboxMessage, error) {
+	query := `
+		SELECT ` + outboxColumns + `
+		FROM outbox_messages
+		WHERE status = $1
+		ORDER BY id DESC
+		LIMIT $2 OFFSET $3`
+
+	rows, err := conn(ctx, r.db).Query(ctx, query, status, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanOutboxMessages(rows)
+}
+
+// Requeue возвращает dead сообщение в очередь с обнулением попыток
+func (r *OutboxRepositoryImpl) Requeue(ctx context.Context, id int64) error {
+	query := `
+		UPDATE outbox_messages
+		SET status = 'pending', attempts = 0, next_attempt_at = $2, locked_until = NULL, updated_at = $2
+		WHERE id = $1 AND status = 'dead'`
+
+	tag, err := conn(ctx, r.db).Exec(ctx, query, id, time.Now())
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.ErrOutboxNotDead
+	}
+
+	return nil
+}
+
+// outboxColumns список колонок сообщения outbox
+const outboxColumns = `id, user_id, channel, event_type, recipient, subject, body, text_body, template_version,
+		status, attempts, max_attempts, next_attempt_at, locked_until, last_error, sent_at, created_at, updated_at`
+
+func scanOutboxMessages(rows pgx.Rows) ([]*domain.OutboxMessage, error) {
+	var messages []*domain.OutboxMessage
+	for rows.Next() {
+		message := &domain.OutboxMessage{}
+		err := rows.Scan(
+			&message.ID,
+			&message.UserID,
+			&message.Channel,
+			&message.EventType,
+			&message.Recipient,
+			&message.Subject,
+			&message.Body,
+			&message.TextBody,
+			&message.TemplateVersion,
+			&message.Status,
+			&message.Attempts,
+			&message.MaxAttempts,
+			&message.NextAttemptAt,
+			&message.LockedUntil,
+			&message.LastError,
+			&message.SentAt,
+			&message.CreatedAt,
+			&message.UpdatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		messages = append(messages, message)
+	}
+
+	return messages, rows.Err()
+}
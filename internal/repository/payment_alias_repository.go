@@ -0,0 +1,124 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/vterdunov/learn-bank-app/internal/domain"
+	"github.com/vterdunov/learn-bank-app/internal/utils"
+)
+
+// PaymentAliasRepositoryImpl реализация PaymentAliasRepository
+type PaymentAliasRepositoryImpl struct {
+	db *pgxpool.Pool
+}
+
+// NewPaymentAliasRepository создает новый экземпляр PaymentAliasRepository
+func NewPaymentAliasRepository(db *pgxpool.Pool) PaymentAliasRepository {
+	return &PaymentAliasRepositoryImpl{db: db}
+}
+
+// Create регистрирует псевдоним; занятый телефон или email возвращает ErrPaymentAliasTaken
+func (r *PaymentAliasRepositoryImpl) Create(ctx context.Context, alias *domain.PaymentAlias) error {
+	query := `
+		INSERT INTO payment_aliases (user_id, alias_type, value, account_id, created_at)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id`
+
+	alias.CreatedAt = time.Now()
+
+	err := conn(ctx, r.db).QueryRow(ctx, query,
+		alias.UserID,
+		alias.Type,
+		alias.Value,
+		alias.AccountID,
+		alias.CreatedAt,
+	).Scan(&alias.ID)
+	if err != nil {
+		if utils.IsUniqueViolation(utils.ParseDBError(err)) {
+			return domain.ErrPaymentAliasTaken
+		}
+		return err
+	}
+
+	return nil
+}
+
+// GetByValue получает псевдоним по типу и значению
+func (r *PaymentAliasRepositoryImpl) GetByValue(ctx context.Context, aliasType, value string) (*domain.PaymentAlias, error) {
+	query := `
+		SELECT id, user_id, alias_type, value, account_id, created_at
+		FROM payment_aliases
+		WHERE alias_type = $1 AND value = $2`
+
+	alias := &domain.PaymentAlias{}
+	err := conn(ctx, r.db).QueryRow(ctx, query, aliasType, value).Scan(
+		&alias.ID,
+		&alias.UserID,
+		&alias.Type,
+		&alias.Value,
+		&alias.AccountID,
+		&alias.CreatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.ErrPaymentAliasNotFound
+		}
+		return nil, err
+	}
+
+	return alias, nil
+}
+
+// GetByUserID получает псевдонимы пользователя
+func (r *PaymentAliasRepositoryImpl) GetByUserID(ctx context.Context, userID int) ([]*domain.PaymentAlias, error) {
+	query := `
+		SELECT id, user_id, alias_type, value, account_id, created_at
+		FROM payment_aliases
+		WHERE user_id = $1
+		ORDER BY id`
+
+	rows, err := conn(ctx, r.db).Query(ctx, query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var aliases []*domain.PaymentAlias
+	for rows.Next() {
+		alias := &domain.PaymentAlias{}
+		err := rows.Scan(
+			&alias.ID,
+			&alias.UserID,
+			&alias.Type,
+			&alias.Value,
+			&alias.AccountID,
+			&alias.CreatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		aliases = append(aliases, alias)
+	}
+
+	return aliases, rows.Err()
+}
+
+// Delete удаляет псевдоним пользователя
+func (r *PaymentAliasRepositoryImpl) Delete(ctx context.Context, userID, id int) error {
+	query := `DELETE FROM payment_aliases WHERE id = $1 AND user_id = $2`
+
+	tag, err := conn(ctx, r.db).Exec(ctx, query, id, userID)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.ErrPaymentAliasNotFound
+	}
+
+	return nil
+}
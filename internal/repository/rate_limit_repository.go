@@ -0,0 +1,65 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/vterdunov/learn-bank-app/internal/utils"
+)
+
+// RateLimitRepositoryImpl реализация RateLimitRepository
+type RateLimitRepositoryImpl struct {
+	db *pgxpool.Pool
+}
+
+// NewRateLimitRepository создает новый экземпляр RateLimitRepository
+func NewRateLimitRepository(db *pgxpool.Pool) RateLimitRepository {
+	return &RateLimitRepositoryImpl{db: db}
+}
+
+// Take берет токен из корзины key. Строка корзины блокируется до конца транзакции,
+// поэтому параллельные запросы разных экземпляров списывают токены последовательно.
+func (r *RateLimitRepositoryImpl) Take(ctx context.Context, key string, limit utils.RateLimit, now time.Time) (utils.RateLimitDecision, error) {
+	tx, err := conn(ctx, r.db).Begin(ctx)
+	if err != nil {
+		return utils.RateLimitDecision{}, err
+	}
+	defer tx.Rollback(ctx)
+
+	// Новая корзина создается полной; для существующей пустой UPDATE нужен, чтобы RETURNING вернул и заблокировал строку
+	bucket := utils.NewTokenBucket(limit, now)
+	err = tx.QueryRow(ctx, `
+		INSERT INTO rate_limit_buckets (key, tokens, updated_at, expires_at)
+		VALUES ($1, $2, $3, $3)
+		ON CONFLICT (key) DO UPDATE SET key = EXCLUDED.key
+		RETURNING tokens, updated_at`,
+		key, bucket.Tokens, bucket.UpdatedAt,
+	).Scan(&bucket.Tokens, &bucket.UpdatedAt)
+	if err != nil {
+		return utils.RateLimitDecision{}, err
+	}
+
+	decision := bucket.Take(limit, now)
+
+	_, err = tx.Exec(ctx, `
+		UPDATE rate_limit_buckets
+		SET tokens = $2, updated_at = $3, expires_at = $4
+		WHERE key = $1`,
+		key, bucket.Tokens, bucket.UpdatedAt, bucket.FullAt(limit))
+	if err != nil {
+		return utils.RateLimitDecision{}, err
+	}
+
+	return decision, tx.Commit(ctx)
+}
+
+// DeleteExpired удаляет корзины, полностью восполнившиеся к now
+func (r *RateLimitRepositoryImpl) DeleteExpired(ctx context.Context, now time.Time) (int64, error) {
+	result, err := conn(ctx, r.db).Exec(ctx, `DELETE FROM rate_limit_buckets WHERE expires_at <= $1`, now)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
@@ -0,0 +1,160 @@
+package repository
+
+import (
+	"context"
+	"os"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/vterdunov/learn-bank-app/internal/database"
+	"github.com/vterdunov/learn-bank-app/internal/utils"
+)
+
+// testDatabaseURLEnv переменная окружения с DSN тестовой базы; без нее тесты с PostgreSQL пропускаются
+const testDatabaseURLEnv = "TEST_DATABASE_URL"
+
+// newTestPool подключается к тестовой базе и применяет миграции
+func newTestPool(t *testing.T) *pgxpool.Pool {
+	t.Helper()
+
+	dsn := os.Getenv(testDatabaseURLEnv)
+	if dsn == "" {
+		t.Skipf("%s is not set", testDatabaseURLEnv)
+	}
+
+	ctx := context.Background()
+	pool, err := pgxpool.New(ctx, dsn)
+	if err != nil {
+		t.Fatalf("failed to connect to test database: %v", err)
+	}
+	t.Cleanup(pool.Close)
+
+	if err := (&database.DB{Pool: pool}).ApplyMigrations(ctx); err != nil {
+		t.Fatalf("failed to apply migrations: %v", err)
+	}
+	return pool
+}
+
+// testRateLimitKey возвращает уникальный ключ корзины и удаляет ее после теста
+func testRateLimitKey(t *testing.T, pool *pgxpool.Pool) string {
+	t.Helper()
+
+	key := "test|" + t.Name() + "|" + strconv.FormatInt(time.Now().UnixNano(), 10)
+	t.Cleanup(func() {
+		_, _ = pool.Exec(context.Background(), `DELETE FROM rate_limit_buckets WHERE key = $1`, key)
+	})
+	return key
+}
+
+func TestRateLimitRepository_Take(t *testing.T) {
+	pool := newTestPool(t)
+	repo := NewRateLimitRepository(pool)
+	key := testRateLimitKey(t, pool)
+
+	ctx := context.Background()
+	limit := utils.RateLimit{Requests: 2, Period: time.Minute}
+	// Время в прошлом, чтобы корзины теста не пересекались с реальными
+	now := time.Date(2000, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name          string
+		advance       time.Duration
+		wantAllowed   bool
+		wantRemaining int
+		wantRetry     time.Duration
+	}{
+		{name: "new bucket is full", wantAllowed: true, wantRemaining: 1},
+		{name: "last token", wantAllowed: true, wantRemaining: 0},
+		{name: "bucket empty", wantAllowed: false, wantRemaining: 0, wantRetry: 30 * time.Second},
+		{name: "token refilled", advance: 30 * time.Second, wantAllowed: true, wantRemaining: 0},
+	}
+
+	for _, tt := range tests {
+		now = now.Add(tt.advance)
+		decision, err := repo.Take(ctx, key, limit, now)
+		if err != nil {
+			t.Fatalf("%s: Take failed: %v", tt.name, err)
+		}
+		if decision.Allowed != tt.wantAllowed || decision.Remaining != tt.wantRemaining || decision.RetryAfter != tt.wantRetry {
+			t.Errorf("%s: expected allowed=%v remaining=%d retry=%v, got %+v",
+				tt.name, tt.wantAllowed, tt.wantRemaining, tt.wantRetry, decision)
+		}
+	}
+}
+
+func TestRateLimitRepository_TakeConcurrent(t *testing.T) {
+	pool := newTestPool(t)
+	repo := NewRateLimitRepository(pool)
+	key := testRateLimitKey(t, pool)
+
+	limit := utils.RateLimit{Requests: 5, Period: time.Hour}
+	now := time.Date(2000, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	var (
+		wg      sync.WaitGroup
+		mu      sync.Mutex
+		allowed int
+	)
+	for range 20 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			decision, err := repo.Take(context.Background(), key, limit, now)
+			if err != nil {
+				t.Errorf("Take failed: %v", err)
+				return
+			}
+			if decision.Allowed {
+				mu.Lock()
+				allowed++
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	if allowed != limit.Requests {
+		t.Errorf("expected %d requests allowed, got %d", limit.Requests, allowed)
+	}
+}
+
+func TestRateLimitRepository_DeleteExpired(t *testing.T) {
+	pool := newTestPool(t)
+	repo := NewRateLimitRepository(pool)
+	key := testRateLimitKey(t, pool)
+
+	ctx := context.Background()
+	limit := utils.RateLimit{Requests: 2, Period: time.Minute}
+	now := time.Date(2000, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	if _, err := repo.Take(ctx, key, limit, now); err != nil {
+		t.Fatalf("Take failed: %v", err)
+	}
+
+	exists := func() bool {
+		var n int
+		if err := pool.QueryRow(ctx, `SELECT COUNT(*) FROM rate_limit_buckets WHERE key = $1`, key).Scan(&n); err != nil {
+			t.Fatalf("failed to query bucket: %v", err)
+		}
+		return n == 1
+	}
+
+	// Корзина восполнится через 30 секунд: раньше удалять ее нельзя
+	if _, err := repo.DeleteExpired(ctx, now.Add(29*time.Second)); err != nil {
+		t.Fatalf("DeleteExpired failed: %v", err)
+	}
+	if !exists() {
+		t.Fatal("expected bucket kept before refill")
+	}
+
+	if _, err := repo.DeleteExpired(ctx, now.Add(30*time.Second)); err != nil {
+		t.Fatalf("DeleteExpired failed: %v", err)
+	}
+	if exists() {
+		t.Error("expected refilled bucket deleted")
+	}
+}
@@ -0,0 +1,286 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/vterdunov/learn-bank-app/internal/domain"
+)
+
+// sanctionsHitColumns колонки совпадения в порядке scanSanctionsHit
+const sanctionsHitColumns = `id, user_id, list_name, entry_external_id, entry_name, matched_name, score, source,
+	status, reviewer_id, comment, reviewed_at, created_at, updated_at`
+
+// SanctionsRepositoryImpl реализация SanctionsRepository
+type SanctionsRepositoryImpl struct {
+	db *pgxpool.Pool
+}
+
+// NewSanctionsRepository создает новый экземпляр SanctionsRepository
+func NewSanctionsRepository(db *pgxpool.Pool) SanctionsRepository {
+	return &SanctionsRepositoryImpl{db: db}
+}
+
+// ListLists возвращает загруженные списки по имени
+func (r *SanctionsRepositoryImpl) ListLists(ctx context.Context) ([]*domain.SanctionsList, error) {
+	query := `
+		SELECT id, name, source, checksum, entries_count, loaded_at, created_at, updated_at
+		FROM sanctions_lists
+		ORDER BY name`
+
+	rows, err := conn(ctx, r.db).Query(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var lists []*domain.SanctionsList
+	for rows.Next() {
+		list := &domain.SanctionsList{}
+		if err := rows.Scan(
+			&list.ID,
+			&list.Name,
+			&list.Source,
+			&list.Checksum,
+			&list.EntriesCount,
+			&list.LoadedAt,
+			&list.CreatedAt,
+			&list.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		lists = append(lists, list)
+	}
+
+	return lists, rows.Err()
+}
+
+// ReplaceList сохраняет новую версию списка: записи прежней версии заменяются целиком в одной транзакции
+func (r *SanctionsRepositoryImpl) ReplaceList(ctx context.Context, list *domain.SanctionsList, entries []*domain.SanctionsEntry) error {
+	tx, err := conn(ctx, r.db).Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	now := time.Now()
+	list.EntriesCount = len(entries)
+	list.LoadedAt = now
+
+	err = tx.QueryRow(ctx, `
+		INSERT INTO sanctions_lists (name, source, checksum, entries_count, loaded_at, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $5, $5)
+		ON CONFLICT (name) DO UPDATE
+		SET source = EXCLUDED.source, checksum = EXCLUDED.checksum,
+			entries_count = EXCLUDED.entries_count, loaded_at = EXCLUDED.loaded_at
+		RETURNING id, created_at, updated_at`,
+		list.Name, list.Source, list.Checksum, list.EntriesCount, list.LoadedAt,
+	).Scan(&list.ID, &list.CreatedAt, &list.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to save sanctions list: %w", err)
+	}
+
+	if _, err := tx.Exec(ctx, `DELETE FROM sanctions_entries WHERE list_id = $1`, list.ID); err != nil {
+		return fmt.Errorf("failed to delete sanctions entries: %w", err)
+	}
+
+	// Списки содержат тысячи записей: загружаем их через COPY
+	_, err = tx.CopyFrom(ctx,
+		pgx.Identifier{"sanctions_entries"},
+		[]string{"list_id", "external_id", "name", "aliases", "birth_date", "country", "program"},
+		pgx.CopyFromSlice(len(entries), func(i int) ([]any, error) {
+			entry := entries[i]
+			entry.ListID = list.ID
+			aliases := entry.Aliases
+			if aliases == nil {
+				aliases = []string{}
+			}
+			return []any{entry.ListID, entry.ExternalID, entry.Name, aliases, entry.BirthDate, entry.Country, entry.Program}, nil
+		}),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to copy sanctions entries: %w", err)
+	}
+
+	return tx.Commit(ctx)
+}
+
+// ListEntries возвращает записи всех загруженных списков
+func (r *SanctionsRepositoryImpl) ListEntries(ctx context.Context) ([]*domain.SanctionsEntry, error) {
+	query := `
+		SELECT id, list_id, external_id, name, aliases, birth_date, country, program
+		FROM sanctions_entries
+		ORDER BY list_id, id`
+
+	rows, err := conn(ctx, r.db).Query(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var entries []*domain.SanctionsEntry
+	for rows.Next() {
+		entry := &domain.SanctionsEntry{}
+		if err := rows.Scan(
+			&entry.ID,
+			&entry.ListID,
+			&entry.ExternalID,
+			&entry.Name,
+			&entry.Aliases,
+			&entry.BirthDate,
+			&entry.Country,
+			&entry.Program,
+		); err != nil {
+			return nil, err
+		}
+		entries = append(entries, entry)
+	}
+
+	return entries, rows.Err()
+}
+
+// CreateHit ставит совпадение в очередь проверки. Возвращает false, если совпадение клиента
+// с этой записью списка уже есть: повторные проверки не отменяют решение оператора.
+func (r *SanctionsRepositoryImpl) CreateHit(ctx context.Context, hit *domain.SanctionsHit) (bool, error) {
+	query := `
+		INSERT INTO sanctions_hits (user_id, list_name, entry_external_id, entry_name, matched_name, score, source,
+			status, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		ON CONFLICT (user_id, list_name, entry_external_id) DO NOTHING
+		RETURNING id`
+
+	now := time.Now()
+	hit.Status = domain.SanctionsHitStatusPending
+	hit.CreatedAt = now
+	hit.UpdatedAt = now
+
+	err := conn(ctx, r.db).QueryRow(ctx, query,
+		hit.UserID,
+		hit.ListName,
+		hit.EntryExternalID,
+		hit.EntryName,
+		hit.MatchedName,
+		hit.Score,
+		hit.Source,
+		hit.Status,
+		hit.CreatedAt,
+		hit.UpdatedAt,
+	).Scan(&hit.ID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return false, nil
+		}
+		return false, err
+	}
+
+	return true, nil
+}
+
+// GetHit получает совпадение по ID
+func (r *SanctionsRepositoryImpl) GetHit(ctx context.Context, id int) (*domain.SanctionsHit, error) {
+	query := `SELECT ` + sanctionsHitColumns + ` FROM sanctions_hits WHERE id = $1`
+
+	hit, err := scanSanctionsHit(conn(ctx, r.db).QueryRow(ctx, query, id))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.ErrSanctionsHitNotFound
+		}
+		return nil, err
+	}
+
+	return hit, nil
+}
+
+// ListHits возвращает совпадения с указанным статусом, старые первыми
+func (r *SanctionsRepositoryImpl) ListHits(ctx context.Context, status string, limit, offset int) ([]*domain.SanctionsHit, error) {
+	query := `
+		SELECT ` + sanctionsHitColumns + `
+		FROM sanctions_hits
+		WHERE status = $1
+		ORDER BY created_at, id
+		LIMIT $2 OFFSET $3`
+
+	return r.queryHits(ctx, query, status, limit, offset)
+}
+
+// GetUserHits возвращает все совпадения клиента
+func (r *SanctionsRepositoryImpl) GetUserHits(ctx context.Context, userID int) ([]*domain.SanctionsHit, error) {
+	query := `
+		SELECT ` + sanctionsHitColumns + `
+		FROM sanctions_hits
+		WHERE user_id = $1
+		ORDER BY id`
+
+	return r.queryHits(ctx, query, userID)
+}
+
+// ResolveHit фиксирует решение оператора по совпадению в статусе pending
+func (r *SanctionsRepositoryImpl) ResolveHit(ctx context.Context, id int, status string, reviewerID int, comment string) (*domain.SanctionsHit, error) {
+	query := `
+		UPDATE sanctions_hits
+		SET status = $2, reviewer_id = $3, comment = $4, reviewed_at = $5, updated_at = $5
+		WHERE id = $1 AND status = 'pending'
+		RETURNING ` + sanctionsHitColumns
+
+	hit, err := scanSanctionsHit(conn(ctx, r.db).QueryRow(ctx, query, id, status, reviewerID, comment, time.Now()))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			// Совпадение либо не существует, либо уже решено
+			if _, getErr := r.GetHit(ctx, id); getErr != nil {
+				return nil, getErr
+			}
+			return nil, domain.ErrSanctionsHitResolved
+		}
+		return nil, err
+	}
+
+	return hit, nil
+}
+
+func (r *SanctionsRepositoryImpl) queryHits(ctx context.Context, query string, args ...any) ([]*domain.SanctionsHit, error) {
+	rows, err := conn(ctx, r.db).Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var hits []*domain.SanctionsHit
+	for rows.Next() {
+		hit, err := scanSanctionsHit(rows)
+		if err != nil {
+			return nil, err
+		}
+		hits = append(hits, hit)
+	}
+
+	return hits, rows.Err()
+}
+
+func scanSanctionsHit(row pgx.Row) (*domain.SanctionsHit, error) {
+	hit := &domain.SanctionsHit{}
+	err := row.Scan(
+		&hit.ID,
+		&hit.UserID,
+		&hit.ListName,
+		&hit.EntryExternalID,
+		&hit.EntryName,
+		&hit.MatchedName,
+		&hit.Score,
+		&hit.Source,
+		&hit.Status,
+		&hit.ReviewerID,
+		&hit.Comment,
+		&hit.ReviewedAt,
+		&hit.CreatedAt,
+		&hit.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return hit, nil
+}
@@ -0,0 +1,243 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/vterdunov/learn-bank-app/internal/domain"
+)
+
+// StandingOrderRepositoryImpl реализация StandingOrderRepository
+type StandingOrderRepositoryImpl struct {
+	db *pgxpool.Pool
+}
+
+// NewStandingOrderRepository создает новый экземпляр StandingOrderRepository
+func NewStandingOrderRepository(db *pgxpool.Pool) StandingOrderRepository {
+	return &StandingOrderRepositoryImpl{db: db}
+}
+
+// Create создает постоянное поручение
+func (r *StandingOrderRepositoryImpl) Create(ctx context.Context, order *domain.StandingOrder) error {
+	query := `
+		INSERT INTO standing_orders (user_id, from_account_id, to_account_id, to_masked_name, to_masked_account_number,
+			amount, description, schedule, day_of_week, day_of_month, start_date, end_date, next_run_date, attempts,
+			retry_at, status, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18)
+		RETURNING id`
+
+	now := time.Now()
+	order.CreatedAt = now
+	order.UpdatedAt = now
+
+	return conn(ctx, r.db).QueryRow(ctx, query,
+		order.UserID,
+		order.FromAccountID,
+		order.ToAccountID,
+		order.ToMaskedName,
+		order.ToMaskedAccountNumber,
+		order.Amount,
+		order.Description,
+		order.Schedule,
+		order.DayOfWeek,
+		order.DayOfMonth,
+		order.StartDate,
+		order.EndDate,
+		order.NextRunDate,
+		order.Attempts,
+		order.RetryAt,
+		order.Status,
+		order.CreatedAt,
+		order.UpdatedAt,
+	).Scan(&order.ID)
+}
+
+// GetByID получает поручение по ID
+func (r *StandingOrderRepositoryImpl) GetByID(ctx context.Context, id int) (*domain.StandingOrder, error) {
+	query := `SELECT ` + standingOrderColumns + ` FROM standing_orders WHERE id = $1`
+
+	return r.get(ctx, query, id)
+}
+
+// GetByIDForUpdate получает поручение по ID с блокировкой строки до конца транзакции
+func (r *StandingOrderRepositoryImpl) GetByIDForUpdate(ctx context.Context, id int) (*domain.StandingOrder, error) {
+	query := `SELECT ` + standingOrderColumns + ` FROM standing_orders WHERE id = $1 FOR UPDATE`
+
+	return r.get(ctx, query, id)
+}
+
+// GetByUserID получает поручения пользователя (новые первыми)
+func (r *StandingOrderRepositoryImpl) GetByUserID(ctx context.Context, userID int) ([]*domain.StandingOrder, error) {
+	query := `SELECT ` + standingOrderColumns + ` FROM standing_orders WHERE user_id = $1 ORDER BY id DESC`
+
+	return r.list(ctx, query, userID)
+}
+
+// Update сохраняет условия, расписание и статус поручения
+func (r *StandingOrderRepositoryImpl) Update(ctx context.Context, order *domain.StandingOrder) error {
+	query := `
+		UPDATE standing_orders
+		SET from_account_id = $2, to_account_id = $3, to_masked_name = $4, to_masked_account_number = $5,
+			amount = $6, description = $7, schedule = $8, day_of_week = $9, day_of_month = $10, start_date = $11,
+			end_date = $12, next_run_date = $13, attempts = $14, retry_at = $15, status = $16
+		WHERE id = $1`
+
+	tag, err := conn(ctx, r.db).Exec(ctx, query,
+		order.ID,
+		order.FromAccountID,
+		order.ToAccountID,
+		order.ToMaskedName,
+		order.ToMaskedAccountNumber,
+		order.Amount,
+		order.Description,
+		order.Schedule,
+		order.DayOfWeek,
+		order.DayOfMonth,
+		order.StartDate,
+		order.EndDate,
+		order.NextRunDate,
+		order.Attempts,
+		order.RetryAt,
+		order.Status,
+	)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.ErrStandingOrderNotFound
+	}
+
+	order.UpdatedAt = time.Now()
+	return nil
+}
+
+// ListDue возвращает активные поручения, платеж по которым наступил и время повтора прошло
+func (r *StandingOrderRepositoryImpl) ListDue(ctx context.Context, today, now time.Time) ([]*domain.StandingOrder, error) {
+	query := `
+		SELECT ` + standingOrderColumns + `
+		FROM standing_orders
+		WHERE status = 'active' AND next_run_date <= $1 AND (retry_at IS NULL OR retry_at <= $2)
+		ORDER BY next_run_date, id`
+
+	return r.list(ctx, query, today, now)
+}
+
+// AddExecution записывает попытку исполнения поручения
+func (r *StandingOrderRepositoryImpl) AddExecution(ctx context.Context, execution *domain.StandingOrderExecution) error {
+	query := `
+		INSERT INTO standing_order_executions (standing_order_id, scheduled_date, attempt, status, amount, error, executed_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING id`
+
+	return conn(ctx, r.db).QueryRow(ctx, query,
+		execution.StandingOrderID,
+		execution.ScheduledDate,
+		execution.Attempt,
+		execution.Status,
+		execution.Amount,
+		execution.Error,
+		execution.ExecutedAt,
+	).Scan(&execution.ID)
+}
+
+// GetExecutions возвращает историю исполнения поручения (новые первыми)
+func (r *StandingOrderRepositoryImpl) GetExecutions(ctx context.Context, orderID, limit, offset int) ([]*domain.StandingOrderExecution, error) {
+	query := `
+		SELECT id, standing_order_id, scheduled_date, attempt, status, amount, error, executed_at
+		FROM standing_order_executions
+		WHERE standing_order_id = $1
+		ORDER BY id DESC
+		LIMIT $2 OFFSET $3`
+
+	rows, err := conn(ctx, r.db).Query(ctx, query, orderID, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var executions []*domain.StandingOrderExecution
+	for rows.Next() {
+		execution := &domain.StandingOrderExecution{}
+		err := rows.Scan(
+			&execution.ID,
+			&execution.StandingOrderID,
+			&execution.ScheduledDate,
+			&execution.Attempt,
+			&execution.Status,
+			&execution.Amount,
+			&execution.Error,
+			&execution.ExecutedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		executions = append(executions, execution)
+	}
+
+	return executions, rows.Err()
+}
+
+func (r *StandingOrderRepositoryImpl) get(ctx context.Context, query string, id int) (*domain.StandingOrder, error) {
+	order := &domain.StandingOrder{}
+	err := scanStandingOrder(conn(ctx, r.db).QueryRow(ctx, query, id), order)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.ErrStandingOrderNotFound
+		}
+		return nil, err
+	}
+
+	return order, nil
+}
+
+func (r *StandingOrderRepositoryImpl) list(ctx context.Context, query string, args ...any) ([]*domain.StandingOrder, error) {
+	rows, err := conn(ctx, r.db).Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var orders []*domain.StandingOrder
+	for rows.Next() {
+		order := &domain.StandingOrder{}
+		if err := scanStandingOrder(rows, order); err != nil {
+			return nil, err
+		}
+		orders = append(orders, order)
+	}
+
+	return orders, rows.Err()
+}
+
+// standingOrderColumns список колонок поручения
+const standingOrderColumns = `id, user_id, from_account_id, to_account_id, to_masked_name, to_masked_account_number,
+	amount, description, schedule, day_of_week, day_of_month, start_date, end_date, next_run_date, attempts, retry_at,
+	status, created_at, updated_at`
+
+func scanStandingOrder(row pgx.Row, order *domain.StandingOrder) error {
+	return row.Scan(
+		&order.ID,
+		&order.UserID,
+		&order.FromAccountID,
+		&order.ToAccountID,
+		&order.ToMaskedName,
+		&order.ToMaskedAccountNumber,
+		&order.Amount,
+		&order.Description,
+		&order.Schedule,
+		&order.DayOfWeek,
+		&order.DayOfMonth,
+		&order.StartDate,
+		&order.EndDate,
+		&order.NextRunDate,
+		&order.Attempts,
+		&order.RetryAt,
+		&order.Status,
+		&order.CreatedAt,
+		&order.UpdatedAt,
+	)
+}
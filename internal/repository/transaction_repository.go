@@ -24,21 +24,23 @@ func NewTransactionRepository(db *pgxpool.Pool) TransactionRepository {
 // Create создает новую транзакцию
 func (r *TransactionRepositoryImpl) Create(ctx context.Context, transaction *domain.Transaction) error {
 	query := `
-		INSERT INTO transactions (from_account, to_account, amount, type, status, description, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		INSERT INTO transactions (from_account, to_account, amount, type, status, description, merchant_id, mcc, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, NULLIF($8, ''), $9, $10)
 		RETURNING id`
 
 	now := time.Now()
 	transaction.CreatedAt = now
 	transaction.UpdatedAt = now
 
-	err := r.db.QueryRow(ctx, query,
+	err := conn(ctx, r.db).QueryRow(ctx, query,
 		transaction.FromAccount,
 		transaction.ToAccount,
 		transaction.Amount,
 		transaction.Type,
 		transaction.Status,
 		transaction.Description,
+		transaction.MerchantID,
+		transaction.MCC,
 		transaction.CreatedAt,
 		transaction.UpdatedAt,
 	).Scan(&transaction.ID)
@@ -48,24 +50,10 @@ func (r *TransactionRepositoryImpl) Create(ctx context.Context, transaction *dom
 
 // GetByID получает транзакцию по ID
 func (r *TransactionRepositoryImpl) GetByID(ctx context.Context, id int) (*domain.Transaction, error) {
-	query := `
-		SELECT id, from_account, to_account, amount, type, status, description, created_at, updated_at
-		FROM transactions
-		WHERE id = $1`
+	query := `SELECT ` + transactionColumns + ` FROM transactions WHERE id = $1`
 
 	transaction := &domain.Transaction{}
-	err := r.db.QueryRow(ctx, query, id).Scan(
-		&transaction.ID,
-		&transaction.FromAccount,
-		&transaction.ToAccount,
-		&transaction.Amount,
-		&transaction.Type,
-		&transaction.Status,
-		&transaction.Description,
-		&transaction.CreatedAt,
-		&transaction.UpdatedAt,
-	)
-
+	err := scanTransaction(conn(ctx, r.db).QueryRow(ctx, query, id), transaction)
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			return nil, errors.New("transaction not found")
@@ -79,79 +67,26 @@ func (r *TransactionRepositoryImpl) GetByID(ctx context.Context, id int) (*domai
 // GetByAccountID получает транзакции по ID счета с пагинацией
 func (r *TransactionRepositoryImpl) GetByAccountID(ctx context.Context, accountID int, limit, offset int) ([]*domain.Transaction, error) {
 	query := `
-		SELECT id, from_account, to_account, amount, type, status, description, created_at, updated_at
+		SELECT ` + transactionColumns + `
 		FROM transactions
 		WHERE from_account = $1 OR to_account = $1
 		ORDER BY created_at DESC
 		LIMIT $2 OFFSET $3`
 
-	rows, err := r.db.Query(ctx, query, accountID, limit, offset)
-	if err != nil {
-		return nil, err
-	}
-	defer rows.Close()
-
-	var transactions []*domain.Transaction
-	for rows.Next() {
-		transaction := &domain.Transaction{}
-		err := rows.Scan(
-			&transaction.ID,
-			&transaction.FromAccount,
-			&transaction.ToAccount,
-			&transaction.Amount,
-			&transaction.Type,
-			&transaction.Status,
-			&transaction.Description,
-			&transaction.CreatedAt,
-			&transaction.UpdatedAt,
-		)
-		if err != nil {
-			return nil, err
-		}
-		transactions = append(transactions, transaction)
-	}
-
-	return transactions, nil
+	return r.list(ctx, query, accountID, limit, offset)
 }
 
 // GetByUserID получает транзакции пользователя с пагинацией
 func (r *TransactionRepositoryImpl) GetByUserID(ctx context.Context, userID int, limit, offset int) ([]*domain.Transaction, error) {
 	query := `
-		SELECT t.id, t.from_account, t.to_account, t.amount, t.type, t.status, t.description, t.created_at, t.updated_at
-		FROM transactions t
-		LEFT JOIN accounts a1 ON t.from_account = a1.id
-		LEFT JOIN accounts a2 ON t.to_account = a2.id
-		WHERE a1.user_id = $1 OR a2.user_id = $1
-		ORDER BY t.created_at DESC
+		SELECT ` + transactionColumns + `
+		FROM transactions
+		WHERE from_account IN (SELECT id FROM accounts WHERE user_id = $1)
+		   OR to_account IN (SELECT id FROM accounts WHERE user_id = $1)
+		ORDER BY created_at DESC
 		LIMIT $2 OFFSET $3`
 
-	rows, err := r.db.Query(ctx, query, userID, limit, offset)
-	if err != nil {
-		return nil, err
-	}
-	defer rows.Close()
-
-	var transactions []*domain.Transaction
-	for rows.Next() {
-		transaction := &domain.Transaction{}
-		err := rows.Scan(
-			&transaction.ID,
-			&transaction.FromAccount,
-			&transaction.ToAccount,
-			&transaction.Amount,
-			&transaction.Type,
-			&transaction.Status,
-			&transaction.Description,
-			&transaction.CreatedAt,
-			&transaction.UpdatedAt,
-		)
-		if err != nil {
-			return nil, err
-		}
-		transactions = append(transactions, transaction)
-	}
-
-	return transactions, nil
+	return r.list(ctx, query, userID, limit, offset)
 }
 
 // Update обновляет транзакцию
@@ -163,7 +98,7 @@ func (r *TransactionRepositoryImpl) Update(ctx context.Context, transaction *dom
 
 	transaction.UpdatedAt = time.Now()
 
-	result, err := r.db.Exec(ctx, query,
+	result, err := conn(ctx, r.db).Exec(ctx, query,
 		transaction.ID,
 		transaction.Amount,
 		transaction.Type,
@@ -187,7 +122,7 @@ func (r *TransactionRepositoryImpl) Update(ctx context.Context, transaction *dom
 func (r *TransactionRepositoryImpl) Delete(ctx context.Context, id int) error {
 	query := `DELETE FROM transactions WHERE id = $1`
 
-	result, err := r.db.Exec(ctx, query, id)
+	result, err := conn(ctx, r.db).Exec(ctx, query, id)
 	if err != nil {
 		return err
 	}
@@ -202,39 +137,13 @@ func (r *TransactionRepositoryImpl) Delete(ctx context.Context, id int) error {
 // GetTransactionsByDateRange получает транзакции за период
 func (r *TransactionRepositoryImpl) GetTransactionsByDateRange(ctx context.Context, accountID int, startDate, endDate time.Time) ([]*domain.Transaction, error) {
 	query := `
-		SELECT id, from_account, to_account, amount, type, status, description, created_at, updated_at
+		SELECT ` + transactionColumns + `
 		FROM transactions
 		WHERE (from_account = $1 OR to_account = $1)
 		  AND created_at >= $2 AND created_at <= $3
 		ORDER BY created_at DESC`
 
-	rows, err := r.db.Query(ctx, query, accountID, startDate, endDate)
-	if err != nil {
-		return nil, err
-	}
-	defer rows.Close()
-
-	var transactions []*domain.Transaction
-	for rows.Next() {
-		transaction := &domain.Transaction{}
-		err := rows.Scan(
-			&transaction.ID,
-			&transaction.FromAccount,
-			&transaction.ToAccount,
-			&transaction.Amount,
-			&transaction.Type,
-			&transaction.Status,
-			&transaction.Description,
-			&transaction.CreatedAt,
-			&transaction.UpdatedAt,
-		)
-		if err != nil {
-			return nil, err
-		}
-		transactions = append(transactions, transaction)
-	}
-
-	return transactions, nil
+	return r.list(ctx, query, accountID, startDate, endDate)
 }
 
 // GetMonthlyStatistics получает месячную статистику транзакций
@@ -264,7 +173,7 @@ func (r *TransactionRepositoryImpl) GetMonthlyStatistics(ctx context.Context, us
 		Month: month,
 	}
 
-	err := r.db.QueryRow(ctx, query, userID, year, month).Scan(&stats.Income, &stats.Expenses)
+	err := conn(ctx, r.db).QueryRow(ctx, query, userID, year, month).Scan(&stats.Income, &stats.Expenses)
 	if err != nil {
 		return nil, err
 	}
@@ -273,3 +182,137 @@ func (r *TransactionRepositoryImpl) GetMonthlyStatistics(ctx context.Context, us
 
 	return stats, nil
 }
+
+// GetTransferStats получает дату первого перевода пользователя на счет и сумму переводов на него с указанного момента
+func (r *TransactionRepositoryImpl) GetTransferStats(ctx context.Context, userID, toAccountID int, since time.Time) (*domain.TransferStats, error) {
+	query := `
+		SELECT MIN(t.created_at), COALESCE(SUM(t.amount) FILTER (WHERE t.created_at >= $3), 0)
+		FROM transactions t
+		JOIN accounts a ON t.from_account = a.id
+		WHERE a.user_id = $1
+		  AND t.to_account = $2
+		  AND t.type = 'transfer'
+		  AND t.status = 'completed'`
+
+	stats := &domain.TransferStats{}
+	err := conn(ctx, r.db).QueryRow(ctx, query, userID, toAccountID, since).Scan(&stats.FirstTransferAt, &stats.AmountSince)
+	if err != nil {
+		return nil, err
+	}
+
+	return stats, nil
+}
+
+// GetOutgoingStats получает число и сумму исходящих операций со счетов пользователя с указанного момента
+func (r *TransactionRepositoryImpl) GetOutgoingStats(ctx context.Context, userID int, since time.Time) (*domain.OutgoingStats, error) {
+	query := `
+		SELECT COUNT(*), COALESCE(SUM(t.amount), 0)
+		FROM transactions t
+		JOIN accounts a ON t.from_account = a.id
+		WHERE a.user_id = $1
+		  AND t.type IN ('transfer', 'withdrawal', 'payment')
+		  AND t.status IN ('pending', 'completed')
+		  AND t.created_at >= $2`
+
+	stats := &domain.OutgoingStats{}
+	err := conn(ctx, r.db).QueryRow(ctx, query, userID, since).Scan(&stats.Count, &stats.Amount)
+	if err != nil {
+		return nil, err
+	}
+
+	return stats, nil
+}
+
+// GetExternalOutgoingAmount получает сумму исходящих операций пользователя с указанного момента
+// без переводов между его собственными счетами
+func (r *TransactionRepositoryImpl) GetExternalOutgoingAmount(ctx context.Context, userID int, since time.Time) (float64, error) {
+	query := `
+		SELECT COALESCE(SUM(t.amount), 0)
+		FROM transactions t
+		JOIN accounts a ON t.from_account = a.id
+		LEFT JOIN accounts b ON t.to_account = b.id
+		WHERE a.user_id = $1
+		  AND t.type IN ('transfer', 'withdrawal', 'payment')
+		  AND t.status IN ('pending', 'completed')
+		  AND t.created_at >= $2
+		  AND (b.id IS NULL OR b.user_id <> a.user_id)`
+
+	var amount float64
+	if err := conn(ctx, r.db).QueryRow(ctx, query, userID, since).Scan(&amount); err != nil {
+		return 0, err
+	}
+
+	return amount, nil
+}
+
+// GetSpendingByMCC получает расходы пользователя по картам за месяц в разрезе MCC (за вычетом возвратов)
+func (r *TransactionRepositoryImpl) GetSpendingByMCC(ctx context.Context, userID int, year int, month int) ([]*domain.MCCSpending, error) {
+	query := `
+		SELECT COALESCE(t.mcc, ''),
+			SUM(CASE WHEN t.type = 'payment' THEN t.amount ELSE -t.amount END) AS amount
+		FROM transactions t
+		JOIN accounts a ON a.id = CASE WHEN t.type = 'payment' THEN t.from_account ELSE t.to_account END
+		WHERE a.user_id = $1
+		  AND t.type IN ('payment', 'refund')
+		  AND t.status = 'completed'
+		  AND EXTRACT(YEAR FROM t.created_at) = $2
+		  AND EXTRACT(MONTH FROM t.created_at) = $3
+		GROUP BY COALESCE(t.mcc, '')
+		ORDER BY amount DESC`
+
+	rows, err := conn(ctx, r.db).Query(ctx, query, userID, year, month)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var spending []*domain.MCCSpending
+	for rows.Next() {
+		item := &domain.MCCSpending{}
+		if err := rows.Scan(&item.MCC, &item.Amount); err != nil {
+			return nil, err
+		}
+		spending = append(spending, item)
+	}
+
+	return spending, rows.Err()
+}
+
+func (r *TransactionRepositoryImpl) list(ctx context.Context, query string, args ...any) ([]*domain.Transaction, error) {
+	rows, err := conn(ctx, r.db).Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var transactions []*domain.Transaction
+	for rows.Next() {
+		transaction := &domain.Transaction{}
+		if err := scanTransaction(rows, transaction); err != nil {
+			return nil, err
+		}
+		transactions = append(transactions, transaction)
+	}
+
+	return transactions, rows.Err()
+}
+
+// transactionColumns список колонок транзакции
+const transactionColumns = `id, from_account, to_account, amount, type, status, description, merchant_id, COALESCE(mcc, ''),
+	created_at, updated_at`
+
+func scanTransaction(row pgx.Row, transaction *domain.Transaction) error {
+	return row.Scan(
+		&transaction.ID,
+		&transaction.FromAccount,
+		&transaction.ToAccount,
+		&transaction.Amount,
+		&transaction.Type,
+		&transaction.Status,
+		&transaction.Description,
+		&transaction.MerchantID,
+		&transaction.MCC,
+		&transaction.CreatedAt,
+		&transaction.UpdatedAt,
+	)
+}
@@ -0,0 +1,71 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// querier общий интерфейс pgxpool.Pool и pgx.Tx
+type querier interface {
+	Begin(ctx context.Context) (pgx.Tx, error)
+	Exec(ctx context.Context, sql string, arguments ...any) (pgconn.CommandTag, error)
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+}
+
+// txKey ключ контекста для текущей транзакции
+type txKey struct{}
+
+// TxManager выполняет функцию в транзакции БД.
+// Репозитории, вызванные с переданным контекстом, работают в той же транзакции.
+type TxManager interface {
+	WithinTx(ctx context.Context, fn func(ctx context.Context) error) error
+}
+
+// TxManagerImpl реализация TxManager
+type TxManagerImpl struct {
+	db *pgxpool.Pool
+}
+
+// NewTxManager создает новый экземпляр TxManager
+func NewTxManager(db *pgxpool.Pool) TxManager {
+	return &TxManagerImpl{db: db}
+}
+
+// WithinTx открывает транзакцию (или savepoint, если транзакция уже есть в контексте)
+// и фиксирует ее, если fn завершилась без ошибки
+func (m *TxManagerImpl) WithinTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	tx, err := conn(ctx, m.db).Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	if err := fn(context.WithValue(ctx, txKey{}, tx)); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// conn возвращает транзакцию из контекста или пул соединений
+func conn(ctx context.Context, db *pgxpool.Pool) querier {
+	if tx, ok := ctx.Value(txKey{}).(pgx.Tx); ok {
+		return tx
+	}
+	return db
+}
+
+// DetachTx возвращает контекст без текущей транзакции: записи через него сохраняются,
+// даже если транзакция вызывающего будет откачена
+func DetachTx(ctx context.Context) context.Context {
+	return context.WithValue(ctx, txKey{}, nil)
+}
@@ -23,18 +23,20 @@ func NewUserRepository(db *pgxpool.Pool) UserRepository {
 // Create создает нового пользователя
 func (r *UserRepositoryImpl) Create(ctx context.Context, user *domain.User) error {
 	query := `
-		INSERT INTO users (username, email, password_hash, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5)
+		INSERT INTO users (username, email, password_hash, locale, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
 		RETURNING id`
 
 	now := time.Now()
 	user.CreatedAt = now
 	user.UpdatedAt = now
+	user.Locale = domain.NormalizeLocale(user.Locale)
 
-	err := r.db.QueryRow(ctx, query,
+	err := conn(ctx, r.db).QueryRow(ctx, query,
 		user.Username,
 		user.Email,
 		user.PasswordHash,
+		user.Locale,
 		user.CreatedAt,
 		user.UpdatedAt,
 	).Scan(&user.ID)
@@ -49,16 +51,18 @@ func (r *UserRepositoryImpl) Create(ctx context.Context, user *domain.User) erro
 // GetByID получает пользователя по ID
 func (r *UserRepositoryImpl) GetByID(ctx context.Context, id int) (*domain.User, error) {
 	query := `
-		SELECT id, username, email, password_hash, created_at, updated_at
+		SELECT id, username, email, password_hash, role, locale, created_at, updated_at
 		FROM users
 		WHERE id = $1`
 
 	user := &domain.User{}
-	err := r.db.QueryRow(ctx, query, id).Scan(
+	err := conn(ctx, r.db).QueryRow(ctx, query, id).Scan(
 		&user.ID,
 		&user.Username,
 		&user.Email,
 		&user.PasswordHash,
+		&user.Role,
+		&user.Locale,
 		&user.CreatedAt,
 		&user.UpdatedAt,
 	)
@@ -76,16 +80,18 @@ func (r *UserRepositoryImpl) GetByID(ctx context.Context, id int) (*domain.User,
 // GetByEmail получает пользователя по email
 func (r *UserRepositoryImpl) GetByEmail(ctx context.Context, email string) (*domain.User, error) {
 	query := `
-		SELECT id, username, email, password_hash, created_at, updated_at
+		SELECT id, username, email, password_hash, role, locale, created_at, updated_at
 		FROM users
 		WHERE email = $1`
 
 	user := &domain.User{}
-	err := r.db.QueryRow(ctx, query, email).Scan(
+	err := conn(ctx, r.db).QueryRow(ctx, query, email).Scan(
 		&user.ID,
 		&user.Username,
 		&user.Email,
 		&user.PasswordHash,
+		&user.Role,
+		&user.Locale,
 		&user.CreatedAt,
 		&user.UpdatedAt,
 	)
@@ -103,16 +109,18 @@ func (r *UserRepositoryImpl) GetByEmail(ctx context.Context, email string) (*dom
 // GetByUsername получает пользователя по username
 func (r *UserRepositoryImpl) GetByUsername(ctx context.Context, username string) (*domain.User, error) {
 	query := `
-		SELECT id, username, email, password_hash, created_at, updated_at
+		SELECT id, username, email, password_hash, role, locale, created_at, updated_at
 		FROM users
 		WHERE username = $1`
 
 	user := &domain.User{}
-	err := r.db.QueryRow(ctx, query, username).Scan(
+	err := conn(ctx, r.db).QueryRow(ctx, query, username).Scan(
 		&user.ID,
 		&user.Username,
 		&user.Email,
 		&user.PasswordHash,
+		&user.Role,
+		&user.Locale,
 		&user.CreatedAt,
 		&user.UpdatedAt,
 	)
@@ -131,16 +139,18 @@ func (r *UserRepositoryImpl) GetByUsername(ctx context.Context, username string)
 func (r *UserRepositoryImpl) Update(ctx context.Context, user *domain.User) error {
 	query := `
 		UPDATE users
-		SET username = $2, email = $3, password_hash = $4, updated_at = $5
+		SET username = $2, email = $3, password_hash = $4, locale = $5, updated_at = $6
 		WHERE id = $1`
 
 	user.UpdatedAt = time.Now()
+	user.Locale = domain.NormalizeLocale(user.Locale)
 
-	result, err := r.db.Exec(ctx, query,
+	result, err := conn(ctx, r.db).Exec(ctx, query,
 		user.ID,
 		user.Username,
 		user.Email,
 		user.PasswordHash,
+		user.Locale,
 		user.UpdatedAt,
 	)
 
@@ -159,7 +169,7 @@ func (r *UserRepositoryImpl) Update(ctx context.Context, user *domain.User) erro
 func (r *UserRepositoryImpl) Delete(ctx context.Context, id int) error {
 	query := `DELETE FROM users WHERE id = $1`
 
-	result, err := r.db.Exec(ctx, query, id)
+	result, err := conn(ctx, r.db).Exec(ctx, query, id)
 	if err != nil {
 		return utils.WrapDBError(err, "delete user")
 	}
@@ -176,7 +186,7 @@ func (r *UserRepositoryImpl) EmailExists(ctx context.Context, email string) (boo
 	query := `SELECT EXISTS(SELECT 1 FROM users WHERE email = $1)`
 
 	var exists bool
-	err := r.db.QueryRow(ctx, query, email).Scan(&exists)
+	err := conn(ctx, r.db).QueryRow(ctx, query, email).Scan(&exists)
 	if err != nil {
 		return false, utils.WrapDBError(err, "check email exists")
 	}
@@ -189,10 +199,50 @@ func (r *UserRepositoryImpl) UsernameExists(ctx context.Context, username string
 	query := `SELECT EXISTS(SELECT 1 FROM users WHERE username = $1)`
 
 	var exists bool
-	err := r.db.QueryRow(ctx, query, username).Scan(&exists)
+	err := conn(ctx, r.db).QueryRow(ctx, query, username).Scan(&exists)
 	if err != nil {
 		return false, utils.WrapDBError(err, "check username exists")
 	}
 
 	return exists, nil
 }
+
+// ListAfterID возвращает пользователей с ID больше afterID по возрастанию ID (постраничный обход)
+func (r *UserRepositoryImpl) ListAfterID(ctx context.Context, afterID, limit int) ([]*domain.User, error) {
+	query := `
+		SELECT id, username, email, password_hash, role, locale, created_at, updated_at
+		FROM users
+		WHERE id > $1
+		ORDER BY id
+		LIMIT $2`
+
+	rows, err := conn(ctx, r.db).Query(ctx, query, afterID, limit)
+	if err != nil {
+		return nil, utils.WrapDBError(err, "list users")
+	}
+	defer rows.Close()
+
+	var users []*domain.User
+	for rows.Next() {
+		user := &domain.User{}
+		if err := rows.Scan(
+			&user.ID,
+			&user.Username,
+			&user.Email,
+			&user.PasswordHash,
+			&user.Role,
+			&user.Locale,
+			&user.CreatedAt,
+			&user.UpdatedAt,
+		); err != nil {
+			return nil, utils.WrapDBError(err, "scan user")
+		}
+		users = append(users, user)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, utils.WrapDBError(err, "list users")
+	}
+
+	return users, nil
+}
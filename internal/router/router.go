@@ -4,111 +4,324 @@ import (
 	"log/slog"
 	"net/http"
 
+	"github.com/vterdunov/learn-bank-app/internal/config"
 	"github.com/vterdunov/learn-bank-app/internal/handlers"
 	"github.com/vterdunov/learn-bank-app/internal/middleware"
 	"github.com/vterdunov/learn-bank-app/internal/service"
+	"github.com/vterdunov/learn-bank-app/internal/utils"
 )
 
 // Router содержит все маршруты приложения
 type Router struct {
-	mux       *http.ServeMux
-	logger    *slog.Logger
-	handlers  *Handlers
-	jwtSecret string
+	mux      *http.ServeMux
+	logger   *slog.Logger
+	handlers *Handlers
+	jwtKeys  *utils.KeyRing
+	users    middleware.UserRoleProvider
+	limiter  *middleware.RateLimiter
+	server   config.ServerConfig
+	handler  http.Handler
 }
 
 // Handlers содержит все обработчики
 type Handlers struct {
-	Auth      *handlers.AuthHandler
-	Account   *handlers.AccountHandler
-	Card      *handlers.CardHandler
-	Credit    *handlers.CreditHandler
-	Analytics *handlers.AnalyticsHandler
-	CBR       *handlers.CBRHandler
+	Auth         *handlers.AuthHandler
+	Account      *handlers.AccountHandler
+	Recipient    *handlers.RecipientHandler
+	Card         *handlers.CardHandler
+	Credit       *handlers.CreditHandler
+	Deposit      *handlers.DepositHandler
+	Standing     *handlers.StandingOrderHandler
+	Analytics    *handlers.AnalyticsHandler
+	CBR          *handlers.CBRHandler
+	JWKS         *handlers.JWKSHandler
+	Audit        *handlers.AuditHandler
+	Outbox       *handlers.OutboxHandler
+	Notification *handlers.NotificationHandler
+	Template     *handlers.TemplateHandler
+	Job          *handlers.JobHandler
+	Merchant     *handlers.MerchantHandler
+	Cashback     *handlers.CashbackHandler
+	CardReveal   *handlers.CardRevealHandler
+	Fraud        *handlers.FraudHandler
+	Sanctions    *handlers.SanctionsHandler
+	KYC          *handlers.KYCHandler
 }
 
 // Config содержит конфигурацию для роутера
 type Config struct {
-	Logger    *slog.Logger
-	Services  *Services
-	JWTSecret string
+	Logger   *slog.Logger
+	Services *Services
+	JWTKeys  *utils.KeyRing
+	// Users источник ролей пользователей для admin endpoints
+	Users middleware.UserRoleProvider
+	// RateLimiter ограничитель частоты запросов; nil отключает ограничения
+	RateLimiter *middleware.RateLimiter
+	// Server лимиты запросов, таймауты маршрутов, CORS и заголовки безопасности
+	Server config.ServerConfig
 }
 
 // Services содержит все сервисы
 type Services struct {
-	Auth      service.AuthService
-	Account   service.AccountService
-	Card      service.CardService
-	Credit    service.CreditService
-	Analytics service.AnalyticsService
-	CBR       service.CBRService
+	Auth         service.AuthService
+	Account      service.AccountService
+	Recipient    service.RecipientService
+	Card         service.CardService
+	Credit       service.CreditService
+	Deposit      service.DepositService
+	Standing     service.StandingOrderService
+	Analytics    service.AnalyticsService
+	CBR          service.CBRService
+	Audit        service.AuditService
+	Outbox       service.OutboxService
+	Notification service.NotificationService
+	Email        service.EmailService
+	Jobs         service.JobRunner
+	Merchant     service.MerchantService
+	Cashback     service.CashbackService
+	CardReveal   service.CardRevealService
+	Fraud        service.FraudService
+	Sanctions    service.SanctionsService
+	KYC          service.KYCService
 }
 
 // New создает новый роутер
 func New(config Config) *Router {
 	// Создаем все обработчики
 	h := &Handlers{
-		Auth:      handlers.NewAuthHandler(config.Services.Auth, config.Logger),
-		Account:   handlers.NewAccountHandler(config.Services.Account, config.Logger),
-		Card:      handlers.NewCardHandler(config.Services.Card, config.Logger),
-		Credit:    handlers.NewCreditHandler(config.Services.Credit, config.Logger),
-		Analytics: handlers.NewAnalyticsHandler(config.Services.Analytics, config.Logger),
-		CBR:       handlers.NewCBRHandler(config.Services.CBR, config.Logger),
+		Auth:         handlers.NewAuthHandler(config.Services.Auth, config.Logger),
+		Account:      handlers.NewAccountHandler(config.Services.Account, config.Services.Recipient, config.Logger),
+		Recipient:    handlers.NewRecipientHandler(config.Services.Recipient, config.Logger),
+		Card:         handlers.NewCardHandler(config.Services.Card, config.Logger),
+		Credit:       handlers.NewCreditHandler(config.Services.Credit, config.Logger),
+		Deposit:      handlers.NewDepositHandler(config.Services.Deposit, config.Logger),
+		Standing:     handlers.NewStandingOrderHandler(config.Services.Standing, config.Logger),
+		Analytics:    handlers.NewAnalyticsHandler(config.Services.Analytics, config.Logger),
+		CBR:          handlers.NewCBRHandler(config.Services.CBR, config.Logger),
+		JWKS:         handlers.NewJWKSHandler(config.JWTKeys, config.Logger),
+		Audit:        handlers.NewAuditHandler(config.Services.Audit, config.Logger),
+		Outbox:       handlers.NewOutboxHandler(config.Services.Outbox, config.Logger),
+		Template:     handlers.NewTemplateHandler(config.Services.Email, config.Logger),
+		Job:          handlers.NewJobHandler(config.Services.Jobs, config.Logger),
+		Notification: handlers.NewNotificationHandler(config.Services.Notification, config.Logger),
+		Merchant:     handlers.NewMerchantHandler(config.Services.Merchant, config.Logger),
+		Cashback:     handlers.NewCashbackHandler(config.Services.Cashback, config.Logger),
+		CardReveal:   handlers.NewCardRevealHandler(config.Services.CardReveal, config.Logger),
+		Fraud:        handlers.NewFraudHandler(config.Services.Fraud, config.Logger),
+		Sanctions:    handlers.NewSanctionsHandler(config.Services.Sanctions, config.Logger),
+		KYC:          handlers.NewKYCHandler(config.Services.KYC, config.Logger),
 	}
 
 	router := &Router{
-		mux:       http.NewServeMux(),
-		logger:    config.Logger,
-		handlers:  h,
-		jwtSecret: config.JWTSecret,
+		mux:      http.NewServeMux(),
+		logger:   config.Logger,
+		handlers: h,
+		jwtKeys:  config.JWTKeys,
+		users:    config.Users,
+		limiter:  config.RateLimiter,
+		server:   config.Server,
 	}
 
 	router.setupRoutes()
+
+	// Recovery снаружи всех маршрутов: паника в любом обработчике или middleware завершается ответом 500.
+	// ID запроса назначается до Recovery, чтобы попасть в лог паники.
+	// CORS обрабатывает preflight запросы OPTIONS, для которых маршрутов нет
+	router.handler = middleware.Chain(
+		middleware.RequestIDMiddleware(config.Server.TrustedProxies),
+		middleware.RecoveryMiddleware(config.Logger),
+		middleware.SecurityHeadersMiddleware(config.Server),
+		middleware.CORSMiddleware(config.Server.CORS),
+	)(router.mux)
+
 	return router
 }
 
 // setupRoutes настраивает все маршруты
 func (r *Router) setupRoutes() {
-	// Middleware для всех запросов
-	commonMiddleware := middleware.Chain(
-		middleware.LoggingMiddleware(),
-		middleware.RequestIDMiddleware(),
+	// Middleware для всех запросов; ID запроса назначается снаружи маршрутов, в New
+	commonMiddleware := middleware.LoggingMiddleware()
+
+	// Ограничения размера тела и времени обработки: по умолчанию, для запроса к ЦБ РФ и для передачи файлов
+	defaultLimits := middleware.Chain(
+		middleware.BodyLimitMiddleware(r.server.MaxBodySize),
+		middleware.TimeoutMiddleware(r.server.Timeouts.Default),
 	)
+	cbrLimits := middleware.Chain(
+		middleware.BodyLimitMiddleware(r.server.MaxBodySize),
+		middleware.TimeoutMiddleware(r.server.Timeouts.CBR),
+	)
+	fileLimits := middleware.Chain(
+		middleware.BodyLimitMiddleware(r.server.MaxUploadSize),
+		middleware.StreamingTimeoutMiddleware(r.server.Timeouts.File),
+	)
+
+	// Public routes (без аутентификации, лимиты по IP адресу)
+	registerMiddleware := middleware.Chain(commonMiddleware, middleware.RateLimitMiddleware(r.limiter, "register"), defaultLimits)
+	loginMiddleware := middleware.Chain(commonMiddleware, middleware.RateLimitMiddleware(r.limiter, "login"), defaultLimits)
+	cbrMiddleware := middleware.Chain(commonMiddleware, middleware.RateLimitMiddleware(r.limiter, "cbr"), cbrLimits)
+	publicMiddleware := middleware.Chain(commonMiddleware, middleware.RateLimitMiddleware(r.limiter, "public"), defaultLimits)
 
-	// Public routes (без аутентификации)
-	r.mux.Handle("POST /api/v1/auth/register", commonMiddleware(http.HandlerFunc(r.handlers.Auth.Register)))
-	r.mux.Handle("POST /api/v1/auth/login", commonMiddleware(http.HandlerFunc(r.handlers.Auth.Login)))
+	r.mux.Handle("POST /api/v1/auth/register", registerMiddleware(http.HandlerFunc(r.handlers.Auth.Register)))
+	r.mux.Handle("POST /api/v1/auth/login", loginMiddleware(http.HandlerFunc(r.handlers.Auth.Login)))
 
 	// Protected routes (с аутентификацией)
-	authMiddleware := middleware.Chain(
+	authBase := middleware.Chain(
 		middleware.LoggingMiddleware(),
-		middleware.RequestIDMiddleware(),
-		middleware.AuthMiddleware(r.jwtSecret),
+		middleware.AuthMiddleware(r.jwtKeys),
+		middleware.RateLimitMiddleware(r.limiter, "api"),
 	)
+	authMiddleware := middleware.Chain(authBase, defaultLimits)
+	authFileMiddleware := middleware.Chain(authBase, fileLimits)
 
 	// Account endpoints
 	r.mux.Handle("POST /api/v1/accounts", authMiddleware(http.HandlerFunc(r.handlers.Account.CreateAccount)))
 	r.mux.Handle("GET /api/v1/accounts", authMiddleware(http.HandlerFunc(r.handlers.Account.GetUserAccounts)))
 	r.mux.Handle("POST /api/v1/accounts/{id}/deposit", authMiddleware(http.HandlerFunc(r.handlers.Account.Deposit)))
 	r.mux.Handle("POST /api/v1/accounts/{id}/withdraw", authMiddleware(http.HandlerFunc(r.handlers.Account.Withdraw)))
+	r.mux.Handle("POST /api/v1/accounts/{id}/freeze", authMiddleware(http.HandlerFunc(r.handlers.Account.FreezeAccount)))
+	r.mux.Handle("POST /api/v1/accounts/{id}/unfreeze", authMiddleware(http.HandlerFunc(r.handlers.Account.UnfreezeAccount)))
+	r.mux.Handle("PUT /api/v1/accounts/{id}/overdraft", authMiddleware(http.HandlerFunc(r.handlers.Account.SetOverdraft)))
+	r.mux.Handle("POST /api/v1/accounts/{id}/close", authMiddleware(http.HandlerFunc(r.handlers.Account.CloseAccount)))
+	r.mux.Handle("GET /api/v1/accounts/{id}/holders", authMiddleware(http.HandlerFunc(r.handlers.Account.GetAccountHolders)))
+	r.mux.Handle("POST /api/v1/accounts/{id}/holders", authMiddleware(http.HandlerFunc(r.handlers.Account.AddAccountHolder)))
+	r.mux.Handle("DELETE /api/v1/accounts/{id}/holders/{userId}", authMiddleware(http.HandlerFunc(r.handlers.Account.RemoveAccountHolder)))
 	r.mux.Handle("POST /api/v1/transfer", authMiddleware(http.HandlerFunc(r.handlers.Account.Transfer)))
 
+	// Recipient endpoints
+	r.mux.Handle("POST /api/v1/transfer/recipient", authMiddleware(http.HandlerFunc(r.handlers.Recipient.ResolveRecipient)))
+	r.mux.Handle("GET /api/v1/payment-aliases", authMiddleware(http.HandlerFunc(r.handlers.Recipient.GetAliases)))
+	r.mux.Handle("POST /api/v1/payment-aliases", authMiddleware(http.HandlerFunc(r.handlers.Recipient.CreateAlias)))
+	r.mux.Handle("DELETE /api/v1/payment-aliases/{id}", authMiddleware(http.HandlerFunc(r.handlers.Recipient.DeleteAlias)))
+
 	// Card endpoints
 	r.mux.Handle("POST /api/v1/cards", authMiddleware(http.HandlerFunc(r.handlers.Card.CreateCard)))
 	r.mux.Handle("GET /api/v1/accounts/{accountId}/cards", authMiddleware(http.HandlerFunc(r.handlers.Card.GetAccountCards)))
 	r.mux.Handle("POST /api/v1/cards/{id}/payment", authMiddleware(http.HandlerFunc(r.handlers.Card.CardPayment)))
+	r.mux.Handle("POST /api/v1/card-payments", authMiddleware(http.HandlerFunc(r.handlers.Card.PayByCardDetails)))
+	r.mux.Handle("POST /api/v1/cards/{id}/authorizations", authMiddleware(http.HandlerFunc(r.handlers.Card.AuthorizePayment)))
+	r.mux.Handle("GET /api/v1/accounts/{accountId}/holds", authMiddleware(http.HandlerFunc(r.handlers.Card.GetAccountHolds)))
+	r.mux.Handle("POST /api/v1/card-holds/{id}/void", authMiddleware(http.HandlerFunc(r.handlers.Card.CancelAuthorization)))
+	r.mux.Handle("POST /api/v1/cards/{id}/pin", authMiddleware(http.HandlerFunc(r.handlers.Card.SetPIN)))
+	r.mux.Handle("PUT /api/v1/cards/{id}/pin", authMiddleware(http.HandlerFunc(r.handlers.Card.ChangePIN)))
+	r.mux.Handle("GET /api/v1/cards/{id}/rewards", authMiddleware(http.HandlerFunc(r.handlers.Cashback.GetCardRewards)))
+	r.mux.Handle("POST /api/v1/cards/{id}/reveal-token", authMiddleware(http.HandlerFunc(r.handlers.CardReveal.IssueToken)))
+	r.mux.Handle("POST /api/v1/cards/{id}/reveal", authMiddleware(http.HandlerFunc(r.handlers.CardReveal.Reveal)))
 
 	// Credit endpoints
 	r.mux.Handle("POST /api/v1/credits", authMiddleware(http.HandlerFunc(r.handlers.Credit.CreateCredit)))
 	r.mux.Handle("GET /api/v1/credits/{id}/schedule", authMiddleware(http.HandlerFunc(r.handlers.Credit.GetCreditSchedule)))
 
+	// Deposit endpoints
+	r.mux.Handle("GET /api/v1/deposits/products", authMiddleware(http.HandlerFunc(r.handlers.Deposit.ListProducts)))
+	r.mux.Handle("POST /api/v1/deposits", authMiddleware(http.HandlerFunc(r.handlers.Deposit.OpenDeposit)))
+	r.mux.Handle("GET /api/v1/deposits", authMiddleware(http.HandlerFunc(r.handlers.Deposit.GetUserDeposits)))
+	r.mux.Handle("GET /api/v1/deposits/{id}", authMiddleware(http.HandlerFunc(r.handlers.Deposit.GetDeposit)))
+	r.mux.Handle("GET /api/v1/deposits/{id}/projection", authMiddleware(http.HandlerFunc(r.handlers.Deposit.GetProjection)))
+	r.mux.Handle("POST /api/v1/deposits/{id}/close", authMiddleware(http.HandlerFunc(r.handlers.Deposit.CloseDeposit)))
+
+	// Standing order endpoints
+	r.mux.Handle("POST /api/v1/standing-orders", authMiddleware(http.HandlerFunc(r.handlers.Standing.CreateStandingOrder)))
+	r.mux.Handle("GET /api/v1/standing-orders", authMiddleware(http.HandlerFunc(r.handlers.Standing.GetUserStandingOrders)))
+	r.mux.Handle("GET /api/v1/standing-orders/{id}", authMiddleware(http.HandlerFunc(r.handlers.Standing.GetStandingOrder)))
+	r.mux.Handle("PUT /api/v1/standing-orders/{id}", authMiddleware(http.HandlerFunc(r.handlers.Standing.UpdateStandingOrder)))
+	r.mux.Handle("DELETE /api/v1/standing-orders/{id}", authMiddleware(http.HandlerFunc(r.handlers.Standing.CancelStandingOrder)))
+	r.mux.Handle("POST /api/v1/standing-orders/{id}/pause", authMiddleware(http.HandlerFunc(r.handlers.Standing.PauseStandingOrder)))
+	r.mux.Handle("POST /api/v1/standing-orders/{id}/resume", authMiddleware(http.HandlerFunc(r.handlers.Standing.ResumeStandingOrder)))
+	r.mux.Handle("GET /api/v1/standing-orders/{id}/executions", authMiddleware(http.HandlerFunc(r.handlers.Standing.GetExecutions)))
+
 	// Analytics endpoints
 	r.mux.Handle("GET /api/v1/analytics/monthly", authMiddleware(http.HandlerFunc(r.handlers.Analytics.GetMonthlyStats)))
 	r.mux.Handle("GET /api/v1/analytics/credit-load", authMiddleware(http.HandlerFunc(r.handlers.Analytics.GetCreditLoad)))
 	r.mux.Handle("POST /api/v1/analytics/balance-prediction", authMiddleware(http.HandlerFunc(r.handlers.Analytics.PredictBalance)))
+	r.mux.Handle("GET /api/v1/analytics/spending-categories", authMiddleware(http.HandlerFunc(r.handlers.Analytics.GetSpendingCategories)))
+
+	// Notification endpoints
+	r.mux.Handle("GET /api/v1/notifications", authMiddleware(http.HandlerFunc(r.handlers.Notification.ListNotifications)))
+	r.mux.Handle("POST /api/v1/notifications/{id}/read", authMiddleware(http.HandlerFunc(r.handlers.Notification.MarkRead)))
+	r.mux.Handle("POST /api/v1/notifications/read-all", authMiddleware(http.HandlerFunc(r.handlers.Notification.MarkAllRead)))
+	r.mux.Handle("GET /api/v1/notifications/preferences", authMiddleware(http.HandlerFunc(r.handlers.Notification.GetPreferences)))
+	r.mux.Handle("PUT /api/v1/notifications/preferences", authMiddleware(http.HandlerFunc(r.handlers.Notification.UpdatePreferences)))
+	r.mux.Handle("GET /api/v1/notifications/endpoints", authMiddleware(http.HandlerFunc(r.handlers.Notification.ListEndpoints)))
+	r.mux.Handle("PUT /api/v1/notifications/endpoints/{channel}", authMiddleware(http.HandlerFunc(r.handlers.Notification.SetEndpoint)))
+	r.mux.Handle("DELETE /api/v1/notifications/endpoints/{channel}", authMiddleware(http.HandlerFunc(r.handlers.Notification.DeleteEndpoint)))
+	r.mux.Handle("PUT /api/v1/notifications/locale", authMiddleware(http.HandlerFunc(r.handlers.Notification.SetLocale)))
+
+	// KYC endpoints
+	r.mux.Handle("GET /api/v1/kyc/profile", authMiddleware(http.HandlerFunc(r.handlers.KYC.GetProfile)))
+	r.mux.Handle("PUT /api/v1/kyc/profile", authMiddleware(http.HandlerFunc(r.handlers.KYC.UpdateProfile)))
+	r.mux.Handle("POST /api/v1/kyc/documents", authFileMiddleware(http.HandlerFunc(r.handlers.KYC.UploadDocument)))
+	r.mux.Handle("GET /api/v1/kyc/documents", authMiddleware(http.HandlerFunc(r.handlers.KYC.ListDocuments)))
+	r.mux.Handle("POST /api/v1/kyc/submit", authMiddleware(http.HandlerFunc(r.handlers.KYC.Submit)))
+	r.mux.Handle("GET /api/v1/kyc/limits", authMiddleware(http.HandlerFunc(r.handlers.KYC.GetLimits)))
+
+	// Admin routes (аутентификация + роль admin)
+	adminBase := middleware.Chain(
+		middleware.LoggingMiddleware(),
+		middleware.AuthMiddleware(r.jwtKeys),
+		middleware.RateLimitMiddleware(r.limiter, "admin"),
+		middleware.RequireAdminMiddleware(r.users),
+	)
+	adminMiddleware := middleware.Chain(adminBase, defaultLimits)
+	adminFileMiddleware := middleware.Chain(adminBase, fileLimits)
+
+	// Audit endpoints
+	r.mux.Handle("GET /api/v1/admin/audit/events", adminMiddleware(http.HandlerFunc(r.handlers.Audit.ListEvents)))
+	r.mux.Handle("GET /api/v1/admin/audit/verify", adminMiddleware(http.HandlerFunc(r.handlers.Audit.VerifyChain)))
+
+	// Outbox endpoints
+	r.mux.Handle("GET /api/v1/admin/outbox", adminMiddleware(http.HandlerFunc(r.handlers.Outbox.ListMessages)))
+	r.mux.Handle("POST /api/v1/admin/outbox/{id}/retry", adminMiddleware(http.HandlerFunc(r.handlers.Outbox.RetryMessage)))
+
+	// Email template endpoints
+	r.mux.Handle("GET /api/v1/admin/templates/{name}/preview", adminMiddleware(http.HandlerFunc(r.handlers.Template.Preview)))
+
+	// Job endpoints
+	r.mux.Handle("GET /api/v1/admin/jobs", adminMiddleware(http.HandlerFunc(r.handlers.Job.ListJobs)))
+	r.mux.Handle("GET /api/v1/admin/jobs/{name}/runs", adminMiddleware(http.HandlerFunc(r.handlers.Job.ListRuns)))
+	r.mux.Handle("POST /api/v1/admin/jobs/{name}/trigger", adminMiddleware(http.HandlerFunc(r.handlers.Job.TriggerJob)))
+	r.mux.Handle("POST /api/v1/admin/jobs/{name}/pause", adminMiddleware(http.HandlerFunc(r.handlers.Job.PauseJob)))
+	r.mux.Handle("POST /api/v1/admin/jobs/{name}/resume", adminMiddleware(http.HandlerFunc(r.handlers.Job.ResumeJob)))
+
+	// Merchant endpoints
+	r.mux.Handle("POST /api/v1/admin/merchants", adminMiddleware(http.HandlerFunc(r.handlers.Merchant.CreateMerchant)))
+	r.mux.Handle("GET /api/v1/admin/merchants", adminMiddleware(http.HandlerFunc(r.handlers.Merchant.ListMerchants)))
+	r.mux.Handle("GET /api/v1/admin/merchants/{id}/settlements", adminMiddleware(http.HandlerFunc(r.handlers.Merchant.GetSettlements)))
+	r.mux.Handle("POST /api/v1/admin/merchants/{id}/terminals", adminMiddleware(http.HandlerFunc(r.handlers.Merchant.AddTerminal)))
+
+	// Card operator endpoints
+	r.mux.Handle("POST /api/v1/admin/cards/{id}/unblock", adminMiddleware(http.HandlerFunc(r.handlers.Card.UnblockCard)))
+	r.mux.Handle("POST /api/v1/admin/card-holds/{id}/capture", adminMiddleware(http.HandlerFunc(r.handlers.Card.CapturePayment)))
+	r.mux.Handle("POST /api/v1/admin/card-holds/{id}/void", adminMiddleware(http.HandlerFunc(r.handlers.Card.VoidAuthorization)))
+	r.mux.Handle("POST /api/v1/admin/card-holds/{id}/refund", adminMiddleware(http.HandlerFunc(r.handlers.Card.RefundPayment)))
+
+	// Cashback rule endpoints
+	r.mux.Handle("POST /api/v1/admin/cashback-rules", adminMiddleware(http.HandlerFunc(r.handlers.Cashback.CreateRule)))
+	r.mux.Handle("GET /api/v1/admin/cashback-rules", adminMiddleware(http.HandlerFunc(r.handlers.Cashback.ListRules)))
+	r.mux.Handle("DELETE /api/v1/admin/cashback-rules/{id}", adminMiddleware(http.HandlerFunc(r.handlers.Cashback.DeleteRule)))
+
+	// Fraud review endpoints
+	r.mux.Handle("GET /api/v1/admin/fraud/reviews", adminMiddleware(http.HandlerFunc(r.handlers.Fraud.ListReviews)))
+	r.mux.Handle("POST /api/v1/admin/fraud/reviews/{id}/approve", adminMiddleware(http.HandlerFunc(r.handlers.Fraud.ApproveReview)))
+	r.mux.Handle("POST /api/v1/admin/fraud/reviews/{id}/decline", adminMiddleware(http.HandlerFunc(r.handlers.Fraud.DeclineReview)))
+
+	// Sanctions screening endpoints
+	r.mux.Handle("GET /api/v1/admin/sanctions/lists", adminMiddleware(http.HandlerFunc(r.handlers.Sanctions.ListLists)))
+	r.mux.Handle("GET /api/v1/admin/sanctions/hits", adminMiddleware(http.HandlerFunc(r.handlers.Sanctions.ListHits)))
+	r.mux.Handle("POST /api/v1/admin/sanctions/hits/{id}/confirm", adminMiddleware(http.HandlerFunc(r.handlers.Sanctions.ConfirmHit)))
+	r.mux.Handle("POST /api/v1/admin/sanctions/hits/{id}/clear", adminMiddleware(http.HandlerFunc(r.handlers.Sanctions.ClearHit)))
+
+	// KYC review endpoints
+	r.mux.Handle("GET /api/v1/admin/kyc/profiles", adminMiddleware(http.HandlerFunc(r.handlers.KYC.ListProfiles)))
+	r.mux.Handle("POST /api/v1/admin/kyc/profiles/{userId}/verify", adminMiddleware(http.HandlerFunc(r.handlers.KYC.VerifyProfile)))
+	r.mux.Handle("POST /api/v1/admin/kyc/profiles/{userId}/reject", adminMiddleware(http.HandlerFunc(r.handlers.KYC.RejectProfile)))
+	r.mux.Handle("GET /api/v1/admin/kyc/documents/{id}/file", adminFileMiddleware(http.HandlerFunc(r.handlers.KYC.GetDocumentFile)))
 
 	// CBR endpoints (public)
-	r.mux.Handle("GET /api/v1/cbr/rate", commonMiddleware(http.HandlerFunc(r.handlers.CBR.GetCBRRate)))
+	r.mux.Handle("GET /api/v1/cbr/rate", cbrMiddleware(http.HandlerFunc(r.handlers.CBR.GetCBRRate)))
+
+	// JWKS endpoint (public) - публичные ключи для проверки JWT
+	r.mux.Handle("GET /.well-known/jwks.json", publicMiddleware(http.HandlerFunc(r.handlers.JWKS.GetJWKS)))
 
 	// Health check endpoint
 	r.mux.Handle("GET /health", commonMiddleware(http.HandlerFunc(r.healthCheck)))
@@ -123,5 +336,5 @@ func (r *Router) healthCheck(w http.ResponseWriter, req *http.Request) {
 
 // Handler возвращает основной HTTP handler
 func (r *Router) Handler() http.Handler {
-	return r.mux
+	return r.handler
 }
@@ -3,12 +3,14 @@ package service
 import (
 	"context"
 	"crypto/rand"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"log/slog"
 	"net/http"
 	"time"
 
+	"github.com/vterdunov/learn-bank-app/internal/config"
 	"github.com/vterdunov/learn-bank-app/internal/domain"
 	"github.com/vterdunov/learn-bank-app/internal/repository"
 )
@@ -18,28 +20,69 @@ var (
 	ErrAccountNotFound   = errors.New("account not found")
 	ErrInvalidAmount     = errors.New("invalid amount")
 	ErrAccountBlocked    = errors.New("account is blocked")
+	// ErrCardAccountMismatch карта выпущена к другому счету
+	ErrCardAccountMismatch = errors.New("card is not linked to the account")
+	// ErrHolderUserNotFound пользователь для добавления в совладельцы не найден по email
+	ErrHolderUserNotFound = errors.New("user with this email not found")
 )
 
 // accountService реализует интерфейс AccountService
 type accountService struct {
-	accountRepo     repository.AccountRepository
-	transactionRepo repository.TransactionRepository
-	accessControl   domain.AccessControlService
-	logger          *slog.Logger
+	accountRepo         repository.AccountRepository
+	transactionRepo     repository.TransactionRepository
+	cardRepo            repository.CardRepository
+	creditRepo          repository.CreditRepository
+	holderRepo          repository.AccountHolderRepository
+	userRepo            repository.UserRepository
+	accessControl       domain.AccessControlService
+	txManager           repository.TxManager
+	notificationService NotificationService
+	cardService         CardService
+	fraudService        FraudService
+	sanctionsService    SanctionsService
+	kycService          KYCService
+	auditService        AuditService
+	// overdraftProducts условия овердрафта по продуктам счетов
+	overdraftProducts map[string]config.OverdraftProductConfig
+	logger            *slog.Logger
 }
 
 // NewAccountService создает новый экземпляр сервиса счетов
 func NewAccountService(
+	cfg *config.Config,
 	accountRepo repository.AccountRepository,
 	transactionRepo repository.TransactionRepository,
+	cardRepo repository.CardRepository,
+	creditRepo repository.CreditRepository,
+	holderRepo repository.AccountHolderRepository,
+	userRepo repository.UserRepository,
 	accessControl domain.AccessControlService,
+	txManager repository.TxManager,
+	notificationService NotificationService,
+	cardService CardService,
+	fraudService FraudService,
+	sanctionsService SanctionsService,
+	kycService KYCService,
+	auditService AuditService,
 	logger *slog.Logger,
 ) AccountService {
 	return &accountService{
-		accountRepo:     accountRepo,
-		transactionRepo: transactionRepo,
-		accessControl:   accessControl,
-		logger:          logger,
+		accountRepo:         accountRepo,
+		transactionRepo:     transactionRepo,
+		cardRepo:            cardRepo,
+		creditRepo:          creditRepo,
+		holderRepo:          holderRepo,
+		userRepo:            userRepo,
+		accessControl:       accessControl,
+		txManager:           txManager,
+		notificationService: notificationService,
+		cardService:         cardService,
+		fraudService:        fraudService,
+		sanctionsService:    sanctionsService,
+		kycService:          kycService,
+		auditService:        auditService,
+		overdraftProducts:   cfg.Overdraft.Products,
+		logger:              logger,
 	}
 }
 
@@ -55,6 +98,13 @@ func (s *accountService) CreateAccount(ctx context.Context, userID int, req Crea
 		return nil, fmt.Errorf("unsupported currency: %s. Only RUB is supported", req.Currency)
 	}
 
+	if req.Product == "" {
+		req.Product = domain.AccountProductChecking
+	}
+	if !domain.IsValidAccountProduct(req.Product) {
+		return nil, domain.ErrInvalidAccountProduct
+	}
+
 	// Генерация номера счета
 	accountNumber := s.generateAccountNumber()
 
@@ -62,6 +112,7 @@ func (s *accountService) CreateAccount(ctx context.Context, userID int, req Crea
 	account := &domain.Account{
 		UserID:    userID,
 		Number:    accountNumber,
+		Product:   req.Product,
 		Balance:   0.0,
 		Currency:  req.Currency,
 		Status:    "active",
@@ -78,12 +129,13 @@ func (s *accountService) CreateAccount(ctx context.Context, userID int, req Crea
 		"account_id", account.ID,
 		"user_id", userID,
 		"number", account.Number,
+		"product", account.Product,
 		"currency", account.Currency)
 
 	return account, nil
 }
 
-// GetUserAccounts возвращает все счета пользователя
+// GetUserAccounts возвращает все счета пользователя: свои и те, где он совладелец
 func (s *accountService) GetUserAccounts(ctx context.Context, userID int) ([]*domain.Account, error) {
 	accounts, err := s.accountRepo.GetByUserID(ctx, userID)
 	if err != nil {
@@ -91,6 +143,13 @@ func (s *accountService) GetUserAccounts(ctx context.Context, userID int) ([]*do
 		return nil, fmt.Errorf("failed to get user accounts: %w", err)
 	}
 
+	shared, err := s.holderRepo.GetAccountsByUserID(ctx, userID)
+	if err != nil {
+		s.logger.Error("Failed to get shared accounts", "user_id", userID, "error", err)
+		return nil, fmt.Errorf("failed to get shared accounts: %w", err)
+	}
+	accounts = append(accounts, shared...)
+
 	s.logger.Debug("Retrieved user accounts", "user_id", userID, "count", len(accounts))
 	return accounts, nil
 }
@@ -125,35 +184,46 @@ func (s *accountService) DepositMoney(ctx context.Context, userID, accountID int
 		return ErrAccountBlocked
 	}
 
-	// 5. Пополнение баланса
-	newBalance := account.Balance + amount
-	if err := s.accountRepo.UpdateBalance(ctx, accountID, newBalance); err != nil {
-		s.logger.Error("Failed to update balance", "account_id", accountID, "error", err)
-		return fmt.Errorf("failed to update balance: %w", err)
-	}
+	// 5. Пополнение баланса, запись транзакции и уведомление владельца в одной транзакции БД
+	err = s.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		if err := s.accountRepo.Credit(ctx, accountID, amount); err != nil {
+			s.logger.Error("Failed to update balance", "account_id", accountID, "error", err)
+			return fmt.Errorf("failed to update balance: %w", err)
+		}
 
-	// 6. Создание записи о транзакции
-	transaction := &domain.Transaction{
-		FromAccount: nil, // Пополнение извне
-		ToAccount:   &accountID,
-		Amount:      amount,
-		Type:        "deposit",
-		Status:      "completed",
-		Description: "Account deposit",
-		CreatedAt:   time.Now(),
-		UpdatedAt:   time.Now(),
-	}
+		// 6. Создание записи о транзакции
+		transaction := &domain.Transaction{
+			FromAccount: nil, // Пополнение извне
+			ToAccount:   &accountID,
+			Amount:      amount,
+			Type:        "deposit",
+			Status:      "completed",
+			Description: "Account deposit",
+			CreatedAt:   time.Now(),
+			UpdatedAt:   time.Now(),
+		}
 
-	if err := s.transactionRepo.Create(ctx, transaction); err != nil {
-		s.logger.Error("Failed to create transaction record", "account_id", accountID, "amount", amount, "error", err)
-		// Не возвращаем ошибку, так как операция пополнения уже выполнена
+		if err := s.transactionRepo.Create(ctx, transaction); err != nil {
+			s.logger.Error("Failed to create transaction record", "account_id", accountID, "amount", amount, "error", err)
+			return fmt.Errorf("failed to create transaction record: %w", err)
+		}
+
+		// 7. Уведомление владельца счета
+		if err := s.notificationService.NotifyDeposit(ctx, account.UserID, accountID, amount); err != nil {
+			s.logger.Error("Failed to notify about deposit", "account_id", accountID, "error", err)
+			return fmt.Errorf("failed to notify about deposit: %w", err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return err
 	}
 
 	s.logger.Info("Account deposit successful",
 		"user_id", userID,
 		"account_id", accountID,
-		"amount", amount,
-		"new_balance", newBalance)
+		"amount", amount)
 
 	return nil
 }
@@ -175,6 +245,40 @@ func (s *accountService) WithdrawMoney(ctx context.Context, userID, accountID in
 		return ErrInvalidAmount
 	}
 
+	return s.withdraw(ctx, userID, accountID, amount, "Account withdrawal")
+}
+
+// WithdrawByCard выдает наличные по карте счета, как в банкомате: сначала проверяется PIN карты,
+// неверные PIN подряд блокируют карту. Списание выполняется так же, как WithdrawMoney.
+func (s *accountService) WithdrawByCard(ctx context.Context, userID, accountID, cardID int, pin string, amount float64) error {
+	if err := s.accessControl.CanAccessAccount(ctx, userID, accountID); err != nil {
+		s.logger.Warn("Access denied for card withdrawal", "user_id", userID, "account_id", accountID)
+		if domain.IsAccessDeniedError(err) {
+			return &ServiceError{Code: http.StatusForbidden, Message: err.Error()}
+		}
+		return err
+	}
+
+	if amount <= 0 {
+		s.logger.Warn("Invalid withdrawal amount", "account_id", accountID, "amount", amount)
+		return ErrInvalidAmount
+	}
+
+	card, err := s.cardService.VerifyPIN(ctx, userID, cardID, pin)
+	if err != nil {
+		s.logger.Warn("Card withdrawal PIN verification failed", "user_id", userID, "card_id", cardID, "error", err)
+		return err
+	}
+	if card.AccountID != accountID {
+		s.logger.Warn("Card is not linked to the account", "card_id", cardID, "account_id", accountID)
+		return ErrCardAccountMismatch
+	}
+
+	return s.withdraw(ctx, userID, accountID, amount, fmt.Sprintf("Cash withdrawal by card #%d", cardID))
+}
+
+// withdraw списывает средства со счета во внешнюю систему после проверки прав и суммы
+func (s *accountService) withdraw(ctx context.Context, userID, accountID int, amount float64, description string) error {
 	// 3. Получение счета
 	account, err := s.accountRepo.GetByID(ctx, accountID)
 	if err != nil {
@@ -189,43 +293,69 @@ func (s *accountService) WithdrawMoney(ctx context.Context, userID, accountID in
 	}
 
 	// 5. Проверка достаточности средств
-	if account.Balance < amount {
+	if account.AvailableBalance() < amount {
 		s.logger.Warn("Insufficient funds",
 			"account_id", accountID,
-			"balance", account.Balance,
+			"available", account.AvailableBalance(),
 			"requested", amount)
 		return ErrInsufficientFunds
 	}
 
-	// 6. Списание средств
-	newBalance := account.Balance - amount
-	if err := s.accountRepo.UpdateBalance(ctx, accountID, newBalance); err != nil {
-		s.logger.Error("Failed to update balance", "account_id", accountID, "error", err)
-		return fmt.Errorf("failed to update balance: %w", err)
+	// Лимиты операций по уровню идентификации клиента
+	if err := s.kycService.CheckOperationLimit(ctx, userID, amount); err != nil {
+		return err
 	}
 
-	// 7. Создание записи о транзакции
-	transaction := &domain.Transaction{
-		FromAccount: &accountID,
-		ToAccount:   nil, // Списание во внешнюю систему
-		Amount:      amount,
-		Type:        "withdrawal",
-		Status:      "completed",
-		Description: "Account withdrawal",
-		CreatedAt:   time.Now(),
-		UpdatedAt:   time.Now(),
-	}
+	// Антифрод, списание и запись транзакции в одной транзакции БД:
+	// одобрение оператора погашается, только если снятие выполнено
+	err = s.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		// Проверка правилами антифрода: снятие может быть отклонено или отложено до решения оператора
+		if err := s.fraudService.Screen(ctx, &domain.FraudOperation{
+			Type:      domain.FraudOperationWithdrawal,
+			UserID:    userID,
+			AccountID: accountID,
+			Amount:    amount,
+		}); err != nil {
+			return err
+		}
 
-	if err := s.transactionRepo.Create(ctx, transaction); err != nil {
-		s.logger.Error("Failed to create transaction record", "account_id", accountID, "amount", amount, "error", err)
-		// Не возвращаем ошибку, так как операция списания уже выполнена
+		// 6. Списание средств: доступный остаток перепроверяется в том же запросе
+		if err := s.accountRepo.Debit(ctx, accountID, amount); err != nil {
+			if errors.Is(err, domain.ErrAccountInsufficientFunds) {
+				s.logger.Warn("Insufficient funds", "account_id", accountID, "requested", amount)
+				return ErrInsufficientFunds
+			}
+			s.logger.Error("Failed to update balance", "account_id", accountID, "error", err)
+			return fmt.Errorf("failed to update balance: %w", err)
+		}
+
+		// 7. Создание записи о транзакции
+		transaction := &domain.Transaction{
+			FromAccount: &accountID,
+			ToAccount:   nil, // Списание во внешнюю систему
+			Amount:      amount,
+			Type:        "withdrawal",
+			Status:      "completed",
+			Description: description,
+			CreatedAt:   time.Now(),
+			UpdatedAt:   time.Now(),
+		}
+
+		if err := s.transactionRepo.Create(ctx, transaction); err != nil {
+			s.logger.Error("Failed to create transaction record", "account_id", accountID, "amount", amount, "error", err)
+			return fmt.Errorf("failed to create transaction record: %w", err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return err
 	}
 
 	s.logger.Info("Account withdrawal successful",
 		"user_id", userID,
 		"account_id", accountID,
-		"amount", amount,
-		"new_balance", newBalance)
+		"amount", amount)
 
 	return nil
 }
@@ -253,17 +383,80 @@ func (s *accountService) TransferMoney(ctx context.Context, userID, fromAccountI
 		return fmt.Errorf("cannot transfer to the same account")
 	}
 
-	// 4. Выполнение перевода через репозиторий (транзакция)
-	if err := s.accountRepo.Transfer(ctx, fromAccountID, toAccountID, amount); err != nil {
-		s.logger.Error("Transfer failed",
+	// 4. Проверка статуса и баланса исходящего счета
+	fromAccount, err := s.accountRepo.GetByID(ctx, fromAccountID)
+	if err != nil {
+		s.logger.Error("Account not found for transfer", "from_account_id", fromAccountID, "error", err)
+		return ErrAccountNotFound
+	}
+	if fromAccount.Status != "active" {
+		s.logger.Warn("Account is not active", "account_id", fromAccountID, "status", fromAccount.Status)
+		return ErrAccountBlocked
+	}
+	if fromAccount.AvailableBalance() < amount {
+		s.logger.Warn("Insufficient funds for transfer",
 			"from_account_id", fromAccountID,
-			"to_account_id", toAccountID,
-			"amount", amount,
-			"error", err)
-		return fmt.Errorf("transfer failed: %w", err)
+			"available", fromAccount.AvailableBalance(),
+			"requested", amount)
+		return ErrInsufficientFunds
+	}
+
+	// Закрытый счет не принимает зачисления
+	toAccount, err := s.accountRepo.GetByID(ctx, toAccountID)
+	if err != nil {
+		s.logger.Error("Account not found for transfer", "to_account_id", toAccountID, "error", err)
+		return ErrAccountNotFound
+	}
+	if toAccount.Status == domain.AccountStatusClosed {
+		s.logger.Warn("Transfer to closed account", "to_account_id", toAccountID)
+		return domain.ErrAccountClosed
+	}
+
+	// Получатель-другой клиент проверяется по санкционным спискам; перевод другому клиенту
+	// учитывается в лимитах по уровню идентификации, переводы между своими счетами — нет
+	if toAccount.UserID != userID {
+		if err := s.sanctionsService.CheckCounterparty(ctx, toAccount.UserID); err != nil {
+			return err
+		}
+		if err := s.kycService.CheckOperationLimit(ctx, userID, amount); err != nil {
+			return err
+		}
+	}
+
+	// Состояние счетов до перевода для аудита
+	before := s.transferAuditState(ctx, fromAccountID, toAccountID)
+
+	// Антифрод и перевод в одной транзакции БД: одобрение оператора погашается, только если перевод выполнен
+	err = s.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		// Проверка правилами антифрода: перевод может быть отклонен или отложен до решения оператора
+		if err := s.fraudService.Screen(ctx, &domain.FraudOperation{
+			Type:        domain.FraudOperationTransfer,
+			UserID:      userID,
+			AccountID:   fromAccountID,
+			ToAccountID: &toAccountID,
+			ToUserID:    &toAccount.UserID,
+			Amount:      amount,
+		}); err != nil {
+			return err
+		}
+
+		// 5. Выполнение перевода через репозиторий (savepoint внутри транзакции)
+		if err := s.accountRepo.Transfer(ctx, fromAccountID, toAccountID, amount); err != nil {
+			s.logger.Error("Transfer failed",
+				"from_account_id", fromAccountID,
+				"to_account_id", toAccountID,
+				"amount", amount,
+				"error", err)
+			return fmt.Errorf("transfer failed: %w", err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return err
 	}
 
-	// 5. Создание записи о транзакции
+	// 6. Создание записи о транзакции
 	transaction := &domain.Transaction{
 		FromAccount: &fromAccountID,
 		ToAccount:   &toAccountID,
@@ -286,9 +479,389 @@ func (s *accountService) TransferMoney(ctx context.Context, userID, fromAccountI
 		"to_account_id", toAccountID,
 		"amount", amount)
 
+	// 7. Запись в журнал аудита
+	event := NewUserAuditEvent(userID, domain.AuditActionTransfer, "account", auditResourceID(fromAccountID))
+	event.Before = before
+	event.After = s.transferAuditState(ctx, fromAccountID, toAccountID)
+	event.Metadata = domain.NewAuditState(map[string]interface{}{
+		"to_account_id":  toAccountID,
+		"amount":         amount,
+		"transaction_id": transaction.ID,
+	})
+	// Ошибка аудита уже залогирована, перевод выполнен
+	_ = s.auditService.Record(ctx, event)
+
+	return nil
+}
+
+// FreezeAccount замораживает счет: пока счет заморожен, списания, пополнения и платежи картами отклоняются
+func (s *accountService) FreezeAccount(ctx context.Context, userID, accountID int) (*domain.Account, error) {
+	return s.changeFreezeStatus(ctx, userID, accountID, true)
+}
+
+// UnfreezeAccount снимает заморозку счета
+func (s *accountService) UnfreezeAccount(ctx context.Context, userID, accountID int) (*domain.Account, error) {
+	return s.changeFreezeStatus(ctx, userID, accountID, false)
+}
+
+func (s *accountService) changeFreezeStatus(ctx context.Context, userID, accountID int, freeze bool) (*domain.Account, error) {
+	if err := s.checkPermission(ctx, userID, accountID, domain.AccountPermissionManage); err != nil {
+		return nil, err
+	}
+
+	// Статус меняется под блокировкой строки и без записи остатка, чтобы не затереть параллельные движения
+	var account *domain.Account
+	var before string
+	action := domain.AuditActionAccountFreeze
+	err := s.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		var err error
+		account, err = s.accountRepo.GetByIDForUpdate(ctx, accountID)
+		if err != nil {
+			return ErrAccountNotFound
+		}
+
+		before = account.Status
+		switch {
+		case account.Status == domain.AccountStatusClosed:
+			return domain.ErrAccountClosed
+		case freeze && account.Status != domain.AccountStatusActive:
+			return domain.ErrAccountNotActive
+		case freeze:
+			account.Status = domain.AccountStatusBlocked
+		case account.Status != domain.AccountStatusBlocked:
+			return domain.ErrAccountNotFrozen
+		default:
+			account.Status = domain.AccountStatusActive
+			action = domain.AuditActionAccountUnfreeze
+		}
+
+		if err := s.accountRepo.UpdateStatus(ctx, accountID, account.Status); err != nil {
+			s.logger.Error("Failed to update account status", "account_id", accountID, "error", err)
+			return fmt.Errorf("failed to update account status: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("Account status changed", "user_id", userID, "account_id", accountID, "status", account.Status)
+
+	event := NewUserAuditEvent(userID, action, "account", auditResourceID(accountID))
+	event.Before = domain.NewAuditState(map[string]interface{}{"status": before})
+	event.After = domain.NewAuditState(map[string]interface{}{"status": account.Status})
+	// Ошибка аудита уже залогирована, статус счета изменен
+	_ = s.auditService.Record(ctx, event)
+
+	return account, nil
+}
+
+// CloseAccount закрывает счет владельцем. Счет без холдов и действующих кредитов; положительный остаток
+// переводится на residualAccountID, карты счета аннулируются. Закрытый счет остается доступен для просмотра.
+func (s *accountService) CloseAccount(ctx context.Context, userID, accountID, residualAccountID int) (*domain.Account, error) {
+	if err := s.checkPermission(ctx, userID, accountID, domain.AccountPermissionOwner); err != nil {
+		return nil, err
+	}
+
+	var closed *domain.Account
+	var residual float64
+	err := s.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		// Блокировка строки: пока проверяются холды и остаток, на счет ничего не поступит и не спишется
+		account, err := s.accountRepo.GetByIDForUpdate(ctx, accountID)
+		if err != nil {
+			return ErrAccountNotFound
+		}
+
+		if err := s.checkClosable(ctx, account); err != nil {
+			return err
+		}
+
+		residual = account.Balance
+		if residual > 0 {
+			if err := s.moveResidualBalance(ctx, userID, account, residualAccountID); err != nil {
+				return err
+			}
+		}
+
+		cards, err := s.cardRepo.GetByAccountID(ctx, accountID)
+		if err != nil {
+			return fmt.Errorf("failed to get account cards: %w", err)
+		}
+		for _, card := range cards {
+			if card.Status == domain.CardStatusCancelled {
+				continue
+			}
+			if err := s.cardRepo.UpdateStatus(ctx, card.ID, domain.CardStatusCancelled); err != nil {
+				return fmt.Errorf("failed to cancel card %d: %w", card.ID, err)
+			}
+		}
+
+		// Счет перечитывается: остаток уже переведен
+		closed, err = s.accountRepo.GetByID(ctx, accountID)
+		if err != nil {
+			return ErrAccountNotFound
+		}
+		closed.Status = domain.AccountStatusClosed
+		if err := s.accountRepo.UpdateStatus(ctx, accountID, closed.Status); err != nil {
+			return fmt.Errorf("failed to close account: %w", err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		s.logger.Warn("Failed to close account", "user_id", userID, "account_id", accountID, "error", err)
+		return nil, err
+	}
+
+	s.logger.Info("Account closed", "user_id", userID, "account_id", accountID, "residual", residual)
+
+	event := NewUserAuditEvent(userID, domain.AuditActionAccountClose, "account", auditResourceID(accountID))
+	event.After = domain.NewAuditState(map[string]interface{}{"status": domain.AccountStatusClosed})
+	event.Metadata = domain.NewAuditState(map[string]interface{}{
+		"residual_amount":     residual,
+		"residual_account_id": residualAccountID,
+	})
+	// Ошибка аудита уже залогирована, счет закрыт
+	_ = s.auditService.Record(ctx, event)
+
+	return closed, nil
+}
+
+// checkClosable проверяет, что счет можно закрыть: он не закрыт, без холдов, отрицательного остатка и кредитов
+func (s *accountService) checkClosable(ctx context.Context, account *domain.Account) error {
+	if account.Status == domain.AccountStatusClosed {
+		return domain.ErrAccountClosed
+	}
+	if account.HeldAmount > 0 {
+		return domain.ErrAccountHasHolds
+	}
+	if account.Balance < 0 {
+		return domain.ErrAccountNegativeBalance
+	}
+
+	credits, err := s.creditRepo.GetByAccountID(ctx, account.ID)
+	if err != nil {
+		return fmt.Errorf("failed to get account credits: %w", err)
+	}
+	for _, credit := range credits {
+		if credit.Status == domain.CreditStatusActive || credit.Status == domain.CreditStatusOverdue {
+			return domain.ErrAccountHasCredits
+		}
+	}
+
+	return nil
+}
+
+// moveResidualBalance переводит остаток закрываемого счета на другой активный счет пользователя
+func (s *accountService) moveResidualBalance(ctx context.Context, userID int, account *domain.Account, residualAccountID int) error {
+	if residualAccountID == 0 {
+		return domain.ErrResidualAccountRequired
+	}
+	if residualAccountID == account.ID {
+		return domain.ErrInvalidResidualAccount
+	}
+	if err := s.checkPermission(ctx, userID, residualAccountID, domain.AccountPermissionOperate); err != nil {
+		return err
+	}
+
+	target, err := s.accountRepo.GetByID(ctx, residualAccountID)
+	if err != nil {
+		return ErrAccountNotFound
+	}
+	if target.Status != domain.AccountStatusActive {
+		return domain.ErrInvalidResidualAccount
+	}
+
+	if err := s.accountRepo.Transfer(ctx, account.ID, residualAccountID, account.Balance); err != nil {
+		return fmt.Errorf("failed to move residual balance: %w", err)
+	}
+
+	now := time.Now()
+	transaction := &domain.Transaction{
+		FromAccount: &account.ID,
+		ToAccount:   &residualAccountID,
+		Amount:      account.Balance,
+		Type:        "transfer",
+		Status:      "completed",
+		Description: "Residual balance on account closure",
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+	if err := s.transactionRepo.Create(ctx, transaction); err != nil {
+		return fmt.Errorf("failed to create transaction record: %w", err)
+	}
+
+	return nil
+}
+
+// SetOverdraftLimit устанавливает лимит овердрафта счета в пределах условий продукта; 0 отключает овердрафт.
+// Лимит нельзя снизить ниже уже использованного овердрафта с учетом холдов.
+func (s *accountService) SetOverdraftLimit(ctx context.Context, userID, accountID int, limit float64) (*domain.Account, error) {
+	if err := s.checkPermission(ctx, userID, accountID, domain.AccountPermissionOwner); err != nil {
+		return nil, err
+	}
+	if limit < 0 {
+		return nil, domain.ErrInvalidOverdraftLimit
+	}
+
+	account, err := s.accountRepo.GetByID(ctx, accountID)
+	if err != nil {
+		return nil, ErrAccountNotFound
+	}
+	if account.Status == domain.AccountStatusClosed {
+		return nil, domain.ErrAccountClosed
+	}
+
+	terms := s.overdraftProducts[account.Product]
+	if limit > 0 && terms.MaxLimit <= 0 {
+		return nil, domain.ErrOverdraftNotAvailable
+	}
+	if limit > terms.MaxLimit {
+		return nil, domain.ErrOverdraftLimitExceeded
+	}
+
+	before := account.OverdraftLimit
+	if err := s.accountRepo.UpdateOverdraftLimit(ctx, accountID, limit); err != nil {
+		if errors.Is(err, domain.ErrOverdraftLimitBelowDebt) {
+			return nil, err
+		}
+		s.logger.Error("Failed to update overdraft limit", "account_id", accountID, "error", err)
+		return nil, fmt.Errorf("failed to update overdraft limit: %w", err)
+	}
+	account.OverdraftLimit = limit
+
+	s.logger.Info("Overdraft limit set", "user_id", userID, "account_id", accountID, "limit", limit)
+
+	event := NewUserAuditEvent(userID, domain.AuditActionAccountOverdraftSet, "account", auditResourceID(accountID))
+	event.Before = domain.NewAuditState(map[string]interface{}{"overdraft_limit": before})
+	event.After = domain.NewAuditState(map[string]interface{}{
+		"overdraft_limit": limit,
+		"annual_rate":     terms.AnnualRate,
+	})
+	// Ошибка аудита уже залогирована, лимит установлен
+	_ = s.auditService.Record(ctx, event)
+
+	return account, nil
+}
+
+// GetAccountHolders возвращает совладельцев счета
+func (s *accountService) GetAccountHolders(ctx context.Context, userID, accountID int) ([]*domain.AccountHolder, error) {
+	if err := s.checkPermission(ctx, userID, accountID, domain.AccountPermissionView); err != nil {
+		return nil, err
+	}
+
+	holders, err := s.holderRepo.GetByAccountID(ctx, accountID)
+	if err != nil {
+		s.logger.Error("Failed to get account holders", "account_id", accountID, "error", err)
+		return nil, fmt.Errorf("failed to get account holders: %w", err)
+	}
+
+	return holders, nil
+}
+
+// AddAccountHolder добавляет совладельца счета по email; добавлять совладельцев может только владелец
+func (s *accountService) AddAccountHolder(ctx context.Context, userID, accountID int, req domain.AddAccountHolderRequest) (*domain.AccountHolder, error) {
+	if err := s.checkPermission(ctx, userID, accountID, domain.AccountPermissionOwner); err != nil {
+		return nil, err
+	}
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+
+	account, err := s.accountRepo.GetByID(ctx, accountID)
+	if err != nil {
+		return nil, ErrAccountNotFound
+	}
+	if account.Status == domain.AccountStatusClosed {
+		return nil, domain.ErrAccountClosed
+	}
+
+	user, err := s.userRepo.GetByEmail(ctx, req.Email)
+	if err != nil {
+		return nil, ErrHolderUserNotFound
+	}
+	if user.ID == account.UserID {
+		return nil, domain.ErrAccountHolderIsOwner
+	}
+
+	holder := &domain.AccountHolder{
+		AccountID:   accountID,
+		UserID:      user.ID,
+		Role:        req.Role,
+		Permissions: req.Permissions,
+	}
+	if err := s.holderRepo.Create(ctx, holder); err != nil {
+		if errors.Is(err, domain.ErrAccountHolderExists) {
+			return nil, err
+		}
+		s.logger.Error("Failed to add account holder", "account_id", accountID, "error", err)
+		return nil, fmt.Errorf("failed to add account holder: %w", err)
+	}
+
+	s.logger.Info("Account holder added", "account_id", accountID, "holder_user_id", user.ID, "role", holder.Role)
+
+	event := NewUserAuditEvent(userID, domain.AuditActionAccountHolderAdd, "account", auditResourceID(accountID))
+	event.After = domain.NewAuditState(map[string]interface{}{
+		"holder_user_id": user.ID,
+		"role":           holder.Role,
+		"permissions":    holder.Permissions,
+	})
+	// Ошибка аудита уже залогирована, совладелец добавлен
+	_ = s.auditService.Record(ctx, event)
+
+	return holder, nil
+}
+
+// RemoveAccountHolder удаляет совладельца: владелец удаляет любого совладельца, совладелец — только себя
+func (s *accountService) RemoveAccountHolder(ctx context.Context, userID, accountID, holderUserID int) error {
+	if userID != holderUserID {
+		if err := s.checkPermission(ctx, userID, accountID, domain.AccountPermissionOwner); err != nil {
+			return err
+		}
+	}
+
+	if err := s.holderRepo.Delete(ctx, accountID, holderUserID); err != nil {
+		if errors.Is(err, domain.ErrAccountHolderNotFound) {
+			return err
+		}
+		s.logger.Error("Failed to remove account holder", "account_id", accountID, "error", err)
+		return fmt.Errorf("failed to remove account holder: %w", err)
+	}
+
+	s.logger.Info("Account holder removed", "account_id", accountID, "holder_user_id", holderUserID)
+
+	event := NewUserAuditEvent(userID, domain.AuditActionAccountHolderRemove, "account", auditResourceID(accountID))
+	event.Before = domain.NewAuditState(map[string]interface{}{"holder_user_id": holderUserID})
+	// Ошибка аудита уже залогирована, совладелец удален
+	_ = s.auditService.Record(ctx, event)
+
+	return nil
+}
+
+// checkPermission проверяет право пользователя на счет; чужой счет — 403, несуществующий — ErrAccountNotFound
+func (s *accountService) checkPermission(ctx context.Context, userID, accountID int, permission string) error {
+	if err := s.accessControl.CheckAccountPermission(ctx, userID, accountID, permission); err != nil {
+		s.logger.Warn("Access denied for account", "user_id", userID, "account_id", accountID, "permission", permission)
+		if domain.IsAccessDeniedError(err) {
+			return &ServiceError{Code: http.StatusForbidden, Message: err.Error()}
+		}
+		return ErrAccountNotFound
+	}
 	return nil
 }
 
+// transferAuditState возвращает балансы счетов перевода для аудита
+func (s *accountService) transferAuditState(ctx context.Context, fromAccountID, toAccountID int) json.RawMessage {
+	state := make(map[string]interface{}, 2)
+	if balance, err := s.accountRepo.GetBalance(ctx, fromAccountID); err == nil {
+		state["from_balance"] = balance
+	}
+	if balance, err := s.accountRepo.GetBalance(ctx, toAccountID); err == nil {
+		state["to_balance"] = balance
+	}
+	return domain.NewAuditState(state)
+}
+
 // generateAccountNumber генерирует уникальный номер счета
 func (s *accountService) generateAccountNumber() string {
 	// Генерируем 16-значный номер счета
@@ -0,0 +1,401 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"os"
+	"testing"
+
+	"github.com/vterdunov/learn-bank-app/internal/config"
+	"github.com/vterdunov/learn-bank-app/internal/domain"
+)
+
+// MockCreditStore кредиты в памяти; используется только для проверок при закрытии счета
+type MockCreditStore struct {
+	credits map[int]*domain.Credit
+}
+
+func (m *MockCreditStore) Create(ctx context.Context, credit *domain.Credit) error {
+	m.credits[credit.ID] = credit
+	return nil
+}
+
+func (m *MockCreditStore) GetByID(ctx context.Context, id int) (*domain.Credit, error) {
+	credit, ok := m.credits[id]
+	if !ok {
+		return nil, errors.New("credit not found")
+	}
+	return credit, nil
+}
+
+func (m *MockCreditStore) GetByUserID(ctx context.Context, userID int) ([]*domain.Credit, error) {
+	var credits []*domain.Credit
+	for _, credit := range m.credits {
+		if credit.UserID == userID {
+			credits = append(credits, credit)
+		}
+	}
+	return credits, nil
+}
+
+func (m *MockCreditStore) GetByAccountID(ctx context.Context, accountID int) ([]*domain.Credit, error) {
+	var credits []*domain.Credit
+	for _, credit := range m.credits {
+		if credit.AccountID == accountID {
+			credits = append(credits, credit)
+		}
+	}
+	return credits, nil
+}
+
+func (m *MockCreditStore) Update(ctx context.Context, credit *domain.Credit) error {
+	m.credits[credit.ID] = credit
+	return nil
+}
+
+func (m *MockCreditStore) Delete(ctx context.Context, id int) error {
+	delete(m.credits, id)
+	return nil
+}
+
+func (m *MockCreditStore) UpdateRemainingDebt(ctx context.Context, id int, remainingDebt float64) error {
+	m.credits[id].RemainingDebt = remainingDebt
+	return nil
+}
+
+func (m *MockCreditStore) GetActiveCredits(ctx context.Context) ([]*domain.Credit, error) {
+	return nil, nil
+}
+
+func (m *MockCreditStore) GetCreditAnalytics(ctx context.Context, userID int) (*domain.CreditAnalytics, error) {
+	return &domain.CreditAnalytics{}, nil
+}
+
+// MockAccountHolderRepository совладельцы счетов в памяти
+type MockAccountHolderRepository struct {
+	accounts *MockAccountStore
+	holders  []*domain.AccountHolder
+}
+
+func (m *MockAccountHolderRepository) Create(ctx context.Context, holder *domain.AccountHolder) error {
+	if _, err := m.Get(ctx, holder.AccountID, holder.UserID); err == nil {
+		return domain.ErrAccountHolderExists
+	}
+	holder.ID = len(m.holders) + 1
+	m.holders = append(m.holders, holder)
+	return nil
+}
+
+func (m *MockAccountHolderRepository) Get(ctx context.Context, accountID, userID int) (*domain.AccountHolder, error) {
+	for _, holder := range m.holders {
+		if holder.AccountID == accountID && holder.UserID == userID {
+			return holder, nil
+		}
+	}
+	return nil, domain.ErrAccountHolderNotFound
+}
+
+func (m *MockAccountHolderRepository) GetByAccountID(ctx context.Context, accountID int) ([]*domain.AccountHolder, error) {
+	var holders []*domain.AccountHolder
+	for _, holder := range m.holders {
+		if holder.AccountID == accountID {
+			holders = append(holders, holder)
+		}
+	}
+	return holders, nil
+}
+
+func (m *MockAccountHolderRepository) GetAccountsByUserID(ctx context.Context, userID int) ([]*domain.Account, error) {
+	var accounts []*domain.Account
+	for _, holder := range m.holders {
+		if holder.UserID == userID {
+			account, _ := m.accounts.GetByID(ctx, holder.AccountID)
+			accounts = append(accounts, account)
+		}
+	}
+	return accounts, nil
+}
+
+func (m *MockAccountHolderRepository) Delete(ctx context.Context, accountID, userID int) error {
+	for i, holder := range m.holders {
+		if holder.AccountID == accountID && holder.UserID == userID {
+			m.holders = append(m.holders[:i], m.holders[i+1:]...)
+			return nil
+		}
+	}
+	return domain.ErrAccountHolderNotFound
+}
+
+type accountLifecycleTestDeps struct {
+	accounts     *MockAccountStore
+	cards        *MockCardRepository
+	credits      *MockCreditStore
+	holders      *MockAccountHolderRepository
+	transactions *MockTransactionRepository
+	users        *MockUserRepository
+	owner        *domain.User
+}
+
+// setupAccountLifecycleService собирает сервис счетов с доменным контролем доступа:
+// счета 1 (checking) и 2 (savings, без овердрафта) принадлежат владельцу, счет 3 — другому пользователю
+func setupAccountLifecycleService(t *testing.T) (AccountService, *accountLifecycleTestDeps) {
+	t.Helper()
+
+	ctx := context.Background()
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	auditService, _ := setupAuditService()
+
+	users := NewMockUserRepository()
+	owner := &domain.User{Username: "owner", Email: "owner@example.com"}
+	other := &domain.User{Username: "other", Email: "other@example.com"}
+	if err := users.Create(ctx, owner); err != nil {
+		t.Fatalf("failed to create user: %v", err)
+	}
+	if err := users.Create(ctx, other); err != nil {
+		t.Fatalf("failed to create user: %v", err)
+	}
+
+	accounts := &MockAccountStore{accounts: map[int]*domain.Account{
+		1: {ID: 1, UserID: owner.ID, Product: domain.AccountProductChecking, Balance: 500, Status: domain.AccountStatusActive},
+		2: {ID: 2, UserID: owner.ID, Product: domain.AccountProductSavings, Balance: 100, Status: domain.AccountStatusActive},
+		3: {ID: 3, UserID: other.ID, Product: domain.AccountProductChecking, Balance: 100, Status: domain.AccountStatusActive},
+	}}
+	cards := &MockCardRepository{cards: map[int]*domain.Card{
+		1: {ID: 1, AccountID: 1, Status: domain.CardStatusActive},
+		2: {ID: 2, AccountID: 1, Status: domain.CardStatusBlocked},
+	}}
+	credits := &MockCreditStore{credits: map[int]*domain.Credit{}}
+	holders := &MockAccountHolderRepository{accounts: accounts}
+	transactions := &MockTransactionRepository{accounts: accounts}
+	accessControl := domain.NewAccessControlDomain(accounts, cards, credits, holders)
+
+	cfg := &config.Config{Overdraft: config.OverdraftConfig{Products: map[string]config.OverdraftProductConfig{
+		domain.AccountProductChecking: {MaxLimit: 10000, AnnualRate: 36.5},
+	}}}
+	svc := NewAccountService(cfg, accounts, transactions, cards, credits, holders, users, accessControl,
+		mockTxManager{}, nil, nil, mockFraudService{}, mockSanctionsService{}, mockKYCService{}, auditService, logger)
+
+	return svc, &accountLifecycleTestDeps{
+		accounts:     accounts,
+		cards:        cards,
+		credits:      credits,
+		holders:      holders,
+		transactions: transactions,
+		users:        users,
+		owner:        owner,
+	}
+}
+
+func TestAccountService_FreezeAndUnfreeze(t *testing.T) {
+	svc, deps := setupAccountLifecycleService(t)
+	ctx := context.Background()
+
+	if _, err := svc.UnfreezeAccount(ctx, deps.owner.ID, 1); !errors.Is(err, domain.ErrAccountNotFrozen) {
+		t.Errorf("expected ErrAccountNotFrozen, got %v", err)
+	}
+
+	account, err := svc.FreezeAccount(ctx, deps.owner.ID, 1)
+	if err != nil {
+		t.Fatalf("FreezeAccount failed: %v", err)
+	}
+	if account.Status != domain.AccountStatusBlocked {
+		t.Errorf("expected blocked status, got %s", account.Status)
+	}
+	// Заморозка меняет только статус: остаток в хранилище не перезаписывается
+	if stored := deps.accounts.accounts[1]; stored.Status != domain.AccountStatusBlocked || stored.Balance != 500 {
+		t.Errorf("unexpected stored account after freeze: %+v", stored)
+	}
+
+	// Замороженный счет не принимает списания
+	if err := svc.WithdrawMoney(ctx, deps.owner.ID, 1, 10); err == nil {
+		t.Error("withdrawal from frozen account must fail")
+	}
+	if _, err := svc.FreezeAccount(ctx, deps.owner.ID, 1); !errors.Is(err, domain.ErrAccountNotActive) {
+		t.Errorf("expected ErrAccountNotActive, got %v", err)
+	}
+
+	account, err = svc.UnfreezeAccount(ctx, deps.owner.ID, 1)
+	if err != nil {
+		t.Fatalf("UnfreezeAccount failed: %v", err)
+	}
+	if account.Status != domain.AccountStatusActive {
+		t.Errorf("expected active status, got %s", account.Status)
+	}
+
+	// Чужой счет заморозить нельзя
+	_, err = svc.FreezeAccount(ctx, deps.owner.ID, 3)
+	var serviceErr *ServiceError
+	if !errors.As(err, &serviceErr) || serviceErr.Code != http.StatusForbidden {
+		t.Errorf("expected 403 for foreign account, got %v", err)
+	}
+}
+
+func TestAccountService_CloseAccount(t *testing.T) {
+	svc, deps := setupAccountLifecycleService(t)
+	ctx := context.Background()
+
+	// Остаток нужно куда-то перевести
+	if _, err := svc.CloseAccount(ctx, deps.owner.ID, 1, 0); !errors.Is(err, domain.ErrResidualAccountRequired) {
+		t.Errorf("expected ErrResidualAccountRequired, got %v", err)
+	}
+	if _, err := svc.CloseAccount(ctx, deps.owner.ID, 1, 1); !errors.Is(err, domain.ErrInvalidResidualAccount) {
+		t.Errorf("expected ErrInvalidResidualAccount, got %v", err)
+	}
+
+	// Холды и действующие кредиты блокируют закрытие
+	deps.accounts.accounts[1].HeldAmount = 50
+	if _, err := svc.CloseAccount(ctx, deps.owner.ID, 1, 2); !errors.Is(err, domain.ErrAccountHasHolds) {
+		t.Errorf("expected ErrAccountHasHolds, got %v", err)
+	}
+	deps.accounts.accounts[1].HeldAmount = 0
+
+	deps.credits.credits[1] = &domain.Credit{ID: 1, UserID: deps.owner.ID, AccountID: 1, Status: domain.CreditStatusOverdue}
+	if _, err := svc.CloseAccount(ctx, deps.owner.ID, 1, 2); !errors.Is(err, domain.ErrAccountHasCredits) {
+		t.Errorf("expected ErrAccountHasCredits, got %v", err)
+	}
+	deps.credits.credits[1].Status = domain.CreditStatusPaidOff
+
+	account, err := svc.CloseAccount(ctx, deps.owner.ID, 1, 2)
+	if err != nil {
+		t.Fatalf("CloseAccount failed: %v", err)
+	}
+	if account.Status != domain.AccountStatusClosed || account.Balance != 0 {
+		t.Errorf("expected closed account with zero balance, got %s/%.2f", account.Status, account.Balance)
+	}
+	if balance := deps.accounts.accounts[2].Balance; balance != 600 {
+		t.Errorf("expected residual balance moved to account 2, got %.2f", balance)
+	}
+	if len(deps.transactions.transactions) != 1 {
+		t.Errorf("expected residual transfer transaction, got %d", len(deps.transactions.transactions))
+	}
+	for _, card := range deps.cards.cards {
+		if card.Status != domain.CardStatusCancelled {
+			t.Errorf("card %d must be cancelled, got %s", card.ID, card.Status)
+		}
+	}
+
+	// Закрытый счет остается в списке, повторное закрытие отклоняется
+	accounts, err := svc.GetUserAccounts(ctx, deps.owner.ID)
+	if err != nil || len(accounts) != 2 {
+		t.Errorf("closed account must stay in the list: %v", err)
+	}
+	if _, err := svc.CloseAccount(ctx, deps.owner.ID, 1, 2); !errors.Is(err, domain.ErrAccountClosed) {
+		t.Errorf("expected ErrAccountClosed, got %v", err)
+	}
+}
+
+func TestAccountService_Holders(t *testing.T) {
+	svc, deps := setupAccountLifecycleService(t)
+	ctx := context.Background()
+
+	secondary := &domain.User{Username: "secondary", Email: "secondary@example.com"}
+	joint := &domain.User{Username: "joint", Email: "joint@example.com"}
+	_ = deps.users.Create(ctx, secondary)
+	_ = deps.users.Create(ctx, joint)
+
+	if _, err := svc.AddAccountHolder(ctx, deps.owner.ID, 1, domain.AddAccountHolderRequest{
+		Email: deps.owner.Email, Role: domain.AccountHolderRoleJoint,
+	}); !errors.Is(err, domain.ErrAccountHolderIsOwner) {
+		t.Errorf("expected ErrAccountHolderIsOwner, got %v", err)
+	}
+
+	if _, err := svc.AddAccountHolder(ctx, deps.owner.ID, 1, domain.AddAccountHolderRequest{
+		Email: secondary.Email, Role: domain.AccountHolderRoleSecondary,
+		Permissions: []string{domain.AccountPermissionView},
+	}); err != nil {
+		t.Fatalf("AddAccountHolder failed: %v", err)
+	}
+	if _, err := svc.AddAccountHolder(ctx, deps.owner.ID, 1, domain.AddAccountHolderRequest{
+		Email: joint.Email, Role: domain.AccountHolderRoleJoint,
+	}); err != nil {
+		t.Fatalf("AddAccountHolder failed: %v", err)
+	}
+	if _, err := svc.AddAccountHolder(ctx, deps.owner.ID, 1, domain.AddAccountHolderRequest{
+		Email: joint.Email, Role: domain.AccountHolderRoleJoint,
+	}); !errors.Is(err, domain.ErrAccountHolderExists) {
+		t.Errorf("expected ErrAccountHolderExists, got %v", err)
+	}
+
+	// Совладелец с правом просмотра видит счет, но не может списывать
+	if _, err := svc.GetAccountHolders(ctx, secondary.ID, 1); err != nil {
+		t.Errorf("secondary holder must view account: %v", err)
+	}
+	var serviceErr *ServiceError
+	if err := svc.WithdrawMoney(ctx, secondary.ID, 1, 10); !errors.As(err, &serviceErr) || serviceErr.Code != http.StatusForbidden {
+		t.Errorf("expected 403 for view-only holder, got %v", err)
+	}
+
+	// Совместный владелец работает со счетом, но не управляет совладельцами
+	if err := svc.WithdrawMoney(ctx, joint.ID, 1, 10); err != nil {
+		t.Errorf("joint holder must withdraw: %v", err)
+	}
+	if err := svc.RemoveAccountHolder(ctx, joint.ID, 1, secondary.ID); !errors.As(err, &serviceErr) || serviceErr.Code != http.StatusForbidden {
+		t.Errorf("expected 403 for joint holder removing others, got %v", err)
+	}
+
+	accounts, err := svc.GetUserAccounts(ctx, joint.ID)
+	if err != nil {
+		t.Fatalf("GetUserAccounts failed: %v", err)
+	}
+	if len(accounts) != 1 || accounts[0].ID != 1 {
+		t.Errorf("expected shared account in joint holder's list, got %d accounts", len(accounts))
+	}
+
+	// Совладелец может выйти сам
+	if err := svc.RemoveAccountHolder(ctx, secondary.ID, 1, secondary.ID); err != nil {
+		t.Fatalf("self removal failed: %v", err)
+	}
+	holders, err := svc.GetAccountHolders(ctx, deps.owner.ID, 1)
+	if err != nil {
+		t.Fatalf("GetAccountHolders failed: %v", err)
+	}
+	if len(holders) != 1 || holders[0].UserID != joint.ID {
+		t.Errorf("expected only joint holder left, got %d", len(holders))
+	}
+}
+
+func TestAccountService_Overdraft(t *testing.T) {
+	svc, deps := setupAccountLifecycleService(t)
+	ctx := context.Background()
+
+	if err := svc.WithdrawMoney(ctx, deps.owner.ID, 1, 1200); !errors.Is(err, ErrInsufficientFunds) {
+		t.Errorf("expected ErrInsufficientFunds without overdraft, got %v", err)
+	}
+
+	// Условия продукта: лимит не выше максимума, для savings овердрафт не предоставляется
+	if _, err := svc.SetOverdraftLimit(ctx, deps.owner.ID, 1, 20000); !errors.Is(err, domain.ErrOverdraftLimitExceeded) {
+		t.Errorf("expected ErrOverdraftLimitExceeded, got %v", err)
+	}
+	if _, err := svc.SetOverdraftLimit(ctx, deps.owner.ID, 2, 1000); !errors.Is(err, domain.ErrOverdraftNotAvailable) {
+		t.Errorf("expected ErrOverdraftNotAvailable, got %v", err)
+	}
+
+	account, err := svc.SetOverdraftLimit(ctx, deps.owner.ID, 1, 1000)
+	if err != nil {
+		t.Fatalf("SetOverdraftLimit failed: %v", err)
+	}
+	if account.AvailableBalance() != 1500 {
+		t.Errorf("expected available balance 1500, got %.2f", account.AvailableBalance())
+	}
+
+	// Списание уводит остаток в минус в пределах лимита
+	if err := svc.WithdrawMoney(ctx, deps.owner.ID, 1, 1200); err != nil {
+		t.Fatalf("withdrawal within overdraft failed: %v", err)
+	}
+	if balance := deps.accounts.accounts[1].Balance; balance != -700 {
+		t.Errorf("expected balance -700, got %.2f", balance)
+	}
+	if err := svc.TransferMoney(ctx, deps.owner.ID, 1, 2, 400); !errors.Is(err, ErrInsufficientFunds) {
+		t.Errorf("expected ErrInsufficientFunds above overdraft limit, got %v", err)
+	}
+
+	// Лимит нельзя снизить ниже использованного овердрафта, счет с долгом нельзя закрыть
+	if _, err := svc.SetOverdraftLimit(ctx, deps.owner.ID, 1, 500); !errors.Is(err, domain.ErrOverdraftLimitBelowDebt) {
+		t.Errorf("expected ErrOverdraftLimitBelowDebt, got %v", err)
+	}
+	if _, err := svc.CloseAccount(ctx, deps.owner.ID, 1, 2); !errors.Is(err, domain.ErrAccountNegativeBalance) {
+		t.Errorf("expected ErrAccountNegativeBalance, got %v", err)
+	}
+}
@@ -3,8 +3,11 @@ package service
 import (
 	"context"
 	"log/slog"
+	"math"
+	"sort"
 	"time"
 
+	"github.com/vterdunov/learn-bank-app/internal/domain"
 	"github.com/vterdunov/learn-bank-app/internal/repository"
 	"github.com/vterdunov/learn-bank-app/pkg/logger"
 )
@@ -54,22 +57,55 @@ func (s *analyticsService) GetMonthlyStatistics(ctx context.Context, userID int,
 	return stats, nil
 }
 
-// GetCreditLoad возвращает информацию о кредитной нагрузке пользователя
+// GetCreditLoad возвращает кредитную нагрузку пользователя: долг по действующим кредитам
+// и использованный овердрафт по его счетам
 func (s *analyticsService) GetCreditLoad(ctx context.Context, userID int) (*CreditLoad, error) {
 	s.logger.Info("Getting credit load",
 		slog.Int("user_id", userID),
 	)
 
-	// Простая заглушка - возвращаем моковые данные
+	credits, err := s.creditRepo.GetCreditAnalytics(ctx, userID)
+	if err != nil {
+		s.logger.Error("Failed to get credit analytics",
+			slog.Int("user_id", userID),
+			slog.String("error", err.Error()),
+		)
+		return nil, err
+	}
+
+	accounts, err := s.accountRepo.GetByUserID(ctx, userID)
+	if err != nil {
+		s.logger.Error("Failed to get user accounts",
+			slog.Int("user_id", userID),
+			slog.String("error", err.Error()),
+		)
+		return nil, err
+	}
+
 	load := &CreditLoad{
-		TotalDebt:       120000.0, // Общая задолженность
-		MonthlyPayments: 8500.0,   // Ежемесячные платежи
-		CreditRatio:     0.35,     // Коэффициент кредитной нагрузки (35%)
+		CreditDebt:      credits.TotalDebt,
+		MonthlyPayments: credits.MonthlyPayments,
+	}
+	var assets float64
+	for _, account := range accounts {
+		load.OverdraftUsed += account.OverdraftUsed()
+		load.OverdraftLimit += account.OverdraftLimit
+		if account.Balance > 0 {
+			assets += account.Balance
+		}
+	}
+	load.OverdraftUsed = math.Round(load.OverdraftUsed*100) / 100
+	load.TotalDebt = math.Round((load.CreditDebt+load.OverdraftUsed)*100) / 100
+
+	// Коэффициент нагрузки — доля заемных средств: долг к сумме долга и собственных остатков
+	if load.TotalDebt > 0 {
+		load.CreditRatio = math.Round(load.TotalDebt/(load.TotalDebt+assets)*100) / 100
 	}
 
 	s.logger.Info("Credit load calculated",
 		slog.Int("user_id", userID),
 		slog.Float64("total_debt", load.TotalDebt),
+		slog.Float64("overdraft_used", load.OverdraftUsed),
 		slog.Float64("monthly_payments", load.MonthlyPayments),
 		slog.Float64("credit_ratio", load.CreditRatio),
 	)
@@ -100,3 +136,37 @@ func (s *analyticsService) PredictBalance(ctx context.Context, userID, accountID
 
 	return prediction, nil
 }
+
+// GetSpendingByCategory возвращает расходы по картам за месяц в разрезе категорий MCC (по убыванию суммы)
+func (s *analyticsService) GetSpendingByCategory(ctx context.Context, userID int, month time.Time) ([]*CategorySpending, error) {
+	spending, err := s.transactionRepo.GetSpendingByMCC(ctx, userID, month.Year(), int(month.Month()))
+	if err != nil {
+		s.logger.Error("Failed to get spending by MCC",
+			slog.Int("user_id", userID),
+			slog.String("error", err.Error()),
+		)
+		return nil, err
+	}
+
+	totals := make(map[string]float64)
+	for _, item := range spending {
+		totals[domain.MCCCategory(item.MCC)] += item.Amount
+	}
+
+	categories := make([]*CategorySpending, 0, len(totals))
+	for category, amount := range totals {
+		amount = math.Round(amount*100) / 100
+		if amount <= 0 {
+			continue
+		}
+		categories = append(categories, &CategorySpending{Category: category, Amount: amount})
+	}
+	sort.Slice(categories, func(i, j int) bool {
+		if categories[i].Amount != categories[j].Amount {
+			return categories[i].Amount > categories[j].Amount
+		}
+		return categories[i].Category < categories[j].Category
+	})
+
+	return categories, nil
+}
@@ -0,0 +1,45 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/vterdunov/learn-bank-app/internal/domain"
+)
+
+// mockCreditAnalyticsStore возвращает заданную сводку по кредитам
+type mockCreditAnalyticsStore struct {
+	MockCreditStore
+	analytics *domain.CreditAnalytics
+}
+
+func (m *mockCreditAnalyticsStore) GetCreditAnalytics(ctx context.Context, userID int) (*domain.CreditAnalytics, error) {
+	return m.analytics, nil
+}
+
+func TestAnalyticsService_CreditLoadIncludesOverdraft(t *testing.T) {
+	accounts := &MockAccountStore{accounts: map[int]*domain.Account{
+		1: {ID: 1, UserID: 7, Balance: -2000, OverdraftLimit: 5000, Status: domain.AccountStatusActive},
+		2: {ID: 2, UserID: 7, Balance: 12000, Status: domain.AccountStatusActive},
+		3: {ID: 3, UserID: 8, Balance: -1000, OverdraftLimit: 1000, Status: domain.AccountStatusActive},
+	}}
+	credits := &mockCreditAnalyticsStore{analytics: &domain.CreditAnalytics{TotalDebt: 10000, MonthlyPayments: 1500}}
+
+	svc := NewAnalyticsService(accounts, &MockTransactionRepository{accounts: accounts}, credits)
+
+	load, err := svc.GetCreditLoad(context.Background(), 7)
+	if err != nil {
+		t.Fatalf("GetCreditLoad failed: %v", err)
+	}
+
+	if load.OverdraftUsed != 2000 || load.OverdraftLimit != 5000 {
+		t.Errorf("expected overdraft 2000 of 5000, got %.2f of %.2f", load.OverdraftUsed, load.OverdraftLimit)
+	}
+	if load.TotalDebt != 12000 || load.CreditDebt != 10000 || load.MonthlyPayments != 1500 {
+		t.Errorf("unexpected debt totals: %+v", load)
+	}
+	// 12000 долга к 12000 долга и 12000 остатков
+	if load.CreditRatio != 0.5 {
+		t.Errorf("expected credit ratio 0.5, got %.2f", load.CreditRatio)
+	}
+}
@@ -0,0 +1,181 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"time"
+
+	"github.com/vterdunov/learn-bank-app/internal/domain"
+	"github.com/vterdunov/learn-bank-app/internal/repository"
+	"github.com/vterdunov/learn-bank-app/pkg/logger"
+)
+
+const (
+	// defaultAuditPageSize размер страницы выборки аудита по умолчанию
+	defaultAuditPageSize = 50
+	// maxAuditPageSize максимальный размер страницы выборки аудита
+	maxAuditPageSize = 500
+	// auditVerifyBatchSize размер пачки записей при проверке цепочки
+	auditVerifyBatchSize = 1000
+)
+
+// auditService реализует интерфейс AuditService
+type auditService struct {
+	auditRepo repository.AuditRepository
+	logger    *slog.Logger
+}
+
+// NewAuditService создает новый экземпляр сервиса аудита
+func NewAuditService(auditRepo repository.AuditRepository, lg *slog.Logger) AuditService {
+	return &auditService{
+		auditRepo: auditRepo,
+		logger:    logger.WithService(lg, "audit_service"),
+	}
+}
+
+// Record добавляет запись в журнал аудита.
+// ID запроса и IP берутся из контекста, если не заданы явно.
+func (s *auditService) Record(ctx context.Context, event *domain.AuditEvent) error {
+	if meta, ok := domain.RequestMetaFromContext(ctx); ok {
+		if event.RequestID == "" {
+			event.RequestID = meta.RequestID
+		}
+		if event.IP == "" {
+			event.IP = meta.IP
+		}
+	}
+
+	if event.CreatedAt.IsZero() {
+		event.CreatedAt = time.Now()
+	}
+
+	if err := event.Validate(); err != nil {
+		logger.LogError(s.logger, err, "Invalid audit event", "action", event.Action)
+		return err
+	}
+
+	if err := s.auditRepo.Append(ctx, event); err != nil {
+		logger.LogError(s.logger, err, "Failed to append audit event",
+			"action", event.Action,
+			"resource_type", event.ResourceType,
+			"resource_id", event.ResourceID,
+			"request_id", event.RequestID,
+		)
+		return fmt.Errorf("failed to record audit event: %w", err)
+	}
+
+	return nil
+}
+
+// QueryEvents возвращает записи аудита по фильтру; сам запрос администратора также попадает в аудит
+func (s *auditService) QueryEvents(ctx context.Context, adminID int, filter domain.AuditFilter) ([]*domain.AuditEvent, error) {
+	if filter.Limit <= 0 {
+		filter.Limit = defaultAuditPageSize
+	}
+	if filter.Limit > maxAuditPageSize {
+		filter.Limit = maxAuditPageSize
+	}
+	if filter.Offset < 0 {
+		filter.Offset = 0
+	}
+
+	queryEvent := NewUserAuditEvent(adminID, domain.AuditActionAdminAuditQuery, "audit_events", "")
+	queryEvent.ActorType = domain.AuditActorAdmin
+	queryEvent.Metadata = domain.NewAuditState(filter)
+	if err := s.Record(ctx, queryEvent); err != nil {
+		return nil, err
+	}
+
+	events, err := s.auditRepo.List(ctx, filter)
+	if err != nil {
+		logger.LogError(s.logger, err, "Failed to list audit events", "admin_id", adminID)
+		return nil, fmt.Errorf("failed to list audit events: %w", err)
+	}
+
+	return events, nil
+}
+
+// VerifyChain проверяет целостность всей цепочки аудита.
+// adminID задается при вызове через API (проверка записывается в аудит), nil - при запуске из CLI.
+func (s *auditService) VerifyChain(ctx context.Context, adminID *int) (*AuditVerifyResult, error) {
+	start := time.Now()
+	result := &AuditVerifyResult{Valid: true}
+
+	var prev *domain.AuditEvent
+	for {
+		afterSeq := int64(0)
+		if prev != nil {
+			afterSeq = prev.Seq
+		}
+
+		events, err := s.auditRepo.ListAfterSeq(ctx, afterSeq, auditVerifyBatchSize)
+		if err != nil {
+			logger.LogError(s.logger, err, "Failed to read audit chain", "after_seq", afterSeq)
+			return nil, fmt.Errorf("failed to read audit chain: %w", err)
+		}
+		if len(events) == 0 {
+			break
+		}
+
+		if err := domain.VerifyAuditChain(prev, events); err != nil {
+			var chainErr *domain.AuditChainError
+			if !errors.As(err, &chainErr) {
+				return nil, err
+			}
+
+			result.Valid = false
+			result.BrokenAtSeq = chainErr.Seq
+			result.Error = chainErr.Error()
+			result.Checked += chainErr.Seq - afterSeq - 1
+			logger.LogSecurityEvent(s.logger, "audit_chain_broken", "critical", map[string]interface{}{
+				"seq":    chainErr.Seq,
+				"id":     chainErr.ID,
+				"reason": chainErr.Reason,
+			})
+			break
+		}
+
+		result.Checked += int64(len(events))
+		prev = events[len(events)-1]
+	}
+
+	if prev != nil && result.Valid {
+		result.HeadSeq = prev.Seq
+		result.HeadHash = prev.Hash
+	}
+
+	logger.LogOperation(s.logger, "audit_chain_verify", result.Valid, time.Since(start).Milliseconds(),
+		"checked", result.Checked,
+		"head_seq", result.HeadSeq,
+	)
+
+	if adminID != nil {
+		verifyEvent := NewUserAuditEvent(*adminID, domain.AuditActionAdminAuditCheck, "audit_events", "")
+		verifyEvent.ActorType = domain.AuditActorAdmin
+		verifyEvent.Metadata = domain.NewAuditState(result)
+		if err := s.Record(ctx, verifyEvent); err != nil {
+			return nil, err
+		}
+	}
+
+	return result, nil
+}
+
+// NewUserAuditEvent создает запись аудита о действии пользователя
+func NewUserAuditEvent(userID int, action, resourceType, resourceID string) *domain.AuditEvent {
+	return &domain.AuditEvent{
+		ActorUserID:  &userID,
+		ActorType:    domain.AuditActorUser,
+		Action:       action,
+		ResourceType: resourceType,
+		ResourceID:   resourceID,
+	}
+}
+
+// auditResourceID форматирует числовой ID ресурса для аудита
+func auditResourceID(id int) string {
+	return strconv.Itoa(id)
+}
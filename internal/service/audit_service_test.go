@@ -0,0 +1,197 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"os"
+	"testing"
+
+	"github.com/vterdunov/learn-bank-app/internal/domain"
+)
+
+// MockAuditRepository для тестирования (хранит цепочку в памяти)
+type MockAuditRepository struct {
+	events      []*domain.AuditEvent
+	appendError error
+}
+
+func NewMockAuditRepository() *MockAuditRepository {
+	return &MockAuditRepository{}
+}
+
+func (m *MockAuditRepository) Append(ctx context.Context, event *domain.AuditEvent) error {
+	if m.appendError != nil {
+		return m.appendError
+	}
+
+	var prev *domain.AuditEvent
+	if len(m.events) > 0 {
+		prev = m.events[len(m.events)-1]
+	}
+
+	if err := event.Seal(prev); err != nil {
+		return err
+	}
+
+	event.ID = int64(len(m.events) + 1)
+	saved := *event
+	m.events = append(m.events, &saved)
+	return nil
+}
+
+func (m *MockAuditRepository) List(ctx context.Context, filter domain.AuditFilter) ([]*domain.AuditEvent, error) {
+	var result []*domain.AuditEvent
+	for i := len(m.events) - 1; i >= 0; i-- {
+		event := m.events[i]
+		if filter.Action != "" && event.Action != filter.Action {
+			continue
+		}
+		result = append(result, event)
+	}
+	return result, nil
+}
+
+func (m *MockAuditRepository) ListAfterSeq(ctx context.Context, afterSeq int64, limit int) ([]*domain.AuditEvent, error) {
+	var result []*domain.AuditEvent
+	for _, event := range m.events {
+		if event.Seq > afterSeq && len(result) < limit {
+			result = append(result, event)
+		}
+	}
+	return result, nil
+}
+
+func setupAuditService() (*auditService, *MockAuditRepository) {
+	mockRepo := NewMockAuditRepository()
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	service := NewAuditService(mockRepo, logger).(*auditService)
+	return service, mockRepo
+}
+
+func TestAuditService_Record(t *testing.T) {
+	service, mockRepo := setupAuditService()
+
+	ctx := domain.ContextWithRequestMeta(context.Background(), domain.RequestMeta{
+		RequestID: "req-1",
+		IP:        "10.0.0.1",
+	})
+
+	t.Run("fills request metadata and chains hashes", func(t *testing.T) {
+		first := NewUserAuditEvent(1, domain.AuditActionLogin, "user", "1")
+		if err := service.Record(ctx, first); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+
+		second := NewUserAuditEvent(1, domain.AuditActionTransfer, "account", "10")
+		second.Before = domain.NewAuditState(map[string]float64{"from_balance": 100})
+		second.After = domain.NewAuditState(map[string]float64{"from_balance": 50})
+		if err := service.Record(ctx, second); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+
+		if first.RequestID != "req-1" || first.IP != "10.0.0.1" {
+			t.Errorf("Expected request metadata from context, got %q %q", first.RequestID, first.IP)
+		}
+		if first.PrevHash != domain.AuditGenesisHash {
+			t.Errorf("Expected genesis prev hash, got %s", first.PrevHash)
+		}
+		if second.PrevHash != first.Hash || second.Seq != 2 {
+			t.Errorf("Expected second event to reference first, got seq %d prev %s", second.Seq, second.PrevHash)
+		}
+		if len(mockRepo.events) != 2 {
+			t.Errorf("Expected 2 events, got %d", len(mockRepo.events))
+		}
+	})
+
+	t.Run("invalid event", func(t *testing.T) {
+		err := service.Record(ctx, &domain.AuditEvent{ActorType: domain.AuditActorUser})
+		if !errors.Is(err, domain.ErrEmptyAuditAction) {
+			t.Errorf("Expected ErrEmptyAuditAction, got %v", err)
+		}
+	})
+
+	t.Run("repository error", func(t *testing.T) {
+		mockRepo.appendError = errors.New("database error")
+		defer func() { mockRepo.appendError = nil }()
+
+		if err := service.Record(ctx, NewUserAuditEvent(1, domain.AuditActionLogin, "user", "1")); err == nil {
+			t.Error("Expected error when repository fails")
+		}
+	})
+}
+
+func TestAuditService_VerifyChain(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("valid chain", func(t *testing.T) {
+		service, _ := setupAuditService()
+		for i := 0; i < 5; i++ {
+			if err := service.Record(ctx, NewUserAuditEvent(i, domain.AuditActionLogin, "user", "")); err != nil {
+				t.Fatalf("Failed to record event: %v", err)
+			}
+		}
+
+		result, err := service.VerifyChain(ctx, nil)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if !result.Valid || result.Checked != 5 || result.HeadSeq != 5 {
+			t.Errorf("Expected valid chain of 5 events, got %+v", result)
+		}
+	})
+
+	t.Run("tampered record", func(t *testing.T) {
+		service, mockRepo := setupAuditService()
+		for i := 0; i < 5; i++ {
+			event := NewUserAuditEvent(1, domain.AuditActionTransfer, "account", "10")
+			event.After = domain.NewAuditState(map[string]int{"balance": i})
+			if err := service.Record(ctx, event); err != nil {
+				t.Fatalf("Failed to record event: %v", err)
+			}
+		}
+
+		// Подменяем состояние в середине цепочки
+		mockRepo.events[2].After = domain.NewAuditState(map[string]int{"balance": 1000})
+
+		result, err := service.VerifyChain(ctx, nil)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if result.Valid || result.BrokenAtSeq != 3 {
+			t.Errorf("Expected chain broken at seq 3, got %+v", result)
+		}
+	})
+
+	t.Run("deleted record", func(t *testing.T) {
+		service, mockRepo := setupAuditService()
+		for i := 0; i < 3; i++ {
+			if err := service.Record(ctx, NewUserAuditEvent(1, domain.AuditActionLogin, "user", "1")); err != nil {
+				t.Fatalf("Failed to record event: %v", err)
+			}
+		}
+
+		mockRepo.events = append(mockRepo.events[:1], mockRepo.events[2:]...)
+
+		result, err := service.VerifyChain(ctx, nil)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if result.Valid || result.BrokenAtSeq != 3 {
+			t.Errorf("Expected chain broken at seq 3, got %+v", result)
+		}
+	})
+
+	t.Run("admin verification is audited", func(t *testing.T) {
+		service, mockRepo := setupAuditService()
+		adminID := 42
+
+		if _, err := service.VerifyChain(ctx, &adminID); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+
+		if len(mockRepo.events) != 1 || mockRepo.events[0].Action != domain.AuditActionAdminAuditCheck {
+			t.Errorf("Expected admin verification to be recorded, got %d events", len(mockRepo.events))
+		}
+	})
+}
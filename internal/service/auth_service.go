@@ -22,15 +22,19 @@ var (
 
 // authService реализует интерфейс AuthService
 type authService struct {
-	userRepo repository.UserRepository
-	logger   *slog.Logger
+	userRepo         repository.UserRepository
+	sanctionsService SanctionsService
+	auditService     AuditService
+	logger           *slog.Logger
 }
 
 // NewAuthService создает новый экземпляр сервиса аутентификации
-func NewAuthService(userRepo repository.UserRepository, lg *slog.Logger) AuthService {
+func NewAuthService(userRepo repository.UserRepository, sanctionsService SanctionsService, auditService AuditService, lg *slog.Logger) AuthService {
 	return &authService{
-		userRepo: userRepo,
-		logger:   logger.WithService(lg, "auth_service"),
+		userRepo:         userRepo,
+		sanctionsService: sanctionsService,
+		auditService:     auditService,
+		logger:           logger.WithService(lg, "auth_service"),
 	}
 }
 
@@ -96,6 +100,12 @@ func (s *authService) Register(ctx context.Context, req RegisterRequest) (*domai
 	// Убираем пароль из ответа
 	user.PasswordHash = ""
 
+	// Проверка по санкционным спискам: совпадения уходят оператору, регистрация не прерывается.
+	// Клиент, пропущенный из-за ошибки, будет проверен при переводе ему или при обновлении списков.
+	if _, err := s.sanctionsService.ScreenUser(ctx, user, domain.SanctionsSourceRegistration); err != nil {
+		logger.LogError(s.logger, err, "Failed to screen user against sanctions lists", "user_id", user.ID)
+	}
+
 	// Логируем успешную регистрацию
 	logger.LogOperation(s.logger, "user_registration", true, time.Since(start).Milliseconds(),
 		"user_id", user.ID,
@@ -127,6 +137,7 @@ func (s *authService) Login(ctx context.Context, req LoginRequest) (string, erro
 		logger.LogSecurityEvent(s.logger, "login_attempt_unknown_email", "medium", map[string]interface{}{
 			"email": req.Email,
 		})
+		s.recordFailedLogin(ctx, nil, req.Email, "unknown_email")
 		return "", ErrInvalidCredentials
 	}
 
@@ -136,6 +147,7 @@ func (s *authService) Login(ctx context.Context, req LoginRequest) (string, erro
 			"email":   req.Email,
 			"user_id": user.ID,
 		})
+		s.recordFailedLogin(ctx, &user.ID, req.Email, "invalid_password")
 		return "", ErrInvalidCredentials
 	}
 
@@ -156,9 +168,32 @@ func (s *authService) Login(ctx context.Context, req LoginRequest) (string, erro
 		"email": user.Email,
 	})
 
+	// Ошибка аудита уже залогирована и не прерывает вход
+	_ = s.auditService.Record(ctx, NewUserAuditEvent(user.ID, domain.AuditActionLogin, "user", auditResourceID(user.ID)))
+
 	return token, nil
 }
 
+// recordFailedLogin записывает неудачную попытку входа в аудит
+func (s *authService) recordFailedLogin(ctx context.Context, userID *int, email, reason string) {
+	event := &domain.AuditEvent{
+		ActorUserID: userID,
+		ActorType:   domain.AuditActorAnonymous,
+		Action:      domain.AuditActionLoginFailed,
+		Metadata: domain.NewAuditState(map[string]string{
+			"email":  email,
+			"reason": reason,
+		}),
+	}
+	if userID != nil {
+		event.ResourceType = "user"
+		event.ResourceID = auditResourceID(*userID)
+	}
+
+	// Ошибка аудита уже залогирована и не влияет на ответ клиенту
+	_ = s.auditService.Record(ctx, event)
+}
+
 // ValidateToken проверяет валидность JWT токена и возвращает пользователя
 func (s *authService) ValidateToken(ctx context.Context, token string) (*domain.User, error) {
 	start := time.Now()
@@ -5,6 +5,7 @@ import (
 	"errors"
 	"log/slog"
 	"os"
+	"sort"
 	"testing"
 	"time"
 
@@ -136,13 +137,32 @@ func (m *MockUserRepository) UsernameExists(ctx context.Context, username string
 	return false, nil
 }
 
+func (m *MockUserRepository) ListAfterID(ctx context.Context, afterID, limit int) ([]*domain.User, error) {
+	if m.getError != nil {
+		return nil, m.getError
+	}
+
+	var users []*domain.User
+	for _, user := range m.usersByID {
+		if user.ID > afterID {
+			users = append(users, user)
+		}
+	}
+	sort.Slice(users, func(i, j int) bool { return users[i].ID < users[j].ID })
+	if len(users) > limit {
+		users = users[:limit]
+	}
+	return users, nil
+}
+
 func setupAuthService() (*authService, *MockUserRepository) {
 	// Инициализируем JWT для тестов
 	utils.InitJWT("test-secret-key-for-testing")
 
 	mockRepo := NewMockUserRepository()
 	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
-	service := NewAuthService(mockRepo, logger).(*authService)
+	auditService := NewAuditService(NewMockAuditRepository(), logger)
+	service := NewAuthService(mockRepo, mockSanctionsService{}, auditService, logger).(*authService)
 	return service, mockRepo
 }
 
@@ -0,0 +1,296 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+
+	"github.com/vterdunov/learn-bank-app/internal/domain"
+	"github.com/vterdunov/learn-bank-app/internal/repository"
+	"github.com/vterdunov/learn-bank-app/pkg/iso8583"
+	"github.com/vterdunov/learn-bank-app/pkg/logger"
+)
+
+// gatewayEchoFields поля запроса, которые возвращаются в ответе без изменений
+var gatewayEchoFields = []int{
+	iso8583.FieldPAN,
+	iso8583.FieldProcessingCode,
+	iso8583.FieldAmount,
+	iso8583.FieldTransmissionDateTime,
+	iso8583.FieldSTAN,
+	iso8583.FieldRRN,
+	iso8583.FieldTerminalID,
+	iso8583.FieldMerchantID,
+	iso8583.FieldCurrencyCode,
+}
+
+// cardGatewayService реализует интерфейс CardGatewayService.
+// Запрос авторизации (0100) создает холд, финансовый запрос (0200) создает и сразу списывает его,
+// реверс (0400) отменяет холд или возвращает списанную сумму исходной операции.
+// Операции выполняются от имени владельца счета карты: держатель подтверждается номером, сроком и CVV.
+// Реверс принимается только от зарегистрированного терминала ТСП исходной операции.
+type cardGatewayService struct {
+	cardService  CardService
+	cardRepo     repository.CardRepository
+	accountRepo  repository.AccountRepository
+	merchantRepo repository.MerchantRepository
+	messageRepo  repository.GatewayMessageRepository
+	logger       *slog.Logger
+}
+
+// NewCardGatewayService создает новый экземпляр CardGatewayService
+func NewCardGatewayService(
+	cardService CardService,
+	cardRepo repository.CardRepository,
+	accountRepo repository.AccountRepository,
+	merchantRepo repository.MerchantRepository,
+	messageRepo repository.GatewayMessageRepository,
+	lg *slog.Logger,
+) CardGatewayService {
+	return &cardGatewayService{
+		cardService:  cardService,
+		cardRepo:     cardRepo,
+		accountRepo:  accountRepo,
+		merchantRepo: merchantRepo,
+		messageRepo:  messageRepo,
+		logger:       logger.WithService(lg, "card_gateway_service"),
+	}
+}
+
+// HandleMessage обрабатывает запрос и возвращает ответ с кодом в поле 39.
+// Ошибка возвращается, только если на сообщение нельзя ответить (например, это сам ответ).
+func (s *cardGatewayService) HandleMessage(ctx context.Context, request *iso8583.Message) (*iso8583.Message, error) {
+	response, err := request.Response(gatewayEchoFields...)
+	if err != nil {
+		return nil, err
+	}
+
+	switch request.MTI {
+	case iso8583.MTIAuthorizationRequest, iso8583.MTIFinancialRequest, iso8583.MTIReversalRequest:
+	default:
+		s.logger.Warn("Unsupported gateway message", "mti", request.MTI)
+		response.Set(iso8583.FieldResponseCode, domain.GatewayResponseInvalidTxn)
+		return response, nil
+	}
+
+	amount, err := iso8583.ParseAmount(request.Fields[iso8583.FieldAmount])
+	if err != nil || !request.Has(iso8583.FieldRRN) || !request.Has(iso8583.FieldTerminalID) {
+		s.logger.Warn("Malformed gateway message", "mti", request.MTI, "stan", request.Get(iso8583.FieldSTAN))
+		response.Set(iso8583.FieldResponseCode, domain.GatewayResponseFormatError)
+		return response, nil
+	}
+	if currency := request.Get(iso8583.FieldCurrencyCode); currency != "" && currency != domain.GatewayCurrencyRUB {
+		response.Set(iso8583.FieldResponseCode, domain.GatewayResponseNotPermitted)
+		return response, nil
+	}
+
+	message := &domain.GatewayMessage{
+		MTI:        request.MTI,
+		STAN:       request.Get(iso8583.FieldSTAN),
+		RRN:        request.Get(iso8583.FieldRRN),
+		TerminalID: request.Get(iso8583.FieldTerminalID),
+		MerchantID: request.Get(iso8583.FieldMerchantID),
+		Amount:     amount,
+	}
+
+	// Запрос регистрируется до обработки: повтор с тем же RRN получает сохраненный ответ, а не второе списание
+	if err := s.messageRepo.Create(ctx, message); err != nil {
+		if errors.Is(err, domain.ErrGatewayMessageDuplicate) {
+			return s.repeatResponse(ctx, message, response), nil
+		}
+		s.logger.Error("Failed to register gateway message", "mti", message.MTI, "rrn", message.RRN, "error", err)
+		response.Set(iso8583.FieldResponseCode, domain.GatewayResponseSystemError)
+		return response, nil
+	}
+
+	switch request.MTI {
+	case iso8583.MTIAuthorizationRequest:
+		s.authorize(ctx, request, message, false)
+	case iso8583.MTIFinancialRequest:
+		s.authorize(ctx, request, message, true)
+	case iso8583.MTIReversalRequest:
+		s.reverse(ctx, message)
+	}
+
+	if err := s.messageRepo.Update(ctx, message); err != nil {
+		s.logger.Error("Failed to save gateway response",
+			"message_id", message.ID,
+			"response_code", message.ResponseCode,
+			"error", err)
+	}
+
+	s.logger.Info("Gateway message processed",
+		"message_id", message.ID,
+		"mti", message.MTI,
+		"rrn", message.RRN,
+		"terminal_id", message.TerminalID,
+		"amount", message.Amount,
+		"response_code", message.ResponseCode)
+
+	setGatewayResponse(response, message)
+	return response, nil
+}
+
+// authorize проверяет держателя карты и блокирует сумму; при capture платеж сразу списывается
+func (s *cardGatewayService) authorize(ctx context.Context, request *iso8583.Message, message *domain.GatewayMessage, capture bool) {
+	// Поле 14 передает срок как ГГММ
+	expiry := request.Get(iso8583.FieldExpiryDate)
+	if len(expiry) == 4 {
+		expiry = expiry[2:] + "/" + expiry[:2]
+	}
+
+	card, err := s.cardService.VerifyCardholder(ctx, request.Get(iso8583.FieldPAN), expiry,
+		request.Get(iso8583.FieldAdditionalData))
+	if err != nil {
+		message.ResponseCode = gatewayResponseCode(err)
+		return
+	}
+	message.CardID = &card.ID
+
+	hold, err := s.cardService.AuthorizeGatewayPayment(ctx, card.ID, message.Amount, message.MerchantID,
+		request.Get(iso8583.FieldMerchantName))
+	if err != nil {
+		message.ResponseCode = gatewayResponseCode(err)
+		return
+	}
+	message.HoldID = &hold.ID
+
+	if capture {
+		if _, err := s.cardService.CapturePayment(ctx, gatewayOperatorID, hold.ID, 0); err != nil {
+			s.logger.Error("Failed to capture financial request", "hold_id", hold.ID, "error", err)
+			if _, err := s.cardService.VoidAuthorization(ctx, gatewayOperatorID, hold.ID); err != nil {
+				s.logger.Error("Failed to void uncaptured financial request", "hold_id", hold.ID, "error", err)
+			}
+			message.ResponseCode = domain.GatewayResponseSystemError
+			return
+		}
+	}
+
+	message.ResponseCode = domain.GatewayResponseApproved
+	message.AuthCode = fmt.Sprintf("%06d", hold.ID%1000000)
+}
+
+// reverse отменяет одобренную операцию с тем же RRN и терминалом: снимает холд,
+// а если он уже списан — возвращает списанную сумму.
+// Возврат списывается с расчетного счета ТСП, поэтому терминал должен быть зарегистрирован
+// за ТСП из поля 42, а ТСП — совпадать с исходной операцией.
+func (s *cardGatewayService) reverse(ctx context.Context, message *domain.GatewayMessage) {
+	merchant, err := s.merchantRepo.GetByTerminal(ctx, message.TerminalID)
+	if err != nil {
+		if errors.Is(err, domain.ErrMerchantNotFound) {
+			s.logger.Warn("Reversal from unregistered terminal", "rrn", message.RRN, "terminal_id", message.TerminalID)
+			message.ResponseCode = domain.GatewayResponseInvalidMerchant
+			return
+		}
+		s.logger.Error("Failed to find terminal merchant", "terminal_id", message.TerminalID, "error", err)
+		message.ResponseCode = domain.GatewayResponseSystemError
+		return
+	}
+	if merchant.Code != message.MerchantID {
+		s.logger.Warn("Reversal merchant does not match terminal",
+			"rrn", message.RRN, "terminal_id", message.TerminalID, "merchant_id", message.MerchantID)
+		message.ResponseCode = domain.GatewayResponseInvalidMerchant
+		return
+	}
+
+	original, err := s.messageRepo.GetOriginal(ctx, message.RRN, message.TerminalID)
+	if err != nil {
+		if errors.Is(err, domain.ErrGatewayMessageNotFound) {
+			message.ResponseCode = domain.GatewayResponseOriginalNotFound
+			return
+		}
+		s.logger.Error("Failed to find original gateway message", "rrn", message.RRN, "error", err)
+		message.ResponseCode = domain.GatewayResponseSystemError
+		return
+	}
+	if original.CardID == nil || original.HoldID == nil {
+		message.ResponseCode = domain.GatewayResponseOriginalNotFound
+		return
+	}
+	if original.TerminalID != message.TerminalID || original.MerchantID != message.MerchantID {
+		s.logger.Warn("Reversal merchant does not match original",
+			"rrn", message.RRN, "original_id", original.ID, "merchant_id", message.MerchantID)
+		message.ResponseCode = domain.GatewayResponseInvalidMerchant
+		return
+	}
+	if message.Amount != original.Amount {
+		message.ResponseCode = domain.GatewayResponseInvalidAmount
+		return
+	}
+	message.CardID = original.CardID
+	message.HoldID = original.HoldID
+
+	_, err = s.cardService.VoidAuthorization(ctx, gatewayOperatorID, *original.HoldID)
+	if errors.Is(err, domain.ErrCardHoldNotAuthorized) {
+		_, err = s.cardService.RefundPayment(ctx, gatewayOperatorID, *original.HoldID, 0)
+		// Холд уже снят или сумма полностью возвращена: отменять нечего
+		if errors.Is(err, domain.ErrCardHoldNotCaptured) || errors.Is(err, domain.ErrInvalidRefundAmount) {
+			err = nil
+		}
+	}
+	if err != nil {
+		message.ResponseCode = gatewayResponseCode(err)
+		return
+	}
+
+	message.ResponseCode = domain.GatewayResponseApproved
+	message.AuthCode = original.AuthCode
+}
+
+// repeatResponse отвечает на повтор запроса сохраненным результатом
+func (s *cardGatewayService) repeatResponse(ctx context.Context, message *domain.GatewayMessage, response *iso8583.Message) *iso8583.Message {
+	existing, err := s.messageRepo.GetByReference(ctx, message.MTI, message.RRN, message.TerminalID)
+	if err != nil {
+		s.logger.Error("Failed to load repeated gateway message", "mti", message.MTI, "rrn", message.RRN, "error", err)
+		response.Set(iso8583.FieldResponseCode, domain.GatewayResponseSystemError)
+		return response
+	}
+
+	s.logger.Info("Repeated gateway message", "message_id", existing.ID, "rrn", existing.RRN)
+
+	if !existing.IsFinished() {
+		response.Set(iso8583.FieldResponseCode, domain.GatewayResponseInProgress)
+		return response
+	}
+
+	setGatewayResponse(response, existing)
+	return response
+}
+
+func setGatewayResponse(response *iso8583.Message, message *domain.GatewayMessage) {
+	response.Set(iso8583.FieldResponseCode, message.ResponseCode)
+	if message.AuthCode != "" {
+		response.Set(iso8583.FieldAuthCode, message.AuthCode)
+	}
+}
+
+// gatewayResponseCode переводит ошибку сервиса карт в код ответа поля 39
+func gatewayResponseCode(err error) string {
+	var serviceErr *ServiceError
+	switch {
+	case errors.Is(err, ErrCardNotFound), errors.Is(err, ErrAccountNotFound):
+		return domain.GatewayResponseInvalidCard
+	case errors.Is(err, ErrCardExpired), errors.Is(err, ErrCardExpiryMismatch):
+		return domain.GatewayResponseExpiredCard
+	case errors.Is(err, ErrInvalidCVV):
+		return domain.GatewayResponseCVVFailure
+	case errors.Is(err, ErrCardBlocked), errors.Is(err, ErrAccountBlocked):
+		return domain.GatewayResponseRestrictedCard
+	case errors.Is(err, ErrInsufficientFunds):
+		return domain.GatewayResponseInsufficientFunds
+	case errors.Is(err, ErrInvalidAmount):
+		return domain.GatewayResponseInvalidAmount
+	case errors.Is(err, domain.ErrInvalidMerchantID):
+		return domain.GatewayResponseFormatError
+	case errors.Is(err, domain.ErrKYCLimitExceeded):
+		return domain.GatewayResponseExceedsLimit
+	case isScreeningError(err):
+		return domain.GatewayResponseSuspectedFraud
+	case errors.As(err, &serviceErr) && serviceErr.Code == http.StatusForbidden:
+		return domain.GatewayResponseNotPermitted
+	default:
+		return domain.GatewayResponseSystemError
+	}
+}
@@ -0,0 +1,246 @@
+package service
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/vterdunov/learn-bank-app/internal/domain"
+	"github.com/vterdunov/learn-bank-app/pkg/iso8583"
+)
+
+// MockGatewayMessageRepository журнал сообщений шлюза в памяти
+type MockGatewayMessageRepository struct {
+	messages []*domain.GatewayMessage
+}
+
+func (m *MockGatewayMessageRepository) Create(ctx context.Context, message *domain.GatewayMessage) error {
+	if _, err := m.GetByReference(ctx, message.MTI, message.RRN, message.TerminalID); err == nil {
+		return domain.ErrGatewayMessageDuplicate
+	}
+	message.ID = int64(len(m.messages) + 1)
+	copied := *message
+	m.messages = append(m.messages, &copied)
+	return nil
+}
+
+func (m *MockGatewayMessageRepository) Update(ctx context.Context, message *domain.GatewayMessage) error {
+	copied := *message
+	m.messages[message.ID-1] = &copied
+	return nil
+}
+
+func (m *MockGatewayMessageRepository) GetByReference(ctx context.Context, mti, rrn, terminalID string) (*domain.GatewayMessage, error) {
+	for _, message := range m.messages {
+		if message.MTI == mti && message.RRN == rrn && message.TerminalID == terminalID {
+			copied := *message
+			return &copied, nil
+		}
+	}
+	return nil, domain.ErrGatewayMessageNotFound
+}
+
+func (m *MockGatewayMessageRepository) GetOriginal(ctx context.Context, rrn, terminalID string) (*domain.GatewayMessage, error) {
+	for i := len(m.messages) - 1; i >= 0; i-- {
+		message := m.messages[i]
+		if message.RRN == rrn && message.TerminalID == terminalID && message.ResponseCode == domain.GatewayResponseApproved &&
+			(message.MTI == iso8583.MTIAuthorizationRequest || message.MTI == iso8583.MTIFinancialRequest) {
+			copied := *message
+			return &copied, nil
+		}
+	}
+	return nil, domain.ErrGatewayMessageNotFound
+}
+
+// setupCardGatewayService создает шлюз поверх сервиса карт с картой 4000001234567899, 03/28, CVV 123,
+// ТСП фикстур MERCH000000001 с терминалом TERM0001 и терминалом TERM0009 ТСП grocery-1
+func setupCardGatewayService(t *testing.T) (CardGatewayService, *cardHoldTestDeps) {
+	t.Helper()
+
+	cardSvc, deps := setupCardHoldService(t)
+
+	cards := cardSvc.cardRepo.(*MockCardRepository)
+	issueTestCard(t, cardSvc, cards.cards[1], "4000001234567899", "03/28", "123")
+
+	deps.merchants.merchants[2] = &domain.Merchant{ID: 2, Code: "MERCH000000001", Name: "Coffee House", MCC: "5814", SettlementAccountID: 3}
+	deps.merchants.terminals = map[string]int{"TERM0001": 2, "TERM0009": 1}
+	// Возврат списывается с расчетного счета целиком, а зачислено было за вычетом комиссии
+	deps.accounts.accounts[3].Balance = 1000
+
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	gateway := NewCardGatewayService(cardSvc, cards, deps.accounts, deps.merchants, &MockGatewayMessageRepository{}, logger)
+
+	return gateway, deps
+}
+
+// replayFixtures прогоняет фикстуры через упаковку в кадр и обработку шлюзом
+func replayFixtures(t *testing.T, gateway CardGatewayService, path string) {
+	t.Helper()
+
+	fixtures, err := iso8583.LoadFixtures(path)
+	if err != nil {
+		t.Fatalf("failed to load fixtures: %v", err)
+	}
+
+	for i := range fixtures {
+		fixture := &fixtures[i]
+
+		packed, err := iso8583.Pack(fixture.Request)
+		if err != nil {
+			t.Fatalf("%s: failed to pack request: %v", fixture.Name, err)
+		}
+		request, err := iso8583.Unpack(packed)
+		if err != nil {
+			t.Fatalf("%s: failed to unpack request: %v", fixture.Name, err)
+		}
+
+		response, err := gateway.HandleMessage(context.Background(), request)
+		if err != nil {
+			t.Fatalf("%s: HandleMessage failed: %v", fixture.Name, err)
+		}
+		if _, err := iso8583.Pack(response); err != nil {
+			t.Fatalf("%s: failed to pack response: %v", fixture.Name, err)
+		}
+
+		expectedMTI, _ := iso8583.ResponseMTI(request.MTI)
+		if response.MTI != expectedMTI {
+			t.Errorf("%s: expected response MTI %s, got %s", fixture.Name, expectedMTI, response.MTI)
+		}
+		if err := fixture.Check(response); err != nil {
+			t.Error(err)
+		}
+		if response.Get(iso8583.FieldSTAN) != request.Get(iso8583.FieldSTAN) {
+			t.Errorf("%s: STAN is not echoed", fixture.Name)
+		}
+	}
+}
+
+func TestCardGatewayService_AuthorizationFixtures(t *testing.T) {
+	gateway, deps := setupCardGatewayService(t)
+
+	replayFixtures(t, gateway, filepath.Join("testdata", "iso8583", "authorization.json"))
+
+	// Авторизация снята реверсом, покупка списана и возвращена; повторы не создали вторых операций
+	account := deps.accounts.accounts[1]
+	if account.Balance != 1000 || account.HeldAmount != 0 {
+		t.Errorf("expected balance 1000 and nothing held, got balance=%.2f held=%.2f", account.Balance, account.HeldAmount)
+	}
+	if len(deps.holds.holds) != 2 {
+		t.Fatalf("expected 2 holds, got %d", len(deps.holds.holds))
+	}
+	if hold := deps.holds.holds[1]; hold.Status != domain.CardHoldStatusVoided {
+		t.Errorf("expected voided authorization, got %s", hold.Status)
+	}
+	if hold := deps.holds.holds[2]; hold.Status != domain.CardHoldStatusCaptured || hold.RefundedAmount != 200.5 {
+		t.Errorf("expected captured and refunded purchase, got %+v", hold)
+	}
+}
+
+func TestCardGatewayService_DeclineFixtures(t *testing.T) {
+	gateway, deps := setupCardGatewayService(t)
+
+	replayFixtures(t, gateway, filepath.Join("testdata", "iso8583", "declines.json"))
+
+	account := deps.accounts.accounts[1]
+	if account.Balance != 1000 || account.HeldAmount != 0 || len(deps.holds.holds) != 0 {
+		t.Errorf("declined messages must not move funds, got balance=%.2f held=%.2f holds=%d",
+			account.Balance, account.HeldAmount, len(deps.holds.holds))
+	}
+}
+
+func TestCardGatewayService_InProgressRetransmission(t *testing.T) {
+	gateway, _ := setupCardGatewayService(t)
+	messages := gateway.(*cardGatewayService).messageRepo
+
+	// Запрос получен, но ответ еще не сохранен (обработка идет или прервалась)
+	_ = messages.Create(context.Background(), &domain.GatewayMessage{
+		MTI: iso8583.MTIAuthorizationRequest, RRN: "000000000042", TerminalID: "TERM0001",
+	})
+
+	request := iso8583.NewMessage(iso8583.MTIAuthorizationRequest)
+	request.Set(iso8583.FieldAmount, iso8583.FormatAmount(10))
+	request.Set(iso8583.FieldRRN, "000000000042")
+	request.Set(iso8583.FieldTerminalID, "TERM0001")
+
+	response, err := gateway.HandleMessage(context.Background(), request)
+	if err != nil {
+		t.Fatalf("HandleMessage failed: %v", err)
+	}
+	if code := response.Get(iso8583.FieldResponseCode); code != domain.GatewayResponseInProgress {
+		t.Errorf("expected response code %s, got %s", domain.GatewayResponseInProgress, code)
+	}
+}
+
+func TestCardGatewayService_UnanswerableMessage(t *testing.T) {
+	gateway, _ := setupCardGatewayService(t)
+
+	if _, err := gateway.HandleMessage(context.Background(), iso8583.NewMessage(iso8583.MTIAuthorizationResponse)); err == nil {
+		t.Error("expected error for a response message")
+	}
+}
+
+func TestCardGatewayService_ReversalMerchantChecks(t *testing.T) {
+	gateway, deps := setupCardGatewayService(t)
+	ctx := context.Background()
+
+	message := func(mti, rrn, stan, terminalID, merchantID string) *iso8583.Message {
+		m := iso8583.NewMessage(mti)
+		m.Set(iso8583.FieldPAN, "4000001234567899")
+		m.Set(iso8583.FieldAmount, iso8583.FormatAmount(150))
+		m.Set(iso8583.FieldSTAN, stan)
+		m.Set(iso8583.FieldExpiryDate, "2803")
+		m.Set(iso8583.FieldRRN, rrn)
+		m.Set(iso8583.FieldTerminalID, terminalID)
+		m.Set(iso8583.FieldMerchantID, merchantID)
+		m.Set(iso8583.FieldAdditionalData, "123")
+		return m
+	}
+
+	// Авторизации в ТСП фикстур и, с того же терминала, во внешний ТСП
+	for _, auth := range []*iso8583.Message{
+		message(iso8583.MTIAuthorizationRequest, "000000000101", "000001", "TERM0001", "MERCH000000001"),
+		message(iso8583.MTIAuthorizationRequest, "000000000102", "000002", "TERM0001", "EXTERNAL"),
+	} {
+		response, err := gateway.HandleMessage(ctx, auth)
+		if err != nil || response.Get(iso8583.FieldResponseCode) != domain.GatewayResponseApproved {
+			t.Fatalf("expected approved authorization, got %v %v", response, err)
+		}
+	}
+
+	tests := []struct {
+		name     string
+		reversal *iso8583.Message
+		want     string
+	}{
+		{"unregistered terminal", message(iso8583.MTIReversalRequest, "000000000101", "000011", "TERM0002", "MERCH000000001"),
+			domain.GatewayResponseInvalidMerchant},
+		{"terminal of another merchant", message(iso8583.MTIReversalRequest, "000000000101", "000012", "TERM0009", "MERCH000000001"),
+			domain.GatewayResponseInvalidMerchant},
+		// Повтор реверса с тем же RRN и терминалом получил бы сохраненный ответ, поэтому RRN другой
+		{"merchant does not match terminal", message(iso8583.MTIReversalRequest, "000000000103", "000013", "TERM0001", "grocery-1"),
+			domain.GatewayResponseInvalidMerchant},
+		{"merchant does not match original", message(iso8583.MTIReversalRequest, "000000000102", "000014", "TERM0001", "MERCH000000001"),
+			domain.GatewayResponseInvalidMerchant},
+		{"registered terminal of original merchant", message(iso8583.MTIReversalRequest, "000000000101", "000015", "TERM0001", "MERCH000000001"),
+			domain.GatewayResponseApproved},
+	}
+
+	for _, tt := range tests {
+		response, err := gateway.HandleMessage(ctx, tt.reversal)
+		if err != nil {
+			t.Fatalf("%s: HandleMessage failed: %v", tt.name, err)
+		}
+		if code := response.Get(iso8583.FieldResponseCode); code != tt.want {
+			t.Errorf("%s: expected response code %s, got %s", tt.name, tt.want, code)
+		}
+	}
+
+	if hold := deps.holds.holds[1]; hold.Status != domain.CardHoldStatusVoided {
+		t.Errorf("expected authorization voided by its own terminal, got %s", hold.Status)
+	}
+	if hold := deps.holds.holds[2]; hold.Status != domain.CardHoldStatusAuthorized {
+		t.Errorf("expected forged reversal to keep authorization, got %s", hold.Status)
+	}
+}
@@ -0,0 +1,189 @@
+package service
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/vterdunov/learn-bank-app/internal/config"
+	"github.com/vterdunov/learn-bank-app/internal/domain"
+	"github.com/vterdunov/learn-bank-app/internal/repository"
+	"github.com/vterdunov/learn-bank-app/internal/utils"
+	"github.com/vterdunov/learn-bank-app/pkg/logger"
+)
+
+// cardRevealTokenBytes длина одноразового токена показа реквизитов (случайные байты)
+const cardRevealTokenBytes = 32
+
+// cardRevealService показывает полные реквизиты карты в два шага: одноразовый токен выдается после
+// повторной проверки пароля, а номер и срок действия возвращаются один раз в течение RevealTokenTTL.
+// Каждая попытка получить токен, в том числе с неверным паролем, расходует лимит RevealLimit за RevealWindow.
+// Выдача токена, отказ в ней и показ реквизитов записываются в аудит.
+type cardRevealService struct {
+	cardRepo      repository.CardRepository
+	revealRepo    repository.CardRevealRepository
+	userRepo      repository.UserRepository
+	accessControl domain.AccessControlService
+	cardService   CardService
+	txManager     repository.TxManager
+	auditService  AuditService
+	clock         utils.Clock
+	tokenTTL      time.Duration
+	limit         int
+	window        time.Duration
+	logger        *slog.Logger
+}
+
+// NewCardRevealService создает новый экземпляр CardRevealService
+func NewCardRevealService(
+	cfg *config.Config,
+	cardRepo repository.CardRepository,
+	revealRepo repository.CardRevealRepository,
+	userRepo repository.UserRepository,
+	accessControl domain.AccessControlService,
+	cardService CardService,
+	txManager repository.TxManager,
+	auditService AuditService,
+	clock utils.Clock,
+	lg *slog.Logger,
+) CardRevealService {
+	return &cardRevealService{
+		cardRepo:      cardRepo,
+		revealRepo:    revealRepo,
+		userRepo:      userRepo,
+		accessControl: accessControl,
+		cardService:   cardService,
+		txManager:     txManager,
+		auditService:  auditService,
+		clock:         clock,
+		tokenTTL:      cfg.Card.RevealTokenTTL,
+		limit:         cfg.Card.RevealLimit,
+		window:        cfg.Card.RevealWindow,
+		logger:        logger.WithService(lg, "card_reveal_service"),
+	}
+}
+
+// IssueRevealToken проверяет пароль пользователя и выдает одноразовый токен показа реквизитов карты
+func (s *cardRevealService) IssueRevealToken(ctx context.Context, userID, cardID int, password string) (*RevealToken, error) {
+	card, err := s.getCard(ctx, userID, cardID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Попытка расходует лимит до проверки пароля: неверный пароль тоже учитывается
+	now := s.clock.Now()
+	allowed, err := s.revealRepo.TakeAttempt(ctx, cardID, userID, s.limit, now.Add(-s.window), now)
+	if err != nil {
+		return nil, fmt.Errorf("failed to record card reveal attempt: %w", err)
+	}
+	if !allowed {
+		s.logger.Warn("Card reveal rate limit exceeded", "user_id", userID, "card_id", cardID)
+		s.audit(ctx, userID, domain.AuditActionCardRevealDenied, card, "rate_limited")
+		return nil, domain.ErrCardRevealRateLimited
+	}
+
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+	if err := utils.VerifyPassword(user.PasswordHash, password); err != nil {
+		logger.LogSecurityEvent(s.logger, "card_reveal_step_up_failed", "high", map[string]interface{}{
+			"user_id": userID,
+			"card_id": cardID,
+		})
+		s.audit(ctx, userID, domain.AuditActionCardRevealDenied, card, "invalid_password")
+		return nil, domain.ErrStepUpFailed
+	}
+
+	raw, err := utils.GenerateRandomKey(cardRevealTokenBytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate reveal token: %w", err)
+	}
+	token := hex.EncodeToString(raw)
+
+	revealToken := &domain.CardRevealToken{
+		CardID:    cardID,
+		UserID:    userID,
+		TokenHash: domain.HashCardRevealToken(token),
+		ExpiresAt: now.Add(s.tokenTTL),
+		CreatedAt: now,
+	}
+	if err := s.revealRepo.Create(ctx, revealToken); err != nil {
+		return nil, fmt.Errorf("failed to save reveal token: %w", err)
+	}
+
+	s.logger.Info("Card reveal token issued", "user_id", userID, "card_id", cardID, "expires_at", revealToken.ExpiresAt)
+	s.audit(ctx, userID, domain.AuditActionCardRevealToken, card, "")
+
+	return &RevealToken{Token: token, ExpiresAt: revealToken.ExpiresAt}, nil
+}
+
+// RevealCard погашает токен и возвращает номер и срок действия карты.
+// Токен погашается в одной транзакции с записью аудита: без записи реквизиты не показываются.
+func (s *cardRevealService) RevealCard(ctx context.Context, userID, cardID int, token string) (*CardData, error) {
+	card, err := s.getCard(ctx, userID, cardID)
+	if err != nil {
+		return nil, err
+	}
+
+	var data *CardData
+	err = s.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		if err := s.revealRepo.Consume(ctx, domain.HashCardRevealToken(token), cardID, userID, s.clock.Now()); err != nil {
+			return err
+		}
+
+		var err error
+		data, err = s.cardService.DecryptCardData(ctx, userID, card)
+		return err
+	})
+	if err != nil {
+		if errors.Is(err, domain.ErrCardRevealTokenInvalid) {
+			logger.LogSecurityEvent(s.logger, "card_reveal_invalid_token", "high", map[string]interface{}{
+				"user_id": userID,
+				"card_id": cardID,
+			})
+			return nil, err
+		}
+		s.logger.Error("Failed to reveal card details", "user_id", userID, "card_id", cardID, "error", err)
+		return nil, err
+	}
+
+	s.logger.Info("Card details revealed", "user_id", userID, "card_id", cardID)
+	return data, nil
+}
+
+// getCard проверяет, что пользователь — держатель карты или владелец счета, и что карта активна
+func (s *cardRevealService) getCard(ctx context.Context, userID, cardID int) (*domain.Card, error) {
+	if err := s.accessControl.CheckCardholder(ctx, userID, cardID); err != nil {
+		s.logger.Warn("Access denied for card reveal", "user_id", userID, "card_id", cardID)
+		if domain.IsAccessDeniedError(err) {
+			return nil, &ServiceError{Code: http.StatusForbidden, Message: err.Error()}
+		}
+		return nil, ErrCardNotFound
+	}
+
+	card, err := s.cardRepo.GetByID(ctx, cardID)
+	if err != nil {
+		return nil, ErrCardNotFound
+	}
+	if card.Status != domain.CardStatusActive {
+		return nil, ErrCardBlocked
+	}
+
+	return card, nil
+}
+
+func (s *cardRevealService) audit(ctx context.Context, userID int, action string, card *domain.Card, reason string) {
+	metadata := map[string]interface{}{"account_id": card.AccountID}
+	if reason != "" {
+		metadata["reason"] = reason
+	}
+	event := NewUserAuditEvent(userID, action, "card", auditResourceID(card.ID))
+	event.Metadata = domain.NewAuditState(metadata)
+	// Ошибка аудита уже залогирована и не влияет на ответ клиенту
+	_ = s.auditService.Record(ctx, event)
+}
@@ -0,0 +1,180 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/vterdunov/learn-bank-app/internal/config"
+	"github.com/vterdunov/learn-bank-app/internal/domain"
+	"github.com/vterdunov/learn-bank-app/internal/utils"
+)
+
+// MockCardRevealRepository хранит токены и попытки показа реквизитов в памяти
+type MockCardRevealRepository struct {
+	tokens   []*domain.CardRevealToken
+	attempts []time.Time
+}
+
+func (m *MockCardRevealRepository) Create(ctx context.Context, token *domain.CardRevealToken) error {
+	token.ID = int64(len(m.tokens) + 1)
+	m.tokens = append(m.tokens, token)
+	return nil
+}
+
+func (m *MockCardRevealRepository) TakeAttempt(ctx context.Context, cardID, userID, limit int, since, now time.Time) (bool, error) {
+	count := 0
+	for _, at := range m.attempts {
+		if !at.Before(since) {
+			count++
+		}
+	}
+	if limit > 0 && count >= limit {
+		return false, nil
+	}
+	m.attempts = append(m.attempts, now)
+	return true, nil
+}
+
+func (m *MockCardRevealRepository) Consume(ctx context.Context, tokenHash string, cardID, userID int, now time.Time) error {
+	for _, token := range m.tokens {
+		if token.TokenHash == tokenHash && token.CardID == cardID && token.UserID == userID &&
+			token.UsedAt == nil && token.ExpiresAt.After(now) {
+			token.UsedAt = &now
+			return nil
+		}
+	}
+	return domain.ErrCardRevealTokenInvalid
+}
+
+const testRevealPassword = "Secret123!"
+
+func setupCardRevealService(t *testing.T) (*cardRevealService, *cardHoldTestDeps, *MockCardRevealRepository) {
+	t.Helper()
+
+	cardSvc, deps := setupCardHoldService(t)
+	issueTestCard(t, cardSvc, deps.cards.cards[1], "7777001234567890", "03/28", "123")
+
+	passwordHash, err := utils.HashPassword(testRevealPassword)
+	if err != nil {
+		t.Fatalf("HashPassword failed: %v", err)
+	}
+	users := NewMockUserRepository()
+	user := &domain.User{ID: deps.userID, Username: "reveal", Email: "reveal@example.com", PasswordHash: passwordHash}
+	users.users[user.Email] = user
+	users.usersByID[user.ID] = user
+
+	cfg := &config.Config{
+		Card: config.CardConfig{RevealTokenTTL: time.Minute, RevealLimit: 3, RevealWindow: 24 * time.Hour},
+	}
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	auditService, _ := setupAuditService()
+	reveals := &MockCardRevealRepository{}
+
+	svc := NewCardRevealService(cfg, deps.cards, reveals, users, &mockStoreAccessControl{accounts: deps.accounts},
+		cardSvc, mockTxManager{}, auditService, deps.clock, logger)
+
+	return svc.(*cardRevealService), deps, reveals
+}
+
+func TestCardRevealService_RevealOnce(t *testing.T) {
+	svc, deps, reveals := setupCardRevealService(t)
+	ctx := context.Background()
+
+	if _, err := svc.IssueRevealToken(ctx, deps.userID, 1, "wrong-password"); !errors.Is(err, domain.ErrStepUpFailed) {
+		t.Fatalf("expected ErrStepUpFailed, got %v", err)
+	}
+	if len(reveals.tokens) != 0 {
+		t.Fatalf("token must not be issued without step-up")
+	}
+
+	token, err := svc.IssueRevealToken(ctx, deps.userID, 1, testRevealPassword)
+	if err != nil {
+		t.Fatalf("IssueRevealToken failed: %v", err)
+	}
+	if !token.ExpiresAt.Equal(deps.clock.now.Add(time.Minute)) {
+		t.Errorf("unexpected token expiry: %v", token.ExpiresAt)
+	}
+	if reveals.tokens[0].TokenHash == token.Token {
+		t.Errorf("token must be stored hashed")
+	}
+
+	data, err := svc.RevealCard(ctx, deps.userID, 1, token.Token)
+	if err != nil {
+		t.Fatalf("RevealCard failed: %v", err)
+	}
+	if data.Number != "7777001234567890" {
+		t.Errorf("unexpected card number: %s", data.Number)
+	}
+
+	if _, err := svc.RevealCard(ctx, deps.userID, 1, token.Token); !errors.Is(err, domain.ErrCardRevealTokenInvalid) {
+		t.Errorf("expected reused token to be rejected, got %v", err)
+	}
+}
+
+func TestCardRevealService_TokenExpires(t *testing.T) {
+	svc, deps, _ := setupCardRevealService(t)
+	ctx := context.Background()
+
+	token, err := svc.IssueRevealToken(ctx, deps.userID, 1, testRevealPassword)
+	if err != nil {
+		t.Fatalf("IssueRevealToken failed: %v", err)
+	}
+
+	deps.clock.now = deps.clock.now.Add(2 * time.Minute)
+	if _, err := svc.RevealCard(ctx, deps.userID, 1, token.Token); !errors.Is(err, domain.ErrCardRevealTokenInvalid) {
+		t.Errorf("expected expired token to be rejected, got %v", err)
+	}
+}
+
+func TestCardRevealService_RateLimit(t *testing.T) {
+	svc, deps, _ := setupCardRevealService(t)
+	ctx := context.Background()
+
+	for i := 0; i < 3; i++ {
+		if _, err := svc.IssueRevealToken(ctx, deps.userID, 1, testRevealPassword); err != nil {
+			t.Fatalf("IssueRevealToken %d failed: %v", i, err)
+		}
+	}
+	if _, err := svc.IssueRevealToken(ctx, deps.userID, 1, testRevealPassword); !errors.Is(err, domain.ErrCardRevealRateLimited) {
+		t.Fatalf("expected ErrCardRevealRateLimited, got %v", err)
+	}
+
+	deps.clock.now = deps.clock.now.Add(25 * time.Hour)
+	if _, err := svc.IssueRevealToken(ctx, deps.userID, 1, testRevealPassword); err != nil {
+		t.Errorf("expected limit to reset after window, got %v", err)
+	}
+}
+
+func TestCardRevealService_FailedStepUpCountsTowardLimit(t *testing.T) {
+	svc, deps, reveals := setupCardRevealService(t)
+	ctx := context.Background()
+
+	// Перебор пароля расходует тот же лимит, что и выдача токенов
+	for i := 0; i < 3; i++ {
+		if _, err := svc.IssueRevealToken(ctx, deps.userID, 1, "wrong-password"); !errors.Is(err, domain.ErrStepUpFailed) {
+			t.Fatalf("attempt %d: expected ErrStepUpFailed, got %v", i, err)
+		}
+	}
+	if _, err := svc.IssueRevealToken(ctx, deps.userID, 1, testRevealPassword); !errors.Is(err, domain.ErrCardRevealRateLimited) {
+		t.Errorf("expected ErrCardRevealRateLimited even with valid password, got %v", err)
+	}
+	if len(reveals.tokens) != 0 {
+		t.Errorf("expected no tokens issued, got %d", len(reveals.tokens))
+	}
+}
+
+func TestCardRevealService_NoLimit(t *testing.T) {
+	svc, deps, _ := setupCardRevealService(t)
+	ctx := context.Background()
+	svc.limit = 0
+
+	for i := 0; i < 10; i++ {
+		if _, err := svc.IssueRevealToken(ctx, deps.userID, 1, testRevealPassword); err != nil {
+			t.Fatalf("IssueRevealToken %d failed: %v", i, err)
+		}
+	}
+}
@@ -2,12 +2,16 @@ package service
 
 import (
 	"context"
+	"encoding/hex"
 	"errors"
 	"fmt"
 	"log/slog"
+	"math"
+	"net/http"
 	"strings"
 	"time"
 
+	"github.com/vterdunov/learn-bank-app/internal/config"
 	"github.com/vterdunov/learn-bank-app/internal/domain"
 	"github.com/vterdunov/learn-bank-app/internal/repository"
 	"github.com/vterdunov/learn-bank-app/internal/utils"
@@ -18,43 +22,142 @@ var (
 	ErrCardBlocked     = errors.New("card is blocked")
 	ErrCardExpired     = errors.New("card is expired")
 	ErrInvalidCardData = errors.New("invalid card data")
+	ErrInvalidCVV      = errors.New("invalid CVV")
+	// ErrCardExpiryMismatch срок действия в запросе не совпадает со сроком карты
+	ErrCardExpiryMismatch = errors.New("card expiry date mismatch")
+	// ErrCardVerificationFailed неверный номер, срок или CVV: клиенту не сообщается, что именно
+	ErrCardVerificationFailed = errors.New("card verification failed")
 )
 
+// defaultCVVMaxAttempts число неверных CVV подряд до блокировки карты, если лимит не задан
+const defaultCVVMaxAttempts = 3
+
+// defaultPINMaxAttempts число неверных PIN подряд до блокировки карты, если лимит не задан
+const defaultPINMaxAttempts = 3
+
+// pinSaltBytes длина соли карты для хеша PIN-блока
+const pinSaltBytes = 16
+
+// gatewayOperatorID оператор операций эквайера, пришедших из шлюза: они записываются в аудит от имени системы
+const gatewayOperatorID = 0
+
 // cardService реализует интерфейс CardService
 type cardService struct {
-	cardRepo        repository.CardRepository
-	accountRepo     repository.AccountRepository
-	transactionRepo repository.TransactionRepository
-	logger          *slog.Logger
-	encryptionKey   []byte
+	cardRepo            repository.CardRepository
+	accountRepo         repository.AccountRepository
+	transactionRepo     repository.TransactionRepository
+	holdRepo            repository.HoldRepository
+	merchantRepo        repository.MerchantRepository
+	accessControl       domain.AccessControlService
+	txManager           repository.TxManager
+	notificationService NotificationService
+	cashbackService     CashbackService
+	fraudService        FraudService
+	kycService          KYCService
+	auditService        AuditService
+	clock               utils.Clock
+	holdTTL             time.Duration
+	cvvMaxAttempts      int
+	pinMaxAttempts      int
+	interchangeFee      float64
+	logger              *slog.Logger
+	encryptionKey       []byte
+	panHashKey          []byte
 }
 
 // NewCardService создает новый экземпляр сервиса карт
 func NewCardService(
+	cfg *config.Config,
 	cardRepo repository.CardRepository,
 	accountRepo repository.AccountRepository,
 	transactionRepo repository.TransactionRepository,
+	holdRepo repository.HoldRepository,
+	merchantRepo repository.MerchantRepository,
+	accessControl domain.AccessControlService,
+	txManager repository.TxManager,
+	notificationService NotificationService,
+	cashbackService CashbackService,
+	fraudService FraudService,
+	kycService KYCService,
+	auditService AuditService,
+	clock utils.Clock,
 	logger *slog.Logger,
 ) CardService {
-	// Генерируем ключ шифрования (в продакшене должен браться из конфигурации)
-	key, err := utils.GenerateRandomKey(32)
-	if err != nil {
-		logger.Error("Failed to generate encryption key", "error", err)
-		// Fallback на фиксированный ключ для тестирования
-		key = []byte("test-encryption-key-32-bytes-!!")
+	// Ключ шифрования берется из конфигурации; без него генерируется на время работы процесса
+	key, err := hex.DecodeString(cfg.Card.EncryptionKey)
+	if err != nil || len(key) < 32 {
+		if cfg.Card.EncryptionKey != "" {
+			logger.Error("Invalid card encryption key, generating a temporary one")
+		} else {
+			logger.Warn("Card encryption key is not configured, generating a temporary one")
+		}
+		key, err = utils.GenerateRandomKey(32)
+		if err != nil {
+			logger.Error("Failed to generate encryption key", "error", err)
+			// Fallback на фиксированный ключ для тестирования
+			key = []byte("test-encryption-key-32-bytes-!!")
+		}
+	}
+
+	cvvMaxAttempts := cfg.Card.CVVMaxAttempts
+	if cvvMaxAttempts <= 0 {
+		cvvMaxAttempts = defaultCVVMaxAttempts
+	}
+
+	pinMaxAttempts := cfg.Card.PINMaxAttempts
+	if pinMaxAttempts <= 0 {
+		pinMaxAttempts = defaultPINMaxAttempts
 	}
 
 	return &cardService{
-		cardRepo:        cardRepo,
-		accountRepo:     accountRepo,
-		transactionRepo: transactionRepo,
-		logger:          logger,
-		encryptionKey:   key,
+		cardRepo:            cardRepo,
+		accountRepo:         accountRepo,
+		transactionRepo:     transactionRepo,
+		holdRepo:            holdRepo,
+		merchantRepo:        merchantRepo,
+		accessControl:       accessControl,
+		txManager:           txManager,
+		notificationService: notificationService,
+		cashbackService:     cashbackService,
+		fraudService:        fraudService,
+		kycService:          kycService,
+		auditService:        auditService,
+		clock:               clock,
+		holdTTL:             cfg.Card.HoldTTL,
+		cvvMaxAttempts:      cvvMaxAttempts,
+		pinMaxAttempts:      pinMaxAttempts,
+		interchangeFee:      cfg.Merchant.InterchangeFeePercent,
+		logger:              logger,
+		encryptionKey:       key,
+		// Ключ поиска по номеру выводится из ключа шифрования, чтобы HMAC номера не совпадал с HMAC шифротекста
+		panHashKey: []byte(utils.ComputeHMAC("card-pan-hash", key)),
 	}
 }
 
-// CreateCard создает новую банковскую карту
-func (s *cardService) CreateCard(ctx context.Context, userID, accountID int) (*domain.Card, error) {
+// cardPrefixes BIN номеров карт по типу карты
+var cardPrefixes = map[string]string{
+	domain.CardTypePhysical:  utils.LearnBankPrefix,
+	domain.CardTypeVirtual:   utils.LearnBankVirtualPrefix,
+	domain.CardTypeSingleUse: utils.LearnBankSingleUsePrefix,
+}
+
+// CreateCard создает новую банковскую карту: физическую, виртуальную или одноразовую.
+// Карта активна сразу после выпуска; срок действия можно сократить, а платежи — ограничить одним ТСП.
+// Выпускать карты может владелец или совладелец с правом управления; держателем карты становится выпустивший ее.
+func (s *cardService) CreateCard(ctx context.Context, userID int, req domain.CreateCardRequest) (*domain.Card, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+	accountID := req.AccountID
+
+	if err := s.accessControl.CheckAccountPermission(ctx, userID, accountID, domain.AccountPermissionManage); err != nil {
+		s.logger.Warn("Access denied for card creation", "user_id", userID, "account_id", accountID)
+		if domain.IsAccessDeniedError(err) {
+			return nil, &ServiceError{Code: http.StatusForbidden, Message: err.Error()}
+		}
+		return nil, ErrAccountNotFound
+	}
+
 	// Проверяем существование счета
 	account, err := s.accountRepo.GetByID(ctx, accountID)
 	if err != nil {
@@ -68,8 +171,8 @@ func (s *cardService) CreateCard(ctx context.Context, userID, accountID int) (*d
 		return nil, ErrAccountBlocked
 	}
 
-	// Генерируем номер карты по алгоритму Луна
-	cardNumber, err := utils.GenerateCardNumber()
+	// Генерируем номер карты по алгоритму Луна из BIN типа карты
+	cardNumber, err := utils.GenerateCardNumber(cardPrefixes[req.CardType])
 	if err != nil {
 		s.logger.Error("Failed to generate card number", "account_id", accountID, "error", err)
 		return nil, fmt.Errorf("failed to generate card number: %w", err)
@@ -89,13 +192,24 @@ func (s *cardService) CreateCard(ctx context.Context, userID, accountID int) (*d
 		return nil, fmt.Errorf("failed to hash CVV: %w", err)
 	}
 
-	// Генерируем срок действия карты
-	expiryStr := utils.GenerateExpiryDate()
-	expiryDate, err := time.Parse("01/06", expiryStr)
-	if err != nil {
-		s.logger.Error("Failed to parse generated expiry date", "expiry", expiryStr, "error", err)
-		// Fallback - 4 года от текущей даты
-		expiryDate = time.Now().AddDate(4, 0, 0)
+	// Генерируем срок действия карты: короткий срок действует до конца дня, на карте печатается его месяц
+	validityDays := req.ValidityDays
+	if validityDays == 0 && req.CardType == domain.CardTypeSingleUse {
+		validityDays = domain.SingleUseCardValidityDays
+	}
+	var expiryStr string
+	var expiryDate time.Time
+	if validityDays > 0 {
+		expiryDate = s.clock.Now().AddDate(0, 0, validityDays)
+		expiryStr = expiryDate.Format("01/06")
+	} else {
+		expiryStr = utils.GenerateExpiryDate()
+		expiryDate, err = time.Parse("01/06", expiryStr)
+		if err != nil {
+			s.logger.Error("Failed to parse generated expiry date", "expiry", expiryStr, "error", err)
+			// Fallback - 4 года от текущей даты
+			expiryDate = time.Now().AddDate(4, 0, 0)
+		}
 	}
 
 	// Шифруем данные карты
@@ -105,6 +219,16 @@ func (s *cardService) CreateCard(ctx context.Context, userID, accountID int) (*d
 		return nil, fmt.Errorf("failed to encrypt card data: %w", err)
 	}
 
+	// PIN при выпуске необязателен: без него карту нельзя использовать в банкомате до SetPIN
+	var pinHash, pinSalt string
+	if req.PIN != "" {
+		pinHash, pinSalt, err = hashPIN(req.PIN, cardNumber)
+		if err != nil {
+			s.logger.Error("Failed to hash PIN block", "account_id", accountID, "error", err)
+			return nil, err
+		}
+	}
+
 	// Объединяем зашифрованные данные в одну строку для хранения
 	encryptedDataStr := fmt.Sprintf("%x:%s", encryptedNumber.Data, encryptedNumber.HMAC)
 	hmacStr := fmt.Sprintf("%x:%s", encryptedExpiry.Data, encryptedExpiry.HMAC)
@@ -112,11 +236,17 @@ func (s *cardService) CreateCard(ctx context.Context, userID, accountID int) (*d
 	// Создаем карту
 	card := &domain.Card{
 		AccountID:     accountID,
+		HolderID:      userID,
 		EncryptedData: encryptedDataStr,
 		HMAC:          hmacStr,
 		CVVHash:       cvvHash,
+		PANHash:       s.panHash(cardNumber),
+		PINHash:       pinHash,
+		PINSalt:       pinSalt,
 		ExpiryDate:    expiryDate,
 		Status:        "active",
+		CardType:      req.CardType,
+		MerchantLock:  req.MerchantLock,
 		CreatedAt:     time.Now(),
 		UpdatedAt:     time.Now(),
 	}
@@ -130,13 +260,22 @@ func (s *cardService) CreateCard(ctx context.Context, userID, accountID int) (*d
 		"card_id", card.ID,
 		"account_id", accountID,
 		"expiry_date", card.ExpiryDate,
-		"card_type", utils.GetCardType(cardNumber))
+		"card_type", card.CardType,
+		"merchant_lock", card.MerchantLock)
 
 	return card, nil
 }
 
 // GetAccountCards возвращает все карты счета
 func (s *cardService) GetAccountCards(ctx context.Context, userID, accountID int) ([]*domain.Card, error) {
+	if err := s.accessControl.CheckAccountPermission(ctx, userID, accountID, domain.AccountPermissionView); err != nil {
+		s.logger.Warn("Access denied for account cards", "user_id", userID, "account_id", accountID)
+		if domain.IsAccessDeniedError(err) {
+			return nil, &ServiceError{Code: http.StatusForbidden, Message: err.Error()}
+		}
+		return nil, ErrAccountNotFound
+	}
+
 	cards, err := s.cardRepo.GetByAccountID(ctx, accountID)
 	if err != nil {
 		s.logger.Error("Failed to get account cards", "account_id", accountID, "error", err)
@@ -147,20 +286,49 @@ func (s *cardService) GetAccountCards(ctx context.Context, userID, accountID int
 	return cards, nil
 }
 
-// DecryptCardData расшифровывает данные карты
+// DecryptCardData расшифровывает данные карты. Реквизиты доступны только держателю карты и владельцу счета
 func (s *cardService) DecryptCardData(ctx context.Context, userID int, card *domain.Card) (*CardData, error) {
+	if err := s.checkCardholder(ctx, userID, card.ID); err != nil {
+		return nil, err
+	}
+
+	cardNumber, expiryDate, err := s.decryptCard(card)
+	if err != nil {
+		return nil, err
+	}
+
+	// Раскрытие данных карты без записи в аудит не допускается
+	event := NewUserAuditEvent(userID, domain.AuditActionCardDecrypt, "card", auditResourceID(card.ID))
+	event.Metadata = domain.NewAuditState(map[string]interface{}{
+		"account_id": card.AccountID,
+	})
+	if err := s.auditService.Record(ctx, event); err != nil {
+		return nil, err
+	}
+
+	cardData := &CardData{
+		Number:     cardNumber,
+		ExpiryDate: expiryDate,
+	}
+
+	s.logger.Debug("Card data decrypted successfully", "card_id", card.ID)
+	return cardData, nil
+}
+
+// decryptCard расшифровывает номер и срок действия карты без записи в аудит
+func (s *cardService) decryptCard(card *domain.Card) (string, time.Time, error) {
 	// Парсим зашифрованные данные номера карты
 	numberParts := strings.Split(card.EncryptedData, ":")
 	if len(numberParts) != 2 {
 		s.logger.Error("Invalid encrypted card number format", "card_id", card.ID)
-		return nil, ErrInvalidCardData
+		return "", time.Time{}, ErrInvalidCardData
 	}
 
 	// Парсим зашифрованные данные даты истечения
 	expiryParts := strings.Split(card.HMAC, ":")
 	if len(expiryParts) != 2 {
 		s.logger.Error("Invalid encrypted expiry date format", "card_id", card.ID)
-		return nil, ErrInvalidCardData
+		return "", time.Time{}, ErrInvalidCardData
 	}
 
 	// Воссоздаем EncryptedData структуры
@@ -169,13 +337,13 @@ func (s *cardService) DecryptCardData(ctx context.Context, userID int, card *dom
 	// Декодируем hex данные
 	if _, err := fmt.Sscanf(numberParts[0], "%x", &encryptedNumber.Data); err != nil {
 		s.logger.Error("Failed to decode card number data", "card_id", card.ID, "error", err)
-		return nil, ErrInvalidCardData
+		return "", time.Time{}, ErrInvalidCardData
 	}
 	encryptedNumber.HMAC = numberParts[1]
 
 	if _, err := fmt.Sscanf(expiryParts[0], "%x", &encryptedExpiry.Data); err != nil {
 		s.logger.Error("Failed to decode expiry date data", "card_id", card.ID, "error", err)
-		return nil, ErrInvalidCardData
+		return "", time.Time{}, ErrInvalidCardData
 	}
 	encryptedExpiry.HMAC = expiryParts[1]
 
@@ -183,27 +351,166 @@ func (s *cardService) DecryptCardData(ctx context.Context, userID int, card *dom
 	cardNumber, expiryStr, err := utils.DecryptCardData(&encryptedNumber, &encryptedExpiry, s.encryptionKey)
 	if err != nil {
 		s.logger.Error("Failed to decrypt card data", "card_id", card.ID, "error", err)
-		return nil, fmt.Errorf("failed to decrypt card data: %w", err)
+		return "", time.Time{}, fmt.Errorf("failed to decrypt card data: %w", err)
 	}
 
 	// Парсим дату истечения
 	expiryDate, err := time.Parse("01/06", expiryStr)
 	if err != nil {
 		s.logger.Error("Failed to parse expiry date", "card_id", card.ID, "expiry", expiryStr, "error", err)
-		return nil, fmt.Errorf("failed to parse expiry date: %w", err)
+		return "", time.Time{}, fmt.Errorf("failed to parse expiry date: %w", err)
 	}
 
-	cardData := &CardData{
-		Number:     cardNumber,
-		ExpiryDate: expiryDate,
+	return cardNumber, expiryDate, nil
+}
+
+// VerifyCardholder находит карту по номеру и проверяет срок действия (ММ/ГГ) и CVV.
+// Неверные CVV подряд считаются, после cvvMaxAttempts карта блокируется.
+func (s *cardService) VerifyCardholder(ctx context.Context, pan, expiry, cvv string) (*domain.Card, error) {
+	if err := utils.ValidateExpiryDateCard(expiry); err != nil {
+		if errors.Is(err, utils.ErrCardExpired) {
+			return nil, ErrCardExpired
+		}
+		return nil, ErrCardExpiryMismatch
 	}
 
-	s.logger.Debug("Card data decrypted successfully", "card_id", card.ID)
-	return cardData, nil
+	card, err := s.cardRepo.GetByPANHash(ctx, s.panHash(pan))
+	if err != nil {
+		s.logger.Warn("Card not found by number")
+		return nil, ErrCardNotFound
+	}
+
+	// Заблокированная карта не проверяет CVV: перебор после блокировки бесполезен
+	if card.Status != domain.CardStatusActive {
+		s.logger.Warn("Card is not active", "card_id", card.ID, "status", card.Status)
+		return nil, ErrCardBlocked
+	}
+
+	_, expiryDate, err := s.decryptCard(card)
+	if err != nil {
+		return nil, err
+	}
+	if expiry != expiryDate.Format("01/06") {
+		s.logger.Warn("Card expiry mismatch", "card_id", card.ID)
+		return nil, ErrCardExpiryMismatch
+	}
+
+	if err := utils.VerifyCVV(card.CVVHash, cvv); err != nil {
+		s.recordCVVFailure(ctx, card)
+		return nil, ErrInvalidCVV
+	}
+
+	if card.CVVFailedAttempts > 0 {
+		if err := s.cardRepo.ResetCVVFailures(ctx, card.ID); err != nil {
+			s.logger.Error("Failed to reset CVV attempts", "card_id", card.ID, "error", err)
+		}
+		card.CVVFailedAttempts = 0
+	}
+
+	return card, nil
 }
 
-// ProcessPayment обрабатывает платеж с карты
-func (s *cardService) ProcessPayment(ctx context.Context, userID, cardID int, amount float64) error {
+// PayByCardDetails оплачивает по реквизитам карты: номер, срок действия и CVV.
+// Платеж списывается со счета карты так же, как ProcessPayment.
+func (s *cardService) PayByCardDetails(ctx context.Context, userID int, req CardDetailsPaymentRequest) error {
+	card, err := s.VerifyCardholder(ctx, req.Number, req.ExpiryDate, req.CVV)
+	if err != nil {
+		s.logger.Warn("Card details verification failed", "user_id", userID, "error", err)
+		if errors.Is(err, ErrCardNotFound) || errors.Is(err, ErrCardExpiryMismatch) || errors.Is(err, ErrInvalidCVV) {
+			return ErrCardVerificationFailed
+		}
+		return err
+	}
+
+	// Реквизиты карты подтверждают право на платеж вместо проверки доступа к карте
+	if err := s.processPayment(ctx, card.ID, req.Amount, req.MerchantID); err != nil {
+		return err
+	}
+
+	s.logger.Info("Card-not-present payment processed",
+		"card_id", card.ID,
+		"user_id", userID,
+		"merchant_id", req.MerchantID,
+		"amount", req.Amount)
+
+	return nil
+}
+
+// BackfillPANHashes заполняет хеши номеров карт, выпущенных до появления поиска по номеру
+func (s *cardService) BackfillPANHashes(ctx context.Context) (int, error) {
+	cards, err := s.cardRepo.ListWithoutPANHash(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to list cards without PAN hash: %w", err)
+	}
+
+	filled := 0
+	for _, card := range cards {
+		cardNumber, _, err := s.decryptCard(card)
+		if err != nil {
+			// Карта зашифрована другим ключом: найти ее по номеру нельзя
+			continue
+		}
+		if err := s.cardRepo.SetPANHash(ctx, card.ID, s.panHash(cardNumber)); err != nil {
+			s.logger.Error("Failed to save card PAN hash", "card_id", card.ID, "error", err)
+			continue
+		}
+		filled++
+	}
+
+	return filled, nil
+}
+
+// recordCVVFailure увеличивает счетчик неверных CVV; блокировка карты записывается в аудит
+func (s *cardService) recordCVVFailure(ctx context.Context, card *domain.Card) {
+	attempts, status, err := s.cardRepo.RecordCVVFailure(ctx, card.ID, s.cvvMaxAttempts)
+	if err != nil {
+		s.logger.Error("Failed to record CVV failure", "card_id", card.ID, "error", err)
+		return
+	}
+	s.logger.Warn("Card CVV verification failed", "card_id", card.ID, "attempts", attempts)
+
+	if status != domain.CardStatusBlocked {
+		return
+	}
+	s.logger.Warn("Card blocked after failed CVV attempts", "card_id", card.ID, "attempts", attempts)
+
+	event := &domain.AuditEvent{
+		ActorType:    domain.AuditActorSystem,
+		Action:       domain.AuditActionCardBlock,
+		ResourceType: "card",
+		ResourceID:   auditResourceID(card.ID),
+		Metadata: domain.NewAuditState(map[string]interface{}{
+			"account_id": card.AccountID,
+			"reason":     "cvv_attempts",
+			"attempts":   attempts,
+		}),
+	}
+	// Ошибка аудита уже залогирована, карта заблокирована
+	_ = s.auditService.Record(ctx, event)
+}
+
+// panHash вычисляет HMAC номера карты для поиска
+func (s *cardService) panHash(pan string) string {
+	return utils.ComputeHMAC(pan, s.panHashKey)
+}
+
+// ProcessPayment обрабатывает одностадийный платеж с карты: средства списываются сразу, без холда.
+// Платеж в зарегистрированное ТСП сразу зачисляется на его расчетный счет за вычетом комиссии.
+func (s *cardService) ProcessPayment(ctx context.Context, userID, cardID int, amount float64, merchantID string) error {
+	if err := s.accessControl.CanAccessCard(ctx, userID, cardID); err != nil {
+		s.logger.Warn("Access denied for card payment", "user_id", userID, "card_id", cardID)
+		if domain.IsAccessDeniedError(err) {
+			return &ServiceError{Code: http.StatusForbidden, Message: err.Error()}
+		}
+		return ErrCardNotFound
+	}
+
+	return s.processPayment(ctx, cardID, amount, merchantID)
+}
+
+// processPayment списывает платеж с карты после проверки права на операцию.
+// Лимиты и антифрод проверяются по владельцу счета: держатель дополнительной карты платит его деньгами
+func (s *cardService) processPayment(ctx context.Context, cardID int, amount float64, merchantID string) error {
 	// Валидация суммы
 	if amount <= 0 {
 		s.logger.Warn("Invalid payment amount", "card_id", cardID, "amount", amount)
@@ -224,60 +531,981 @@ func (s *cardService) ProcessPayment(ctx context.Context, userID, cardID int, am
 	}
 
 	// Проверяем срок действия карты
-	if time.Now().After(card.ExpiryDate) {
+	if s.clock.Now().After(card.ExpiryDate) {
 		s.logger.Warn("Card is expired", "card_id", cardID, "expiry_date", card.ExpiryDate)
 		return ErrCardExpired
 	}
 
+	if !card.AllowsMerchant(merchantID) {
+		s.logger.Warn("Card is locked to another merchant", "card_id", cardID, "merchant_id", merchantID)
+		return domain.ErrCardMerchantLocked
+	}
+
 	// Получаем счет карты
 	account, err := s.accountRepo.GetByID(ctx, card.AccountID)
 	if err != nil {
 		s.logger.Error("Account not found for card payment", "card_id", cardID, "account_id", card.AccountID, "error", err)
 		return ErrAccountNotFound
 	}
+	if account.Status != domain.AccountStatusActive {
+		s.logger.Warn("Account is not active", "account_id", account.ID, "status", account.Status)
+		return ErrAccountBlocked
+	}
 
 	// Проверяем достаточность средств
-	if account.Balance < amount {
+	if account.AvailableBalance() < amount {
 		s.logger.Warn("Insufficient funds for card payment",
 			"card_id", cardID,
 			"account_id", card.AccountID,
-			"balance", account.Balance,
+			"available", account.AvailableBalance(),
 			"amount", amount)
 		return ErrInsufficientFunds
 	}
 
-	// Списываем средства со счета
-	newBalance := account.Balance - amount
-	if err := s.accountRepo.UpdateBalance(ctx, card.AccountID, newBalance); err != nil {
-		s.logger.Error("Failed to update balance for card payment", "card_id", cardID, "error", err)
-		return fmt.Errorf("failed to update balance: %w", err)
+	merchant, err := s.resolveMerchant(ctx, merchantID, card.AccountID)
+	if err != nil {
+		return err
 	}
 
-	// Создаем запись о транзакции
-	transaction := &domain.Transaction{
-		FromAccount: &card.AccountID,
-		ToAccount:   nil, // Платеж во внешнюю систему
-		Amount:      amount,
-		Type:        "payment",
-		Status:      "completed",
-		Description: fmt.Sprintf("Card payment (Card ID: %d)", cardID),
-		CreatedAt:   time.Now(),
-		UpdatedAt:   time.Now(),
+	// Лимиты операций по уровню идентификации клиента
+	if err := s.kycService.CheckOperationLimit(ctx, account.UserID, amount); err != nil {
+		return err
 	}
 
-	if err := s.transactionRepo.Create(ctx, transaction); err != nil {
-		s.logger.Error("Failed to create transaction record for card payment",
-			"card_id", cardID,
-			"amount", amount,
-			"error", err)
-		// Не возвращаем ошибку, так как платеж уже выполнен
+	// Антифрод, списание, запись транзакции, зачисление ТСП и уведомление владельца в одной транзакции БД
+	err = s.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		if err := s.screenCardPayment(ctx, account.UserID, card, merchantID, amount); err != nil {
+			return err
+		}
+
+		// Доступный остаток перепроверяется в том же запросе, что и списание
+		if err := s.accountRepo.Debit(ctx, card.AccountID, amount); err != nil {
+			if errors.Is(err, domain.ErrAccountInsufficientFunds) {
+				return ErrInsufficientFunds
+			}
+			s.logger.Error("Failed to update balance for card payment", "card_id", cardID, "error", err)
+			return fmt.Errorf("failed to update balance: %w", err)
+		}
+
+		// Создаем запись о транзакции
+		transaction := &domain.Transaction{
+			FromAccount: &card.AccountID,
+			ToAccount:   nil, // Платеж во внешнюю систему
+			Amount:      amount,
+			Type:        "payment",
+			Status:      "completed",
+			Description: fmt.Sprintf("Card payment (Card ID: %d)", cardID),
+			CreatedAt:   time.Now(),
+			UpdatedAt:   time.Now(),
+		}
+		setPaymentMerchant(transaction, merchant)
+
+		if err := s.transactionRepo.Create(ctx, transaction); err != nil {
+			s.logger.Error("Failed to create transaction record for card payment",
+				"card_id", cardID,
+				"amount", amount,
+				"error", err)
+			return fmt.Errorf("failed to create transaction record: %w", err)
+		}
+
+		if merchant != nil {
+			if err := s.creditMerchant(ctx, transaction); err != nil {
+				s.logger.Error("Failed to credit merchant for card payment", "card_id", cardID, "merchant_id", merchant.ID, "error", err)
+				return err
+			}
+		}
+
+		if err := s.cashbackService.AccruePayment(ctx, cardID, transaction); err != nil {
+			s.logger.Error("Failed to accrue cashback for card payment", "card_id", cardID, "error", err)
+			return err
+		}
+
+		if err := s.closeSingleUseCard(ctx, card); err != nil {
+			return err
+		}
+
+		// Уведомляем владельца счета
+		if err := s.notificationService.NotifyCardPayment(ctx, account.UserID, cardID, amount); err != nil {
+			s.logger.Error("Failed to notify about card payment", "card_id", cardID, "error", err)
+			return fmt.Errorf("failed to notify about card payment: %w", err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return err
 	}
 
 	s.logger.Info("Card payment processed successfully",
 		"card_id", cardID,
 		"account_id", card.AccountID,
+		"amount", amount)
+
+	return nil
+}
+
+// AuthorizePayment авторизует платеж картой по запросу держателя: блокирует сумму на счете (холд)
+// и создает платеж в статусе pending. Держатель может сам отменить только такую авторизацию (CancelAuthorization).
+// Учетный остаток не меняется до списания (CapturePayment); не списанный в срок холд снимает ExpireHolds.
+func (s *cardService) AuthorizePayment(ctx context.Context, userID, cardID int, amount float64, merchantID, description string) (*domain.CardHold, error) {
+	if err := s.accessControl.CanAccessCard(ctx, userID, cardID); err != nil {
+		s.logger.Warn("Access denied for card authorization", "user_id", userID, "card_id", cardID)
+		if domain.IsAccessDeniedError(err) {
+			return nil, &ServiceError{Code: http.StatusForbidden, Message: err.Error()}
+		}
+		return nil, ErrCardNotFound
+	}
+
+	return s.authorizePayment(ctx, &userID, cardID, amount, merchantID, description)
+}
+
+// AuthorizeGatewayPayment авторизует платеж из шлюза эквайринга: держатель уже подтвержден реквизитами карты
+func (s *cardService) AuthorizeGatewayPayment(ctx context.Context, cardID int, amount float64, merchantID, description string) (*domain.CardHold, error) {
+	return s.authorizePayment(ctx, nil, cardID, amount, merchantID, description)
+}
+
+// authorizePayment создает холд; initiatedBy — пользователь API, nil для шлюза.
+// Лимиты KYC проверяются для владельца счета карты.
+func (s *cardService) authorizePayment(ctx context.Context, initiatedBy *int, cardID int, amount float64, merchantID, description string) (*domain.CardHold, error) {
+	amount = math.Round(amount*100) / 100
+	if amount <= 0 {
+		s.logger.Warn("Invalid authorization amount", "card_id", cardID, "amount", amount)
+		return nil, ErrInvalidAmount
+	}
+	merchantID = strings.TrimSpace(merchantID)
+	if merchantID == "" {
+		return nil, domain.ErrInvalidMerchantID
+	}
+
+	card, err := s.cardRepo.GetByID(ctx, cardID)
+	if err != nil {
+		s.logger.Error("Card not found for authorization", "card_id", cardID, "error", err)
+		return nil, ErrCardNotFound
+	}
+	if card.Status != "active" {
+		s.logger.Warn("Card is not active", "card_id", cardID, "status", card.Status)
+		return nil, ErrCardBlocked
+	}
+
+	now := s.clock.Now()
+	if now.After(card.ExpiryDate) {
+		s.logger.Warn("Card is expired", "card_id", cardID, "expiry_date", card.ExpiryDate)
+		return nil, ErrCardExpired
+	}
+	if !card.AllowsMerchant(merchantID) {
+		s.logger.Warn("Card is locked to another merchant", "card_id", cardID, "merchant_id", merchantID)
+		return nil, domain.ErrCardMerchantLocked
+	}
+
+	account, err := s.accountRepo.GetByID(ctx, card.AccountID)
+	if err != nil {
+		s.logger.Error("Account not found for card authorization", "card_id", cardID, "account_id", card.AccountID, "error", err)
+		return nil, ErrAccountNotFound
+	}
+	if account.Status != domain.AccountStatusActive {
+		s.logger.Warn("Account is not active", "account_id", account.ID, "status", account.Status)
+		return nil, ErrAccountBlocked
+	}
+
+	merchant, err := s.resolveMerchant(ctx, merchantID, card.AccountID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Лимиты операций по уровню идентификации клиента
+	if err := s.kycService.CheckOperationLimit(ctx, account.UserID, amount); err != nil {
+		return nil, err
+	}
+
+	if description == "" {
+		description = fmt.Sprintf("Card payment (Card ID: %d)", cardID)
+	}
+
+	hold := &domain.CardHold{
+		CardID:      cardID,
+		AccountID:   card.AccountID,
+		MerchantID:  merchantID,
+		Description: description,
+		Amount:      amount,
+		Status:      domain.CardHoldStatusAuthorized,
+		InitiatedBy: initiatedBy,
+		ExpiresAt:   now.Add(s.holdTTL),
+	}
+
+	// Антифрод, блокировка средств, платеж, холд и уведомление владельца в одной транзакции БД
+	err = s.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		if err := s.screenCardPayment(ctx, account.UserID, card, merchantID, amount); err != nil {
+			return err
+		}
+
+		if err := s.holdRepo.ReserveFunds(ctx, card.AccountID, amount); err != nil {
+			if errors.Is(err, domain.ErrHoldInsufficientFunds) {
+				return ErrInsufficientFunds
+			}
+			return fmt.Errorf("failed to reserve funds: %w", err)
+		}
+
+		transaction := &domain.Transaction{
+			FromAccount: &card.AccountID,
+			ToAccount:   nil, // Платеж во внешнюю систему
+			Amount:      amount,
+			Type:        domain.TransactionTypePayment,
+			Status:      domain.TransactionStatusPending,
+			Description: description,
+			CreatedAt:   now,
+			UpdatedAt:   now,
+		}
+		setPaymentMerchant(transaction, merchant)
+		if err := s.transactionRepo.Create(ctx, transaction); err != nil {
+			return fmt.Errorf("failed to create transaction record: %w", err)
+		}
+
+		hold.TransactionID = transaction.ID
+		if err := s.holdRepo.Create(ctx, hold); err != nil {
+			return fmt.Errorf("failed to create card hold: %w", err)
+		}
+
+		if err := s.notificationService.NotifyCardPayment(ctx, account.UserID, cardID, amount); err != nil {
+			return fmt.Errorf("failed to notify about card payment: %w", err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		if errors.Is(err, ErrInsufficientFunds) {
+			s.logger.Warn("Insufficient funds for card authorization",
+				"card_id", cardID,
+				"account_id", card.AccountID,
+				"available", account.AvailableBalance(),
+				"amount", amount)
+			return nil, err
+		}
+		if !isScreeningError(err) {
+			s.logger.Error("Failed to authorize card payment", "card_id", cardID, "amount", amount, "error", err)
+		}
+		return nil, err
+	}
+
+	s.logger.Info("Card payment authorized",
+		"hold_id", hold.ID,
+		"card_id", cardID,
+		"account_id", card.AccountID,
+		"merchant_id", merchantID,
 		"amount", amount,
-		"new_balance", newBalance)
+		"expires_at", hold.ExpiresAt)
+
+	return hold, nil
+}
+
+// screenCardPayment проверяет платеж картой правилами антифрода от имени владельца счета.
+// Вызывается внутри транзакции платежа: одобрение оператора погашается вместе с ним
+func (s *cardService) screenCardPayment(ctx context.Context, ownerID int, card *domain.Card, merchantID string, amount float64) error {
+	return s.fraudService.Screen(ctx, &domain.FraudOperation{
+		Type:       domain.FraudOperationCardPayment,
+		UserID:     ownerID,
+		AccountID:  card.AccountID,
+		CardID:     &card.ID,
+		MerchantID: merchantID,
+		Amount:     amount,
+	})
+}
+
+// CapturePayment списывает авторизованный платеж полностью (amount = 0) или частично.
+// Остаток авторизации при частичном списании разблокируется. Это операция эквайера:
+// ее выполняет шлюз (operatorID = gatewayOperatorID) или оператор, но не держатель карты.
+func (s *cardService) CapturePayment(ctx context.Context, operatorID, holdID int, amount float64) (*domain.CardHold, error) {
+	var hold *domain.CardHold
+	err := s.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		var err error
+		hold, err = s.holdRepo.GetByIDForUpdate(ctx, holdID)
+		if err != nil {
+			return err
+		}
+
+		if err := hold.Capture(amount, s.clock.Now()); err != nil {
+			return err
+		}
+		if err := s.holdRepo.CaptureFunds(ctx, hold.AccountID, hold.Amount, hold.CapturedAmount); err != nil {
+			return fmt.Errorf("failed to capture funds: %w", err)
+		}
+		if err := s.holdRepo.Update(ctx, hold); err != nil {
+			return fmt.Errorf("failed to update card hold: %w", err)
+		}
+
+		payment, err := s.updateHoldTransaction(ctx, hold.TransactionID, domain.TransactionStatusCompleted, hold.CapturedAmount)
+		if err != nil {
+			return err
+		}
+		if payment.MerchantID != nil {
+			if err := s.creditMerchant(ctx, payment); err != nil {
+				return err
+			}
+		}
+
+		if err := s.cashbackService.AccruePayment(ctx, hold.CardID, payment); err != nil {
+			return err
+		}
+
+		card, err := s.cardRepo.GetByID(ctx, hold.CardID)
+		if err != nil {
+			return fmt.Errorf("failed to get card: %w", err)
+		}
+		// Списание уже авторизованной суммы не отклоняется, если карту закрыло предыдущее списание
+		if card.Status == domain.CardStatusCancelled {
+			return nil
+		}
+		return s.closeSingleUseCard(ctx, card)
+	})
+	if err != nil {
+		s.logHoldError("capture", holdID, err)
+		return nil, err
+	}
+
+	s.logger.Info("Card payment captured",
+		"hold_id", hold.ID,
+		"account_id", hold.AccountID,
+		"authorized", hold.Amount,
+		"captured", hold.CapturedAmount)
+
+	event := acquirerAuditEvent(operatorID, domain.AuditActionCardHoldCapture, hold.ID)
+	event.After = domain.NewAuditState(hold)
+	// Ошибка аудита уже залогирована, платеж списан
+	_ = s.auditService.Record(ctx, event)
+
+	return hold, nil
+}
+
+// VoidAuthorization отменяет авторизацию и разблокирует средства. Операция эквайера, как CapturePayment
+func (s *cardService) VoidAuthorization(ctx context.Context, operatorID, holdID int) (*domain.CardHold, error) {
+	hold, err := s.voidAuthorization(ctx, holdID)
+	if err != nil {
+		return nil, err
+	}
+
+	event := acquirerAuditEvent(operatorID, domain.AuditActionCardHoldVoid, hold.ID)
+	event.After = domain.NewAuditState(hold)
+	// Ошибка аудита уже залогирована, авторизация отменена
+	_ = s.auditService.Record(ctx, event)
+
+	return hold, nil
+}
+
+// CancelAuthorization отменяет авторизацию по запросу держателя. Отменить можно только авторизацию,
+// которую пользователь создал сам через API: авторизации ТСП из шлюза отменяет эквайер
+func (s *cardService) CancelAuthorization(ctx context.Context, userID, holdID int) (*domain.CardHold, error) {
+	hold, err := s.checkHoldAccess(ctx, userID, holdID)
+	if err != nil {
+		return nil, err
+	}
+	if hold.InitiatedBy == nil || *hold.InitiatedBy != userID {
+		s.logger.Warn("Card authorization cancel denied: not the initiator", "user_id", userID, "hold_id", holdID)
+		return nil, &ServiceError{Code: http.StatusForbidden, Message: "only the initiator can cancel this authorization"}
+	}
+
+	hold, err = s.voidAuthorization(ctx, holdID)
+	if err != nil {
+		return nil, err
+	}
+
+	event := NewUserAuditEvent(userID, domain.AuditActionCardHoldVoid, "card_hold", auditResourceID(hold.ID))
+	event.After = domain.NewAuditState(hold)
+	// Ошибка аудита уже залогирована, авторизация отменена
+	_ = s.auditService.Record(ctx, event)
+
+	return hold, nil
+}
+
+// voidAuthorization снимает холд в статусе authorized и отменяет связанный платеж
+func (s *cardService) voidAuthorization(ctx context.Context, holdID int) (*domain.CardHold, error) {
+	var hold *domain.CardHold
+	err := s.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		var err error
+		hold, err = s.holdRepo.GetByIDForUpdate(ctx, holdID)
+		if err != nil {
+			return err
+		}
+
+		if err := hold.Void(); err != nil {
+			return err
+		}
+
+		return s.releaseHold(ctx, hold)
+	})
+	if err != nil {
+		s.logHoldError("void", holdID, err)
+		return nil, err
+	}
+
+	s.logger.Info("Card authorization voided", "hold_id", hold.ID, "account_id", hold.AccountID, "amount", hold.Amount)
+	return hold, nil
+}
+
+// RefundPayment возвращает на счет всю оставшуюся (amount = 0) или часть списанной суммы.
+// Каждый возврат записывается отдельной транзакцией типа refund. Возврат списывается с расчетного счета ТСП,
+// поэтому это операция эквайера (шлюза или оператора), а не держателя карты.
+func (s *cardService) RefundPayment(ctx context.Context, operatorID, holdID int, amount float64) (*domain.CardHold, error) {
+	var hold *domain.CardHold
+	var refunded float64
+	err := s.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		var err error
+		hold, err = s.holdRepo.GetByIDForUpdate(ctx, holdID)
+		if err != nil {
+			return err
+		}
+
+		refunded, err = hold.Refund(amount)
+		if err != nil {
+			return err
+		}
+		if err := s.holdRepo.RefundFunds(ctx, hold.AccountID, refunded); err != nil {
+			return fmt.Errorf("failed to refund funds: %w", err)
+		}
+		if err := s.holdRepo.Update(ctx, hold); err != nil {
+			return fmt.Errorf("failed to update card hold: %w", err)
+		}
+
+		payment, err := s.transactionRepo.GetByID(ctx, hold.TransactionID)
+		if err != nil {
+			return fmt.Errorf("failed to get hold transaction: %w", err)
+		}
+
+		now := s.clock.Now()
+		transaction := &domain.Transaction{
+			FromAccount: nil, // Возврат из внешней системы
+			ToAccount:   &hold.AccountID,
+			Amount:      refunded,
+			Type:        domain.TransactionTypeRefund,
+			Status:      domain.TransactionStatusCompleted,
+			Description: fmt.Sprintf("Card refund (Hold ID: %d, merchant: %s)", hold.ID, hold.MerchantID),
+			MerchantID:  payment.MerchantID,
+			MCC:         payment.MCC,
+			CreatedAt:   now,
+			UpdatedAt:   now,
+		}
+		// Возврат покупателю списывается с расчетного счета ТСП; комиссия не возвращается
+		if payment.MerchantID != nil {
+			transaction.FromAccount = payment.ToAccount
+			if err := s.merchantRepo.AdjustSettlementBalance(ctx, *payment.ToAccount, -refunded); err != nil {
+				return fmt.Errorf("failed to debit merchant: %w", err)
+			}
+		}
+		if err := s.transactionRepo.Create(ctx, transaction); err != nil {
+			return fmt.Errorf("failed to create transaction record: %w", err)
+		}
+
+		return s.cashbackService.ReversePayment(ctx, hold.TransactionID, hold.RefundedAmount)
+	})
+	if err != nil {
+		s.logHoldError("refund", holdID, err)
+		return nil, err
+	}
+
+	s.logger.Info("Card payment refunded",
+		"hold_id", hold.ID,
+		"account_id", hold.AccountID,
+		"amount", refunded,
+		"refunded_total", hold.RefundedAmount)
+
+	event := acquirerAuditEvent(operatorID, domain.AuditActionCardRefund, hold.ID)
+	event.Metadata = domain.NewAuditState(map[string]interface{}{
+		"account_id": hold.AccountID,
+		"amount":     refunded,
+	})
+	// Ошибка аудита уже залогирована, возврат выполнен
+	_ = s.auditService.Record(ctx, event)
+
+	return hold, nil
+}
+
+// GetAccountHolds возвращает холды счета (новые первыми)
+func (s *cardService) GetAccountHolds(ctx context.Context, userID, accountID int) ([]*domain.CardHold, error) {
+	if err := s.accessControl.CheckAccountPermission(ctx, userID, accountID, domain.AccountPermissionView); err != nil {
+		s.logger.Warn("Access denied for account holds", "user_id", userID, "account_id", accountID)
+		if domain.IsAccessDeniedError(err) {
+			return nil, &ServiceError{Code: http.StatusForbidden, Message: err.Error()}
+		}
+		return nil, ErrAccountNotFound
+	}
+
+	holds, err := s.holdRepo.GetByAccountID(ctx, accountID)
+	if err != nil {
+		s.logger.Error("Failed to get account holds", "account_id", accountID, "error", err)
+		return nil, fmt.Errorf("failed to get account holds: %w", err)
+	}
+
+	return holds, nil
+}
+
+// ExpireHolds снимает авторизации, не списанные до истечения срока, и разблокирует средства.
+// При отмене контекста обработка прерывается между холдами.
+func (s *cardService) ExpireHolds(ctx context.Context) (*JobResult, error) {
+	now := s.clock.Now()
+
+	holds, err := s.holdRepo.ListExpired(ctx, now)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get expired card holds: %w", err)
+	}
+
+	result := &JobResult{}
+	for _, hold := range holds {
+		if err := ctx.Err(); err != nil {
+			return result, err
+		}
 
+		expired, err := s.expireHold(ctx, hold.ID, now)
+		if err != nil {
+			s.logger.Error("Failed to expire card hold", "hold_id", hold.ID, "error", err)
+			result.Failed++
+			continue
+		}
+		if expired {
+			result.Processed++
+		}
+	}
+
+	return result, nil
+}
+
+// expireHold снимает просроченный холд. Возвращает false, если к моменту блокировки
+// холд уже списан или отменен параллельно.
+func (s *cardService) expireHold(ctx context.Context, holdID int, now time.Time) (bool, error) {
+	var hold *domain.CardHold
+	expired := false
+	err := s.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		var err error
+		hold, err = s.holdRepo.GetByIDForUpdate(ctx, holdID)
+		if err != nil {
+			return err
+		}
+		if !hold.IsExpired(now) {
+			return nil
+		}
+
+		if err := hold.Expire(now); err != nil {
+			return err
+		}
+		expired = true
+
+		return s.releaseHold(ctx, hold)
+	})
+	if err != nil || !expired {
+		return false, err
+	}
+
+	s.logger.Info("Card hold expired", "hold_id", hold.ID, "account_id", hold.AccountID, "amount", hold.Amount)
+	return true, nil
+}
+
+// closeSingleUseCard закрывает одноразовую карту после первого списания; остальные карты не меняются.
+// Статус карты читается до транзакции платежа, поэтому карта закрывается условным обновлением:
+// из параллельных списаний проходит только первое, остальные получают ErrCardBlocked и откатываются
+func (s *cardService) closeSingleUseCard(ctx context.Context, card *domain.Card) error {
+	if card.CardType != domain.CardTypeSingleUse {
+		return nil
+	}
+	cancelled, err := s.cardRepo.Cancel(ctx, card.ID)
+	if err != nil {
+		return fmt.Errorf("failed to cancel single-use card: %w", err)
+	}
+	if !cancelled {
+		s.logger.Warn("Single-use card already used", "card_id", card.ID)
+		return ErrCardBlocked
+	}
+
+	s.logger.Info("Single-use card cancelled after payment", "card_id", card.ID)
 	return nil
 }
+
+// checkHoldAccess проверяет, что холд относится к счету пользователя, и возвращает его
+func (s *cardService) checkHoldAccess(ctx context.Context, userID, holdID int) (*domain.CardHold, error) {
+	hold, err := s.holdRepo.GetByID(ctx, holdID)
+	if err != nil {
+		if errors.Is(err, domain.ErrCardHoldNotFound) {
+			return nil, err
+		}
+		s.logger.Error("Failed to get card hold", "hold_id", holdID, "error", err)
+		return nil, fmt.Errorf("failed to get card hold: %w", err)
+	}
+
+	if err := s.accessControl.CanAccessAccount(ctx, userID, hold.AccountID); err != nil {
+		s.logger.Warn("Access denied for card hold", "user_id", userID, "hold_id", holdID)
+		if domain.IsAccessDeniedError(err) {
+			return nil, &ServiceError{Code: http.StatusForbidden, Message: err.Error()}
+		}
+		return nil, domain.ErrCardHoldNotFound
+	}
+
+	return hold, nil
+}
+
+// acquirerAuditEvent событие аудита операции эквайера: от имени оператора или системы, если операция пришла из шлюза
+func acquirerAuditEvent(operatorID int, action string, holdID int) *domain.AuditEvent {
+	if operatorID == gatewayOperatorID {
+		return &domain.AuditEvent{
+			ActorType:    domain.AuditActorSystem,
+			Action:       action,
+			ResourceType: "card_hold",
+			ResourceID:   auditResourceID(holdID),
+		}
+	}
+
+	event := NewUserAuditEvent(operatorID, action, "card_hold", auditResourceID(holdID))
+	event.ActorType = domain.AuditActorAdmin
+	return event
+}
+
+// releaseHold разблокирует всю сумму холда и отменяет связанный платеж; вызывается в транзакции
+func (s *cardService) releaseHold(ctx context.Context, hold *domain.CardHold) error {
+	if err := s.holdRepo.ReleaseFunds(ctx, hold.AccountID, hold.Amount); err != nil {
+		return fmt.Errorf("failed to release funds: %w", err)
+	}
+	if err := s.holdRepo.Update(ctx, hold); err != nil {
+		return fmt.Errorf("failed to update card hold: %w", err)
+	}
+
+	_, err := s.updateHoldTransaction(ctx, hold.TransactionID, domain.TransactionStatusCancelled, hold.Amount)
+	return err
+}
+
+// updateHoldTransaction переводит платеж холда в итоговый статус с фактической суммой
+func (s *cardService) updateHoldTransaction(ctx context.Context, transactionID int, status string, amount float64) (*domain.Transaction, error) {
+	transaction, err := s.transactionRepo.GetByID(ctx, transactionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get hold transaction: %w", err)
+	}
+
+	transaction.Status = status
+	transaction.Amount = amount
+	if err := s.transactionRepo.Update(ctx, transaction); err != nil {
+		return nil, fmt.Errorf("failed to update hold transaction: %w", err)
+	}
+
+	return transaction, nil
+}
+
+// resolveMerchant находит зарегистрированное ТСП по коду из платежа.
+// Для незарегистрированного ТСП возвращает nil: платеж уходит во внешнюю систему.
+func (s *cardService) resolveMerchant(ctx context.Context, code string, cardAccountID int) (*domain.Merchant, error) {
+	if code == "" {
+		return nil, nil
+	}
+
+	merchant, err := s.merchantRepo.GetByCode(ctx, code)
+	if err != nil {
+		if errors.Is(err, domain.ErrMerchantNotFound) {
+			return nil, nil
+		}
+		s.logger.Error("Failed to get merchant", "merchant_code", code, "error", err)
+		return nil, fmt.Errorf("failed to get merchant: %w", err)
+	}
+
+	if merchant.SettlementAccountID == cardAccountID {
+		return nil, domain.ErrMerchantSelfPayment
+	}
+
+	return merchant, nil
+}
+
+// creditMerchant зачисляет списанный платеж на расчетный счет ТСП за вычетом межбанковской комиссии
+// и записывает комиссию отдельной транзакцией; вызывается в транзакции
+func (s *cardService) creditMerchant(ctx context.Context, payment *domain.Transaction) error {
+	fee := domain.InterchangeFee(payment.Amount, s.interchangeFee)
+	net := math.Round((payment.Amount-fee)*100) / 100
+	if err := s.merchantRepo.AdjustSettlementBalance(ctx, *payment.ToAccount, net); err != nil {
+		return fmt.Errorf("failed to credit merchant: %w", err)
+	}
+	if fee <= 0 {
+		return nil
+	}
+
+	now := s.clock.Now()
+	feeTransaction := &domain.Transaction{
+		FromAccount: payment.ToAccount,
+		ToAccount:   nil, // Комиссия банка
+		Amount:      fee,
+		Type:        domain.TransactionTypeInterchangeFee,
+		Status:      domain.TransactionStatusCompleted,
+		Description: fmt.Sprintf("Interchange fee (Transaction ID: %d)", payment.ID),
+		MerchantID:  payment.MerchantID,
+		MCC:         payment.MCC,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+	if err := s.transactionRepo.Create(ctx, feeTransaction); err != nil {
+		return fmt.Errorf("failed to create interchange fee record: %w", err)
+	}
+
+	return nil
+}
+
+// setPaymentMerchant направляет платеж на расчетный счет ТСП и копирует его MCC
+func setPaymentMerchant(transaction *domain.Transaction, merchant *domain.Merchant) {
+	if merchant == nil {
+		return
+	}
+	transaction.ToAccount = &merchant.SettlementAccountID
+	transaction.MerchantID = &merchant.ID
+	transaction.MCC = merchant.MCC
+	transaction.Description += ", " + merchant.Name
+}
+
+// logHoldError логирует неожиданные ошибки операций с холдом; ошибки состояния и суммы ожидаемы
+func (s *cardService) logHoldError(action string, holdID int, err error) {
+	switch {
+	case errors.Is(err, domain.ErrCardHoldNotFound),
+		errors.Is(err, domain.ErrCardHoldNotAuthorized),
+		errors.Is(err, domain.ErrCardHoldNotCaptured),
+		errors.Is(err, domain.ErrCardHoldExpired),
+		errors.Is(err, domain.ErrInvalidCaptureAmount),
+		errors.Is(err, domain.ErrInvalidRefundAmount):
+		s.logger.Warn("Card hold operation rejected", "action", action, "hold_id", holdID, "error", err)
+	default:
+		s.logger.Error("Failed to "+action+" card hold", "hold_id", holdID, "error", err)
+	}
+}
+
+// SetPIN устанавливает первый PIN карты. Хранится только bcrypt от соли карты и PIN-блока,
+// поэтому PIN нельзя восстановить — только сменить через ChangePIN. PIN задают держатель карты или владелец счета.
+func (s *cardService) SetPIN(ctx context.Context, userID, cardID int, pin string) error {
+	if err := s.checkCardholder(ctx, userID, cardID); err != nil {
+		return err
+	}
+
+	card, err := s.getActiveCard(ctx, userID, cardID)
+	if err != nil {
+		return err
+	}
+
+	if card.HasPIN() {
+		return domain.ErrPINAlreadySet
+	}
+
+	if err := s.savePIN(ctx, card, pin); err != nil {
+		return err
+	}
+
+	s.logger.Info("Card PIN set", "user_id", userID, "card_id", cardID)
+	s.auditPIN(ctx, userID, domain.AuditActionCardPINSet, card)
+	return nil
+}
+
+// ChangePIN меняет PIN карты после проверки текущего; неверный текущий PIN считается попыткой подбора.
+// Менять PIN могут держатель карты или владелец счета.
+func (s *cardService) ChangePIN(ctx context.Context, userID, cardID int, oldPIN, newPIN string) error {
+	if err := s.checkCardholder(ctx, userID, cardID); err != nil {
+		return err
+	}
+
+	card, err := s.VerifyPIN(ctx, userID, cardID, oldPIN)
+	if err != nil {
+		return err
+	}
+
+	if err := s.savePIN(ctx, card, newPIN); err != nil {
+		return err
+	}
+
+	s.logger.Info("Card PIN changed", "user_id", userID, "card_id", cardID)
+	s.auditPIN(ctx, userID, domain.AuditActionCardPINChange, card)
+	return nil
+}
+
+// VerifyPIN проверяет PIN карты пользователя. Неверные PIN подряд считаются,
+// после pinMaxAttempts карта блокируется до разблокировки оператором.
+func (s *cardService) VerifyPIN(ctx context.Context, userID, cardID int, pin string) (*domain.Card, error) {
+	card, err := s.getActiveCard(ctx, userID, cardID)
+	if err != nil {
+		return nil, err
+	}
+
+	if !card.HasPIN() {
+		return nil, domain.ErrPINNotSet
+	}
+
+	cardNumber, _, err := s.decryptCard(card)
+	if err != nil {
+		return nil, err
+	}
+
+	pinBlock, err := utils.BuildPINBlock(pin, cardNumber)
+	if err != nil {
+		// PIN неверного формата тоже считается попыткой: иначе перебор не ограничен
+		s.recordPINFailure(ctx, card)
+		return nil, domain.ErrIncorrectPIN
+	}
+
+	if err := utils.VerifyPINBlock(card.PINHash, pinBlock, card.PINSalt); err != nil {
+		s.recordPINFailure(ctx, card)
+		return nil, domain.ErrIncorrectPIN
+	}
+
+	if card.PINFailedAttempts > 0 {
+		if err := s.cardRepo.ResetPINFailures(ctx, card.ID); err != nil {
+			s.logger.Error("Failed to reset PIN attempts", "card_id", card.ID, "error", err)
+		}
+		card.PINFailedAttempts = 0
+	}
+
+	return card, nil
+}
+
+// UnblockCard разблокирует карту оператором и сбрасывает счетчики неверных PIN и CVV
+func (s *cardService) UnblockCard(ctx context.Context, adminID, cardID int) error {
+	card, err := s.cardRepo.GetByID(ctx, cardID)
+	if err != nil {
+		return ErrCardNotFound
+	}
+
+	if s.clock.Now().After(card.ExpiryDate) {
+		s.logger.Warn("Cannot unblock expired card", "card_id", cardID, "expiry_date", card.ExpiryDate)
+		return ErrCardExpired
+	}
+
+	if err := s.cardRepo.Unblock(ctx, cardID); err != nil {
+		if errors.Is(err, domain.ErrCardNotBlocked) {
+			return err
+		}
+		s.logger.Error("Failed to unblock card", "card_id", cardID, "error", err)
+		return fmt.Errorf("failed to unblock card: %w", err)
+	}
+
+	s.logger.Info("Card unblocked by operator", "admin_id", adminID, "card_id", cardID)
+
+	event := NewUserAuditEvent(adminID, domain.AuditActionCardUnblock, "card", auditResourceID(cardID))
+	event.ActorType = domain.AuditActorAdmin
+	event.Before = domain.NewAuditState(map[string]interface{}{
+		"status":              card.Status,
+		"pin_failed_attempts": card.PINFailedAttempts,
+		"cvv_failed_attempts": card.CVVFailedAttempts,
+	})
+	event.After = domain.NewAuditState(map[string]interface{}{"status": domain.CardStatusActive})
+	// Ошибка аудита уже залогирована, карта разблокирована
+	_ = s.auditService.Record(ctx, event)
+
+	return nil
+}
+
+// checkCardholder проверяет, что пользователь — держатель карты или владелец счета
+func (s *cardService) checkCardholder(ctx context.Context, userID, cardID int) error {
+	if err := s.accessControl.CheckCardholder(ctx, userID, cardID); err != nil {
+		s.logger.Warn("Access denied for cardholder operation", "user_id", userID, "card_id", cardID)
+		if domain.IsAccessDeniedError(err) {
+			return &ServiceError{Code: http.StatusForbidden, Message: err.Error()}
+		}
+		return ErrCardNotFound
+	}
+	return nil
+}
+
+// getActiveCard проверяет доступ пользователя к карте, ее статус и срок действия
+func (s *cardService) getActiveCard(ctx context.Context, userID, cardID int) (*domain.Card, error) {
+	if err := s.accessControl.CanAccessCard(ctx, userID, cardID); err != nil {
+		s.logger.Warn("Access denied for card", "user_id", userID, "card_id", cardID)
+		if domain.IsAccessDeniedError(err) {
+			return nil, &ServiceError{Code: http.StatusForbidden, Message: err.Error()}
+		}
+		return nil, ErrCardNotFound
+	}
+
+	card, err := s.cardRepo.GetByID(ctx, cardID)
+	if err != nil {
+		return nil, ErrCardNotFound
+	}
+
+	// Заблокированная карта не проверяет PIN: перебор после блокировки бесполезен
+	if card.Status != domain.CardStatusActive {
+		s.logger.Warn("Card is not active", "card_id", cardID, "status", card.Status)
+		return nil, ErrCardBlocked
+	}
+
+	if s.clock.Now().After(card.ExpiryDate) {
+		s.logger.Warn("Card is expired", "card_id", cardID, "expiry_date", card.ExpiryDate)
+		return nil, ErrCardExpired
+	}
+
+	return card, nil
+}
+
+// savePIN хеширует PIN-блок с новой солью карты и сохраняет его
+func (s *cardService) savePIN(ctx context.Context, card *domain.Card, pin string) error {
+	if err := domain.ValidatePIN(pin); err != nil {
+		return err
+	}
+
+	cardNumber, _, err := s.decryptCard(card)
+	if err != nil {
+		return err
+	}
+
+	pinHash, pinSalt, err := hashPIN(pin, cardNumber)
+	if err != nil {
+		s.logger.Error("Failed to hash PIN block", "card_id", card.ID, "error", err)
+		return err
+	}
+
+	if err := s.cardRepo.SetPIN(ctx, card.ID, pinHash, pinSalt); err != nil {
+		s.logger.Error("Failed to save card PIN", "card_id", card.ID, "error", err)
+		return fmt.Errorf("failed to save card PIN: %w", err)
+	}
+
+	return nil
+}
+
+// hashPIN строит PIN-блок по номеру карты и хеширует его с новой случайной солью
+func hashPIN(pin, cardNumber string) (string, string, error) {
+	pinBlock, err := utils.BuildPINBlock(pin, cardNumber)
+	if err != nil {
+		return "", "", domain.ErrInvalidPIN
+	}
+
+	salt, err := utils.GenerateRandomKey(pinSaltBytes)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to generate PIN salt: %w", err)
+	}
+	pinSalt := hex.EncodeToString(salt)
+
+	pinHash, err := utils.HashPINBlock(pinBlock, pinSalt)
+	if err != nil {
+		return "", "", err
+	}
+
+	return pinHash, pinSalt, nil
+}
+
+// recordPINFailure увеличивает счетчик неверных PIN; блокировка карты записывается в аудит
+func (s *cardService) recordPINFailure(ctx context.Context, card *domain.Card) {
+	attempts, status, err := s.cardRepo.RecordPINFailure(ctx, card.ID, s.pinMaxAttempts)
+	if err != nil {
+		s.logger.Error("Failed to record PIN failure", "card_id", card.ID, "error", err)
+		return
+	}
+	s.logger.Warn("Card PIN verification failed", "card_id", card.ID, "attempts", attempts)
+
+	if status != domain.CardStatusBlocked {
+		return
+	}
+	s.logger.Warn("Card blocked after failed PIN attempts", "card_id", card.ID, "attempts", attempts)
+
+	event := &domain.AuditEvent{
+		ActorType:    domain.AuditActorSystem,
+		Action:       domain.AuditActionCardBlock,
+		ResourceType: "card",
+		ResourceID:   auditResourceID(card.ID),
+		Metadata: domain.NewAuditState(map[string]interface{}{
+			"account_id": card.AccountID,
+			"reason":     "pin_attempts",
+			"attempts":   attempts,
+		}),
+	}
+	// Ошибка аудита уже залогирована, карта заблокирована
+	_ = s.auditService.Record(ctx, event)
+}
+
+func (s *cardService) auditPIN(ctx context.Context, userID int, action string, card *domain.Card) {
+	event := NewUserAuditEvent(userID, action, "card", auditResourceID(card.ID))
+	event.Metadata = domain.NewAuditState(map[string]interface{}{
+		"account_id": card.AccountID,
+	})
+	// Ошибка аудита уже залогирована, PIN сохранен
+	_ = s.auditService.Record(ctx, event)
+}
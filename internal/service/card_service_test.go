@@ -0,0 +1,1105 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/vterdunov/learn-bank-app/internal/config"
+	"github.com/vterdunov/learn-bank-app/internal/domain"
+	"github.com/vterdunov/learn-bank-app/internal/utils"
+)
+
+// MockCardRepository карты в памяти
+type MockCardRepository struct {
+	cards map[int]*domain.Card
+}
+
+func (m *MockCardRepository) Create(ctx context.Context, card *domain.Card) error {
+	card.ID = len(m.cards) + 1
+	m.cards[card.ID] = card
+	return nil
+}
+
+func (m *MockCardRepository) GetByID(ctx context.Context, id int) (*domain.Card, error) {
+	card, ok := m.cards[id]
+	if !ok {
+		return nil, errors.New("card not found")
+	}
+	copied := *card
+	return &copied, nil
+}
+
+func (m *MockCardRepository) GetByAccountID(ctx context.Context, accountID int) ([]*domain.Card, error) {
+	var cards []*domain.Card
+	for _, card := range m.cards {
+		if card.AccountID == accountID {
+			cards = append(cards, card)
+		}
+	}
+	return cards, nil
+}
+
+func (m *MockCardRepository) Update(ctx context.Context, card *domain.Card) error {
+	m.cards[card.ID] = card
+	return nil
+}
+
+func (m *MockCardRepository) Delete(ctx context.Context, id int) error {
+	delete(m.cards, id)
+	return nil
+}
+
+func (m *MockCardRepository) UpdateStatus(ctx context.Context, id int, status string) error {
+	m.cards[id].Status = status
+	return nil
+}
+
+func (m *MockCardRepository) Cancel(ctx context.Context, id int) (bool, error) {
+	card, ok := m.cards[id]
+	if !ok || card.Status == domain.CardStatusCancelled {
+		return false, nil
+	}
+	card.Status = domain.CardStatusCancelled
+	return true, nil
+}
+
+func (m *MockCardRepository) GetActiveCardsByAccount(ctx context.Context, accountID int) ([]*domain.Card, error) {
+	return m.GetByAccountID(ctx, accountID)
+}
+
+func (m *MockCardRepository) GetByPANHash(ctx context.Context, panHash string) (*domain.Card, error) {
+	for _, card := range m.cards {
+		if card.PANHash == panHash {
+			copied := *card
+			return &copied, nil
+		}
+	}
+	return nil, errors.New("card not found")
+}
+
+func (m *MockCardRepository) ListWithoutPANHash(ctx context.Context) ([]*domain.Card, error) {
+	var cards []*domain.Card
+	for _, card := range m.cards {
+		if card.PANHash == "" {
+			cards = append(cards, card)
+		}
+	}
+	sort.Slice(cards, func(i, j int) bool { return cards[i].ID < cards[j].ID })
+	return cards, nil
+}
+
+func (m *MockCardRepository) SetPANHash(ctx context.Context, id int, panHash string) error {
+	m.cards[id].PANHash = panHash
+	return nil
+}
+
+func (m *MockCardRepository) RecordCVVFailure(ctx context.Context, id, maxAttempts int) (int, string, error) {
+	card := m.cards[id]
+	card.CVVFailedAttempts++
+	if card.CVVFailedAttempts >= maxAttempts {
+		card.Status = domain.CardStatusBlocked
+	}
+	return card.CVVFailedAttempts, card.Status, nil
+}
+
+func (m *MockCardRepository) ResetCVVFailures(ctx context.Context, id int) error {
+	m.cards[id].CVVFailedAttempts = 0
+	return nil
+}
+
+func (m *MockCardRepository) SetPIN(ctx context.Context, id int, pinHash, pinSalt string) error {
+	card := m.cards[id]
+	card.PINHash = pinHash
+	card.PINSalt = pinSalt
+	card.PINFailedAttempts = 0
+	return nil
+}
+
+func (m *MockCardRepository) RecordPINFailure(ctx context.Context, id, maxAttempts int) (int, string, error) {
+	card := m.cards[id]
+	card.PINFailedAttempts++
+	if card.PINFailedAttempts >= maxAttempts {
+		card.Status = domain.CardStatusBlocked
+	}
+	return card.PINFailedAttempts, card.Status, nil
+}
+
+func (m *MockCardRepository) ResetPINFailures(ctx context.Context, id int) error {
+	m.cards[id].PINFailedAttempts = 0
+	return nil
+}
+
+func (m *MockCardRepository) Unblock(ctx context.Context, id int) error {
+	card := m.cards[id]
+	if card.Status != domain.CardStatusBlocked {
+		return domain.ErrCardNotBlocked
+	}
+	card.Status = domain.CardStatusActive
+	card.PINFailedAttempts = 0
+	card.CVVFailedAttempts = 0
+	return nil
+}
+
+// MockHoldRepository холды в памяти; суммы блокируются на счетах MockAccountStore
+type MockHoldRepository struct {
+	accounts *MockAccountStore
+	holds    map[int]*domain.CardHold
+}
+
+func (m *MockHoldRepository) Create(ctx context.Context, hold *domain.CardHold) error {
+	hold.ID = len(m.holds) + 1
+	copied := *hold
+	m.holds[hold.ID] = &copied
+	return nil
+}
+
+func (m *MockHoldRepository) GetByID(ctx context.Context, id int) (*domain.CardHold, error) {
+	hold, ok := m.holds[id]
+	if !ok {
+		return nil, domain.ErrCardHoldNotFound
+	}
+	copied := *hold
+	return &copied, nil
+}
+
+func (m *MockHoldRepository) GetByIDForUpdate(ctx context.Context, id int) (*domain.CardHold, error) {
+	return m.GetByID(ctx, id)
+}
+
+func (m *MockHoldRepository) GetByAccountID(ctx context.Context, accountID int) ([]*domain.CardHold, error) {
+	var holds []*domain.CardHold
+	for _, hold := range m.holds {
+		if hold.AccountID == accountID {
+			copied := *hold
+			holds = append(holds, &copied)
+		}
+	}
+	sort.Slice(holds, func(i, j int) bool { return holds[i].ID > holds[j].ID })
+	return holds, nil
+}
+
+func (m *MockHoldRepository) ListExpired(ctx context.Context, now time.Time) ([]*domain.CardHold, error) {
+	var holds []*domain.CardHold
+	for _, hold := range m.holds {
+		if hold.IsExpired(now) {
+			copied := *hold
+			holds = append(holds, &copied)
+		}
+	}
+	return holds, nil
+}
+
+func (m *MockHoldRepository) Update(ctx context.Context, hold *domain.CardHold) error {
+	if _, ok := m.holds[hold.ID]; !ok {
+		return domain.ErrCardHoldNotFound
+	}
+	copied := *hold
+	m.holds[hold.ID] = &copied
+	return nil
+}
+
+func (m *MockHoldRepository) ReserveFunds(ctx context.Context, accountID int, amount float64) error {
+	account := m.accounts.accounts[accountID]
+	if account.AvailableBalance() < amount {
+		return domain.ErrHoldInsufficientFunds
+	}
+	account.HeldAmount += amount
+	return nil
+}
+
+func (m *MockHoldRepository) ReleaseFunds(ctx context.Context, accountID int, amount float64) error {
+	m.accounts.accounts[accountID].HeldAmount -= amount
+	return nil
+}
+
+func (m *MockHoldRepository) CaptureFunds(ctx context.Context, accountID int, held, captured float64) error {
+	account := m.accounts.accounts[accountID]
+	account.HeldAmount -= held
+	account.Balance -= captured
+	return nil
+}
+
+func (m *MockHoldRepository) RefundFunds(ctx context.Context, accountID int, amount float64) error {
+	m.accounts.accounts[accountID].Balance += amount
+	return nil
+}
+
+type cardHoldTestDeps struct {
+	accounts     *MockAccountStore
+	transactions *MockTransactionRepository
+	holds        *MockHoldRepository
+	merchants    *MockMerchantRepository
+	cards        *MockCardRepository
+	cashback     *MockCashbackRepository
+	// cashbackService сервис кэшбэка, которому сервис карт передает списания и возвраты
+	cashbackService CashbackService
+	clock           *fakeClock
+	userID          int
+}
+
+// setupCardHoldService создает сервис карт со счетом 1 (баланс 1000) и картой 1 пользователя
+// и ТСП "grocery-1" (MCC 5411) с расчетным счетом 3
+func setupCardHoldService(t *testing.T) (*cardService, *cardHoldTestDeps) {
+	t.Helper()
+
+	cfg := &config.Config{
+		Card:     config.CardConfig{HoldTTL: 72 * time.Hour},
+		Merchant: config.MerchantConfig{InterchangeFeePercent: 1.5},
+		Cashback: config.CashbackConfig{MonthlyCap: 3000},
+	}
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	auditService, _ := setupAuditService()
+	notificationService, notificationDeps := setupNotificationService(t)
+	clock := &fakeClock{now: time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)}
+
+	accounts := &MockAccountStore{accounts: map[int]*domain.Account{
+		1: {ID: 1, UserID: notificationDeps.userID, Balance: 1000, Status: domain.AccountStatusActive},
+		2: {ID: 2, UserID: notificationDeps.userID + 100, Balance: 1000, Status: domain.AccountStatusActive},
+		3: {ID: 3, UserID: notificationDeps.userID + 200, Status: domain.AccountStatusActive},
+	}}
+	cards := &MockCardRepository{cards: map[int]*domain.Card{
+		1: {ID: 1, AccountID: 1, Status: "active", ExpiryDate: clock.now.AddDate(2, 0, 0)},
+	}}
+	transactions := &MockTransactionRepository{accounts: accounts}
+	holds := &MockHoldRepository{accounts: accounts, holds: map[int]*domain.CardHold{}}
+	merchants := &MockMerchantRepository{accounts: accounts, transactions: transactions, merchants: map[int]*domain.Merchant{
+		1: {ID: 1, Code: "grocery-1", Name: "Grocery", MCC: "5411", SettlementAccountID: 3},
+	}}
+
+	cashback := &MockCashbackRepository{accounts: accounts, inactive: map[int]bool{}}
+	accessControl := &mockStoreAccessControl{accounts: accounts, cards: cards}
+
+	cashbackService, err := NewCashbackService(cfg, cashback, accessControl, mockTxManager{}, auditService, clock, logger)
+	if err != nil {
+		t.Fatalf("NewCashbackService failed: %v", err)
+	}
+	svc := NewCardService(cfg, cards, accounts, transactions, holds, merchants, accessControl,
+		mockTxManager{}, notificationService, cashbackService, mockFraudService{}, mockKYCService{}, auditService, clock, logger)
+
+	return svc.(*cardService), &cardHoldTestDeps{
+		accounts:        accounts,
+		transactions:    transactions,
+		holds:           holds,
+		merchants:       merchants,
+		cards:           cards,
+		cashback:        cashback,
+		cashbackService: cashbackService,
+		clock:           clock,
+		userID:          notificationDeps.userID,
+	}
+}
+
+// issueTestCard записывает в карту зашифрованные реквизиты, хеш номера и хеш CVV
+func issueTestCard(t *testing.T, svc *cardService, card *domain.Card, pan, expiry, cvv string) {
+	t.Helper()
+
+	encryptedNumber, encryptedExpiry, err := utils.EncryptCardData(pan, expiry, svc.encryptionKey)
+	if err != nil {
+		t.Fatalf("failed to encrypt card data: %v", err)
+	}
+	cvvHash, err := utils.HashCVV(cvv)
+	if err != nil {
+		t.Fatalf("failed to hash CVV: %v", err)
+	}
+
+	card.EncryptedData = fmt.Sprintf("%x:%s", encryptedNumber.Data, encryptedNumber.HMAC)
+	card.HMAC = fmt.Sprintf("%x:%s", encryptedExpiry.Data, encryptedExpiry.HMAC)
+	card.CVVHash = cvvHash
+	card.PANHash = svc.panHash(pan)
+}
+
+// rejectingFraudService отклоняет все операции и запоминает проверенные
+type rejectingFraudService struct {
+	mockFraudService
+	screened []*domain.FraudOperation
+}
+
+func (m *rejectingFraudService) Screen(ctx context.Context, op *domain.FraudOperation) error {
+	m.screened = append(m.screened, op)
+	return domain.ErrOperationRejected
+}
+
+func TestCardService_AuthorizationIsScreened(t *testing.T) {
+	svc, deps := setupCardHoldService(t)
+	ctx := context.Background()
+	fraud := &rejectingFraudService{}
+	svc.fraudService = fraud
+
+	if _, err := svc.AuthorizePayment(ctx, deps.userID, 1, 300, "shop-1", ""); !errors.Is(err, domain.ErrOperationRejected) {
+		t.Errorf("expected ErrOperationRejected, got %v", err)
+	}
+	if _, err := svc.AuthorizeGatewayPayment(ctx, 1, 300, "shop-1", ""); !errors.Is(err, domain.ErrOperationRejected) {
+		t.Errorf("expected ErrOperationRejected for gateway authorization, got %v", err)
+	}
+	if account := deps.accounts.accounts[1]; account.HeldAmount != 0 {
+		t.Errorf("rejected authorizations must not hold funds, got held=%.2f", account.HeldAmount)
+	}
+
+	// Проверяется владелец счета карты, в том числе для авторизаций шлюза
+	if len(fraud.screened) != 2 {
+		t.Fatalf("expected 2 screened operations, got %d", len(fraud.screened))
+	}
+	for _, op := range fraud.screened {
+		if op.Type != domain.FraudOperationCardPayment || op.UserID != deps.accounts.accounts[1].UserID || op.CardID == nil || *op.CardID != 1 {
+			t.Errorf("unexpected screened operation: %+v", op)
+		}
+	}
+}
+
+func TestCardService_AuthorizeAndCapture(t *testing.T) {
+	svc, deps := setupCardHoldService(t)
+	ctx := context.Background()
+
+	hold, err := svc.AuthorizePayment(ctx, deps.userID, 1, 300, "shop-1", "")
+	if err != nil {
+		t.Fatalf("AuthorizePayment failed: %v", err)
+	}
+
+	account := deps.accounts.accounts[1]
+	if account.Balance != 1000 || account.HeldAmount != 300 || account.AvailableBalance() != 700 {
+		t.Errorf("expected ledger 1000 and 300 held, got balance=%.2f held=%.2f", account.Balance, account.HeldAmount)
+	}
+	if !hold.ExpiresAt.Equal(deps.clock.now.Add(72 * time.Hour)) {
+		t.Errorf("unexpected expiry: %v", hold.ExpiresAt)
+	}
+
+	pending, _ := deps.transactions.GetByID(ctx, hold.TransactionID)
+	if pending.Status != domain.TransactionStatusPending || pending.Amount != 300 {
+		t.Errorf("expected pending payment of 300, got %+v", pending)
+	}
+
+	// Авторизация сверх доступного остатка отклоняется, хотя учетного остатка хватает
+	if _, err := svc.AuthorizePayment(ctx, deps.userID, 1, 800, "shop-2", ""); !errors.Is(err, ErrInsufficientFunds) {
+		t.Errorf("expected ErrInsufficientFunds, got %v", err)
+	}
+	if err := NewAccountService(&config.Config{}, deps.accounts, deps.transactions, deps.cards, nil, nil, nil, &mockStoreAccessControl{accounts: deps.accounts},
+		mockTxManager{}, nil, svc, mockFraudService{}, mockSanctionsService{}, mockKYCService{}, svc.auditService, svc.logger).WithdrawMoney(ctx, deps.userID, 1, 800); !errors.Is(err, ErrInsufficientFunds) {
+		t.Errorf("withdrawal must respect held funds, got %v", err)
+	}
+
+	// Частичное списание разблокирует остаток авторизации
+	captured, err := svc.CapturePayment(ctx, deps.userID, hold.ID, 250.5)
+	if err != nil {
+		t.Fatalf("CapturePayment failed: %v", err)
+	}
+	if captured.Status != domain.CardHoldStatusCaptured || captured.CapturedAmount != 250.5 || captured.CapturedAt == nil {
+		t.Errorf("unexpected captured hold: %+v", captured)
+	}
+	if account.Balance != 749.5 || account.HeldAmount != 0 {
+		t.Errorf("expected balance 749.50 and nothing held, got balance=%.2f held=%.2f", account.Balance, account.HeldAmount)
+	}
+
+	completed, _ := deps.transactions.GetByID(ctx, hold.TransactionID)
+	if completed.Status != domain.TransactionStatusCompleted || completed.Amount != 250.5 {
+		t.Errorf("expected completed payment of 250.50, got %+v", completed)
+	}
+
+	if _, err := svc.CapturePayment(ctx, deps.userID, hold.ID, 0); !errors.Is(err, domain.ErrCardHoldNotAuthorized) {
+		t.Errorf("expected ErrCardHoldNotAuthorized on second capture, got %v", err)
+	}
+}
+
+func TestCardService_CaptureValidation(t *testing.T) {
+	svc, deps := setupCardHoldService(t)
+	ctx := context.Background()
+
+	hold, err := svc.AuthorizePayment(ctx, deps.userID, 1, 100, "shop-1", "Coffee")
+	if err != nil {
+		t.Fatalf("AuthorizePayment failed: %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		amount  float64
+		wantErr error
+	}{
+		{"above authorized", 100.01, domain.ErrInvalidCaptureAmount},
+		{"negative", -1, domain.ErrInvalidCaptureAmount},
+		{"rounds to zero", 0.001, domain.ErrInvalidCaptureAmount},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := svc.CapturePayment(ctx, deps.userID, hold.ID, tt.amount); !errors.Is(err, tt.wantErr) {
+				t.Errorf("expected %v, got %v", tt.wantErr, err)
+			}
+		})
+	}
+
+}
+
+func TestCardService_CancelAuthorization(t *testing.T) {
+	svc, deps := setupCardHoldService(t)
+	ctx := context.Background()
+
+	own, err := svc.AuthorizePayment(ctx, deps.userID, 1, 100, "shop-1", "")
+	if err != nil {
+		t.Fatalf("AuthorizePayment failed: %v", err)
+	}
+	if own.InitiatedBy == nil || *own.InitiatedBy != deps.userID {
+		t.Fatalf("expected hold initiated by user %d, got %v", deps.userID, own.InitiatedBy)
+	}
+	merchantHold, err := svc.AuthorizeGatewayPayment(ctx, 1, 200, "shop-2", "")
+	if err != nil {
+		t.Fatalf("AuthorizeGatewayPayment failed: %v", err)
+	}
+	if merchantHold.InitiatedBy != nil {
+		t.Fatalf("gateway hold must have no initiator, got %v", *merchantHold.InitiatedBy)
+	}
+
+	// Авторизацию ТСП держатель отменить не может, даже со своего счета
+	_, err = svc.CancelAuthorization(ctx, deps.userID, merchantHold.ID)
+	var serviceErr *ServiceError
+	if !errors.As(err, &serviceErr) || serviceErr.Code != http.StatusForbidden {
+		t.Errorf("expected 403 for merchant authorization, got %v", err)
+	}
+
+	if _, err := svc.CancelAuthorization(ctx, deps.userID, own.ID); err != nil {
+		t.Fatalf("CancelAuthorization failed: %v", err)
+	}
+	if account := deps.accounts.accounts[1]; account.HeldAmount != 200 {
+		t.Errorf("expected only merchant hold to remain, got held=%.2f", account.HeldAmount)
+	}
+
+	// Чужой холд недоступен
+	deps.accounts.accounts[1].UserID = deps.userID + 100
+	if _, err := svc.CancelAuthorization(ctx, deps.userID, merchantHold.ID); !errors.As(err, &serviceErr) || serviceErr.Code != http.StatusForbidden {
+		t.Errorf("expected 403 for foreign hold, got %v", err)
+	}
+}
+
+func TestCardService_VoidAuthorization(t *testing.T) {
+	svc, deps := setupCardHoldService(t)
+	ctx := context.Background()
+
+	hold, err := svc.AuthorizePayment(ctx, deps.userID, 1, 400, "shop-1", "")
+	if err != nil {
+		t.Fatalf("AuthorizePayment failed: %v", err)
+	}
+
+	voided, err := svc.VoidAuthorization(ctx, deps.userID, hold.ID)
+	if err != nil {
+		t.Fatalf("VoidAuthorization failed: %v", err)
+	}
+	if voided.Status != domain.CardHoldStatusVoided {
+		t.Errorf("expected voided status, got %s", voided.Status)
+	}
+
+	account := deps.accounts.accounts[1]
+	if account.Balance != 1000 || account.HeldAmount != 0 {
+		t.Errorf("void must release funds without debit, got balance=%.2f held=%.2f", account.Balance, account.HeldAmount)
+	}
+
+	cancelled, _ := deps.transactions.GetByID(ctx, hold.TransactionID)
+	if cancelled.Status != domain.TransactionStatusCancelled {
+		t.Errorf("expected cancelled payment, got %s", cancelled.Status)
+	}
+
+	if _, err := svc.CapturePayment(ctx, deps.userID, hold.ID, 0); !errors.Is(err, domain.ErrCardHoldNotAuthorized) {
+		t.Errorf("expected ErrCardHoldNotAuthorized after void, got %v", err)
+	}
+}
+
+func TestCardService_ExpireHolds(t *testing.T) {
+	svc, deps := setupCardHoldService(t)
+	ctx := context.Background()
+
+	old, err := svc.AuthorizePayment(ctx, deps.userID, 1, 200, "shop-1", "")
+	if err != nil {
+		t.Fatalf("AuthorizePayment failed: %v", err)
+	}
+	deps.clock.now = deps.clock.now.Add(48 * time.Hour)
+	fresh, err := svc.AuthorizePayment(ctx, deps.userID, 1, 100, "shop-2", "")
+	if err != nil {
+		t.Fatalf("AuthorizePayment failed: %v", err)
+	}
+
+	// Списание просроченного холда до запуска задачи уже невозможно
+	deps.clock.now = deps.clock.now.Add(24 * time.Hour)
+	if _, err := svc.CapturePayment(ctx, deps.userID, old.ID, 0); !errors.Is(err, domain.ErrCardHoldExpired) {
+		t.Errorf("expected ErrCardHoldExpired, got %v", err)
+	}
+
+	result, err := svc.ExpireHolds(ctx)
+	if err != nil {
+		t.Fatalf("ExpireHolds failed: %v", err)
+	}
+	if result.Processed != 1 || result.Failed != 0 {
+		t.Errorf("expected 1 expired hold, got %+v", result)
+	}
+
+	if hold, _ := deps.holds.GetByID(ctx, old.ID); hold.Status != domain.CardHoldStatusExpired {
+		t.Errorf("expected expired status, got %s", hold.Status)
+	}
+	if hold, _ := deps.holds.GetByID(ctx, fresh.ID); hold.Status != domain.CardHoldStatusAuthorized {
+		t.Errorf("fresh hold must stay authorized, got %s", hold.Status)
+	}
+	if account := deps.accounts.accounts[1]; account.Balance != 1000 || account.HeldAmount != 100 {
+		t.Errorf("expected only fresh hold to remain, got balance=%.2f held=%.2f", account.Balance, account.HeldAmount)
+	}
+}
+
+func TestCardService_RefundPayment(t *testing.T) {
+	svc, deps := setupCardHoldService(t)
+	ctx := context.Background()
+
+	hold, err := svc.AuthorizePayment(ctx, deps.userID, 1, 500, "shop-1", "")
+	if err != nil {
+		t.Fatalf("AuthorizePayment failed: %v", err)
+	}
+
+	if _, err := svc.RefundPayment(ctx, deps.userID, hold.ID, 100); !errors.Is(err, domain.ErrCardHoldNotCaptured) {
+		t.Errorf("expected ErrCardHoldNotCaptured before capture, got %v", err)
+	}
+
+	if _, err := svc.CapturePayment(ctx, deps.userID, hold.ID, 0); err != nil {
+		t.Fatalf("CapturePayment failed: %v", err)
+	}
+
+	refunded, err := svc.RefundPayment(ctx, deps.userID, hold.ID, 120)
+	if err != nil {
+		t.Fatalf("RefundPayment failed: %v", err)
+	}
+	if refunded.RefundedAmount != 120 || refunded.RefundableAmount() != 380 {
+		t.Errorf("unexpected refund state: %+v", refunded)
+	}
+
+	if _, err := svc.RefundPayment(ctx, deps.userID, hold.ID, 400); !errors.Is(err, domain.ErrInvalidRefundAmount) {
+		t.Errorf("expected ErrInvalidRefundAmount above refundable, got %v", err)
+	}
+
+	// Нулевая сумма возвращает весь остаток
+	refunded, err = svc.RefundPayment(ctx, deps.userID, hold.ID, 0)
+	if err != nil {
+		t.Fatalf("RefundPayment failed: %v", err)
+	}
+	if refunded.RefundedAmount != 500 {
+		t.Errorf("expected full refund, got %.2f", refunded.RefundedAmount)
+	}
+	if account := deps.accounts.accounts[1]; account.Balance != 1000 || account.HeldAmount != 0 {
+		t.Errorf("expected balance restored, got balance=%.2f held=%.2f", account.Balance, account.HeldAmount)
+	}
+
+	var refunds []float64
+	for _, tr := range deps.transactions.transactions {
+		if tr.Type == domain.TransactionTypeRefund {
+			if tr.ToAccount == nil || *tr.ToAccount != 1 || tr.Status != domain.TransactionStatusCompleted {
+				t.Errorf("unexpected refund transaction: %+v", tr)
+			}
+			refunds = append(refunds, tr.Amount)
+		}
+	}
+	if len(refunds) != 2 || refunds[0] != 120 || refunds[1] != 380 {
+		t.Errorf("expected refund transactions [120 380], got %v", refunds)
+	}
+}
+
+func TestCardService_MerchantPayment(t *testing.T) {
+	svc, deps := setupCardHoldService(t)
+	ctx := context.Background()
+
+	if err := svc.ProcessPayment(ctx, deps.userID, 1, 200, "grocery-1"); err != nil {
+		t.Fatalf("ProcessPayment failed: %v", err)
+	}
+
+	// Покупатель платит 200, ТСП получает 200 за вычетом 1.5% комиссии
+	if balance := deps.accounts.accounts[1].Balance; balance != 800 {
+		t.Errorf("expected card account balance 800, got %.2f", balance)
+	}
+	if balance := deps.accounts.accounts[3].Balance; balance != 197 {
+		t.Errorf("expected merchant balance 197, got %.2f", balance)
+	}
+
+	if len(deps.transactions.transactions) != 2 {
+		t.Fatalf("expected payment and fee transactions, got %d", len(deps.transactions.transactions))
+	}
+	payment, fee := deps.transactions.transactions[0], deps.transactions.transactions[1]
+	if payment.ToAccount == nil || *payment.ToAccount != 3 || payment.MerchantID == nil || *payment.MerchantID != 1 || payment.MCC != "5411" {
+		t.Errorf("unexpected payment transaction: %+v", payment)
+	}
+	if fee.Type != domain.TransactionTypeInterchangeFee || fee.Amount != 3 || fee.FromAccount == nil || *fee.FromAccount != 3 {
+		t.Errorf("unexpected fee transaction: %+v", fee)
+	}
+
+	// Незарегистрированное ТСП: платеж уходит во внешнюю систему без MCC
+	if err := svc.ProcessPayment(ctx, deps.userID, 1, 100, "shop-1"); err != nil {
+		t.Fatalf("ProcessPayment failed: %v", err)
+	}
+	if external := deps.transactions.transactions[2]; external.ToAccount != nil || external.MerchantID != nil || external.MCC != "" {
+		t.Errorf("unexpected external payment: %+v", external)
+	}
+
+	// Оплата со счета, который сам является расчетным счетом ТСП
+	deps.merchants.merchants[2] = &domain.Merchant{ID: 2, Code: "own-shop", MCC: "5999", SettlementAccountID: 1}
+	if err := svc.ProcessPayment(ctx, deps.userID, 1, 10, "own-shop"); !errors.Is(err, domain.ErrMerchantSelfPayment) {
+		t.Errorf("expected ErrMerchantSelfPayment, got %v", err)
+	}
+}
+
+func TestCardService_PaymentFromFrozenAccount(t *testing.T) {
+	svc, deps := setupCardHoldService(t)
+	ctx := context.Background()
+	deps.accounts.accounts[1].Status = domain.AccountStatusBlocked
+
+	if err := svc.ProcessPayment(ctx, deps.userID, 1, 200, "grocery-1"); !errors.Is(err, ErrAccountBlocked) {
+		t.Errorf("expected ErrAccountBlocked, got %v", err)
+	}
+	if balance := deps.accounts.accounts[1].Balance; balance != 1000 {
+		t.Errorf("expected balance unchanged, got %.2f", balance)
+	}
+	if len(deps.transactions.transactions) != 0 {
+		t.Errorf("expected no transactions, got %d", len(deps.transactions.transactions))
+	}
+}
+
+func TestCardService_MerchantHoldCaptureAndRefund(t *testing.T) {
+	svc, deps := setupCardHoldService(t)
+	ctx := context.Background()
+
+	hold, err := svc.AuthorizePayment(ctx, deps.userID, 1, 300, "grocery-1", "")
+	if err != nil {
+		t.Fatalf("AuthorizePayment failed: %v", err)
+	}
+	// До списания ТСП ничего не получает
+	if balance := deps.accounts.accounts[3].Balance; balance != 0 {
+		t.Errorf("expected merchant balance 0 before capture, got %.2f", balance)
+	}
+
+	if _, err := svc.CapturePayment(ctx, deps.userID, hold.ID, 200); err != nil {
+		t.Fatalf("CapturePayment failed: %v", err)
+	}
+	if balance := deps.accounts.accounts[3].Balance; balance != 197 {
+		t.Errorf("expected merchant balance 197 after capture, got %.2f", balance)
+	}
+
+	// Возврат списывается с ТСП, комиссия не возвращается
+	if _, err := svc.RefundPayment(ctx, deps.userID, hold.ID, 50); err != nil {
+		t.Fatalf("RefundPayment failed: %v", err)
+	}
+	if balance := deps.accounts.accounts[3].Balance; balance != 147 {
+		t.Errorf("expected merchant balance 147 after refund, got %.2f", balance)
+	}
+	refund := deps.transactions.transactions[len(deps.transactions.transactions)-1]
+	if refund.Type != domain.TransactionTypeRefund || refund.FromAccount == nil || *refund.FromAccount != 3 || refund.MCC != "5411" {
+		t.Errorf("unexpected refund transaction: %+v", refund)
+	}
+
+	// Остаток ТСП меньше возврата
+	deps.accounts.accounts[3].Balance = 10
+	if _, err := svc.RefundPayment(ctx, deps.userID, hold.ID, 0); !errors.Is(err, domain.ErrMerchantInsufficientFunds) {
+		t.Errorf("expected ErrMerchantInsufficientFunds, got %v", err)
+	}
+}
+
+func TestCardService_PayByCardDetails(t *testing.T) {
+	svc, deps := setupCardHoldService(t)
+	ctx := context.Background()
+	card := svc.cardRepo.(*MockCardRepository).cards[1]
+	issueTestCard(t, svc, card, "4000001234567899", "03/28", "123")
+
+	payment := CardDetailsPaymentRequest{
+		Number:     "4000001234567899",
+		ExpiryDate: "03/28",
+		CVV:        "999",
+		Amount:     100,
+		MerchantID: "shop-1",
+	}
+
+	// Неверные номер, срок и CVV неразличимы для клиента
+	for _, tc := range []struct {
+		name   string
+		modify func(*CardDetailsPaymentRequest)
+	}{
+		{"wrong CVV", func(r *CardDetailsPaymentRequest) {}},
+		{"unknown number", func(r *CardDetailsPaymentRequest) { r.Number = "4000000000000002"; r.CVV = "123" }},
+		{"wrong expiry", func(r *CardDetailsPaymentRequest) { r.ExpiryDate = "04/28"; r.CVV = "123" }},
+	} {
+		req := payment
+		tc.modify(&req)
+		if err := svc.PayByCardDetails(ctx, deps.userID+100, req); !errors.Is(err, ErrCardVerificationFailed) {
+			t.Errorf("%s: expected ErrCardVerificationFailed, got %v", tc.name, err)
+		}
+	}
+	if card.CVVFailedAttempts != 1 {
+		t.Errorf("expected 1 failed CVV attempt, got %d", card.CVVFailedAttempts)
+	}
+
+	// Истекший срок отклоняется до поиска карты
+	expired := payment
+	expired.ExpiryDate = "01/20"
+	if err := svc.PayByCardDetails(ctx, deps.userID, expired); !errors.Is(err, ErrCardExpired) {
+		t.Errorf("expected ErrCardExpired, got %v", err)
+	}
+
+	// Платеж по верным реквизитам списывается со счета карты и сбрасывает счетчик
+	payment.CVV = "123"
+	if err := svc.PayByCardDetails(ctx, deps.userID+100, payment); err != nil {
+		t.Fatalf("PayByCardDetails failed: %v", err)
+	}
+	if balance := deps.accounts.accounts[1].Balance; balance != 900 {
+		t.Errorf("expected balance 900, got %.2f", balance)
+	}
+	if card.CVVFailedAttempts != 0 {
+		t.Errorf("expected CVV attempts to be reset, got %d", card.CVVFailedAttempts)
+	}
+}
+
+func TestCardService_CVVAttemptsBlockCard(t *testing.T) {
+	svc, _ := setupCardHoldService(t)
+	ctx := context.Background()
+	card := svc.cardRepo.(*MockCardRepository).cards[1]
+	issueTestCard(t, svc, card, "4000001234567899", "03/28", "123")
+
+	for i := 1; i <= defaultCVVMaxAttempts; i++ {
+		if _, err := svc.VerifyCardholder(ctx, "4000001234567899", "03/28", "000"); !errors.Is(err, ErrInvalidCVV) {
+			t.Fatalf("attempt %d: expected ErrInvalidCVV, got %v", i, err)
+		}
+	}
+	if card.Status != domain.CardStatusBlocked {
+		t.Fatalf("expected card to be blocked after %d attempts, got %s", defaultCVVMaxAttempts, card.Status)
+	}
+
+	// После блокировки не проходит и верный CVV
+	if _, err := svc.VerifyCardholder(ctx, "4000001234567899", "03/28", "123"); !errors.Is(err, ErrCardBlocked) {
+		t.Errorf("expected ErrCardBlocked, got %v", err)
+	}
+}
+
+func TestCardService_BackfillPANHashes(t *testing.T) {
+	svc, _ := setupCardHoldService(t)
+	ctx := context.Background()
+	cards := svc.cardRepo.(*MockCardRepository)
+	issueTestCard(t, svc, cards.cards[1], "4000001234567899", "03/28", "123")
+	cards.cards[1].PANHash = ""
+	cards.cards[2] = &domain.Card{ID: 2, AccountID: 1, EncryptedData: "broken", Status: domain.CardStatusActive}
+
+	filled, err := svc.BackfillPANHashes(ctx)
+	if err != nil {
+		t.Fatalf("BackfillPANHashes failed: %v", err)
+	}
+	if filled != 1 {
+		t.Errorf("expected 1 card to be filled, got %d", filled)
+	}
+	if _, err := svc.VerifyCardholder(ctx, "4000001234567899", "03/28", "123"); err != nil {
+		t.Errorf("card must be found by number after backfill: %v", err)
+	}
+}
+
+func TestCardService_CreateCardTypes(t *testing.T) {
+	svc, deps := setupCardHoldService(t)
+	ctx := context.Background()
+
+	tests := []struct {
+		name       string
+		req        domain.CreateCardRequest
+		wantType   string
+		wantPrefix string
+		wantExpiry time.Time
+	}{
+		{"default physical", domain.CreateCardRequest{AccountID: 1}, domain.CardTypePhysical, utils.LearnBankPrefix, time.Time{}},
+		{"virtual", domain.CreateCardRequest{AccountID: 1, CardType: domain.CardTypeVirtual}, domain.CardTypeVirtual, utils.LearnBankVirtualPrefix, time.Time{}},
+		{"single use", domain.CreateCardRequest{AccountID: 1, CardType: domain.CardTypeSingleUse}, domain.CardTypeSingleUse,
+			utils.LearnBankSingleUsePrefix, deps.clock.now.AddDate(0, 0, domain.SingleUseCardValidityDays)},
+		{"virtual with short expiry", domain.CreateCardRequest{AccountID: 1, CardType: domain.CardTypeVirtual, ValidityDays: 7}, domain.CardTypeVirtual,
+			utils.LearnBankVirtualPrefix, deps.clock.now.AddDate(0, 0, 7)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			card, err := svc.CreateCard(ctx, deps.userID, tt.req)
+			if err != nil {
+				t.Fatalf("CreateCard failed: %v", err)
+			}
+			if card.CardType != tt.wantType || card.Status != domain.CardStatusActive {
+				t.Errorf("unexpected card: type=%s status=%s", card.CardType, card.Status)
+			}
+
+			number, expiry, err := svc.decryptCard(card)
+			if err != nil {
+				t.Fatalf("decryptCard failed: %v", err)
+			}
+			if number[:4] != tt.wantPrefix || utils.GetCardType(number) != utils.CardTypeLearnBank {
+				t.Errorf("unexpected card number prefix %s", number[:4])
+			}
+			if !tt.wantExpiry.IsZero() {
+				if !card.ExpiryDate.Equal(tt.wantExpiry) {
+					t.Errorf("expected expiry %v, got %v", tt.wantExpiry, card.ExpiryDate)
+				}
+				if expiry.Format("01/06") != tt.wantExpiry.Format("01/06") {
+					t.Errorf("expected printed expiry %s, got %s", tt.wantExpiry.Format("01/06"), expiry.Format("01/06"))
+				}
+			}
+		})
+	}
+
+	invalid := []struct {
+		name string
+		req  domain.CreateCardRequest
+		want error
+	}{
+		{"unknown type", domain.CreateCardRequest{AccountID: 1, CardType: "credit"}, domain.ErrInvalidCardType},
+		{"long validity", domain.CreateCardRequest{AccountID: 1, ValidityDays: 400}, domain.ErrInvalidCardValidity},
+		{"unknown account", domain.CreateCardRequest{AccountID: 99}, ErrAccountNotFound},
+	}
+	for _, tt := range invalid {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := svc.CreateCard(ctx, deps.userID, tt.req); !errors.Is(err, tt.want) {
+				t.Errorf("expected %v, got %v", tt.want, err)
+			}
+		})
+	}
+}
+
+func TestCardService_SingleUseCard(t *testing.T) {
+	svc, deps := setupCardHoldService(t)
+	ctx := context.Background()
+
+	card, err := svc.CreateCard(ctx, deps.userID, domain.CreateCardRequest{
+		AccountID: 1, CardType: domain.CardTypeSingleUse, MerchantLock: " grocery-1 ",
+	})
+	if err != nil {
+		t.Fatalf("CreateCard failed: %v", err)
+	}
+	if card.MerchantLock != "grocery-1" {
+		t.Errorf("expected merchant lock grocery-1, got %q", card.MerchantLock)
+	}
+
+	if err := svc.ProcessPayment(ctx, deps.userID, card.ID, 50, "shop-1"); !errors.Is(err, domain.ErrCardMerchantLocked) {
+		t.Errorf("expected ErrCardMerchantLocked, got %v", err)
+	}
+	if _, err := svc.AuthorizePayment(ctx, deps.userID, card.ID, 50, "shop-1", ""); !errors.Is(err, domain.ErrCardMerchantLocked) {
+		t.Errorf("expected ErrCardMerchantLocked for authorization, got %v", err)
+	}
+
+	if err := svc.ProcessPayment(ctx, deps.userID, card.ID, 50, "grocery-1"); err != nil {
+		t.Fatalf("ProcessPayment failed: %v", err)
+	}
+	if card.Status != domain.CardStatusCancelled {
+		t.Errorf("expected single-use card to be cancelled, got %s", card.Status)
+	}
+	if err := svc.ProcessPayment(ctx, deps.userID, card.ID, 50, "grocery-1"); !errors.Is(err, ErrCardBlocked) {
+		t.Errorf("expected ErrCardBlocked after first payment, got %v", err)
+	}
+
+	// Двухстадийная оплата: карта закрывается при списании, а не при авторизации
+	card, err = svc.CreateCard(ctx, deps.userID, domain.CreateCardRequest{AccountID: 1, CardType: domain.CardTypeSingleUse})
+	if err != nil {
+		t.Fatalf("CreateCard failed: %v", err)
+	}
+	hold, err := svc.AuthorizePayment(ctx, deps.userID, card.ID, 100, "shop-1", "")
+	if err != nil {
+		t.Fatalf("AuthorizePayment failed: %v", err)
+	}
+	if card.Status != domain.CardStatusActive {
+		t.Errorf("expected card to stay active after authorization, got %s", card.Status)
+	}
+	if _, err := svc.CapturePayment(ctx, deps.userID, hold.ID, 80); err != nil {
+		t.Fatalf("CapturePayment failed: %v", err)
+	}
+	if card.Status != domain.CardStatusCancelled {
+		t.Errorf("expected single-use card to be cancelled after capture, got %s", card.Status)
+	}
+
+	// Обычная карта после оплаты остается активной
+	if err := svc.ProcessPayment(ctx, deps.userID, 1, 10, "shop-1"); err != nil {
+		t.Fatalf("ProcessPayment failed: %v", err)
+	}
+	if status := deps.cards.cards[1].Status; status != domain.CardStatusActive {
+		t.Errorf("expected regular card to stay active, got %s", status)
+	}
+}
+
+// staleCardRepository отдает карту активной, как при чтении до фиксации параллельного платежа
+type staleCardRepository struct {
+	*MockCardRepository
+}
+
+func (r staleCardRepository) GetByID(ctx context.Context, id int) (*domain.Card, error) {
+	card, err := r.MockCardRepository.GetByID(ctx, id)
+	if err == nil {
+		card.Status = domain.CardStatusActive
+	}
+	return card, err
+}
+
+func TestCardService_SingleUseCardConcurrentPayments(t *testing.T) {
+	svc, deps := setupCardHoldService(t)
+	ctx := context.Background()
+
+	card, err := svc.CreateCard(ctx, deps.userID, domain.CreateCardRequest{AccountID: 1, CardType: domain.CardTypeSingleUse})
+	if err != nil {
+		t.Fatalf("CreateCard failed: %v", err)
+	}
+	svc.cardRepo = staleCardRepository{deps.cards}
+
+	if err := svc.ProcessPayment(ctx, deps.userID, card.ID, 50, "shop-1"); err != nil {
+		t.Fatalf("ProcessPayment failed: %v", err)
+	}
+	// Второй платеж прочитал карту активной, но закрыть ее уже не может
+	if err := svc.ProcessPayment(ctx, deps.userID, card.ID, 50, "shop-1"); !errors.Is(err, ErrCardBlocked) {
+		t.Errorf("expected ErrCardBlocked for concurrent payment, got %v", err)
+	}
+}
+
+func TestCardService_PINSetAndChange(t *testing.T) {
+	svc, deps := setupCardHoldService(t)
+	ctx := context.Background()
+	issueTestCard(t, svc, deps.cards.cards[1], "7777001234567890", "03/28", "123")
+
+	if _, err := svc.VerifyPIN(ctx, deps.userID, 1, "4821"); !errors.Is(err, domain.ErrPINNotSet) {
+		t.Fatalf("expected ErrPINNotSet, got %v", err)
+	}
+	if err := svc.SetPIN(ctx, deps.userID, 1, "1111"); !errors.Is(err, domain.ErrInvalidPIN) {
+		t.Errorf("expected weak PIN to be rejected, got %v", err)
+	}
+	if err := svc.SetPIN(ctx, deps.userID, 1, "4821"); err != nil {
+		t.Fatalf("SetPIN failed: %v", err)
+	}
+
+	card := deps.cards.cards[1]
+	if card.PINSalt == "" || strings.Contains(card.PINHash, "4821") {
+		t.Errorf("PIN must be stored as a salted hash, got hash=%q salt=%q", card.PINHash, card.PINSalt)
+	}
+	if err := svc.SetPIN(ctx, deps.userID, 1, "5930"); !errors.Is(err, domain.ErrPINAlreadySet) {
+		t.Errorf("expected ErrPINAlreadySet, got %v", err)
+	}
+
+	if err := svc.ChangePIN(ctx, deps.userID, 1, "0000", "5930"); !errors.Is(err, domain.ErrIncorrectPIN) {
+		t.Fatalf("expected ErrIncorrectPIN for wrong current PIN, got %v", err)
+	}
+	if err := svc.ChangePIN(ctx, deps.userID, 1, "4821", "5930"); err != nil {
+		t.Fatalf("ChangePIN failed: %v", err)
+	}
+	if card.PINFailedAttempts != 0 {
+		t.Errorf("expected PIN attempts reset after change, got %d", card.PINFailedAttempts)
+	}
+	if _, err := svc.VerifyPIN(ctx, deps.userID, 1, "4821"); !errors.Is(err, domain.ErrIncorrectPIN) {
+		t.Errorf("expected old PIN to be rejected, got %v", err)
+	}
+	if _, err := svc.VerifyPIN(ctx, deps.userID, 1, "5930"); err != nil {
+		t.Errorf("expected new PIN to verify, got %v", err)
+	}
+
+	issued, err := svc.CreateCard(ctx, deps.userID, domain.CreateCardRequest{AccountID: 1, PIN: "2468"})
+	if err != nil {
+		t.Fatalf("CreateCard with PIN failed: %v", err)
+	}
+	if _, err := svc.VerifyPIN(ctx, deps.userID, issued.ID, "2468"); err != nil {
+		t.Errorf("expected PIN set at issue to verify, got %v", err)
+	}
+}
+
+func TestCardService_CoHolderCardPowers(t *testing.T) {
+	svc, deps := setupCardHoldService(t)
+	ctx := context.Background()
+	coHolder := deps.userID + 100
+	issueTestCard(t, svc, deps.cards.cards[1], "7777001234567890", "03/28", "123")
+
+	// В моке CheckAccountPermission требует владения счетом, как для совладельца без права управления
+	if _, err := svc.CreateCard(ctx, coHolder, domain.CreateCardRequest{AccountID: 1}); !isForbidden(err) {
+		t.Fatalf("expected 403 for card issue without manage permission, got %v", err)
+	}
+
+	// Карта владельца: совладелец с доступом к операциям не меняет PIN и не видит реквизиты
+	if err := svc.SetPIN(ctx, coHolder, 1, "4821"); !isForbidden(err) {
+		t.Errorf("expected 403 for SetPIN by non-holder, got %v", err)
+	}
+	if _, err := svc.DecryptCardData(ctx, coHolder, deps.cards.cards[1]); !isForbidden(err) {
+		t.Errorf("expected 403 for card data of another holder, got %v", err)
+	}
+	if err := svc.SetPIN(ctx, deps.userID, 1, "4821"); err != nil {
+		t.Fatalf("SetPIN by account owner failed: %v", err)
+	}
+	if err := svc.ChangePIN(ctx, coHolder, 1, "4821", "5930"); !isForbidden(err) {
+		t.Errorf("expected 403 for ChangePIN by non-holder, got %v", err)
+	}
+	if deps.cards.cards[1].PINFailedAttempts != 0 {
+		t.Errorf("rejected ChangePIN must not count as a PIN attempt")
+	}
+
+	// Держатель карты на чужом счете управляет PIN своей карты, владелец счета — тоже
+	issued, err := svc.CreateCard(ctx, deps.userID, domain.CreateCardRequest{AccountID: 1})
+	if err != nil {
+		t.Fatalf("CreateCard failed: %v", err)
+	}
+	if issued.HolderID != deps.userID {
+		t.Fatalf("expected issuer to be the cardholder, got %d", issued.HolderID)
+	}
+	deps.cards.cards[issued.ID].HolderID = coHolder
+	if err := svc.SetPIN(ctx, coHolder, issued.ID, "2468"); err != nil {
+		t.Errorf("SetPIN by cardholder failed: %v", err)
+	}
+	if _, err := svc.DecryptCardData(ctx, coHolder, deps.cards.cards[issued.ID]); err != nil {
+		t.Errorf("DecryptCardData by cardholder failed: %v", err)
+	}
+	if err := svc.ChangePIN(ctx, deps.userID, issued.ID, "2468", "1357"); err != nil {
+		t.Errorf("ChangePIN by account owner failed: %v", err)
+	}
+}
+
+// isForbidden проверяет, что сервис вернул ServiceError с кодом 403
+func isForbidden(err error) bool {
+	var serviceErr *ServiceError
+	return errors.As(err, &serviceErr) && serviceErr.Code == http.StatusForbidden
+}
+
+func TestCardService_PINAttemptsBlockCard(t *testing.T) {
+	svc, deps := setupCardHoldService(t)
+	ctx := context.Background()
+	issueTestCard(t, svc, deps.cards.cards[1], "7777001234567890", "03/28", "123")
+	if err := svc.SetPIN(ctx, deps.userID, 1, "4821"); err != nil {
+		t.Fatalf("SetPIN failed: %v", err)
+	}
+
+	accountService := NewAccountService(&config.Config{}, deps.accounts, deps.transactions, deps.cards, nil, nil, nil, &mockStoreAccessControl{accounts: deps.accounts},
+		mockTxManager{}, nil, svc, mockFraudService{}, mockSanctionsService{}, mockKYCService{}, svc.auditService, svc.logger)
+
+	for i := 0; i < 2; i++ {
+		if err := accountService.WithdrawByCard(ctx, deps.userID, 1, 1, "0000", 200); !errors.Is(err, domain.ErrIncorrectPIN) {
+			t.Fatalf("expected ErrIncorrectPIN, got %v", err)
+		}
+	}
+	if deps.accounts.accounts[1].Balance != 1000 {
+		t.Fatalf("expected no withdrawal with wrong PIN, balance %.2f", deps.accounts.accounts[1].Balance)
+	}
+
+	// Верный PIN сбрасывает счетчик неверных попыток
+	if err := accountService.WithdrawByCard(ctx, deps.userID, 1, 1, "4821", 200); err != nil {
+		t.Fatalf("WithdrawByCard failed: %v", err)
+	}
+	if deps.accounts.accounts[1].Balance != 800 {
+		t.Errorf("expected balance 800, got %.2f", deps.accounts.accounts[1].Balance)
+	}
+	if deps.cards.cards[1].PINFailedAttempts != 0 {
+		t.Errorf("expected PIN attempts reset, got %d", deps.cards.cards[1].PINFailedAttempts)
+	}
+
+	for i := 0; i < 3; i++ {
+		if _, err := svc.VerifyPIN(ctx, deps.userID, 1, "0000"); !errors.Is(err, domain.ErrIncorrectPIN) {
+			t.Fatalf("expected ErrIncorrectPIN, got %v", err)
+		}
+	}
+	if deps.cards.cards[1].Status != domain.CardStatusBlocked {
+		t.Fatalf("expected card blocked after 3 wrong PINs, got %s", deps.cards.cards[1].Status)
+	}
+	if err := accountService.WithdrawByCard(ctx, deps.userID, 1, 1, "4821", 100); !errors.Is(err, ErrCardBlocked) {
+		t.Errorf("expected ErrCardBlocked with correct PIN on blocked card, got %v", err)
+	}
+
+	if err := svc.UnblockCard(ctx, deps.userID+500, 1); err != nil {
+		t.Fatalf("UnblockCard failed: %v", err)
+	}
+	if err := svc.UnblockCard(ctx, deps.userID+500, 1); !errors.Is(err, domain.ErrCardNotBlocked) {
+		t.Errorf("expected ErrCardNotBlocked, got %v", err)
+	}
+	if err := accountService.WithdrawByCard(ctx, deps.userID, 1, 1, "4821", 100); err != nil {
+		t.Errorf("expected withdrawal after unblock, got %v", err)
+	}
+}
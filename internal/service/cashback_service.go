@@ -0,0 +1,316 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math"
+	"net/http"
+	"time"
+
+	"github.com/vterdunov/learn-bank-app/internal/config"
+	"github.com/vterdunov/learn-bank-app/internal/domain"
+	"github.com/vterdunov/learn-bank-app/internal/repository"
+	"github.com/vterdunov/learn-bank-app/internal/utils"
+	"github.com/vterdunov/learn-bank-app/pkg/logger"
+)
+
+// cashbackEntriesLimit число последних начислений в ответе о кэшбэке карты
+const cashbackEntriesLimit = 50
+
+// cashbackService начисляет кэшбэк за списанные платежи картой, сторнирует его при возвратах
+// и раз в месяц выплачивает накопленное на счет карты.
+// Ставка берется из правила для MCC или категории платежа, иначе — базовая utils.GetCashbackRate().
+type cashbackService struct {
+	cashbackRepo  repository.CashbackRepository
+	accessControl domain.AccessControlService
+	txManager     repository.TxManager
+	auditService  AuditService
+	clock         utils.Clock
+	location      *time.Location
+	defaultRate   float64
+	monthlyCap    float64
+	logger        *slog.Logger
+}
+
+// NewCashbackService создает новый экземпляр CashbackService
+func NewCashbackService(
+	cfg *config.Config,
+	cashbackRepo repository.CashbackRepository,
+	accessControl domain.AccessControlService,
+	txManager repository.TxManager,
+	auditService AuditService,
+	clock utils.Clock,
+	lg *slog.Logger,
+) (CashbackService, error) {
+	location, err := time.LoadLocation(cfg.EOD.Timezone)
+	if err != nil {
+		return nil, fmt.Errorf("invalid EOD timezone %q: %w", cfg.EOD.Timezone, err)
+	}
+
+	return &cashbackService{
+		cashbackRepo:  cashbackRepo,
+		accessControl: accessControl,
+		txManager:     txManager,
+		auditService:  auditService,
+		clock:         clock,
+		location:      location,
+		defaultRate:   utils.GetCashbackRate(),
+		monthlyCap:    cfg.Cashback.MonthlyCap,
+		logger:        logger.WithService(lg, "cashback_service"),
+	}, nil
+}
+
+// AccruePayment начисляет кэшбэк за списанный платеж картой с учетом месячных лимитов карты и правила.
+// Вызывается в транзакции списания.
+func (s *cashbackService) AccruePayment(ctx context.Context, cardID int, payment *domain.Transaction) error {
+	if payment.FromAccount == nil || payment.Amount <= 0 {
+		return nil
+	}
+
+	rate := s.defaultRate
+	var ruleID *int
+	var ruleCap float64
+	rule, err := s.cashbackRepo.FindRule(ctx, payment.MCC, domain.MCCCategory(payment.MCC))
+	switch {
+	case err == nil:
+		rate, ruleID, ruleCap = rule.Rate, &rule.ID, rule.MonthlyCap
+	case !errors.Is(err, domain.ErrCashbackRuleNotFound):
+		return fmt.Errorf("failed to find cashback rule: %w", err)
+	}
+	if rate <= 0 {
+		return nil
+	}
+
+	period := domain.CashbackPeriod(s.clock.Now(), s.location)
+	totals, err := s.cashbackRepo.GetPeriodTotals(ctx, cardID, period, ruleID)
+	if err != nil {
+		return fmt.Errorf("failed to get cashback totals: %w", err)
+	}
+
+	amount := domain.CashbackAmount(payment.Amount, rate,
+		remainingCap(s.monthlyCap, totals.Card), remainingCap(ruleCap, totals.Rule))
+	if amount <= 0 {
+		return nil
+	}
+
+	entry := &domain.CashbackEntry{
+		CardID:        cardID,
+		AccountID:     *payment.FromAccount,
+		TransactionID: payment.ID,
+		RuleID:        ruleID,
+		Kind:          domain.CashbackEntryAccrual,
+		Period:        period,
+		BaseAmount:    payment.Amount,
+		Rate:          rate,
+		Amount:        amount,
+	}
+	if _, err := s.cashbackRepo.AddEntry(ctx, entry); err != nil {
+		return fmt.Errorf("failed to accrue cashback: %w", err)
+	}
+
+	s.logger.Info("Cashback accrued", "card_id", cardID, "transaction_id", payment.ID, "rate", rate, "amount", amount)
+	return nil
+}
+
+// ReversePayment сторнирует кэшбэк платежа пропорционально общей возвращенной сумме.
+// Сторно относится к месяцу начисления, а если он уже выплачен — к текущему месяцу.
+// Вызывается в транзакции возврата.
+func (s *cashbackService) ReversePayment(ctx context.Context, transactionID int, refundedTotal float64) error {
+	cashback, err := s.cashbackRepo.GetPaymentCashback(ctx, transactionID)
+	if err != nil {
+		if errors.Is(err, domain.ErrCashbackNotAccrued) {
+			return nil
+		}
+		return fmt.Errorf("failed to get payment cashback: %w", err)
+	}
+
+	accrual := cashback.Accrual
+	target := math.Min(math.Round(accrual.Amount*refundedTotal/accrual.BaseAmount*100)/100, accrual.Amount)
+	amount := math.Round((target-cashback.Reversed)*100) / 100
+	if amount <= 0 {
+		return nil
+	}
+
+	period := accrual.Period
+	paid, err := s.cashbackRepo.HasPayout(ctx, accrual.CardID, period)
+	if err != nil {
+		return fmt.Errorf("failed to check cashback payout: %w", err)
+	}
+	if paid {
+		period = domain.CashbackPeriod(s.clock.Now(), s.location)
+	}
+
+	entry := &domain.CashbackEntry{
+		CardID:        accrual.CardID,
+		AccountID:     accrual.AccountID,
+		TransactionID: transactionID,
+		RuleID:        accrual.RuleID,
+		Kind:          domain.CashbackEntryReversal,
+		Period:        period,
+		BaseAmount:    refundedTotal,
+		Rate:          accrual.Rate,
+		Amount:        -amount,
+	}
+	if _, err := s.cashbackRepo.AddEntry(ctx, entry); err != nil {
+		return fmt.Errorf("failed to reverse cashback: %w", err)
+	}
+
+	s.logger.Info("Cashback reversed", "card_id", accrual.CardID, "transaction_id", transactionID, "amount", amount)
+	return nil
+}
+
+// GetCardRewards возвращает кэшбэк карты: текущий месяц с лимитом, ожидающие выплаты суммы и историю
+func (s *cashbackService) GetCardRewards(ctx context.Context, userID, cardID int) (*CardRewards, error) {
+	if err := s.accessControl.CanAccessCard(ctx, userID, cardID); err != nil {
+		s.logger.Warn("Access denied for card rewards", "user_id", userID, "card_id", cardID)
+		if domain.IsAccessDeniedError(err) {
+			return nil, &ServiceError{Code: http.StatusForbidden, Message: err.Error()}
+		}
+		return nil, ErrCardNotFound
+	}
+
+	period := domain.CashbackPeriod(s.clock.Now(), s.location)
+	totals, err := s.cashbackRepo.GetPeriodTotals(ctx, cardID, period, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get cashback totals: %w", err)
+	}
+	balance, err := s.cashbackRepo.GetBalance(ctx, cardID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get cashback balance: %w", err)
+	}
+	entries, err := s.cashbackRepo.ListEntries(ctx, cardID, cashbackEntriesLimit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get cashback entries: %w", err)
+	}
+	payouts, err := s.cashbackRepo.ListPayouts(ctx, cardID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get cashback payouts: %w", err)
+	}
+
+	rewards := &CardRewards{
+		CardID:       cardID,
+		Period:       period,
+		PeriodAmount: totals.Card,
+		MonthlyCap:   s.monthlyCap,
+		Pending:      balance.Pending,
+		Paid:         balance.Paid,
+		Entries:      entries,
+		Payouts:      payouts,
+	}
+	if s.monthlyCap > 0 {
+		rewards.CapRemaining = remainingCap(s.monthlyCap, totals.Card)
+	}
+
+	return rewards, nil
+}
+
+// CreateRule создает правило кэшбэка для MCC или категории
+func (s *cashbackService) CreateRule(ctx context.Context, adminID int, req domain.CreateCashbackRuleRequest) (*domain.CashbackRule, error) {
+	rule := &domain.CashbackRule{
+		MCC:        req.MCC,
+		Category:   req.Category,
+		Rate:       req.Rate,
+		MonthlyCap: req.MonthlyCap,
+	}
+	if err := rule.Validate(); err != nil {
+		return nil, err
+	}
+
+	if err := s.cashbackRepo.CreateRule(ctx, rule); err != nil {
+		if !errors.Is(err, domain.ErrCashbackRuleExists) {
+			s.logger.Error("Failed to create cashback rule", "error", err)
+		}
+		return nil, err
+	}
+
+	s.logger.Info("Cashback rule created",
+		"rule_id", rule.ID,
+		"mcc", rule.MCC,
+		"category", rule.Category,
+		"rate", rule.Rate,
+		"monthly_cap", rule.MonthlyCap,
+		"admin_id", adminID)
+
+	s.audit(ctx, adminID, domain.AuditActionAdminCashbackRuleCreate, rule.ID, rule)
+	return rule, nil
+}
+
+// ListRules возвращает действующие правила кэшбэка
+func (s *cashbackService) ListRules(ctx context.Context) ([]*domain.CashbackRule, error) {
+	return s.cashbackRepo.ListRules(ctx)
+}
+
+// DeleteRule отключает правило; новые платежи получают ставку по категории или базовую
+func (s *cashbackService) DeleteRule(ctx context.Context, adminID, ruleID int) error {
+	if err := s.cashbackRepo.DeactivateRule(ctx, ruleID); err != nil {
+		return err
+	}
+
+	s.logger.Info("Cashback rule deleted", "rule_id", ruleID, "admin_id", adminID)
+
+	s.audit(ctx, adminID, domain.AuditActionAdminCashbackRuleDelete, ruleID, nil)
+	return nil
+}
+
+// PayoutCashback выплачивает кэшбэк за прошедшие месяцы на счета карт.
+// Отрицательный итог месяца (сторно больше начислений) списывается без выплаты.
+func (s *cashbackService) PayoutCashback(ctx context.Context) (*JobResult, error) {
+	before := domain.CashbackPeriod(s.clock.Now(), s.location)
+
+	unpaid, err := s.cashbackRepo.ListUnpaid(ctx, before)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get unpaid cashback: %w", err)
+	}
+
+	result := &JobResult{}
+	for _, payout := range unpaid {
+		if err := ctx.Err(); err != nil {
+			return result, err
+		}
+
+		payout.Amount = math.Max(math.Round(payout.Amount*100)/100, 0)
+		description := fmt.Sprintf("Кэшбэк по карте за %s", payout.Period.Format("01.2006"))
+
+		var created bool
+		err := s.txManager.WithinTx(ctx, func(ctx context.Context) error {
+			var err error
+			created, err = s.cashbackRepo.CreatePayout(ctx, payout, description)
+			return err
+		})
+		if err != nil {
+			s.logger.Error("Failed to pay out cashback", "card_id", payout.CardID, "period", payout.Period.Format("2006-01"), "error", err)
+			result.Failed++
+			continue
+		}
+		if created {
+			s.logger.Info("Cashback paid out",
+				"card_id", payout.CardID,
+				"account_id", payout.AccountID,
+				"period", payout.Period.Format("2006-01"),
+				"amount", payout.Amount)
+			result.Processed++
+		}
+	}
+
+	return result, nil
+}
+
+func (s *cashbackService) audit(ctx context.Context, adminID int, action string, ruleID int, rule *domain.CashbackRule) {
+	event := NewUserAuditEvent(adminID, action, "cashback_rule", auditResourceID(ruleID))
+	event.ActorType = domain.AuditActorAdmin
+	if rule != nil {
+		event.After = domain.NewAuditState(rule)
+	}
+	// Ошибка аудита уже залогирована, действие выполнено
+	_ = s.auditService.Record(ctx, event)
+}
+
+// remainingCap возвращает остаток месячного лимита; -1 — лимит не задан
+func remainingCap(limit, used float64) float64 {
+	if limit <= 0 {
+		return -1
+	}
+	return math.Max(math.Round((limit-used)*100)/100, 0)
+}
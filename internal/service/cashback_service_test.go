@@ -0,0 +1,464 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/vterdunov/learn-bank-app/internal/domain"
+)
+
+// MockCashbackRepository правила, начисления и выплаты кэшбэка в памяти; выплаты зачисляются на счета MockAccountStore
+type MockCashbackRepository struct {
+	accounts *MockAccountStore
+	rules    []*domain.CashbackRule
+	inactive map[int]bool
+	entries  []*domain.CashbackEntry
+	payouts  []*domain.CashbackPayout
+}
+
+func (m *MockCashbackRepository) CreateRule(ctx context.Context, rule *domain.CashbackRule) error {
+	for _, existing := range m.rules {
+		if m.inactive[existing.ID] {
+			continue
+		}
+		if (rule.MCC != "" && existing.MCC == rule.MCC) || (rule.Category != "" && existing.Category == rule.Category) {
+			return domain.ErrCashbackRuleExists
+		}
+	}
+	rule.ID = len(m.rules) + 1
+	copied := *rule
+	m.rules = append(m.rules, &copied)
+	return nil
+}
+
+func (m *MockCashbackRepository) ListRules(ctx context.Context) ([]*domain.CashbackRule, error) {
+	var rules []*domain.CashbackRule
+	for _, rule := range m.rules {
+		if !m.inactive[rule.ID] {
+			rules = append(rules, rule)
+		}
+	}
+	return rules, nil
+}
+
+func (m *MockCashbackRepository) FindRule(ctx context.Context, mcc, category string) (*domain.CashbackRule, error) {
+	var found *domain.CashbackRule
+	for _, rule := range m.rules {
+		if m.inactive[rule.ID] {
+			continue
+		}
+		if mcc != "" && rule.MCC == mcc {
+			copied := *rule
+			return &copied, nil
+		}
+		if rule.Category == category {
+			copied := *rule
+			found = &copied
+		}
+	}
+	if found == nil {
+		return nil, domain.ErrCashbackRuleNotFound
+	}
+	return found, nil
+}
+
+func (m *MockCashbackRepository) DeactivateRule(ctx context.Context, id int) error {
+	if id < 1 || id > len(m.rules) || m.inactive[id] {
+		return domain.ErrCashbackRuleNotFound
+	}
+	m.inactive[id] = true
+	return nil
+}
+
+func (m *MockCashbackRepository) AddEntry(ctx context.Context, entry *domain.CashbackEntry) (bool, error) {
+	if entry.Kind == domain.CashbackEntryAccrual {
+		for _, existing := range m.entries {
+			if existing.Kind == domain.CashbackEntryAccrual && existing.TransactionID == entry.TransactionID {
+				return false, nil
+			}
+		}
+	}
+	entry.ID = int64(len(m.entries) + 1)
+	copied := *entry
+	m.entries = append(m.entries, &copied)
+	return true, nil
+}
+
+func (m *MockCashbackRepository) GetPaymentCashback(ctx context.Context, transactionID int) (*domain.PaymentCashback, error) {
+	cashback := &domain.PaymentCashback{}
+	for _, entry := range m.entries {
+		if entry.TransactionID != transactionID {
+			continue
+		}
+		if entry.Kind == domain.CashbackEntryAccrual {
+			copied := *entry
+			cashback.Accrual = &copied
+		} else {
+			cashback.Reversed -= entry.Amount
+		}
+	}
+	if cashback.Accrual == nil {
+		return nil, domain.ErrCashbackNotAccrued
+	}
+	return cashback, nil
+}
+
+func (m *MockCashbackRepository) GetPeriodTotals(ctx context.Context, cardID int, period time.Time, ruleID *int) (*domain.CashbackPeriodTotals, error) {
+	totals := &domain.CashbackPeriodTotals{}
+	for _, entry := range m.entries {
+		if entry.CardID != cardID || !entry.Period.Equal(period) {
+			continue
+		}
+		totals.Card += entry.Amount
+		if ruleID != nil && entry.RuleID != nil && *entry.RuleID == *ruleID {
+			totals.Rule += entry.Amount
+		}
+	}
+	return totals, nil
+}
+
+func (m *MockCashbackRepository) HasPayout(ctx context.Context, cardID int, period time.Time) (bool, error) {
+	for _, payout := range m.payouts {
+		if payout.CardID == cardID && payout.Period.Equal(period) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (m *MockCashbackRepository) GetBalance(ctx context.Context, cardID int) (*domain.CashbackBalance, error) {
+	balance := &domain.CashbackBalance{}
+	for _, entry := range m.entries {
+		if entry.CardID != cardID {
+			continue
+		}
+		if paid, _ := m.HasPayout(ctx, cardID, entry.Period); !paid {
+			balance.Pending += entry.Amount
+		}
+	}
+	for _, payout := range m.payouts {
+		if payout.CardID == cardID {
+			balance.Paid += payout.Amount
+		}
+	}
+	return balance, nil
+}
+
+func (m *MockCashbackRepository) ListEntries(ctx context.Context, cardID, limit int) ([]*domain.CashbackEntry, error) {
+	var entries []*domain.CashbackEntry
+	for i := len(m.entries) - 1; i >= 0 && len(entries) < limit; i-- {
+		if m.entries[i].CardID == cardID {
+			entries = append(entries, m.entries[i])
+		}
+	}
+	return entries, nil
+}
+
+func (m *MockCashbackRepository) ListPayouts(ctx context.Context, cardID int) ([]*domain.CashbackPayout, error) {
+	var payouts []*domain.CashbackPayout
+	for _, payout := range m.payouts {
+		if payout.CardID == cardID {
+			payouts = append(payouts, payout)
+		}
+	}
+	return payouts, nil
+}
+
+func (m *MockCashbackRepository) ListUnpaid(ctx context.Context, before time.Time) ([]*domain.CashbackPayout, error) {
+	var payouts []*domain.CashbackPayout
+	for _, entry := range m.entries {
+		if !entry.Period.Before(before) {
+			continue
+		}
+		if paid, _ := m.HasPayout(ctx, entry.CardID, entry.Period); paid {
+			continue
+		}
+		var found bool
+		for _, payout := range payouts {
+			if payout.CardID == entry.CardID && payout.Period.Equal(entry.Period) {
+				payout.Amount += entry.Amount
+				found = true
+			}
+		}
+		if !found {
+			payouts = append(payouts, &domain.CashbackPayout{
+				CardID: entry.CardID, AccountID: entry.AccountID, Period: entry.Period, Amount: entry.Amount,
+			})
+		}
+	}
+	return payouts, nil
+}
+
+func (m *MockCashbackRepository) CreatePayout(ctx context.Context, payout *domain.CashbackPayout, description string) (bool, error) {
+	if paid, _ := m.HasPayout(ctx, payout.CardID, payout.Period); paid {
+		return false, nil
+	}
+	payout.ID = int64(len(m.payouts) + 1)
+	m.payouts = append(m.payouts, payout)
+	if payout.Amount > 0 {
+		m.accounts.accounts[payout.AccountID].Balance += payout.Amount
+	}
+	return true, nil
+}
+
+// cashbackTotal сумма начислений и сторно кэшбэка в моке
+func cashbackTotal(repo *MockCashbackRepository) float64 {
+	var total float64
+	for _, entry := range repo.entries {
+		total += entry.Amount
+	}
+	return total
+}
+
+func TestCashbackService_AccrueWithRules(t *testing.T) {
+	svc, deps := setupCardHoldService(t)
+	ctx := context.Background()
+
+	// Базовая ставка 1% для платежа без ТСП
+	if err := svc.ProcessPayment(ctx, deps.userID, 1, 150.55, "shop-1"); err != nil {
+		t.Fatalf("ProcessPayment failed: %v", err)
+	}
+	if len(deps.cashback.entries) != 1 || deps.cashback.entries[0].Amount != 1.5 || deps.cashback.entries[0].RuleID != nil {
+		t.Fatalf("unexpected default accrual: %+v", deps.cashback.entries)
+	}
+
+	// Правило по категории и более приоритетное правило по MCC того же ТСП
+	cashbackSvc := deps.cashbackService
+	if _, err := cashbackSvc.CreateRule(ctx, 1, domain.CreateCashbackRuleRequest{Category: domain.SpendingCategoryGroceries, Rate: 3}); err != nil {
+		t.Fatalf("CreateRule failed: %v", err)
+	}
+	if err := svc.ProcessPayment(ctx, deps.userID, 1, 100, "grocery-1"); err != nil {
+		t.Fatalf("ProcessPayment failed: %v", err)
+	}
+	if amount := deps.cashback.entries[1].Amount; amount != 3 {
+		t.Errorf("expected category cashback 3, got %v", amount)
+	}
+
+	rule, err := cashbackSvc.CreateRule(ctx, 1, domain.CreateCashbackRuleRequest{MCC: "5411", Rate: 5, MonthlyCap: 7})
+	if err != nil {
+		t.Fatalf("CreateRule failed: %v", err)
+	}
+	if err := svc.ProcessPayment(ctx, deps.userID, 1, 100, "grocery-1"); err != nil {
+		t.Fatalf("ProcessPayment failed: %v", err)
+	}
+	if entry := deps.cashback.entries[2]; entry.Amount != 5 || entry.RuleID == nil || *entry.RuleID != rule.ID {
+		t.Errorf("unexpected mcc accrual: %+v", entry)
+	}
+
+	// Лимит правила 7: из 5 за следующий платеж остается 2
+	if err := svc.ProcessPayment(ctx, deps.userID, 1, 100, "grocery-1"); err != nil {
+		t.Fatalf("ProcessPayment failed: %v", err)
+	}
+	if amount := deps.cashback.entries[3].Amount; amount != 2 {
+		t.Errorf("expected rule cap to leave 2, got %v", amount)
+	}
+	if err := svc.ProcessPayment(ctx, deps.userID, 1, 100, "grocery-1"); err != nil {
+		t.Fatalf("ProcessPayment failed: %v", err)
+	}
+	if len(deps.cashback.entries) != 4 {
+		t.Errorf("expected no accrual over rule cap, got %d entries", len(deps.cashback.entries))
+	}
+
+	// После отключения правила MCC снова действует правило категории
+	if err := cashbackSvc.DeleteRule(ctx, 1, rule.ID); err != nil {
+		t.Fatalf("DeleteRule failed: %v", err)
+	}
+	if err := cashbackSvc.DeleteRule(ctx, 1, rule.ID); !errors.Is(err, domain.ErrCashbackRuleNotFound) {
+		t.Errorf("expected ErrCashbackRuleNotFound, got %v", err)
+	}
+	if err := svc.ProcessPayment(ctx, deps.userID, 1, 10, "grocery-1"); err != nil {
+		t.Fatalf("ProcessPayment failed: %v", err)
+	}
+	if amount := deps.cashback.entries[4].Amount; amount != 0.3 {
+		t.Errorf("expected category cashback 0.3, got %v", amount)
+	}
+
+	tests := []struct {
+		name string
+		req  domain.CreateCashbackRuleRequest
+		want error
+	}{
+		{"duplicate category", domain.CreateCashbackRuleRequest{Category: domain.SpendingCategoryGroceries, Rate: 2}, domain.ErrCashbackRuleExists},
+		{"both targets", domain.CreateCashbackRuleRequest{MCC: "5812", Category: domain.SpendingCategoryRestaurants, Rate: 2}, domain.ErrInvalidCashbackRule},
+		{"unknown category", domain.CreateCashbackRuleRequest{Category: "casino", Rate: 2}, domain.ErrInvalidCategory},
+		{"invalid rate", domain.CreateCashbackRuleRequest{MCC: "5812", Rate: 120}, domain.ErrInvalidCashbackRate},
+		{"negative cap", domain.CreateCashbackRuleRequest{MCC: "5812", Rate: 2, MonthlyCap: -1}, domain.ErrInvalidCashbackCap},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := cashbackSvc.CreateRule(ctx, 1, tt.req); !errors.Is(err, tt.want) {
+				t.Errorf("expected %v, got %v", tt.want, err)
+			}
+		})
+	}
+}
+
+func TestCashbackService_MonthlyCap(t *testing.T) {
+	svc, deps := setupCardHoldService(t)
+	ctx := context.Background()
+	deps.accounts.accounts[1].Balance = 100000
+	deps.cashbackService.(*cashbackService).monthlyCap = 30
+
+	if _, err := deps.cashbackService.CreateRule(ctx, 1, domain.CreateCashbackRuleRequest{MCC: "5411", Rate: 10}); err != nil {
+		t.Fatalf("CreateRule failed: %v", err)
+	}
+
+	// Лимит карты 30 за месяц: 25 + 5, дальше начисления нет
+	for _, amount := range []float64{250, 250, 250} {
+		if err := svc.ProcessPayment(ctx, deps.userID, 1, amount, "grocery-1"); err != nil {
+			t.Fatalf("ProcessPayment failed: %v", err)
+		}
+	}
+	if len(deps.cashback.entries) != 2 || cashbackTotal(deps.cashback) != 30 {
+		t.Fatalf("expected 2 accruals totaling 30, got %+v", deps.cashback.entries)
+	}
+
+	// В следующем месяце лимит снова доступен
+	deps.clock.now = time.Date(2026, 4, 1, 0, 0, 0, 0, time.UTC)
+	if err := svc.ProcessPayment(ctx, deps.userID, 1, 100, "grocery-1"); err != nil {
+		t.Fatalf("ProcessPayment failed: %v", err)
+	}
+	entry := deps.cashback.entries[len(deps.cashback.entries)-1]
+	if entry.Amount != 10 || !entry.Period.Equal(time.Date(2026, 4, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("unexpected next month accrual: %+v", entry)
+	}
+}
+
+func TestCashbackService_RefundReversal(t *testing.T) {
+	svc, deps := setupCardHoldService(t)
+	ctx := context.Background()
+
+	hold, err := svc.AuthorizePayment(ctx, deps.userID, 1, 300, "shop-1", "")
+	if err != nil {
+		t.Fatalf("AuthorizePayment failed: %v", err)
+	}
+	if len(deps.cashback.entries) != 0 {
+		t.Fatalf("expected no cashback for authorization, got %d entries", len(deps.cashback.entries))
+	}
+	if _, err := svc.CapturePayment(ctx, deps.userID, hold.ID, 200); err != nil {
+		t.Fatalf("CapturePayment failed: %v", err)
+	}
+	if cashbackTotal(deps.cashback) != 2 {
+		t.Fatalf("expected cashback 2 for captured 200, got %v", cashbackTotal(deps.cashback))
+	}
+
+	// Частичный возврат сторнирует кэшбэк пропорционально
+	if _, err := svc.RefundPayment(ctx, deps.userID, hold.ID, 50); err != nil {
+		t.Fatalf("RefundPayment failed: %v", err)
+	}
+	if total := cashbackTotal(deps.cashback); total != 1.5 {
+		t.Errorf("expected cashback 1.5 after partial refund, got %v", total)
+	}
+
+	// Возврат остатка сторнирует кэшбэк полностью
+	if _, err := svc.RefundPayment(ctx, deps.userID, hold.ID, 0); err != nil {
+		t.Fatalf("RefundPayment failed: %v", err)
+	}
+	if total := cashbackTotal(deps.cashback); total != 0 {
+		t.Errorf("expected no cashback after full refund, got %v", total)
+	}
+	if len(deps.cashback.entries) != 3 {
+		t.Errorf("expected accrual and 2 reversals, got %d entries", len(deps.cashback.entries))
+	}
+}
+
+func TestCashbackService_PayoutCashback(t *testing.T) {
+	svc, deps := setupCardHoldService(t)
+	ctx := context.Background()
+
+	if err := svc.ProcessPayment(ctx, deps.userID, 1, 500, "shop-1"); err != nil {
+		t.Fatalf("ProcessPayment failed: %v", err)
+	}
+
+	// Текущий месяц не выплачивается
+	result, err := deps.cashbackService.PayoutCashback(ctx)
+	if err != nil {
+		t.Fatalf("PayoutCashback failed: %v", err)
+	}
+	if result.Processed != 0 || len(deps.cashback.payouts) != 0 {
+		t.Errorf("expected no payout for current month, got processed=%d", result.Processed)
+	}
+
+	deps.clock.now = time.Date(2026, 4, 2, 9, 0, 0, 0, time.UTC)
+	if err := svc.ProcessPayment(ctx, deps.userID, 1, 100, "shop-1"); err != nil {
+		t.Fatalf("ProcessPayment failed: %v", err)
+	}
+
+	result, err = deps.cashbackService.PayoutCashback(ctx)
+	if err != nil {
+		t.Fatalf("PayoutCashback failed: %v", err)
+	}
+	if result.Processed != 1 || len(deps.cashback.payouts) != 1 {
+		t.Fatalf("expected 1 payout, got processed=%d payouts=%d", result.Processed, len(deps.cashback.payouts))
+	}
+	payout := deps.cashback.payouts[0]
+	if payout.AccountID != 1 || payout.Amount != 5 || !payout.Period.Equal(time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("unexpected payout: %+v", payout)
+	}
+	if balance := deps.accounts.accounts[1].Balance; balance != 405 {
+		t.Errorf("expected balance 405 after payout, got %v", balance)
+	}
+
+	// Повторный запуск ничего не выплачивает
+	result, err = deps.cashbackService.PayoutCashback(ctx)
+	if err != nil {
+		t.Fatalf("PayoutCashback failed: %v", err)
+	}
+	if result.Processed != 0 || len(deps.cashback.payouts) != 1 {
+		t.Errorf("expected no repeated payout, got processed=%d", result.Processed)
+	}
+
+	rewards, err := deps.cashbackService.GetCardRewards(ctx, deps.userID, 1)
+	if err != nil {
+		t.Fatalf("GetCardRewards failed: %v", err)
+	}
+	if rewards.PeriodAmount != 1 || rewards.Pending != 1 || rewards.Paid != 5 ||
+		rewards.MonthlyCap != 3000 || rewards.CapRemaining != 2999 {
+		t.Errorf("unexpected rewards: %+v", rewards)
+	}
+	if len(rewards.Entries) != 2 || rewards.Entries[0].Amount != 1 || len(rewards.Payouts) != 1 {
+		t.Errorf("unexpected rewards history: entries=%d payouts=%d", len(rewards.Entries), len(rewards.Payouts))
+	}
+}
+
+func TestCashbackService_ReversalAfterPayout(t *testing.T) {
+	svc, deps := setupCardHoldService(t)
+	ctx := context.Background()
+
+	hold, err := svc.AuthorizePayment(ctx, deps.userID, 1, 400, "shop-1", "")
+	if err != nil {
+		t.Fatalf("AuthorizePayment failed: %v", err)
+	}
+	if _, err := svc.CapturePayment(ctx, deps.userID, hold.ID, 0); err != nil {
+		t.Fatalf("CapturePayment failed: %v", err)
+	}
+
+	deps.clock.now = time.Date(2026, 4, 1, 12, 0, 0, 0, time.UTC)
+	if _, err := deps.cashbackService.PayoutCashback(ctx); err != nil {
+		t.Fatalf("PayoutCashback failed: %v", err)
+	}
+
+	// Сторно выплаченного месяца переносится в текущий месяц
+	if _, err := svc.RefundPayment(ctx, deps.userID, hold.ID, 0); err != nil {
+		t.Fatalf("RefundPayment failed: %v", err)
+	}
+	reversal := deps.cashback.entries[len(deps.cashback.entries)-1]
+	if reversal.Amount != -4 || !reversal.Period.Equal(time.Date(2026, 4, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("unexpected reversal: %+v", reversal)
+	}
+
+	// Отрицательный итог месяца закрывается без списания со счета
+	balance := deps.accounts.accounts[1].Balance
+	deps.clock.now = time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+	if _, err := deps.cashbackService.PayoutCashback(ctx); err != nil {
+		t.Fatalf("PayoutCashback failed: %v", err)
+	}
+	if len(deps.cashback.payouts) != 2 || deps.cashback.payouts[1].Amount != 0 {
+		t.Errorf("expected zero payout for negative month, got %+v", deps.cashback.payouts)
+	}
+	if deps.accounts.accounts[1].Balance != balance {
+		t.Errorf("expected balance unchanged, got %v", deps.accounts.accounts[1].Balance)
+	}
+}
@@ -26,7 +26,10 @@ type creditService struct {
 	paymentScheduleRepo repository.PaymentScheduleRepository
 	accountRepo         repository.AccountRepository
 	transactionRepo     repository.TransactionRepository
+	txManager           repository.TxManager
 	cbrService          CBRService
+	notificationService NotificationService
+	auditService        AuditService
 	logger              *slog.Logger
 }
 
@@ -36,7 +39,10 @@ func NewCreditService(
 	paymentScheduleRepo repository.PaymentScheduleRepository,
 	accountRepo repository.AccountRepository,
 	transactionRepo repository.TransactionRepository,
+	txManager repository.TxManager,
 	cbrService CBRService,
+	notificationService NotificationService,
+	auditService AuditService,
 	logger *slog.Logger,
 ) CreditService {
 	return &creditService{
@@ -44,7 +50,10 @@ func NewCreditService(
 		paymentScheduleRepo: paymentScheduleRepo,
 		accountRepo:         accountRepo,
 		transactionRepo:     transactionRepo,
+		txManager:           txManager,
 		cbrService:          cbrService,
+		notificationService: notificationService,
+		auditService:        auditService,
 		logger:              logger,
 	}
 }
@@ -107,39 +116,59 @@ func (s *creditService) CreateCredit(ctx context.Context, userID int, req domain
 		UpdatedAt:      time.Now(),
 	}
 
-	if err := s.creditRepo.Create(ctx, credit); err != nil {
-		s.logger.Error("Failed to create credit", "account_id", req.AccountID, "error", err)
-		return nil, fmt.Errorf("failed to create credit: %w", err)
-	}
+	// Кредит, график, зачисление и уведомление фиксируются в одной транзакции
+	var newBalance float64
+	err = s.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		if err := s.creditRepo.Create(ctx, credit); err != nil {
+			s.logger.Error("Failed to create credit", "account_id", req.AccountID, "error", err)
+			return fmt.Errorf("failed to create credit: %w", err)
+		}
 
-	// Создаем график платежей
-	if err := s.createPaymentSchedule(ctx, credit); err != nil {
-		s.logger.Error("Failed to create payment schedule", "credit_id", credit.ID, "error", err)
-		// Не возвращаем ошибку, так как кредит уже создан
-	}
+		// Создаем график платежей
+		if err := s.createPaymentSchedule(ctx, credit); err != nil {
+			s.logger.Error("Failed to create payment schedule", "credit_id", credit.ID, "error", err)
+			return fmt.Errorf("failed to create payment schedule: %w", err)
+		}
 
-	// Зачисляем кредитные средства на счет
-	newBalance := account.Balance + req.Amount
-	if err := s.accountRepo.UpdateBalance(ctx, req.AccountID, newBalance); err != nil {
-		s.logger.Error("Failed to update balance after credit", "account_id", req.AccountID, "error", err)
-		return nil, fmt.Errorf("failed to update account balance: %w", err)
-	}
+		// Зачисляем кредитные средства на счет
+		if err := s.accountRepo.Credit(ctx, req.AccountID, req.Amount); err != nil {
+			s.logger.Error("Failed to update balance after credit", "account_id", req.AccountID, "error", err)
+			return fmt.Errorf("failed to update account balance: %w", err)
+		}
+		// Остаток после зачисления для журнала аудита: счет уже заблокирован обновлением
+		balance, err := s.accountRepo.GetBalance(ctx, req.AccountID)
+		if err != nil {
+			return fmt.Errorf("failed to get account balance: %w", err)
+		}
+		newBalance = balance
+
+		// Создаем транзакцию о выдаче кредита
+		transaction := &domain.Transaction{
+			FromAccount: nil, // Кредит от банка
+			ToAccount:   &req.AccountID,
+			Amount:      req.Amount,
+			Type:        "credit",
+			Status:      "completed",
+			Description: fmt.Sprintf("Credit disbursement (Credit ID: %d)", credit.ID),
+			CreatedAt:   time.Now(),
+			UpdatedAt:   time.Now(),
+		}
 
-	// Создаем транзакцию о выдаче кредита
-	transaction := &domain.Transaction{
-		FromAccount: nil, // Кредит от банка
-		ToAccount:   &req.AccountID,
-		Amount:      req.Amount,
-		Type:        "credit",
-		Status:      "completed",
-		Description: fmt.Sprintf("Credit disbursement (Credit ID: %d)", credit.ID),
-		CreatedAt:   time.Now(),
-		UpdatedAt:   time.Now(),
-	}
+		if err := s.transactionRepo.Create(ctx, transaction); err != nil {
+			s.logger.Error("Failed to create credit transaction", "credit_id", credit.ID, "error", err)
+			return fmt.Errorf("failed to create credit transaction: %w", err)
+		}
 
-	if err := s.transactionRepo.Create(ctx, transaction); err != nil {
-		s.logger.Error("Failed to create credit transaction", "credit_id", credit.ID, "error", err)
-		// Не возвращаем ошибку, так как кредит уже выдан
+		// Уведомление по настроенным каналам в той же транзакции
+		if err := s.notificationService.NotifyCreditIssued(ctx, userID, credit); err != nil {
+			s.logger.Error("Failed to queue credit notification", "credit_id", credit.ID, "error", err)
+			return fmt.Errorf("failed to queue credit notification: %w", err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
 
 	s.logger.Info("Credit created successfully",
@@ -150,6 +179,17 @@ func (s *creditService) CreateCredit(ctx context.Context, userID int, req domain
 		"monthly_payment", monthlyPayment,
 		"new_balance", newBalance)
 
+	// Запись в журнал аудита
+	event := NewUserAuditEvent(userID, domain.AuditActionCreditIssue, "credit", auditResourceID(credit.ID))
+	event.Before = domain.NewAuditState(map[string]interface{}{"balance": account.Balance})
+	event.After = domain.NewAuditState(credit)
+	event.Metadata = domain.NewAuditState(map[string]interface{}{
+		"account_id":  req.AccountID,
+		"new_balance": newBalance,
+	})
+	// Ошибка аудита уже залогирована, кредит выдан
+	_ = s.auditService.Record(ctx, event)
+
 	return credit, nil
 }
 
@@ -267,8 +307,12 @@ func (s *creditService) processOverduePayment(ctx context.Context, payment *doma
 	penalty := payment.PaymentAmount * 0.10
 	totalAmount := payment.PaymentAmount + penalty
 
-	// Проверяем достаточность средств
-	if account.Balance < totalAmount {
+	// Доступный остаток проверяется в том же запросе, что и списание
+	if err := s.accountRepo.Debit(ctx, credit.AccountID, totalAmount); err != nil {
+		if !errors.Is(err, domain.ErrAccountInsufficientFunds) {
+			return fmt.Errorf("failed to update balance: %w", err)
+		}
+
 		// Недостаточно средств - только начисляем штраф
 		payment.PenaltyAmount += penalty
 		payment.Status = "overdue_with_penalty"
@@ -282,19 +326,13 @@ func (s *creditService) processOverduePayment(ctx context.Context, payment *doma
 			"payment_id", payment.ID,
 			"credit_id", payment.CreditID,
 			"account_id", credit.AccountID,
-			"balance", account.Balance,
+			"available", account.AvailableBalance(),
 			"required", totalAmount,
 			"penalty", penalty)
 
 		return nil
 	}
 
-	// Списываем средства
-	newBalance := account.Balance - totalAmount
-	if err := s.accountRepo.UpdateBalance(ctx, credit.AccountID, newBalance); err != nil {
-		return fmt.Errorf("failed to update balance: %w", err)
-	}
-
 	// Обновляем платеж
 	payment.PenaltyAmount += penalty
 	payment.Status = "paid"
@@ -336,8 +374,7 @@ func (s *creditService) processOverduePayment(ctx context.Context, payment *doma
 		"credit_id", payment.CreditID,
 		"account_id", credit.AccountID,
 		"amount", payment.PaymentAmount,
-		"penalty", penalty,
-		"new_balance", newBalance)
+		"penalty", penalty)
 
 	return nil
 }
@@ -0,0 +1,292 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math"
+	"net/http"
+	"time"
+
+	"github.com/vterdunov/learn-bank-app/internal/config"
+	"github.com/vterdunov/learn-bank-app/internal/domain"
+	"github.com/vterdunov/learn-bank-app/internal/repository"
+	"github.com/vterdunov/learn-bank-app/internal/utils"
+	"github.com/vterdunov/learn-bank-app/pkg/logger"
+)
+
+// fallbackKeyRate ставка, используемая при недоступности ЦБ РФ
+const fallbackKeyRate = 16.0
+
+// defaultSavingsProjectionMonths горизонт прогноза для вкладов без срока
+const defaultSavingsProjectionMonths = 12
+
+var ErrInvalidProjectionMonths = errors.New("invalid projection months")
+
+// depositService реализует интерфейс DepositService
+type depositService struct {
+	depositRepo   repository.DepositRepository
+	accountRepo   repository.AccountRepository
+	accessControl domain.AccessControlService
+	txManager     repository.TxManager
+	cbrService    CBRService
+	auditService  AuditService
+	clock         utils.Clock
+	location      *time.Location
+	logger        *slog.Logger
+}
+
+// NewDepositService создает новый экземпляр DepositService.
+// Даты открытия и окончания вкладов считаются в поясе операционного дня.
+func NewDepositService(
+	cfg *config.Config,
+	depositRepo repository.DepositRepository,
+	accountRepo repository.AccountRepository,
+	accessControl domain.AccessControlService,
+	txManager repository.TxManager,
+	cbrService CBRService,
+	auditService AuditService,
+	clock utils.Clock,
+	lg *slog.Logger,
+) (DepositService, error) {
+	location, err := time.LoadLocation(cfg.EOD.Timezone)
+	if err != nil {
+		return nil, fmt.Errorf("invalid EOD timezone %q: %w", cfg.EOD.Timezone, err)
+	}
+
+	return &depositService{
+		depositRepo:   depositRepo,
+		accountRepo:   accountRepo,
+		accessControl: accessControl,
+		txManager:     txManager,
+		cbrService:    cbrService,
+		auditService:  auditService,
+		clock:         clock,
+		location:      location,
+		logger:        logger.WithService(lg, "deposit_service"),
+	}, nil
+}
+
+// ListProducts возвращает депозитные продукты с текущими ставками
+func (s *depositService) ListProducts(ctx context.Context) ([]*DepositProductOffer, error) {
+	keyRate := s.keyRate(ctx)
+
+	offers := make([]*DepositProductOffer, 0, len(domain.DepositProducts))
+	for i := range domain.DepositProducts {
+		product := domain.DepositProducts[i]
+		offers = append(offers, &DepositProductOffer{
+			Product:      product,
+			KeyRate:      keyRate,
+			InterestRate: product.Rate(keyRate),
+		})
+	}
+
+	return offers, nil
+}
+
+// OpenDeposit открывает вклад, списывая сумму со счета пользователя
+func (s *depositService) OpenDeposit(ctx context.Context, userID int, req domain.OpenDepositRequest) (*domain.Deposit, error) {
+	product, err := domain.FindDepositProduct(req.Product)
+	if err != nil {
+		return nil, err
+	}
+	if req.Capitalization == "" {
+		req.Capitalization = product.Capitalizations[0]
+	}
+	if err := req.Validate(product); err != nil {
+		s.logger.Warn("Invalid deposit request", "user_id", userID, "error", err)
+		return nil, err
+	}
+
+	if err := s.accessControl.CanAccessAccount(ctx, userID, req.AccountID); err != nil {
+		s.logger.Warn("Access denied for deposit opening", "user_id", userID, "account_id", req.AccountID)
+		if domain.IsAccessDeniedError(err) {
+			return nil, &ServiceError{Code: http.StatusForbidden, Message: err.Error()}
+		}
+		return nil, ErrAccountNotFound
+	}
+
+	account, err := s.accountRepo.GetByID(ctx, req.AccountID)
+	if err != nil {
+		return nil, ErrAccountNotFound
+	}
+	if account.Status != domain.AccountStatusActive {
+		return nil, ErrAccountBlocked
+	}
+
+	keyRate := s.keyRate(ctx)
+	openedOn := domain.BusinessDate(s.clock.Now(), s.location)
+
+	deposit := &domain.Deposit{
+		UserID:         userID,
+		AccountID:      req.AccountID,
+		Product:        product.Code,
+		Kind:           product.Kind,
+		Amount:         req.Amount,
+		Balance:        req.Amount,
+		InterestRate:   product.Rate(keyRate),
+		Capitalization: req.Capitalization,
+		TermMonths:     req.TermMonths,
+		OpenedOn:       openedOn,
+		Status:         domain.DepositStatusActive,
+	}
+	if product.Kind == domain.DepositKindTerm {
+		maturity := openedOn.AddDate(0, req.TermMonths, 0)
+		deposit.MaturityDate = &maturity
+	}
+
+	err = s.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		return s.depositRepo.Open(ctx, deposit, fmt.Sprintf("Открытие вклада «%s»", product.Name))
+	})
+	if err != nil {
+		if errors.Is(err, domain.ErrDepositInsufficientFunds) {
+			return nil, ErrInsufficientFunds
+		}
+		s.logger.Error("Failed to open deposit", "user_id", userID, "account_id", req.AccountID, "error", err)
+		return nil, fmt.Errorf("failed to open deposit: %w", err)
+	}
+
+	s.logger.Info("Deposit opened",
+		"deposit_id", deposit.ID,
+		"account_id", deposit.AccountID,
+		"product", deposit.Product,
+		"amount", deposit.Amount,
+		"key_rate", keyRate,
+		"rate", deposit.InterestRate)
+
+	event := NewUserAuditEvent(userID, domain.AuditActionDepositOpen, "deposit", auditResourceID(deposit.ID))
+	event.Before = domain.NewAuditState(map[string]interface{}{"balance": account.Balance})
+	event.After = domain.NewAuditState(deposit)
+	// Ошибка аудита уже залогирована, вклад открыт
+	_ = s.auditService.Record(ctx, event)
+
+	return deposit, nil
+}
+
+// GetUserDeposits возвращает вклады пользователя
+func (s *depositService) GetUserDeposits(ctx context.Context, userID int) ([]*domain.Deposit, error) {
+	deposits, err := s.depositRepo.GetByUserID(ctx, userID)
+	if err != nil {
+		s.logger.Error("Failed to get user deposits", "user_id", userID, "error", err)
+		return nil, fmt.Errorf("failed to get user deposits: %w", err)
+	}
+
+	return deposits, nil
+}
+
+// GetDeposit возвращает вклад пользователя
+func (s *depositService) GetDeposit(ctx context.Context, userID, depositID int) (*domain.Deposit, error) {
+	deposit, err := s.depositRepo.GetByID(ctx, depositID)
+	if err != nil {
+		return nil, err
+	}
+
+	if deposit.UserID != userID {
+		s.logger.Warn("Access denied for deposit", "user_id", userID, "deposit_id", depositID)
+		return nil, &ServiceError{
+			Code:    http.StatusForbidden,
+			Message: domain.NewAccessDeniedError("deposit", depositID, userID).Error(),
+		}
+	}
+
+	return deposit, nil
+}
+
+// GetProjection рассчитывает выплату по вкладу: для срочного вклада на дату окончания,
+// для накопительного вклада — через months месяцев (по умолчанию 12)
+func (s *depositService) GetProjection(ctx context.Context, userID, depositID, months int) (*domain.DepositProjection, error) {
+	if months < 0 || months > 120 {
+		return nil, ErrInvalidProjectionMonths
+	}
+
+	deposit, err := s.GetDeposit(ctx, userID, depositID)
+	if err != nil {
+		return nil, err
+	}
+	if !deposit.IsActive() {
+		return nil, domain.ErrDepositNotActive
+	}
+
+	today := domain.BusinessDate(s.clock.Now(), s.location)
+
+	// Проценты за прошедшие дни уже начислены закрытием дня; за день открытия не начисляются
+	from := today
+	if !from.After(deposit.OpenedOn) {
+		from = deposit.OpenedOn.AddDate(0, 0, 1)
+	}
+
+	to := today
+	switch {
+	case deposit.MaturityDate != nil:
+		if deposit.MaturityDate.After(to) {
+			to = *deposit.MaturityDate
+		}
+	default:
+		if months == 0 {
+			months = defaultSavingsProjectionMonths
+		}
+		to = today.AddDate(0, months, 0)
+	}
+
+	projection := deposit.Project(from, to)
+	projection.AsOf = today
+	projection.EarlyPayout, projection.EarlyRate = deposit.EarlyPayout(today)
+	projection.EarlyPayout = math.Round(projection.EarlyPayout*100) / 100
+
+	return projection, nil
+}
+
+// CloseDeposit закрывает вклад с выплатой на счет. Срочный вклад до окончания срока
+// закрывается с пересчетом процентов по пониженной ставке.
+func (s *depositService) CloseDeposit(ctx context.Context, userID, depositID int) (*domain.Deposit, error) {
+	deposit, err := s.GetDeposit(ctx, userID, depositID)
+	if err != nil {
+		return nil, err
+	}
+	if !deposit.IsActive() {
+		return nil, domain.ErrDepositNotActive
+	}
+
+	before := *deposit
+	today := domain.BusinessDate(s.clock.Now(), s.location)
+	payout, rate := deposit.EarlyPayout(today)
+	deposit.Settle(domain.DepositStatusClosed, payout, s.clock.Now())
+
+	err = s.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		return s.depositRepo.Settle(ctx, deposit, fmt.Sprintf("Закрытие вклада №%d", deposit.ID))
+	})
+	if err != nil {
+		if errors.Is(err, domain.ErrDepositNotActive) {
+			return nil, err
+		}
+		s.logger.Error("Failed to close deposit", "deposit_id", depositID, "error", err)
+		return nil, fmt.Errorf("failed to close deposit: %w", err)
+	}
+
+	s.logger.Info("Deposit closed",
+		"deposit_id", deposit.ID,
+		"account_id", deposit.AccountID,
+		"payout", *deposit.Payout,
+		"rate", rate,
+		"early", rate != before.InterestRate)
+
+	event := NewUserAuditEvent(userID, domain.AuditActionDepositClose, "deposit", auditResourceID(deposit.ID))
+	event.Before = domain.NewAuditState(before)
+	event.After = domain.NewAuditState(deposit)
+	event.Metadata = domain.NewAuditState(map[string]interface{}{"rate": rate})
+	// Ошибка аудита уже залогирована, вклад закрыт
+	_ = s.auditService.Record(ctx, event)
+
+	return deposit, nil
+}
+
+// keyRate возвращает ключевую ставку ЦБ РФ или резервную ставку при недоступности сервиса
+func (s *depositService) keyRate(ctx context.Context) float64 {
+	keyRate, err := s.cbrService.GetKeyRate(ctx)
+	if err != nil {
+		s.logger.Warn("Failed to get CBR key rate, using fallback", "error", err)
+		return fallbackKeyRate
+	}
+	return keyRate
+}
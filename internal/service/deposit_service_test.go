@@ -0,0 +1,402 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"math"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/vterdunov/learn-bank-app/internal/config"
+	"github.com/vterdunov/learn-bank-app/internal/domain"
+)
+
+// MockDepositRepository для тестирования (вклады и балансы счетов в памяти)
+type MockDepositRepository struct {
+	deposits map[int]*domain.Deposit
+	balances map[int]float64
+	nextID   int
+}
+
+func NewMockDepositRepository() *MockDepositRepository {
+	return &MockDepositRepository{
+		deposits: make(map[int]*domain.Deposit),
+		balances: make(map[int]float64),
+		nextID:   1,
+	}
+}
+
+func (m *MockDepositRepository) Open(ctx context.Context, deposit *domain.Deposit, description string) error {
+	if m.balances[deposit.AccountID] < deposit.Amount {
+		return domain.ErrDepositInsufficientFunds
+	}
+	m.balances[deposit.AccountID] -= deposit.Amount
+
+	deposit.ID = m.nextID
+	m.nextID++
+	copied := *deposit
+	m.deposits[deposit.ID] = &copied
+	return nil
+}
+
+func (m *MockDepositRepository) GetByID(ctx context.Context, id int) (*domain.Deposit, error) {
+	deposit, ok := m.deposits[id]
+	if !ok {
+		return nil, domain.ErrDepositNotFound
+	}
+	copied := *deposit
+	return &copied, nil
+}
+
+func (m *MockDepositRepository) GetByUserID(ctx context.Context, userID int) ([]*domain.Deposit, error) {
+	return m.filter(func(d *domain.Deposit) bool { return d.UserID == userID }), nil
+}
+
+func (m *MockDepositRepository) ListForAccrual(ctx context.Context, date time.Time) ([]*domain.Deposit, error) {
+	return m.filter(func(d *domain.Deposit) bool {
+		return d.IsActive() && d.OpenedOn.Before(date) && (d.MaturityDate == nil || !d.MaturityDate.Before(date))
+	}), nil
+}
+
+func (m *MockDepositRepository) ListForCapitalization(ctx context.Context) ([]*domain.Deposit, error) {
+	return m.filter(func(d *domain.Deposit) bool {
+		return d.IsActive() && d.Capitalization == domain.DepositCapitalizationMonthly && d.AccruedInterest >= 0.01
+	}), nil
+}
+
+func (m *MockDepositRepository) Capitalize(ctx context.Context, id int, amount float64) error {
+	deposit := m.deposits[id]
+	deposit.Balance += amount
+	deposit.AccruedInterest -= amount
+	deposit.InterestPaid += amount
+	return nil
+}
+
+func (m *MockDepositRepository) ListMatured(ctx context.Context, date time.Time) ([]*domain.Deposit, error) {
+	return m.filter(func(d *domain.Deposit) bool {
+		return d.IsActive() && d.MaturityDate != nil && !d.MaturityDate.After(date)
+	}), nil
+}
+
+func (m *MockDepositRepository) Settle(ctx context.Context, deposit *domain.Deposit, description string) error {
+	stored, ok := m.deposits[deposit.ID]
+	if !ok || !stored.IsActive() {
+		return domain.ErrDepositNotActive
+	}
+	copied := *deposit
+	m.deposits[deposit.ID] = &copied
+	m.balances[deposit.AccountID] += *deposit.Payout
+	return nil
+}
+
+// filter возвращает копии вкладов по условию (в порядке ID)
+func (m *MockDepositRepository) filter(match func(d *domain.Deposit) bool) []*domain.Deposit {
+	var deposits []*domain.Deposit
+	for id := 1; id < m.nextID; id++ {
+		if deposit, ok := m.deposits[id]; ok && match(deposit) {
+			copied := *deposit
+			deposits = append(deposits, &copied)
+		}
+	}
+	return deposits
+}
+
+// mockAccountRepository отдает счета из карты балансов MockDepositRepository
+type mockAccountRepository struct {
+	depositRepo *MockDepositRepository
+	userID      int
+}
+
+func (m *mockAccountRepository) Create(ctx context.Context, account *domain.Account) error {
+	return nil
+}
+
+func (m *mockAccountRepository) GetByID(ctx context.Context, id int) (*domain.Account, error) {
+	balance, ok := m.depositRepo.balances[id]
+	if !ok {
+		return nil, errors.New("account not found")
+	}
+	return &domain.Account{ID: id, UserID: m.userID, Balance: balance, Status: domain.AccountStatusActive}, nil
+}
+
+func (m *mockAccountRepository) GetByUserID(ctx context.Context, userID int) ([]*domain.Account, error) {
+	return nil, nil
+}
+
+func (m *mockAccountRepository) GetByNumber(ctx context.Context, number string) (*domain.Account, error) {
+	return nil, errors.New("account not found")
+}
+
+func (m *mockAccountRepository) Update(ctx context.Context, account *domain.Account) error {
+	return nil
+}
+
+func (m *mockAccountRepository) GetByIDForUpdate(ctx context.Context, id int) (*domain.Account, error) {
+	return m.GetByID(ctx, id)
+}
+
+func (m *mockAccountRepository) UpdateStatus(ctx context.Context, id int, status string) error {
+	return nil
+}
+
+func (m *mockAccountRepository) UpdateBalance(ctx context.Context, id int, balance float64) error {
+	m.depositRepo.balances[id] = balance
+	return nil
+}
+
+func (m *mockAccountRepository) Debit(ctx context.Context, id int, amount float64) error {
+	if m.depositRepo.balances[id] < amount {
+		return domain.ErrAccountInsufficientFunds
+	}
+	m.depositRepo.balances[id] -= amount
+	return nil
+}
+
+func (m *mockAccountRepository) Credit(ctx context.Context, id int, amount float64) error {
+	m.depositRepo.balances[id] += amount
+	return nil
+}
+
+func (m *mockAccountRepository) Delete(ctx context.Context, id int) error {
+	return nil
+}
+
+func (m *mockAccountRepository) Transfer(ctx context.Context, fromID, toID int, amount float64) error {
+	return nil
+}
+
+func (m *mockAccountRepository) GetBalance(ctx context.Context, id int) (float64, error) {
+	return m.depositRepo.balances[id], nil
+}
+
+func (m *mockAccountRepository) UpdateOverdraftLimit(ctx context.Context, id int, limit float64) error {
+	return nil
+}
+
+// mockAccessControl разрешает доступ только к счетам владельца
+type mockAccessControl struct {
+	accounts *mockAccountRepository
+}
+
+func (m *mockAccessControl) CanAccessAccount(ctx context.Context, userID, accountID int) error {
+	account, err := m.accounts.GetByID(ctx, accountID)
+	if err != nil {
+		return err
+	}
+	if account.UserID != userID {
+		return domain.NewAccessDeniedError("account", accountID, userID)
+	}
+	return nil
+}
+
+// CheckAccountPermission в моке проверяет только владение счетом
+func (m *mockAccessControl) CheckAccountPermission(ctx context.Context, userID, accountID int, permission string) error {
+	return m.CanAccessAccount(ctx, userID, accountID)
+}
+
+func (m *mockAccessControl) CanAccessCard(ctx context.Context, userID, cardID int) error {
+	return nil
+}
+
+func (m *mockAccessControl) CheckCardholder(ctx context.Context, userID, cardID int) error {
+	return nil
+}
+
+func (m *mockAccessControl) CanAccessCredit(ctx context.Context, userID, creditID int) error {
+	return nil
+}
+
+// mockCBRService возвращает фиксированную ключевую ставку
+type mockCBRService struct {
+	rate float64
+	err  error
+}
+
+func (m *mockCBRService) GetKeyRate(ctx context.Context) (float64, error) {
+	return m.rate, m.err
+}
+
+type depositTestDeps struct {
+	depositRepo *MockDepositRepository
+	clock       *fakeClock
+	endOfDay    *endOfDayService
+}
+
+func setupDepositService(t *testing.T, now time.Time) (*depositService, *depositTestDeps) {
+	t.Helper()
+
+	cfg := &config.Config{EOD: config.EODConfig{Timezone: "Europe/Moscow"}}
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	auditService, _ := setupAuditService()
+	depositRepo := NewMockDepositRepository()
+	depositRepo.balances[10] = 1000000
+	accountRepo := &mockAccountRepository{depositRepo: depositRepo, userID: 1}
+	clock := &fakeClock{now: now}
+
+	svc, err := NewDepositService(cfg, depositRepo, accountRepo, &mockAccessControl{accounts: accountRepo},
+		mockTxManager{}, &mockCBRService{rate: 21}, auditService, clock, logger)
+	if err != nil {
+		t.Fatalf("NewDepositService failed: %v", err)
+	}
+
+	eodRepo := NewMockEndOfDayRepository()
+	eodRepo.deposits = depositRepo
+	endOfDay, err := NewEndOfDayService(cfg, eodRepo, depositRepo, mockTxManager{}, clock, logger)
+	if err != nil {
+		t.Fatalf("NewEndOfDayService failed: %v", err)
+	}
+
+	return svc.(*depositService), &depositTestDeps{
+		depositRepo: depositRepo,
+		clock:       clock,
+		endOfDay:    endOfDay.(*endOfDayService),
+	}
+}
+
+func TestDepositService_OpenValidatesProduct(t *testing.T) {
+	svc, deps := setupDepositService(t, time.Date(2025, time.January, 15, 9, 0, 0, 0, time.UTC))
+	ctx := context.Background()
+
+	tests := []struct {
+		name string
+		req  domain.OpenDepositRequest
+		want error
+	}{
+		{"unknown product", domain.OpenDepositRequest{AccountID: 10, Product: "gold", Amount: 50000}, domain.ErrDepositProductNotFound},
+		{"below minimum", domain.OpenDepositRequest{AccountID: 10, Product: "term", Amount: 500, TermMonths: 6}, domain.ErrDepositAmountTooSmall},
+		{"term too long", domain.OpenDepositRequest{AccountID: 10, Product: "term", Amount: 50000, TermMonths: 60}, domain.ErrInvalidDepositTerm},
+		{"flexible with term", domain.OpenDepositRequest{AccountID: 10, Product: "flexible", Amount: 50000, TermMonths: 6}, domain.ErrInvalidDepositTerm},
+		{"flexible paid at end", domain.OpenDepositRequest{AccountID: 10, Product: "flexible", Amount: 50000, Capitalization: "end"}, domain.ErrInvalidDepositCapitalization},
+		{"insufficient funds", domain.OpenDepositRequest{AccountID: 10, Product: "term", Amount: 2000000, TermMonths: 6}, ErrInsufficientFunds},
+		{"missing account", domain.OpenDepositRequest{AccountID: 99, Product: "term", Amount: 50000, TermMonths: 6}, ErrAccountNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := svc.OpenDeposit(ctx, 1, tt.req); !errors.Is(err, tt.want) {
+				t.Errorf("expected %v, got %v", tt.want, err)
+			}
+		})
+	}
+
+	deposit, err := svc.OpenDeposit(ctx, 1, domain.OpenDepositRequest{AccountID: 10, Product: "term", Amount: 100000, TermMonths: 6})
+	if err != nil {
+		t.Fatalf("OpenDeposit failed: %v", err)
+	}
+	// Ключевая ставка 21% минус надбавка продукта 1%
+	if deposit.InterestRate != 20 || deposit.Capitalization != domain.DepositCapitalizationMonthly {
+		t.Errorf("unexpected deposit terms: rate %f capitalization %s", deposit.InterestRate, deposit.Capitalization)
+	}
+	if deposit.MaturityDate == nil || !deposit.MaturityDate.Equal(time.Date(2025, time.July, 15, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("unexpected maturity date: %v", deposit.MaturityDate)
+	}
+	if deps.depositRepo.balances[10] != 900000 {
+		t.Errorf("expected account to be debited, balance %f", deps.depositRepo.balances[10])
+	}
+
+	if _, err := svc.GetDeposit(ctx, 2, deposit.ID); err == nil {
+		t.Error("expected access denied for another user")
+	}
+}
+
+func TestDepositService_MaturityMatchesProjection(t *testing.T) {
+	svc, deps := setupDepositService(t, time.Date(2025, time.January, 15, 9, 0, 0, 0, time.UTC))
+	ctx := context.Background()
+
+	for _, capitalization := range []string{domain.DepositCapitalizationMonthly, domain.DepositCapitalizationEnd} {
+		_, err := svc.OpenDeposit(ctx, 1, domain.OpenDepositRequest{
+			AccountID: 10, Product: "term", Amount: 100000, TermMonths: 12, Capitalization: capitalization,
+		})
+		if err != nil {
+			t.Fatalf("OpenDeposit failed: %v", err)
+		}
+		if _, err := deps.endOfDay.RunEndOfDay(ctx); err != nil {
+			t.Fatalf("RunEndOfDay failed: %v", err)
+		}
+	}
+
+	monthly, _ := svc.GetProjection(ctx, 1, 1, 0)
+	atEnd, _ := svc.GetProjection(ctx, 1, 2, 0)
+	if monthly.ProjectedPayout <= atEnd.ProjectedPayout {
+		t.Errorf("monthly capitalization must pay more: %f vs %f", monthly.ProjectedPayout, atEnd.ProjectedPayout)
+	}
+	// 100000 * 20% за 365 дней без капитализации
+	if math.Abs(atEnd.ProjectedPayout-120000) > 0.01 {
+		t.Errorf("expected payout 120000 without capitalization, got %f", atEnd.ProjectedPayout)
+	}
+
+	// Ежедневное закрытие дней до окончания срока
+	for deps.clock.now.Before(time.Date(2026, time.January, 17, 0, 0, 0, 0, time.UTC)) {
+		deps.clock.now = deps.clock.now.Add(24 * time.Hour)
+		if _, err := deps.endOfDay.RunEndOfDay(ctx); err != nil {
+			t.Fatalf("RunEndOfDay failed at %s: %v", deps.clock.now, err)
+		}
+	}
+
+	for id, projection := range map[int]*domain.DepositProjection{1: monthly, 2: atEnd} {
+		deposit, _ := svc.GetDeposit(ctx, 1, id)
+		if deposit.Status != domain.DepositStatusMatured || deposit.Payout == nil {
+			t.Fatalf("expected deposit %d to mature, got %s", id, deposit.Status)
+		}
+		if math.Abs(*deposit.Payout-projection.ProjectedPayout) > 0.01 {
+			t.Errorf("deposit %d: payout %f differs from projection %f", id, *deposit.Payout, projection.ProjectedPayout)
+		}
+	}
+
+	expected := 1000000 - 200000 + *deps.depositRepo.deposits[1].Payout + *deps.depositRepo.deposits[2].Payout
+	if math.Abs(deps.depositRepo.balances[10]-expected) > 1e-6 {
+		t.Errorf("expected account balance %f, got %f", expected, deps.depositRepo.balances[10])
+	}
+}
+
+func TestDepositService_EarlyCloseReducesRate(t *testing.T) {
+	svc, deps := setupDepositService(t, time.Date(2025, time.January, 1, 9, 0, 0, 0, time.UTC))
+	ctx := context.Background()
+
+	open := func() *domain.Deposit {
+		deposit, err := svc.OpenDeposit(ctx, 1, domain.OpenDepositRequest{
+			AccountID: 10, Product: "term", Amount: 365000, TermMonths: 12, Capitalization: domain.DepositCapitalizationEnd,
+		})
+		if err != nil {
+			t.Fatalf("OpenDeposit failed: %v", err)
+		}
+		return deposit
+	}
+	first := open()
+
+	// Через 100 дней — первая половина срока, ставка до востребования
+	deps.clock.now = deps.clock.now.AddDate(0, 0, 100)
+	closed, err := svc.CloseDeposit(ctx, 1, first.ID)
+	if err != nil {
+		t.Fatalf("CloseDeposit failed: %v", err)
+	}
+	// 365000 * 0.01% / 365 * 99 дней
+	if *closed.Payout != 365009.9 || closed.Status != domain.DepositStatusClosed {
+		t.Errorf("unexpected early payout: %f (%s)", *closed.Payout, closed.Status)
+	}
+	if _, err := svc.CloseDeposit(ctx, 1, first.ID); !errors.Is(err, domain.ErrDepositNotActive) {
+		t.Errorf("expected ErrDepositNotActive, got %v", err)
+	}
+
+	second := open()
+	// Через 201 день — вторая половина срока, 2/3 ставки договора (20% -> 13.33%)
+	deps.clock.now = deps.clock.now.AddDate(0, 0, 201)
+	projection, err := svc.GetProjection(ctx, 1, second.ID, 0)
+	if err != nil {
+		t.Fatalf("GetProjection failed: %v", err)
+	}
+	if projection.EarlyRate != 13.33 {
+		t.Errorf("expected early rate 13.33, got %f", projection.EarlyRate)
+	}
+
+	closed, err = svc.CloseDeposit(ctx, 1, second.ID)
+	if err != nil {
+		t.Fatalf("CloseDeposit failed: %v", err)
+	}
+	// 365000 * 13.33% / 365 * 200 дней
+	if *closed.Payout != 391660 || *closed.Payout != projection.EarlyPayout {
+		t.Errorf("unexpected early payout: %f (projected %f)", *closed.Payout, projection.EarlyPayout)
+	}
+}
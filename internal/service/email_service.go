@@ -1,143 +1,125 @@
 package service
 
 import (
-	"bytes"
-	"crypto/tls"
+	"context"
 	"fmt"
-	"html/template"
 	"log/slog"
 
-	"github.com/go-mail/mail/v2"
-
 	"github.com/vterdunov/learn-bank-app/internal/config"
 	"github.com/vterdunov/learn-bank-app/internal/domain"
+	"github.com/vterdunov/learn-bank-app/internal/repository"
 )
 
-// EmailServiceImpl реализация EmailService
+// EmailServiceImpl реализация EmailService.
+// Письма не отправляются напрямую, а ставятся в outbox; доставку выполняет OutboxDispatcher.
 type EmailServiceImpl struct {
-	dialer    *mail.Dialer
-	from      string
-	logger    *slog.Logger
-	templates map[string]*template.Template
+	outboxRepo  repository.OutboxRepository
+	templates   *EmailTemplates
+	maxAttempts int
+	logger      *slog.Logger
 }
 
-// NewEmailService создает новый экземпляр EmailService
-func NewEmailService(cfg *config.Config, logger *slog.Logger) EmailService {
-	d := mail.NewDialer(cfg.SMTP.Host, cfg.SMTP.Port, cfg.SMTP.Username, cfg.SMTP.Password)
-	d.TLSConfig = &tls.Config{
-		ServerName:         cfg.SMTP.Host,
-		InsecureSkipVerify: false,
-		MinVersion:         tls.VersionTLS12,
-	}
-
-	// Загружаем шаблоны
-	templates := make(map[string]*template.Template)
-	templateFiles := map[string]string{
-		"payment": "templates/email/payment_notification.tmpl",
-		"credit":  "templates/email/credit_notification.tmpl",
-		"overdue": "templates/email/overdue_notification.tmpl",
-	}
-
-	for name, file := range templateFiles {
-		tmpl, err := template.ParseFiles(file)
-		if err != nil {
-			logger.Error("Failed to parse template", "name", name, "file", file, "error", err)
-			continue
-		}
-		templates[name] = tmpl
+// NewEmailService создает новый экземпляр EmailService.
+// Шаблоны загружаются и проверяются заранее через LoadEmailTemplates.
+func NewEmailService(cfg *config.Config, outboxRepo repository.OutboxRepository, templates *EmailTemplates, logger *slog.Logger) EmailService {
+	maxAttempts := cfg.Outbox.MaxAttempts
+	if maxAttempts < 1 {
+		maxAttempts = 1
 	}
 
 	return &EmailServiceImpl{
-		dialer:    d,
-		from:      cfg.SMTP.Username,
-		logger:    logger,
-		templates: templates,
+		outboxRepo:  outboxRepo,
+		templates:   templates,
+		maxAttempts: maxAttempts,
+		logger:      logger,
 	}
 }
 
-// SendPaymentNotification отправляет уведомление об успешном платеже
-func (s *EmailServiceImpl) SendPaymentNotification(userEmail string, amount float64) error {
-	subject := "Платеж успешно проведен"
-	data := struct {
-		Amount float64
-	}{
-		Amount: amount,
-	}
-
-	body, err := s.renderTemplate("payment", data)
-	if err != nil {
-		return fmt.Errorf("failed to render payment template: %w", err)
-	}
-
-	return s.sendEmail(userEmail, subject, body)
+// QueuePaymentNotification ставит в очередь уведомление об успешном платеже
+func (s *EmailServiceImpl) QueuePaymentNotification(ctx context.Context, user *domain.User, amount float64) error {
+	data := paymentEmailData{Amount: amount}
+	return s.queue(ctx, domain.OutboxEventPayment, user, EmailTemplatePayment, data)
 }
 
-// SendCreditNotification отправляет уведомление о выдаче кредита
-func (s *EmailServiceImpl) SendCreditNotification(userEmail string, credit *domain.Credit) error {
-	subject := "Кредит успешно оформлен"
-	body, err := s.renderTemplate("credit", credit)
-	if err != nil {
-		return fmt.Errorf("failed to render credit template: %w", err)
-	}
-
-	return s.sendEmail(userEmail, subject, body)
+// QueueCreditNotification ставит в очередь уведомление о выдаче кредита
+func (s *EmailServiceImpl) QueueCreditNotification(ctx context.Context, user *domain.User, credit *domain.Credit) error {
+	return s.queue(ctx, domain.OutboxEventCredit, user, EmailTemplateCredit, credit)
 }
 
-// SendOverdueNotification отправляет уведомление о просроченном платеже
-func (s *EmailServiceImpl) SendOverdueNotification(userEmail string, payment *domain.PaymentSchedule) error {
-	subject := "Просроченный платеж по кредиту"
-	data := struct {
-		PaymentAmount float64
-		DueDate       string
-		Status        string
-	}{
+// QueueOverdueNotification ставит в очередь уведомление о просроченном платеже
+func (s *EmailServiceImpl) QueueOverdueNotification(ctx context.Context, user *domain.User, payment *domain.PaymentSchedule) error {
+	data := overdueEmailData{
 		PaymentAmount: payment.PaymentAmount,
 		DueDate:       payment.DueDate.Format("02.01.2006"),
 		Status:        payment.Status,
 	}
+	return s.queue(ctx, domain.OutboxEventOverdue, user, EmailTemplateOverdue, data)
+}
 
-	body, err := s.renderTemplate("overdue", data)
-	if err != nil {
-		return fmt.Errorf("failed to render overdue template: %w", err)
+// QueueStandingOrderFailedNotification ставит в очередь уведомление о неисполненном платеже по поручению
+func (s *EmailServiceImpl) QueueStandingOrderFailedNotification(ctx context.Context, user *domain.User, order *domain.StandingOrder, execution *domain.StandingOrderExecution) error {
+	data := standingOrderEmailData{
+		OrderID:         order.ID,
+		Amount:          execution.Amount,
+		ToAccountNumber: order.ToMaskedAccountNumber,
+		ScheduledDate:   execution.ScheduledDate.Format("02.01.2006"),
+		Attempts:        execution.Attempt,
+		Reason:          execution.Error,
 	}
-
-	return s.sendEmail(userEmail, subject, body)
+	return s.queue(ctx, domain.OutboxEventStandingOrder, user, EmailTemplateStandingOrder, data)
 }
 
-// renderTemplate рендерит шаблон с данными
-func (s *EmailServiceImpl) renderTemplate(templateName string, data interface{}) (string, error) {
-	tmpl, ok := s.templates[templateName]
+// PreviewTemplate рендерит шаблон на тестовых данных
+func (s *EmailServiceImpl) PreviewTemplate(name, locale, version string) (*RenderedEmail, error) {
+	sample, ok := emailTemplateSamples()[name]
 	if !ok {
-		return "", fmt.Errorf("template %s not found", templateName)
+		return nil, fmt.Errorf("%w: %s", ErrEmailTemplateNotFound, name)
 	}
 
-	var buf bytes.Buffer
-	if err := tmpl.Execute(&buf, data); err != nil {
-		return "", fmt.Errorf("failed to execute template: %w", err)
+	return s.templates.Render(name, locale, version, sample)
+}
+
+// queue рендерит письмо на языке пользователя и ставит его в outbox
+func (s *EmailServiceImpl) queue(ctx context.Context, eventType string, user *domain.User, templateName string, data interface{}) error {
+	rendered, err := s.templates.Render(templateName, user.Locale, "", data)
+	if err != nil {
+		return fmt.Errorf("failed to render %s template: %w", templateName, err)
 	}
 
-	return buf.String(), nil
+	return s.enqueue(ctx, eventType, user.Email, rendered)
 }
 
-// sendEmail отправляет email
-func (s *EmailServiceImpl) sendEmail(to, subject, body string) error {
-	m := mail.NewMessage()
-	m.SetHeader("From", s.from)
-	m.SetHeader("To", to)
-	m.SetHeader("Subject", subject)
-	m.SetBody("text/html", body)
+// enqueue добавляет письмо в outbox (в транзакции из контекста, если она открыта)
+func (s *EmailServiceImpl) enqueue(ctx context.Context, eventType, to string, rendered *RenderedEmail) error {
+	message := &domain.OutboxMessage{
+		Channel:         domain.OutboxChannelEmail,
+		EventType:       eventType,
+		Recipient:       to,
+		Subject:         rendered.Subject,
+		Body:            rendered.HTML,
+		TextBody:        rendered.Text,
+		TemplateVersion: rendered.Version,
+		MaxAttempts:     s.maxAttempts,
+	}
+
+	if err := message.Validate(); err != nil {
+		return err
+	}
 
-	if err := s.dialer.DialAndSend(m); err != nil {
-		s.logger.Error("Failed to send email",
+	if err := s.outboxRepo.Enqueue(ctx, message); err != nil {
+		s.logger.Error("Failed to enqueue email",
 			"to", to,
-			"subject", subject,
+			"event_type", eventType,
 			"error", err)
-		return fmt.Errorf("email sending failed: %w", err)
+		return fmt.Errorf("failed to enqueue email: %w", err)
 	}
 
-	s.logger.Info("Email sent successfully",
+	s.logger.Info("Email queued",
+		"outbox_id", message.ID,
 		"to", to,
-		"subject", subject)
+		"event_type", eventType,
+		"template_version", rendered.Version,
+		"locale", rendered.Locale)
 
 	return nil
 }
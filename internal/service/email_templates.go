@@ -0,0 +1,269 @@
+package service
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	htmltemplate "html/template"
+	"io/fs"
+	"path"
+	"sort"
+	"strings"
+	texttemplate "text/template"
+	"time"
+
+	"github.com/vterdunov/learn-bank-app/internal/domain"
+)
+
+// Имена шаблонов писем
+const (
+	EmailTemplatePayment = "payment"
+	EmailTemplateCredit  = "credit"
+	EmailTemplateOverdue = "overdue"
+	// EmailTemplateStandingOrder уведомление о неисполненном платеже по поручению
+	EmailTemplateStandingOrder = "standing_order"
+)
+
+// emailTemplateParts части шаблона письма: тема, текстовая и HTML версии
+var emailTemplateParts = []string{"subject", "txt", "html"}
+
+var (
+	ErrEmailTemplateNotFound = errors.New("email template not found")
+	ErrEmailTemplateVersion  = errors.New("email template version not found")
+)
+
+// paymentEmailData данные шаблона уведомления о платеже
+type paymentEmailData struct {
+	Amount float64
+}
+
+// overdueEmailData данные шаблона уведомления о просрочке
+type overdueEmailData struct {
+	PaymentAmount float64
+	DueDate       string
+	Status        string
+}
+
+// standingOrderEmailData данные шаблона уведомления о неисполненном поручении
+type standingOrderEmailData struct {
+	OrderID         int
+	Amount          float64
+	ToAccountNumber string // Маскированный номер счета получателя
+	ScheduledDate   string
+	Attempts        int
+	Reason          string
+}
+
+// emailTemplateSamples тестовые данные для проверки шаблонов при старте, предпросмотра и golden тестов
+func emailTemplateSamples() map[string]interface{} {
+	return map[string]interface{}{
+		EmailTemplatePayment: paymentEmailData{Amount: 1500.5},
+		EmailTemplateCredit: &domain.Credit{
+			ID:             1,
+			Amount:         500000,
+			InterestRate:   21,
+			TermMonths:     24,
+			MonthlyPayment: 25700.37,
+		},
+		EmailTemplateOverdue: overdueEmailData{
+			PaymentAmount: 25700.37,
+			DueDate:       time.Date(2025, time.March, 15, 0, 0, 0, 0, time.UTC).Format("02.01.2006"),
+			Status:        domain.PaymentStatusOverdue,
+		},
+		EmailTemplateStandingOrder: standingOrderEmailData{
+			OrderID:         7,
+			Amount:          45000,
+			ToAccountNumber: "40817***********1234",
+			ScheduledDate:   time.Date(2025, time.March, 31, 0, 0, 0, 0, time.UTC).Format("02.01.2006"),
+			Attempts:        3,
+			Reason:          "insufficient funds",
+		},
+	}
+}
+
+// emailTemplate шаблон письма одной версии и одного языка
+type emailTemplate struct {
+	subject *texttemplate.Template
+	text    *texttemplate.Template
+	html    *htmltemplate.Template
+}
+
+// EmailTemplates набор версионированных локализованных шаблонов писем
+type EmailTemplates struct {
+	current string
+	// versions версия -> язык -> имя шаблона
+	versions map[string]map[string]map[string]*emailTemplate
+}
+
+// LoadEmailTemplates загружает шаблоны email/<версия>/<язык>/<имя>.<часть>.tmpl
+// и проверяет, что текущая версия полна для языка по умолчанию и все шаблоны рендерятся.
+func LoadEmailTemplates(fsys fs.FS, currentVersion string) (*EmailTemplates, error) {
+	files, err := fs.Glob(fsys, "email/*/*/*.tmpl")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list email templates: %w", err)
+	}
+
+	t := &EmailTemplates{
+		current:  currentVersion,
+		versions: make(map[string]map[string]map[string]*emailTemplate),
+	}
+
+	seen := make(map[string]map[string]bool)
+	for _, file := range files {
+		parts := strings.Split(file, "/")
+		version, locale := parts[1], parts[2]
+		name, part, ok := strings.Cut(strings.TrimSuffix(path.Base(file), ".tmpl"), ".")
+		if !ok {
+			return nil, fmt.Errorf("invalid email template file name: %s", file)
+		}
+		if !domain.IsSupportedLocale(locale) {
+			return nil, fmt.Errorf("unsupported locale in %s", file)
+		}
+
+		content, err := fs.ReadFile(fsys, file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", file, err)
+		}
+
+		tmpl := t.entry(version, locale, name)
+		switch part {
+		case "subject":
+			tmpl.subject, err = texttemplate.New(file).Option("missingkey=error").Parse(strings.TrimSpace(string(content)))
+		case "txt":
+			tmpl.text, err = texttemplate.New(file).Option("missingkey=error").Parse(string(content))
+		case "html":
+			tmpl.html, err = htmltemplate.New(file).Option("missingkey=error").Parse(string(content))
+		default:
+			return nil, fmt.Errorf("unknown email template part in %s", file)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", file, err)
+		}
+
+		key := version + "/" + locale + "/" + name
+		if seen[key] == nil {
+			seen[key] = make(map[string]bool)
+		}
+		seen[key][part] = true
+	}
+
+	if err := t.validate(seen); err != nil {
+		return nil, err
+	}
+
+	return t, nil
+}
+
+func (t *EmailTemplates) entry(version, locale, name string) *emailTemplate {
+	if t.versions[version] == nil {
+		t.versions[version] = make(map[string]map[string]*emailTemplate)
+	}
+	if t.versions[version][locale] == nil {
+		t.versions[version][locale] = make(map[string]*emailTemplate)
+	}
+	if t.versions[version][locale][name] == nil {
+		t.versions[version][locale][name] = &emailTemplate{}
+	}
+	return t.versions[version][locale][name]
+}
+
+// validate проверяет полноту шаблонов и рендерит каждый на тестовых данных
+func (t *EmailTemplates) validate(seen map[string]map[string]bool) error {
+	for key, parts := range seen {
+		for _, part := range emailTemplateParts {
+			if !parts[part] {
+				return fmt.Errorf("email template %s is missing %s part", key, part)
+			}
+		}
+	}
+
+	current, ok := t.versions[t.current]
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrEmailTemplateVersion, t.current)
+	}
+
+	samples := emailTemplateSamples()
+	for name := range samples {
+		if _, ok := current[domain.DefaultLocale][name]; !ok {
+			return fmt.Errorf("email template %s/%s/%s is required", t.current, domain.DefaultLocale, name)
+		}
+	}
+
+	for version, locales := range t.versions {
+		for locale, templates := range locales {
+			for name := range templates {
+				sample, ok := samples[name]
+				if !ok {
+					return fmt.Errorf("email template %s/%s/%s has no sample data", version, locale, name)
+				}
+				if _, err := t.Render(name, locale, version, sample); err != nil {
+					return fmt.Errorf("email template %s/%s/%s: %w", version, locale, name, err)
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+// CurrentVersion возвращает версию шаблонов, используемую для новых писем
+func (t *EmailTemplates) CurrentVersion() string {
+	return t.current
+}
+
+// Keys возвращает все шаблоны в виде "версия/язык/имя" (отсортировано)
+func (t *EmailTemplates) Keys() []string {
+	var keys []string
+	for version, locales := range t.versions {
+		for locale, templates := range locales {
+			for name := range templates {
+				keys = append(keys, version+"/"+locale+"/"+name)
+			}
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// Render рендерит письмо. Пустая версия означает текущую;
+// при отсутствии шаблона на языке пользователя используется язык по умолчанию.
+func (t *EmailTemplates) Render(name, locale, version string, data interface{}) (*RenderedEmail, error) {
+	if version == "" {
+		version = t.current
+	}
+
+	locales, ok := t.versions[version]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrEmailTemplateVersion, version)
+	}
+
+	locale = domain.NormalizeLocale(locale)
+	tmpl, ok := locales[locale][name]
+	if !ok {
+		locale = domain.DefaultLocale
+		tmpl, ok = locales[locale][name]
+	}
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrEmailTemplateNotFound, name)
+	}
+
+	var subject, text, html bytes.Buffer
+	if err := tmpl.subject.Execute(&subject, data); err != nil {
+		return nil, fmt.Errorf("failed to render subject: %w", err)
+	}
+	if err := tmpl.text.Execute(&text, data); err != nil {
+		return nil, fmt.Errorf("failed to render text body: %w", err)
+	}
+	if err := tmpl.html.Execute(&html, data); err != nil {
+		return nil, fmt.Errorf("failed to render html body: %w", err)
+	}
+
+	return &RenderedEmail{
+		Name:    name,
+		Version: version,
+		Locale:  locale,
+		Subject: subject.String(),
+		Text:    text.String(),
+		HTML:    html.String(),
+	}, nil
+}
@@ -0,0 +1,170 @@
+package service
+
+import (
+	"errors"
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/vterdunov/learn-bank-app/internal/domain"
+	"github.com/vterdunov/learn-bank-app/templates"
+)
+
+// updateGolden перезаписывает эталонные файлы: go test ./internal/service -run TestEmailTemplates_Golden -update
+var updateGolden = flag.Bool("update", false, "update golden files")
+
+func loadTestEmailTemplates(t *testing.T) *EmailTemplates {
+	t.Helper()
+
+	emailTemplates, err := LoadEmailTemplates(templates.Email, "v1")
+	if err != nil {
+		t.Fatalf("failed to load email templates: %v", err)
+	}
+	return emailTemplates
+}
+
+func TestEmailTemplates_Golden(t *testing.T) {
+	emailTemplates := loadTestEmailTemplates(t)
+	samples := emailTemplateSamples()
+
+	keys := emailTemplates.Keys()
+	if len(keys) == 0 {
+		t.Fatal("expected embedded email templates")
+	}
+
+	for _, key := range keys {
+		t.Run(key, func(t *testing.T) {
+			parts := strings.Split(key, "/")
+			version, locale, name := parts[0], parts[1], parts[2]
+
+			rendered, err := emailTemplates.Render(name, locale, version, samples[name])
+			if err != nil {
+				t.Fatalf("render failed: %v", err)
+			}
+			if rendered.Locale != locale {
+				t.Fatalf("expected locale %s, got %s", locale, rendered.Locale)
+			}
+
+			outputs := map[string]string{
+				"subject": rendered.Subject,
+				"txt":     rendered.Text,
+				"html":    rendered.HTML,
+			}
+			for part, got := range outputs {
+				golden := filepath.Join("testdata", "email", version, locale, name+"."+part+".golden")
+				if *updateGolden {
+					if err := os.MkdirAll(filepath.Dir(golden), 0o755); err != nil {
+						t.Fatalf("failed to create golden dir: %v", err)
+					}
+					if err := os.WriteFile(golden, []byte(got), 0o644); err != nil {
+						t.Fatalf("failed to write golden file: %v", err)
+					}
+					continue
+				}
+
+				want, err := os.ReadFile(golden)
+				if err != nil {
+					t.Fatalf("failed to read golden file (run with -update): %v", err)
+				}
+				if got != string(want) {
+					t.Errorf("%s mismatch\n--- got ---\n%s\n--- want ---\n%s", golden, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestEmailTemplates_LocaleFallback(t *testing.T) {
+	fsys := fstest.MapFS{
+		"email/v1/ru/payment.subject.tmpl":        {Data: []byte("Платеж")},
+		"email/v1/ru/payment.txt.tmpl":            {Data: []byte("Сумма {{.Amount}}")},
+		"email/v1/ru/payment.html.tmpl":           {Data: []byte("<p>{{.Amount}}</p>")},
+		"email/v1/ru/credit.subject.tmpl":         {Data: []byte("Кредит")},
+		"email/v1/ru/credit.txt.tmpl":             {Data: []byte("{{.Amount}}")},
+		"email/v1/ru/credit.html.tmpl":            {Data: []byte("{{.Amount}}")},
+		"email/v1/ru/overdue.subject.tmpl":        {Data: []byte("Просрочка")},
+		"email/v1/ru/overdue.txt.tmpl":            {Data: []byte("{{.DueDate}}")},
+		"email/v1/ru/overdue.html.tmpl":           {Data: []byte("{{.DueDate}}")},
+		"email/v1/ru/standing_order.subject.tmpl": {Data: []byte("Поручение")},
+		"email/v1/ru/standing_order.txt.tmpl":     {Data: []byte("{{.Reason}}")},
+		"email/v1/ru/standing_order.html.tmpl":    {Data: []byte("{{.Reason}}")},
+	}
+
+	emailTemplates, err := LoadEmailTemplates(fsys, "v1")
+	if err != nil {
+		t.Fatalf("failed to load templates: %v", err)
+	}
+
+	rendered, err := emailTemplates.Render(EmailTemplatePayment, domain.LocaleEN, "", paymentEmailData{Amount: 10})
+	if err != nil {
+		t.Fatalf("render failed: %v", err)
+	}
+	if rendered.Locale != domain.LocaleRU || rendered.Subject != "Платеж" {
+		t.Fatalf("expected fallback to ru, got %s %q", rendered.Locale, rendered.Subject)
+	}
+
+	if _, err := emailTemplates.Render(EmailTemplatePayment, domain.LocaleRU, "v2", paymentEmailData{}); !errors.Is(err, ErrEmailTemplateVersion) {
+		t.Fatalf("expected ErrEmailTemplateVersion, got %v", err)
+	}
+}
+
+func TestLoadEmailTemplates_Invalid(t *testing.T) {
+	valid := func() fstest.MapFS {
+		fsys := fstest.MapFS{}
+		for _, name := range []string{EmailTemplatePayment, EmailTemplateCredit, EmailTemplateOverdue, EmailTemplateStandingOrder} {
+			for _, part := range emailTemplateParts {
+				fsys["email/v1/ru/"+name+"."+part+".tmpl"] = &fstest.MapFile{Data: []byte(name)}
+			}
+		}
+		return fsys
+	}
+
+	if _, err := LoadEmailTemplates(valid(), "v1"); err != nil {
+		t.Fatalf("expected valid templates, got %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		modify  func(fsys fstest.MapFS)
+		version string
+	}{
+		{
+			name:    "missing part",
+			modify:  func(fsys fstest.MapFS) { delete(fsys, "email/v1/ru/payment.txt.tmpl") },
+			version: "v1",
+		},
+		{
+			name: "missing default locale template",
+			modify: func(fsys fstest.MapFS) {
+				for _, part := range emailTemplateParts {
+					delete(fsys, "email/v1/ru/overdue."+part+".tmpl")
+				}
+			},
+			version: "v1",
+		},
+		{
+			name:    "unknown field",
+			modify:  func(fsys fstest.MapFS) { fsys["email/v1/ru/payment.html.tmpl"].Data = []byte("{{.Missing}}") },
+			version: "v1",
+		},
+		{
+			name:    "unknown version",
+			modify:  func(fsys fstest.MapFS) {},
+			version: "v9",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fsys := valid()
+			tt.modify(fsys)
+
+			if _, err := LoadEmailTemplates(fsys, tt.version); err == nil {
+				t.Fatal("expected error")
+			}
+		})
+	}
+}
@@ -0,0 +1,411 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/vterdunov/learn-bank-app/internal/config"
+	"github.com/vterdunov/learn-bank-app/internal/domain"
+	"github.com/vterdunov/learn-bank-app/internal/repository"
+	"github.com/vterdunov/learn-bank-app/internal/utils"
+	"github.com/vterdunov/learn-bank-app/pkg/logger"
+)
+
+var (
+	ErrBusinessDayClosed  = errors.New("business day already closed")
+	ErrBusinessDayNotOver = errors.New("business day is not over yet")
+)
+
+// endOfDayService закрывает операционные дни: начисляет дневные проценты по кредитам,
+// вкладам и на остатки счетов, начисляет и списывает проценты за овердрафт,
+// в последний день месяца капитализирует проценты, выплачивает вклады с истекшим сроком.
+// Каждый день закрывается в одной транзакции и не более одного раза.
+type endOfDayService struct {
+	eodRepo     repository.EndOfDayRepository
+	depositRepo repository.DepositRepository
+	txManager   repository.TxManager
+	clock       utils.Clock
+	location    *time.Location
+	savingsRate float64
+	// overdraftRates годовые ставки овердрафта по продуктам счетов
+	overdraftRates map[string]float64
+	logger         *slog.Logger
+}
+
+// NewEndOfDayService создает новый экземпляр EndOfDayService
+func NewEndOfDayService(
+	cfg *config.Config,
+	eodRepo repository.EndOfDayRepository,
+	depositRepo repository.DepositRepository,
+	txManager repository.TxManager,
+	clock utils.Clock,
+	lg *slog.Logger,
+) (EndOfDayService, error) {
+	location, err := time.LoadLocation(cfg.EOD.Timezone)
+	if err != nil {
+		return nil, fmt.Errorf("invalid EOD timezone %q: %w", cfg.EOD.Timezone, err)
+	}
+
+	overdraftRates := make(map[string]float64, len(cfg.Overdraft.Products))
+	for product, terms := range cfg.Overdraft.Products {
+		overdraftRates[product] = terms.AnnualRate
+	}
+
+	return &endOfDayService{
+		eodRepo:        eodRepo,
+		depositRepo:    depositRepo,
+		txManager:      txManager,
+		clock:          clock,
+		location:       location,
+		savingsRate:    cfg.EOD.SavingsRate,
+		overdraftRates: overdraftRates,
+		logger:         logger.WithService(lg, "end_of_day_service"),
+	}, nil
+}
+
+// RunEndOfDay закрывает все прошедшие незакрытые дни по порядку.
+// При первом запуске закрывается только вчерашний день. Исторических остатков нет,
+// поэтому пропущенные дни начисляются от остатков на момент закрытия.
+func (s *endOfDayService) RunEndOfDay(ctx context.Context) (*JobResult, error) {
+	today := domain.BusinessDate(s.clock.Now(), s.location)
+
+	lastClosed, err := s.eodRepo.LastClosedDate(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get last closed business day: %w", err)
+	}
+
+	date := today.AddDate(0, 0, -1)
+	if lastClosed != nil {
+		date = lastClosed.AddDate(0, 0, 1)
+	}
+
+	if missed := today.AddDate(0, 0, -1); date.Before(missed) {
+		s.logger.Warn("Closing missed business days with current balances",
+			"from", date.Format("2006-01-02"),
+			"to", missed.Format("2006-01-02"))
+	}
+
+	result := &JobResult{}
+	for ; date.Before(today); date = date.AddDate(0, 0, 1) {
+		if err := ctx.Err(); err != nil {
+			return result, err
+		}
+
+		if _, err := s.CloseBusinessDay(ctx, date); err != nil {
+			if errors.Is(err, ErrBusinessDayClosed) {
+				continue
+			}
+			result.Failed++
+			return result, err
+		}
+		result.Processed++
+	}
+
+	return result, nil
+}
+
+// CloseBusinessDay закрывает операционный день. Повторное закрытие возвращает ErrBusinessDayClosed.
+func (s *endOfDayService) CloseBusinessDay(ctx context.Context, date time.Time) (*domain.BusinessDay, error) {
+	date = time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, time.UTC)
+	if !date.Before(domain.BusinessDate(s.clock.Now(), s.location)) {
+		return nil, ErrBusinessDayNotOver
+	}
+
+	day := &domain.BusinessDay{Date: date}
+	err := s.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		created, err := s.eodRepo.CreateDay(ctx, day)
+		if err != nil {
+			return fmt.Errorf("failed to open business day: %w", err)
+		}
+		if !created {
+			return ErrBusinessDayClosed
+		}
+
+		if err := s.accrueCredits(ctx, day); err != nil {
+			return err
+		}
+
+		if err := s.accrueDeposits(ctx, day); err != nil {
+			return err
+		}
+
+		if s.savingsRate > 0 {
+			if err := s.accrueSavings(ctx, day); err != nil {
+				return err
+			}
+		}
+
+		if err := s.accrueOverdrafts(ctx, day); err != nil {
+			return err
+		}
+		if err := s.chargeOverdrafts(ctx, day); err != nil {
+			return err
+		}
+
+		if domain.IsMonthEnd(date) {
+			if err := s.postSavings(ctx, day); err != nil {
+				return err
+			}
+			if err := s.capitalizeDeposits(ctx); err != nil {
+				return err
+			}
+		}
+
+		if err := s.settleMaturedDeposits(ctx, day); err != nil {
+			return err
+		}
+
+		return s.eodRepo.UpdateDayTotals(ctx, day)
+	})
+	if err != nil {
+		if !errors.Is(err, ErrBusinessDayClosed) {
+			s.logger.Error("Failed to close business day", "business_date", date.Format("2006-01-02"), "error", err)
+		}
+		return nil, err
+	}
+
+	s.logger.Info("Business day closed",
+		"business_date", date.Format("2006-01-02"),
+		"credits_accrued", day.CreditsAccrued,
+		"credit_interest", day.CreditInterest,
+		"accounts_accrued", day.AccountsAccrued,
+		"savings_interest", day.SavingsInterest,
+		"savings_posted", day.SavingsPosted,
+		"deposits_accrued", day.DepositsAccrued,
+		"deposit_interest", day.DepositInterest,
+		"deposits_matured", day.DepositsMatured,
+		"overdrafts_accrued", day.OverdraftsAccrued,
+		"overdraft_interest", day.OverdraftInterest,
+		"overdraft_charged", day.OverdraftCharged)
+
+	return day, nil
+}
+
+// accrueCredits начисляет дневные проценты на остаток долга по кредитам
+func (s *endOfDayService) accrueCredits(ctx context.Context, day *domain.BusinessDay) error {
+	credits, err := s.eodRepo.ListCreditsForAccrual(ctx, day.Date)
+	if err != nil {
+		return fmt.Errorf("failed to get credits for accrual: %w", err)
+	}
+
+	for _, credit := range credits {
+		amount := domain.DailyInterest(credit.RemainingDebt, credit.InterestRate, day.Date)
+		if amount <= 0 {
+			continue
+		}
+
+		creditID := credit.ID
+		added, err := s.eodRepo.AddAccrual(ctx, &domain.InterestAccrual{
+			BusinessDate: day.Date,
+			Kind:         domain.InterestAccrualCredit,
+			CreditID:     &creditID,
+			AccountID:    credit.AccountID,
+			BaseAmount:   credit.RemainingDebt,
+			AnnualRate:   credit.InterestRate,
+			Amount:       amount,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to accrue interest for credit %d: %w", credit.ID, err)
+		}
+		if added {
+			day.CreditsAccrued++
+			day.CreditInterest += amount
+		}
+	}
+
+	return nil
+}
+
+// accrueSavings начисляет дневные проценты на остатки накопительных счетов
+func (s *endOfDayService) accrueSavings(ctx context.Context, day *domain.BusinessDay) error {
+	accounts, err := s.eodRepo.ListAccountsForAccrual(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get accounts for accrual: %w", err)
+	}
+
+	for _, account := range accounts {
+		amount := domain.DailyInterest(account.Balance, s.savingsRate, day.Date)
+		if amount <= 0 {
+			continue
+		}
+
+		added, err := s.eodRepo.AddAccrual(ctx, &domain.InterestAccrual{
+			BusinessDate: day.Date,
+			Kind:         domain.InterestAccrualSavings,
+			AccountID:    account.ID,
+			BaseAmount:   account.Balance,
+			AnnualRate:   s.savingsRate,
+			Amount:       amount,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to accrue interest for account %d: %w", account.ID, err)
+		}
+		if added {
+			day.AccountsAccrued++
+			day.SavingsInterest += amount
+		}
+	}
+
+	return nil
+}
+
+// accrueOverdrafts начисляет дневные проценты на использованный овердрафт по ставке продукта счета
+func (s *endOfDayService) accrueOverdrafts(ctx context.Context, day *domain.BusinessDay) error {
+	accounts, err := s.eodRepo.ListOverdraftAccounts(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get overdraft accounts: %w", err)
+	}
+
+	for _, account := range accounts {
+		rate := s.overdraftRates[account.Product]
+		amount := domain.DailyInterest(account.OverdraftUsed(), rate, day.Date)
+		if amount <= 0 {
+			continue
+		}
+
+		added, err := s.eodRepo.AddAccrual(ctx, &domain.InterestAccrual{
+			BusinessDate: day.Date,
+			Kind:         domain.InterestAccrualOverdraft,
+			AccountID:    account.ID,
+			BaseAmount:   account.OverdraftUsed(),
+			AnnualRate:   rate,
+			Amount:       amount,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to accrue overdraft interest for account %d: %w", account.ID, err)
+		}
+		if added {
+			day.OverdraftsAccrued++
+			day.OverdraftInterest += amount
+		}
+	}
+
+	return nil
+}
+
+// chargeOverdrafts списывает со счетов начисленные проценты за овердрафт целыми копейками;
+// доли копейки и проценты сверх лимита овердрафта переходят на следующий день
+func (s *endOfDayService) chargeOverdrafts(ctx context.Context, day *domain.BusinessDay) error {
+	accrued, err := s.eodRepo.ListAccruedOverdraftInterest(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get accrued overdraft interest: %w", err)
+	}
+
+	description := fmt.Sprintf("Проценты за овердрафт за %s", day.Date.Format("02.01.2006"))
+	for _, item := range accrued {
+		amount := domain.FloorKopecks(item.Amount)
+		if amount <= 0 {
+			continue
+		}
+
+		charged, err := s.eodRepo.ChargeOverdraftInterest(ctx, item.AccountID, amount, description)
+		if err != nil {
+			return fmt.Errorf("failed to charge overdraft interest to account %d: %w", item.AccountID, err)
+		}
+		day.OverdraftCharged += charged
+	}
+
+	return nil
+}
+
+// postSavings зачисляет накопленные за месяц проценты на счета (целыми копейками)
+func (s *endOfDayService) postSavings(ctx context.Context, day *domain.BusinessDay) error {
+	savings, err := s.eodRepo.ListAccruedSavings(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get accrued savings: %w", err)
+	}
+
+	description := fmt.Sprintf("Проценты на остаток за %s", day.Date.Format("01.2006"))
+	for _, item := range savings {
+		amount := domain.FloorKopecks(item.Amount)
+		if amount <= 0 {
+			continue
+		}
+
+		if err := s.eodRepo.PostSavingsInterest(ctx, item.AccountID, amount, description); err != nil {
+			return fmt.Errorf("failed to post interest to account %d: %w", item.AccountID, err)
+		}
+		day.SavingsPosted += amount
+	}
+
+	return nil
+}
+
+// accrueDeposits начисляет дневные проценты на сумму вкладов
+func (s *endOfDayService) accrueDeposits(ctx context.Context, day *domain.BusinessDay) error {
+	deposits, err := s.depositRepo.ListForAccrual(ctx, day.Date)
+	if err != nil {
+		return fmt.Errorf("failed to get deposits for accrual: %w", err)
+	}
+
+	for _, deposit := range deposits {
+		amount := domain.DailyInterest(deposit.Balance, deposit.InterestRate, day.Date)
+		if amount <= 0 {
+			continue
+		}
+
+		depositID := deposit.ID
+		added, err := s.eodRepo.AddAccrual(ctx, &domain.InterestAccrual{
+			BusinessDate: day.Date,
+			Kind:         domain.InterestAccrualDeposit,
+			DepositID:    &depositID,
+			AccountID:    deposit.AccountID,
+			BaseAmount:   deposit.Balance,
+			AnnualRate:   deposit.InterestRate,
+			Amount:       amount,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to accrue interest for deposit %d: %w", deposit.ID, err)
+		}
+		if added {
+			day.DepositsAccrued++
+			day.DepositInterest += amount
+		}
+	}
+
+	return nil
+}
+
+// capitalizeDeposits прибавляет накопленные за месяц проценты к вкладам с ежемесячной капитализацией
+func (s *endOfDayService) capitalizeDeposits(ctx context.Context) error {
+	deposits, err := s.depositRepo.ListForCapitalization(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get deposits for capitalization: %w", err)
+	}
+
+	for _, deposit := range deposits {
+		amount := domain.FloorKopecks(deposit.AccruedInterest)
+		if amount <= 0 {
+			continue
+		}
+
+		if err := s.depositRepo.Capitalize(ctx, deposit.ID, amount); err != nil {
+			return fmt.Errorf("failed to capitalize deposit %d: %w", deposit.ID, err)
+		}
+	}
+
+	return nil
+}
+
+// settleMaturedDeposits выплачивает на счет вклады, срок которых истек
+func (s *endOfDayService) settleMaturedDeposits(ctx context.Context, day *domain.BusinessDay) error {
+	deposits, err := s.depositRepo.ListMatured(ctx, day.Date)
+	if err != nil {
+		return fmt.Errorf("failed to get matured deposits: %w", err)
+	}
+
+	for _, deposit := range deposits {
+		deposit.Settle(domain.DepositStatusMatured, deposit.Balance+domain.FloorKopecks(deposit.AccruedInterest), s.clock.Now())
+
+		description := fmt.Sprintf("Выплата вклада №%d по окончании срока", deposit.ID)
+		if err := s.depositRepo.Settle(ctx, deposit, description); err != nil {
+			return fmt.Errorf("failed to settle deposit %d: %w", deposit.ID, err)
+		}
+		day.DepositsMatured++
+	}
+
+	return nil
+}
@@ -0,0 +1,378 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/vterdunov/learn-bank-app/internal/config"
+	"github.com/vterdunov/learn-bank-app/internal/domain"
+)
+
+// fakeClock управляемые часы для моделирования течения дней
+type fakeClock struct {
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	return c.now
+}
+
+// mockTxManager выполняет функцию без транзакции
+type mockTxManager struct{}
+
+func (mockTxManager) WithinTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	return fn(ctx)
+}
+
+// MockEndOfDayRepository для тестирования (кредиты, счета и начисления в памяти)
+type MockEndOfDayRepository struct {
+	days         map[string]*domain.BusinessDay
+	credits      []*domain.Credit
+	accounts     []*domain.Account
+	accruals     map[string]*domain.InterestAccrual
+	creditAccrue map[int]float64
+	savings      map[int]float64
+	posted       []float64
+	overdraft    map[int]float64
+	charged      []float64
+	// deposits хранилище вкладов, в которое записываются начисления по ним
+	deposits *MockDepositRepository
+}
+
+func NewMockEndOfDayRepository() *MockEndOfDayRepository {
+	return &MockEndOfDayRepository{
+		days:         make(map[string]*domain.BusinessDay),
+		accruals:     make(map[string]*domain.InterestAccrual),
+		creditAccrue: make(map[int]float64),
+		savings:      make(map[int]float64),
+		overdraft:    make(map[int]float64),
+	}
+}
+
+func (m *MockEndOfDayRepository) CreateDay(ctx context.Context, day *domain.BusinessDay) (bool, error) {
+	key := day.Date.Format("2006-01-02")
+	if _, ok := m.days[key]; ok {
+		return false, nil
+	}
+	m.days[key] = day
+	return true, nil
+}
+
+func (m *MockEndOfDayRepository) UpdateDayTotals(ctx context.Context, day *domain.BusinessDay) error {
+	m.days[day.Date.Format("2006-01-02")] = day
+	return nil
+}
+
+func (m *MockEndOfDayRepository) LastClosedDate(ctx context.Context) (*time.Time, error) {
+	var last *time.Time
+	for _, day := range m.days {
+		if last == nil || day.Date.After(*last) {
+			date := day.Date
+			last = &date
+		}
+	}
+	return last, nil
+}
+
+func (m *MockEndOfDayRepository) ListCreditsForAccrual(ctx context.Context, date time.Time) ([]*domain.Credit, error) {
+	var credits []*domain.Credit
+	for _, credit := range m.credits {
+		if credit.RemainingDebt > 0 && credit.CreatedAt.Before(date) {
+			credits = append(credits, credit)
+		}
+	}
+	return credits, nil
+}
+
+func (m *MockEndOfDayRepository) ListAccountsForAccrual(ctx context.Context) ([]*domain.Account, error) {
+	var accounts []*domain.Account
+	for _, account := range m.accounts {
+		if account.Product == domain.AccountProductSavings && account.Balance > 0 {
+			accounts = append(accounts, account)
+		}
+	}
+	return accounts, nil
+}
+
+func (m *MockEndOfDayRepository) AddAccrual(ctx context.Context, accrual *domain.InterestAccrual) (bool, error) {
+	id := accrual.AccountID
+	if accrual.CreditID != nil {
+		id = *accrual.CreditID
+	}
+	if accrual.DepositID != nil {
+		id = *accrual.DepositID
+	}
+	key := fmt.Sprintf("%s/%s/%d", accrual.Kind, accrual.BusinessDate.Format("2006-01-02"), id)
+	if _, ok := m.accruals[key]; ok {
+		return false, nil
+	}
+	m.accruals[key] = accrual
+
+	switch accrual.Kind {
+	case domain.InterestAccrualCredit:
+		m.creditAccrue[*accrual.CreditID] += accrual.Amount
+	case domain.InterestAccrualDeposit:
+		m.deposits.deposits[*accrual.DepositID].AccruedInterest += accrual.Amount
+	case domain.InterestAccrualOverdraft:
+		m.overdraft[accrual.AccountID] += accrual.Amount
+	default:
+		m.savings[accrual.AccountID] += accrual.Amount
+	}
+	return true, nil
+}
+
+func (m *MockEndOfDayRepository) ListAccruedSavings(ctx context.Context) ([]*domain.AccruedSavings, error) {
+	var savings []*domain.AccruedSavings
+	for _, account := range m.accounts {
+		if m.savings[account.ID] >= 0.01 {
+			savings = append(savings, &domain.AccruedSavings{AccountID: account.ID, Amount: m.savings[account.ID]})
+		}
+	}
+	return savings, nil
+}
+
+func (m *MockEndOfDayRepository) PostSavingsInterest(ctx context.Context, accountID int, amount float64, description string) error {
+	for _, account := range m.accounts {
+		if account.ID == accountID {
+			account.Balance += amount
+		}
+	}
+	m.savings[accountID] -= amount
+	m.posted = append(m.posted, amount)
+	return nil
+}
+
+func (m *MockEndOfDayRepository) ListOverdraftAccounts(ctx context.Context) ([]*domain.Account, error) {
+	var accounts []*domain.Account
+	for _, account := range m.accounts {
+		if account.Balance < 0 {
+			accounts = append(accounts, account)
+		}
+	}
+	return accounts, nil
+}
+
+func (m *MockEndOfDayRepository) ListAccruedOverdraftInterest(ctx context.Context) ([]*domain.AccruedOverdraftInterest, error) {
+	var accrued []*domain.AccruedOverdraftInterest
+	for _, account := range m.accounts {
+		if m.overdraft[account.ID] >= 0.01 {
+			accrued = append(accrued, &domain.AccruedOverdraftInterest{AccountID: account.ID, Amount: m.overdraft[account.ID]})
+		}
+	}
+	return accrued, nil
+}
+
+func (m *MockEndOfDayRepository) ChargeOverdraftInterest(ctx context.Context, accountID int, amount float64, description string) (float64, error) {
+	for _, account := range m.accounts {
+		if account.ID == accountID {
+			amount = math.Min(amount, math.Max(account.Balance+account.OverdraftLimit, 0))
+			account.Balance -= amount
+		}
+	}
+	if amount <= 0 {
+		return 0, nil
+	}
+	m.overdraft[accountID] -= amount
+	m.charged = append(m.charged, amount)
+	return amount, nil
+}
+
+func setupEndOfDayService(t *testing.T, savingsRate float64, now time.Time) (*endOfDayService, *MockEndOfDayRepository, *fakeClock) {
+	t.Helper()
+
+	cfg := &config.Config{EOD: config.EODConfig{Timezone: "Europe/Moscow", SavingsRate: savingsRate}}
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	eodRepo := NewMockEndOfDayRepository()
+	clock := &fakeClock{now: now}
+
+	svc, err := NewEndOfDayService(cfg, eodRepo, NewMockDepositRepository(), mockTxManager{}, clock, logger)
+	if err != nil {
+		t.Fatalf("NewEndOfDayService failed: %v", err)
+	}
+
+	return svc.(*endOfDayService), eodRepo, clock
+}
+
+func TestEndOfDay_SimulatesMonthsOfAccruals(t *testing.T) {
+	// 2024 год високосный: 366 дней
+	start := time.Date(2024, time.January, 1, 10, 0, 0, 0, time.UTC)
+	svc, eodRepo, clock := setupEndOfDayService(t, 3.66, start)
+	ctx := context.Background()
+
+	eodRepo.credits = []*domain.Credit{{
+		ID: 1, AccountID: 10, RemainingDebt: 100000, InterestRate: 18.3,
+		CreatedAt: time.Date(2023, time.December, 31, 12, 0, 0, 0, time.UTC),
+	}}
+	eodRepo.accounts = []*domain.Account{
+		{ID: 10, Product: domain.AccountProductSavings, Balance: 10000, Status: domain.AccountStatusActive},
+		// Проценты на остаток начисляются только по накопительным счетам
+		{ID: 11, Product: domain.AccountProductChecking, Balance: 10000, Status: domain.AccountStatusActive},
+	}
+
+	// Первый запуск закрывает только вчерашний день
+	result, err := svc.RunEndOfDay(ctx)
+	if err != nil || result.Processed != 1 {
+		t.Fatalf("expected first run to close 1 day, got %+v (err %v)", result, err)
+	}
+
+	// Ежедневные запуски до 1 апреля, по несколько раз в день
+	for clock.now.Before(time.Date(2024, time.April, 1, 0, 0, 0, 0, time.UTC)) {
+		clock.now = clock.now.Add(8 * time.Hour)
+		if _, err := svc.RunEndOfDay(ctx); err != nil {
+			t.Fatalf("RunEndOfDay failed at %s: %v", clock.now, err)
+		}
+	}
+
+	// Закрыты дни с 31 декабря по 31 марта
+	if len(eodRepo.days) != 92 {
+		t.Fatalf("expected 92 closed days, got %d", len(eodRepo.days))
+	}
+
+	// 100000 * 18.3% / 366 = 50 в день, начисления с 1 января по 31 марта (91 день)
+	if got := eodRepo.creditAccrue[1]; math.Abs(got-4550) > 1e-6 {
+		t.Errorf("expected credit accrued 4550, got %f", got)
+	}
+
+	// 10000 * 3.66% / 366 = 1 в день, капитализация в конце каждого месяца
+	if len(eodRepo.posted) != 4 {
+		t.Fatalf("expected 4 month-end postings (Dec..Mar), got %v", eodRepo.posted)
+	}
+	if eodRepo.posted[1] != 31 {
+		t.Errorf("expected January posting 31, got %f", eodRepo.posted[1])
+	}
+	if eodRepo.savings[10] > 0.01 {
+		t.Errorf("expected savings to be posted, left %f", eodRepo.savings[10])
+	}
+	if eodRepo.savings[11] != 0 {
+		t.Errorf("checking account must not accrue savings interest, got %f", eodRepo.savings[11])
+	}
+}
+
+func TestEndOfDay_CloseIsIdempotent(t *testing.T) {
+	svc, eodRepo, _ := setupEndOfDayService(t, 0, time.Date(2025, time.March, 10, 12, 0, 0, 0, time.UTC))
+	ctx := context.Background()
+
+	eodRepo.credits = []*domain.Credit{{
+		ID: 1, AccountID: 10, RemainingDebt: 36500, InterestRate: 10,
+		CreatedAt: time.Date(2025, time.January, 1, 0, 0, 0, 0, time.UTC),
+	}}
+	date := time.Date(2025, time.March, 5, 0, 0, 0, 0, time.UTC)
+
+	day, err := svc.CloseBusinessDay(ctx, date)
+	if err != nil {
+		t.Fatalf("CloseBusinessDay failed: %v", err)
+	}
+	if day.CreditsAccrued != 1 || day.CreditInterest != 10 {
+		t.Errorf("unexpected day totals: %+v", day)
+	}
+
+	if _, err := svc.CloseBusinessDay(ctx, date); !errors.Is(err, ErrBusinessDayClosed) {
+		t.Errorf("expected ErrBusinessDayClosed, got %v", err)
+	}
+	if eodRepo.creditAccrue[1] != 10 {
+		t.Errorf("expected single accrual, got %f", eodRepo.creditAccrue[1])
+	}
+
+	// Текущий день еще не закончился
+	if _, err := svc.CloseBusinessDay(ctx, time.Date(2025, time.March, 10, 0, 0, 0, 0, time.UTC)); !errors.Is(err, ErrBusinessDayNotOver) {
+		t.Errorf("expected ErrBusinessDayNotOver, got %v", err)
+	}
+}
+
+func TestEndOfDay_CatchesUpMissedDays(t *testing.T) {
+	// 21:30 UTC 9 марта — уже 10 марта по Москве
+	svc, eodRepo, clock := setupEndOfDayService(t, 0, time.Date(2025, time.March, 9, 21, 30, 0, 0, time.UTC))
+	ctx := context.Background()
+
+	if _, err := svc.RunEndOfDay(ctx); err != nil {
+		t.Fatalf("RunEndOfDay failed: %v", err)
+	}
+	if _, ok := eodRepo.days["2025-03-09"]; !ok {
+		t.Fatal("expected March 9 to be closed")
+	}
+
+	eodRepo.credits = []*domain.Credit{{
+		ID: 1, AccountID: 10, RemainingDebt: 36500, InterestRate: 10,
+		CreatedAt: time.Date(2025, time.January, 1, 0, 0, 0, 0, time.UTC),
+	}}
+
+	// Процесс не работал пять дней: каждый пропущенный день начисляется от текущего остатка
+	clock.now = clock.now.AddDate(0, 0, 5)
+	result, err := svc.RunEndOfDay(ctx)
+	if err != nil || result.Processed != 5 {
+		t.Fatalf("expected 5 missed days to be closed, got %+v (err %v)", result, err)
+	}
+	if got := eodRepo.creditAccrue[1]; math.Abs(got-50) > 1e-6 {
+		t.Errorf("expected 5 daily accruals of 10, got %f", got)
+	}
+}
+
+func TestEndOfDay_ChargesOverdraftInterestDaily(t *testing.T) {
+	svc, eodRepo, clock := setupEndOfDayService(t, 0, time.Date(2024, time.January, 2, 10, 0, 0, 0, time.UTC))
+	svc.overdraftRates = map[string]float64{domain.AccountProductChecking: 36.5}
+	ctx := context.Background()
+
+	eodRepo.accounts = []*domain.Account{
+		{ID: 20, Product: domain.AccountProductChecking, Balance: -3660, OverdraftLimit: 5000, Status: domain.AccountStatusActive},
+		// Ставка для продукта не задана — проценты не начисляются
+		{ID: 21, Product: domain.AccountProductSavings, Balance: -100, OverdraftLimit: 100, Status: domain.AccountStatusActive},
+		{ID: 22, Product: domain.AccountProductChecking, Balance: 500, OverdraftLimit: 5000, Status: domain.AccountStatusActive},
+	}
+
+	// 3660 * 36.5% / 366 = 3.65 за 1 января, списывается в тот же день
+	if _, err := svc.RunEndOfDay(ctx); err != nil {
+		t.Fatalf("RunEndOfDay failed: %v", err)
+	}
+	day := eodRepo.days["2024-01-01"]
+	if day.OverdraftsAccrued != 1 || day.OverdraftCharged != 3.65 {
+		t.Errorf("expected 1 overdraft charged 3.65, got %d/%.2f", day.OverdraftsAccrued, day.OverdraftCharged)
+	}
+	if balance := eodRepo.accounts[0].Balance; math.Abs(balance+3663.65) > 1e-9 {
+		t.Errorf("expected balance -3663.65 after charge, got %f", balance)
+	}
+
+	// На следующий день база выросла на списанные проценты; доли копейки переходят дальше
+	clock.now = clock.now.Add(24 * time.Hour)
+	if _, err := svc.RunEndOfDay(ctx); err != nil {
+		t.Fatalf("RunEndOfDay failed: %v", err)
+	}
+	if len(eodRepo.charged) != 2 || eodRepo.charged[1] != 3.65 {
+		t.Errorf("expected second charge 3.65, got %v", eodRepo.charged)
+	}
+	if left := eodRepo.overdraft[20]; left <= 0 || left >= 0.01 {
+		t.Errorf("expected fraction of kopeck carried over, got %f", left)
+	}
+	if eodRepo.overdraft[21] != 0 || eodRepo.accounts[2].Balance != 500 {
+		t.Error("accounts without used overdraft or rate must not be charged")
+	}
+}
+
+func TestEndOfDay_OverdraftInterestStaysWithinLimit(t *testing.T) {
+	svc, eodRepo, _ := setupEndOfDayService(t, 0, time.Date(2024, time.January, 2, 10, 0, 0, 0, time.UTC))
+	svc.overdraftRates = map[string]float64{domain.AccountProductChecking: 36.5}
+	ctx := context.Background()
+
+	// До лимита осталось 2 рубля, начислено 3.65: списывается только 2, остальное ждет пополнения
+	eodRepo.accounts = []*domain.Account{
+		{ID: 20, Product: domain.AccountProductChecking, Balance: -3660, OverdraftLimit: 3662, Status: domain.AccountStatusActive},
+	}
+
+	if _, err := svc.RunEndOfDay(ctx); err != nil {
+		t.Fatalf("RunEndOfDay failed: %v", err)
+	}
+	if day := eodRepo.days["2024-01-01"]; day.OverdraftCharged != 2 {
+		t.Errorf("expected 2 charged, got %.2f", day.OverdraftCharged)
+	}
+	if balance := eodRepo.accounts[0].Balance; balance != -3662 {
+		t.Errorf("expected balance at the limit -3662, got %f", balance)
+	}
+	if left := eodRepo.overdraft[20]; math.Abs(left-1.65) > 1e-9 {
+		t.Errorf("expected 1.65 interest left, got %f", left)
+	}
+}
@@ -0,0 +1,332 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/vterdunov/learn-bank-app/internal/config"
+	"github.com/vterdunov/learn-bank-app/internal/domain"
+	"github.com/vterdunov/learn-bank-app/internal/repository"
+	"github.com/vterdunov/learn-bank-app/internal/utils"
+	"github.com/vterdunov/learn-bank-app/pkg/logger"
+)
+
+const (
+	// defaultFraudPageSize размер страницы очереди проверки по умолчанию
+	defaultFraudPageSize = 50
+	// maxFraudPageSize максимальный размер страницы очереди проверки
+	maxFraudPageSize = 500
+)
+
+// fraudService проверяет переводы, снятия и платежи картой перед исполнением.
+// Правила возвращают решение allow, review или reject; операции на проверке попадают
+// в очередь оператора, а одобренную операцию клиент может один раз повторить в течение ApprovalTTL.
+type fraudService struct {
+	fraudRepo          repository.FraudRepository
+	transactionRepo    repository.TransactionRepository
+	auditService       AuditService
+	clock              utils.Clock
+	location           *time.Location
+	cfg                config.FraudConfig
+	newRecipientPeriod time.Duration
+	logger             *slog.Logger
+}
+
+// NewFraudService создает новый экземпляр FraudService
+func NewFraudService(
+	cfg *config.Config,
+	fraudRepo repository.FraudRepository,
+	transactionRepo repository.TransactionRepository,
+	auditService AuditService,
+	clock utils.Clock,
+	lg *slog.Logger,
+) (FraudService, error) {
+	location, err := time.LoadLocation(cfg.EOD.Timezone)
+	if err != nil {
+		return nil, fmt.Errorf("invalid EOD timezone %q: %w", cfg.EOD.Timezone, err)
+	}
+	for _, hour := range []int{cfg.Fraud.NightStartHour, cfg.Fraud.NightEndHour} {
+		if hour < 0 || hour > 23 {
+			return nil, fmt.Errorf("invalid fraud night hour %d", hour)
+		}
+	}
+
+	return &fraudService{
+		fraudRepo:          fraudRepo,
+		transactionRepo:    transactionRepo,
+		auditService:       auditService,
+		clock:              clock,
+		location:           location,
+		cfg:                cfg.Fraud,
+		newRecipientPeriod: cfg.Transfer.NewRecipientPeriod,
+		logger:             logger.WithService(lg, "fraud_service"),
+	}, nil
+}
+
+// Screen проверяет операцию перед исполнением. Возвращает nil, если операцию можно исполнять,
+// domain.ErrOperationRejected при отказе и *domain.FraudReviewError, если операция ждет решения оператора.
+// Вызывается в транзакции БД самой операции: одобрение оператора погашается в ней же
+// и остается действующим, если операция откатится.
+func (s *fraudService) Screen(ctx context.Context, op *domain.FraudOperation) error {
+	op.Amount = math.Round(op.Amount*100) / 100
+	if op.At.IsZero() {
+		op.At = s.clock.Now()
+	}
+	if meta, ok := domain.RequestMetaFromContext(ctx); ok {
+		op.IP = meta.IP
+		op.Country = meta.Country
+	}
+
+	// Операция, одобренная оператором, исполняется без повторной проверки
+	approved, err := s.fraudRepo.ConsumeApprovedReview(ctx, op, op.At.Add(-s.cfg.ApprovalTTL))
+	if err != nil {
+		s.logger.Error("Failed to consume approved fraud review", "user_id", op.UserID, "error", err)
+		return fmt.Errorf("failed to check fraud approvals: %w", err)
+	}
+	if approved != nil {
+		s.logger.Info("Operation allowed by approved fraud review", "review_id", approved.ID, "user_id", op.UserID)
+		return nil
+	}
+
+	hits, err := s.evaluate(ctx, op)
+	if err != nil {
+		return err
+	}
+
+	switch domain.FraudDecisionFor(hits) {
+	case domain.FraudDecisionReject:
+		s.logger.Warn("Operation rejected by fraud screening",
+			"user_id", op.UserID,
+			"operation", op.Type,
+			"account_id", op.AccountID,
+			"amount", op.Amount,
+			"rules", ruleNames(hits))
+		return domain.ErrOperationRejected
+	case domain.FraudDecisionReview:
+		return s.holdForReview(ctx, op, hits)
+	default:
+		return nil
+	}
+}
+
+// holdForReview ставит операцию в очередь оператора. Повтор той же операции до решения
+// не создает новую проверку. Проверка сохраняется вне транзакции вызывающего,
+// которая будет откачена из-за отказа в операции.
+func (s *fraudService) holdForReview(ctx context.Context, op *domain.FraudOperation, hits []domain.FraudRuleHit) error {
+	ctx = repository.DetachTx(ctx)
+
+	pending, err := s.fraudRepo.FindPendingReview(ctx, op)
+	if err != nil {
+		s.logger.Error("Failed to find pending fraud review", "user_id", op.UserID, "error", err)
+		return fmt.Errorf("failed to find pending fraud review: %w", err)
+	}
+	if pending != nil {
+		return &domain.FraudReviewError{ReviewID: pending.ID}
+	}
+
+	review := &domain.FraudReview{
+		UserID:        op.UserID,
+		OperationType: op.Type,
+		AccountID:     op.AccountID,
+		ToAccountID:   op.ToAccountID,
+		CardID:        op.CardID,
+		MerchantID:    op.MerchantID,
+		Amount:        op.Amount,
+		IP:            op.IP,
+		Country:       op.Country,
+		Rules:         hits,
+	}
+	if err := s.fraudRepo.CreateReview(ctx, review); err != nil {
+		s.logger.Error("Failed to create fraud review", "user_id", op.UserID, "error", err)
+		return fmt.Errorf("failed to create fraud review: %w", err)
+	}
+
+	s.logger.Warn("Operation held for fraud review",
+		"review_id", review.ID,
+		"user_id", op.UserID,
+		"operation", op.Type,
+		"account_id", op.AccountID,
+		"amount", op.Amount,
+		"rules", ruleNames(hits))
+
+	return &domain.FraudReviewError{ReviewID: review.ID}
+}
+
+// evaluate применяет правила к операции и возвращает сработавшие
+func (s *fraudService) evaluate(ctx context.Context, op *domain.FraudOperation) ([]domain.FraudRuleHit, error) {
+	var hits []domain.FraudRuleHit
+	hit := func(rule, decision, reason string, args ...interface{}) {
+		hits = append(hits, domain.FraudRuleHit{Rule: rule, Decision: decision, Reason: fmt.Sprintf(reason, args...)})
+	}
+
+	// Пороговые суммы
+	if s.cfg.RejectAmount > 0 && op.Amount >= s.cfg.RejectAmount {
+		hit(domain.FraudRuleAmountLimit, domain.FraudDecisionReject,
+			"amount %.2f exceeds limit %.2f", op.Amount, s.cfg.RejectAmount)
+	}
+	if s.cfg.MandatoryControlAmount > 0 && op.Amount >= s.cfg.MandatoryControlAmount {
+		hit(domain.FraudRuleMandatoryControl, domain.FraudDecisionReview,
+			"amount %.2f is subject to mandatory control (threshold %.2f)", op.Amount, s.cfg.MandatoryControlAmount)
+	}
+
+	// Частота исходящих операций клиента
+	if s.cfg.VelocityMaxCount > 0 || s.cfg.VelocityMaxAmount > 0 {
+		stats, err := s.transactionRepo.GetOutgoingStats(ctx, op.UserID, op.At.Add(-s.cfg.VelocityWindow))
+		if err != nil {
+			s.logger.Error("Failed to get outgoing stats", "user_id", op.UserID, "error", err)
+			return nil, fmt.Errorf("failed to get outgoing stats: %w", err)
+		}
+		if s.cfg.VelocityMaxCount > 0 && stats.Count+1 > s.cfg.VelocityMaxCount {
+			hit(domain.FraudRuleVelocityCount, domain.FraudDecisionReview,
+				"%d operations within %s (limit %d)", stats.Count+1, s.cfg.VelocityWindow, s.cfg.VelocityMaxCount)
+		}
+		if total := stats.Amount + op.Amount; s.cfg.VelocityMaxAmount > 0 && total > s.cfg.VelocityMaxAmount {
+			hit(domain.FraudRuleVelocityAmount, domain.FraudDecisionReview,
+				"%.2f spent within %s (limit %.2f)", total, s.cfg.VelocityWindow, s.cfg.VelocityMaxAmount)
+		}
+	}
+
+	// Крупный перевод чужому счету, на который клиент раньше не переводил
+	if s.isNewRecipientCandidate(op) {
+		since := op.At.Add(-s.newRecipientPeriod)
+		stats, err := s.transactionRepo.GetTransferStats(ctx, op.UserID, *op.ToAccountID, since)
+		if err != nil {
+			s.logger.Error("Failed to get transfer stats", "user_id", op.UserID, "to_account_id", *op.ToAccountID, "error", err)
+			return nil, fmt.Errorf("failed to get transfer stats: %w", err)
+		}
+		if stats.FirstTransferAt == nil || stats.FirstTransferAt.After(since) {
+			hit(domain.FraudRuleNewRecipient, domain.FraudDecisionReview,
+				"transfer of %.2f to a new recipient (threshold %.2f)", op.Amount, s.cfg.NewRecipientAmount)
+		}
+	}
+
+	// Крупная операция ночью по времени банка
+	if s.cfg.NightAmount > 0 && op.Amount >= s.cfg.NightAmount && s.isNightHour(op.At) {
+		hit(domain.FraudRuleUnusualHour, domain.FraudDecisionReview,
+			"amount %.2f at %s", op.Amount, op.At.In(s.location).Format("15:04"))
+	}
+
+	// Страна клиента по GeoIP
+	if country := strings.ToUpper(op.Country); country != "" {
+		if containsCountry(s.cfg.BlockedCountries, country) {
+			hit(domain.FraudRuleBlockedGeo, domain.FraudDecisionReject, "operation from blocked country %s", country)
+		} else if len(s.cfg.AllowedCountries) > 0 && !containsCountry(s.cfg.AllowedCountries, country) {
+			hit(domain.FraudRuleUnusualGeo, domain.FraudDecisionReview, "operation from unusual country %s", country)
+		}
+	}
+
+	return hits, nil
+}
+
+// isNewRecipientCandidate проверяет, что операция - перевод чужому счету на сумму не ниже порога
+func (s *fraudService) isNewRecipientCandidate(op *domain.FraudOperation) bool {
+	if op.Type != domain.FraudOperationTransfer || op.ToAccountID == nil || s.cfg.NewRecipientAmount <= 0 {
+		return false
+	}
+	if op.ToUserID != nil && *op.ToUserID == op.UserID {
+		return false
+	}
+	return op.Amount >= s.cfg.NewRecipientAmount
+}
+
+// isNightHour проверяет, попадает ли момент в ночные часы; окно может переходить через полночь
+func (s *fraudService) isNightHour(at time.Time) bool {
+	start, end := s.cfg.NightStartHour, s.cfg.NightEndHour
+	if start == end {
+		return false
+	}
+	hour := at.In(s.location).Hour()
+	if start < end {
+		return hour >= start && hour < end
+	}
+	return hour >= start || hour < end
+}
+
+// ListReviews возвращает проверки с указанным статусом (по умолчанию pending)
+func (s *fraudService) ListReviews(ctx context.Context, status string, limit, offset int) ([]*domain.FraudReview, error) {
+	if status == "" {
+		status = domain.FraudReviewStatusPending
+	}
+	if !domain.IsValidFraudReviewStatus(status) {
+		return nil, domain.ErrInvalidFraudReviewStatus
+	}
+
+	if limit <= 0 {
+		limit = defaultFraudPageSize
+	}
+	if limit > maxFraudPageSize {
+		limit = maxFraudPageSize
+	}
+
+	reviews, err := s.fraudRepo.ListReviews(ctx, status, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list fraud reviews: %w", err)
+	}
+
+	return reviews, nil
+}
+
+// ApproveReview одобряет операцию: клиент может повторить ее один раз в течение ApprovalTTL
+func (s *fraudService) ApproveReview(ctx context.Context, adminID, reviewID int, comment string) (*domain.FraudReview, error) {
+	return s.resolve(ctx, adminID, reviewID, domain.FraudReviewStatusApproved, domain.AuditActionAdminFraudApprove, comment)
+}
+
+// DeclineReview отклоняет операцию
+func (s *fraudService) DeclineReview(ctx context.Context, adminID, reviewID int, comment string) (*domain.FraudReview, error) {
+	return s.resolve(ctx, adminID, reviewID, domain.FraudReviewStatusDeclined, domain.AuditActionAdminFraudDecline, comment)
+}
+
+// resolve фиксирует решение оператора и записывает его в журнал аудита
+func (s *fraudService) resolve(ctx context.Context, adminID, reviewID int, status, action, comment string) (*domain.FraudReview, error) {
+	review, err := s.fraudRepo.ResolveReview(ctx, reviewID, status, adminID, strings.TrimSpace(comment))
+	if err != nil {
+		s.logger.Warn("Failed to resolve fraud review", "review_id", reviewID, "admin_id", adminID, "status", status, "error", err)
+		return nil, err
+	}
+
+	s.logger.Info("Fraud review resolved", "review_id", reviewID, "admin_id", adminID, "status", status)
+
+	event := NewUserAuditEvent(adminID, action, "fraud_review", auditResourceID(reviewID))
+	event.ActorType = domain.AuditActorAdmin
+	event.Before = domain.NewAuditState(map[string]interface{}{"status": domain.FraudReviewStatusPending})
+	event.After = domain.NewAuditState(map[string]interface{}{"status": review.Status})
+	event.Metadata = domain.NewAuditState(map[string]interface{}{
+		"user_id":        review.UserID,
+		"operation_type": review.OperationType,
+		"account_id":     review.AccountID,
+		"amount":         review.Amount,
+		"rules":          ruleNames(review.Rules),
+		"comment":        review.Comment,
+	})
+	// Ошибка аудита уже залогирована, решение по проверке сохранено
+	_ = s.auditService.Record(ctx, event)
+
+	return review, nil
+}
+
+// ruleNames возвращает названия сработавших правил для логов и аудита
+func ruleNames(hits []domain.FraudRuleHit) []string {
+	names := make([]string, 0, len(hits))
+	for _, hit := range hits {
+		names = append(names, hit.Rule)
+	}
+	return names
+}
+
+// isScreeningError сообщает, что операция отклонена или отложена антифродом; такой отказ уже залогирован
+func isScreeningError(err error) bool {
+	return errors.Is(err, domain.ErrOperationRejected) || errors.Is(err, domain.ErrOperationUnderReview)
+}
+
+// containsCountry проверяет наличие страны в списке без учета регистра
+func containsCountry(countries []string, country string) bool {
+	return slices.ContainsFunc(countries, func(c string) bool {
+		return strings.EqualFold(c, country)
+	})
+}
@@ -0,0 +1,396 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/vterdunov/learn-bank-app/internal/config"
+	"github.com/vterdunov/learn-bank-app/internal/domain"
+)
+
+// mockFraudService пропускает все операции без проверки
+type mockFraudService struct{}
+
+func (mockFraudService) Screen(ctx context.Context, op *domain.FraudOperation) error {
+	return nil
+}
+
+func (mockFraudService) ListReviews(ctx context.Context, status string, limit, offset int) ([]*domain.FraudReview, error) {
+	return nil, nil
+}
+
+func (mockFraudService) ApproveReview(ctx context.Context, adminID, reviewID int, comment string) (*domain.FraudReview, error) {
+	return nil, domain.ErrFraudReviewNotFound
+}
+
+func (mockFraudService) DeclineReview(ctx context.Context, adminID, reviewID int, comment string) (*domain.FraudReview, error) {
+	return nil, domain.ErrFraudReviewNotFound
+}
+
+// MockFraudRepository очередь проверки в памяти
+type MockFraudRepository struct {
+	reviews []*domain.FraudReview
+}
+
+func (m *MockFraudRepository) CreateReview(ctx context.Context, review *domain.FraudReview) error {
+	review.ID = len(m.reviews) + 1
+	review.Status = domain.FraudReviewStatusPending
+	review.CreatedAt = time.Now()
+	m.reviews = append(m.reviews, review)
+	return nil
+}
+
+func (m *MockFraudRepository) GetReview(ctx context.Context, id int) (*domain.FraudReview, error) {
+	for _, review := range m.reviews {
+		if review.ID == id {
+			return review, nil
+		}
+	}
+	return nil, domain.ErrFraudReviewNotFound
+}
+
+func (m *MockFraudRepository) ListReviews(ctx context.Context, status string, limit, offset int) ([]*domain.FraudReview, error) {
+	var reviews []*domain.FraudReview
+	for _, review := range m.reviews {
+		if review.Status == status {
+			reviews = append(reviews, review)
+		}
+	}
+	return reviews, nil
+}
+
+func (m *MockFraudRepository) ResolveReview(ctx context.Context, id int, status string, reviewerID int, comment string) (*domain.FraudReview, error) {
+	review, err := m.GetReview(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if review.Status != domain.FraudReviewStatusPending {
+		return nil, domain.ErrFraudReviewResolved
+	}
+	now := time.Now()
+	review.Status = status
+	review.ReviewerID = &reviewerID
+	review.Comment = comment
+	review.ReviewedAt = &now
+	return review, nil
+}
+
+func (m *MockFraudRepository) FindPendingReview(ctx context.Context, op *domain.FraudOperation) (*domain.FraudReview, error) {
+	for _, review := range m.reviews {
+		if review.Status == domain.FraudReviewStatusPending && matchesFraudOperation(review, op) {
+			return review, nil
+		}
+	}
+	return nil, nil
+}
+
+func (m *MockFraudRepository) ConsumeApprovedReview(ctx context.Context, op *domain.FraudOperation, since time.Time) (*domain.FraudReview, error) {
+	for _, review := range m.reviews {
+		if review.Status == domain.FraudReviewStatusApproved && review.ConsumedAt == nil &&
+			!review.ReviewedAt.Before(since) && matchesFraudOperation(review, op) {
+			now := time.Now()
+			review.ConsumedAt = &now
+			return review, nil
+		}
+	}
+	return nil, nil
+}
+
+func matchesFraudOperation(review *domain.FraudReview, op *domain.FraudOperation) bool {
+	sameID := func(a, b *int) bool {
+		return (a == nil && b == nil) || (a != nil && b != nil && *a == *b)
+	}
+	return review.UserID == op.UserID && review.OperationType == op.Type && review.AccountID == op.AccountID &&
+		sameID(review.ToAccountID, op.ToAccountID) && sameID(review.CardID, op.CardID) &&
+		review.MerchantID == op.MerchantID && review.Amount == op.Amount
+}
+
+type fraudTestDeps struct {
+	reviews      *MockFraudRepository
+	accounts     *MockAccountStore
+	transactions *MockTransactionRepository
+	audit        *MockAuditRepository
+	clock        *fakeClock
+}
+
+// setupFraudService создает сервис антифрода с порогами по умолчанию.
+// Счет 1 клиента 1, счет 2 его же, счет 3 другого клиента; банковское время 12:00 МСК.
+func setupFraudService(t *testing.T, modify func(cfg *config.FraudConfig)) (FraudService, *fraudTestDeps) {
+	t.Helper()
+
+	cfg := &config.Config{
+		EOD:      config.EODConfig{Timezone: "Europe/Moscow"},
+		Transfer: config.TransferConfig{NewRecipientPeriod: 24 * time.Hour},
+		Fraud: config.FraudConfig{
+			MandatoryControlAmount: 600000,
+			VelocityWindow:         time.Hour,
+			VelocityMaxCount:       3,
+			VelocityMaxAmount:      700000,
+			NewRecipientAmount:     100000,
+			NightStartHour:         1,
+			NightEndHour:           5,
+			NightAmount:            50000,
+			AllowedCountries:       []string{"RU"},
+			BlockedCountries:       []string{"KP"},
+			ApprovalTTL:            24 * time.Hour,
+		},
+	}
+	if modify != nil {
+		modify(&cfg.Fraud)
+	}
+
+	accounts := &MockAccountStore{accounts: map[int]*domain.Account{
+		1: {ID: 1, UserID: 1, Balance: 1000000, Status: domain.AccountStatusActive},
+		2: {ID: 2, UserID: 1, Status: domain.AccountStatusActive},
+		3: {ID: 3, UserID: 2, Status: domain.AccountStatusActive},
+	}}
+	deps := &fraudTestDeps{
+		reviews:      &MockFraudRepository{},
+		accounts:     accounts,
+		transactions: &MockTransactionRepository{accounts: accounts},
+		clock:        &fakeClock{now: time.Date(2024, 3, 15, 9, 0, 0, 0, time.UTC)},
+	}
+	auditService, auditRepo := setupAuditService()
+	deps.audit = auditRepo
+
+	svc, err := NewFraudService(cfg, deps.reviews, deps.transactions, auditService, deps.clock, slog.New(slog.NewTextHandler(os.Stdout, nil)))
+	if err != nil {
+		t.Fatalf("NewFraudService failed: %v", err)
+	}
+	return svc, deps
+}
+
+func transferOp(to int, amount float64) *domain.FraudOperation {
+	toUserID := 1
+	if to == 3 {
+		toUserID = 2
+	}
+	return &domain.FraudOperation{Type: domain.FraudOperationTransfer, UserID: 1, AccountID: 1, ToAccountID: &to, ToUserID: &toUserID, Amount: amount}
+}
+
+func TestFraudService_AllowsOrdinaryOperation(t *testing.T) {
+	svc, deps := setupFraudService(t, nil)
+
+	if err := svc.Screen(context.Background(), transferOp(2, 5000)); err != nil {
+		t.Fatalf("ordinary transfer must be allowed, got %v", err)
+	}
+	if len(deps.reviews.reviews) != 0 {
+		t.Errorf("allowed operation must not be queued")
+	}
+}
+
+func TestFraudService_MandatoryControlReviewAndApproval(t *testing.T) {
+	svc, deps := setupFraudService(t, nil)
+	ctx := context.Background()
+
+	err := svc.Screen(ctx, transferOp(2, 600000))
+	var reviewErr *domain.FraudReviewError
+	if !errors.As(err, &reviewErr) || !errors.Is(err, domain.ErrOperationUnderReview) {
+		t.Fatalf("expected FraudReviewError, got %v", err)
+	}
+	review := deps.reviews.reviews[0]
+	if len(review.Rules) != 1 || review.Rules[0].Rule != domain.FraudRuleMandatoryControl {
+		t.Errorf("expected mandatory control rule, got %+v", review.Rules)
+	}
+
+	// Повтор до решения оператора не создает новую проверку
+	if err := svc.Screen(ctx, transferOp(2, 600000)); !errors.As(err, &reviewErr) || reviewErr.ReviewID != review.ID {
+		t.Fatalf("expected the same review, got %v", err)
+	}
+	if len(deps.reviews.reviews) != 1 {
+		t.Fatalf("expected 1 review, got %d", len(deps.reviews.reviews))
+	}
+
+	approved, err := svc.ApproveReview(ctx, 99, review.ID, "client confirmed by phone")
+	if err != nil {
+		t.Fatalf("ApproveReview failed: %v", err)
+	}
+	if approved.Status != domain.FraudReviewStatusApproved || *approved.ReviewerID != 99 {
+		t.Errorf("unexpected approved review: %+v", approved)
+	}
+	if len(deps.audit.events) != 1 || deps.audit.events[0].Action != domain.AuditActionAdminFraudApprove ||
+		deps.audit.events[0].ActorType != domain.AuditActorAdmin {
+		t.Errorf("expected admin approval audit event, got %+v", deps.audit.events)
+	}
+
+	// Другая сумма одобрением не покрывается
+	if err := svc.Screen(ctx, transferOp(2, 650000)); !errors.Is(err, domain.ErrOperationUnderReview) {
+		t.Errorf("approval must cover only the same operation, got %v", err)
+	}
+
+	// Одобренная операция пропускается один раз
+	if err := svc.Screen(ctx, transferOp(2, 600000)); err != nil {
+		t.Fatalf("approved operation must be allowed, got %v", err)
+	}
+	if err := svc.Screen(ctx, transferOp(2, 600000)); !errors.Is(err, domain.ErrOperationUnderReview) {
+		t.Errorf("approval must be consumed once, got %v", err)
+	}
+
+	if _, err := svc.DeclineReview(ctx, 99, review.ID, ""); !errors.Is(err, domain.ErrFraudReviewResolved) {
+		t.Errorf("expected ErrFraudReviewResolved, got %v", err)
+	}
+}
+
+func TestFraudService_RejectRules(t *testing.T) {
+	svc, deps := setupFraudService(t, func(cfg *config.FraudConfig) {
+		cfg.RejectAmount = 900000
+	})
+
+	if err := svc.Screen(context.Background(), transferOp(2, 900000)); !errors.Is(err, domain.ErrOperationRejected) {
+		t.Errorf("expected ErrOperationRejected for amount limit, got %v", err)
+	}
+
+	ctx := domain.ContextWithRequestMeta(context.Background(), domain.RequestMeta{IP: "10.0.0.1", Country: "KP"})
+	if err := svc.Screen(ctx, transferOp(2, 100)); !errors.Is(err, domain.ErrOperationRejected) {
+		t.Errorf("expected ErrOperationRejected for blocked country, got %v", err)
+	}
+
+	if len(deps.reviews.reviews) != 0 {
+		t.Errorf("rejected operations must not be queued")
+	}
+}
+
+func TestFraudService_ReviewRules(t *testing.T) {
+	tests := []struct {
+		name string
+		ctx  func() context.Context
+		now  time.Time
+		op   *domain.FraudOperation
+		rule string
+	}{
+		{
+			name: "unusual country",
+			ctx: func() context.Context {
+				return domain.ContextWithRequestMeta(context.Background(), domain.RequestMeta{Country: "BR"})
+			},
+			op:   transferOp(2, 100),
+			rule: domain.FraudRuleUnusualGeo,
+		},
+		{
+			name: "night hours in bank timezone",
+			now:  time.Date(2024, 3, 15, 0, 30, 0, 0, time.UTC), // 03:30 МСК
+			op:   &domain.FraudOperation{Type: domain.FraudOperationWithdrawal, UserID: 1, AccountID: 1, Amount: 50000},
+			rule: domain.FraudRuleUnusualHour,
+		},
+		{
+			name: "new recipient",
+			op:   transferOp(3, 100000),
+			rule: domain.FraudRuleNewRecipient,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc, deps := setupFraudService(t, nil)
+			if !tt.now.IsZero() {
+				deps.clock.now = tt.now
+			}
+			ctx := context.Background()
+			if tt.ctx != nil {
+				ctx = tt.ctx()
+			}
+
+			if err := svc.Screen(ctx, tt.op); !errors.Is(err, domain.ErrOperationUnderReview) {
+				t.Fatalf("expected review, got %v", err)
+			}
+			rules := deps.reviews.reviews[0].Rules
+			if len(rules) != 1 || rules[0].Rule != tt.rule {
+				t.Errorf("expected rule %s, got %+v", tt.rule, rules)
+			}
+		})
+	}
+}
+
+func TestFraudService_NewRecipientSkipsKnownAndOwnAccounts(t *testing.T) {
+	svc, deps := setupFraudService(t, nil)
+
+	// Перевод себе не проверяется как новый получатель
+	if err := svc.Screen(context.Background(), transferOp(2, 100000)); err != nil {
+		t.Fatalf("own account transfer must be allowed, got %v", err)
+	}
+
+	// Получатель, которому переводили раньше срока новизны, не новый
+	from, to := 1, 3
+	deps.transactions.transactions = append(deps.transactions.transactions, &domain.Transaction{
+		FromAccount: &from, ToAccount: &to, Amount: 10, Type: domain.TransactionTypeTransfer, Status: "completed",
+		CreatedAt: deps.clock.now.Add(-48 * time.Hour),
+	})
+	if err := svc.Screen(context.Background(), transferOp(3, 100000)); err != nil {
+		t.Fatalf("known recipient transfer must be allowed, got %v", err)
+	}
+}
+
+func TestFraudService_Velocity(t *testing.T) {
+	svc, deps := setupFraudService(t, nil)
+
+	from := 1
+	for i := 0; i < 3; i++ {
+		deps.transactions.transactions = append(deps.transactions.transactions, &domain.Transaction{
+			FromAccount: &from, Amount: 100, Type: "withdrawal", Status: "completed",
+			CreatedAt: deps.clock.now.Add(-10 * time.Minute),
+		})
+	}
+
+	withdrawal := func() *domain.FraudOperation {
+		return &domain.FraudOperation{Type: domain.FraudOperationWithdrawal, UserID: 1, AccountID: 1, Amount: 100}
+	}
+	if err := svc.Screen(context.Background(), withdrawal()); !errors.Is(err, domain.ErrOperationUnderReview) {
+		t.Fatalf("expected review, got %v", err)
+	}
+	if rules := deps.reviews.reviews[0].Rules; len(rules) != 1 || rules[0].Rule != domain.FraudRuleVelocityCount {
+		t.Errorf("expected velocity rule, got %+v", rules)
+	}
+
+	// Операции за пределами окна не учитываются
+	deps.clock.now = deps.clock.now.Add(time.Hour)
+	if err := svc.Screen(context.Background(), withdrawal()); err != nil {
+		t.Errorf("operations outside the window must not count, got %v", err)
+	}
+}
+
+func TestFraudService_ListReviews(t *testing.T) {
+	svc, _ := setupFraudService(t, nil)
+
+	if _, err := svc.ListReviews(context.Background(), "unknown", 0, 0); !errors.Is(err, domain.ErrInvalidFraudReviewStatus) {
+		t.Errorf("expected ErrInvalidFraudReviewStatus, got %v", err)
+	}
+
+	_ = svc.Screen(context.Background(), transferOp(2, 700000))
+	reviews, err := svc.ListReviews(context.Background(), "", 0, 0)
+	if err != nil {
+		t.Fatalf("ListReviews failed: %v", err)
+	}
+	if len(reviews) != 1 {
+		t.Errorf("expected 1 pending review, got %d", len(reviews))
+	}
+}
+
+func TestAccountService_TransferHeldForReview(t *testing.T) {
+	fraud, deps := setupFraudService(t, nil)
+	auditService, _ := setupAuditService()
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	accountService := NewAccountService(&config.Config{}, deps.accounts, deps.transactions, nil, nil, nil, nil,
+		&mockStoreAccessControl{accounts: deps.accounts}, mockTxManager{}, nil, nil, fraud, mockSanctionsService{}, mockKYCService{}, auditService, logger)
+	ctx := context.Background()
+
+	if err := accountService.TransferMoney(ctx, 1, 1, 3, 600000); !errors.Is(err, domain.ErrOperationUnderReview) {
+		t.Fatalf("expected transfer held for review, got %v", err)
+	}
+	if deps.accounts.accounts[1].Balance != 1000000 || deps.accounts.accounts[3].Balance != 0 {
+		t.Fatalf("held transfer must not move money")
+	}
+
+	if _, err := fraud.ApproveReview(ctx, 99, deps.reviews.reviews[0].ID, ""); err != nil {
+		t.Fatalf("ApproveReview failed: %v", err)
+	}
+	if err := accountService.TransferMoney(ctx, 1, 1, 3, 600000); err != nil {
+		t.Fatalf("approved transfer failed: %v", err)
+	}
+	if deps.accounts.accounts[3].Balance != 600000 {
+		t.Errorf("expected approved transfer to be executed, balance %v", deps.accounts.accounts[3].Balance)
+	}
+}
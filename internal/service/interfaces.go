@@ -2,9 +2,11 @@ package service
 
 import (
 	"context"
+	"io"
 	"time"
 
 	"github.com/vterdunov/learn-bank-app/internal/domain"
+	"github.com/vterdunov/learn-bank-app/pkg/iso8583"
 )
 
 // AuthService определяет интерфейс сервиса аутентификации
@@ -20,15 +22,102 @@ type AccountService interface {
 	GetUserAccounts(ctx context.Context, userID int) ([]*domain.Account, error)
 	DepositMoney(ctx context.Context, userID, accountID int, amount float64) error
 	WithdrawMoney(ctx context.Context, userID, accountID int, amount float64) error
+	// WithdrawByCard выдает наличные по карте счета после проверки PIN
+	WithdrawByCard(ctx context.Context, userID, accountID, cardID int, pin string, amount float64) error
 	TransferMoney(ctx context.Context, userID, fromAccountID, toAccountID int, amount float64) error
+
+	FreezeAccount(ctx context.Context, userID, accountID int) (*domain.Account, error)
+	UnfreezeAccount(ctx context.Context, userID, accountID int) (*domain.Account, error)
+	// SetOverdraftLimit устанавливает лимит овердрафта в пределах условий продукта счета
+	SetOverdraftLimit(ctx context.Context, userID, accountID int, limit float64) (*domain.Account, error)
+	// CloseAccount закрывает счет; положительный остаток переводится на residualAccountID
+	CloseAccount(ctx context.Context, userID, accountID, residualAccountID int) (*domain.Account, error)
+	GetAccountHolders(ctx context.Context, userID, accountID int) ([]*domain.AccountHolder, error)
+	AddAccountHolder(ctx context.Context, userID, accountID int, req domain.AddAccountHolderRequest) (*domain.AccountHolder, error)
+	RemoveAccountHolder(ctx context.Context, userID, accountID, holderUserID int) error
+}
+
+// RecipientService определяет интерфейс поиска получателей и переводов по номеру счета, email или телефону
+type RecipientService interface {
+	ResolveRecipient(ctx context.Context, userID int, lookup domain.RecipientLookup) (*domain.Recipient, error)
+	Transfer(ctx context.Context, userID, fromAccountID int, lookup domain.RecipientLookup, amount float64) (*domain.Recipient, error)
+	// TransferToAccount переводит на ранее найденный счет получателя с проверкой лимита нового получателя
+	TransferToAccount(ctx context.Context, userID, fromAccountID, toAccountID int, amount float64) error
+	GetAliases(ctx context.Context, userID int) ([]*domain.PaymentAlias, error)
+	CreateAlias(ctx context.Context, userID int, alias domain.PaymentAlias) (*domain.PaymentAlias, error)
+	DeleteAlias(ctx context.Context, userID, aliasID int) error
 }
 
 // CardService определяет интерфейс сервиса управления картами
 type CardService interface {
-	CreateCard(ctx context.Context, userID, accountID int) (*domain.Card, error)
+	CreateCard(ctx context.Context, userID int, req domain.CreateCardRequest) (*domain.Card, error)
 	GetAccountCards(ctx context.Context, userID, accountID int) ([]*domain.Card, error)
 	DecryptCardData(ctx context.Context, userID int, card *domain.Card) (*CardData, error)
-	ProcessPayment(ctx context.Context, userID, cardID int, amount float64) error
+	ProcessPayment(ctx context.Context, userID, cardID int, amount float64, merchantID string) error
+
+	AuthorizePayment(ctx context.Context, userID, cardID int, amount float64, merchantID, description string) (*domain.CardHold, error)
+	// AuthorizeGatewayPayment авторизует платеж из шлюза эквайринга после проверки реквизитов карты
+	AuthorizeGatewayPayment(ctx context.Context, cardID int, amount float64, merchantID, description string) (*domain.CardHold, error)
+	// CapturePayment, VoidAuthorization и RefundPayment — операции эквайера: шлюза (operatorID = 0) или оператора
+	CapturePayment(ctx context.Context, operatorID, holdID int, amount float64) (*domain.CardHold, error)
+	VoidAuthorization(ctx context.Context, operatorID, holdID int) (*domain.CardHold, error)
+	// CancelAuthorization отменяет авторизацию, созданную самим пользователем через API
+	CancelAuthorization(ctx context.Context, userID, holdID int) (*domain.CardHold, error)
+	RefundPayment(ctx context.Context, operatorID, holdID int, amount float64) (*domain.CardHold, error)
+	GetAccountHolds(ctx context.Context, userID, accountID int) ([]*domain.CardHold, error)
+	// ExpireHolds снимает просроченные авторизации (задача JobRunner)
+	ExpireHolds(ctx context.Context) (*JobResult, error)
+	// VerifyCardholder находит карту по номеру и проверяет срок действия (ММ/ГГ) и CVV
+	VerifyCardholder(ctx context.Context, pan, expiry, cvv string) (*domain.Card, error)
+	PayByCardDetails(ctx context.Context, userID int, req CardDetailsPaymentRequest) error
+	// BackfillPANHashes заполняет хеши номеров карт, выпущенных до появления поиска по номеру
+	BackfillPANHashes(ctx context.Context) (int, error)
+
+	SetPIN(ctx context.Context, userID, cardID int, pin string) error
+	ChangePIN(ctx context.Context, userID, cardID int, oldPIN, newPIN string) error
+	// VerifyPIN проверяет PIN карты; после исчерпания попыток карта блокируется
+	VerifyPIN(ctx context.Context, userID, cardID int, pin string) (*domain.Card, error)
+	// UnblockCard разблокирует карту оператором
+	UnblockCard(ctx context.Context, adminID, cardID int) error
+}
+
+// CardRevealService определяет интерфейс показа полных реквизитов карты по одноразовому токену
+type CardRevealService interface {
+	// IssueRevealToken выдает одноразовый токен после повторной проверки пароля
+	IssueRevealToken(ctx context.Context, userID, cardID int, password string) (*RevealToken, error)
+	// RevealCard погашает токен и возвращает номер и срок действия карты
+	RevealCard(ctx context.Context, userID, cardID int, token string) (*CardData, error)
+}
+
+// MerchantService определяет интерфейс управления ТСП и дневных расчетов с ними
+type MerchantService interface {
+	CreateMerchant(ctx context.Context, adminID int, req domain.CreateMerchantRequest) (*domain.Merchant, error)
+	ListMerchants(ctx context.Context) ([]*domain.Merchant, error)
+	AddTerminal(ctx context.Context, adminID, merchantID int, terminalID string) error
+	GetSettlements(ctx context.Context, merchantID int, from, to time.Time) ([]*domain.MerchantSettlement, error)
+	// RunSettlement рассчитывает все прошедшие нерассчитанные дни (задача JobRunner)
+	RunSettlement(ctx context.Context) (*JobResult, error)
+	SettleDay(ctx context.Context, date time.Time) ([]*domain.MerchantSettlement, error)
+}
+
+// CashbackService определяет интерфейс программы кэшбэка по картам
+type CashbackService interface {
+	// AccruePayment начисляет кэшбэк за списанный платеж картой (в транзакции списания)
+	AccruePayment(ctx context.Context, cardID int, payment *domain.Transaction) error
+	// ReversePayment сторнирует кэшбэк пропорционально общей сумме возвратов по платежу (в транзакции возврата)
+	ReversePayment(ctx context.Context, transactionID int, refundedTotal float64) error
+	GetCardRewards(ctx context.Context, userID, cardID int) (*CardRewards, error)
+
+	CreateRule(ctx context.Context, adminID int, req domain.CreateCashbackRuleRequest) (*domain.CashbackRule, error)
+	ListRules(ctx context.Context) ([]*domain.CashbackRule, error)
+	DeleteRule(ctx context.Context, adminID, ruleID int) error
+	// PayoutCashback выплачивает кэшбэк за прошедшие месяцы (задача JobRunner)
+	PayoutCashback(ctx context.Context) (*JobResult, error)
+}
+
+// CardGatewayService определяет интерфейс обработки сообщений шлюза карточных операций (ISO 8583)
+type CardGatewayService interface {
+	HandleMessage(ctx context.Context, request *iso8583.Message) (*iso8583.Message, error)
 }
 
 // CreditService определяет интерфейс сервиса кредитования
@@ -39,18 +128,88 @@ type CreditService interface {
 	ProcessOverduePayments(ctx context.Context) error
 }
 
+// DepositService определяет интерфейс сервиса вкладов
+type DepositService interface {
+	ListProducts(ctx context.Context) ([]*DepositProductOffer, error)
+	OpenDeposit(ctx context.Context, userID int, req domain.OpenDepositRequest) (*domain.Deposit, error)
+	GetUserDeposits(ctx context.Context, userID int) ([]*domain.Deposit, error)
+	GetDeposit(ctx context.Context, userID, depositID int) (*domain.Deposit, error)
+	GetProjection(ctx context.Context, userID, depositID, months int) (*domain.DepositProjection, error)
+	CloseDeposit(ctx context.Context, userID, depositID int) (*domain.Deposit, error)
+}
+
+// StandingOrderService определяет интерфейс сервиса постоянных поручений
+type StandingOrderService interface {
+	CreateStandingOrder(ctx context.Context, userID int, req domain.StandingOrderRequest) (*domain.StandingOrder, error)
+	GetUserStandingOrders(ctx context.Context, userID int) ([]*domain.StandingOrder, error)
+	GetStandingOrder(ctx context.Context, userID, orderID int) (*domain.StandingOrder, error)
+	UpdateStandingOrder(ctx context.Context, userID, orderID int, req domain.StandingOrderRequest) (*domain.StandingOrder, error)
+	PauseStandingOrder(ctx context.Context, userID, orderID int) (*domain.StandingOrder, error)
+	ResumeStandingOrder(ctx context.Context, userID, orderID int) (*domain.StandingOrder, error)
+	CancelStandingOrder(ctx context.Context, userID, orderID int) (*domain.StandingOrder, error)
+	GetExecutions(ctx context.Context, userID, orderID, limit, offset int) ([]*domain.StandingOrderExecution, error)
+	ExecuteDue(ctx context.Context) (*JobResult, error)
+}
+
 // AnalyticsService определяет интерфейс сервиса аналитики
 type AnalyticsService interface {
 	GetMonthlyStatistics(ctx context.Context, userID int, month time.Time) (*MonthlyStats, error)
 	GetCreditLoad(ctx context.Context, userID int) (*CreditLoad, error)
 	PredictBalance(ctx context.Context, userID, accountID int, days int) (*BalancePrediction, error)
+	GetSpendingByCategory(ctx context.Context, userID int, month time.Time) ([]*CategorySpending, error)
 }
 
-// EmailService определяет интерфейс сервиса отправки email
+// EmailService определяет интерфейс сервиса email уведомлений.
+// Письма ставятся в outbox в транзакции из контекста и доставляются OutboxDispatcher.
 type EmailService interface {
-	SendPaymentNotification(userEmail string, amount float64) error
-	SendCreditNotification(userEmail string, credit *domain.Credit) error
-	SendOverdueNotification(userEmail string, payment *domain.PaymentSchedule) error
+	QueuePaymentNotification(ctx context.Context, user *domain.User, amount float64) error
+	QueueCreditNotification(ctx context.Context, user *domain.User, credit *domain.Credit) error
+	QueueOverdueNotification(ctx context.Context, user *domain.User, payment *domain.PaymentSchedule) error
+	QueueStandingOrderFailedNotification(ctx context.Context, user *domain.User, order *domain.StandingOrder, execution *domain.StandingOrderExecution) error
+	PreviewTemplate(name, locale, version string) (*RenderedEmail, error)
+}
+
+// ChannelSender определяет интерфейс транспорта доставки сообщения outbox в свой канал
+type ChannelSender interface {
+	Send(ctx context.Context, message *domain.OutboxMessage) error
+}
+
+// SMSProvider определяет интерфейс SMS шлюза
+type SMSProvider interface {
+	SendSMS(ctx context.Context, phone, text string) error
+}
+
+// NotificationService определяет интерфейс уведомлений пользователя по настроенным каналам
+type NotificationService interface {
+	NotifyDeposit(ctx context.Context, userID, accountID int, amount float64) error
+	NotifyCardPayment(ctx context.Context, userID, cardID int, amount float64) error
+	NotifyOverdue(ctx context.Context, userID int, payment *domain.PaymentSchedule) error
+	NotifyCreditIssued(ctx context.Context, userID int, credit *domain.Credit) error
+	NotifyStandingOrderFailed(ctx context.Context, userID int, order *domain.StandingOrder, execution *domain.StandingOrderExecution) error
+
+	ListNotifications(ctx context.Context, userID int, unreadOnly bool, limit, offset int) ([]*domain.Notification, error)
+	MarkRead(ctx context.Context, userID int, id int64) error
+	MarkAllRead(ctx context.Context, userID int) (int64, error)
+
+	GetPreferences(ctx context.Context, userID int) ([]*domain.NotificationPreference, error)
+	UpdatePreferences(ctx context.Context, userID int, preferences []*domain.NotificationPreference) error
+	ListEndpoints(ctx context.Context, userID int) ([]*domain.NotificationEndpoint, error)
+	SetEndpoint(ctx context.Context, userID int, channel, address string) (*domain.NotificationEndpoint, error)
+	DeleteEndpoint(ctx context.Context, userID int, channel string) error
+	SetLocale(ctx context.Context, userID int, locale string) error
+}
+
+// OutboxDispatcher определяет интерфейс фоновой доставки сообщений из outbox
+type OutboxDispatcher interface {
+	Start(ctx context.Context) error
+	Stop()
+	DispatchPending(ctx context.Context) (int, error)
+}
+
+// OutboxService определяет интерфейс администрирования outbox
+type OutboxService interface {
+	ListMessages(ctx context.Context, status string, limit, offset int) ([]*domain.OutboxMessage, error)
+	RetryMessage(ctx context.Context, adminID int, id int64) error
 }
 
 // CBRService определяет интерфейс сервиса интеграции с ЦБ РФ
@@ -58,15 +217,87 @@ type CBRService interface {
 	GetKeyRate(ctx context.Context) (float64, error)
 }
 
-// SchedulerService определяет интерфейс сервиса шедулера
+// SchedulerService определяет интерфейс задач обслуживания кредитов
 type SchedulerService interface {
+	ProcessOverduePayments(ctx context.Context) (*JobResult, error)
+}
+
+// EndOfDayService определяет интерфейс закрытия операционного дня
+type EndOfDayService interface {
+	RunEndOfDay(ctx context.Context) (*JobResult, error)
+	CloseBusinessDay(ctx context.Context, date time.Time) (*domain.BusinessDay, error)
+}
+
+// JobRunner определяет интерфейс распределенного исполнителя фоновых задач
+type JobRunner interface {
+	Register(job JobDefinition) error
 	Start(ctx context.Context) error
-	Stop()
-	ProcessOverduePayments(ctx context.Context) error
+	Stop(ctx context.Context) error
+	IsLeader() bool
+
+	ListJobs(ctx context.Context) ([]*JobStatus, error)
+	ListRuns(ctx context.Context, name string, limit, offset int) ([]*domain.JobRun, error)
+	Trigger(ctx context.Context, adminID int, name string) error
+	Pause(ctx context.Context, adminID int, name string) error
+	Resume(ctx context.Context, adminID int, name string) error
+}
+
+// FraudService определяет интерфейс проверки денежных операций правилами антифрода
+// и очереди проверки операций оператором
+type FraudService interface {
+	Screen(ctx context.Context, op *domain.FraudOperation) error
+	ListReviews(ctx context.Context, status string, limit, offset int) ([]*domain.FraudReview, error)
+	ApproveReview(ctx context.Context, adminID, reviewID int, comment string) (*domain.FraudReview, error)
+	DeclineReview(ctx context.Context, adminID, reviewID int, comment string) (*domain.FraudReview, error)
+}
+
+// SanctionsService определяет интерфейс проверки клиентов по санкционным спискам
+// и очереди проверки возможных совпадений оператором
+type SanctionsService interface {
+	ScreenUser(ctx context.Context, user *domain.User, source string) ([]*domain.SanctionsHit, error)
+	CheckCounterparty(ctx context.Context, userID int) error
+	RefreshLists(ctx context.Context) (*JobResult, error)
+	ListLists(ctx context.Context) ([]*domain.SanctionsList, error)
+	ListHits(ctx context.Context, status string, limit, offset int) ([]*domain.SanctionsHit, error)
+	ConfirmHit(ctx context.Context, adminID, hitID int, comment string) (*domain.SanctionsHit, error)
+	ClearHit(ctx context.Context, adminID, hitID int, comment string) (*domain.SanctionsHit, error)
+}
+
+// KYCService определяет интерфейс идентификации клиентов: анкета, документы, проверка оператором
+// и лимиты операций по уровню идентификации
+type KYCService interface {
+	GetProfile(ctx context.Context, userID int) (*domain.KYCProfile, error)
+	UpdateProfile(ctx context.Context, userID int, req UpdateKYCProfileRequest) (*domain.KYCProfile, error)
+	UploadDocument(ctx context.Context, userID int, docType, fileName string, content io.Reader) (*domain.KYCDocument, error)
+	ListDocuments(ctx context.Context, userID int) ([]*domain.KYCDocument, error)
+	Submit(ctx context.Context, userID int) (*domain.KYCProfile, error)
+	GetLimits(ctx context.Context, userID int) (*KYCLimits, error)
+	// CheckOperationLimit возвращает domain.ErrKYCLimitExceeded, если исходящая операция превышает лимиты уровня клиента
+	CheckOperationLimit(ctx context.Context, userID int, amount float64) error
+
+	ListProfiles(ctx context.Context, status string, limit, offset int) ([]*domain.KYCProfile, error)
+	VerifyProfile(ctx context.Context, adminID, userID int, level string) (*domain.KYCProfile, error)
+	RejectProfile(ctx context.Context, adminID, userID int, reason string) (*domain.KYCProfile, error)
+	// OpenDocument открывает файл документа для оператора; вызывающий закрывает файл
+	OpenDocument(ctx context.Context, adminID, documentID int) (*domain.KYCDocument, io.ReadCloser, error)
+}
+
+// AuditService определяет интерфейс сервиса журнала аудита
+type AuditService interface {
+	Record(ctx context.Context, event *domain.AuditEvent) error
+	QueryEvents(ctx context.Context, adminID int, filter domain.AuditFilter) ([]*domain.AuditEvent, error)
+	VerifyChain(ctx context.Context, adminID *int) (*AuditVerifyResult, error)
 }
 
 // DTO структуры для запросов и ответов
 
+// DepositProductOffer депозитный продукт со ставкой, действующей при открытии сегодня
+type DepositProductOffer struct {
+	Product      domain.DepositProduct
+	KeyRate      float64
+	InterestRate float64
+}
+
 // RegisterRequest структура запроса регистрации
 type RegisterRequest struct {
 	Username string `json:"username"`
@@ -83,6 +314,45 @@ type LoginRequest struct {
 // CreateAccountRequest структура запроса создания счета
 type CreateAccountRequest struct {
 	Currency string `json:"currency"`
+	// Product продукт счета (checking, savings); по умолчанию checking
+	Product string `json:"product"`
+}
+
+// CardDetailsPaymentRequest структура запроса оплаты по реквизитам карты (без предъявления карты)
+type CardDetailsPaymentRequest struct {
+	Number      string  `json:"card_number"`
+	ExpiryDate  string  `json:"expiry_date"` // ММ/ГГ
+	CVV         string  `json:"cvv"`
+	Amount      float64 `json:"amount"`
+	MerchantID  string  `json:"merchant_id"`
+	Description string  `json:"description"`
+}
+
+// UpdateKYCProfileRequest структура анкеты клиента; даты без времени
+type UpdateKYCProfileRequest struct {
+	LastName             string
+	FirstName            string
+	MiddleName           string
+	BirthDate            time.Time
+	BirthPlace           string
+	PassportSeries       string
+	PassportNumber       string
+	PassportIssuedBy     string
+	PassportIssueDate    time.Time
+	PassportDivisionCode string
+	INN                  string
+	SNILS                string
+	Address              string
+	Phone                string
+}
+
+// KYCLimits лимиты исходящих операций клиента по уровню идентификации; 0 — без ограничения
+type KYCLimits struct {
+	Level           string    `json:"level"`
+	SingleOperation float64   `json:"single_operation"`
+	Monthly         float64   `json:"monthly"`
+	MonthlyUsed     float64   `json:"monthly_used"`
+	PeriodStart     time.Time `json:"period_start"`
 }
 
 // CardData структура расшифрованных данных карты
@@ -91,6 +361,12 @@ type CardData struct {
 	ExpiryDate time.Time `json:"expiry_date"`
 }
 
+// RevealToken одноразовый токен показа реквизитов карты
+type RevealToken struct {
+	Token     string    `json:"token"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
 // MonthlyStats структура месячной статистики
 type MonthlyStats struct {
 	Income   float64 `json:"income"`
@@ -100,14 +376,75 @@ type MonthlyStats struct {
 
 // CreditLoad структура кредитной нагрузки
 type CreditLoad struct {
-	TotalDebt       float64 `json:"total_debt"`
+	TotalDebt       float64 `json:"total_debt"`  // Долг по кредитам и использованный овердрафт
+	CreditDebt      float64 `json:"credit_debt"` // Остаток долга по действующим кредитам
+	OverdraftUsed   float64 `json:"overdraft_used"`
+	OverdraftLimit  float64 `json:"overdraft_limit"`
 	MonthlyPayments float64 `json:"monthly_payments"`
 	CreditRatio     float64 `json:"credit_ratio"`
 }
 
+// CardRewards структура кэшбэка по карте
+type CardRewards struct {
+	CardID       int
+	Period       time.Time // Текущий месяц
+	PeriodAmount float64   // Начислено за текущий месяц
+	MonthlyCap   float64   // 0 — без лимита
+	CapRemaining float64
+	Pending      float64 // Начислено и еще не выплачено
+	Paid         float64
+	Entries      []*domain.CashbackEntry
+	Payouts      []*domain.CashbackPayout
+}
+
+// CategorySpending структура расходов по категории MCC
+type CategorySpending struct {
+	Category string  `json:"category"`
+	Amount   float64 `json:"amount"`
+}
+
 // BalancePrediction структура прогноза баланса
 type BalancePrediction struct {
 	CurrentBalance   float64   `json:"current_balance"`
 	PredictedBalance float64   `json:"predicted_balance"`
 	PredictionDate   time.Time `json:"prediction_date"`
 }
+
+// RenderedEmail структура отрендеренного письма
+type RenderedEmail struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+	Locale  string `json:"locale"`
+	Subject string `json:"subject"`
+	Text    string `json:"text"`
+	HTML    string `json:"html"`
+}
+
+// JobDefinition описание фоновой задачи: имя, расписание (cron или @every) и функция запуска
+type JobDefinition struct {
+	Name     string
+	Schedule string
+	Run      func(ctx context.Context) (*JobResult, error)
+}
+
+// JobResult результат запуска задачи
+type JobResult struct {
+	Processed int `json:"processed"`
+	Failed    int `json:"failed"`
+}
+
+// JobStatus структура состояния задачи с последним запуском
+type JobStatus struct {
+	Job     *domain.Job
+	LastRun *domain.JobRun
+}
+
+// AuditVerifyResult структура результата проверки цепочки аудита
+type AuditVerifyResult struct {
+	Valid       bool   `json:"valid"`
+	Checked     int64  `json:"checked"`
+	HeadSeq     int64  `json:"head_seq,omitempty"`
+	HeadHash    string `json:"head_hash,omitempty"`
+	BrokenAtSeq int64  `json:"broken_at_seq,omitempty"`
+	Error       string `json:"error,omitempty"`
+}
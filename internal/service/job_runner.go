@@ -0,0 +1,502 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/vterdunov/learn-bank-app/internal/config"
+	"github.com/vterdunov/learn-bank-app/internal/domain"
+	"github.com/vterdunov/learn-bank-app/internal/repository"
+	"github.com/vterdunov/learn-bank-app/internal/utils"
+	"github.com/vterdunov/learn-bank-app/pkg/logger"
+)
+
+// JobRunnerLockKey ключ advisory lock лидера фоновых задач
+const JobRunnerLockKey int64 = 0x6c62615f6a6f6273
+
+// JobOverduePayments имя задачи обработки просроченных платежей
+const JobOverduePayments = "overdue_payments"
+
+// JobEndOfDay имя задачи закрытия операционного дня
+const JobEndOfDay = "end_of_day"
+
+// JobStandingOrders имя задачи исполнения постоянных поручений
+const JobStandingOrders = "standing_orders"
+
+// JobExpireCardHolds имя задачи снятия просроченных холдов по картам
+const JobExpireCardHolds = "expire_card_holds"
+
+// JobMerchantSettlement имя задачи дневных расчетов с ТСП
+const JobMerchantSettlement = "merchant_settlement"
+
+// JobCashbackPayout имя задачи ежемесячной выплаты кэшбэка
+const JobCashbackPayout = "cashback_payout"
+
+// JobSanctionsRefresh имя задачи загрузки санкционных списков и повторной проверки клиентов
+const JobSanctionsRefresh = "sanctions_refresh"
+
+const (
+	// defaultJobRunsPageSize размер страницы истории запусков по умолчанию
+	defaultJobRunsPageSize = 20
+	// maxJobRunsPageSize максимальный размер страницы истории запусков
+	maxJobRunsPageSize = 200
+	// jobFinishTimeout время на сохранение результата запуска после отмены контекста
+	jobFinishTimeout = 10 * time.Second
+)
+
+var (
+	ErrJobAlreadyRegistered = errors.New("job already registered")
+	ErrJobRunnerStarted     = errors.New("job runner already started")
+)
+
+// registeredJob задача, зарегистрированная в этой реплике
+type registeredJob struct {
+	definition JobDefinition
+	schedule   utils.CronSchedule
+}
+
+// JobRunnerImpl реализация JobRunner.
+// Все реплики опрашивают реестр задач, но выполняет задачи только лидер,
+// удерживающий advisory lock. Пауза и ручной запуск хранятся в БД,
+// поэтому admin API работает на любой реплике.
+type JobRunnerImpl struct {
+	jobRepo      repository.JobRepository
+	lock         repository.LeaderLock
+	auditService AuditService
+	logger       *slog.Logger
+	instanceID   string
+	tickInterval time.Duration
+	now          func() time.Time
+
+	jobs    map[string]*registeredJob
+	mu      sync.Mutex
+	running map[string]bool
+	leader  atomic.Bool
+	started bool
+
+	stopChan chan struct{}
+	stopOnce sync.Once
+	loop     sync.WaitGroup
+	runs     sync.WaitGroup
+	// baseCtx контекст Start; leaderCtx отменяется при потере лидерства,
+	// чтобы задачи не выполнялись одновременно со следующим лидером
+	baseCtx      context.Context
+	leaderCtx    context.Context
+	cancelLeader context.CancelFunc
+}
+
+// NewJobRunner создает новый экземпляр JobRunner
+func NewJobRunner(
+	cfg *config.Config,
+	jobRepo repository.JobRepository,
+	lock repository.LeaderLock,
+	auditService AuditService,
+	lg *slog.Logger,
+) JobRunner {
+	hostname, err := os.Hostname()
+	if err != nil {
+		hostname = "unknown"
+	}
+
+	return &JobRunnerImpl{
+		jobRepo:      jobRepo,
+		lock:         lock,
+		auditService: auditService,
+		logger:       logger.WithService(lg, "job_runner"),
+		instanceID:   fmt.Sprintf("%s-%d", hostname, os.Getpid()),
+		tickInterval: cfg.Scheduler.TickInterval,
+		now:          time.Now,
+		jobs:         make(map[string]*registeredJob),
+		running:      make(map[string]bool),
+		stopChan:     make(chan struct{}),
+	}
+}
+
+// Register добавляет задачу в реплику. Вызывается до Start.
+func (r *JobRunnerImpl) Register(job JobDefinition) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.started {
+		return ErrJobRunnerStarted
+	}
+	if _, ok := r.jobs[job.Name]; ok {
+		return fmt.Errorf("%w: %s", ErrJobAlreadyRegistered, job.Name)
+	}
+	if job.Name == "" || job.Run == nil {
+		return fmt.Errorf("invalid job definition: %q", job.Name)
+	}
+
+	schedule, err := utils.ParseCronSchedule(job.Schedule)
+	if err != nil {
+		return fmt.Errorf("job %s: %w", job.Name, err)
+	}
+
+	r.jobs[job.Name] = &registeredJob{definition: job, schedule: schedule}
+	return nil
+}
+
+// Start сохраняет задачи в реестр и запускает цикл выборов лидера и запуска задач
+func (r *JobRunnerImpl) Start(ctx context.Context) error {
+	if r.tickInterval <= 0 {
+		return fmt.Errorf("invalid job runner tick interval: %s", r.tickInterval)
+	}
+
+	if err := r.prepare(ctx); err != nil {
+		return err
+	}
+
+	r.logger.Info("Starting job runner",
+		"instance_id", r.instanceID,
+		"interval", r.tickInterval,
+		"jobs", len(r.jobs))
+
+	r.loop.Add(1)
+	go func() {
+		defer r.loop.Done()
+
+		ticker := time.NewTicker(r.tickInterval)
+		defer ticker.Stop()
+
+		for {
+			r.tick(ctx)
+
+			select {
+			case <-ticker.C:
+			case <-r.stopChan:
+				return
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return nil
+}
+
+// prepare сохраняет зарегистрированные задачи в реестр БД
+func (r *JobRunnerImpl) prepare(ctx context.Context) error {
+	r.mu.Lock()
+	if r.started {
+		r.mu.Unlock()
+		return ErrJobRunnerStarted
+	}
+	r.started = true
+	r.mu.Unlock()
+
+	now := r.now()
+	for name, job := range r.jobs {
+		if err := r.jobRepo.Register(ctx, name, job.definition.Schedule, job.schedule.Next(now)); err != nil {
+			return fmt.Errorf("failed to register job %s: %w", name, err)
+		}
+	}
+
+	r.baseCtx = ctx
+	return nil
+}
+
+// Stop останавливает цикл и дожидается завершения выполняющихся задач.
+// Если ctx истекает раньше, задачам отменяется контекст и Stop ждет их выхода.
+func (r *JobRunnerImpl) Stop(ctx context.Context) error {
+	r.stopOnce.Do(func() { close(r.stopChan) })
+	r.loop.Wait()
+
+	done := make(chan struct{})
+	go func() {
+		r.runs.Wait()
+		close(done)
+	}()
+
+	var stopErr error
+	select {
+	case <-done:
+	case <-ctx.Done():
+		r.logger.Warn("Shutdown timeout, cancelling running jobs")
+		stopErr = ctx.Err()
+		r.resign()
+		<-done
+	}
+
+	r.resign()
+
+	if err := r.lock.Release(context.WithoutCancel(ctx)); err != nil {
+		r.logger.Error("Failed to release job leadership", "error", err)
+	}
+	r.leader.Store(false)
+
+	r.logger.Info("Job runner stopped")
+	return stopErr
+}
+
+// IsLeader сообщает, выполняет ли эта реплика задачи
+func (r *JobRunnerImpl) IsLeader() bool {
+	return r.leader.Load()
+}
+
+// tick подтверждает лидерство и запускает задачи, для которых наступило время
+func (r *JobRunnerImpl) tick(ctx context.Context) {
+	leader, err := r.lock.TryAcquire(ctx)
+	if err != nil {
+		r.logger.Error("Failed to check job leadership", "error", err)
+	}
+
+	wasLeader := r.leader.Swap(leader)
+	switch {
+	case leader && !wasLeader:
+		r.logger.Info("Acquired job leadership", "instance_id", r.instanceID)
+		r.interruptStaleRuns(ctx)
+		r.mu.Lock()
+		r.leaderCtx, r.cancelLeader = context.WithCancel(r.baseCtx)
+		r.mu.Unlock()
+	case !leader && wasLeader:
+		r.logger.Warn("Lost job leadership, cancelling running jobs", "instance_id", r.instanceID)
+		r.resign()
+	}
+
+	if leader {
+		r.runDue(ctx)
+	}
+}
+
+// interruptStaleRuns закрывает запуски, оставшиеся от предыдущего лидера
+func (r *JobRunnerImpl) interruptStaleRuns(ctx context.Context) {
+	r.mu.Lock()
+	busy := len(r.running) > 0
+	r.mu.Unlock()
+
+	// Свои запуски еще выполняются (лидерство было потеряно и снова получено)
+	if busy {
+		return
+	}
+
+	count, err := r.jobRepo.InterruptRunning(ctx)
+	if err != nil {
+		r.logger.Error("Failed to interrupt stale job runs", "error", err)
+		return
+	}
+	if count > 0 {
+		r.logger.Warn("Interrupted stale job runs", "count", count)
+	}
+}
+
+// runDue запускает задачи с наступившим временем или ручным запуском
+func (r *JobRunnerImpl) runDue(ctx context.Context) {
+	jobs, err := r.jobRepo.List(ctx)
+	if err != nil {
+		r.logger.Error("Failed to list jobs", "error", err)
+		return
+	}
+
+	now := r.now()
+	for _, job := range jobs {
+		registered, ok := r.jobs[job.Name]
+		if !ok || !job.IsDue(now) || r.isRunning(job.Name) {
+			continue
+		}
+
+		// Ручной запуск задачи на паузе не сдвигает расписание
+		nextRunAt := job.NextRunAt
+		if !job.Paused && !job.NextRunAt.After(now) {
+			nextRunAt = registered.schedule.Next(now)
+		}
+
+		run := &domain.JobRun{
+			JobName:    job.Name,
+			Trigger:    job.TriggerFor(),
+			InstanceID: r.instanceID,
+			Status:     domain.JobRunStatusRunning,
+			StartedAt:  now,
+		}
+		if err := r.jobRepo.CreateRun(ctx, run); err != nil {
+			r.logger.Error("Failed to create job run", "job", job.Name, "error", err)
+			continue
+		}
+		if err := r.jobRepo.MarkStarted(ctx, job.Name, nextRunAt); err != nil {
+			r.logger.Error("Failed to mark job started", "job", job.Name, "error", err)
+			r.finish(run, nil, err)
+			continue
+		}
+
+		r.launch(registered, run)
+	}
+}
+
+// launch выполняет задачу в отдельной горутине
+func (r *JobRunnerImpl) launch(job *registeredJob, run *domain.JobRun) {
+	r.mu.Lock()
+	r.running[run.JobName] = true
+	ctx := r.leaderCtx
+	r.mu.Unlock()
+
+	r.runs.Add(1)
+	go func() {
+		defer r.runs.Done()
+		defer func() {
+			r.mu.Lock()
+			delete(r.running, run.JobName)
+			r.mu.Unlock()
+		}()
+
+		r.logger.Info("Job started", "job", run.JobName, "run_id", run.ID, "trigger", run.Trigger)
+
+		result, err := r.execute(ctx, job)
+		if err != nil && ctx.Err() != nil {
+			run.Status = domain.JobRunStatusInterrupted
+		}
+		r.finish(run, result, err)
+	}()
+}
+
+// execute вызывает функцию задачи, превращая панику в ошибку запуска
+func (r *JobRunnerImpl) execute(ctx context.Context, job *registeredJob) (result *JobResult, err error) {
+	defer func() {
+		if p := recover(); p != nil {
+			err = fmt.Errorf("job panicked: %v", p)
+		}
+	}()
+
+	return job.definition.Run(ctx)
+}
+
+// finish сохраняет результат запуска в историю.
+// Статус interrupted, выставленный вызывающим, сохраняется.
+func (r *JobRunnerImpl) finish(run *domain.JobRun, result *JobResult, err error) {
+	finishedAt := r.now()
+	run.FinishedAt = &finishedAt
+	if result != nil {
+		run.Processed = result.Processed
+		run.Failed = result.Failed
+	}
+	switch {
+	case run.Status == domain.JobRunStatusInterrupted:
+		run.Error = err.Error()
+	case err != nil:
+		run.Status = domain.JobRunStatusFailed
+		run.Error = err.Error()
+	default:
+		run.Status = domain.JobRunStatusSucceeded
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), jobFinishTimeout)
+	defer cancel()
+
+	if err := r.jobRepo.FinishRun(ctx, run); err != nil {
+		r.logger.Error("Failed to save job run result", "job", run.JobName, "run_id", run.ID, "error", err)
+	}
+
+	r.logger.Info("Job finished",
+		"job", run.JobName,
+		"run_id", run.ID,
+		"status", run.Status,
+		"processed", run.Processed,
+		"failed", run.Failed,
+		"duration", finishedAt.Sub(run.StartedAt))
+}
+
+// resign отменяет контекст задач текущего срока лидерства
+func (r *JobRunnerImpl) resign() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.cancelLeader != nil {
+		r.cancelLeader()
+	}
+}
+
+func (r *JobRunnerImpl) isRunning(name string) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.running[name]
+}
+
+// ListJobs возвращает задачи реестра с последним запуском
+func (r *JobRunnerImpl) ListJobs(ctx context.Context) ([]*JobStatus, error) {
+	jobs, err := r.jobRepo.List(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list jobs: %w", err)
+	}
+
+	statuses := make([]*JobStatus, 0, len(jobs))
+	for _, job := range jobs {
+		status := &JobStatus{Job: job}
+
+		runs, err := r.jobRepo.ListRuns(ctx, job.Name, 1, 0)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get last run of %s: %w", job.Name, err)
+		}
+		if len(runs) > 0 {
+			status.LastRun = runs[0]
+		}
+
+		statuses = append(statuses, status)
+	}
+
+	return statuses, nil
+}
+
+// ListRuns возвращает историю запусков задачи
+func (r *JobRunnerImpl) ListRuns(ctx context.Context, name string, limit, offset int) ([]*domain.JobRun, error) {
+	if _, err := r.jobRepo.Get(ctx, name); err != nil {
+		return nil, err
+	}
+
+	if limit <= 0 {
+		limit = defaultJobRunsPageSize
+	}
+	if limit > maxJobRunsPageSize {
+		limit = maxJobRunsPageSize
+	}
+
+	runs, err := r.jobRepo.ListRuns(ctx, name, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list job runs: %w", err)
+	}
+
+	return runs, nil
+}
+
+// Trigger запрашивает внеочередной запуск; задачу выполнит лидер на ближайшем такте
+func (r *JobRunnerImpl) Trigger(ctx context.Context, adminID int, name string) error {
+	if err := r.jobRepo.RequestTrigger(ctx, name); err != nil {
+		return err
+	}
+
+	r.logger.Info("Job trigger requested", "job", name, "admin_id", adminID)
+	r.audit(ctx, adminID, domain.AuditActionAdminJobTrigger, name)
+	return nil
+}
+
+// Pause останавливает запуски задачи по расписанию
+func (r *JobRunnerImpl) Pause(ctx context.Context, adminID int, name string) error {
+	if err := r.jobRepo.SetPaused(ctx, name, true); err != nil {
+		return err
+	}
+
+	r.logger.Info("Job paused", "job", name, "admin_id", adminID)
+	r.audit(ctx, adminID, domain.AuditActionAdminJobPause, name)
+	return nil
+}
+
+// Resume возобновляет запуски задачи по расписанию
+func (r *JobRunnerImpl) Resume(ctx context.Context, adminID int, name string) error {
+	if err := r.jobRepo.SetPaused(ctx, name, false); err != nil {
+		return err
+	}
+
+	r.logger.Info("Job resumed", "job", name, "admin_id", adminID)
+	r.audit(ctx, adminID, domain.AuditActionAdminJobResume, name)
+	return nil
+}
+
+func (r *JobRunnerImpl) audit(ctx context.Context, adminID int, action, name string) {
+	event := NewUserAuditEvent(adminID, action, "job", name)
+	event.ActorType = domain.AuditActorAdmin
+	// Ошибка аудита уже залогирована, действие выполнено
+	_ = r.auditService.Record(ctx, event)
+}
@@ -0,0 +1,361 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/vterdunov/learn-bank-app/internal/config"
+	"github.com/vterdunov/learn-bank-app/internal/domain"
+)
+
+// MockJobRepository для тестирования (хранит реестр и историю в памяти)
+type MockJobRepository struct {
+	mu     sync.Mutex
+	jobs   map[string]*domain.Job
+	runs   []*domain.JobRun
+	nextID int64
+}
+
+func NewMockJobRepository() *MockJobRepository {
+	return &MockJobRepository{
+		jobs:   make(map[string]*domain.Job),
+		nextID: 1,
+	}
+}
+
+func (m *MockJobRepository) Register(ctx context.Context, name, schedule string, nextRunAt time.Time) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if job, ok := m.jobs[name]; ok {
+		if job.Schedule != schedule {
+			job.Schedule = schedule
+			job.NextRunAt = nextRunAt
+		}
+		return nil
+	}
+	m.jobs[name] = &domain.Job{Name: name, Schedule: schedule, NextRunAt: time.Now()}
+	return nil
+}
+
+func (m *MockJobRepository) Get(ctx context.Context, name string) (*domain.Job, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	job, ok := m.jobs[name]
+	if !ok {
+		return nil, domain.ErrJobNotFound
+	}
+	copied := *job
+	return &copied, nil
+}
+
+func (m *MockJobRepository) List(ctx context.Context) ([]*domain.Job, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var jobs []*domain.Job
+	for _, job := range m.jobs {
+		copied := *job
+		jobs = append(jobs, &copied)
+	}
+	return jobs, nil
+}
+
+func (m *MockJobRepository) SetPaused(ctx context.Context, name string, paused bool) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	job, ok := m.jobs[name]
+	if !ok {
+		return domain.ErrJobNotFound
+	}
+	job.Paused = paused
+	return nil
+}
+
+func (m *MockJobRepository) RequestTrigger(ctx context.Context, name string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	job, ok := m.jobs[name]
+	if !ok {
+		return domain.ErrJobNotFound
+	}
+	now := time.Now()
+	job.TriggerRequestedAt = &now
+	return nil
+}
+
+func (m *MockJobRepository) MarkStarted(ctx context.Context, name string, nextRunAt time.Time) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	job, ok := m.jobs[name]
+	if !ok {
+		return domain.ErrJobNotFound
+	}
+	job.NextRunAt = nextRunAt
+	job.TriggerRequestedAt = nil
+	return nil
+}
+
+func (m *MockJobRepository) CreateRun(ctx context.Context, run *domain.JobRun) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	run.ID = m.nextID
+	m.nextID++
+	copied := *run
+	m.runs = append(m.runs, &copied)
+	return nil
+}
+
+func (m *MockJobRepository) FinishRun(ctx context.Context, run *domain.JobRun) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for i, stored := range m.runs {
+		if stored.ID == run.ID {
+			copied := *run
+			m.runs[i] = &copied
+		}
+	}
+	return nil
+}
+
+func (m *MockJobRepository) ListRuns(ctx context.Context, name string, limit, offset int) ([]*domain.JobRun, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var runs []*domain.JobRun
+	for i := len(m.runs) - 1; i >= 0; i-- {
+		if m.runs[i].JobName == name {
+			copied := *m.runs[i]
+			runs = append(runs, &copied)
+		}
+	}
+	if offset >= len(runs) {
+		return nil, nil
+	}
+	runs = runs[offset:]
+	if len(runs) > limit {
+		runs = runs[:limit]
+	}
+	return runs, nil
+}
+
+func (m *MockJobRepository) InterruptRunning(ctx context.Context) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var count int64
+	for _, run := range m.runs {
+		if run.Status == domain.JobRunStatusRunning {
+			run.Status = domain.JobRunStatusInterrupted
+			count++
+		}
+	}
+	return count, nil
+}
+
+// mockLeaderLock блокировка лидера с управляемым результатом
+type mockLeaderLock struct {
+	mu       sync.Mutex
+	held     bool
+	released bool
+}
+
+func (l *mockLeaderLock) TryAcquire(ctx context.Context) (bool, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.held, nil
+}
+
+func (l *mockLeaderLock) Release(ctx context.Context) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.released = true
+	l.held = false
+	return nil
+}
+
+func (l *mockLeaderLock) set(held bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.held = held
+}
+
+// setupJobRunner создает исполнитель с одной задачей; цикл не запускается, такты вызываются тестом
+func setupJobRunner(t *testing.T, run func(ctx context.Context) (*JobResult, error)) (*JobRunnerImpl, *MockJobRepository, *mockLeaderLock) {
+	t.Helper()
+
+	cfg := &config.Config{Scheduler: config.SchedulerConfig{TickInterval: time.Hour}}
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	auditService, _ := setupAuditService()
+	jobRepo := NewMockJobRepository()
+	lock := &mockLeaderLock{}
+
+	runner := NewJobRunner(cfg, jobRepo, lock, auditService, logger).(*JobRunnerImpl)
+	if err := runner.Register(JobDefinition{Name: "test_job", Schedule: "@every 1h", Run: run}); err != nil {
+		t.Fatalf("Register failed: %v", err)
+	}
+	if err := runner.prepare(context.Background()); err != nil {
+		t.Fatalf("prepare failed: %v", err)
+	}
+
+	return runner, jobRepo, lock
+}
+
+// lastRun возвращает последний запуск задачи
+func lastRun(t *testing.T, jobRepo *MockJobRepository) *domain.JobRun {
+	t.Helper()
+
+	runs, err := jobRepo.ListRuns(context.Background(), "test_job", 1, 0)
+	if err != nil || len(runs) == 0 {
+		t.Fatalf("expected job run, got %v (err %v)", runs, err)
+	}
+	return runs[0]
+}
+
+func TestJobRunner_RunsOnlyOnLeader(t *testing.T) {
+	calls := 0
+	runner, jobRepo, lock := setupJobRunner(t, func(ctx context.Context) (*JobResult, error) {
+		calls++
+		return &JobResult{Processed: 3, Failed: 1}, nil
+	})
+	ctx := context.Background()
+
+	runner.tick(ctx)
+	runner.runs.Wait()
+	if calls != 0 || runner.IsLeader() {
+		t.Fatalf("expected no runs without leadership, got %d", calls)
+	}
+
+	lock.set(true)
+	runner.tick(ctx)
+	runner.runs.Wait()
+	if calls != 1 || !runner.IsLeader() {
+		t.Fatalf("expected 1 run on leader, got %d", calls)
+	}
+
+	run := lastRun(t, jobRepo)
+	if run.Status != domain.JobRunStatusSucceeded || run.Trigger != domain.JobTriggerSchedule {
+		t.Errorf("unexpected run: status %s trigger %s", run.Status, run.Trigger)
+	}
+	if run.Processed != 3 || run.Failed != 1 || run.FinishedAt == nil {
+		t.Errorf("unexpected run counts: %+v", run)
+	}
+
+	// Следующий запуск по расписанию через час
+	runner.tick(ctx)
+	runner.runs.Wait()
+	if calls != 1 {
+		t.Fatalf("expected job to wait for schedule, got %d runs", calls)
+	}
+}
+
+func TestJobRunner_PauseAndManualTrigger(t *testing.T) {
+	calls := 0
+	runner, jobRepo, lock := setupJobRunner(t, func(ctx context.Context) (*JobResult, error) {
+		calls++
+		return &JobResult{}, nil
+	})
+	ctx := context.Background()
+	lock.set(true)
+
+	if err := runner.Pause(ctx, 1, "test_job"); err != nil {
+		t.Fatalf("Pause failed: %v", err)
+	}
+	runner.tick(ctx)
+	runner.runs.Wait()
+	if calls != 0 {
+		t.Fatalf("expected paused job not to run, got %d", calls)
+	}
+
+	before, _ := jobRepo.Get(ctx, "test_job")
+	if err := runner.Trigger(ctx, 1, "test_job"); err != nil {
+		t.Fatalf("Trigger failed: %v", err)
+	}
+	runner.tick(ctx)
+	runner.runs.Wait()
+	if calls != 1 {
+		t.Fatalf("expected manual run of paused job, got %d", calls)
+	}
+	if run := lastRun(t, jobRepo); run.Trigger != domain.JobTriggerManual {
+		t.Errorf("expected manual trigger, got %s", run.Trigger)
+	}
+
+	after, _ := jobRepo.Get(ctx, "test_job")
+	if after.TriggerRequestedAt != nil || !after.NextRunAt.Equal(before.NextRunAt) {
+		t.Errorf("manual run must clear trigger and keep schedule: %+v", after)
+	}
+
+	if err := runner.Trigger(ctx, 1, "missing"); !errors.Is(err, domain.ErrJobNotFound) {
+		t.Errorf("expected ErrJobNotFound, got %v", err)
+	}
+}
+
+func TestJobRunner_StopWaitsForRunningJob(t *testing.T) {
+	started := make(chan struct{})
+	release := make(chan struct{})
+	runner, jobRepo, lock := setupJobRunner(t, func(ctx context.Context) (*JobResult, error) {
+		close(started)
+		<-release
+		return &JobResult{Processed: 1}, nil
+	})
+	lock.set(true)
+	runner.tick(context.Background())
+	<-started
+
+	stopped := make(chan error, 1)
+	go func() {
+		stopped <- runner.Stop(context.Background())
+	}()
+
+	select {
+	case <-stopped:
+		t.Fatal("Stop returned before running job finished")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	close(release)
+	if err := <-stopped; err != nil {
+		t.Fatalf("Stop failed: %v", err)
+	}
+
+	if run := lastRun(t, jobRepo); run.Status != domain.JobRunStatusSucceeded {
+		t.Errorf("expected succeeded run, got %s", run.Status)
+	}
+	if !lock.released {
+		t.Error("expected leadership to be released")
+	}
+}
+
+func TestJobRunner_StopTimeoutInterruptsJob(t *testing.T) {
+	started := make(chan struct{})
+	runner, jobRepo, lock := setupJobRunner(t, func(ctx context.Context) (*JobResult, error) {
+		close(started)
+		<-ctx.Done()
+		return nil, ctx.Err()
+	})
+	lock.set(true)
+	runner.tick(context.Background())
+	<-started
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	if err := runner.Stop(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected deadline exceeded, got %v", err)
+	}
+
+	if run := lastRun(t, jobRepo); run.Status != domain.JobRunStatusInterrupted {
+		t.Errorf("expected interrupted run, got %s", run.Status)
+	}
+}
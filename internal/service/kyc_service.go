@@ -0,0 +1,547 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/vterdunov/learn-bank-app/internal/config"
+	"github.com/vterdunov/learn-bank-app/internal/domain"
+	"github.com/vterdunov/learn-bank-app/internal/repository"
+	"github.com/vterdunov/learn-bank-app/internal/utils"
+	"github.com/vterdunov/learn-bank-app/pkg/logger"
+)
+
+const (
+	// defaultKYCPageSize размер страницы очереди анкет по умолчанию
+	defaultKYCPageSize = 50
+	// maxKYCPageSize максимальный размер страницы очереди анкет
+	maxKYCPageSize = 500
+	// kycMinAge возраст, с которого выдается паспорт гражданина РФ
+	kycMinAge = 14
+	// kycMaxAge максимальный правдоподобный возраст клиента
+	kycMaxAge = 120
+)
+
+// kycDocumentExtensions допустимые форматы сканов и расширения файлов в хранилище
+var kycDocumentExtensions = map[string]string{
+	"image/jpeg":      ".jpg",
+	"image/png":       ".png",
+	"application/pdf": ".pdf",
+}
+
+// kycService ведет анкеты клиентов и документы для идентификации.
+// Клиент заполняет анкету, загружает сканы и отправляет анкету на проверку; оператор подтверждает ее
+// с уровнем simplified или full либо отклоняет с причиной. От уровня зависят лимиты исходящих операций.
+// Сканы хранятся в локальном каталоге, в БД — только метаданные и SHA-256 файла.
+type kycService struct {
+	kycRepo         repository.KYCRepository
+	transactionRepo repository.TransactionRepository
+	auditService    AuditService
+	clock           utils.Clock
+	location        *time.Location
+	documentDir     string
+	maxDocumentSize int64
+	limits          map[string]config.KYCLimitConfig
+	logger          *slog.Logger
+}
+
+// NewKYCService создает новый экземпляр KYCService
+func NewKYCService(
+	cfg *config.Config,
+	kycRepo repository.KYCRepository,
+	transactionRepo repository.TransactionRepository,
+	auditService AuditService,
+	clock utils.Clock,
+	lg *slog.Logger,
+) (KYCService, error) {
+	location, err := time.LoadLocation(cfg.EOD.Timezone)
+	if err != nil {
+		return nil, fmt.Errorf("invalid EOD timezone %q: %w", cfg.EOD.Timezone, err)
+	}
+	if cfg.KYC.MaxDocumentSize <= 0 {
+		return nil, fmt.Errorf("invalid KYC document max size %d", cfg.KYC.MaxDocumentSize)
+	}
+	for _, level := range []string{domain.KYCLevelNone, domain.KYCLevelSimplified, domain.KYCLevelFull} {
+		limit, ok := cfg.KYC.Limits[level]
+		if !ok {
+			return nil, fmt.Errorf("missing KYC limits for level %q", level)
+		}
+		if limit.SingleOperation < 0 || limit.Monthly < 0 {
+			return nil, fmt.Errorf("invalid KYC limits for level %q", level)
+		}
+	}
+	if err := os.MkdirAll(cfg.KYC.DocumentDir, 0o700); err != nil {
+		return nil, fmt.Errorf("failed to create KYC document directory: %w", err)
+	}
+
+	return &kycService{
+		kycRepo:         kycRepo,
+		transactionRepo: transactionRepo,
+		auditService:    auditService,
+		clock:           clock,
+		location:        location,
+		documentDir:     cfg.KYC.DocumentDir,
+		maxDocumentSize: cfg.KYC.MaxDocumentSize,
+		limits:          cfg.KYC.Limits,
+		logger:          logger.WithService(lg, "kyc_service"),
+	}, nil
+}
+
+// GetProfile возвращает анкету клиента; если клиент ее не заполнял — пустую неподтвержденную анкету
+func (s *kycService) GetProfile(ctx context.Context, userID int) (*domain.KYCProfile, error) {
+	profile, err := s.kycRepo.GetProfile(ctx, userID)
+	if err != nil {
+		if errors.Is(err, domain.ErrKYCProfileNotFound) {
+			return domain.NewKYCProfile(userID), nil
+		}
+		s.logger.Error("Failed to get KYC profile", "user_id", userID, "error", err)
+		return nil, fmt.Errorf("failed to get KYC profile: %w", err)
+	}
+	return profile, nil
+}
+
+// UpdateProfile сохраняет анкету клиента. Изменение анкеты снимает идентификацию: анкету нужно
+// заново отправить на проверку. Анкету, которую проверяет оператор, изменить нельзя.
+func (s *kycService) UpdateProfile(ctx context.Context, userID int, req UpdateKYCProfileRequest) (*domain.KYCProfile, error) {
+	phone, err := utils.NormalizePhone(req.Phone)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.validateDates(req.BirthDate, req.PassportIssueDate); err != nil {
+		return nil, err
+	}
+
+	current, err := s.GetProfile(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if current.Status == domain.KYCStatusPending {
+		return nil, domain.ErrKYCUnderReview
+	}
+
+	birthDate := kycDate(req.BirthDate)
+	issueDate := kycDate(req.PassportIssueDate)
+	profile := &domain.KYCProfile{
+		UserID:               userID,
+		LastName:             strings.TrimSpace(req.LastName),
+		FirstName:            strings.TrimSpace(req.FirstName),
+		MiddleName:           strings.TrimSpace(req.MiddleName),
+		BirthDate:            &birthDate,
+		BirthPlace:           strings.TrimSpace(req.BirthPlace),
+		PassportSeries:       req.PassportSeries,
+		PassportNumber:       req.PassportNumber,
+		PassportIssuedBy:     strings.TrimSpace(req.PassportIssuedBy),
+		PassportIssueDate:    &issueDate,
+		PassportDivisionCode: req.PassportDivisionCode,
+		INN:                  req.INN,
+		SNILS:                utils.DigitsOnly(req.SNILS),
+		Address:              strings.TrimSpace(req.Address),
+		Phone:                phone,
+		Status:               domain.KYCStatusUnverified,
+		Level:                domain.KYCLevelNone,
+	}
+
+	if err := s.kycRepo.SaveProfile(ctx, profile); err != nil {
+		s.logger.Warn("Failed to save KYC profile", "user_id", userID, "error", err)
+		if errors.Is(err, domain.ErrKYCUnderReview) || errors.Is(err, domain.ErrKYCIdentityInUse) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to save KYC profile: %w", err)
+	}
+	profile.ReviewerID = current.ReviewerID
+	profile.ReviewedAt = current.ReviewedAt
+
+	s.logger.Info("KYC profile updated", "user_id", userID, "previous_status", current.Status)
+
+	event := NewUserAuditEvent(userID, domain.AuditActionKYCProfileUpdate, "kyc_profile", auditResourceID(userID))
+	event.Before = domain.NewAuditState(map[string]interface{}{"status": current.Status, "level": current.Level})
+	event.After = domain.NewAuditState(map[string]interface{}{"status": profile.Status, "level": profile.Level})
+	// Ошибка аудита уже залогирована, анкета сохранена
+	_ = s.auditService.Record(ctx, event)
+
+	return profile, nil
+}
+
+// validateDates проверяет дату рождения и дату выдачи паспорта: паспорт выдается с 14 лет и не позже сегодняшнего дня
+func (s *kycService) validateDates(birthDate, issueDate time.Time) error {
+	today := kycDate(s.clock.Now().In(s.location))
+	birth := kycDate(birthDate)
+	issue := kycDate(issueDate)
+
+	if birth.IsZero() || birth.After(today.AddDate(-kycMinAge, 0, 0)) || birth.Before(today.AddDate(-kycMaxAge, 0, 0)) {
+		return fmt.Errorf("%w: age must be between %d and %d", domain.ErrInvalidKYCDates, kycMinAge, kycMaxAge)
+	}
+	if issue.IsZero() || issue.After(today) || issue.Before(birth.AddDate(kycMinAge, 0, 0)) {
+		return fmt.Errorf("%w: passport must be issued at the age of %d or later and not in the future", domain.ErrInvalidKYCDates, kycMinAge)
+	}
+	return nil
+}
+
+// UploadDocument сохраняет скан документа клиента. Формат определяется по содержимому файла:
+// принимаются JPEG, PNG и PDF не больше KYC_DOCUMENT_MAX_SIZE.
+func (s *kycService) UploadDocument(ctx context.Context, userID int, docType, fileName string, content io.Reader) (*domain.KYCDocument, error) {
+	if !domain.IsValidKYCDocumentType(docType) {
+		return nil, domain.ErrInvalidKYCDocumentType
+	}
+
+	// Читаем на байт больше лимита, чтобы отличить файл ровно лимитного размера от превышающего
+	data, err := io.ReadAll(io.LimitReader(content, s.maxDocumentSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read KYC document: %w", err)
+	}
+	if int64(len(data)) > s.maxDocumentSize {
+		return nil, domain.ErrKYCDocumentTooLarge
+	}
+	if len(data) == 0 {
+		return nil, domain.ErrKYCDocumentUnsupported
+	}
+
+	contentType := http.DetectContentType(data)
+	if i := strings.IndexByte(contentType, ';'); i >= 0 {
+		contentType = contentType[:i]
+	}
+	ext, ok := kycDocumentExtensions[contentType]
+	if !ok {
+		return nil, domain.ErrKYCDocumentUnsupported
+	}
+
+	name, err := randomKYCFileName()
+	if err != nil {
+		return nil, err
+	}
+	// Путь в хранилище не зависит от имени файла у клиента
+	storagePath := filepath.Join(strconv.Itoa(userID), name+ext)
+	if err := s.writeDocumentFile(storagePath, data); err != nil {
+		s.logger.Error("Failed to store KYC document", "user_id", userID, "error", err)
+		return nil, fmt.Errorf("failed to store KYC document: %w", err)
+	}
+
+	sum := sha256.Sum256(data)
+	doc := &domain.KYCDocument{
+		UserID:      userID,
+		Type:        docType,
+		FileName:    sanitizeKYCFileName(fileName),
+		ContentType: contentType,
+		Size:        int64(len(data)),
+		SHA256:      hex.EncodeToString(sum[:]),
+		StoragePath: storagePath,
+	}
+	if err := s.kycRepo.CreateDocument(ctx, doc); err != nil {
+		s.logger.Error("Failed to save KYC document", "user_id", userID, "error", err)
+		// Файл без записи в БД никто не увидит: удаляем его
+		if removeErr := os.Remove(filepath.Join(s.documentDir, storagePath)); removeErr != nil {
+			s.logger.Warn("Failed to remove orphaned KYC document file", "path", storagePath, "error", removeErr)
+		}
+		return nil, fmt.Errorf("failed to save KYC document: %w", err)
+	}
+
+	s.logger.Info("KYC document uploaded", "user_id", userID, "document_id", doc.ID, "type", docType, "size", doc.Size)
+
+	event := NewUserAuditEvent(userID, domain.AuditActionKYCDocumentUpload, "kyc_document", auditResourceID(doc.ID))
+	event.Metadata = domain.NewAuditState(map[string]interface{}{
+		"type":         doc.Type,
+		"content_type": doc.ContentType,
+		"size":         doc.Size,
+		"sha256":       doc.SHA256,
+	})
+	// Ошибка аудита уже залогирована, документ сохранен
+	_ = s.auditService.Record(ctx, event)
+
+	return doc, nil
+}
+
+// writeDocumentFile записывает файл через временный файл, чтобы в каталоге не оставались недописанные сканы
+func (s *kycService) writeDocumentFile(storagePath string, data []byte) error {
+	path := filepath.Join(s.documentDir, storagePath)
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".upload-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := io.Copy(tmp, bytes.NewReader(data)); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), path)
+}
+
+// randomKYCFileName генерирует случайное имя файла в хранилище
+func randomKYCFileName() (string, error) {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("failed to generate file name: %w", err)
+	}
+	return hex.EncodeToString(buf), nil
+}
+
+// sanitizeKYCFileName оставляет от имени файла клиента только базовое имя ограниченной длины
+func sanitizeKYCFileName(fileName string) string {
+	name := filepath.Base(strings.ReplaceAll(fileName, "\\", "/"))
+	if name == "." || name == "/" {
+		name = ""
+	}
+	if runes := []rune(name); len(runes) > 255 {
+		name = string(runes[:255])
+	}
+	return name
+}
+
+// ListDocuments возвращает документы клиента
+func (s *kycService) ListDocuments(ctx context.Context, userID int) ([]*domain.KYCDocument, error) {
+	docs, err := s.kycRepo.ListDocuments(ctx, userID)
+	if err != nil {
+		s.logger.Error("Failed to list KYC documents", "user_id", userID, "error", err)
+		return nil, fmt.Errorf("failed to list KYC documents: %w", err)
+	}
+	return docs, nil
+}
+
+// Submit отправляет заполненную анкету на проверку оператору
+func (s *kycService) Submit(ctx context.Context, userID int) (*domain.KYCProfile, error) {
+	profile, err := s.GetProfile(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	switch profile.Status {
+	case domain.KYCStatusPending:
+		return nil, domain.ErrKYCUnderReview
+	case domain.KYCStatusVerified:
+		return nil, domain.ErrKYCAlreadyVerified
+	}
+	if missing := profile.MissingFields(); len(missing) > 0 {
+		return nil, fmt.Errorf("%w: missing %s", domain.ErrKYCProfileIncomplete, strings.Join(missing, ", "))
+	}
+
+	previousStatus := profile.Status
+	submittedAt := s.clock.Now()
+	profile.Status = domain.KYCStatusPending
+	profile.Level = domain.KYCLevelNone
+	profile.RejectionReason = ""
+	profile.SubmittedAt = &submittedAt
+
+	if err := s.kycRepo.SaveProfile(ctx, profile); err != nil {
+		s.logger.Warn("Failed to submit KYC profile", "user_id", userID, "error", err)
+		if errors.Is(err, domain.ErrKYCUnderReview) || errors.Is(err, domain.ErrKYCIdentityInUse) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to submit KYC profile: %w", err)
+	}
+
+	s.logger.Info("KYC profile submitted", "user_id", userID)
+
+	event := NewUserAuditEvent(userID, domain.AuditActionKYCSubmit, "kyc_profile", auditResourceID(userID))
+	event.Before = domain.NewAuditState(map[string]interface{}{"status": previousStatus})
+	event.After = domain.NewAuditState(map[string]interface{}{"status": profile.Status})
+	// Ошибка аудита уже залогирована, анкета отправлена
+	_ = s.auditService.Record(ctx, event)
+
+	return profile, nil
+}
+
+// GetLimits возвращает лимиты клиента и сумму исходящих операций за текущий месяц
+func (s *kycService) GetLimits(ctx context.Context, userID int) (*KYCLimits, error) {
+	profile, err := s.GetProfile(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	limit := s.limits[profile.Level]
+	periodStart := s.monthStart()
+	used, err := s.transactionRepo.GetExternalOutgoingAmount(ctx, userID, periodStart)
+	if err != nil {
+		s.logger.Error("Failed to get monthly outgoing amount", "user_id", userID, "error", err)
+		return nil, fmt.Errorf("failed to get monthly outgoing amount: %w", err)
+	}
+
+	return &KYCLimits{
+		Level:           profile.Level,
+		SingleOperation: limit.SingleOperation,
+		Monthly:         limit.Monthly,
+		MonthlyUsed:     used,
+		PeriodStart:     periodStart,
+	}, nil
+}
+
+// CheckOperationLimit проверяет исходящую операцию клиента по лимитам его уровня идентификации:
+// сумму операции и сумму исходящих операций за календарный месяц вместе с этой операцией
+func (s *kycService) CheckOperationLimit(ctx context.Context, userID int, amount float64) error {
+	profile, err := s.GetProfile(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	limit := s.limits[profile.Level]
+	if limit.SingleOperation > 0 && amount > limit.SingleOperation {
+		s.logger.Warn("KYC single operation limit exceeded",
+			"user_id", userID, "level", profile.Level, "amount", amount, "limit", limit.SingleOperation)
+		return fmt.Errorf("%w: single operation limit is %.2f", domain.ErrKYCLimitExceeded, limit.SingleOperation)
+	}
+	if limit.Monthly <= 0 {
+		return nil
+	}
+
+	used, err := s.transactionRepo.GetExternalOutgoingAmount(ctx, userID, s.monthStart())
+	if err != nil {
+		s.logger.Error("Failed to get monthly outgoing amount", "user_id", userID, "error", err)
+		return fmt.Errorf("failed to get monthly outgoing amount: %w", err)
+	}
+	if used+amount > limit.Monthly {
+		s.logger.Warn("KYC monthly limit exceeded",
+			"user_id", userID, "level", profile.Level, "amount", amount, "used", used, "limit", limit.Monthly)
+		return fmt.Errorf("%w: monthly limit is %.2f, %.2f already used", domain.ErrKYCLimitExceeded, limit.Monthly, used)
+	}
+
+	return nil
+}
+
+// monthStart возвращает начало текущего календарного месяца по часовому поясу операционного дня
+func (s *kycService) monthStart() time.Time {
+	now := s.clock.Now().In(s.location)
+	return time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, s.location)
+}
+
+// ListProfiles возвращает анкеты по статусу (по умолчанию — ожидающие проверки)
+func (s *kycService) ListProfiles(ctx context.Context, status string, limit, offset int) ([]*domain.KYCProfile, error) {
+	if status == "" {
+		status = domain.KYCStatusPending
+	}
+	if !domain.IsValidKYCStatus(status) {
+		return nil, domain.ErrInvalidKYCStatus
+	}
+	if limit <= 0 {
+		limit = defaultKYCPageSize
+	}
+	if limit > maxKYCPageSize {
+		limit = maxKYCPageSize
+	}
+	if offset < 0 {
+		offset = 0
+	}
+
+	profiles, err := s.kycRepo.ListProfiles(ctx, status, limit, offset)
+	if err != nil {
+		s.logger.Error("Failed to list KYC profiles", "status", status, "error", err)
+		return nil, fmt.Errorf("failed to list KYC profiles: %w", err)
+	}
+	return profiles, nil
+}
+
+// VerifyProfile подтверждает анкету с указанным уровнем. Для полной идентификации нужен скан паспорта.
+func (s *kycService) VerifyProfile(ctx context.Context, adminID, userID int, level string) (*domain.KYCProfile, error) {
+	if !domain.IsVerifiedKYCLevel(level) {
+		return nil, domain.ErrInvalidKYCLevel
+	}
+
+	if level == domain.KYCLevelFull {
+		docs, err := s.kycRepo.ListDocuments(ctx, userID)
+		if err != nil {
+			s.logger.Error("Failed to list KYC documents", "user_id", userID, "error", err)
+			return nil, fmt.Errorf("failed to list KYC documents: %w", err)
+		}
+		hasPassport := false
+		for _, doc := range docs {
+			if doc.Type == domain.KYCDocumentPassport {
+				hasPassport = true
+				break
+			}
+		}
+		if !hasPassport {
+			return nil, domain.ErrKYCPassportDocMissing
+		}
+	}
+
+	return s.review(ctx, adminID, userID, domain.KYCStatusVerified, level, "", domain.AuditActionAdminKYCVerify)
+}
+
+// RejectProfile отклоняет анкету; причину видит клиент
+func (s *kycService) RejectProfile(ctx context.Context, adminID, userID int, reason string) (*domain.KYCProfile, error) {
+	reason = strings.TrimSpace(reason)
+	if reason == "" {
+		return nil, domain.ErrKYCRejectionReasonEmpty
+	}
+
+	return s.review(ctx, adminID, userID, domain.KYCStatusRejected, domain.KYCLevelNone, reason, domain.AuditActionAdminKYCReject)
+}
+
+// review фиксирует решение оператора и записывает его в журнал аудита
+func (s *kycService) review(ctx context.Context, adminID, userID int, status, level, reason, action string) (*domain.KYCProfile, error) {
+	profile, err := s.kycRepo.ReviewProfile(ctx, userID, status, level, adminID, reason)
+	if err != nil {
+		s.logger.Warn("Failed to review KYC profile", "user_id", userID, "admin_id", adminID, "status", status, "error", err)
+		if errors.Is(err, domain.ErrKYCProfileNotFound) || errors.Is(err, domain.ErrKYCNotPending) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to review KYC profile: %w", err)
+	}
+
+	s.logger.Info("KYC profile reviewed", "user_id", userID, "admin_id", adminID, "status", status, "level", level)
+
+	event := NewUserAuditEvent(adminID, action, "kyc_profile", auditResourceID(userID))
+	event.ActorType = domain.AuditActorAdmin
+	event.Before = domain.NewAuditState(map[string]interface{}{"status": domain.KYCStatusPending, "level": domain.KYCLevelNone})
+	event.After = domain.NewAuditState(map[string]interface{}{"status": profile.Status, "level": profile.Level})
+	if reason != "" {
+		event.Metadata = domain.NewAuditState(map[string]interface{}{"reason": reason})
+	}
+	// Ошибка аудита уже залогирована, решение по анкете сохранено
+	_ = s.auditService.Record(ctx, event)
+
+	return profile, nil
+}
+
+// OpenDocument открывает скан документа для оператора; каждый просмотр записывается в журнал аудита
+func (s *kycService) OpenDocument(ctx context.Context, adminID, documentID int) (*domain.KYCDocument, io.ReadCloser, error) {
+	doc, err := s.kycRepo.GetDocument(ctx, documentID)
+	if err != nil {
+		if errors.Is(err, domain.ErrKYCDocumentNotFound) {
+			return nil, nil, err
+		}
+		s.logger.Error("Failed to get KYC document", "document_id", documentID, "error", err)
+		return nil, nil, fmt.Errorf("failed to get KYC document: %w", err)
+	}
+
+	file, err := os.Open(filepath.Join(s.documentDir, doc.StoragePath))
+	if err != nil {
+		s.logger.Error("Failed to open KYC document file", "document_id", documentID, "path", doc.StoragePath, "error", err)
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil, domain.ErrKYCDocumentNotFound
+		}
+		return nil, nil, fmt.Errorf("failed to open KYC document: %w", err)
+	}
+
+	event := NewUserAuditEvent(adminID, domain.AuditActionAdminKYCDocumentView, "kyc_document", auditResourceID(doc.ID))
+	event.ActorType = domain.AuditActorAdmin
+	event.Metadata = domain.NewAuditState(map[string]interface{}{"user_id": doc.UserID, "type": doc.Type})
+	// Ошибка аудита уже залогирована, документ выдается оператору
+	_ = s.auditService.Record(ctx, event)
+
+	return doc, file, nil
+}
+
+// kycDate отбрасывает время, оставляя календарную дату (полночь UTC), как в колонках DATE
+func kycDate(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
@@ -0,0 +1,562 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/vterdunov/learn-bank-app/internal/config"
+	"github.com/vterdunov/learn-bank-app/internal/domain"
+	"github.com/vterdunov/learn-bank-app/internal/utils"
+)
+
+// mockKYCService не ограничивает операции клиентов
+type mockKYCService struct{}
+
+func (mockKYCService) GetProfile(ctx context.Context, userID int) (*domain.KYCProfile, error) {
+	return domain.NewKYCProfile(userID), nil
+}
+
+func (mockKYCService) UpdateProfile(ctx context.Context, userID int, req UpdateKYCProfileRequest) (*domain.KYCProfile, error) {
+	return domain.NewKYCProfile(userID), nil
+}
+
+func (mockKYCService) UploadDocument(ctx context.Context, userID int, docType, fileName string, content io.Reader) (*domain.KYCDocument, error) {
+	return &domain.KYCDocument{UserID: userID, Type: docType, FileName: fileName}, nil
+}
+
+func (mockKYCService) ListDocuments(ctx context.Context, userID int) ([]*domain.KYCDocument, error) {
+	return nil, nil
+}
+
+func (mockKYCService) Submit(ctx context.Context, userID int) (*domain.KYCProfile, error) {
+	return nil, domain.ErrKYCProfileIncomplete
+}
+
+func (mockKYCService) GetLimits(ctx context.Context, userID int) (*KYCLimits, error) {
+	return &KYCLimits{Level: domain.KYCLevelNone}, nil
+}
+
+func (mockKYCService) CheckOperationLimit(ctx context.Context, userID int, amount float64) error {
+	return nil
+}
+
+func (mockKYCService) ListProfiles(ctx context.Context, status string, limit, offset int) ([]*domain.KYCProfile, error) {
+	return nil, nil
+}
+
+func (mockKYCService) VerifyProfile(ctx context.Context, adminID, userID int, level string) (*domain.KYCProfile, error) {
+	return nil, domain.ErrKYCProfileNotFound
+}
+
+func (mockKYCService) RejectProfile(ctx context.Context, adminID, userID int, reason string) (*domain.KYCProfile, error) {
+	return nil, domain.ErrKYCProfileNotFound
+}
+
+func (mockKYCService) OpenDocument(ctx context.Context, adminID, documentID int) (*domain.KYCDocument, io.ReadCloser, error) {
+	return nil, nil, domain.ErrKYCDocumentNotFound
+}
+
+// MockKYCRepository анкеты и документы в памяти
+type MockKYCRepository struct {
+	profiles  map[int]*domain.KYCProfile
+	documents []*domain.KYCDocument
+	// failDocuments имитирует ошибку БД при сохранении документа
+	failDocuments bool
+}
+
+func (m *MockKYCRepository) GetProfile(ctx context.Context, userID int) (*domain.KYCProfile, error) {
+	profile, ok := m.profiles[userID]
+	if !ok {
+		return nil, domain.ErrKYCProfileNotFound
+	}
+	copied := *profile
+	return &copied, nil
+}
+
+func (m *MockKYCRepository) SaveProfile(ctx context.Context, profile *domain.KYCProfile) error {
+	if existing, ok := m.profiles[profile.UserID]; ok && existing.Status == domain.KYCStatusPending {
+		return domain.ErrKYCUnderReview
+	}
+	for userID, other := range m.profiles {
+		if userID != profile.UserID && profile.INN != "" && other.INN == profile.INN {
+			return domain.ErrKYCIdentityInUse
+		}
+	}
+	copied := *profile
+	m.profiles[profile.UserID] = &copied
+	return nil
+}
+
+func (m *MockKYCRepository) ListProfiles(ctx context.Context, status string, limit, offset int) ([]*domain.KYCProfile, error) {
+	var profiles []*domain.KYCProfile
+	for _, profile := range m.profiles {
+		if profile.Status == status {
+			profiles = append(profiles, profile)
+		}
+	}
+	return profiles, nil
+}
+
+func (m *MockKYCRepository) ReviewProfile(ctx context.Context, userID int, status, level string, reviewerID int, reason string) (*domain.KYCProfile, error) {
+	profile, ok := m.profiles[userID]
+	if !ok {
+		return nil, domain.ErrKYCProfileNotFound
+	}
+	if profile.Status != domain.KYCStatusPending {
+		return nil, domain.ErrKYCNotPending
+	}
+	now := time.Now()
+	profile.Status = status
+	profile.Level = level
+	profile.ReviewerID = &reviewerID
+	profile.RejectionReason = reason
+	profile.ReviewedAt = &now
+	copied := *profile
+	return &copied, nil
+}
+
+func (m *MockKYCRepository) CreateDocument(ctx context.Context, doc *domain.KYCDocument) error {
+	if m.failDocuments {
+		return errors.New("database is unavailable")
+	}
+	doc.ID = len(m.documents) + 1
+	doc.CreatedAt = time.Now()
+	m.documents = append(m.documents, doc)
+	return nil
+}
+
+func (m *MockKYCRepository) GetDocument(ctx context.Context, id int) (*domain.KYCDocument, error) {
+	for _, doc := range m.documents {
+		if doc.ID == id {
+			return doc, nil
+		}
+	}
+	return nil, domain.ErrKYCDocumentNotFound
+}
+
+func (m *MockKYCRepository) ListDocuments(ctx context.Context, userID int) ([]*domain.KYCDocument, error) {
+	var docs []*domain.KYCDocument
+	for _, doc := range m.documents {
+		if doc.UserID == userID {
+			docs = append(docs, doc)
+		}
+	}
+	return docs, nil
+}
+
+type kycTestDeps struct {
+	kyc          *MockKYCRepository
+	accounts     *MockAccountStore
+	transactions *MockTransactionRepository
+	audit        *MockAuditRepository
+	clock        *fakeClock
+	documentDir  string
+}
+
+// setupKYCService создает сервис с лимитами по умолчанию. Счета 1 и 2 принадлежат клиенту 1,
+// счет 3 — клиенту 2; банковское время 15 марта 2026 12:00 МСК.
+func setupKYCService(t *testing.T) (*kycService, *kycTestDeps) {
+	t.Helper()
+
+	deps := &kycTestDeps{
+		kyc: &MockKYCRepository{profiles: map[int]*domain.KYCProfile{}},
+		accounts: &MockAccountStore{accounts: map[int]*domain.Account{
+			1: {ID: 1, UserID: 1, Balance: 500000, Status: domain.AccountStatusActive},
+			2: {ID: 2, UserID: 1, Balance: 0, Status: domain.AccountStatusActive},
+			3: {ID: 3, UserID: 2, Balance: 0, Status: domain.AccountStatusActive},
+		}},
+		clock:       &fakeClock{now: time.Date(2026, 3, 15, 9, 0, 0, 0, time.UTC)},
+		documentDir: filepath.Join(t.TempDir(), "kyc"),
+	}
+	deps.transactions = &MockTransactionRepository{accounts: deps.accounts}
+
+	cfg := &config.Config{
+		EOD: config.EODConfig{Timezone: "Europe/Moscow"},
+		KYC: config.KYCConfig{
+			DocumentDir:     deps.documentDir,
+			MaxDocumentSize: 1024,
+			Limits: map[string]config.KYCLimitConfig{
+				domain.KYCLevelNone:       {SingleOperation: 15000, Monthly: 40000},
+				domain.KYCLevelSimplified: {SingleOperation: 60000, Monthly: 200000},
+				domain.KYCLevelFull:       {},
+			},
+		},
+	}
+
+	auditService, auditRepo := setupAuditService()
+	deps.audit = auditRepo
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+
+	svc, err := NewKYCService(cfg, deps.kyc, deps.transactions, auditService, deps.clock, logger)
+	if err != nil {
+		t.Fatalf("NewKYCService() error = %v", err)
+	}
+	return svc.(*kycService), deps
+}
+
+func validKYCProfileRequest() UpdateKYCProfileRequest {
+	return UpdateKYCProfileRequest{
+		LastName:             "Иванов",
+		FirstName:            "Иван",
+		MiddleName:           "Иванович",
+		BirthDate:            time.Date(1990, 5, 20, 0, 0, 0, 0, time.UTC),
+		BirthPlace:           "г. Москва",
+		PassportSeries:       "4510",
+		PassportNumber:       "123456",
+		PassportIssuedBy:     "ОВД района Арбат г. Москвы",
+		PassportIssueDate:    time.Date(2010, 6, 1, 0, 0, 0, 0, time.UTC),
+		PassportDivisionCode: "770-001",
+		INN:                  "500100732259",
+		SNILS:                "11223344595",
+		Address:              "г. Москва, ул. Арбат, д. 1, кв. 1",
+		Phone:                "8 (916) 123-45-67",
+	}
+}
+
+// pngHeader сигнатура PNG: по ней определяется формат файла
+var pngHeader = []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR")
+
+func addKYCTestTransaction(deps *kycTestDeps, from, to int, amount float64, createdAt time.Time) {
+	transaction := &domain.Transaction{FromAccount: &from, Amount: amount, Type: domain.TransactionTypeTransfer, CreatedAt: createdAt}
+	if to != 0 {
+		transaction.ToAccount = &to
+	}
+	deps.transactions.transactions = append(deps.transactions.transactions, transaction)
+}
+
+func TestKYCService_VerificationWorkflow(t *testing.T) {
+	svc, deps := setupKYCService(t)
+	ctx := context.Background()
+
+	profile, err := svc.GetProfile(ctx, 1)
+	if err != nil {
+		t.Fatalf("GetProfile failed: %v", err)
+	}
+	if profile.Status != domain.KYCStatusUnverified || profile.Level != domain.KYCLevelNone {
+		t.Errorf("expected empty unverified profile, got %s/%s", profile.Status, profile.Level)
+	}
+	if _, err := svc.Submit(ctx, 1); !errors.Is(err, domain.ErrKYCProfileIncomplete) {
+		t.Errorf("expected ErrKYCProfileIncomplete for empty profile, got %v", err)
+	}
+
+	profile, err = svc.UpdateProfile(ctx, 1, validKYCProfileRequest())
+	if err != nil {
+		t.Fatalf("UpdateProfile failed: %v", err)
+	}
+	if profile.Phone != "+79161234567" {
+		t.Errorf("phone must be normalized, got %q", profile.Phone)
+	}
+	if profile.FullName() != "Иванов Иван Иванович" {
+		t.Errorf("unexpected full name %q", profile.FullName())
+	}
+
+	profile, err = svc.Submit(ctx, 1)
+	if err != nil {
+		t.Fatalf("Submit failed: %v", err)
+	}
+	if profile.Status != domain.KYCStatusPending || profile.SubmittedAt == nil {
+		t.Errorf("expected pending profile with submitted_at, got %s", profile.Status)
+	}
+
+	// Анкету на проверке нельзя изменить или отправить повторно
+	if _, err := svc.UpdateProfile(ctx, 1, validKYCProfileRequest()); !errors.Is(err, domain.ErrKYCUnderReview) {
+		t.Errorf("expected ErrKYCUnderReview on update, got %v", err)
+	}
+	if _, err := svc.Submit(ctx, 1); !errors.Is(err, domain.ErrKYCUnderReview) {
+		t.Errorf("expected ErrKYCUnderReview on resubmit, got %v", err)
+	}
+
+	// Полная идентификация требует скана паспорта
+	if _, err := svc.VerifyProfile(ctx, 99, 1, domain.KYCLevelFull); !errors.Is(err, domain.ErrKYCPassportDocMissing) {
+		t.Fatalf("expected ErrKYCPassportDocMissing, got %v", err)
+	}
+	if _, err := svc.UploadDocument(ctx, 1, domain.KYCDocumentPassport, "passport.png", bytes.NewReader(pngHeader)); err != nil {
+		t.Fatalf("UploadDocument failed: %v", err)
+	}
+
+	profile, err = svc.VerifyProfile(ctx, 99, 1, domain.KYCLevelFull)
+	if err != nil {
+		t.Fatalf("VerifyProfile failed: %v", err)
+	}
+	if profile.Status != domain.KYCStatusVerified || profile.Level != domain.KYCLevelFull {
+		t.Errorf("expected verified/full, got %s/%s", profile.Status, profile.Level)
+	}
+	if _, err := svc.VerifyProfile(ctx, 99, 1, domain.KYCLevelFull); !errors.Is(err, domain.ErrKYCNotPending) {
+		t.Errorf("expected ErrKYCNotPending on second review, got %v", err)
+	}
+	if _, err := svc.Submit(ctx, 1); !errors.Is(err, domain.ErrKYCAlreadyVerified) {
+		t.Errorf("expected ErrKYCAlreadyVerified, got %v", err)
+	}
+
+	// Изменение подтвержденной анкеты снимает идентификацию
+	profile, err = svc.UpdateProfile(ctx, 1, validKYCProfileRequest())
+	if err != nil {
+		t.Fatalf("UpdateProfile failed: %v", err)
+	}
+	if profile.Status != domain.KYCStatusUnverified || profile.Level != domain.KYCLevelNone {
+		t.Errorf("edited profile must lose verification, got %s/%s", profile.Status, profile.Level)
+	}
+
+	var actions []string
+	for _, event := range deps.audit.events {
+		actions = append(actions, event.Action)
+	}
+	want := []string{
+		domain.AuditActionKYCProfileUpdate,
+		domain.AuditActionKYCSubmit,
+		domain.AuditActionKYCDocumentUpload,
+		domain.AuditActionAdminKYCVerify,
+		domain.AuditActionKYCProfileUpdate,
+	}
+	if strings.Join(actions, ",") != strings.Join(want, ",") {
+		t.Errorf("audit actions = %v, want %v", actions, want)
+	}
+}
+
+func TestKYCService_RejectAndResubmit(t *testing.T) {
+	svc, _ := setupKYCService(t)
+	ctx := context.Background()
+
+	if _, err := svc.UpdateProfile(ctx, 1, validKYCProfileRequest()); err != nil {
+		t.Fatalf("UpdateProfile failed: %v", err)
+	}
+	if _, err := svc.Submit(ctx, 1); err != nil {
+		t.Fatalf("Submit failed: %v", err)
+	}
+
+	if _, err := svc.RejectProfile(ctx, 99, 1, "  "); !errors.Is(err, domain.ErrKYCRejectionReasonEmpty) {
+		t.Errorf("expected ErrKYCRejectionReasonEmpty, got %v", err)
+	}
+	profile, err := svc.RejectProfile(ctx, 99, 1, "Скан паспорта нечитаем")
+	if err != nil {
+		t.Fatalf("RejectProfile failed: %v", err)
+	}
+	if profile.Status != domain.KYCStatusRejected || profile.RejectionReason == "" {
+		t.Errorf("expected rejected profile with reason, got %s %q", profile.Status, profile.RejectionReason)
+	}
+
+	// Отклоненную анкету можно отправить повторно: причина отказа сбрасывается
+	profile, err = svc.Submit(ctx, 1)
+	if err != nil {
+		t.Fatalf("resubmit failed: %v", err)
+	}
+	if profile.Status != domain.KYCStatusPending || profile.RejectionReason != "" {
+		t.Errorf("expected pending profile without reason, got %s %q", profile.Status, profile.RejectionReason)
+	}
+
+	if _, err := svc.VerifyProfile(ctx, 99, 1, domain.KYCLevelNone); !errors.Is(err, domain.ErrInvalidKYCLevel) {
+		t.Errorf("expected ErrInvalidKYCLevel, got %v", err)
+	}
+	profile, err = svc.VerifyProfile(ctx, 99, 1, domain.KYCLevelSimplified)
+	if err != nil {
+		t.Fatalf("VerifyProfile failed: %v", err)
+	}
+	if profile.Level != domain.KYCLevelSimplified {
+		t.Errorf("expected simplified level, got %s", profile.Level)
+	}
+}
+
+func TestKYCService_UpdateProfileValidation(t *testing.T) {
+	svc, _ := setupKYCService(t)
+	ctx := context.Background()
+
+	tests := []struct {
+		name    string
+		modify  func(req *UpdateKYCProfileRequest)
+		wantErr error
+	}{
+		{"younger than 14", func(req *UpdateKYCProfileRequest) {
+			req.BirthDate = time.Date(2012, 3, 16, 0, 0, 0, 0, time.UTC)
+		}, domain.ErrInvalidKYCDates},
+		{"passport issued before 14", func(req *UpdateKYCProfileRequest) {
+			req.PassportIssueDate = time.Date(2004, 5, 19, 0, 0, 0, 0, time.UTC)
+		}, domain.ErrInvalidKYCDates},
+		{"passport issued in the future", func(req *UpdateKYCProfileRequest) {
+			req.PassportIssueDate = time.Date(2026, 3, 16, 0, 0, 0, 0, time.UTC)
+		}, domain.ErrInvalidKYCDates},
+		{"invalid phone", func(req *UpdateKYCProfileRequest) {
+			req.Phone = "+1 212 555 0100"
+		}, utils.ErrInvalidPhone},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := validKYCProfileRequest()
+			tt.modify(&req)
+			_, err := svc.UpdateProfile(ctx, 1, req)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("UpdateProfile() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	// Паспорт, выданный в день 14-летия, допустим
+	req := validKYCProfileRequest()
+	req.PassportIssueDate = time.Date(2004, 5, 20, 0, 0, 0, 0, time.UTC)
+	if _, err := svc.UpdateProfile(ctx, 1, req); err != nil {
+		t.Errorf("passport issued on 14th birthday must be accepted, got %v", err)
+	}
+
+	// ИНН другого клиента занят
+	if _, err := svc.UpdateProfile(ctx, 2, validKYCProfileRequest()); !errors.Is(err, domain.ErrKYCIdentityInUse) {
+		t.Errorf("expected ErrKYCIdentityInUse, got %v", err)
+	}
+}
+
+func TestKYCService_UploadDocument(t *testing.T) {
+	svc, deps := setupKYCService(t)
+	ctx := context.Background()
+
+	content := append(append([]byte{}, pngHeader...), []byte("scan")...)
+	doc, err := svc.UploadDocument(ctx, 1, domain.KYCDocumentPassport, `C:\scans\..\паспорт.png`, bytes.NewReader(content))
+	if err != nil {
+		t.Fatalf("UploadDocument failed: %v", err)
+	}
+
+	sum := sha256.Sum256(content)
+	if doc.ContentType != "image/png" || doc.Size != int64(len(content)) || doc.SHA256 != hex.EncodeToString(sum[:]) {
+		t.Errorf("unexpected document metadata: %+v", doc)
+	}
+	if doc.FileName != "паспорт.png" {
+		t.Errorf("file name must be reduced to base name, got %q", doc.FileName)
+	}
+	if !strings.HasPrefix(doc.StoragePath, "1"+string(filepath.Separator)) || !strings.HasSuffix(doc.StoragePath, ".png") {
+		t.Errorf("unexpected storage path %q", doc.StoragePath)
+	}
+
+	info, err := os.Stat(filepath.Join(deps.documentDir, doc.StoragePath))
+	if err != nil {
+		t.Fatalf("stored file not found: %v", err)
+	}
+	if info.Mode().Perm() != 0o600 {
+		t.Errorf("stored file mode = %v, want 0600", info.Mode().Perm())
+	}
+
+	docMeta, file, err := svc.OpenDocument(ctx, 99, doc.ID)
+	if err != nil {
+		t.Fatalf("OpenDocument failed: %v", err)
+	}
+	stored, _ := io.ReadAll(file)
+	file.Close()
+	if !bytes.Equal(stored, content) || docMeta.ID != doc.ID {
+		t.Error("opened document differs from uploaded")
+	}
+	last := deps.audit.events[len(deps.audit.events)-1]
+	if last.Action != domain.AuditActionAdminKYCDocumentView || last.ActorType != domain.AuditActorAdmin {
+		t.Errorf("document view must be audited, got %s/%s", last.Action, last.ActorType)
+	}
+
+	if _, err := svc.UploadDocument(ctx, 1, "selfie", "a.png", bytes.NewReader(content)); !errors.Is(err, domain.ErrInvalidKYCDocumentType) {
+		t.Errorf("expected ErrInvalidKYCDocumentType, got %v", err)
+	}
+	if _, err := svc.UploadDocument(ctx, 1, domain.KYCDocumentINN, "inn.txt", strings.NewReader("plain text")); !errors.Is(err, domain.ErrKYCDocumentUnsupported) {
+		t.Errorf("expected ErrKYCDocumentUnsupported, got %v", err)
+	}
+	large := append(append([]byte{}, pngHeader...), make([]byte, 1024)...)
+	if _, err := svc.UploadDocument(ctx, 1, domain.KYCDocumentINN, "inn.png", bytes.NewReader(large)); !errors.Is(err, domain.ErrKYCDocumentTooLarge) {
+		t.Errorf("expected ErrKYCDocumentTooLarge, got %v", err)
+	}
+
+	// При ошибке БД файл не остается в хранилище
+	deps.kyc.failDocuments = true
+	if _, err := svc.UploadDocument(ctx, 2, domain.KYCDocumentPassport, "p.png", bytes.NewReader(content)); err == nil {
+		t.Fatal("expected error when document metadata cannot be saved")
+	}
+	entries, _ := os.ReadDir(filepath.Join(deps.documentDir, "2"))
+	if len(entries) != 0 {
+		t.Errorf("orphaned files left in storage: %d", len(entries))
+	}
+}
+
+func TestKYCService_CheckOperationLimit(t *testing.T) {
+	svc, deps := setupKYCService(t)
+	ctx := context.Background()
+
+	if err := svc.CheckOperationLimit(ctx, 1, 15000.01); !errors.Is(err, domain.ErrKYCLimitExceeded) {
+		t.Errorf("expected single operation limit, got %v", err)
+	}
+	if err := svc.CheckOperationLimit(ctx, 1, 15000); err != nil {
+		t.Errorf("operation at the limit must pass, got %v", err)
+	}
+
+	// 1 марта 00:30 МСК — уже текущий месяц, 28 февраля 23:30 МСК — прошлый
+	addKYCTestTransaction(deps, 1, 3, 15000, time.Date(2026, 2, 28, 21, 30, 0, 0, time.UTC))
+	addKYCTestTransaction(deps, 1, 3, 15000, time.Date(2026, 2, 28, 20, 30, 0, 0, time.UTC))
+	addKYCTestTransaction(deps, 1, 0, 15000, time.Date(2026, 3, 10, 10, 0, 0, 0, time.UTC))
+	// Переводы между своими счетами не учитываются
+	addKYCTestTransaction(deps, 1, 2, 100000, time.Date(2026, 3, 11, 10, 0, 0, 0, time.UTC))
+
+	limits, err := svc.GetLimits(ctx, 1)
+	if err != nil {
+		t.Fatalf("GetLimits failed: %v", err)
+	}
+	if limits.MonthlyUsed != 30000 || limits.Monthly != 40000 {
+		t.Errorf("expected 30000 of 40000 used, got %.2f of %.2f", limits.MonthlyUsed, limits.Monthly)
+	}
+	if !limits.PeriodStart.Equal(time.Date(2026, 2, 28, 21, 0, 0, 0, time.UTC)) {
+		t.Errorf("unexpected period start %v", limits.PeriodStart)
+	}
+
+	if err := svc.CheckOperationLimit(ctx, 1, 10000); err != nil {
+		t.Errorf("operation within monthly limit must pass, got %v", err)
+	}
+	if err := svc.CheckOperationLimit(ctx, 1, 10000.01); !errors.Is(err, domain.ErrKYCLimitExceeded) {
+		t.Errorf("expected monthly limit, got %v", err)
+	}
+
+	// Полная идентификация снимает ограничения
+	deps.kyc.profiles[1] = &domain.KYCProfile{UserID: 1, Status: domain.KYCStatusVerified, Level: domain.KYCLevelFull}
+	if err := svc.CheckOperationLimit(ctx, 1, 1000000); err != nil {
+		t.Errorf("full level must be unlimited, got %v", err)
+	}
+}
+
+func TestAccountService_TransferRespectsKYCLimits(t *testing.T) {
+	kyc, deps := setupKYCService(t)
+	auditService, _ := setupAuditService()
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	accountService := NewAccountService(&config.Config{}, deps.accounts, deps.transactions, nil, nil, nil, nil,
+		&mockStoreAccessControl{accounts: deps.accounts}, mockTxManager{}, nil, nil, mockFraudService{}, mockSanctionsService{}, kyc, auditService, logger)
+	ctx := context.Background()
+
+	if err := accountService.TransferMoney(ctx, 1, 1, 3, 20000); !errors.Is(err, domain.ErrKYCLimitExceeded) {
+		t.Fatalf("expected ErrKYCLimitExceeded for transfer to another customer, got %v", err)
+	}
+	// Перевод между своими счетами лимитами не ограничен
+	if err := accountService.TransferMoney(ctx, 1, 1, 2, 20000); err != nil {
+		t.Fatalf("transfer between own accounts failed: %v", err)
+	}
+	if err := accountService.WithdrawMoney(ctx, 1, 1, 20000); !errors.Is(err, domain.ErrKYCLimitExceeded) {
+		t.Errorf("expected ErrKYCLimitExceeded for withdrawal, got %v", err)
+	}
+	if deps.accounts.accounts[3].Balance != 0 {
+		t.Errorf("rejected transfer must not credit recipient, balance %.2f", deps.accounts.accounts[3].Balance)
+	}
+}
+
+func TestNewKYCService_InvalidConfig(t *testing.T) {
+	auditService, _ := setupAuditService()
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+
+	cfg := &config.Config{
+		EOD: config.EODConfig{Timezone: "Europe/Moscow"},
+		KYC: config.KYCConfig{
+			DocumentDir:     t.TempDir(),
+			MaxDocumentSize: 1024,
+			Limits:          map[string]config.KYCLimitConfig{domain.KYCLevelNone: {}},
+		},
+	}
+	if _, err := NewKYCService(cfg, &MockKYCRepository{}, &MockTransactionRepository{}, auditService, &fakeClock{}, logger); err == nil {
+		t.Error("expected error for missing level limits")
+	}
+}
@@ -0,0 +1,220 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/vterdunov/learn-bank-app/internal/config"
+	"github.com/vterdunov/learn-bank-app/internal/domain"
+	"github.com/vterdunov/learn-bank-app/internal/repository"
+	"github.com/vterdunov/learn-bank-app/internal/utils"
+	"github.com/vterdunov/learn-bank-app/pkg/logger"
+)
+
+const (
+	// maxMerchantCodeLength максимальная длина кода ТСП
+	maxMerchantCodeLength = 100
+	// maxMerchantNameLength максимальная длина названия ТСП
+	maxMerchantNameLength = 255
+)
+
+// merchantService регистрирует ТСП и формирует дневные отчеты расчетов с ними.
+// Деньги зачисляются на расчетный счет ТСП в момент списания платежа (cardService),
+// дневной расчет фиксирует итоги операционного дня по каждому ТСП.
+type merchantService struct {
+	merchantRepo repository.MerchantRepository
+	accountRepo  repository.AccountRepository
+	auditService AuditService
+	clock        utils.Clock
+	location     *time.Location
+	logger       *slog.Logger
+}
+
+// NewMerchantService создает новый экземпляр MerchantService
+func NewMerchantService(
+	cfg *config.Config,
+	merchantRepo repository.MerchantRepository,
+	accountRepo repository.AccountRepository,
+	auditService AuditService,
+	clock utils.Clock,
+	lg *slog.Logger,
+) (MerchantService, error) {
+	location, err := time.LoadLocation(cfg.EOD.Timezone)
+	if err != nil {
+		return nil, fmt.Errorf("invalid EOD timezone %q: %w", cfg.EOD.Timezone, err)
+	}
+
+	return &merchantService{
+		merchantRepo: merchantRepo,
+		accountRepo:  accountRepo,
+		auditService: auditService,
+		clock:        clock,
+		location:     location,
+		logger:       logger.WithService(lg, "merchant_service"),
+	}, nil
+}
+
+// CreateMerchant регистрирует ТСП с расчетным счетом в банке
+func (s *merchantService) CreateMerchant(ctx context.Context, adminID int, req domain.CreateMerchantRequest) (*domain.Merchant, error) {
+	merchant := &domain.Merchant{
+		Code:                strings.TrimSpace(req.Code),
+		Name:                strings.TrimSpace(req.Name),
+		MCC:                 req.MCC,
+		SettlementAccountID: req.SettlementAccountID,
+	}
+	if merchant.Code == "" || len(merchant.Code) > maxMerchantCodeLength {
+		return nil, domain.ErrInvalidMerchantCode
+	}
+	if merchant.Name == "" || len(merchant.Name) > maxMerchantNameLength {
+		return nil, domain.ErrInvalidMerchantName
+	}
+	if err := domain.ValidateMCC(merchant.MCC); err != nil {
+		return nil, err
+	}
+
+	account, err := s.accountRepo.GetByID(ctx, merchant.SettlementAccountID)
+	if err != nil {
+		s.logger.Warn("Settlement account not found", "account_id", merchant.SettlementAccountID, "error", err)
+		return nil, ErrAccountNotFound
+	}
+	if account.Status != domain.AccountStatusActive {
+		return nil, ErrAccountBlocked
+	}
+
+	if err := s.merchantRepo.Create(ctx, merchant); err != nil {
+		if !errors.Is(err, domain.ErrMerchantCodeTaken) {
+			s.logger.Error("Failed to create merchant", "code", merchant.Code, "error", err)
+		}
+		return nil, err
+	}
+
+	s.logger.Info("Merchant created",
+		"merchant_id", merchant.ID,
+		"code", merchant.Code,
+		"mcc", merchant.MCC,
+		"settlement_account_id", merchant.SettlementAccountID,
+		"admin_id", adminID)
+
+	event := NewUserAuditEvent(adminID, domain.AuditActionAdminMerchantCreate, "merchant", auditResourceID(merchant.ID))
+	event.ActorType = domain.AuditActorAdmin
+	// Ошибка аудита уже залогирована, ТСП зарегистрировано
+	_ = s.auditService.Record(ctx, event)
+
+	return merchant, nil
+}
+
+// AddTerminal регистрирует терминал ТСП, от которого шлюз принимает реверсы
+func (s *merchantService) AddTerminal(ctx context.Context, adminID, merchantID int, terminalID string) error {
+	terminalID = strings.TrimSpace(terminalID)
+	if err := domain.ValidateTerminalID(terminalID); err != nil {
+		return err
+	}
+	if _, err := s.merchantRepo.GetByID(ctx, merchantID); err != nil {
+		return err
+	}
+
+	if err := s.merchantRepo.AddTerminal(ctx, merchantID, terminalID); err != nil {
+		if !errors.Is(err, domain.ErrTerminalTaken) {
+			s.logger.Error("Failed to add merchant terminal", "merchant_id", merchantID, "terminal_id", terminalID, "error", err)
+		}
+		return err
+	}
+
+	s.logger.Info("Merchant terminal added", "merchant_id", merchantID, "terminal_id", terminalID, "admin_id", adminID)
+
+	event := NewUserAuditEvent(adminID, domain.AuditActionAdminTerminalAdd, "merchant", auditResourceID(merchantID))
+	event.ActorType = domain.AuditActorAdmin
+	event.After = domain.NewAuditState(map[string]interface{}{"terminal_id": terminalID})
+	// Ошибка аудита уже залогирована, терминал зарегистрирован
+	_ = s.auditService.Record(ctx, event)
+
+	return nil
+}
+
+// ListMerchants возвращает все зарегистрированные ТСП
+func (s *merchantService) ListMerchants(ctx context.Context) ([]*domain.Merchant, error) {
+	return s.merchantRepo.List(ctx)
+}
+
+// GetSettlements возвращает дневные расчеты с ТСП за дни периода [from, to]
+func (s *merchantService) GetSettlements(ctx context.Context, merchantID int, from, to time.Time) ([]*domain.MerchantSettlement, error) {
+	if _, err := s.merchantRepo.GetByID(ctx, merchantID); err != nil {
+		return nil, err
+	}
+
+	return s.merchantRepo.ListSettlements(ctx, merchantID, from, to)
+}
+
+// RunSettlement рассчитывает все прошедшие нерассчитанные дни по порядку.
+// При первом запуске рассчитывается только вчерашний день.
+func (s *merchantService) RunSettlement(ctx context.Context) (*JobResult, error) {
+	today := domain.BusinessDate(s.clock.Now(), s.location)
+
+	lastSettled, err := s.merchantRepo.LastSettledDate(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get last settled day: %w", err)
+	}
+
+	date := today.AddDate(0, 0, -1)
+	if lastSettled != nil {
+		date = lastSettled.AddDate(0, 0, 1)
+	}
+
+	result := &JobResult{}
+	for ; date.Before(today); date = date.AddDate(0, 0, 1) {
+		if err := ctx.Err(); err != nil {
+			return result, err
+		}
+
+		if _, err := s.SettleDay(ctx, date); err != nil {
+			result.Failed++
+			return result, err
+		}
+		result.Processed++
+	}
+
+	return result, nil
+}
+
+// SettleDay фиксирует итоги операционного дня по каждому ТСП.
+// Уже рассчитанные по ТСП дни не пересчитываются; возвращаются только новые записи.
+func (s *merchantService) SettleDay(ctx context.Context, date time.Time) ([]*domain.MerchantSettlement, error) {
+	date = time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, time.UTC)
+	if !date.Before(domain.BusinessDate(s.clock.Now(), s.location)) {
+		return nil, ErrBusinessDayNotOver
+	}
+
+	// Границы операционного дня в часовом поясе банка
+	from := time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, s.location)
+	to := from.AddDate(0, 0, 1)
+
+	totals, err := s.merchantRepo.SettlementTotals(ctx, from, to)
+	if err != nil {
+		s.logger.Error("Failed to get merchant settlement totals", "business_date", date.Format("2006-01-02"), "error", err)
+		return nil, fmt.Errorf("failed to get settlement totals: %w", err)
+	}
+
+	var settled []*domain.MerchantSettlement
+	for _, settlement := range totals {
+		settlement.BusinessDate = date
+		settlement.Settle()
+
+		saved, err := s.merchantRepo.SaveSettlement(ctx, settlement)
+		if err != nil {
+			return settled, fmt.Errorf("failed to save settlement for merchant %d: %w", settlement.MerchantID, err)
+		}
+		if saved {
+			settled = append(settled, settlement)
+		}
+	}
+
+	s.logger.Info("Merchant settlement completed",
+		"business_date", date.Format("2006-01-02"),
+		"merchants", len(settled))
+
+	return settled, nil
+}
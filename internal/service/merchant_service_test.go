@@ -0,0 +1,303 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/vterdunov/learn-bank-app/internal/config"
+	"github.com/vterdunov/learn-bank-app/internal/domain"
+)
+
+// MockMerchantRepository ТСП в памяти; итоги дня считаются по транзакциям MockTransactionRepository
+type MockMerchantRepository struct {
+	accounts     *MockAccountStore
+	transactions *MockTransactionRepository
+	merchants    map[int]*domain.Merchant
+	settlements  []*domain.MerchantSettlement
+	// terminals ID ТСП по идентификатору терминала
+	terminals map[string]int
+}
+
+func (m *MockMerchantRepository) Create(ctx context.Context, merchant *domain.Merchant) error {
+	for _, existing := range m.merchants {
+		if existing.Code == merchant.Code {
+			return domain.ErrMerchantCodeTaken
+		}
+	}
+	merchant.ID = len(m.merchants) + 1
+	copied := *merchant
+	m.merchants[merchant.ID] = &copied
+	return nil
+}
+
+func (m *MockMerchantRepository) GetByID(ctx context.Context, id int) (*domain.Merchant, error) {
+	merchant, ok := m.merchants[id]
+	if !ok {
+		return nil, domain.ErrMerchantNotFound
+	}
+	copied := *merchant
+	return &copied, nil
+}
+
+func (m *MockMerchantRepository) GetByCode(ctx context.Context, code string) (*domain.Merchant, error) {
+	for _, merchant := range m.merchants {
+		if merchant.Code == code {
+			copied := *merchant
+			return &copied, nil
+		}
+	}
+	return nil, domain.ErrMerchantNotFound
+}
+
+func (m *MockMerchantRepository) GetByTerminal(ctx context.Context, terminalID string) (*domain.Merchant, error) {
+	merchantID, ok := m.terminals[terminalID]
+	if !ok {
+		return nil, domain.ErrMerchantNotFound
+	}
+	return m.GetByID(ctx, merchantID)
+}
+
+func (m *MockMerchantRepository) AddTerminal(ctx context.Context, merchantID int, terminalID string) error {
+	if _, ok := m.terminals[terminalID]; ok {
+		return domain.ErrTerminalTaken
+	}
+	if m.terminals == nil {
+		m.terminals = make(map[string]int)
+	}
+	m.terminals[terminalID] = merchantID
+	return nil
+}
+
+func (m *MockMerchantRepository) List(ctx context.Context) ([]*domain.Merchant, error) {
+	var merchants []*domain.Merchant
+	for id := 1; id <= len(m.merchants); id++ {
+		merchants = append(merchants, m.merchants[id])
+	}
+	return merchants, nil
+}
+
+func (m *MockMerchantRepository) AdjustSettlementBalance(ctx context.Context, accountID int, delta float64) error {
+	account := m.accounts.accounts[accountID]
+	if account.AvailableBalance()+delta < 0 {
+		return domain.ErrMerchantInsufficientFunds
+	}
+	account.Balance += delta
+	return nil
+}
+
+func (m *MockMerchantRepository) LastSettledDate(ctx context.Context) (*time.Time, error) {
+	var last *time.Time
+	for _, settlement := range m.settlements {
+		if last == nil || settlement.BusinessDate.After(*last) {
+			date := settlement.BusinessDate
+			last = &date
+		}
+	}
+	return last, nil
+}
+
+func (m *MockMerchantRepository) SettlementTotals(ctx context.Context, from, to time.Time) ([]*domain.MerchantSettlement, error) {
+	var totals []*domain.MerchantSettlement
+	for id := 1; id <= len(m.merchants); id++ {
+		settlement := &domain.MerchantSettlement{MerchantID: id}
+		for _, t := range m.transactions.transactions {
+			if t.MerchantID == nil || *t.MerchantID != id || t.Status != domain.TransactionStatusCompleted ||
+				t.UpdatedAt.Before(from) || !t.UpdatedAt.Before(to) {
+				continue
+			}
+			switch t.Type {
+			case domain.TransactionTypePayment:
+				settlement.PaymentsCount++
+				settlement.GrossAmount += t.Amount
+			case domain.TransactionTypeRefund:
+				settlement.RefundAmount += t.Amount
+			case domain.TransactionTypeInterchangeFee:
+				settlement.FeeAmount += t.Amount
+			}
+		}
+		totals = append(totals, settlement)
+	}
+	return totals, nil
+}
+
+func (m *MockMerchantRepository) SaveSettlement(ctx context.Context, settlement *domain.MerchantSettlement) (bool, error) {
+	for _, existing := range m.settlements {
+		if existing.MerchantID == settlement.MerchantID && existing.BusinessDate.Equal(settlement.BusinessDate) {
+			return false, nil
+		}
+	}
+	settlement.ID = int64(len(m.settlements) + 1)
+	m.settlements = append(m.settlements, settlement)
+	return true, nil
+}
+
+func (m *MockMerchantRepository) ListSettlements(ctx context.Context, merchantID int, from, to time.Time) ([]*domain.MerchantSettlement, error) {
+	var settlements []*domain.MerchantSettlement
+	for _, settlement := range m.settlements {
+		if settlement.MerchantID == merchantID && !settlement.BusinessDate.Before(from) && !settlement.BusinessDate.After(to) {
+			settlements = append(settlements, settlement)
+		}
+	}
+	return settlements, nil
+}
+
+// setupMerchantService создает сервис ТСП поверх зависимостей setupCardHoldService (часовой пояс Europe/Moscow)
+func setupMerchantService(t *testing.T) (*merchantService, *cardService, *cardHoldTestDeps) {
+	t.Helper()
+
+	cardSvc, deps := setupCardHoldService(t)
+	cfg := &config.Config{EOD: config.EODConfig{Timezone: "Europe/Moscow"}}
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	auditService, _ := setupAuditService()
+
+	svc, err := NewMerchantService(cfg, deps.merchants, deps.accounts, auditService, deps.clock, logger)
+	if err != nil {
+		t.Fatalf("NewMerchantService failed: %v", err)
+	}
+
+	return svc.(*merchantService), cardSvc, deps
+}
+
+func TestMerchantService_CreateMerchant(t *testing.T) {
+	svc, _, deps := setupMerchantService(t)
+	ctx := context.Background()
+
+	merchant, err := svc.CreateMerchant(ctx, 1, domain.CreateMerchantRequest{
+		Code: " cafe-7 ", Name: "Cafe", MCC: "5812", SettlementAccountID: 2,
+	})
+	if err != nil {
+		t.Fatalf("CreateMerchant failed: %v", err)
+	}
+	if merchant.ID != 2 || merchant.Code != "cafe-7" {
+		t.Errorf("unexpected merchant: %+v", merchant)
+	}
+
+	tests := []struct {
+		name string
+		req  domain.CreateMerchantRequest
+		want error
+	}{
+		{"duplicate code", domain.CreateMerchantRequest{Code: "cafe-7", Name: "Cafe", MCC: "5812", SettlementAccountID: 2}, domain.ErrMerchantCodeTaken},
+		{"empty name", domain.CreateMerchantRequest{Code: "cafe-8", Name: " ", MCC: "5812", SettlementAccountID: 2}, domain.ErrInvalidMerchantName},
+		{"invalid mcc", domain.CreateMerchantRequest{Code: "cafe-8", Name: "Cafe", MCC: "58a2", SettlementAccountID: 2}, domain.ErrInvalidMCC},
+		{"unknown account", domain.CreateMerchantRequest{Code: "cafe-8", Name: "Cafe", MCC: "5812", SettlementAccountID: 99}, ErrAccountNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := svc.CreateMerchant(ctx, 1, tt.req); !errors.Is(err, tt.want) {
+				t.Errorf("expected %v, got %v", tt.want, err)
+			}
+		})
+	}
+
+	if len(deps.merchants.merchants) != 2 {
+		t.Errorf("expected 2 merchants, got %d", len(deps.merchants.merchants))
+	}
+}
+
+func TestMerchantService_AddTerminal(t *testing.T) {
+	svc, _, deps := setupMerchantService(t)
+	ctx := context.Background()
+
+	if err := svc.AddTerminal(ctx, 1, 1, " TERM0001 "); err != nil {
+		t.Fatalf("AddTerminal failed: %v", err)
+	}
+	if deps.merchants.terminals["TERM0001"] != 1 {
+		t.Errorf("expected terminal TERM0001 registered to merchant 1, got %v", deps.merchants.terminals)
+	}
+
+	tests := []struct {
+		name       string
+		merchantID int
+		terminalID string
+		want       error
+	}{
+		{"duplicate terminal", 1, "TERM0001", domain.ErrTerminalTaken},
+		{"empty terminal", 1, " ", domain.ErrInvalidTerminalID},
+		{"terminal too long", 1, "TERM00001", domain.ErrInvalidTerminalID},
+		{"terminal with space", 1, "TERM 01", domain.ErrInvalidTerminalID},
+		{"unknown merchant", 99, "TERM0002", domain.ErrMerchantNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := svc.AddTerminal(ctx, 1, tt.merchantID, tt.terminalID); !errors.Is(err, tt.want) {
+				t.Errorf("expected %v, got %v", tt.want, err)
+			}
+		})
+	}
+}
+
+func TestMerchantService_SettleDay(t *testing.T) {
+	svc, _, deps := setupMerchantService(t)
+	ctx := context.Background()
+
+	merchantID := 1
+	// Операционный день 2026-03-09 по Москве: [2026-03-08 21:00 UTC, 2026-03-09 21:00 UTC)
+	for _, tr := range []*domain.Transaction{
+		{Type: domain.TransactionTypePayment, Amount: 200, UpdatedAt: time.Date(2026, 3, 8, 21, 30, 0, 0, time.UTC)},
+		{Type: domain.TransactionTypeInterchangeFee, Amount: 3, UpdatedAt: time.Date(2026, 3, 8, 21, 30, 0, 0, time.UTC)},
+		{Type: domain.TransactionTypePayment, Amount: 100, UpdatedAt: time.Date(2026, 3, 9, 10, 0, 0, 0, time.UTC)},
+		{Type: domain.TransactionTypeInterchangeFee, Amount: 1.5, UpdatedAt: time.Date(2026, 3, 9, 10, 0, 0, 0, time.UTC)},
+		{Type: domain.TransactionTypeRefund, Amount: 40, UpdatedAt: time.Date(2026, 3, 9, 12, 0, 0, 0, time.UTC)},
+		// Следующий операционный день
+		{Type: domain.TransactionTypePayment, Amount: 500, UpdatedAt: time.Date(2026, 3, 9, 21, 0, 0, 0, time.UTC)},
+		// Не завершен
+		{Type: domain.TransactionTypePayment, Amount: 700, Status: domain.TransactionStatusPending, UpdatedAt: time.Date(2026, 3, 9, 12, 0, 0, 0, time.UTC)},
+	} {
+		tr.MerchantID = &merchantID
+		if tr.Status == "" {
+			tr.Status = domain.TransactionStatusCompleted
+		}
+		_ = deps.transactions.Create(ctx, tr)
+	}
+
+	if _, err := svc.SettleDay(ctx, time.Date(2026, 3, 10, 0, 0, 0, 0, time.UTC)); !errors.Is(err, ErrBusinessDayNotOver) {
+		t.Errorf("expected ErrBusinessDayNotOver for today, got %v", err)
+	}
+
+	result, err := svc.RunSettlement(ctx)
+	if err != nil {
+		t.Fatalf("RunSettlement failed: %v", err)
+	}
+	if result.Processed != 1 {
+		t.Errorf("expected 1 settled day, got %d", result.Processed)
+	}
+
+	if len(deps.merchants.settlements) != 1 {
+		t.Fatalf("expected 1 settlement, got %d", len(deps.merchants.settlements))
+	}
+	settlement := deps.merchants.settlements[0]
+	if !settlement.BusinessDate.Equal(time.Date(2026, 3, 9, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("unexpected business date %v", settlement.BusinessDate)
+	}
+	if settlement.PaymentsCount != 2 || settlement.GrossAmount != 300 || settlement.RefundAmount != 40 ||
+		settlement.FeeAmount != 4.5 || settlement.NetAmount != 255.5 {
+		t.Errorf("unexpected settlement: %+v", settlement)
+	}
+
+	// Повторный запуск в тот же день ничего не пересчитывает
+	result, err = svc.RunSettlement(ctx)
+	if err != nil {
+		t.Fatalf("RunSettlement failed: %v", err)
+	}
+	if result.Processed != 0 || len(deps.merchants.settlements) != 1 {
+		t.Errorf("expected no new settlements, got processed=%d settlements=%d", result.Processed, len(deps.merchants.settlements))
+	}
+
+	// Пропущенные дни рассчитываются по порядку
+	deps.clock.now = deps.clock.now.AddDate(0, 0, 2)
+	result, err = svc.RunSettlement(ctx)
+	if err != nil {
+		t.Fatalf("RunSettlement failed: %v", err)
+	}
+	if result.Processed != 2 {
+		t.Errorf("expected 2 settled days, got %d", result.Processed)
+	}
+	if next := deps.merchants.settlements[1]; next.GrossAmount != 500 || next.NetAmount != 500 {
+		t.Errorf("unexpected next day settlement: %+v", next)
+	}
+}
@@ -0,0 +1,392 @@
+package service
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/vterdunov/learn-bank-app/internal/config"
+	"github.com/vterdunov/learn-bank-app/internal/domain"
+	"github.com/vterdunov/learn-bank-app/internal/repository"
+	"github.com/vterdunov/learn-bank-app/internal/utils"
+	"github.com/vterdunov/learn-bank-app/pkg/logger"
+)
+
+const (
+	// defaultNotificationPageSize размер страницы входящих по умолчанию
+	defaultNotificationPageSize = 20
+	// maxNotificationPageSize максимальный размер страницы входящих
+	maxNotificationPageSize = 100
+	// webhookSecretSize размер секрета подписи webhook в байтах
+	webhookSecretSize = 32
+)
+
+// notificationService реализует интерфейс NotificationService
+type notificationService struct {
+	notificationRepo repository.NotificationRepository
+	outboxRepo       repository.OutboxRepository
+	userRepo         repository.UserRepository
+	emailService     EmailService
+	maxAttempts      int
+	logger           *slog.Logger
+}
+
+// NewNotificationService создает новый экземпляр сервиса уведомлений
+func NewNotificationService(
+	cfg *config.Config,
+	notificationRepo repository.NotificationRepository,
+	outboxRepo repository.OutboxRepository,
+	userRepo repository.UserRepository,
+	emailService EmailService,
+	lg *slog.Logger,
+) NotificationService {
+	maxAttempts := cfg.Outbox.MaxAttempts
+	if maxAttempts < 1 {
+		maxAttempts = 1
+	}
+
+	return &notificationService{
+		notificationRepo: notificationRepo,
+		outboxRepo:       outboxRepo,
+		userRepo:         userRepo,
+		emailService:     emailService,
+		maxAttempts:      maxAttempts,
+		logger:           logger.WithService(lg, "notification_service"),
+	}
+}
+
+// NotifyDeposit уведомляет о пополнении счета
+func (s *notificationService) NotifyDeposit(ctx context.Context, userID, accountID int, amount float64) error {
+	event := domain.NotificationEvent{
+		Type:    domain.NotificationEventDeposit,
+		Title:   "Пополнение счета",
+		Message: fmt.Sprintf("Счет пополнен на %.2f ₽", amount),
+		Data: map[string]interface{}{
+			"account_id": accountID,
+			"amount":     amount,
+		},
+	}
+
+	return s.notify(ctx, userID, event, func(ctx context.Context, user *domain.User) error {
+		return s.emailService.QueuePaymentNotification(ctx, user, amount)
+	})
+}
+
+// NotifyCardPayment уведомляет об оплате картой
+func (s *notificationService) NotifyCardPayment(ctx context.Context, userID, cardID int, amount float64) error {
+	event := domain.NotificationEvent{
+		Type:    domain.NotificationEventCardPayment,
+		Title:   "Оплата картой",
+		Message: fmt.Sprintf("Оплата картой на сумму %.2f ₽", amount),
+		Data: map[string]interface{}{
+			"card_id": cardID,
+			"amount":  amount,
+		},
+	}
+
+	return s.notify(ctx, userID, event, func(ctx context.Context, user *domain.User) error {
+		return s.emailService.QueuePaymentNotification(ctx, user, amount)
+	})
+}
+
+// NotifyOverdue уведомляет о просроченном платеже по кредиту
+func (s *notificationService) NotifyOverdue(ctx context.Context, userID int, payment *domain.PaymentSchedule) error {
+	event := domain.NotificationEvent{
+		Type:  domain.NotificationEventOverdue,
+		Title: "Просроченный платеж по кредиту",
+		Message: fmt.Sprintf("Платеж %.2f ₽ со сроком %s просрочен, начислен штраф",
+			payment.PaymentAmount, payment.DueDate.Format("02.01.2006")),
+		Data: map[string]interface{}{
+			"credit_id":      payment.CreditID,
+			"payment_id":     payment.ID,
+			"payment_amount": payment.PaymentAmount,
+			"penalty_amount": payment.PenaltyAmount,
+			"status":         payment.Status,
+		},
+	}
+
+	return s.notify(ctx, userID, event, func(ctx context.Context, user *domain.User) error {
+		return s.emailService.QueueOverdueNotification(ctx, user, payment)
+	})
+}
+
+// NotifyCreditIssued уведомляет о выдаче кредита
+func (s *notificationService) NotifyCreditIssued(ctx context.Context, userID int, credit *domain.Credit) error {
+	event := domain.NotificationEvent{
+		Type:    domain.NotificationEventCreditIssued,
+		Title:   "Кредит оформлен",
+		Message: fmt.Sprintf("Кредит на %.2f ₽ зачислен на счет, ежемесячный платеж %.2f ₽", credit.Amount, credit.MonthlyPayment),
+		Data: map[string]interface{}{
+			"credit_id":       credit.ID,
+			"account_id":      credit.AccountID,
+			"amount":          credit.Amount,
+			"monthly_payment": credit.MonthlyPayment,
+			"term_months":     credit.TermMonths,
+		},
+	}
+
+	return s.notify(ctx, userID, event, func(ctx context.Context, user *domain.User) error {
+		return s.emailService.QueueCreditNotification(ctx, user, credit)
+	})
+}
+
+// NotifyStandingOrderFailed уведомляет о неисполненном платеже по постоянному поручению
+func (s *notificationService) NotifyStandingOrderFailed(ctx context.Context, userID int, order *domain.StandingOrder, execution *domain.StandingOrderExecution) error {
+	event := domain.NotificationEvent{
+		Type:  domain.NotificationEventStandingOrderFailed,
+		Title: "Платеж по поручению не исполнен",
+		Message: fmt.Sprintf("Перевод %.2f ₽ по поручению №%d за %s не выполнен: %s",
+			execution.Amount, order.ID, execution.ScheduledDate.Format("02.01.2006"), execution.Error),
+		Data: map[string]interface{}{
+			"standing_order_id": order.ID,
+			"from_account_id":   order.FromAccountID,
+			"to_account_number": order.ToMaskedAccountNumber,
+			"amount":            execution.Amount,
+			"scheduled_date":    execution.ScheduledDate.Format("2006-01-02"),
+			"attempts":          execution.Attempt,
+			"error":             execution.Error,
+		},
+	}
+
+	return s.notify(ctx, userID, event, func(ctx context.Context, user *domain.User) error {
+		return s.emailService.QueueStandingOrderFailedNotification(ctx, user, order, execution)
+	})
+}
+
+// notify доставляет событие во все включенные каналы пользователя.
+// Все записи делаются с переданным контекстом, поэтому попадают в транзакцию бизнес-операции.
+func (s *notificationService) notify(
+	ctx context.Context,
+	userID int,
+	event domain.NotificationEvent,
+	queueEmail func(ctx context.Context, user *domain.User) error,
+) error {
+	preferences, err := s.notificationRepo.GetPreferences(ctx, userID)
+	if err != nil {
+		return fmt.Errorf("failed to get notification preferences: %w", err)
+	}
+
+	for _, channel := range domain.EnabledChannels(preferences, event.Type) {
+		var err error
+		switch channel {
+		case domain.NotificationChannelInbox:
+			err = s.notificationRepo.Create(ctx, &domain.Notification{
+				UserID:    userID,
+				EventType: event.Type,
+				Title:     event.Title,
+				Message:   event.Message,
+			})
+		case domain.NotificationChannelEmail:
+			var user *domain.User
+			user, err = s.userRepo.GetByID(ctx, userID)
+			if err == nil {
+				err = queueEmail(ctx, user)
+			}
+		case domain.NotificationChannelWebhook:
+			err = s.queueWebhook(ctx, userID, event)
+		case domain.NotificationChannelSMS:
+			err = s.queueSMS(ctx, userID, event)
+		}
+
+		if err != nil {
+			return fmt.Errorf("failed to notify via %s: %w", channel, err)
+		}
+	}
+
+	return nil
+}
+
+// queueWebhook ставит payload события в outbox для доставки на webhook пользователя
+func (s *notificationService) queueWebhook(ctx context.Context, userID int, event domain.NotificationEvent) error {
+	endpoint, err := s.notificationRepo.GetEndpoint(ctx, userID, domain.NotificationChannelWebhook)
+	if err != nil {
+		if errors.Is(err, domain.ErrNotificationEndpointAbsent) {
+			// Канал включен, но webhook не настроен - пропускаем
+			s.logger.Debug("Webhook enabled but not configured", "user_id", userID, "event_type", event.Type)
+			return nil
+		}
+		return err
+	}
+
+	body, err := json.Marshal(domain.WebhookPayload{
+		Event:     event.Type,
+		Title:     event.Title,
+		Message:   event.Message,
+		Data:      event.Data,
+		CreatedAt: time.Now().UTC(),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to marshal webhook payload: %w", err)
+	}
+
+	return s.enqueue(ctx, userID, domain.OutboxChannelWebhook, event.Type, endpoint.Address, string(body))
+}
+
+// queueSMS ставит текст события в outbox для отправки на телефон пользователя
+func (s *notificationService) queueSMS(ctx context.Context, userID int, event domain.NotificationEvent) error {
+	endpoint, err := s.notificationRepo.GetEndpoint(ctx, userID, domain.NotificationChannelSMS)
+	if err != nil {
+		if errors.Is(err, domain.ErrNotificationEndpointAbsent) {
+			// Канал включен, но телефон не указан - пропускаем
+			s.logger.Debug("SMS enabled but phone not configured", "user_id", userID, "event_type", event.Type)
+			return nil
+		}
+		return err
+	}
+
+	return s.enqueue(ctx, userID, domain.OutboxChannelSMS, event.Type, endpoint.Address, event.Message)
+}
+
+func (s *notificationService) enqueue(ctx context.Context, userID int, channel, eventType, recipient, body string) error {
+	message := &domain.OutboxMessage{
+		UserID:      &userID,
+		Channel:     channel,
+		EventType:   eventType,
+		Recipient:   recipient,
+		Body:        body,
+		MaxAttempts: s.maxAttempts,
+	}
+
+	if err := message.Validate(); err != nil {
+		return err
+	}
+
+	return s.outboxRepo.Enqueue(ctx, message)
+}
+
+// ListNotifications возвращает входящие уведомления пользователя
+func (s *notificationService) ListNotifications(ctx context.Context, userID int, unreadOnly bool, limit, offset int) ([]*domain.Notification, error) {
+	if limit <= 0 {
+		limit = defaultNotificationPageSize
+	}
+	if limit > maxNotificationPageSize {
+		limit = maxNotificationPageSize
+	}
+	if offset < 0 {
+		offset = 0
+	}
+
+	notifications, err := s.notificationRepo.ListByUser(ctx, userID, unreadOnly, limit, offset)
+	if err != nil {
+		logger.LogError(s.logger, err, "Failed to list notifications", "user_id", userID)
+		return nil, fmt.Errorf("failed to list notifications: %w", err)
+	}
+
+	return notifications, nil
+}
+
+// MarkRead отмечает уведомление прочитанным
+func (s *notificationService) MarkRead(ctx context.Context, userID int, id int64) error {
+	return s.notificationRepo.MarkRead(ctx, userID, id)
+}
+
+// MarkAllRead отмечает прочитанными все уведомления пользователя
+func (s *notificationService) MarkAllRead(ctx context.Context, userID int) (int64, error) {
+	return s.notificationRepo.MarkAllRead(ctx, userID)
+}
+
+// GetPreferences возвращает настройки всех каналов по всем типам событий
+func (s *notificationService) GetPreferences(ctx context.Context, userID int) ([]*domain.NotificationPreference, error) {
+	saved, err := s.notificationRepo.GetPreferences(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get notification preferences: %w", err)
+	}
+
+	return domain.ResolvePreferences(userID, saved), nil
+}
+
+// UpdatePreferences сохраняет настройки каналов
+func (s *notificationService) UpdatePreferences(ctx context.Context, userID int, preferences []*domain.NotificationPreference) error {
+	for _, p := range preferences {
+		p.UserID = userID
+		if err := p.Validate(); err != nil {
+			return err
+		}
+	}
+
+	if err := s.notificationRepo.UpsertPreferences(ctx, preferences); err != nil {
+		logger.LogError(s.logger, err, "Failed to update notification preferences", "user_id", userID)
+		return fmt.Errorf("failed to update notification preferences: %w", err)
+	}
+
+	logger.LogUserAction(s.logger, userID, "notification_preferences_updated", map[string]interface{}{
+		"count": len(preferences),
+	})
+
+	return nil
+}
+
+// ListEndpoints возвращает адреса доставки внешних каналов
+func (s *notificationService) ListEndpoints(ctx context.Context, userID int) ([]*domain.NotificationEndpoint, error) {
+	return s.notificationRepo.ListEndpoints(ctx, userID)
+}
+
+// SetEndpoint задает адрес доставки канала. Для webhook каждый раз генерируется новый секрет подписи,
+// который возвращается в ответе только один раз.
+func (s *notificationService) SetEndpoint(ctx context.Context, userID int, channel, address string) (*domain.NotificationEndpoint, error) {
+	endpoint := &domain.NotificationEndpoint{
+		UserID:  userID,
+		Channel: channel,
+		Address: address,
+	}
+
+	if err := endpoint.Validate(); err != nil {
+		return nil, err
+	}
+
+	if channel == domain.NotificationChannelWebhook {
+		secret, err := utils.GenerateRandomKey(webhookSecretSize)
+		if err != nil {
+			return nil, fmt.Errorf("failed to generate webhook secret: %w", err)
+		}
+		endpoint.Secret = hex.EncodeToString(secret)
+	}
+
+	if err := s.notificationRepo.SaveEndpoint(ctx, endpoint); err != nil {
+		logger.LogError(s.logger, err, "Failed to save notification endpoint", "user_id", userID, "channel", channel)
+		return nil, fmt.Errorf("failed to save notification endpoint: %w", err)
+	}
+
+	logger.LogUserAction(s.logger, userID, "notification_endpoint_set", map[string]interface{}{
+		"channel": channel,
+	})
+
+	return endpoint, nil
+}
+
+// DeleteEndpoint удаляет адрес доставки канала
+func (s *notificationService) DeleteEndpoint(ctx context.Context, userID int, channel string) error {
+	if !domain.IsEndpointChannel(channel) {
+		return domain.ErrInvalidNotificationChannel
+	}
+
+	return s.notificationRepo.DeleteEndpoint(ctx, userID, channel)
+}
+
+// SetLocale задает язык писем пользователя
+func (s *notificationService) SetLocale(ctx context.Context, userID int, locale string) error {
+	if !domain.IsSupportedLocale(locale) {
+		return domain.ErrUnsupportedLocale
+	}
+
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return fmt.Errorf("failed to get user: %w", err)
+	}
+
+	user.Locale = locale
+	if err := s.userRepo.Update(ctx, user); err != nil {
+		logger.LogError(s.logger, err, "Failed to update user locale", "user_id", userID)
+		return fmt.Errorf("failed to update user locale: %w", err)
+	}
+
+	logger.LogUserAction(s.logger, userID, "notification_locale_set", map[string]interface{}{
+		"locale": locale,
+	})
+
+	return nil
+}
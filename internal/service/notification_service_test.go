@@ -0,0 +1,358 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/vterdunov/learn-bank-app/internal/config"
+	"github.com/vterdunov/learn-bank-app/internal/domain"
+)
+
+// MockNotificationRepository для тестирования (хранит данные в памяти)
+type MockNotificationRepository struct {
+	notifications []*domain.Notification
+	preferences   map[string]*domain.NotificationPreference
+	endpoints     map[string]*domain.NotificationEndpoint
+}
+
+func NewMockNotificationRepository() *MockNotificationRepository {
+	return &MockNotificationRepository{
+		preferences: make(map[string]*domain.NotificationPreference),
+		endpoints:   make(map[string]*domain.NotificationEndpoint),
+	}
+}
+
+func (m *MockNotificationRepository) Create(ctx context.Context, notification *domain.Notification) error {
+	notification.ID = int64(len(m.notifications) + 1)
+	notification.CreatedAt = time.Now()
+	m.notifications = append(m.notifications, notification)
+	return nil
+}
+
+func (m *MockNotificationRepository) ListByUser(ctx context.Context, userID int, unreadOnly bool, limit, offset int) ([]*domain.Notification, error) {
+	var result []*domain.Notification
+	for _, n := range m.notifications {
+		if n.UserID == userID && (!unreadOnly || n.ReadAt == nil) {
+			result = append(result, n)
+		}
+	}
+	return result, nil
+}
+
+func (m *MockNotificationRepository) MarkRead(ctx context.Context, userID int, id int64) error {
+	for _, n := range m.notifications {
+		if n.ID == id && n.UserID == userID {
+			now := time.Now()
+			n.ReadAt = &now
+			return nil
+		}
+	}
+	return domain.ErrNotificationNotFound
+}
+
+func (m *MockNotificationRepository) MarkAllRead(ctx context.Context, userID int) (int64, error) {
+	var count int64
+	for _, n := range m.notifications {
+		if n.UserID == userID && n.ReadAt == nil {
+			now := time.Now()
+			n.ReadAt = &now
+			count++
+		}
+	}
+	return count, nil
+}
+
+func (m *MockNotificationRepository) GetPreferences(ctx context.Context, userID int) ([]*domain.NotificationPreference, error) {
+	var result []*domain.NotificationPreference
+	for _, p := range m.preferences {
+		if p.UserID == userID {
+			result = append(result, p)
+		}
+	}
+	return result, nil
+}
+
+func (m *MockNotificationRepository) UpsertPreferences(ctx context.Context, preferences []*domain.NotificationPreference) error {
+	for _, p := range preferences {
+		saved := *p
+		m.preferences[p.EventType+"/"+p.Channel] = &saved
+	}
+	return nil
+}
+
+func (m *MockNotificationRepository) GetEndpoint(ctx context.Context, userID int, channel string) (*domain.NotificationEndpoint, error) {
+	endpoint, ok := m.endpoints[channel]
+	if !ok || endpoint.UserID != userID {
+		return nil, domain.ErrNotificationEndpointAbsent
+	}
+	return endpoint, nil
+}
+
+func (m *MockNotificationRepository) ListEndpoints(ctx context.Context, userID int) ([]*domain.NotificationEndpoint, error) {
+	var result []*domain.NotificationEndpoint
+	for _, endpoint := range m.endpoints {
+		if endpoint.UserID == userID {
+			result = append(result, endpoint)
+		}
+	}
+	return result, nil
+}
+
+func (m *MockNotificationRepository) SaveEndpoint(ctx context.Context, endpoint *domain.NotificationEndpoint) error {
+	saved := *endpoint
+	m.endpoints[endpoint.Channel] = &saved
+	return nil
+}
+
+func (m *MockNotificationRepository) DeleteEndpoint(ctx context.Context, userID int, channel string) error {
+	if _, ok := m.endpoints[channel]; !ok {
+		return domain.ErrNotificationEndpointAbsent
+	}
+	delete(m.endpoints, channel)
+	return nil
+}
+
+// MockEmailService запоминает поставленные в очередь письма
+type MockEmailService struct {
+	queued []string
+}
+
+func (m *MockEmailService) QueuePaymentNotification(ctx context.Context, user *domain.User, amount float64) error {
+	m.queued = append(m.queued, "payment:"+user.Email)
+	return nil
+}
+
+func (m *MockEmailService) QueueCreditNotification(ctx context.Context, user *domain.User, credit *domain.Credit) error {
+	m.queued = append(m.queued, "credit:"+user.Email)
+	return nil
+}
+
+func (m *MockEmailService) QueueOverdueNotification(ctx context.Context, user *domain.User, payment *domain.PaymentSchedule) error {
+	m.queued = append(m.queued, "overdue:"+user.Email)
+	return nil
+}
+
+func (m *MockEmailService) QueueStandingOrderFailedNotification(ctx context.Context, user *domain.User, order *domain.StandingOrder, execution *domain.StandingOrderExecution) error {
+	m.queued = append(m.queued, "standing_order:"+user.Email)
+	return nil
+}
+
+func (m *MockEmailService) PreviewTemplate(name, locale, version string) (*RenderedEmail, error) {
+	return nil, ErrEmailTemplateNotFound
+}
+
+type notificationTestDeps struct {
+	notificationRepo *MockNotificationRepository
+	outboxRepo       *MockOutboxRepository
+	emailService     *MockEmailService
+	userID           int
+}
+
+func setupNotificationService(t *testing.T) (*notificationService, *notificationTestDeps) {
+	t.Helper()
+
+	userRepo := NewMockUserRepository()
+	user := &domain.User{Username: "notify", Email: "notify@example.com"}
+	if err := userRepo.Create(context.Background(), user); err != nil {
+		t.Fatalf("failed to create user: %v", err)
+	}
+
+	deps := &notificationTestDeps{
+		notificationRepo: NewMockNotificationRepository(),
+		outboxRepo:       NewMockOutboxRepository(),
+		emailService:     &MockEmailService{},
+		userID:           user.ID,
+	}
+
+	cfg := &config.Config{Outbox: config.OutboxConfig{MaxAttempts: 3}}
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	service := NewNotificationService(cfg, deps.notificationRepo, deps.outboxRepo, userRepo, deps.emailService, logger).(*notificationService)
+
+	return service, deps
+}
+
+func TestNotificationService_DefaultChannels(t *testing.T) {
+	service, deps := setupNotificationService(t)
+
+	if err := service.NotifyDeposit(context.Background(), deps.userID, 10, 1500); err != nil {
+		t.Fatalf("NotifyDeposit failed: %v", err)
+	}
+
+	if len(deps.notificationRepo.notifications) != 1 {
+		t.Fatalf("expected 1 inbox notification, got %d", len(deps.notificationRepo.notifications))
+	}
+	if deps.notificationRepo.notifications[0].EventType != domain.NotificationEventDeposit {
+		t.Errorf("unexpected event type: %s", deps.notificationRepo.notifications[0].EventType)
+	}
+
+	if len(deps.emailService.queued) != 1 || deps.emailService.queued[0] != "payment:notify@example.com" {
+		t.Errorf("expected payment email to be queued, got %v", deps.emailService.queued)
+	}
+
+	if len(deps.outboxRepo.messages) != 0 {
+		t.Errorf("expected no webhook/sms messages by default, got %d", len(deps.outboxRepo.messages))
+	}
+}
+
+func TestNotificationService_Preferences(t *testing.T) {
+	service, deps := setupNotificationService(t)
+	ctx := context.Background()
+
+	err := service.UpdatePreferences(ctx, deps.userID, []*domain.NotificationPreference{
+		{EventType: domain.NotificationEventCreditIssued, Channel: domain.NotificationChannelEmail, Enabled: false},
+		{EventType: domain.NotificationEventCreditIssued, Channel: domain.NotificationChannelWebhook, Enabled: true},
+		{EventType: domain.NotificationEventCreditIssued, Channel: domain.NotificationChannelSMS, Enabled: true},
+	})
+	if err != nil {
+		t.Fatalf("UpdatePreferences failed: %v", err)
+	}
+
+	if _, err := service.SetEndpoint(ctx, deps.userID, domain.NotificationChannelWebhook, "https://hooks.example.com/bank"); err != nil {
+		t.Fatalf("SetEndpoint failed: %v", err)
+	}
+
+	credit := &domain.Credit{ID: 7, AccountID: 10, Amount: 100000, MonthlyPayment: 9000, TermMonths: 12}
+	if err := service.NotifyCreditIssued(ctx, deps.userID, credit); err != nil {
+		t.Fatalf("NotifyCreditIssued failed: %v", err)
+	}
+
+	t.Run("disabled email is skipped", func(t *testing.T) {
+		if len(deps.emailService.queued) != 0 {
+			t.Errorf("expected no emails, got %v", deps.emailService.queued)
+		}
+	})
+
+	t.Run("webhook payload is queued, sms without phone is skipped", func(t *testing.T) {
+		if len(deps.outboxRepo.messages) != 1 {
+			t.Fatalf("expected 1 outbox message, got %d", len(deps.outboxRepo.messages))
+		}
+
+		message := deps.outboxRepo.messages[1]
+		if message.Channel != domain.OutboxChannelWebhook {
+			t.Errorf("expected webhook channel, got %s", message.Channel)
+		}
+		if message.UserID == nil || *message.UserID != deps.userID {
+			t.Error("expected webhook message to reference the user")
+		}
+
+		var payload domain.WebhookPayload
+		if err := json.Unmarshal([]byte(message.Body), &payload); err != nil {
+			t.Fatalf("invalid webhook payload: %v", err)
+		}
+		if payload.Event != domain.NotificationEventCreditIssued {
+			t.Errorf("unexpected payload event: %s", payload.Event)
+		}
+	})
+
+	t.Run("inbox stays enabled by default", func(t *testing.T) {
+		if len(deps.notificationRepo.notifications) != 1 {
+			t.Errorf("expected 1 inbox notification, got %d", len(deps.notificationRepo.notifications))
+		}
+	})
+
+	t.Run("invalid channel is rejected", func(t *testing.T) {
+		err := service.UpdatePreferences(ctx, deps.userID, []*domain.NotificationPreference{
+			{EventType: domain.NotificationEventDeposit, Channel: "pigeon", Enabled: true},
+		})
+		if err != domain.ErrInvalidNotificationChannel {
+			t.Errorf("expected ErrInvalidNotificationChannel, got %v", err)
+		}
+	})
+}
+
+func TestNotificationService_SetEndpointValidation(t *testing.T) {
+	service, deps := setupNotificationService(t)
+	ctx := context.Background()
+
+	tests := []struct {
+		name     string
+		channel  string
+		address  string
+		expected error
+	}{
+		{"valid phone", domain.NotificationChannelSMS, "+79991234567", nil},
+		{"phone without plus", domain.NotificationChannelSMS, "89991234567", domain.ErrInvalidPhoneNumber},
+		{"relative webhook URL", domain.NotificationChannelWebhook, "/hooks", domain.ErrInvalidWebhookURL},
+		{"inbox has no endpoint", domain.NotificationChannelInbox, "anything", domain.ErrInvalidNotificationChannel},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := service.SetEndpoint(ctx, deps.userID, tt.channel, tt.address)
+			if err != tt.expected {
+				t.Errorf("expected %v, got %v", tt.expected, err)
+			}
+		})
+	}
+}
+
+func TestWebhookSender_SignsPayload(t *testing.T) {
+	notificationRepo := NewMockNotificationRepository()
+	userID := 5
+
+	var received *http.Request
+	var receivedBody string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		received = r
+		receivedBody = string(body)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	_ = notificationRepo.SaveEndpoint(context.Background(), &domain.NotificationEndpoint{
+		UserID:  userID,
+		Channel: domain.NotificationChannelWebhook,
+		Address: server.URL,
+		Secret:  "test-secret",
+	})
+
+	cfg := &config.Config{Notify: config.NotificationConfig{WebhookTimeout: 5 * time.Second}}
+	sender := NewWebhookSender(cfg, notificationRepo, slog.New(slog.NewTextHandler(os.Stdout, nil)))
+
+	message := &domain.OutboxMessage{
+		ID:        42,
+		UserID:    &userID,
+		Channel:   domain.OutboxChannelWebhook,
+		EventType: domain.NotificationEventDeposit,
+		Recipient: server.URL,
+		Body:      `{"event":"deposit"}`,
+	}
+
+	if err := sender.Send(context.Background(), message); err != nil {
+		t.Fatalf("Send failed: %v", err)
+	}
+
+	if receivedBody != message.Body {
+		t.Errorf("unexpected body: %s", receivedBody)
+	}
+	if received.Header.Get(WebhookHeaderEvent) != domain.NotificationEventDeposit {
+		t.Errorf("unexpected event header: %s", received.Header.Get(WebhookHeaderEvent))
+	}
+
+	timestamp := received.Header.Get(WebhookHeaderTimestamp)
+	signature := strings.TrimPrefix(received.Header.Get(WebhookHeaderSignature), "sha256=")
+	if signature != SignWebhookPayload(timestamp, receivedBody, "test-secret") {
+		t.Error("webhook signature does not match payload")
+	}
+
+	t.Run("non-2xx response is an error", func(t *testing.T) {
+		failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusInternalServerError)
+		}))
+		defer failing.Close()
+
+		notificationRepo.endpoints[domain.NotificationChannelWebhook].Address = failing.URL
+		if err := sender.Send(context.Background(), message); err == nil {
+			t.Error("expected error for failed webhook delivery")
+		}
+	})
+}
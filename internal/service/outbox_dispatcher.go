@@ -0,0 +1,155 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/vterdunov/learn-bank-app/internal/config"
+	"github.com/vterdunov/learn-bank-app/internal/domain"
+	"github.com/vterdunov/learn-bank-app/internal/repository"
+	"github.com/vterdunov/learn-bank-app/pkg/logger"
+)
+
+// ErrUnsupportedChannel канал доставки не поддерживается диспетчером
+var ErrUnsupportedChannel = errors.New("unsupported outbox channel")
+
+// OutboxDispatcherImpl реализация OutboxDispatcher
+type OutboxDispatcherImpl struct {
+	outboxRepo   repository.OutboxRepository
+	senders      map[string]ChannelSender
+	logger       *slog.Logger
+	pollInterval time.Duration
+	batchSize    int
+	baseBackoff  time.Duration
+	maxBackoff   time.Duration
+	lease        time.Duration
+	stopChan     chan struct{}
+	wg           sync.WaitGroup
+}
+
+// NewOutboxDispatcher создает новый экземпляр OutboxDispatcher.
+// senders сопоставляет канал outbox (email, webhook, sms) с транспортом доставки.
+func NewOutboxDispatcher(
+	cfg *config.Config,
+	outboxRepo repository.OutboxRepository,
+	senders map[string]ChannelSender,
+	lg *slog.Logger,
+) OutboxDispatcher {
+	return &OutboxDispatcherImpl{
+		outboxRepo:   outboxRepo,
+		senders:      senders,
+		logger:       logger.WithService(lg, "outbox_dispatcher"),
+		pollInterval: cfg.Outbox.PollInterval,
+		batchSize:    cfg.Outbox.BatchSize,
+		baseBackoff:  cfg.Outbox.BaseBackoff,
+		maxBackoff:   cfg.Outbox.MaxBackoff,
+		lease:        cfg.Outbox.Lease,
+		stopChan:     make(chan struct{}),
+	}
+}
+
+// Start запускает периодическую отправку сообщений из outbox
+func (d *OutboxDispatcherImpl) Start(ctx context.Context) error {
+	if d.pollInterval <= 0 {
+		return fmt.Errorf("invalid outbox poll interval: %s", d.pollInterval)
+	}
+
+	d.logger.Info("Starting outbox dispatcher", "interval", d.pollInterval, "batch_size", d.batchSize)
+
+	d.wg.Add(1)
+	go func() {
+		defer d.wg.Done()
+
+		ticker := time.NewTicker(d.pollInterval)
+		defer ticker.Stop()
+
+		for {
+			if _, err := d.DispatchPending(ctx); err != nil {
+				d.logger.Error("Failed to dispatch outbox messages", "error", err)
+			}
+
+			select {
+			case <-ticker.C:
+			case <-d.stopChan:
+				d.logger.Info("Outbox dispatcher stopped")
+				return
+			case <-ctx.Done():
+				d.logger.Info("Outbox dispatcher stopped due to context cancellation")
+				return
+			}
+		}
+	}()
+
+	return nil
+}
+
+// Stop останавливает диспетчер и дожидается завершения текущей пачки
+func (d *OutboxDispatcherImpl) Stop() {
+	close(d.stopChan)
+	d.wg.Wait()
+}
+
+// DispatchPending отправляет пачку готовых сообщений и возвращает количество доставленных
+func (d *OutboxDispatcherImpl) DispatchPending(ctx context.Context) (int, error) {
+	messages, err := d.outboxRepo.ClaimDue(ctx, d.batchSize, d.lease)
+	if err != nil {
+		return 0, fmt.Errorf("failed to claim outbox messages: %w", err)
+	}
+
+	sent := 0
+	for _, message := range messages {
+		if d.deliver(ctx, message) {
+			sent++
+		}
+	}
+
+	if len(messages) > 0 {
+		d.logger.Info("Outbox batch dispatched", "claimed", len(messages), "sent", sent)
+	}
+
+	return sent, nil
+}
+
+// deliver выполняет одну попытку доставки и сохраняет ее результат
+func (d *OutboxDispatcherImpl) deliver(ctx context.Context, message *domain.OutboxMessage) bool {
+	var sendErr error
+	if sender, ok := d.senders[message.Channel]; ok {
+		sendErr = sender.Send(ctx, message)
+	} else {
+		sendErr = fmt.Errorf("%w: %s", ErrUnsupportedChannel, message.Channel)
+	}
+
+	now := time.Now()
+	if sendErr == nil {
+		message.MarkSent(now)
+	} else {
+		message.MarkFailed(now, sendErr, d.baseBackoff, d.maxBackoff)
+
+		if message.Status == domain.OutboxStatusDead {
+			logger.LogSecurityEvent(d.logger, "outbox_message_dead", "high", map[string]interface{}{
+				"outbox_id":  message.ID,
+				"channel":    message.Channel,
+				"event_type": message.EventType,
+				"attempts":   message.Attempts,
+				"error":      sendErr.Error(),
+			})
+		} else {
+			d.logger.Warn("Outbox delivery failed, retry scheduled",
+				"outbox_id", message.ID,
+				"attempts", message.Attempts,
+				"next_attempt_at", message.NextAttemptAt,
+				"error", sendErr)
+		}
+	}
+
+	if err := d.outboxRepo.Update(ctx, message); err != nil {
+		// Сообщение останется в processing и будет забрано повторно после истечения аренды
+		d.logger.Error("Failed to save outbox delivery result", "outbox_id", message.ID, "error", err)
+	}
+
+	return sendErr == nil
+}
@@ -0,0 +1,287 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/vterdunov/learn-bank-app/internal/config"
+	"github.com/vterdunov/learn-bank-app/internal/domain"
+	"github.com/vterdunov/learn-bank-app/templates"
+)
+
+// MockOutboxRepository для тестирования (хранит сообщения в памяти)
+type MockOutboxRepository struct {
+	messages map[int64]*domain.OutboxMessage
+	nextID   int64
+}
+
+func NewMockOutboxRepository() *MockOutboxRepository {
+	return &MockOutboxRepository{
+		messages: make(map[int64]*domain.OutboxMessage),
+		nextID:   1,
+	}
+}
+
+func (m *MockOutboxRepository) Enqueue(ctx context.Context, message *domain.OutboxMessage) error {
+	message.ID = m.nextID
+	m.nextID++
+	message.Status = domain.OutboxStatusPending
+	if message.NextAttemptAt.IsZero() {
+		message.NextAttemptAt = time.Now()
+	}
+
+	saved := *message
+	m.messages[message.ID] = &saved
+	return nil
+}
+
+func (m *MockOutboxRepository) ClaimDue(ctx context.Context, limit int, lease time.Duration) ([]*domain.OutboxMessage, error) {
+	now := time.Now()
+	var result []*domain.OutboxMessage
+	for id := int64(1); id < m.nextID && len(result) < limit; id++ {
+		message, ok := m.messages[id]
+		if !ok || message.Status != domain.OutboxStatusPending || message.NextAttemptAt.After(now) {
+			continue
+		}
+
+		lockedUntil := now.Add(lease)
+		message.Status = domain.OutboxStatusProcessing
+		message.LockedUntil = &lockedUntil
+
+		claimed := *message
+		result = append(result, &claimed)
+	}
+	return result, nil
+}
+
+func (m *MockOutboxRepository) Update(ctx context.Context, message *domain.OutboxMessage) error {
+	saved := *message
+	m.messages[message.ID] = &saved
+	return nil
+}
+
+func (m *MockOutboxRepository) GetByID(ctx context.Context, id int64) (*domain.OutboxMessage, error) {
+	message, ok := m.messages[id]
+	if !ok {
+		return nil, errors.New("outbox message not found")
+	}
+	return message, nil
+}
+
+func (m *MockOutboxRepository) ListByStatus(ctx context.Context, status string, limit, offset int) ([]*domain.OutboxMessage, error) {
+	var result []*domain.OutboxMessage
+	for _, message := range m.messages {
+		if message.Status == status {
+			result = append(result, message)
+		}
+	}
+	return result, nil
+}
+
+func (m *MockOutboxRepository) Requeue(ctx context.Context, id int64) error {
+	message, ok := m.messages[id]
+	if !ok || message.Status != domain.OutboxStatusDead {
+		return domain.ErrOutboxNotDead
+	}
+	message.Status = domain.OutboxStatusPending
+	message.Attempts = 0
+	message.NextAttemptAt = time.Now()
+	return nil
+}
+
+func setupOutboxDispatcher(t *testing.T, maxAttempts int) (*OutboxDispatcherImpl, EmailService, *MockOutboxRepository, *smtpStub) {
+	t.Helper()
+
+	stub := newSMTPStub(t)
+	host, port := stub.HostPort(t)
+
+	cfg := &config.Config{
+		SMTP: config.SMTPConfig{
+			Host: host,
+			Port: port,
+			From: "bank@example.com",
+		},
+		Outbox: config.OutboxConfig{
+			PollInterval: time.Second,
+			BatchSize:    10,
+			MaxAttempts:  maxAttempts,
+			BaseBackoff:  time.Minute,
+			MaxBackoff:   time.Hour,
+			Lease:        time.Minute,
+		},
+	}
+
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	mockRepo := NewMockOutboxRepository()
+	emailTemplates, err := LoadEmailTemplates(templates.Email, "v1")
+	if err != nil {
+		t.Fatalf("failed to load email templates: %v", err)
+	}
+	emailService := NewEmailService(cfg, mockRepo, emailTemplates, logger)
+	dispatcher := NewOutboxDispatcher(cfg, mockRepo, map[string]ChannelSender{
+		domain.OutboxChannelEmail: NewSMTPEmailSender(cfg, logger),
+	}, logger).(*OutboxDispatcherImpl)
+
+	return dispatcher, emailService, mockRepo, stub
+}
+
+// queueTestEmail ставит письмо в outbox напрямую, без шаблонов
+func queueTestEmail(t *testing.T, emailService EmailService, to string) {
+	t.Helper()
+
+	if err := emailService.(*EmailServiceImpl).enqueue(context.Background(), domain.OutboxEventPayment, to, &RenderedEmail{
+		Subject: "Test",
+		Text:    "hello",
+		HTML:    "<p>hello</p>",
+	}); err != nil {
+		t.Fatalf("failed to enqueue email: %v", err)
+	}
+}
+
+func TestOutboxDispatcher_DeliversQueuedEmail(t *testing.T) {
+	dispatcher, emailService, mockRepo, stub := setupOutboxDispatcher(t, 3)
+	ctx := context.Background()
+
+	queueTestEmail(t, emailService, "user@example.com")
+
+	sent, err := dispatcher.DispatchPending(ctx)
+	if err != nil {
+		t.Fatalf("DispatchPending failed: %v", err)
+	}
+	if sent != 1 {
+		t.Fatalf("expected 1 sent message, got %d", sent)
+	}
+
+	message := mockRepo.messages[1]
+	if message.Status != domain.OutboxStatusSent {
+		t.Errorf("expected status %s, got %s", domain.OutboxStatusSent, message.Status)
+	}
+	if message.SentAt == nil {
+		t.Error("expected sent_at to be set")
+	}
+	if message.Attempts != 1 {
+		t.Errorf("expected 1 attempt, got %d", message.Attempts)
+	}
+
+	received := stub.Messages()
+	if len(received) != 1 {
+		t.Fatalf("expected 1 message on SMTP server, got %d", len(received))
+	}
+	if len(received[0].To) != 1 || !strings.Contains(received[0].To[0], "user@example.com") {
+		t.Errorf("unexpected recipients: %v", received[0].To)
+	}
+	if !strings.Contains(received[0].Data, "hello") {
+		t.Error("expected message body to be delivered")
+	}
+
+	// Повторная отправка не должна дублировать письмо
+	sent, err = dispatcher.DispatchPending(ctx)
+	if err != nil {
+		t.Fatalf("DispatchPending failed: %v", err)
+	}
+	if sent != 0 || len(stub.Messages()) != 1 {
+		t.Error("expected sent message not to be delivered again")
+	}
+}
+
+func TestOutboxDispatcher_RetriesWithBackoffAndDeadLetters(t *testing.T) {
+	dispatcher, emailService, mockRepo, stub := setupOutboxDispatcher(t, 2)
+	ctx := context.Background()
+
+	stub.SetRejectRcpt(true)
+	queueTestEmail(t, emailService, "user@example.com")
+
+	t.Run("failed attempt schedules retry", func(t *testing.T) {
+		before := time.Now()
+		sent, err := dispatcher.DispatchPending(ctx)
+		if err != nil {
+			t.Fatalf("DispatchPending failed: %v", err)
+		}
+		if sent != 0 {
+			t.Errorf("expected no sent messages, got %d", sent)
+		}
+
+		message := mockRepo.messages[1]
+		if message.Status != domain.OutboxStatusPending {
+			t.Errorf("expected status %s, got %s", domain.OutboxStatusPending, message.Status)
+		}
+		if message.LastError == "" {
+			t.Error("expected last error to be recorded")
+		}
+		if message.NextAttemptAt.Before(before.Add(time.Minute)) {
+			t.Errorf("expected retry after base backoff, got %s", message.NextAttemptAt)
+		}
+	})
+
+	t.Run("message is not claimed before backoff expires", func(t *testing.T) {
+		sent, err := dispatcher.DispatchPending(ctx)
+		if err != nil {
+			t.Fatalf("DispatchPending failed: %v", err)
+		}
+		if sent != 0 || mockRepo.messages[1].Attempts != 1 {
+			t.Error("expected message to wait for backoff")
+		}
+	})
+
+	t.Run("exhausted attempts move message to dead", func(t *testing.T) {
+		mockRepo.messages[1].NextAttemptAt = time.Now().Add(-time.Second)
+
+		if _, err := dispatcher.DispatchPending(ctx); err != nil {
+			t.Fatalf("DispatchPending failed: %v", err)
+		}
+
+		message := mockRepo.messages[1]
+		if message.Status != domain.OutboxStatusDead {
+			t.Errorf("expected status %s, got %s", domain.OutboxStatusDead, message.Status)
+		}
+		if message.Attempts != 2 {
+			t.Errorf("expected 2 attempts, got %d", message.Attempts)
+		}
+	})
+
+	t.Run("requeued dead message is delivered", func(t *testing.T) {
+		stub.SetRejectRcpt(false)
+
+		if err := mockRepo.Requeue(ctx, 1); err != nil {
+			t.Fatalf("Requeue failed: %v", err)
+		}
+
+		sent, err := dispatcher.DispatchPending(ctx)
+		if err != nil {
+			t.Fatalf("DispatchPending failed: %v", err)
+		}
+		if sent != 1 {
+			t.Errorf("expected 1 sent message, got %d", sent)
+		}
+		if mockRepo.messages[1].Status != domain.OutboxStatusSent {
+			t.Errorf("expected status %s, got %s", domain.OutboxStatusSent, mockRepo.messages[1].Status)
+		}
+	})
+}
+
+func TestOutboxBackoff(t *testing.T) {
+	base := 30 * time.Second
+	maxBackoff := 5 * time.Minute
+
+	tests := []struct {
+		attempts int
+		expected time.Duration
+	}{
+		{attempts: 1, expected: 30 * time.Second},
+		{attempts: 2, expected: time.Minute},
+		{attempts: 4, expected: 4 * time.Minute},
+		{attempts: 5, expected: 5 * time.Minute},
+		{attempts: 50, expected: 5 * time.Minute},
+	}
+
+	for _, tt := range tests {
+		if got := domain.OutboxBackoff(tt.attempts, base, maxBackoff); got != tt.expected {
+			t.Errorf("OutboxBackoff(%d) = %s, expected %s", tt.attempts, got, tt.expected)
+		}
+	}
+}
@@ -0,0 +1,79 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strconv"
+
+	"github.com/vterdunov/learn-bank-app/internal/domain"
+	"github.com/vterdunov/learn-bank-app/internal/repository"
+	"github.com/vterdunov/learn-bank-app/pkg/logger"
+)
+
+// ErrInvalidOutboxStatus неизвестный статус сообщения outbox
+var ErrInvalidOutboxStatus = errors.New("invalid outbox status")
+
+const (
+	// defaultOutboxPageSize размер страницы выборки outbox по умолчанию
+	defaultOutboxPageSize = 50
+	// maxOutboxPageSize максимальный размер страницы выборки outbox
+	maxOutboxPageSize = 500
+)
+
+// outboxService реализует интерфейс OutboxService
+type outboxService struct {
+	outboxRepo   repository.OutboxRepository
+	auditService AuditService
+	logger       *slog.Logger
+}
+
+// NewOutboxService создает новый экземпляр сервиса администрирования outbox
+func NewOutboxService(outboxRepo repository.OutboxRepository, auditService AuditService, lg *slog.Logger) OutboxService {
+	return &outboxService{
+		outboxRepo:   outboxRepo,
+		auditService: auditService,
+		logger:       logger.WithService(lg, "outbox_service"),
+	}
+}
+
+// ListMessages возвращает сообщения outbox с указанным статусом доставки
+func (s *outboxService) ListMessages(ctx context.Context, status string, limit, offset int) ([]*domain.OutboxMessage, error) {
+	switch status {
+	case domain.OutboxStatusPending, domain.OutboxStatusProcessing, domain.OutboxStatusSent, domain.OutboxStatusDead:
+	default:
+		return nil, ErrInvalidOutboxStatus
+	}
+
+	if limit <= 0 {
+		limit = defaultOutboxPageSize
+	}
+	if limit > maxOutboxPageSize {
+		limit = maxOutboxPageSize
+	}
+
+	messages, err := s.outboxRepo.ListByStatus(ctx, status, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list outbox messages: %w", err)
+	}
+
+	return messages, nil
+}
+
+// RetryMessage возвращает dead сообщение в очередь доставки
+func (s *outboxService) RetryMessage(ctx context.Context, adminID int, id int64) error {
+	if err := s.outboxRepo.Requeue(ctx, id); err != nil {
+		s.logger.Warn("Failed to requeue outbox message", "outbox_id", id, "admin_id", adminID, "error", err)
+		return err
+	}
+
+	s.logger.Info("Outbox message requeued", "outbox_id", id, "admin_id", adminID)
+
+	event := NewUserAuditEvent(adminID, domain.AuditActionAdminOutboxRetry, "outbox_message", strconv.FormatInt(id, 10))
+	event.ActorType = domain.AuditActorAdmin
+	// Ошибка аудита уже залогирована, сообщение возвращено в очередь
+	_ = s.auditService.Record(ctx, event)
+
+	return nil
+}
@@ -0,0 +1,337 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/vterdunov/learn-bank-app/internal/config"
+	"github.com/vterdunov/learn-bank-app/internal/domain"
+	"github.com/vterdunov/learn-bank-app/internal/repository"
+	"github.com/vterdunov/learn-bank-app/internal/utils"
+	"github.com/vterdunov/learn-bank-app/pkg/logger"
+)
+
+// recipientService реализует интерфейс RecipientService.
+// Получатель ищется по номеру счета, email или телефону, внутренние ID чужих
+// счетов клиенту не раскрываются: любой неуспешный поиск — ErrRecipientNotFound.
+type recipientService struct {
+	accountRepo        repository.AccountRepository
+	userRepo           repository.UserRepository
+	aliasRepo          repository.PaymentAliasRepository
+	transactionRepo    repository.TransactionRepository
+	accessControl      domain.AccessControlService
+	accountService     AccountService
+	auditService       AuditService
+	clock              utils.Clock
+	newRecipientLimit  float64
+	newRecipientPeriod time.Duration
+	logger             *slog.Logger
+}
+
+// NewRecipientService создает новый экземпляр RecipientService
+func NewRecipientService(
+	cfg *config.Config,
+	accountRepo repository.AccountRepository,
+	userRepo repository.UserRepository,
+	aliasRepo repository.PaymentAliasRepository,
+	transactionRepo repository.TransactionRepository,
+	accessControl domain.AccessControlService,
+	accountService AccountService,
+	auditService AuditService,
+	clock utils.Clock,
+	lg *slog.Logger,
+) RecipientService {
+	return &recipientService{
+		accountRepo:        accountRepo,
+		userRepo:           userRepo,
+		aliasRepo:          aliasRepo,
+		transactionRepo:    transactionRepo,
+		accessControl:      accessControl,
+		accountService:     accountService,
+		auditService:       auditService,
+		clock:              clock,
+		newRecipientLimit:  cfg.Transfer.NewRecipientLimit,
+		newRecipientPeriod: cfg.Transfer.NewRecipientPeriod,
+		logger:             logger.WithService(lg, "recipient_service"),
+	}
+}
+
+// ResolveRecipient находит получателя и возвращает маскированные данные для подтверждения перевода
+func (s *recipientService) ResolveRecipient(ctx context.Context, userID int, lookup domain.RecipientLookup) (*domain.Recipient, error) {
+	if err := lookup.Validate(); err != nil {
+		return nil, err
+	}
+
+	account, err := s.findAccount(ctx, userID, lookup)
+	if err != nil {
+		s.logger.Info("Recipient not found", "user_id", userID, "lookup", lookupKind(lookup))
+		return nil, domain.ErrRecipientNotFound
+	}
+
+	return s.recipientFor(ctx, userID, account)
+}
+
+// Transfer переводит средства найденному получателю.
+// Лимит нового получателя проверяется до перевода без блокировок, поэтому
+// одновременные переводы одному получателю могут превысить его на сумму последнего перевода.
+func (s *recipientService) Transfer(ctx context.Context, userID, fromAccountID int, lookup domain.RecipientLookup, amount float64) (*domain.Recipient, error) {
+	recipient, err := s.ResolveRecipient(ctx, userID, lookup)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.transfer(ctx, userID, fromAccountID, recipient, amount); err != nil {
+		return nil, err
+	}
+
+	return recipient, nil
+}
+
+// TransferToAccount переводит средства получателю, найденному ранее (например, при создании поручения).
+// Счет получателя и лимит нового получателя проверяются заново на момент перевода.
+func (s *recipientService) TransferToAccount(ctx context.Context, userID, fromAccountID, toAccountID int, amount float64) error {
+	account, err := s.accountRepo.GetByID(ctx, toAccountID)
+	if err != nil {
+		s.logger.Info("Recipient account not found", "user_id", userID, "account_id", toAccountID)
+		return domain.ErrRecipientNotFound
+	}
+
+	recipient, err := s.recipientFor(ctx, userID, account)
+	if err != nil {
+		return err
+	}
+
+	return s.transfer(ctx, userID, fromAccountID, recipient, amount)
+}
+
+// recipientFor проверяет, что счет получателя активен, и собирает маскированные данные и лимит нового получателя
+func (s *recipientService) recipientFor(ctx context.Context, userID int, account *domain.Account) (*domain.Recipient, error) {
+	if account.Status != domain.AccountStatusActive {
+		s.logger.Info("Recipient account is not active", "user_id", userID, "account_id", account.ID, "status", account.Status)
+		return nil, domain.ErrRecipientNotFound
+	}
+
+	owner, err := s.userRepo.GetByID(ctx, account.UserID)
+	if err != nil {
+		s.logger.Error("Failed to get recipient owner", "account_id", account.ID, "error", err)
+		return nil, fmt.Errorf("failed to get recipient: %w", err)
+	}
+
+	recipient := &domain.Recipient{
+		AccountID:           account.ID,
+		UserID:              account.UserID,
+		MaskedName:          domain.MaskName(owner.Username),
+		MaskedAccountNumber: domain.MaskAccountNumber(account.Number),
+		OwnAccount:          account.UserID == userID,
+	}
+
+	if !recipient.OwnAccount {
+		if err := s.applyNewRecipientLimit(ctx, userID, recipient); err != nil {
+			return nil, err
+		}
+	}
+
+	return recipient, nil
+}
+
+// transfer проверяет лимит нового получателя и выполняет перевод; остаток лимита уменьшается на сумму перевода
+func (s *recipientService) transfer(ctx context.Context, userID, fromAccountID int, recipient *domain.Recipient, amount float64) error {
+	if recipient.LimitRemaining != nil && amount > *recipient.LimitRemaining {
+		s.logger.Warn("New recipient limit exceeded",
+			"user_id", userID,
+			"to_account_id", recipient.AccountID,
+			"amount", amount,
+			"limit_remaining", *recipient.LimitRemaining)
+		return domain.ErrNewRecipientLimitExceeded
+	}
+
+	if err := s.accountService.TransferMoney(ctx, userID, fromAccountID, recipient.AccountID, amount); err != nil {
+		return err
+	}
+
+	if recipient.LimitRemaining != nil {
+		remaining := math.Round((*recipient.LimitRemaining-amount)*100) / 100
+		recipient.LimitRemaining = &remaining
+	}
+
+	return nil
+}
+
+// GetAliases возвращает псевдонимы пользователя
+func (s *recipientService) GetAliases(ctx context.Context, userID int) ([]*domain.PaymentAlias, error) {
+	aliases, err := s.aliasRepo.GetByUserID(ctx, userID)
+	if err != nil {
+		s.logger.Error("Failed to get payment aliases", "user_id", userID, "error", err)
+		return nil, fmt.Errorf("failed to get payment aliases: %w", err)
+	}
+
+	return aliases, nil
+}
+
+// CreateAlias привязывает телефон или email пользователя к его счету.
+// Email-псевдоним допускается только для email, под которым зарегистрирован пользователь.
+func (s *recipientService) CreateAlias(ctx context.Context, userID int, alias domain.PaymentAlias) (*domain.PaymentAlias, error) {
+	alias.UserID = userID
+	if err := alias.Validate(); err != nil {
+		return nil, err
+	}
+
+	if err := s.accessControl.CanAccessAccount(ctx, userID, alias.AccountID); err != nil {
+		s.logger.Warn("Access denied for payment alias", "user_id", userID, "account_id", alias.AccountID)
+		if domain.IsAccessDeniedError(err) {
+			return nil, &ServiceError{Code: http.StatusForbidden, Message: err.Error()}
+		}
+		return nil, ErrAccountNotFound
+	}
+
+	account, err := s.accountRepo.GetByID(ctx, alias.AccountID)
+	if err != nil {
+		return nil, ErrAccountNotFound
+	}
+	if account.Status != domain.AccountStatusActive {
+		return nil, ErrAccountBlocked
+	}
+
+	if alias.Type == domain.PaymentAliasEmail {
+		user, err := s.userRepo.GetByID(ctx, userID)
+		if err != nil {
+			s.logger.Error("Failed to get user for payment alias", "user_id", userID, "error", err)
+			return nil, fmt.Errorf("failed to get user: %w", err)
+		}
+		if !strings.EqualFold(user.Email, alias.Value) {
+			return nil, domain.ErrPaymentAliasEmailMismatch
+		}
+	}
+
+	if err := s.aliasRepo.Create(ctx, &alias); err != nil {
+		if errors.Is(err, domain.ErrPaymentAliasTaken) {
+			return nil, err
+		}
+		s.logger.Error("Failed to create payment alias", "user_id", userID, "type", alias.Type, "error", err)
+		return nil, fmt.Errorf("failed to create payment alias: %w", err)
+	}
+
+	s.logger.Info("Payment alias created", "alias_id", alias.ID, "user_id", userID, "type", alias.Type, "account_id", alias.AccountID)
+
+	event := NewUserAuditEvent(userID, domain.AuditActionPaymentAliasCreate, "payment_alias", auditResourceID(alias.ID))
+	event.After = domain.NewAuditState(alias)
+	// Ошибка аудита уже залогирована, псевдоним создан
+	_ = s.auditService.Record(ctx, event)
+
+	return &alias, nil
+}
+
+// DeleteAlias удаляет псевдоним пользователя
+func (s *recipientService) DeleteAlias(ctx context.Context, userID, aliasID int) error {
+	if err := s.aliasRepo.Delete(ctx, userID, aliasID); err != nil {
+		if errors.Is(err, domain.ErrPaymentAliasNotFound) {
+			return err
+		}
+		s.logger.Error("Failed to delete payment alias", "alias_id", aliasID, "user_id", userID, "error", err)
+		return fmt.Errorf("failed to delete payment alias: %w", err)
+	}
+
+	s.logger.Info("Payment alias deleted", "alias_id", aliasID, "user_id", userID)
+
+	event := NewUserAuditEvent(userID, domain.AuditActionPaymentAliasDelete, "payment_alias", auditResourceID(aliasID))
+	// Ошибка аудита уже залогирована, псевдоним удален
+	_ = s.auditService.Record(ctx, event)
+
+	return nil
+}
+
+// findAccount находит счет получателя. По ID доступны только собственные счета,
+// по email без псевдонима — самый старый активный счет владельца email.
+func (s *recipientService) findAccount(ctx context.Context, userID int, lookup domain.RecipientLookup) (*domain.Account, error) {
+	switch {
+	case lookup.AccountID != 0:
+		account, err := s.accountRepo.GetByID(ctx, lookup.AccountID)
+		if err != nil {
+			return nil, err
+		}
+		if account.UserID != userID {
+			return nil, domain.ErrRecipientNotFound
+		}
+		return account, nil
+
+	case lookup.AccountNumber != "":
+		return s.accountRepo.GetByNumber(ctx, lookup.AccountNumber)
+
+	case lookup.Phone != "":
+		alias, err := s.aliasRepo.GetByValue(ctx, domain.PaymentAliasPhone, lookup.Phone)
+		if err != nil {
+			return nil, err
+		}
+		return s.accountRepo.GetByID(ctx, alias.AccountID)
+
+	default:
+		owner, err := s.userRepo.GetByEmail(ctx, lookup.Email)
+		if err != nil {
+			return nil, err
+		}
+
+		alias, err := s.aliasRepo.GetByValue(ctx, domain.PaymentAliasEmail, lookup.Email)
+		switch {
+		case err == nil && alias.UserID == owner.ID:
+			return s.accountRepo.GetByID(ctx, alias.AccountID)
+		case err != nil && !errors.Is(err, domain.ErrPaymentAliasNotFound):
+			return nil, err
+		}
+
+		accounts, err := s.accountRepo.GetByUserID(ctx, owner.ID)
+		if err != nil {
+			return nil, err
+		}
+		// Счета отсортированы от новых к старым
+		for i := len(accounts) - 1; i >= 0; i-- {
+			if accounts[i].Status == domain.AccountStatusActive {
+				return accounts[i], nil
+			}
+		}
+		return nil, domain.ErrRecipientNotFound
+	}
+}
+
+// applyNewRecipientLimit определяет, новый ли получатель, и сколько ему еще можно перевести.
+// Получатель новый, пока с первого перевода ему не прошел NewRecipientPeriod.
+func (s *recipientService) applyNewRecipientLimit(ctx context.Context, userID int, recipient *domain.Recipient) error {
+	if s.newRecipientLimit <= 0 {
+		return nil
+	}
+
+	since := s.clock.Now().Add(-s.newRecipientPeriod)
+	stats, err := s.transactionRepo.GetTransferStats(ctx, userID, recipient.AccountID, since)
+	if err != nil {
+		s.logger.Error("Failed to get transfer stats", "user_id", userID, "to_account_id", recipient.AccountID, "error", err)
+		return fmt.Errorf("failed to get transfer stats: %w", err)
+	}
+
+	if stats.FirstTransferAt != nil && !stats.FirstTransferAt.After(since) {
+		return nil
+	}
+
+	remaining := math.Max(math.Round((s.newRecipientLimit-stats.AmountSince)*100)/100, 0)
+	recipient.NewRecipient = true
+	recipient.LimitRemaining = &remaining
+	return nil
+}
+
+// lookupKind возвращает способ поиска получателя для логов (без самих данных)
+func lookupKind(lookup domain.RecipientLookup) string {
+	switch {
+	case lookup.AccountID != 0:
+		return "account_id"
+	case lookup.AccountNumber != "":
+		return "account_number"
+	case lookup.Phone != "":
+		return "phone"
+	default:
+		return "email"
+	}
+}
@@ -0,0 +1,613 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"os"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/vterdunov/learn-bank-app/internal/config"
+	"github.com/vterdunov/learn-bank-app/internal/domain"
+)
+
+// MockAccountStore счета нескольких пользователей в памяти
+type MockAccountStore struct {
+	accounts map[int]*domain.Account
+}
+
+func (m *MockAccountStore) Create(ctx context.Context, account *domain.Account) error {
+	m.accounts[account.ID] = account
+	return nil
+}
+
+func (m *MockAccountStore) GetByID(ctx context.Context, id int) (*domain.Account, error) {
+	account, ok := m.accounts[id]
+	if !ok {
+		return nil, errors.New("account not found")
+	}
+	copied := *account
+	return &copied, nil
+}
+
+func (m *MockAccountStore) GetByUserID(ctx context.Context, userID int) ([]*domain.Account, error) {
+	var accounts []*domain.Account
+	for _, account := range m.accounts {
+		if account.UserID == userID {
+			copied := *account
+			accounts = append(accounts, &copied)
+		}
+	}
+	// Как в репозитории: новые счета первыми
+	sort.Slice(accounts, func(i, j int) bool { return accounts[i].ID > accounts[j].ID })
+	return accounts, nil
+}
+
+func (m *MockAccountStore) GetByNumber(ctx context.Context, number string) (*domain.Account, error) {
+	for _, account := range m.accounts {
+		if account.Number == number {
+			copied := *account
+			return &copied, nil
+		}
+	}
+	return nil, errors.New("account not found")
+}
+
+func (m *MockAccountStore) Update(ctx context.Context, account *domain.Account) error {
+	m.accounts[account.ID] = account
+	return nil
+}
+
+func (m *MockAccountStore) GetByIDForUpdate(ctx context.Context, id int) (*domain.Account, error) {
+	return m.GetByID(ctx, id)
+}
+
+func (m *MockAccountStore) UpdateStatus(ctx context.Context, id int, status string) error {
+	account, ok := m.accounts[id]
+	if !ok {
+		return errors.New("account not found")
+	}
+	account.Status = status
+	return nil
+}
+
+func (m *MockAccountStore) UpdateBalance(ctx context.Context, id int, balance float64) error {
+	m.accounts[id].Balance = balance
+	return nil
+}
+
+func (m *MockAccountStore) Debit(ctx context.Context, id int, amount float64) error {
+	account := m.accounts[id]
+	if account.AvailableBalance() < amount {
+		return domain.ErrAccountInsufficientFunds
+	}
+	account.Balance -= amount
+	return nil
+}
+
+func (m *MockAccountStore) Credit(ctx context.Context, id int, amount float64) error {
+	account, ok := m.accounts[id]
+	if !ok {
+		return errors.New("account not found")
+	}
+	account.Balance += amount
+	return nil
+}
+
+func (m *MockAccountStore) Delete(ctx context.Context, id int) error {
+	delete(m.accounts, id)
+	return nil
+}
+
+func (m *MockAccountStore) Transfer(ctx context.Context, fromID, toID int, amount float64) error {
+	m.accounts[fromID].Balance -= amount
+	m.accounts[toID].Balance += amount
+	return nil
+}
+
+func (m *MockAccountStore) GetBalance(ctx context.Context, id int) (float64, error) {
+	return m.accounts[id].Balance, nil
+}
+
+func (m *MockAccountStore) UpdateOverdraftLimit(ctx context.Context, id int, limit float64) error {
+	account := m.accounts[id]
+	if account.Balance+limit-account.HeldAmount < 0 {
+		return domain.ErrOverdraftLimitBelowDebt
+	}
+	account.OverdraftLimit = limit
+	return nil
+}
+
+// mockStoreAccessControl разрешает доступ только к счетам владельца из MockAccountStore.
+// Если заданы cards, PIN и реквизиты карты доступны только ее держателю и владельцу счета
+type mockStoreAccessControl struct {
+	accounts *MockAccountStore
+	cards    *MockCardRepository
+}
+
+func (m *mockStoreAccessControl) CanAccessAccount(ctx context.Context, userID, accountID int) error {
+	account, err := m.accounts.GetByID(ctx, accountID)
+	if err != nil {
+		return err
+	}
+	if account.UserID != userID {
+		return domain.NewAccessDeniedError("account", accountID, userID)
+	}
+	return nil
+}
+
+// CheckAccountPermission в моке проверяет только владение счетом
+func (m *mockStoreAccessControl) CheckAccountPermission(ctx context.Context, userID, accountID int, permission string) error {
+	return m.CanAccessAccount(ctx, userID, accountID)
+}
+
+func (m *mockStoreAccessControl) CanAccessCard(ctx context.Context, userID, cardID int) error {
+	return nil
+}
+
+func (m *mockStoreAccessControl) CheckCardholder(ctx context.Context, userID, cardID int) error {
+	if m.cards == nil {
+		return nil
+	}
+	card, err := m.cards.GetByID(ctx, cardID)
+	if err != nil {
+		return err
+	}
+	if card.HolderID == userID {
+		return nil
+	}
+	if err := m.CanAccessAccount(ctx, userID, card.AccountID); err != nil {
+		return domain.NewAccessDeniedError("card", cardID, userID)
+	}
+	return nil
+}
+
+func (m *mockStoreAccessControl) CanAccessCredit(ctx context.Context, userID, creditID int) error {
+	return nil
+}
+
+// MockTransactionRepository хранит созданные транзакции и считает статистику переводов по ним
+type MockTransactionRepository struct {
+	accounts     *MockAccountStore
+	transactions []*domain.Transaction
+}
+
+func (m *MockTransactionRepository) Create(ctx context.Context, transaction *domain.Transaction) error {
+	transaction.ID = len(m.transactions) + 1
+	m.transactions = append(m.transactions, transaction)
+	return nil
+}
+
+func (m *MockTransactionRepository) GetByID(ctx context.Context, id int) (*domain.Transaction, error) {
+	for _, t := range m.transactions {
+		if t.ID == id {
+			copied := *t
+			return &copied, nil
+		}
+	}
+	return nil, errors.New("transaction not found")
+}
+
+func (m *MockTransactionRepository) GetByAccountID(ctx context.Context, accountID int, limit, offset int) ([]*domain.Transaction, error) {
+	return nil, nil
+}
+
+func (m *MockTransactionRepository) GetByUserID(ctx context.Context, userID int, limit, offset int) ([]*domain.Transaction, error) {
+	return nil, nil
+}
+
+func (m *MockTransactionRepository) Update(ctx context.Context, transaction *domain.Transaction) error {
+	for i, t := range m.transactions {
+		if t.ID == transaction.ID {
+			m.transactions[i] = transaction
+			return nil
+		}
+	}
+	return errors.New("transaction not found")
+}
+
+func (m *MockTransactionRepository) Delete(ctx context.Context, id int) error {
+	return nil
+}
+
+func (m *MockTransactionRepository) GetTransactionsByDateRange(ctx context.Context, accountID int, startDate, endDate time.Time) ([]*domain.Transaction, error) {
+	return nil, nil
+}
+
+func (m *MockTransactionRepository) GetMonthlyStatistics(ctx context.Context, userID int, year int, month int) (*domain.MonthlyStatistics, error) {
+	return &domain.MonthlyStatistics{Year: year, Month: month}, nil
+}
+
+func (m *MockTransactionRepository) GetSpendingByMCC(ctx context.Context, userID int, year int, month int) ([]*domain.MCCSpending, error) {
+	return nil, nil
+}
+
+func (m *MockTransactionRepository) GetOutgoingStats(ctx context.Context, userID int, since time.Time) (*domain.OutgoingStats, error) {
+	stats := &domain.OutgoingStats{}
+	for _, t := range m.transactions {
+		if t.FromAccount == nil || t.CreatedAt.Before(since) {
+			continue
+		}
+		if from, ok := m.accounts.accounts[*t.FromAccount]; !ok || from.UserID != userID {
+			continue
+		}
+		stats.Count++
+		stats.Amount += t.Amount
+	}
+	return stats, nil
+}
+
+func (m *MockTransactionRepository) GetExternalOutgoingAmount(ctx context.Context, userID int, since time.Time) (float64, error) {
+	var amount float64
+	for _, t := range m.transactions {
+		if t.FromAccount == nil || t.CreatedAt.Before(since) {
+			continue
+		}
+		if from, ok := m.accounts.accounts[*t.FromAccount]; !ok || from.UserID != userID {
+			continue
+		}
+		if t.ToAccount != nil {
+			if to, ok := m.accounts.accounts[*t.ToAccount]; ok && to.UserID == userID {
+				continue
+			}
+		}
+		amount += t.Amount
+	}
+	return amount, nil
+}
+
+func (m *MockTransactionRepository) GetTransferStats(ctx context.Context, userID, toAccountID int, since time.Time) (*domain.TransferStats, error) {
+	stats := &domain.TransferStats{}
+	for _, t := range m.transactions {
+		if t.Type != domain.TransactionTypeTransfer || t.FromAccount == nil || t.ToAccount == nil || *t.ToAccount != toAccountID {
+			continue
+		}
+		if from, ok := m.accounts.accounts[*t.FromAccount]; !ok || from.UserID != userID {
+			continue
+		}
+		if stats.FirstTransferAt == nil || t.CreatedAt.Before(*stats.FirstTransferAt) {
+			createdAt := t.CreatedAt
+			stats.FirstTransferAt = &createdAt
+		}
+		if !t.CreatedAt.Before(since) {
+			stats.AmountSince += t.Amount
+		}
+	}
+	return stats, nil
+}
+
+// MockPaymentAliasRepository псевдонимы в памяти
+type MockPaymentAliasRepository struct {
+	aliases []*domain.PaymentAlias
+}
+
+func (m *MockPaymentAliasRepository) Create(ctx context.Context, alias *domain.PaymentAlias) error {
+	for _, a := range m.aliases {
+		if a.Type == alias.Type && a.Value == alias.Value {
+			return domain.ErrPaymentAliasTaken
+		}
+	}
+	alias.ID = len(m.aliases) + 1
+	copied := *alias
+	m.aliases = append(m.aliases, &copied)
+	return nil
+}
+
+func (m *MockPaymentAliasRepository) GetByValue(ctx context.Context, aliasType, value string) (*domain.PaymentAlias, error) {
+	for _, a := range m.aliases {
+		if a.Type == aliasType && a.Value == value {
+			return a, nil
+		}
+	}
+	return nil, domain.ErrPaymentAliasNotFound
+}
+
+func (m *MockPaymentAliasRepository) GetByUserID(ctx context.Context, userID int) ([]*domain.PaymentAlias, error) {
+	var aliases []*domain.PaymentAlias
+	for _, a := range m.aliases {
+		if a.UserID == userID {
+			aliases = append(aliases, a)
+		}
+	}
+	return aliases, nil
+}
+
+func (m *MockPaymentAliasRepository) Delete(ctx context.Context, userID, id int) error {
+	for i, a := range m.aliases {
+		if a.ID == id && a.UserID == userID {
+			m.aliases = append(m.aliases[:i], m.aliases[i+1:]...)
+			return nil
+		}
+	}
+	return domain.ErrPaymentAliasNotFound
+}
+
+type recipientTestDeps struct {
+	accounts *MockAccountStore
+	aliases  *MockPaymentAliasRepository
+	clock    *fakeClock
+	sender   int // Отправитель: счет 1
+	receiver int // Получатель: счета 2 (старый) и 3 (новый)
+}
+
+// setupRecipientService создает сервис с реальным AccountService поверх хранилищ в памяти
+func setupRecipientService(t *testing.T) (*recipientService, *recipientTestDeps) {
+	t.Helper()
+
+	cfg := &config.Config{Transfer: config.TransferConfig{NewRecipientLimit: 15000, NewRecipientPeriod: 24 * time.Hour}}
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	auditService, _ := setupAuditService()
+	ctx := context.Background()
+
+	userRepo := NewMockUserRepository()
+	sender := &domain.User{Username: "sender", Email: "sender@example.com"}
+	receiver := &domain.User{Username: "ivanov", Email: "ivanov@example.com"}
+	_ = userRepo.Create(ctx, sender)
+	_ = userRepo.Create(ctx, receiver)
+
+	accounts := &MockAccountStore{accounts: map[int]*domain.Account{
+		1: {ID: 1, UserID: sender.ID, Number: "40817810000000000001", Balance: 100000, Status: domain.AccountStatusActive},
+		2: {ID: 2, UserID: receiver.ID, Number: "40817810000000000002", Status: domain.AccountStatusActive},
+		3: {ID: 3, UserID: receiver.ID, Number: "40817810000000000003", Status: domain.AccountStatusActive},
+		4: {ID: 4, UserID: sender.ID, Number: "40817810000000000004", Status: domain.AccountStatusActive},
+	}}
+	transactions := &MockTransactionRepository{accounts: accounts}
+	accessControl := &mockStoreAccessControl{accounts: accounts}
+	accountService := NewAccountService(cfg, accounts, transactions, nil, nil, nil, userRepo, accessControl, mockTxManager{}, nil, nil, mockFraudService{}, mockSanctionsService{}, mockKYCService{}, auditService, logger)
+	aliases := &MockPaymentAliasRepository{}
+	clock := &fakeClock{now: time.Now()}
+
+	svc := NewRecipientService(cfg, accounts, userRepo, aliases, transactions, accessControl, accountService, auditService, clock, logger)
+
+	return svc.(*recipientService), &recipientTestDeps{
+		accounts: accounts,
+		aliases:  aliases,
+		clock:    clock,
+		sender:   sender.ID,
+		receiver: receiver.ID,
+	}
+}
+
+func TestRecipientService_ResolveRecipient(t *testing.T) {
+	svc, deps := setupRecipientService(t)
+	ctx := context.Background()
+
+	t.Run("by account number", func(t *testing.T) {
+		recipient, err := svc.ResolveRecipient(ctx, deps.sender, domain.RecipientLookup{AccountNumber: " 40817810000000000003 "})
+		if err != nil {
+			t.Fatalf("ResolveRecipient failed: %v", err)
+		}
+		if recipient.AccountID != 3 || recipient.MaskedName != "I***v" || recipient.MaskedAccountNumber != "40817***********0003" {
+			t.Errorf("unexpected recipient: %+v", recipient)
+		}
+		if !recipient.NewRecipient || recipient.LimitRemaining == nil || *recipient.LimitRemaining != 15000 {
+			t.Errorf("expected new recipient with full limit, got %+v", recipient)
+		}
+	})
+
+	t.Run("by registered email uses oldest active account", func(t *testing.T) {
+		recipient, err := svc.ResolveRecipient(ctx, deps.sender, domain.RecipientLookup{Email: "Ivanov@Example.com"})
+		if err != nil {
+			t.Fatalf("ResolveRecipient failed: %v", err)
+		}
+		if recipient.AccountID != 2 {
+			t.Errorf("expected account 2, got %d", recipient.AccountID)
+		}
+	})
+
+	t.Run("by email alias", func(t *testing.T) {
+		if _, err := svc.CreateAlias(ctx, deps.receiver, domain.PaymentAlias{Type: domain.PaymentAliasEmail, Value: "ivanov@example.com", AccountID: 3}); err != nil {
+			t.Fatalf("CreateAlias failed: %v", err)
+		}
+		recipient, err := svc.ResolveRecipient(ctx, deps.sender, domain.RecipientLookup{Email: "ivanov@example.com"})
+		if err != nil {
+			t.Fatalf("ResolveRecipient failed: %v", err)
+		}
+		if recipient.AccountID != 3 {
+			t.Errorf("expected aliased account 3, got %d", recipient.AccountID)
+		}
+	})
+
+	t.Run("by phone alias", func(t *testing.T) {
+		if _, err := svc.CreateAlias(ctx, deps.receiver, domain.PaymentAlias{Type: domain.PaymentAliasPhone, Value: "+79991234567", AccountID: 2}); err != nil {
+			t.Fatalf("CreateAlias failed: %v", err)
+		}
+		recipient, err := svc.ResolveRecipient(ctx, deps.sender, domain.RecipientLookup{Phone: "+79991234567"})
+		if err != nil {
+			t.Fatalf("ResolveRecipient failed: %v", err)
+		}
+		if recipient.AccountID != 2 {
+			t.Errorf("expected account 2, got %d", recipient.AccountID)
+		}
+	})
+
+	t.Run("own account by id", func(t *testing.T) {
+		recipient, err := svc.ResolveRecipient(ctx, deps.sender, domain.RecipientLookup{AccountID: 4})
+		if err != nil {
+			t.Fatalf("ResolveRecipient failed: %v", err)
+		}
+		if !recipient.OwnAccount || recipient.NewRecipient || recipient.LimitRemaining != nil {
+			t.Errorf("expected own account without limit, got %+v", recipient)
+		}
+	})
+
+	notFound := []struct {
+		name   string
+		lookup domain.RecipientLookup
+	}{
+		{"foreign account by id", domain.RecipientLookup{AccountID: 2}},
+		{"missing account by id", domain.RecipientLookup{AccountID: 99}},
+		{"unknown number", domain.RecipientLookup{AccountNumber: "40817810999999999999"}},
+		{"unknown phone", domain.RecipientLookup{Phone: "+79990000000"}},
+		{"unknown email", domain.RecipientLookup{Email: "nobody@example.com"}},
+	}
+	for _, tt := range notFound {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := svc.ResolveRecipient(ctx, deps.sender, tt.lookup)
+			if !errors.Is(err, domain.ErrRecipientNotFound) {
+				t.Errorf("expected ErrRecipientNotFound, got %v", err)
+			}
+		})
+	}
+
+	t.Run("blocked account is not disclosed", func(t *testing.T) {
+		deps.accounts.accounts[3].Status = domain.AccountStatusBlocked
+		defer func() { deps.accounts.accounts[3].Status = domain.AccountStatusActive }()
+
+		_, err := svc.ResolveRecipient(ctx, deps.sender, domain.RecipientLookup{AccountNumber: "40817810000000000003"})
+		if !errors.Is(err, domain.ErrRecipientNotFound) {
+			t.Errorf("expected ErrRecipientNotFound, got %v", err)
+		}
+	})
+
+	invalid := []struct {
+		name   string
+		lookup domain.RecipientLookup
+		want   error
+	}{
+		{"empty", domain.RecipientLookup{}, domain.ErrInvalidRecipient},
+		{"two fields", domain.RecipientLookup{AccountNumber: "40817810000000000002", Phone: "+79991234567"}, domain.ErrInvalidRecipient},
+		{"short number", domain.RecipientLookup{AccountNumber: "408178"}, domain.ErrInvalidAccountNumber},
+		{"phone without plus", domain.RecipientLookup{Phone: "89991234567"}, domain.ErrInvalidPhoneNumber},
+	}
+	for _, tt := range invalid {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := svc.ResolveRecipient(ctx, deps.sender, tt.lookup)
+			if !errors.Is(err, tt.want) {
+				t.Errorf("expected %v, got %v", tt.want, err)
+			}
+		})
+	}
+}
+
+func TestRecipientService_NewRecipientLimit(t *testing.T) {
+	svc, deps := setupRecipientService(t)
+	ctx := context.Background()
+	lookup := domain.RecipientLookup{AccountNumber: "40817810000000000002"}
+
+	recipient, err := svc.Transfer(ctx, deps.sender, 1, lookup, 10000)
+	if err != nil {
+		t.Fatalf("first transfer failed: %v", err)
+	}
+	if recipient.LimitRemaining == nil || *recipient.LimitRemaining != 5000 {
+		t.Fatalf("expected 5000 limit remaining, got %v", recipient.LimitRemaining)
+	}
+
+	if _, err := svc.Transfer(ctx, deps.sender, 1, lookup, 6000); !errors.Is(err, domain.ErrNewRecipientLimitExceeded) {
+		t.Fatalf("expected ErrNewRecipientLimitExceeded, got %v", err)
+	}
+
+	if _, err := svc.Transfer(ctx, deps.sender, 1, lookup, 5000); err != nil {
+		t.Fatalf("transfer within limit failed: %v", err)
+	}
+
+	// Лимит действует на получателя, а не на счет списания
+	if _, err := svc.Transfer(ctx, deps.sender, 1, domain.RecipientLookup{AccountNumber: "40817810000000000003"}, 15000); err != nil {
+		t.Fatalf("transfer to another new recipient failed: %v", err)
+	}
+
+	// Через сутки после первого перевода получатель перестает быть новым
+	deps.clock.now = deps.clock.now.Add(25 * time.Hour)
+	recipient, err = svc.Transfer(ctx, deps.sender, 1, lookup, 20000)
+	if err != nil {
+		t.Fatalf("transfer to known recipient failed: %v", err)
+	}
+	if recipient.NewRecipient || recipient.LimitRemaining != nil {
+		t.Errorf("expected known recipient without limit, got %+v", recipient)
+	}
+
+	if deps.accounts.accounts[1].Balance != 50000 || deps.accounts.accounts[2].Balance != 35000 {
+		t.Errorf("unexpected balances: sender=%.2f receiver=%.2f", deps.accounts.accounts[1].Balance, deps.accounts.accounts[2].Balance)
+	}
+
+	// Переводы между своими счетами не ограничены
+	if _, err := svc.Transfer(ctx, deps.sender, 1, domain.RecipientLookup{AccountID: 4}, 30000); err != nil {
+		t.Errorf("own transfer failed: %v", err)
+	}
+}
+
+func TestRecipientService_TransferToAccount(t *testing.T) {
+	svc, deps := setupRecipientService(t)
+	ctx := context.Background()
+
+	if err := svc.TransferToAccount(ctx, deps.sender, 1, 2, 16000); !errors.Is(err, domain.ErrNewRecipientLimitExceeded) {
+		t.Fatalf("expected ErrNewRecipientLimitExceeded, got %v", err)
+	}
+	if err := svc.TransferToAccount(ctx, deps.sender, 1, 2, 15000); err != nil {
+		t.Fatalf("transfer within limit failed: %v", err)
+	}
+	if err := svc.TransferToAccount(ctx, deps.sender, 1, 2, 1); !errors.Is(err, domain.ErrNewRecipientLimitExceeded) {
+		t.Fatalf("expected exhausted limit, got %v", err)
+	}
+
+	// Несуществующий и закрытый счета неотличимы
+	if err := svc.TransferToAccount(ctx, deps.sender, 1, 99, 100); !errors.Is(err, domain.ErrRecipientNotFound) {
+		t.Errorf("expected ErrRecipientNotFound for missing account, got %v", err)
+	}
+	deps.accounts.accounts[3].Status = domain.AccountStatusClosed
+	if err := svc.TransferToAccount(ctx, deps.sender, 1, 3, 100); !errors.Is(err, domain.ErrRecipientNotFound) {
+		t.Errorf("expected ErrRecipientNotFound for closed account, got %v", err)
+	}
+
+	if err := svc.TransferToAccount(ctx, deps.sender, 1, 4, 30000); err != nil {
+		t.Errorf("own transfer failed: %v", err)
+	}
+}
+
+func TestRecipientService_CreateAlias(t *testing.T) {
+	svc, deps := setupRecipientService(t)
+	ctx := context.Background()
+
+	alias, err := svc.CreateAlias(ctx, deps.receiver, domain.PaymentAlias{Type: domain.PaymentAliasPhone, Value: " +79991234567 ", AccountID: 2})
+	if err != nil {
+		t.Fatalf("CreateAlias failed: %v", err)
+	}
+	if alias.Value != "+79991234567" || alias.UserID != deps.receiver {
+		t.Errorf("unexpected alias: %+v", alias)
+	}
+
+	tests := []struct {
+		name   string
+		userID int
+		alias  domain.PaymentAlias
+		want   error
+	}{
+		{"phone taken", deps.sender, domain.PaymentAlias{Type: domain.PaymentAliasPhone, Value: "+79991234567", AccountID: 1}, domain.ErrPaymentAliasTaken},
+		{"foreign email", deps.sender, domain.PaymentAlias{Type: domain.PaymentAliasEmail, Value: "ivanov@example.com", AccountID: 1}, domain.ErrPaymentAliasEmailMismatch},
+		{"invalid phone", deps.sender, domain.PaymentAlias{Type: domain.PaymentAliasPhone, Value: "12345", AccountID: 1}, domain.ErrInvalidPhoneNumber},
+		{"unknown type", deps.sender, domain.PaymentAlias{Type: "iban", Value: "x", AccountID: 1}, domain.ErrInvalidPaymentAliasType},
+		{"missing account", deps.sender, domain.PaymentAlias{Type: domain.PaymentAliasPhone, Value: "+79990000001", AccountID: 99}, ErrAccountNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := svc.CreateAlias(ctx, tt.userID, tt.alias)
+			if !errors.Is(err, tt.want) {
+				t.Errorf("expected %v, got %v", tt.want, err)
+			}
+		})
+	}
+
+	t.Run("foreign account", func(t *testing.T) {
+		_, err := svc.CreateAlias(ctx, deps.sender, domain.PaymentAlias{Type: domain.PaymentAliasPhone, Value: "+79990000002", AccountID: 2})
+		var serviceErr *ServiceError
+		if !errors.As(err, &serviceErr) || serviceErr.Code != http.StatusForbidden {
+			t.Errorf("expected 403 service error, got %v", err)
+		}
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		if err := svc.DeleteAlias(ctx, deps.sender, alias.ID); !errors.Is(err, domain.ErrPaymentAliasNotFound) {
+			t.Errorf("expected ErrPaymentAliasNotFound for another user, got %v", err)
+		}
+		if err := svc.DeleteAlias(ctx, deps.receiver, alias.ID); err != nil {
+			t.Fatalf("DeleteAlias failed: %v", err)
+		}
+		aliases, _ := svc.GetAliases(ctx, deps.receiver)
+		if len(aliases) != 0 {
+			t.Errorf("expected no aliases, got %d", len(aliases))
+		}
+	})
+}
@@ -0,0 +1,157 @@
+package service
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"github.com/beevik/etree"
+
+	"github.com/vterdunov/learn-bank-app/internal/domain"
+)
+
+// sanctionsCSVColumns колонки CSV-файла списка; обязательны id и name, псевдонимы перечисляются через ";"
+var sanctionsCSVColumns = []string{"id", "name", "aliases", "birth_date", "country", "program"}
+
+// sanctionsListName возвращает имя списка по пути к файлу: имя файла без расширения
+func sanctionsListName(path string) string {
+	base := filepath.Base(path)
+	return strings.TrimSuffix(base, filepath.Ext(base))
+}
+
+// parseSanctionsList разбирает файл списка по расширению (.csv или .xml)
+func parseSanctionsList(path string, data []byte) ([]*domain.SanctionsEntry, error) {
+	var (
+		entries []*domain.SanctionsEntry
+		err     error
+	)
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".csv":
+		entries, err = parseSanctionsCSV(data)
+	case ".xml":
+		entries, err = parseSanctionsXML(data)
+	default:
+		return nil, fmt.Errorf("%w: unsupported file extension %q", domain.ErrInvalidSanctionsListFormat, filepath.Ext(path))
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// ID записи должен быть уникален в пределах списка: по нему хранятся решения оператора
+	seen := make(map[string]bool, len(entries))
+	for i, entry := range entries {
+		if entry.ExternalID == "" || entry.Name == "" {
+			return nil, fmt.Errorf("%w: entry %d has no id or name", domain.ErrInvalidSanctionsListFormat, i+1)
+		}
+		if seen[entry.ExternalID] {
+			return nil, fmt.Errorf("%w: duplicate entry id %q", domain.ErrInvalidSanctionsListFormat, entry.ExternalID)
+		}
+		seen[entry.ExternalID] = true
+	}
+
+	return entries, nil
+}
+
+// parseSanctionsCSV разбирает CSV с заголовком; порядок колонок произвольный, неизвестные колонки пропускаются
+func parseSanctionsCSV(data []byte) ([]*domain.SanctionsEntry, error) {
+	reader := csv.NewReader(bytes.NewReader(bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))))
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("%w: failed to read CSV header: %v", domain.ErrInvalidSanctionsListFormat, err)
+	}
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	for _, required := range sanctionsCSVColumns[:2] {
+		if _, ok := columns[required]; !ok {
+			return nil, fmt.Errorf("%w: CSV column %q is missing", domain.ErrInvalidSanctionsListFormat, required)
+		}
+	}
+
+	var entries []*domain.SanctionsEntry
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", domain.ErrInvalidSanctionsListFormat, err)
+		}
+
+		field := func(name string) string {
+			i, ok := columns[name]
+			if !ok || i >= len(record) {
+				return ""
+			}
+			return strings.TrimSpace(record[i])
+		}
+
+		var aliases []string
+		for _, alias := range strings.Split(field("aliases"), ";") {
+			if alias = strings.TrimSpace(alias); alias != "" {
+				aliases = append(aliases, alias)
+			}
+		}
+
+		entries = append(entries, &domain.SanctionsEntry{
+			ExternalID: field("id"),
+			Name:       field("name"),
+			Aliases:    aliases,
+			BirthDate:  field("birth_date"),
+			Country:    field("country"),
+			Program:    field("program"),
+		})
+	}
+
+	return entries, nil
+}
+
+// parseSanctionsXML разбирает XML вида
+// <sanctionsList><entry id="..."><name/><alias/>...<birthDate/><country/><program/></entry></sanctionsList>
+func parseSanctionsXML(data []byte) ([]*domain.SanctionsEntry, error) {
+	doc := etree.NewDocument()
+	if err := doc.ReadFromBytes(data); err != nil {
+		return nil, fmt.Errorf("%w: failed to parse XML: %v", domain.ErrInvalidSanctionsListFormat, err)
+	}
+
+	root := doc.SelectElement("sanctionsList")
+	if root == nil {
+		return nil, fmt.Errorf("%w: root element sanctionsList not found", domain.ErrInvalidSanctionsListFormat)
+	}
+
+	text := func(element *etree.Element, tag string) string {
+		if child := element.SelectElement(tag); child != nil {
+			return strings.TrimSpace(child.Text())
+		}
+		return ""
+	}
+
+	var entries []*domain.SanctionsEntry
+	for _, element := range root.SelectElements("entry") {
+		var aliases []string
+		for _, alias := range element.SelectElements("alias") {
+			if value := strings.TrimSpace(alias.Text()); value != "" {
+				aliases = append(aliases, value)
+			}
+		}
+
+		entries = append(entries, &domain.SanctionsEntry{
+			ExternalID: strings.TrimSpace(element.SelectAttrValue("id", "")),
+			Name:       text(element, "name"),
+			Aliases:    aliases,
+			BirthDate:  text(element, "birthDate"),
+			Country:    text(element, "country"),
+			Program:    text(element, "program"),
+		})
+	}
+
+	return entries, nil
+}
@@ -0,0 +1,450 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"log/slog"
+	"math"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/vterdunov/learn-bank-app/internal/config"
+	"github.com/vterdunov/learn-bank-app/internal/domain"
+	"github.com/vterdunov/learn-bank-app/internal/repository"
+	"github.com/vterdunov/learn-bank-app/internal/utils"
+	"github.com/vterdunov/learn-bank-app/pkg/logger"
+)
+
+const (
+	// defaultSanctionsPageSize размер страницы очереди совпадений по умолчанию
+	defaultSanctionsPageSize = 50
+	// maxSanctionsPageSize максимальный размер страницы очереди совпадений
+	maxSanctionsPageSize = 500
+	// sanctionsRescreenBatchSize число клиентов, загружаемых за раз при повторной проверке
+	sanctionsRescreenBatchSize = 500
+)
+
+// sanctionsName имя или псевдоним записи списка с нормализованными словами
+type sanctionsName struct {
+	value  string
+	tokens []string
+}
+
+// sanctionsCandidate запись списка, подготовленная для сравнения имен
+type sanctionsCandidate struct {
+	listName string
+	entry    *domain.SanctionsEntry
+	names    []sanctionsName
+}
+
+// sanctionsIndex записи всех списков в памяти; key описывает версии списков, из которых он построен
+type sanctionsIndex struct {
+	key        string
+	candidates []*sanctionsCandidate
+}
+
+// sanctionsService проверяет клиентов по санкционным спискам из локальных файлов.
+// Клиент проверяется при регистрации, как получатель перевода и заново при изменении списка.
+// Возможные совпадения попадают в очередь оператора: подтвержденное совпадение запрещает переводы
+// клиенту, пока совпадение на проверке, переводы ему откладываются.
+type sanctionsService struct {
+	sanctionsRepo repository.SanctionsRepository
+	userRepo      repository.UserRepository
+	auditService  AuditService
+	cfg           config.SanctionsConfig
+	logger        *slog.Logger
+
+	mu    sync.Mutex
+	index *sanctionsIndex
+}
+
+// NewSanctionsService создает новый экземпляр SanctionsService
+func NewSanctionsService(
+	cfg *config.Config,
+	sanctionsRepo repository.SanctionsRepository,
+	userRepo repository.UserRepository,
+	auditService AuditService,
+	lg *slog.Logger,
+) (SanctionsService, error) {
+	if cfg.Sanctions.MatchThreshold <= 0 || cfg.Sanctions.MatchThreshold > 1 {
+		return nil, fmt.Errorf("invalid sanctions match threshold %v", cfg.Sanctions.MatchThreshold)
+	}
+	names := make(map[string]bool, len(cfg.Sanctions.ListFiles))
+	for _, path := range cfg.Sanctions.ListFiles {
+		name := sanctionsListName(path)
+		if names[name] {
+			return nil, fmt.Errorf("duplicate sanctions list name %q", name)
+		}
+		names[name] = true
+	}
+
+	return &sanctionsService{
+		sanctionsRepo: sanctionsRepo,
+		userRepo:      userRepo,
+		auditService:  auditService,
+		cfg:           cfg.Sanctions,
+		logger:        logger.WithService(lg, "sanctions_service"),
+	}, nil
+}
+
+// ScreenUser сравнивает имя клиента с записями списков и ставит новые совпадения в очередь оператора.
+// Возвращает только новые совпадения: по уже известным решение оператора не пересматривается.
+func (s *sanctionsService) ScreenUser(ctx context.Context, user *domain.User, source string) ([]*domain.SanctionsHit, error) {
+	index, err := s.currentIndex(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return s.screen(ctx, index, user, source)
+}
+
+// CheckCounterparty проверяет получателя перевода. Возвращает domain.ErrSanctionsMatch при подтвержденном
+// совпадении и domain.ErrSanctionsReviewPending, пока совпадение ждет решения оператора.
+func (s *sanctionsService) CheckCounterparty(ctx context.Context, userID int) error {
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		s.logger.Error("Failed to get counterparty for sanctions screening", "user_id", userID, "error", err)
+		return fmt.Errorf("failed to get counterparty: %w", err)
+	}
+
+	if _, err := s.ScreenUser(ctx, user, domain.SanctionsSourceTransfer); err != nil {
+		return err
+	}
+
+	hits, err := s.sanctionsRepo.GetUserHits(ctx, userID)
+	if err != nil {
+		s.logger.Error("Failed to get sanctions hits", "user_id", userID, "error", err)
+		return fmt.Errorf("failed to get sanctions hits: %w", err)
+	}
+
+	pending := false
+	for _, hit := range hits {
+		switch hit.Status {
+		case domain.SanctionsHitStatusConfirmed:
+			s.logger.Warn("Transfer to sanctioned counterparty rejected", "user_id", userID, "hit_id", hit.ID, "list", hit.ListName)
+			return domain.ErrSanctionsMatch
+		case domain.SanctionsHitStatusPending:
+			pending = true
+		}
+	}
+	if pending {
+		s.logger.Warn("Transfer to counterparty with pending sanctions hit", "user_id", userID)
+		return domain.ErrSanctionsReviewPending
+	}
+
+	return nil
+}
+
+// RefreshLists перечитывает файлы списков и при изменении хотя бы одного заново проверяет всех клиентов.
+// Новая версия списка сохраняется после повторной проверки: если проверка прервется,
+// следующий запуск увидит изменение снова и повторит ее.
+func (s *sanctionsService) RefreshLists(ctx context.Context) (*JobResult, error) {
+	stored, err := s.sanctionsRepo.ListLists(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get sanctions lists: %w", err)
+	}
+	checksums := make(map[string]string, len(stored))
+	for _, list := range stored {
+		checksums[list.Name] = list.Checksum
+	}
+
+	type loadedList struct {
+		list    *domain.SanctionsList
+		entries []*domain.SanctionsEntry
+	}
+	var changed []loadedList
+	result := &JobResult{}
+	for _, path := range s.cfg.ListFiles {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			s.logger.Error("Failed to read sanctions list", "path", path, "error", err)
+			result.Failed++
+			continue
+		}
+
+		sum := sha256.Sum256(data)
+		list := &domain.SanctionsList{Name: sanctionsListName(path), Source: path, Checksum: hex.EncodeToString(sum[:])}
+		if checksums[list.Name] == list.Checksum {
+			continue
+		}
+
+		entries, err := parseSanctionsList(path, data)
+		if err != nil {
+			s.logger.Error("Failed to parse sanctions list", "path", path, "error", err)
+			result.Failed++
+			continue
+		}
+		changed = append(changed, loadedList{list: list, entries: entries})
+	}
+	if len(changed) == 0 {
+		return result, nil
+	}
+
+	// Индекс из сохраненных версий неизмененных списков и новых версий измененных
+	entries, err := s.sanctionsRepo.ListEntries(ctx)
+	if err != nil {
+		return result, fmt.Errorf("failed to get sanctions entries: %w", err)
+	}
+	listNames := make(map[int]string, len(stored))
+	for _, list := range stored {
+		listNames[list.ID] = list.Name
+	}
+	byList := make(map[string][]*domain.SanctionsEntry)
+	for _, entry := range entries {
+		byList[listNames[entry.ListID]] = append(byList[listNames[entry.ListID]], entry)
+	}
+	for _, loaded := range changed {
+		byList[loaded.list.Name] = loaded.entries
+	}
+	index := buildSanctionsIndex("", byList)
+
+	processed, failed, err := s.rescreenAll(ctx, index)
+	result.Processed += processed
+	result.Failed += failed
+	if err != nil {
+		return result, err
+	}
+
+	for _, loaded := range changed {
+		if err := s.sanctionsRepo.ReplaceList(ctx, loaded.list, loaded.entries); err != nil {
+			s.logger.Error("Failed to save sanctions list", "list", loaded.list.Name, "error", err)
+			result.Failed++
+			continue
+		}
+		s.logger.Info("Sanctions list loaded",
+			"list", loaded.list.Name,
+			"source", loaded.list.Source,
+			"entries", loaded.list.EntriesCount,
+			"checksum", loaded.list.Checksum)
+	}
+
+	return result, nil
+}
+
+// rescreenAll проверяет всех клиентов по индексу, обходя их страницами по ID
+func (s *sanctionsService) rescreenAll(ctx context.Context, index *sanctionsIndex) (processed, failed int, err error) {
+	afterID := 0
+	for {
+		if err := ctx.Err(); err != nil {
+			return processed, failed, err
+		}
+
+		users, err := s.userRepo.ListAfterID(ctx, afterID, sanctionsRescreenBatchSize)
+		if err != nil {
+			return processed, failed, fmt.Errorf("failed to list users: %w", err)
+		}
+		if len(users) == 0 {
+			break
+		}
+
+		for _, user := range users {
+			if _, err := s.screen(ctx, index, user, domain.SanctionsSourceRescreen); err != nil {
+				failed++
+				continue
+			}
+			processed++
+		}
+		afterID = users[len(users)-1].ID
+	}
+
+	s.logger.Info("Users rescreened against sanctions lists", "processed", processed, "failed", failed)
+	return processed, failed, nil
+}
+
+// screen сравнивает имя клиента с записями индекса и сохраняет новые совпадения.
+// Совпадения сохраняются вне транзакции вызывающего, которая может быть откачена из-за отказа в переводе.
+func (s *sanctionsService) screen(ctx context.Context, index *sanctionsIndex, user *domain.User, source string) ([]*domain.SanctionsHit, error) {
+	ctx = repository.DetachTx(ctx)
+
+	var created []*domain.SanctionsHit
+	for _, hit := range index.match(user.Username, s.cfg.MatchThreshold) {
+		hit.UserID = user.ID
+		hit.Source = source
+
+		isNew, err := s.sanctionsRepo.CreateHit(ctx, hit)
+		if err != nil {
+			s.logger.Error("Failed to create sanctions hit", "user_id", user.ID, "list", hit.ListName, "error", err)
+			return nil, fmt.Errorf("failed to create sanctions hit: %w", err)
+		}
+		if !isNew {
+			continue
+		}
+
+		s.logger.Warn("Potential sanctions match",
+			"hit_id", hit.ID,
+			"user_id", user.ID,
+			"list", hit.ListName,
+			"entry_id", hit.EntryExternalID,
+			"score", hit.Score,
+			"source", source)
+		created = append(created, hit)
+	}
+
+	return created, nil
+}
+
+// currentIndex возвращает индекс сохраненных списков, перестраивая его, если списки изменились
+func (s *sanctionsService) currentIndex(ctx context.Context) (*sanctionsIndex, error) {
+	lists, err := s.sanctionsRepo.ListLists(ctx)
+	if err != nil {
+		s.logger.Error("Failed to get sanctions lists", "error", err)
+		return nil, fmt.Errorf("failed to get sanctions lists: %w", err)
+	}
+
+	keyParts := make([]string, 0, len(lists))
+	listNames := make(map[int]string, len(lists))
+	for _, list := range lists {
+		keyParts = append(keyParts, list.Name+":"+list.Checksum)
+		listNames[list.ID] = list.Name
+	}
+	key := strings.Join(keyParts, ",")
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.index != nil && s.index.key == key {
+		return s.index, nil
+	}
+
+	entries, err := s.sanctionsRepo.ListEntries(ctx)
+	if err != nil {
+		s.logger.Error("Failed to get sanctions entries", "error", err)
+		return nil, fmt.Errorf("failed to get sanctions entries: %w", err)
+	}
+	byList := make(map[string][]*domain.SanctionsEntry, len(lists))
+	for _, entry := range entries {
+		name, ok := listNames[entry.ListID]
+		if !ok {
+			continue
+		}
+		byList[name] = append(byList[name], entry)
+	}
+
+	s.index = buildSanctionsIndex(key, byList)
+	s.logger.Info("Sanctions index rebuilt", "lists", len(lists), "entries", len(s.index.candidates))
+
+	return s.index, nil
+}
+
+// buildSanctionsIndex нормализует имена и псевдонимы записей для сравнения
+func buildSanctionsIndex(key string, byList map[string][]*domain.SanctionsEntry) *sanctionsIndex {
+	index := &sanctionsIndex{key: key}
+	for listName, entries := range byList {
+		for _, entry := range entries {
+			candidate := &sanctionsCandidate{listName: listName, entry: entry}
+			for _, value := range append([]string{entry.Name}, entry.Aliases...) {
+				if tokens := utils.NormalizeName(value); len(tokens) > 0 {
+					candidate.names = append(candidate.names, sanctionsName{value: value, tokens: tokens})
+				}
+			}
+			if len(candidate.names) > 0 {
+				index.candidates = append(index.candidates, candidate)
+			}
+		}
+	}
+	return index
+}
+
+// match возвращает записи, одно из имен которых похоже на name не меньше чем на threshold
+func (idx *sanctionsIndex) match(name string, threshold float64) []*domain.SanctionsHit {
+	tokens := utils.NormalizeName(name)
+	if len(tokens) == 0 {
+		return nil
+	}
+
+	var hits []*domain.SanctionsHit
+	for _, candidate := range idx.candidates {
+		var best *sanctionsName
+		bestScore := 0.0
+		for i := range candidate.names {
+			if score := utils.TokensSimilarity(tokens, candidate.names[i].tokens); score > bestScore {
+				best, bestScore = &candidate.names[i], score
+			}
+		}
+		if best == nil || bestScore < threshold {
+			continue
+		}
+
+		hits = append(hits, &domain.SanctionsHit{
+			ListName:        candidate.listName,
+			EntryExternalID: candidate.entry.ExternalID,
+			EntryName:       candidate.entry.Name,
+			MatchedName:     best.value,
+			Score:           math.Round(bestScore*10000) / 10000,
+		})
+	}
+	return hits
+}
+
+// ListLists возвращает загруженные санкционные списки
+func (s *sanctionsService) ListLists(ctx context.Context) ([]*domain.SanctionsList, error) {
+	lists, err := s.sanctionsRepo.ListLists(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get sanctions lists: %w", err)
+	}
+	return lists, nil
+}
+
+// ListHits возвращает совпадения с указанным статусом (по умолчанию pending)
+func (s *sanctionsService) ListHits(ctx context.Context, status string, limit, offset int) ([]*domain.SanctionsHit, error) {
+	if status == "" {
+		status = domain.SanctionsHitStatusPending
+	}
+	if !domain.IsValidSanctionsHitStatus(status) {
+		return nil, domain.ErrInvalidSanctionsHitStatus
+	}
+
+	if limit <= 0 {
+		limit = defaultSanctionsPageSize
+	}
+	if limit > maxSanctionsPageSize {
+		limit = maxSanctionsPageSize
+	}
+
+	hits, err := s.sanctionsRepo.ListHits(ctx, status, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list sanctions hits: %w", err)
+	}
+
+	return hits, nil
+}
+
+// ConfirmHit подтверждает совпадение: переводы клиенту запрещаются
+func (s *sanctionsService) ConfirmHit(ctx context.Context, adminID, hitID int, comment string) (*domain.SanctionsHit, error) {
+	return s.resolve(ctx, adminID, hitID, domain.SanctionsHitStatusConfirmed, domain.AuditActionAdminSanctionsConfirm, comment)
+}
+
+// ClearHit снимает подозрение: совпадение ложное
+func (s *sanctionsService) ClearHit(ctx context.Context, adminID, hitID int, comment string) (*domain.SanctionsHit, error) {
+	return s.resolve(ctx, adminID, hitID, domain.SanctionsHitStatusCleared, domain.AuditActionAdminSanctionsClear, comment)
+}
+
+// resolve фиксирует решение оператора и записывает его в журнал аудита
+func (s *sanctionsService) resolve(ctx context.Context, adminID, hitID int, status, action, comment string) (*domain.SanctionsHit, error) {
+	hit, err := s.sanctionsRepo.ResolveHit(ctx, hitID, status, adminID, strings.TrimSpace(comment))
+	if err != nil {
+		s.logger.Warn("Failed to resolve sanctions hit", "hit_id", hitID, "admin_id", adminID, "status", status, "error", err)
+		return nil, err
+	}
+
+	s.logger.Info("Sanctions hit resolved", "hit_id", hitID, "admin_id", adminID, "user_id", hit.UserID, "status", status)
+
+	event := NewUserAuditEvent(adminID, action, "sanctions_hit", auditResourceID(hitID))
+	event.ActorType = domain.AuditActorAdmin
+	event.Before = domain.NewAuditState(map[string]interface{}{"status": domain.SanctionsHitStatusPending})
+	event.After = domain.NewAuditState(map[string]interface{}{"status": hit.Status})
+	event.Metadata = domain.NewAuditState(map[string]interface{}{
+		"user_id":    hit.UserID,
+		"list_name":  hit.ListName,
+		"entry_id":   hit.EntryExternalID,
+		"entry_name": hit.EntryName,
+		"score":      hit.Score,
+		"comment":    hit.Comment,
+	})
+	// Ошибка аудита уже залогирована, решение по совпадению сохранено
+	_ = s.auditService.Record(ctx, event)
+
+	return hit, nil
+}
@@ -0,0 +1,450 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/vterdunov/learn-bank-app/internal/config"
+	"github.com/vterdunov/learn-bank-app/internal/domain"
+)
+
+// mockSanctionsService пропускает всех клиентов без проверки
+type mockSanctionsService struct{}
+
+func (mockSanctionsService) ScreenUser(ctx context.Context, user *domain.User, source string) ([]*domain.SanctionsHit, error) {
+	return nil, nil
+}
+
+func (mockSanctionsService) CheckCounterparty(ctx context.Context, userID int) error {
+	return nil
+}
+
+func (mockSanctionsService) RefreshLists(ctx context.Context) (*JobResult, error) {
+	return &JobResult{}, nil
+}
+
+func (mockSanctionsService) ListLists(ctx context.Context) ([]*domain.SanctionsList, error) {
+	return nil, nil
+}
+
+func (mockSanctionsService) ListHits(ctx context.Context, status string, limit, offset int) ([]*domain.SanctionsHit, error) {
+	return nil, nil
+}
+
+func (mockSanctionsService) ConfirmHit(ctx context.Context, adminID, hitID int, comment string) (*domain.SanctionsHit, error) {
+	return nil, domain.ErrSanctionsHitNotFound
+}
+
+func (mockSanctionsService) ClearHit(ctx context.Context, adminID, hitID int, comment string) (*domain.SanctionsHit, error) {
+	return nil, domain.ErrSanctionsHitNotFound
+}
+
+// MockSanctionsRepository списки и совпадения в памяти
+type MockSanctionsRepository struct {
+	lists   []*domain.SanctionsList
+	entries []*domain.SanctionsEntry
+	hits    []*domain.SanctionsHit
+	// entryLoads число загрузок записей: по нему проверяется кеширование индекса
+	entryLoads int
+}
+
+func (m *MockSanctionsRepository) ListLists(ctx context.Context) ([]*domain.SanctionsList, error) {
+	return m.lists, nil
+}
+
+func (m *MockSanctionsRepository) ReplaceList(ctx context.Context, list *domain.SanctionsList, entries []*domain.SanctionsEntry) error {
+	list.ID = len(m.lists) + 1
+	for i, existing := range m.lists {
+		if existing.Name == list.Name {
+			list.ID = existing.ID
+			m.lists = append(m.lists[:i], m.lists[i+1:]...)
+			break
+		}
+	}
+	list.EntriesCount = len(entries)
+	list.LoadedAt = time.Now()
+	m.lists = append(m.lists, list)
+
+	kept := m.entries[:0]
+	for _, entry := range m.entries {
+		if entry.ListID != list.ID {
+			kept = append(kept, entry)
+		}
+	}
+	for _, entry := range entries {
+		entry.ListID = list.ID
+		kept = append(kept, entry)
+	}
+	m.entries = kept
+	return nil
+}
+
+func (m *MockSanctionsRepository) ListEntries(ctx context.Context) ([]*domain.SanctionsEntry, error) {
+	m.entryLoads++
+	return m.entries, nil
+}
+
+func (m *MockSanctionsRepository) CreateHit(ctx context.Context, hit *domain.SanctionsHit) (bool, error) {
+	for _, existing := range m.hits {
+		if existing.UserID == hit.UserID && existing.ListName == hit.ListName && existing.EntryExternalID == hit.EntryExternalID {
+			return false, nil
+		}
+	}
+	hit.ID = len(m.hits) + 1
+	hit.Status = domain.SanctionsHitStatusPending
+	hit.CreatedAt = time.Now()
+	m.hits = append(m.hits, hit)
+	return true, nil
+}
+
+func (m *MockSanctionsRepository) GetHit(ctx context.Context, id int) (*domain.SanctionsHit, error) {
+	for _, hit := range m.hits {
+		if hit.ID == id {
+			return hit, nil
+		}
+	}
+	return nil, domain.ErrSanctionsHitNotFound
+}
+
+func (m *MockSanctionsRepository) ListHits(ctx context.Context, status string, limit, offset int) ([]*domain.SanctionsHit, error) {
+	var hits []*domain.SanctionsHit
+	for _, hit := range m.hits {
+		if hit.Status == status {
+			hits = append(hits, hit)
+		}
+	}
+	return hits, nil
+}
+
+func (m *MockSanctionsRepository) GetUserHits(ctx context.Context, userID int) ([]*domain.SanctionsHit, error) {
+	var hits []*domain.SanctionsHit
+	for _, hit := range m.hits {
+		if hit.UserID == userID {
+			hits = append(hits, hit)
+		}
+	}
+	return hits, nil
+}
+
+func (m *MockSanctionsRepository) ResolveHit(ctx context.Context, id int, status string, reviewerID int, comment string) (*domain.SanctionsHit, error) {
+	hit, err := m.GetHit(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if hit.Status != domain.SanctionsHitStatusPending {
+		return nil, domain.ErrSanctionsHitResolved
+	}
+	now := time.Now()
+	hit.Status = status
+	hit.ReviewerID = &reviewerID
+	hit.Comment = comment
+	hit.ReviewedAt = &now
+	return hit, nil
+}
+
+// copySanctionsTestdata копирует файлы списков во временный каталог, чтобы тест мог их менять
+func copySanctionsTestdata(t *testing.T, names ...string) []string {
+	t.Helper()
+	dir := t.TempDir()
+	var paths []string
+	for _, name := range names {
+		data, err := os.ReadFile(filepath.Join("testdata", "sanctions", name))
+		if err != nil {
+			t.Fatalf("failed to read testdata: %v", err)
+		}
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, data, 0o600); err != nil {
+			t.Fatalf("failed to write list file: %v", err)
+		}
+		paths = append(paths, path)
+	}
+	return paths
+}
+
+func setupSanctionsService(t *testing.T, files []string) (*sanctionsService, *MockSanctionsRepository, *MockUserRepository, *MockAuditRepository) {
+	t.Helper()
+	cfg := &config.Config{Sanctions: config.SanctionsConfig{ListFiles: files, MatchThreshold: 0.88}}
+	sanctionsRepo := &MockSanctionsRepository{}
+	users := NewMockUserRepository()
+	auditService, auditRepo := setupAuditService()
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+
+	svc, err := NewSanctionsService(cfg, sanctionsRepo, users, auditService, logger)
+	if err != nil {
+		t.Fatalf("NewSanctionsService() error = %v", err)
+	}
+	return svc.(*sanctionsService), sanctionsRepo, users, auditRepo
+}
+
+func createSanctionsTestUser(t *testing.T, users *MockUserRepository, username string) *domain.User {
+	t.Helper()
+	user := &domain.User{Username: username, Email: username + "@example.com"}
+	if err := users.Create(context.Background(), user); err != nil {
+		t.Fatalf("failed to create user: %v", err)
+	}
+	return user
+}
+
+func TestParseSanctionsList(t *testing.T) {
+	for _, name := range []string{"national.csv", "international.xml"} {
+		data, err := os.ReadFile(filepath.Join("testdata", "sanctions", name))
+		if err != nil {
+			t.Fatalf("failed to read testdata: %v", err)
+		}
+
+		entries, err := parseSanctionsList(name, data)
+		if err != nil {
+			t.Fatalf("parseSanctionsList(%s) error = %v", name, err)
+		}
+		if len(entries) != 2 {
+			t.Fatalf("%s: expected 2 entries, got %d", name, len(entries))
+		}
+		if entries[0].ExternalID == "" || entries[0].Name == "" || len(entries[0].Aliases) != 2 || entries[0].Program == "" {
+			t.Errorf("%s: first entry parsed incompletely: %+v", name, entries[0])
+		}
+		if len(entries[1].Aliases) != 0 {
+			t.Errorf("%s: second entry must have no aliases, got %v", name, entries[1].Aliases)
+		}
+	}
+
+	invalid := map[string]string{
+		"no_name.csv":   "id,country\n1,RU\n",
+		"empty_id.csv":  "id,name\n,Ivan Petrov\n",
+		"duplicate.csv": "id,name\n1,Ivan Petrov\n1,Petr Ivanov\n",
+		"wrong.xml":     "<list><entry id=\"1\"><name>Ivan</name></entry></list>",
+		"list.json":     "[]",
+	}
+	for name, content := range invalid {
+		if _, err := parseSanctionsList(name, []byte(content)); !errors.Is(err, domain.ErrInvalidSanctionsListFormat) {
+			t.Errorf("parseSanctionsList(%s): expected ErrInvalidSanctionsListFormat, got %v", name, err)
+		}
+	}
+}
+
+func TestSanctionsService_RefreshListsRescreensUsers(t *testing.T) {
+	files := copySanctionsTestdata(t, "national.csv", "international.xml")
+	svc, repo, users, _ := setupSanctionsService(t, files)
+	ctx := context.Background()
+
+	ivanov := createSanctionsTestUser(t, users, "pyotr_ivanov")
+	hodorkovsky := createSanctionsTestUser(t, users, "Юрий Ходорковский")
+	createSanctionsTestUser(t, users, "ivan_sidorov")
+
+	result, err := svc.RefreshLists(ctx)
+	if err != nil {
+		t.Fatalf("RefreshLists() error = %v", err)
+	}
+	if result.Processed != 3 || result.Failed != 0 {
+		t.Errorf("expected 3 users rescreened, got %+v", result)
+	}
+	if len(repo.lists) != 2 || len(repo.entries) != 4 {
+		t.Fatalf("expected 2 lists with 4 entries, got %d lists, %d entries", len(repo.lists), len(repo.entries))
+	}
+
+	if len(repo.hits) != 2 {
+		t.Fatalf("expected 2 hits, got %+v", repo.hits)
+	}
+	for _, hit := range repo.hits {
+		if hit.Source != domain.SanctionsSourceRescreen || hit.Status != domain.SanctionsHitStatusPending {
+			t.Errorf("unexpected hit state: %+v", hit)
+		}
+		switch hit.UserID {
+		case ivanov.ID:
+			if hit.ListName != "national" || hit.EntryExternalID != "RU-001" {
+				t.Errorf("unexpected hit for ivanov: %+v", hit)
+			}
+		case hodorkovsky.ID:
+			if hit.ListName != "international" || hit.EntryExternalID != "INT-10" || hit.Score != 1 {
+				t.Errorf("unexpected hit for hodorkovsky: %+v", hit)
+			}
+		default:
+			t.Errorf("unexpected hit for user %d", hit.UserID)
+		}
+	}
+
+	// Списки не изменились: повторной проверки нет
+	result, err = svc.RefreshLists(ctx)
+	if err != nil {
+		t.Fatalf("RefreshLists() error = %v", err)
+	}
+	if result.Processed != 0 {
+		t.Errorf("unchanged lists must not trigger rescreening, got %+v", result)
+	}
+
+	// Новая запись в списке: все клиенты проверяются заново, известные совпадения не дублируются
+	sidorov := "id,name,aliases\nRU-001,Иванов Пётр Сергеевич,Petr Ivanov\nRU-003,Сидоров Иван,\n"
+	if err := os.WriteFile(files[0], []byte(sidorov), 0o600); err != nil {
+		t.Fatalf("failed to update list: %v", err)
+	}
+	result, err = svc.RefreshLists(ctx)
+	if err != nil {
+		t.Fatalf("RefreshLists() error = %v", err)
+	}
+	if result.Processed != 3 {
+		t.Errorf("changed list must trigger rescreening, got %+v", result)
+	}
+	if len(repo.hits) != 3 || repo.hits[2].EntryExternalID != "RU-003" {
+		t.Errorf("expected one new hit for RU-003, got %+v", repo.hits)
+	}
+	if len(repo.lists) != 2 || len(repo.entries) != 4 {
+		t.Errorf("list version must be replaced, got %d lists, %d entries", len(repo.lists), len(repo.entries))
+	}
+}
+
+func TestSanctionsService_RefreshListsSkipsBrokenFile(t *testing.T) {
+	files := copySanctionsTestdata(t, "national.csv")
+	broken := filepath.Join(t.TempDir(), "broken.csv")
+	if err := os.WriteFile(broken, []byte("id,country\n1,RU\n"), 0o600); err != nil {
+		t.Fatalf("failed to write list file: %v", err)
+	}
+	files = append(files, broken, filepath.Join(t.TempDir(), "missing.xml"))
+
+	svc, repo, _, _ := setupSanctionsService(t, files)
+	result, err := svc.RefreshLists(context.Background())
+	if err != nil {
+		t.Fatalf("RefreshLists() error = %v", err)
+	}
+	if result.Failed != 2 {
+		t.Errorf("expected 2 failed files, got %+v", result)
+	}
+	if len(repo.lists) != 1 || repo.lists[0].Name != "national" {
+		t.Errorf("valid list must be loaded, got %+v", repo.lists)
+	}
+}
+
+func TestSanctionsService_CheckCounterparty(t *testing.T) {
+	svc, repo, users, auditRepo := setupSanctionsService(t, copySanctionsTestdata(t, "international.xml"))
+	ctx := context.Background()
+
+	if _, err := svc.RefreshLists(ctx); err != nil {
+		t.Fatalf("RefreshLists() error = %v", err)
+	}
+
+	clean := createSanctionsTestUser(t, users, "maria_kuznetsova")
+	if err := svc.CheckCounterparty(ctx, clean.ID); err != nil {
+		t.Errorf("clean counterparty must pass, got %v", err)
+	}
+
+	// Клиент зарегистрирован после загрузки списка: совпадение находится при переводе ему
+	suspect := createSanctionsTestUser(t, users, "john.smith")
+	if err := svc.CheckCounterparty(ctx, suspect.ID); !errors.Is(err, domain.ErrSanctionsReviewPending) {
+		t.Fatalf("expected ErrSanctionsReviewPending, got %v", err)
+	}
+	if len(repo.hits) != 1 || repo.hits[0].Source != domain.SanctionsSourceTransfer {
+		t.Fatalf("expected transfer hit, got %+v", repo.hits)
+	}
+	hitID := repo.hits[0].ID
+
+	if _, err := svc.ConfirmHit(ctx, 99, hitID, " same person "); err != nil {
+		t.Fatalf("ConfirmHit() error = %v", err)
+	}
+	if err := svc.CheckCounterparty(ctx, suspect.ID); !errors.Is(err, domain.ErrSanctionsMatch) {
+		t.Errorf("expected ErrSanctionsMatch, got %v", err)
+	}
+	if _, err := svc.ClearHit(ctx, 99, hitID, ""); !errors.Is(err, domain.ErrSanctionsHitResolved) {
+		t.Errorf("expected ErrSanctionsHitResolved, got %v", err)
+	}
+
+	if len(auditRepo.events) != 1 {
+		t.Fatalf("expected 1 audit event, got %d", len(auditRepo.events))
+	}
+	event := auditRepo.events[0]
+	if event.Action != domain.AuditActionAdminSanctionsConfirm || event.ActorType != domain.AuditActorAdmin || event.ResourceType != "sanctions_hit" {
+		t.Errorf("unexpected audit event: %+v", event)
+	}
+	if repo.hits[0].Comment != "same person" {
+		t.Errorf("comment must be trimmed, got %q", repo.hits[0].Comment)
+	}
+}
+
+func TestSanctionsService_ClearedHitIsNotReopened(t *testing.T) {
+	svc, repo, users, _ := setupSanctionsService(t, copySanctionsTestdata(t, "international.xml"))
+	ctx := context.Background()
+
+	if _, err := svc.RefreshLists(ctx); err != nil {
+		t.Fatalf("RefreshLists() error = %v", err)
+	}
+
+	user := createSanctionsTestUser(t, users, "Jurij Hodorkovskij")
+	hits, err := svc.ScreenUser(ctx, user, domain.SanctionsSourceRegistration)
+	if err != nil || len(hits) != 1 {
+		t.Fatalf("expected 1 hit, got %v, %v", hits, err)
+	}
+	if _, err := svc.ClearHit(ctx, 99, hits[0].ID, "different birth date"); err != nil {
+		t.Fatalf("ClearHit() error = %v", err)
+	}
+
+	if err := svc.CheckCounterparty(ctx, user.ID); err != nil {
+		t.Errorf("cleared hit must not block transfers, got %v", err)
+	}
+	if len(repo.hits) != 1 || repo.hits[0].Status != domain.SanctionsHitStatusCleared {
+		t.Errorf("cleared hit must not be reopened, got %+v", repo.hits)
+	}
+}
+
+func TestSanctionsService_IndexCachedUntilListsChange(t *testing.T) {
+	svc, repo, users, _ := setupSanctionsService(t, copySanctionsTestdata(t, "international.xml"))
+	ctx := context.Background()
+
+	if _, err := svc.RefreshLists(ctx); err != nil {
+		t.Fatalf("RefreshLists() error = %v", err)
+	}
+	user := createSanctionsTestUser(t, users, "anna_volkova")
+
+	for i := 0; i < 3; i++ {
+		if _, err := svc.ScreenUser(ctx, user, domain.SanctionsSourceTransfer); err != nil {
+			t.Fatalf("ScreenUser() error = %v", err)
+		}
+	}
+	if repo.entryLoads != 2 {
+		t.Errorf("index must be built once after loading, entries loaded %d times", repo.entryLoads)
+	}
+
+	repo.lists[0].Checksum = "changed"
+	if _, err := svc.ScreenUser(ctx, user, domain.SanctionsSourceTransfer); err != nil {
+		t.Fatalf("ScreenUser() error = %v", err)
+	}
+	if repo.entryLoads != 3 {
+		t.Errorf("index must be rebuilt after list change, entries loaded %d times", repo.entryLoads)
+	}
+}
+
+func TestNewSanctionsService_InvalidConfig(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	tests := []config.SanctionsConfig{
+		{MatchThreshold: 0},
+		{MatchThreshold: 1.5},
+		{MatchThreshold: 0.9, ListFiles: []string{"a/list.csv", "b/list.xml"}},
+	}
+
+	for _, cfg := range tests {
+		if _, err := NewSanctionsService(&config.Config{Sanctions: cfg}, &MockSanctionsRepository{}, NewMockUserRepository(), nil, logger); err == nil {
+			t.Errorf("expected error for config %+v", cfg)
+		}
+	}
+}
+
+func TestAuthService_RegisterScreensUser(t *testing.T) {
+	svc, repo, users, _ := setupSanctionsService(t, copySanctionsTestdata(t, "international.xml"))
+	ctx := context.Background()
+
+	if _, err := svc.RefreshLists(ctx); err != nil {
+		t.Fatalf("RefreshLists() error = %v", err)
+	}
+
+	auditService, _ := setupAuditService()
+	auth := NewAuthService(users, svc, auditService, svc.logger)
+
+	user, err := auth.Register(ctx, RegisterRequest{Username: "john_smith", Email: "john@example.com", Password: "SecurePass123!"})
+	if err != nil {
+		t.Fatalf("registration must not be blocked by a potential match, got %v", err)
+	}
+	if len(repo.hits) != 1 || repo.hits[0].UserID != user.ID || repo.hits[0].Source != domain.SanctionsSourceRegistration {
+		t.Errorf("expected registration hit, got %+v", repo.hits)
+	}
+}
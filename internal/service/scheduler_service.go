@@ -2,6 +2,7 @@ package service
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log/slog"
 	"time"
@@ -11,19 +12,17 @@ import (
 	"github.com/vterdunov/learn-bank-app/internal/repository"
 )
 
-// SchedulerServiceImpl реализация SchedulerService
+// SchedulerServiceImpl реализация SchedulerService.
+// Методы вызываются как задачи JobRunner, который гарантирует единственный запуск среди реплик.
 type SchedulerServiceImpl struct {
-	creditRepo      repository.CreditRepository
-	paymentRepo     repository.PaymentScheduleRepository
-	accountRepo     repository.AccountRepository
-	transactionRepo repository.TransactionRepository
-	userRepo        repository.UserRepository
-	emailService    EmailService
-	logger          *slog.Logger
-	ticker          *time.Ticker
-	stopChan        chan struct{}
-	interval        time.Duration
-	penaltyRate     float64
+	creditRepo          repository.CreditRepository
+	paymentRepo         repository.PaymentScheduleRepository
+	accountRepo         repository.AccountRepository
+	transactionRepo     repository.TransactionRepository
+	txManager           repository.TxManager
+	notificationService NotificationService
+	logger              *slog.Logger
+	penaltyRate         float64
 }
 
 // NewSchedulerService создает новый экземпляр SchedulerService
@@ -33,98 +32,59 @@ func NewSchedulerService(
 	paymentRepo repository.PaymentScheduleRepository,
 	accountRepo repository.AccountRepository,
 	transactionRepo repository.TransactionRepository,
-	userRepo repository.UserRepository,
-	emailService EmailService,
+	txManager repository.TxManager,
+	notificationService NotificationService,
 	logger *slog.Logger,
 ) SchedulerService {
 	return &SchedulerServiceImpl{
-		creditRepo:      creditRepo,
-		paymentRepo:     paymentRepo,
-		accountRepo:     accountRepo,
-		transactionRepo: transactionRepo,
-		userRepo:        userRepo,
-		emailService:    emailService,
-		logger:          logger,
-		stopChan:        make(chan struct{}),
-		interval:        cfg.Scheduler.Interval,
-		penaltyRate:     cfg.Scheduler.PenaltyRate,
+		creditRepo:          creditRepo,
+		paymentRepo:         paymentRepo,
+		accountRepo:         accountRepo,
+		transactionRepo:     transactionRepo,
+		txManager:           txManager,
+		notificationService: notificationService,
+		logger:              logger,
+		penaltyRate:         cfg.Scheduler.PenaltyRate,
 	}
 }
 
-// Start запускает шедулер
-func (s *SchedulerServiceImpl) Start(ctx context.Context) error {
-	s.logger.Info("Starting payment scheduler", "interval", s.interval)
-
-	s.ticker = time.NewTicker(s.interval)
-
-	// Запускаем первую обработку сразу
-	go func() {
-		if err := s.ProcessOverduePayments(ctx); err != nil {
-			s.logger.Error("Failed to process overdue payments on startup", "error", err)
-		}
-	}()
-
-	// Запускаем периодическую обработку
-	go func() {
-		for {
-			select {
-			case <-s.ticker.C:
-				if err := s.ProcessOverduePayments(ctx); err != nil {
-					s.logger.Error("Failed to process overdue payments", "error", err)
-				}
-			case <-s.stopChan:
-				s.logger.Info("Scheduler stopped")
-				return
-			case <-ctx.Done():
-				s.logger.Info("Scheduler stopped due to context cancellation")
-				return
-			}
-		}
-	}()
-
-	return nil
-}
-
-// Stop останавливает шедулер
-func (s *SchedulerServiceImpl) Stop() {
-	if s.ticker != nil {
-		s.ticker.Stop()
-	}
-	close(s.stopChan)
-}
-
-// ProcessOverduePayments обрабатывает просроченные платежи
-func (s *SchedulerServiceImpl) ProcessOverduePayments(ctx context.Context) error {
+// ProcessOverduePayments обрабатывает просроченные платежи.
+// При отмене контекста (остановка или потеря лидерства) обработка прерывается между платежами.
+func (s *SchedulerServiceImpl) ProcessOverduePayments(ctx context.Context) (*JobResult, error) {
 	s.logger.Info("Starting overdue payments processing")
 
 	// Получаем все просроченные платежи
 	overduePayments, err := s.paymentRepo.GetOverduePayments(ctx)
 	if err != nil {
-		return fmt.Errorf("failed to get overdue payments: %w", err)
+		return nil, fmt.Errorf("failed to get overdue payments: %w", err)
 	}
 
 	s.logger.Info("Found overdue payments", "count", len(overduePayments))
 
-	var processedCount, failedCount int
+	result := &JobResult{}
 
 	for _, payment := range overduePayments {
+		if err := ctx.Err(); err != nil {
+			return result, err
+		}
+
 		if err := s.processOverduePayment(ctx, payment); err != nil {
 			s.logger.Error("Failed to process overdue payment",
 				"payment_id", payment.ID,
 				"credit_id", payment.CreditID,
 				"error", err)
-			failedCount++
+			result.Failed++
 		} else {
-			processedCount++
+			result.Processed++
 		}
 	}
 
 	s.logger.Info("Overdue payments processing completed",
-		"processed", processedCount,
-		"failed", failedCount,
+		"processed", result.Processed,
+		"failed", result.Failed,
 		"total", len(overduePayments))
 
-	return nil
+	return result, nil
 }
 
 // processOverduePayment обрабатывает один просроченный платеж
@@ -153,41 +113,40 @@ func (s *SchedulerServiceImpl) processOverduePayment(ctx context.Context, paymen
 		"total_amount", totalAmount,
 		"account_balance", account.Balance)
 
-	// Проверяем, достаточно ли средств для списания
-	if account.Balance >= totalAmount {
-		// Списываем средства со счета
-		if err := s.processPaymentDeduction(ctx, account, payment, penaltyAmount, totalAmount); err != nil {
+	// Изменение платежа и уведомление фиксируются в одной транзакции
+	return s.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		// Достаточность средств проверяется в том же запросе, что и списание
+		err := s.processPaymentDeduction(ctx, account, payment, penaltyAmount, totalAmount)
+		switch {
+		case err == nil:
+			s.logger.Info("Successfully processed overdue payment with penalty",
+				"payment_id", payment.ID,
+				"amount_deducted", totalAmount)
+		case errors.Is(err, domain.ErrAccountInsufficientFunds):
+			// Недостаточно средств - увеличиваем штраф
+			if err := s.increasePenalty(ctx, payment, penaltyAmount); err != nil {
+				return fmt.Errorf("failed to increase penalty: %w", err)
+			}
+
+			s.logger.Warn("Insufficient funds for overdue payment, penalty increased",
+				"payment_id", payment.ID,
+				"required", totalAmount,
+				"available", account.AvailableBalance())
+		default:
 			return fmt.Errorf("failed to process payment deduction: %w", err)
 		}
 
-		s.logger.Info("Successfully processed overdue payment with penalty",
-			"payment_id", payment.ID,
-			"amount_deducted", totalAmount)
-	} else {
-		// Недостаточно средств - увеличиваем штраф
-		if err := s.increasePenalty(ctx, payment, penaltyAmount); err != nil {
-			return fmt.Errorf("failed to increase penalty: %w", err)
+		// Уведомление по настроенным каналам в той же транзакции
+		if err := s.notificationService.NotifyOverdue(ctx, credit.UserID, payment); err != nil {
+			return fmt.Errorf("failed to queue overdue notification: %w", err)
 		}
 
-		s.logger.Warn("Insufficient funds for overdue payment, penalty increased",
-			"payment_id", payment.ID,
-			"required", totalAmount,
-			"available", account.Balance)
-	}
-
-	// Отправляем уведомление пользователю
-	if err := s.sendOverdueNotification(ctx, credit.UserID, payment); err != nil {
-		s.logger.Error("Failed to send overdue notification",
-			"payment_id", payment.ID,
-			"user_id", credit.UserID,
-			"error", err)
-		// Не возвращаем ошибку, чтобы не прерывать обработку платежа
-	}
-
-	return nil
+		return nil
+	})
 }
 
-// processPaymentDeduction списывает платеж со счета
+// processPaymentDeduction списывает платеж со счета.
+// Если доступного остатка не хватает, возвращает domain.ErrAccountInsufficientFunds, ничего не изменив
 func (s *SchedulerServiceImpl) processPaymentDeduction(
 	ctx context.Context,
 	account *domain.Account,
@@ -195,8 +154,10 @@ func (s *SchedulerServiceImpl) processPaymentDeduction(
 	penaltyAmount, totalAmount float64,
 ) error {
 	// Списываем средства со счета
-	account.Balance -= totalAmount
-	if err := s.accountRepo.UpdateBalance(ctx, account.ID, account.Balance); err != nil {
+	if err := s.accountRepo.Debit(ctx, account.ID, totalAmount); err != nil {
+		if errors.Is(err, domain.ErrAccountInsufficientFunds) {
+			return err
+		}
 		return fmt.Errorf("failed to update account balance: %w", err)
 	}
 
@@ -241,19 +202,3 @@ func (s *SchedulerServiceImpl) increasePenalty(ctx context.Context, payment *dom
 
 	return nil
 }
-
-// sendOverdueNotification отправляет уведомление о просроченном платеже
-func (s *SchedulerServiceImpl) sendOverdueNotification(ctx context.Context, userID int, payment *domain.PaymentSchedule) error {
-	// Получаем информацию о пользователе
-	user, err := s.userRepo.GetByID(ctx, userID)
-	if err != nil {
-		return fmt.Errorf("failed to get user: %w", err)
-	}
-
-	// Отправляем уведомление
-	if err := s.emailService.SendOverdueNotification(user.Email, payment); err != nil {
-		return fmt.Errorf("failed to send email notification: %w", err)
-	}
-
-	return nil
-}
@@ -0,0 +1,62 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"github.com/vterdunov/learn-bank-app/internal/domain"
+)
+
+// SMSSender доставляет сообщения outbox через SMSProvider
+type SMSSender struct {
+	provider SMSProvider
+}
+
+// NewSMSSender создает новый экземпляр ChannelSender для SMS
+func NewSMSSender(provider SMSProvider) ChannelSender {
+	return &SMSSender{provider: provider}
+}
+
+// Send отправляет текст сообщения на номер получателя
+func (s *SMSSender) Send(ctx context.Context, message *domain.OutboxMessage) error {
+	return s.provider.SendSMS(ctx, message.Recipient, message.Body)
+}
+
+// LogSMSProvider SMS шлюз для разработки: сообщения только пишутся в лог
+type LogSMSProvider struct {
+	logger *slog.Logger
+}
+
+// NewLogSMSProvider создает новый экземпляр LogSMSProvider
+func NewLogSMSProvider(logger *slog.Logger) SMSProvider {
+	return &LogSMSProvider{logger: logger}
+}
+
+// SendSMS логирует сообщение вместо отправки
+func (p *LogSMSProvider) SendSMS(ctx context.Context, phone, text string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	p.logger.Info("SMS sent (log provider)", "phone", maskPhone(phone), "text", text)
+	return nil
+}
+
+// NewSMSProvider создает SMS шлюз по имени из конфигурации
+func NewSMSProvider(name string, logger *slog.Logger) (SMSProvider, error) {
+	switch name {
+	case "", "log":
+		return NewLogSMSProvider(logger), nil
+	default:
+		return nil, fmt.Errorf("unknown SMS provider: %s", name)
+	}
+}
+
+// maskPhone скрывает середину номера телефона для логов
+func maskPhone(phone string) string {
+	if len(phone) <= 6 {
+		return "***"
+	}
+	return phone[:3] + "***" + phone[len(phone)-3:]
+}
@@ -0,0 +1,70 @@
+package service
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"log/slog"
+
+	"github.com/go-mail/mail/v2"
+
+	"github.com/vterdunov/learn-bank-app/internal/config"
+	"github.com/vterdunov/learn-bank-app/internal/domain"
+)
+
+// SMTPEmailSender отправляет письма через SMTP
+type SMTPEmailSender struct {
+	dialer *mail.Dialer
+	from   string
+	logger *slog.Logger
+}
+
+// NewSMTPEmailSender создает новый экземпляр ChannelSender для email поверх SMTP
+func NewSMTPEmailSender(cfg *config.Config, logger *slog.Logger) ChannelSender {
+	d := mail.NewDialer(cfg.SMTP.Host, cfg.SMTP.Port, cfg.SMTP.Username, cfg.SMTP.Password)
+	d.TLSConfig = &tls.Config{
+		ServerName:         cfg.SMTP.Host,
+		InsecureSkipVerify: false,
+		MinVersion:         tls.VersionTLS12,
+	}
+
+	from := cfg.SMTP.From
+	if from == "" {
+		from = cfg.SMTP.Username
+	}
+
+	return &SMTPEmailSender{
+		dialer: d,
+		from:   from,
+		logger: logger,
+	}
+}
+
+// Send отправляет email
+func (s *SMTPEmailSender) Send(ctx context.Context, message *domain.OutboxMessage) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	m := mail.NewMessage()
+	m.SetHeader("From", s.from)
+	m.SetHeader("To", message.Recipient)
+	m.SetHeader("Subject", message.Subject)
+	if message.TextBody != "" {
+		// multipart/alternative: текстовая версия первой, HTML предпочтительна для клиентов
+		m.SetBody("text/plain", message.TextBody)
+		m.AddAlternative("text/html", message.Body)
+	} else {
+		m.SetBody("text/html", message.Body)
+	}
+
+	if err := s.dialer.DialAndSend(m); err != nil {
+		return fmt.Errorf("email sending failed: %w", err)
+	}
+
+	s.logger.Info("Email sent successfully",
+		"to", message.Recipient,
+		"subject", message.Subject)
+
+	return nil
+}
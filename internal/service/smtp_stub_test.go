@@ -0,0 +1,145 @@
+package service
+
+import (
+	"bufio"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// smtpStub минимальный локальный SMTP сервер для тестов доставки писем
+type smtpStub struct {
+	listener net.Listener
+
+	mu       sync.Mutex
+	messages []smtpStubMessage
+	// rejectRcpt заставляет сервер отвечать временной ошибкой 451 на RCPT TO
+	rejectRcpt bool
+}
+
+// smtpStubMessage письмо, принятое сервером
+type smtpStubMessage struct {
+	From string
+	To   []string
+	Data string
+}
+
+func newSMTPStub(t *testing.T) *smtpStub {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to start SMTP stub: %v", err)
+	}
+
+	stub := &smtpStub{listener: listener}
+	go stub.serve()
+	t.Cleanup(func() { _ = listener.Close() })
+
+	return stub
+}
+
+// HostPort возвращает адрес сервера
+func (s *smtpStub) HostPort(t *testing.T) (string, int) {
+	t.Helper()
+
+	host, portStr, err := net.SplitHostPort(s.listener.Addr().String())
+	if err != nil {
+		t.Fatalf("invalid SMTP stub address: %v", err)
+	}
+	port, err := strconv.Atoi(portStr)
+	if err != nil {
+		t.Fatalf("invalid SMTP stub port: %v", err)
+	}
+	return host, port
+}
+
+// SetRejectRcpt включает или выключает отказ в приеме писем
+func (s *smtpStub) SetRejectRcpt(reject bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.rejectRcpt = reject
+}
+
+// Messages возвращает принятые письма
+func (s *smtpStub) Messages() []smtpStubMessage {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]smtpStubMessage(nil), s.messages...)
+}
+
+func (s *smtpStub) serve() {
+	for {
+		c, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+		go s.handle(c)
+	}
+}
+
+func (s *smtpStub) handle(c net.Conn) {
+	defer c.Close()
+
+	reader := bufio.NewReader(c)
+	reply := func(line string) {
+		_, _ = c.Write([]byte(line + "\r\n"))
+	}
+
+	reply("220 localhost ESMTP stub")
+
+	var current smtpStubMessage
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			return
+		}
+		line = strings.TrimRight(line, "\r\n")
+		command := strings.ToUpper(line)
+
+		switch {
+		case strings.HasPrefix(command, "EHLO"), strings.HasPrefix(command, "HELO"):
+			reply("250 localhost")
+		case strings.HasPrefix(command, "MAIL FROM:"):
+			current = smtpStubMessage{From: strings.TrimSpace(line[len("MAIL FROM:"):])}
+			reply("250 OK")
+		case strings.HasPrefix(command, "RCPT TO:"):
+			s.mu.Lock()
+			reject := s.rejectRcpt
+			s.mu.Unlock()
+			if reject {
+				reply("451 Temporary failure")
+				continue
+			}
+			current.To = append(current.To, strings.TrimSpace(line[len("RCPT TO:"):]))
+			reply("250 OK")
+		case command == "DATA":
+			reply("354 End data with <CR><LF>.<CR><LF>")
+			var data strings.Builder
+			for {
+				dataLine, err := reader.ReadString('\n')
+				if err != nil {
+					return
+				}
+				if dataLine == ".\r\n" {
+					break
+				}
+				data.WriteString(dataLine)
+			}
+			current.Data = data.String()
+			s.mu.Lock()
+			s.messages = append(s.messages, current)
+			s.mu.Unlock()
+			reply("250 OK")
+		case command == "RSET", command == "NOOP":
+			reply("250 OK")
+		case command == "QUIT":
+			reply("221 Bye")
+			return
+		default:
+			reply("502 Command not implemented")
+		}
+	}
+}
@@ -0,0 +1,523 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/vterdunov/learn-bank-app/internal/config"
+	"github.com/vterdunov/learn-bank-app/internal/domain"
+	"github.com/vterdunov/learn-bank-app/internal/repository"
+	"github.com/vterdunov/learn-bank-app/internal/utils"
+	"github.com/vterdunov/learn-bank-app/pkg/logger"
+)
+
+const (
+	// defaultExecutionPageSize размер страницы истории исполнения по умолчанию
+	defaultExecutionPageSize = 20
+	// maxExecutionPageSize максимальный размер страницы истории исполнения
+	maxExecutionPageSize = 100
+)
+
+// standingOrderService реализует интерфейс StandingOrderService.
+// Получатель ищется так же, как при переводе (RecipientService), внутренний ID чужого счета клиенту не раскрывается.
+// Платежи исполняются задачей JobRunner через RecipientService.TransferToAccount с лимитом нового получателя;
+// при нехватке средств платеж повторяется, после последней неудачной попытки
+// пользователь получает уведомление, а поручение переходит к следующей дате.
+type standingOrderService struct {
+	orderRepo           repository.StandingOrderRepository
+	accessControl       domain.AccessControlService
+	recipientService    RecipientService
+	notificationService NotificationService
+	txManager           repository.TxManager
+	auditService        AuditService
+	clock               utils.Clock
+	location            *time.Location
+	maxAttempts         int
+	retryInterval       time.Duration
+	logger              *slog.Logger
+}
+
+// NewStandingOrderService создает новый экземпляр StandingOrderService.
+// Даты платежей считаются в поясе операционного дня.
+func NewStandingOrderService(
+	cfg *config.Config,
+	orderRepo repository.StandingOrderRepository,
+	accessControl domain.AccessControlService,
+	recipientService RecipientService,
+	notificationService NotificationService,
+	txManager repository.TxManager,
+	auditService AuditService,
+	clock utils.Clock,
+	lg *slog.Logger,
+) (StandingOrderService, error) {
+	location, err := time.LoadLocation(cfg.EOD.Timezone)
+	if err != nil {
+		return nil, fmt.Errorf("invalid EOD timezone %q: %w", cfg.EOD.Timezone, err)
+	}
+
+	maxAttempts := cfg.Standing.MaxAttempts
+	if maxAttempts < 1 {
+		maxAttempts = 1
+	}
+
+	return &standingOrderService{
+		orderRepo:           orderRepo,
+		accessControl:       accessControl,
+		recipientService:    recipientService,
+		notificationService: notificationService,
+		txManager:           txManager,
+		auditService:        auditService,
+		clock:               clock,
+		location:            location,
+		maxAttempts:         maxAttempts,
+		retryInterval:       cfg.Standing.RetryInterval,
+		logger:              logger.WithService(lg, "standing_order_service"),
+	}, nil
+}
+
+// CreateStandingOrder создает поручение и назначает первый платеж
+func (s *standingOrderService) CreateStandingOrder(ctx context.Context, userID int, req domain.StandingOrderRequest) (*domain.StandingOrder, error) {
+	if err := s.validateRequest(ctx, userID, &req); err != nil {
+		return nil, err
+	}
+
+	order := &domain.StandingOrder{
+		UserID: userID,
+		Status: domain.StandingOrderStatusActive,
+	}
+	order.Apply(&req)
+	order.Reschedule(s.today())
+	if order.IsFinished() {
+		return nil, domain.ErrInvalidStandingOrderPeriod
+	}
+
+	if err := s.orderRepo.Create(ctx, order); err != nil {
+		s.logger.Error("Failed to create standing order", "user_id", userID, "error", err)
+		return nil, fmt.Errorf("failed to create standing order: %w", err)
+	}
+
+	s.logger.Info("Standing order created",
+		"standing_order_id", order.ID,
+		"user_id", userID,
+		"schedule", order.Schedule,
+		"amount", order.Amount,
+		"next_run_date", order.NextRunDate.Format(time.DateOnly))
+
+	event := NewUserAuditEvent(userID, domain.AuditActionStandingOrderCreate, "standing_order", auditResourceID(order.ID))
+	event.After = domain.NewAuditState(order)
+	// Ошибка аудита уже залогирована, поручение создано
+	_ = s.auditService.Record(ctx, event)
+
+	return order, nil
+}
+
+// GetUserStandingOrders возвращает поручения пользователя
+func (s *standingOrderService) GetUserStandingOrders(ctx context.Context, userID int) ([]*domain.StandingOrder, error) {
+	orders, err := s.orderRepo.GetByUserID(ctx, userID)
+	if err != nil {
+		s.logger.Error("Failed to get user standing orders", "user_id", userID, "error", err)
+		return nil, fmt.Errorf("failed to get user standing orders: %w", err)
+	}
+
+	return orders, nil
+}
+
+// GetStandingOrder возвращает поручение пользователя
+func (s *standingOrderService) GetStandingOrder(ctx context.Context, userID, orderID int) (*domain.StandingOrder, error) {
+	order, err := s.orderRepo.GetByID(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.checkOwner(order, userID); err != nil {
+		return nil, err
+	}
+
+	return order, nil
+}
+
+// UpdateStandingOrder меняет условия поручения и пересчитывает дату ближайшего платежа.
+// Незавершенные повторы текущего платежа сбрасываются.
+func (s *standingOrderService) UpdateStandingOrder(ctx context.Context, userID, orderID int, req domain.StandingOrderRequest) (*domain.StandingOrder, error) {
+	if err := s.validateRequest(ctx, userID, &req); err != nil {
+		return nil, err
+	}
+
+	return s.modify(ctx, userID, orderID, domain.AuditActionStandingOrderUpdate, func(order *domain.StandingOrder) error {
+		if order.IsFinished() {
+			return domain.ErrStandingOrderFinished
+		}
+
+		order.Apply(&req)
+		order.Reschedule(s.today())
+		if order.IsFinished() {
+			return domain.ErrInvalidStandingOrderPeriod
+		}
+		return nil
+	})
+}
+
+// PauseStandingOrder приостанавливает исполнение поручения
+func (s *standingOrderService) PauseStandingOrder(ctx context.Context, userID, orderID int) (*domain.StandingOrder, error) {
+	return s.modify(ctx, userID, orderID, domain.AuditActionStandingOrderUpdate, func(order *domain.StandingOrder) error {
+		if !order.IsActive() {
+			return domain.ErrStandingOrderNotActive
+		}
+
+		order.Status = domain.StandingOrderStatusPaused
+		order.Attempts = 0
+		order.RetryAt = nil
+		return nil
+	})
+}
+
+// ResumeStandingOrder возобновляет поручение. Платежи, пропущенные за время паузы, не исполняются.
+func (s *standingOrderService) ResumeStandingOrder(ctx context.Context, userID, orderID int) (*domain.StandingOrder, error) {
+	return s.modify(ctx, userID, orderID, domain.AuditActionStandingOrderUpdate, func(order *domain.StandingOrder) error {
+		if order.Status != domain.StandingOrderStatusPaused {
+			return domain.ErrStandingOrderNotPaused
+		}
+
+		order.Status = domain.StandingOrderStatusActive
+		order.Reschedule(s.today())
+		return nil
+	})
+}
+
+// CancelStandingOrder отменяет поручение; история исполнения сохраняется
+func (s *standingOrderService) CancelStandingOrder(ctx context.Context, userID, orderID int) (*domain.StandingOrder, error) {
+	return s.modify(ctx, userID, orderID, domain.AuditActionStandingOrderCancel, func(order *domain.StandingOrder) error {
+		if order.IsFinished() {
+			return domain.ErrStandingOrderFinished
+		}
+
+		order.Status = domain.StandingOrderStatusCancelled
+		order.NextRunDate = nil
+		order.Attempts = 0
+		order.RetryAt = nil
+		return nil
+	})
+}
+
+// GetExecutions возвращает историю исполнения поручения
+func (s *standingOrderService) GetExecutions(ctx context.Context, userID, orderID, limit, offset int) ([]*domain.StandingOrderExecution, error) {
+	if _, err := s.GetStandingOrder(ctx, userID, orderID); err != nil {
+		return nil, err
+	}
+
+	if limit <= 0 {
+		limit = defaultExecutionPageSize
+	}
+	if limit > maxExecutionPageSize {
+		limit = maxExecutionPageSize
+	}
+	if offset < 0 {
+		offset = 0
+	}
+
+	executions, err := s.orderRepo.GetExecutions(ctx, orderID, limit, offset)
+	if err != nil {
+		s.logger.Error("Failed to get standing order executions", "standing_order_id", orderID, "error", err)
+		return nil, fmt.Errorf("failed to get standing order executions: %w", err)
+	}
+
+	return executions, nil
+}
+
+// ExecuteDue исполняет наступившие платежи по поручениям.
+// При отмене контекста (остановка или потеря лидерства) обработка прерывается между поручениями.
+func (s *standingOrderService) ExecuteDue(ctx context.Context) (*JobResult, error) {
+	now := s.clock.Now()
+	today := domain.BusinessDate(now, s.location)
+
+	orders, err := s.orderRepo.ListDue(ctx, today, now)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get due standing orders: %w", err)
+	}
+
+	result := &JobResult{}
+	for _, order := range orders {
+		if err := ctx.Err(); err != nil {
+			return result, err
+		}
+
+		execution, err := s.execute(ctx, order.ID, today, now)
+		if err != nil {
+			s.logger.Error("Failed to execute standing order", "standing_order_id", order.ID, "error", err)
+			result.Failed++
+			continue
+		}
+
+		switch {
+		case execution == nil:
+			// Поручение изменено или исполнено параллельно
+		case execution.Status == domain.StandingOrderExecutionSucceeded:
+			result.Processed++
+		default:
+			result.Failed++
+		}
+	}
+
+	return result, nil
+}
+
+// execute выполняет перевод по поручению и записывает результат в той же транзакции.
+// Возвращает nil, если к моменту блокировки поручение уже не подлежит исполнению.
+func (s *standingOrderService) execute(ctx context.Context, orderID int, today, now time.Time) (*domain.StandingOrderExecution, error) {
+	var execution *domain.StandingOrderExecution
+	var transferErr error
+
+	err := s.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		order, err := s.orderRepo.GetByIDForUpdate(ctx, orderID)
+		if err != nil {
+			return err
+		}
+		if !order.IsDue(today, now) {
+			return nil
+		}
+
+		execution = newStandingOrderExecution(order, now)
+		transferErr = s.recipientService.TransferToAccount(ctx, order.UserID, order.FromAccountID, order.ToAccountID, order.Amount)
+		if transferErr != nil {
+			return transferErr
+		}
+
+		execution.Status = domain.StandingOrderExecutionSucceeded
+		if err := s.orderRepo.AddExecution(ctx, execution); err != nil {
+			return fmt.Errorf("failed to record execution: %w", err)
+		}
+
+		order.Advance(execution.ScheduledDate)
+		return s.orderRepo.Update(ctx, order)
+	})
+	if transferErr != nil {
+		// Транзакция перевода откачена, попытка фиксируется отдельно
+		return s.recordFailure(ctx, orderID, today, now, transferErr)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if execution != nil {
+		s.logger.Info("Standing order executed",
+			"standing_order_id", orderID,
+			"scheduled_date", execution.ScheduledDate.Format(time.DateOnly),
+			"attempt", execution.Attempt,
+			"amount", execution.Amount)
+	}
+
+	return execution, nil
+}
+
+// standingOrderRejections окончательные отказы в переводе: повтор того же платежа их не изменит
+var standingOrderRejections = []error{
+	ErrAccountNotFound,
+	ErrAccountBlocked,
+	ErrInvalidAmount,
+	domain.ErrAccountClosed,
+	domain.ErrRecipientNotFound,
+	domain.ErrOperationRejected,
+	domain.ErrSanctionsMatch,
+	domain.ErrKYCLimitExceeded,
+}
+
+// isStandingOrderRetryable сообщает, что перевод может пройти позже: не хватает средств, исчерпан лимит
+// нового получателя, перевод ждет решения антифрода или проверки получателя по санкционным спискам
+func isStandingOrderRetryable(err error) bool {
+	return errors.Is(err, ErrInsufficientFunds) || errors.Is(err, domain.ErrNewRecipientLimitExceeded) ||
+		errors.Is(err, domain.ErrOperationUnderReview) || errors.Is(err, domain.ErrSanctionsReviewPending)
+}
+
+// isStandingOrderRejection сообщает, что перевод отклонен по бизнес-правилам и повторять его бессмысленно
+func isStandingOrderRejection(err error) bool {
+	for _, rejection := range standingOrderRejections {
+		if errors.Is(err, rejection) {
+			return true
+		}
+	}
+
+	var serviceErr *ServiceError
+	return errors.As(err, &serviceErr) && serviceErr.Code < http.StatusInternalServerError
+}
+
+// recordFailure записывает неудачную попытку. При нехватке средств, исчерпанном лимите нового получателя, проверке
+// перевода антифродом или проверке получателя по санкционным спискам назначается повтор; после последней попытки
+// или окончательного отказа платеж считается неисполненным и пользователь уведомляется. Прочие ошибки (сбой БД
+// или смежного сервиса) не расходуют попытки: поручение остается на той же дате и исполняется через retryInterval.
+func (s *standingOrderService) recordFailure(ctx context.Context, orderID int, today, now time.Time, cause error) (*domain.StandingOrderExecution, error) {
+	var execution *domain.StandingOrderExecution
+	var postponed bool
+
+	err := s.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		order, err := s.orderRepo.GetByIDForUpdate(ctx, orderID)
+		if err != nil {
+			return err
+		}
+		if !order.IsDue(today, now) {
+			return nil
+		}
+
+		retryable := isStandingOrderRetryable(cause)
+		if !retryable && !isStandingOrderRejection(cause) {
+			// Временный сбой: попытка не засчитывается, платеж не пропускается
+			retryAt := now.Add(s.retryInterval)
+			order.RetryAt = &retryAt
+			postponed = true
+			return s.orderRepo.Update(ctx, order)
+		}
+
+		execution = newStandingOrderExecution(order, now)
+		execution.Error = cause.Error()
+
+		if retryable && execution.Attempt < s.maxAttempts {
+			retryAt := now.Add(s.retryInterval)
+			execution.Status = domain.StandingOrderExecutionRetrying
+			order.Attempts = execution.Attempt
+			order.RetryAt = &retryAt
+		} else {
+			execution.Status = domain.StandingOrderExecutionFailed
+			order.Advance(execution.ScheduledDate)
+		}
+
+		if err := s.orderRepo.AddExecution(ctx, execution); err != nil {
+			return fmt.Errorf("failed to record execution: %w", err)
+		}
+		if err := s.orderRepo.Update(ctx, order); err != nil {
+			return err
+		}
+
+		if execution.Status != domain.StandingOrderExecutionFailed {
+			return nil
+		}
+
+		// Уведомление по настроенным каналам в той же транзакции
+		if err := s.notificationService.NotifyStandingOrderFailed(ctx, order.UserID, order, execution); err != nil {
+			return fmt.Errorf("failed to queue standing order notification: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if postponed {
+		return nil, fmt.Errorf("standing order payment postponed: %w", cause)
+	}
+
+	if execution != nil {
+		s.logger.Warn("Standing order payment failed",
+			"standing_order_id", orderID,
+			"scheduled_date", execution.ScheduledDate.Format(time.DateOnly),
+			"attempt", execution.Attempt,
+			"status", execution.Status,
+			"error", execution.Error)
+	}
+
+	return execution, nil
+}
+
+// modify изменяет поручение пользователя под блокировкой и записывает событие аудита
+func (s *standingOrderService) modify(
+	ctx context.Context,
+	userID, orderID int,
+	action string,
+	change func(order *domain.StandingOrder) error,
+) (*domain.StandingOrder, error) {
+	var order *domain.StandingOrder
+	var before domain.StandingOrder
+
+	err := s.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		var err error
+		order, err = s.orderRepo.GetByIDForUpdate(ctx, orderID)
+		if err != nil {
+			return err
+		}
+		if err := s.checkOwner(order, userID); err != nil {
+			return err
+		}
+
+		before = *order
+		if err := change(order); err != nil {
+			return err
+		}
+
+		return s.orderRepo.Update(ctx, order)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("Standing order updated",
+		"standing_order_id", order.ID,
+		"user_id", userID,
+		"action", action,
+		"status", order.Status)
+
+	event := NewUserAuditEvent(userID, action, "standing_order", auditResourceID(order.ID))
+	event.Before = domain.NewAuditState(before)
+	event.After = domain.NewAuditState(order)
+	// Ошибка аудита уже залогирована, поручение изменено
+	_ = s.auditService.Record(ctx, event)
+
+	return order, nil
+}
+
+// validateRequest проверяет условия поручения, доступ к счету списания и находит получателя.
+// Любая ошибка поиска получателя — ErrRecipientNotFound, чтобы по ответу нельзя было узнать, какие счета существуют
+func (s *standingOrderService) validateRequest(ctx context.Context, userID int, req *domain.StandingOrderRequest) error {
+	if err := req.Validate(); err != nil {
+		s.logger.Warn("Invalid standing order request", "user_id", userID, "error", err)
+		return err
+	}
+
+	if err := s.accessControl.CanAccessAccount(ctx, userID, req.FromAccountID); err != nil {
+		s.logger.Warn("Access denied for standing order", "user_id", userID, "account_id", req.FromAccountID)
+		if domain.IsAccessDeniedError(err) {
+			return &ServiceError{Code: http.StatusForbidden, Message: err.Error()}
+		}
+		return ErrAccountNotFound
+	}
+
+	recipient, err := s.recipientService.ResolveRecipient(ctx, userID, req.To)
+	if err != nil {
+		return err
+	}
+	if recipient.AccountID == req.FromAccountID {
+		return domain.ErrStandingOrderSameAccount
+	}
+
+	req.Recipient = recipient
+	return nil
+}
+
+// checkOwner проверяет, что поручение принадлежит пользователю
+func (s *standingOrderService) checkOwner(order *domain.StandingOrder, userID int) error {
+	if order.UserID == userID {
+		return nil
+	}
+
+	s.logger.Warn("Access denied for standing order", "user_id", userID, "standing_order_id", order.ID)
+	return &ServiceError{
+		Code:    http.StatusForbidden,
+		Message: domain.NewAccessDeniedError("standing_order", order.ID, userID).Error(),
+	}
+}
+
+// today возвращает текущую операционную дату
+func (s *standingOrderService) today() time.Time {
+	return domain.BusinessDate(s.clock.Now(), s.location)
+}
+
+// newStandingOrderExecution создает запись попытки исполнения текущего платежа поручения
+func newStandingOrderExecution(order *domain.StandingOrder, now time.Time) *domain.StandingOrderExecution {
+	return &domain.StandingOrderExecution{
+		StandingOrderID: order.ID,
+		ScheduledDate:   *order.NextRunDate,
+		Attempt:         order.Attempts + 1,
+		Amount:          order.Amount,
+		ExecutedAt:      now,
+	}
+}
@@ -0,0 +1,738 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"os"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/vterdunov/learn-bank-app/internal/config"
+	"github.com/vterdunov/learn-bank-app/internal/domain"
+)
+
+// MockStandingOrderRepository для тестирования (поручения и история в памяти)
+type MockStandingOrderRepository struct {
+	orders     map[int]*domain.StandingOrder
+	executions []*domain.StandingOrderExecution
+	nextID     int
+}
+
+func NewMockStandingOrderRepository() *MockStandingOrderRepository {
+	return &MockStandingOrderRepository{
+		orders: make(map[int]*domain.StandingOrder),
+		nextID: 1,
+	}
+}
+
+func (m *MockStandingOrderRepository) Create(ctx context.Context, order *domain.StandingOrder) error {
+	order.ID = m.nextID
+	m.nextID++
+	copied := *order
+	m.orders[order.ID] = &copied
+	return nil
+}
+
+func (m *MockStandingOrderRepository) GetByID(ctx context.Context, id int) (*domain.StandingOrder, error) {
+	order, ok := m.orders[id]
+	if !ok {
+		return nil, domain.ErrStandingOrderNotFound
+	}
+	copied := *order
+	return &copied, nil
+}
+
+func (m *MockStandingOrderRepository) GetByIDForUpdate(ctx context.Context, id int) (*domain.StandingOrder, error) {
+	return m.GetByID(ctx, id)
+}
+
+func (m *MockStandingOrderRepository) GetByUserID(ctx context.Context, userID int) ([]*domain.StandingOrder, error) {
+	var orders []*domain.StandingOrder
+	for _, order := range m.orders {
+		if order.UserID == userID {
+			copied := *order
+			orders = append(orders, &copied)
+		}
+	}
+	return orders, nil
+}
+
+func (m *MockStandingOrderRepository) Update(ctx context.Context, order *domain.StandingOrder) error {
+	if _, ok := m.orders[order.ID]; !ok {
+		return domain.ErrStandingOrderNotFound
+	}
+	copied := *order
+	m.orders[order.ID] = &copied
+	return nil
+}
+
+func (m *MockStandingOrderRepository) ListDue(ctx context.Context, today, now time.Time) ([]*domain.StandingOrder, error) {
+	var orders []*domain.StandingOrder
+	for _, order := range m.orders {
+		if order.IsDue(today, now) {
+			copied := *order
+			orders = append(orders, &copied)
+		}
+	}
+	sort.Slice(orders, func(i, j int) bool { return orders[i].ID < orders[j].ID })
+	return orders, nil
+}
+
+func (m *MockStandingOrderRepository) AddExecution(ctx context.Context, execution *domain.StandingOrderExecution) error {
+	for _, e := range m.executions {
+		if e.StandingOrderID == execution.StandingOrderID && e.ScheduledDate.Equal(execution.ScheduledDate) && e.Attempt == execution.Attempt {
+			return errors.New("duplicate execution")
+		}
+	}
+	execution.ID = int64(len(m.executions) + 1)
+	m.executions = append(m.executions, execution)
+	return nil
+}
+
+func (m *MockStandingOrderRepository) GetExecutions(ctx context.Context, orderID, limit, offset int) ([]*domain.StandingOrderExecution, error) {
+	var executions []*domain.StandingOrderExecution
+	for i := len(m.executions) - 1; i >= 0; i-- {
+		if m.executions[i].StandingOrderID == orderID {
+			executions = append(executions, m.executions[i])
+		}
+	}
+	if offset >= len(executions) {
+		return nil, nil
+	}
+	executions = executions[offset:]
+	if len(executions) > limit {
+		executions = executions[:limit]
+	}
+	return executions, nil
+}
+
+// mockTransferService переводит средства между балансами mockAccountRepository
+type mockTransferService struct {
+	accounts  *mockAccountRepository
+	transfers int
+	err       error // Если задана, переводы не выполняются и возвращают эту ошибку
+}
+
+func (m *mockTransferService) CreateAccount(ctx context.Context, userID int, req CreateAccountRequest) (*domain.Account, error) {
+	return nil, nil
+}
+
+func (m *mockTransferService) GetUserAccounts(ctx context.Context, userID int) ([]*domain.Account, error) {
+	return nil, nil
+}
+
+func (m *mockTransferService) DepositMoney(ctx context.Context, userID, accountID int, amount float64) error {
+	return nil
+}
+
+func (m *mockTransferService) WithdrawMoney(ctx context.Context, userID, accountID int, amount float64) error {
+	return nil
+}
+
+func (m *mockTransferService) WithdrawByCard(ctx context.Context, userID, accountID, cardID int, pin string, amount float64) error {
+	return nil
+}
+
+func (m *mockTransferService) TransferMoney(ctx context.Context, userID, fromAccountID, toAccountID int, amount float64) error {
+	if m.err != nil {
+		return m.err
+	}
+	balances := m.accounts.depositRepo.balances
+	if balances[fromAccountID] < amount {
+		return ErrInsufficientFunds
+	}
+	balances[fromAccountID] -= amount
+	balances[toAccountID] += amount
+	m.transfers++
+	return nil
+}
+
+func (m *mockTransferService) FreezeAccount(ctx context.Context, userID, accountID int) (*domain.Account, error) {
+	return nil, nil
+}
+
+func (m *mockTransferService) UnfreezeAccount(ctx context.Context, userID, accountID int) (*domain.Account, error) {
+	return nil, nil
+}
+
+func (m *mockTransferService) SetOverdraftLimit(ctx context.Context, userID, accountID int, limit float64) (*domain.Account, error) {
+	return nil, nil
+}
+
+func (m *mockTransferService) CloseAccount(ctx context.Context, userID, accountID, residualAccountID int) (*domain.Account, error) {
+	return nil, nil
+}
+
+func (m *mockTransferService) GetAccountHolders(ctx context.Context, userID, accountID int) ([]*domain.AccountHolder, error) {
+	return nil, nil
+}
+
+func (m *mockTransferService) AddAccountHolder(ctx context.Context, userID, accountID int, req domain.AddAccountHolderRequest) (*domain.AccountHolder, error) {
+	return nil, nil
+}
+
+func (m *mockTransferService) RemoveAccountHolder(ctx context.Context, userID, accountID, holderUserID int) error {
+	return nil
+}
+
+// mockRecipientService находит получателей среди счетов mockAccountRepository и переводит через mockTransferService.
+// Свои счета ищутся по ID, чужие — только по номеру из payees; для чужих действует limitRemaining
+type mockRecipientService struct {
+	accounts       *mockAccountRepository
+	transfers      *mockTransferService
+	payees         map[string]int
+	limitRemaining map[int]float64
+}
+
+func (m *mockRecipientService) ResolveRecipient(ctx context.Context, userID int, lookup domain.RecipientLookup) (*domain.Recipient, error) {
+	if err := lookup.Validate(); err != nil {
+		return nil, err
+	}
+
+	if lookup.AccountID != 0 {
+		if _, ok := m.accounts.depositRepo.balances[lookup.AccountID]; !ok {
+			return nil, domain.ErrRecipientNotFound
+		}
+		return &domain.Recipient{AccountID: lookup.AccountID, UserID: userID, MaskedName: "O***r", OwnAccount: true}, nil
+	}
+
+	accountID, ok := m.payees[lookup.AccountNumber]
+	if !ok {
+		return nil, domain.ErrRecipientNotFound
+	}
+	recipient := &domain.Recipient{
+		AccountID:           accountID,
+		MaskedName:          "I***v",
+		MaskedAccountNumber: domain.MaskAccountNumber(lookup.AccountNumber),
+	}
+	if limit, ok := m.limitRemaining[accountID]; ok {
+		recipient.NewRecipient = true
+		recipient.LimitRemaining = &limit
+	}
+	return recipient, nil
+}
+
+func (m *mockRecipientService) Transfer(ctx context.Context, userID, fromAccountID int, lookup domain.RecipientLookup, amount float64) (*domain.Recipient, error) {
+	return nil, nil
+}
+
+func (m *mockRecipientService) TransferToAccount(ctx context.Context, userID, fromAccountID, toAccountID int, amount float64) error {
+	limit, limited := m.limitRemaining[toAccountID]
+	if limited && amount > limit {
+		return domain.ErrNewRecipientLimitExceeded
+	}
+	if err := m.transfers.TransferMoney(ctx, userID, fromAccountID, toAccountID, amount); err != nil {
+		return err
+	}
+	if limited {
+		m.limitRemaining[toAccountID] = limit - amount
+	}
+	return nil
+}
+
+func (m *mockRecipientService) GetAliases(ctx context.Context, userID int) ([]*domain.PaymentAlias, error) {
+	return nil, nil
+}
+
+func (m *mockRecipientService) CreateAlias(ctx context.Context, userID int, alias domain.PaymentAlias) (*domain.PaymentAlias, error) {
+	return nil, nil
+}
+
+func (m *mockRecipientService) DeleteAlias(ctx context.Context, userID, aliasID int) error {
+	return nil
+}
+
+type standingOrderTestDeps struct {
+	orderRepo    *MockStandingOrderRepository
+	balances     map[int]float64
+	transfers    *mockTransferService
+	recipients   *mockRecipientService
+	notification *notificationTestDeps
+	clock        *fakeClock
+	userID       int
+}
+
+func setupStandingOrderService(t *testing.T, now time.Time) (*standingOrderService, *standingOrderTestDeps) {
+	t.Helper()
+
+	cfg := &config.Config{
+		EOD:      config.EODConfig{Timezone: "Europe/Moscow"},
+		Standing: config.StandingOrderConfig{MaxAttempts: 3, RetryInterval: 4 * time.Hour},
+	}
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	auditService, _ := setupAuditService()
+	notificationService, notificationDeps := setupNotificationService(t)
+
+	depositRepo := NewMockDepositRepository()
+	depositRepo.balances[10] = 100000
+	depositRepo.balances[20] = 0
+	accountRepo := &mockAccountRepository{depositRepo: depositRepo, userID: notificationDeps.userID}
+	transfers := &mockTransferService{accounts: accountRepo}
+	recipients := &mockRecipientService{accounts: accountRepo, transfers: transfers, limitRemaining: make(map[int]float64)}
+	clock := &fakeClock{now: now}
+
+	svc, err := NewStandingOrderService(cfg, NewMockStandingOrderRepository(), &mockAccessControl{accounts: accountRepo},
+		recipients, notificationService, mockTxManager{}, auditService, clock, logger)
+	if err != nil {
+		t.Fatalf("NewStandingOrderService failed: %v", err)
+	}
+	impl := svc.(*standingOrderService)
+
+	return impl, &standingOrderTestDeps{
+		orderRepo:    impl.orderRepo.(*MockStandingOrderRepository),
+		balances:     depositRepo.balances,
+		transfers:    transfers,
+		recipients:   recipients,
+		notification: notificationDeps,
+		clock:        clock,
+		userID:       notificationDeps.userID,
+	}
+}
+
+// runAt исполняет наступившие поручения в указанный момент
+func runAt(t *testing.T, svc *standingOrderService, deps *standingOrderTestDeps, now time.Time) *JobResult {
+	t.Helper()
+
+	deps.clock.now = now
+	result, err := svc.ExecuteDue(context.Background())
+	if err != nil {
+		t.Fatalf("ExecuteDue failed: %v", err)
+	}
+	return result
+}
+
+func date(year int, month time.Month, day int) time.Time {
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+func TestStandingOrderService_CreateValidates(t *testing.T) {
+	svc, deps := setupStandingOrderService(t, time.Date(2025, time.January, 10, 9, 0, 0, 0, time.UTC))
+	ctx := context.Background()
+	pastEnd := date(2025, time.January, 5)
+
+	tests := []struct {
+		name string
+		req  domain.StandingOrderRequest
+		want error
+	}{
+		{"same account", domain.StandingOrderRequest{FromAccountID: 10, To: domain.RecipientLookup{AccountID: 10}, Amount: 100, Schedule: "monthly", StartDate: date(2025, 1, 15)}, domain.ErrStandingOrderSameAccount},
+		{"zero amount", domain.StandingOrderRequest{FromAccountID: 10, To: domain.RecipientLookup{AccountID: 20}, Schedule: "monthly", StartDate: date(2025, 1, 15)}, domain.ErrInvalidStandingOrderAmount},
+		{"unknown schedule", domain.StandingOrderRequest{FromAccountID: 10, To: domain.RecipientLookup{AccountID: 20}, Amount: 100, Schedule: "daily", StartDate: date(2025, 1, 15)}, domain.ErrInvalidStandingOrderType},
+		{"day of month out of range", domain.StandingOrderRequest{FromAccountID: 10, To: domain.RecipientLookup{AccountID: 20}, Amount: 100, Schedule: "monthly", StartDate: date(2025, 1, 15), DayOfMonth: 32}, domain.ErrInvalidStandingOrderDay},
+		{"weekly with day of month", domain.StandingOrderRequest{FromAccountID: 10, To: domain.RecipientLookup{AccountID: 20}, Amount: 100, Schedule: "weekly", StartDate: date(2025, 1, 15), DayOfMonth: 5}, domain.ErrInvalidStandingOrderDay},
+		{"end before start", domain.StandingOrderRequest{FromAccountID: 10, To: domain.RecipientLookup{AccountID: 20}, Amount: 100, Schedule: "weekly", StartDate: date(2025, 1, 15), EndDate: &pastEnd}, domain.ErrInvalidStandingOrderPeriod},
+		{"once in the past", domain.StandingOrderRequest{FromAccountID: 10, To: domain.RecipientLookup{AccountID: 20}, Amount: 100, Schedule: "once", StartDate: date(2025, 1, 9)}, domain.ErrInvalidStandingOrderPeriod},
+		{"missing recipient", domain.StandingOrderRequest{FromAccountID: 10, To: domain.RecipientLookup{AccountID: 99}, Amount: 100, Schedule: "once", StartDate: date(2025, 1, 20)}, domain.ErrRecipientNotFound},
+		{"unknown account number", domain.StandingOrderRequest{FromAccountID: 10, To: domain.RecipientLookup{AccountNumber: "40817810000000000099"}, Amount: 100, Schedule: "once", StartDate: date(2025, 1, 20)}, domain.ErrRecipientNotFound},
+		{"no recipient", domain.StandingOrderRequest{FromAccountID: 10, Amount: 100, Schedule: "once", StartDate: date(2025, 1, 20)}, domain.ErrInvalidRecipient},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := svc.CreateStandingOrder(ctx, deps.userID, tt.req)
+			if !errors.Is(err, tt.want) {
+				t.Fatalf("expected %v, got %v", tt.want, err)
+			}
+		})
+	}
+
+	t.Run("foreign account", func(t *testing.T) {
+		_, err := svc.CreateStandingOrder(ctx, deps.userID+1, domain.StandingOrderRequest{
+			FromAccountID: 10, To: domain.RecipientLookup{AccountID: 20}, Amount: 100, Schedule: "once", StartDate: date(2025, 1, 20),
+		})
+		var serviceErr *ServiceError
+		if !errors.As(err, &serviceErr) || serviceErr.Code != http.StatusForbidden {
+			t.Fatalf("expected 403 service error, got %v", err)
+		}
+	})
+}
+
+func TestStandingOrderService_MonthlyEndOfMonth(t *testing.T) {
+	svc, deps := setupStandingOrderService(t, time.Date(2025, time.January, 10, 9, 0, 0, 0, time.UTC))
+	ctx := context.Background()
+
+	order, err := svc.CreateStandingOrder(ctx, deps.userID, domain.StandingOrderRequest{
+		FromAccountID: 10,
+		To:            domain.RecipientLookup{AccountID: 20},
+		Amount:        10000,
+		Description:   "Аренда",
+		Schedule:      domain.StandingOrderScheduleMonthly,
+		StartDate:     date(2025, time.January, 10),
+		DayOfMonth:    31,
+		EndDate:       ptrTime(date(2025, time.April, 30)),
+	})
+	if err != nil {
+		t.Fatalf("CreateStandingOrder failed: %v", err)
+	}
+	if !order.NextRunDate.Equal(date(2025, time.January, 31)) {
+		t.Fatalf("expected first payment on 2025-01-31, got %s", order.NextRunDate)
+	}
+
+	// Проверяем каждый день с января по май в 09:00 UTC (12:00 МСК)
+	for day := date(2025, time.January, 10); day.Before(date(2025, time.May, 10)); day = day.AddDate(0, 0, 1) {
+		runAt(t, svc, deps, day.Add(9*time.Hour))
+	}
+
+	want := []time.Time{
+		date(2025, time.January, 31),
+		date(2025, time.February, 28),
+		date(2025, time.March, 31),
+		date(2025, time.April, 30),
+	}
+	if len(deps.orderRepo.executions) != len(want) {
+		t.Fatalf("expected %d executions, got %d", len(want), len(deps.orderRepo.executions))
+	}
+	for i, execution := range deps.orderRepo.executions {
+		if !execution.ScheduledDate.Equal(want[i]) || execution.Status != domain.StandingOrderExecutionSucceeded {
+			t.Errorf("execution %d: expected succeeded on %s, got %s on %s",
+				i, want[i].Format(time.DateOnly), execution.Status, execution.ScheduledDate.Format(time.DateOnly))
+		}
+	}
+
+	if deps.balances[10] != 60000 || deps.balances[20] != 40000 {
+		t.Errorf("unexpected balances: from=%.2f to=%.2f", deps.balances[10], deps.balances[20])
+	}
+
+	saved, _ := deps.orderRepo.GetByID(ctx, order.ID)
+	if saved.Status != domain.StandingOrderStatusCompleted || saved.NextRunDate != nil {
+		t.Errorf("expected order to be completed after end date, got %s next=%v", saved.Status, saved.NextRunDate)
+	}
+}
+
+func TestStandingOrderService_WeeklyAndOnce(t *testing.T) {
+	// Среда, 8 января 2025
+	svc, deps := setupStandingOrderService(t, time.Date(2025, time.January, 8, 9, 0, 0, 0, time.UTC))
+	ctx := context.Background()
+
+	weekly, err := svc.CreateStandingOrder(ctx, deps.userID, domain.StandingOrderRequest{
+		FromAccountID: 10, To: domain.RecipientLookup{AccountID: 20}, Amount: 1000,
+		Schedule: domain.StandingOrderScheduleWeekly, StartDate: date(2025, time.January, 8), DayOfWeek: 5,
+	})
+	if err != nil {
+		t.Fatalf("CreateStandingOrder weekly failed: %v", err)
+	}
+	if !weekly.NextRunDate.Equal(date(2025, time.January, 10)) {
+		t.Errorf("expected first weekly payment on Friday 2025-01-10, got %s", weekly.NextRunDate.Format(time.DateOnly))
+	}
+
+	once, err := svc.CreateStandingOrder(ctx, deps.userID, domain.StandingOrderRequest{
+		FromAccountID: 10, To: domain.RecipientLookup{AccountID: 20}, Amount: 5000,
+		Schedule: domain.StandingOrderScheduleOnce, StartDate: date(2025, time.January, 15),
+	})
+	if err != nil {
+		t.Fatalf("CreateStandingOrder once failed: %v", err)
+	}
+
+	for day := date(2025, time.January, 8); day.Before(date(2025, time.February, 1)); day = day.AddDate(0, 0, 1) {
+		runAt(t, svc, deps, day.Add(9*time.Hour))
+	}
+
+	// Пятницы 10, 17, 24, 31 января и разовый платеж 15 января
+	if deps.transfers.transfers != 5 {
+		t.Errorf("expected 5 transfers, got %d", deps.transfers.transfers)
+	}
+
+	saved, _ := deps.orderRepo.GetByID(ctx, once.ID)
+	if saved.Status != domain.StandingOrderStatusCompleted {
+		t.Errorf("expected one-time order to be completed, got %s", saved.Status)
+	}
+
+	saved, _ = deps.orderRepo.GetByID(ctx, weekly.ID)
+	if !saved.NextRunDate.Equal(date(2025, time.February, 7)) {
+		t.Errorf("expected next weekly payment on 2025-02-07, got %s", saved.NextRunDate.Format(time.DateOnly))
+	}
+}
+
+func TestStandingOrderService_RetryOnInsufficientFunds(t *testing.T) {
+	svc, deps := setupStandingOrderService(t, time.Date(2025, time.March, 1, 6, 0, 0, 0, time.UTC))
+	ctx := context.Background()
+	deps.balances[10] = 3000
+
+	order, err := svc.CreateStandingOrder(ctx, deps.userID, domain.StandingOrderRequest{
+		FromAccountID: 10, To: domain.RecipientLookup{AccountID: 20}, Amount: 5000,
+		Schedule: domain.StandingOrderScheduleMonthly, StartDate: date(2025, time.March, 1),
+	})
+	if err != nil {
+		t.Fatalf("CreateStandingOrder failed: %v", err)
+	}
+
+	start := time.Date(2025, time.March, 1, 6, 0, 0, 0, time.UTC)
+	result := runAt(t, svc, deps, start)
+	if result.Failed != 1 || result.Processed != 0 {
+		t.Fatalf("expected failed first attempt, got %+v", result)
+	}
+
+	saved, _ := deps.orderRepo.GetByID(ctx, order.ID)
+	if saved.Attempts != 1 || saved.RetryAt == nil || !saved.RetryAt.Equal(start.Add(4*time.Hour)) {
+		t.Fatalf("expected retry scheduled in 4h, got attempts=%d retry_at=%v", saved.Attempts, saved.RetryAt)
+	}
+
+	t.Run("no retry before retry_at", func(t *testing.T) {
+		result := runAt(t, svc, deps, start.Add(time.Hour))
+		if result.Processed+result.Failed != 0 {
+			t.Errorf("expected nothing to run, got %+v", result)
+		}
+	})
+
+	t.Run("retry succeeds after top up", func(t *testing.T) {
+		deps.balances[10] = 8000
+		result := runAt(t, svc, deps, start.Add(4*time.Hour))
+		if result.Processed != 1 {
+			t.Fatalf("expected successful retry, got %+v", result)
+		}
+
+		last := deps.orderRepo.executions[len(deps.orderRepo.executions)-1]
+		if last.Attempt != 2 || last.Status != domain.StandingOrderExecutionSucceeded {
+			t.Errorf("expected attempt 2 succeeded, got attempt %d %s", last.Attempt, last.Status)
+		}
+
+		saved, _ := deps.orderRepo.GetByID(ctx, order.ID)
+		if saved.Attempts != 0 || saved.RetryAt != nil || !saved.NextRunDate.Equal(date(2025, time.April, 1)) {
+			t.Errorf("expected reset retries and next payment on 2025-04-01, got attempts=%d retry_at=%v next=%v",
+				saved.Attempts, saved.RetryAt, saved.NextRunDate)
+		}
+	})
+
+	if len(deps.notification.notificationRepo.notifications) != 0 {
+		t.Errorf("expected no failure notification, got %d", len(deps.notification.notificationRepo.notifications))
+	}
+}
+
+func TestStandingOrderService_RetryWhenHeldForReview(t *testing.T) {
+	svc, deps := setupStandingOrderService(t, time.Date(2025, time.March, 1, 6, 0, 0, 0, time.UTC))
+	ctx := context.Background()
+	deps.balances[10] = 10000
+	deps.transfers.err = &domain.FraudReviewError{ReviewID: 1}
+
+	order, err := svc.CreateStandingOrder(ctx, deps.userID, domain.StandingOrderRequest{
+		FromAccountID: 10, To: domain.RecipientLookup{AccountID: 20}, Amount: 5000,
+		Schedule: domain.StandingOrderScheduleMonthly, StartDate: date(2025, time.March, 1),
+	})
+	if err != nil {
+		t.Fatalf("CreateStandingOrder failed: %v", err)
+	}
+
+	start := time.Date(2025, time.March, 1, 6, 0, 0, 0, time.UTC)
+	if result := runAt(t, svc, deps, start); result.Failed != 1 {
+		t.Fatalf("expected held first attempt, got %+v", result)
+	}
+	saved, _ := deps.orderRepo.GetByID(ctx, order.ID)
+	if saved.RetryAt == nil {
+		t.Fatalf("expected retry for transfer held for review")
+	}
+
+	// Оператор одобрил перевод: повтор проходит
+	deps.transfers.err = nil
+	if result := runAt(t, svc, deps, start.Add(4*time.Hour)); result.Processed != 1 {
+		t.Fatalf("expected successful retry after approval, got %+v", result)
+	}
+	if len(deps.notification.notificationRepo.notifications) != 0 {
+		t.Errorf("expected no failure notification, got %d", len(deps.notification.notificationRepo.notifications))
+	}
+}
+
+func TestStandingOrderService_PostponesOnTransientError(t *testing.T) {
+	svc, deps := setupStandingOrderService(t, time.Date(2025, time.March, 1, 6, 0, 0, 0, time.UTC))
+	ctx := context.Background()
+	deps.balances[10] = 10000
+	deps.transfers.err = errors.New("connection reset by peer")
+
+	order, err := svc.CreateStandingOrder(ctx, deps.userID, domain.StandingOrderRequest{
+		FromAccountID: 10, To: domain.RecipientLookup{AccountID: 20}, Amount: 5000,
+		Schedule: domain.StandingOrderScheduleMonthly, StartDate: date(2025, time.March, 1),
+	})
+	if err != nil {
+		t.Fatalf("CreateStandingOrder failed: %v", err)
+	}
+
+	// Сбой повторяется дольше, чем допускает лимит попыток: платеж все равно не пропускается
+	start := time.Date(2025, time.March, 1, 6, 0, 0, 0, time.UTC)
+	for i := 0; i < 4; i++ {
+		if result := runAt(t, svc, deps, start.Add(time.Duration(i)*4*time.Hour)); result.Failed != 1 {
+			t.Fatalf("expected postponed attempt %d, got %+v", i+1, result)
+		}
+	}
+
+	saved, _ := deps.orderRepo.GetByID(ctx, order.ID)
+	if saved.Attempts != 0 || !saved.NextRunDate.Equal(date(2025, time.March, 1)) ||
+		saved.RetryAt == nil || !saved.RetryAt.Equal(start.Add(16*time.Hour)) {
+		t.Fatalf("expected payment kept on 2025-03-01 with retry, got attempts=%d retry_at=%v next=%v",
+			saved.Attempts, saved.RetryAt, saved.NextRunDate)
+	}
+	if len(deps.orderRepo.executions) != 0 {
+		t.Errorf("expected no recorded executions, got %d", len(deps.orderRepo.executions))
+	}
+	if len(deps.notification.notificationRepo.notifications) != 0 {
+		t.Errorf("expected no failure notification, got %d", len(deps.notification.notificationRepo.notifications))
+	}
+
+	deps.transfers.err = nil
+	if result := runAt(t, svc, deps, start.Add(16*time.Hour)); result.Processed != 1 {
+		t.Fatalf("expected successful retry after recovery, got %+v", result)
+	}
+	saved, _ = deps.orderRepo.GetByID(ctx, order.ID)
+	if saved.RetryAt != nil || !saved.NextRunDate.Equal(date(2025, time.April, 1)) {
+		t.Errorf("expected next payment on 2025-04-01, got retry_at=%v next=%v", saved.RetryAt, saved.NextRunDate)
+	}
+}
+
+func TestStandingOrderService_FailsAfterMaxAttempts(t *testing.T) {
+	svc, deps := setupStandingOrderService(t, time.Date(2025, time.March, 1, 6, 0, 0, 0, time.UTC))
+	ctx := context.Background()
+	deps.balances[10] = 0
+
+	order, err := svc.CreateStandingOrder(ctx, deps.userID, domain.StandingOrderRequest{
+		FromAccountID: 10, To: domain.RecipientLookup{AccountID: 20}, Amount: 5000,
+		Schedule: domain.StandingOrderScheduleMonthly, StartDate: date(2025, time.March, 1),
+	})
+	if err != nil {
+		t.Fatalf("CreateStandingOrder failed: %v", err)
+	}
+
+	start := time.Date(2025, time.March, 1, 6, 0, 0, 0, time.UTC)
+	for i := 0; i < 3; i++ {
+		runAt(t, svc, deps, start.Add(time.Duration(i)*4*time.Hour))
+	}
+
+	statuses := make([]string, 0, len(deps.orderRepo.executions))
+	for _, execution := range deps.orderRepo.executions {
+		statuses = append(statuses, execution.Status)
+	}
+	want := []string{
+		domain.StandingOrderExecutionRetrying,
+		domain.StandingOrderExecutionRetrying,
+		domain.StandingOrderExecutionFailed,
+	}
+	if len(statuses) != len(want) {
+		t.Fatalf("expected statuses %v, got %v", want, statuses)
+	}
+	for i := range want {
+		if statuses[i] != want[i] {
+			t.Fatalf("expected statuses %v, got %v", want, statuses)
+		}
+	}
+
+	saved, _ := deps.orderRepo.GetByID(ctx, order.ID)
+	if !saved.IsActive() || saved.Attempts != 0 || !saved.NextRunDate.Equal(date(2025, time.April, 1)) {
+		t.Errorf("expected order to move to 2025-04-01, got status=%s attempts=%d next=%v", saved.Status, saved.Attempts, saved.NextRunDate)
+	}
+
+	notifications := deps.notification.notificationRepo.notifications
+	if len(notifications) != 1 || notifications[0].EventType != domain.NotificationEventStandingOrderFailed {
+		t.Fatalf("expected one standing order failure notification, got %+v", notifications)
+	}
+	if len(deps.notification.emailService.queued) != 1 || deps.notification.emailService.queued[0] != "standing_order:notify@example.com" {
+		t.Errorf("expected failure email to be queued, got %v", deps.notification.emailService.queued)
+	}
+
+	executions, err := svc.GetExecutions(ctx, deps.userID, order.ID, 2, 0)
+	if err != nil {
+		t.Fatalf("GetExecutions failed: %v", err)
+	}
+	if len(executions) != 2 || executions[0].Status != domain.StandingOrderExecutionFailed {
+		t.Errorf("expected 2 latest executions starting with failed, got %d", len(executions))
+	}
+}
+
+func TestStandingOrderService_NewRecipientLimit(t *testing.T) {
+	svc, deps := setupStandingOrderService(t, time.Date(2025, time.March, 1, 6, 0, 0, 0, time.UTC))
+	ctx := context.Background()
+	deps.balances[10] = 100000
+	deps.balances[30] = 0
+	deps.recipients.payees = map[string]int{"40817810000000000030": 30}
+	deps.recipients.limitRemaining[30] = 3000
+
+	order, err := svc.CreateStandingOrder(ctx, deps.userID, domain.StandingOrderRequest{
+		FromAccountID: 10, To: domain.RecipientLookup{AccountNumber: "40817810000000000030"}, Amount: 5000,
+		Schedule: domain.StandingOrderScheduleMonthly, StartDate: date(2025, time.March, 1),
+	})
+	if err != nil {
+		t.Fatalf("CreateStandingOrder failed: %v", err)
+	}
+	if order.ToAccountID != 30 || order.ToMaskedAccountNumber != "40817***********0030" || order.ToMaskedName != "I***v" {
+		t.Fatalf("expected resolved masked recipient, got %+v", order)
+	}
+
+	start := time.Date(2025, time.March, 1, 6, 0, 0, 0, time.UTC)
+	if result := runAt(t, svc, deps, start); result.Failed != 1 {
+		t.Fatalf("expected payment over new recipient limit to fail, got %+v", result)
+	}
+	if deps.transfers.transfers != 0 || deps.balances[30] != 0 {
+		t.Fatalf("expected no transfer over new recipient limit")
+	}
+
+	saved, _ := deps.orderRepo.GetByID(ctx, order.ID)
+	if saved.RetryAt == nil {
+		t.Fatalf("expected retry while new recipient limit is exhausted")
+	}
+
+	// Период нового получателя истек: повтор проходит
+	delete(deps.recipients.limitRemaining, 30)
+	if result := runAt(t, svc, deps, start.Add(4*time.Hour)); result.Processed != 1 {
+		t.Fatalf("expected successful retry after limit period, got %+v", result)
+	}
+	if deps.balances[30] != 5000 {
+		t.Errorf("expected recipient balance 5000, got %.2f", deps.balances[30])
+	}
+}
+
+func TestStandingOrderService_PauseResumeCancel(t *testing.T) {
+	svc, deps := setupStandingOrderService(t, time.Date(2025, time.January, 10, 9, 0, 0, 0, time.UTC))
+	ctx := context.Background()
+
+	order, err := svc.CreateStandingOrder(ctx, deps.userID, domain.StandingOrderRequest{
+		FromAccountID: 10, To: domain.RecipientLookup{AccountID: 20}, Amount: 1000,
+		Schedule: domain.StandingOrderScheduleMonthly, StartDate: date(2025, time.January, 15),
+	})
+	if err != nil {
+		t.Fatalf("CreateStandingOrder failed: %v", err)
+	}
+
+	if _, err := svc.PauseStandingOrder(ctx, deps.userID, order.ID); err != nil {
+		t.Fatalf("PauseStandingOrder failed: %v", err)
+	}
+	if _, err := svc.PauseStandingOrder(ctx, deps.userID, order.ID); !errors.Is(err, domain.ErrStandingOrderNotActive) {
+		t.Errorf("expected ErrStandingOrderNotActive, got %v", err)
+	}
+
+	// Платеж 15 января пропущен на паузе
+	runAt(t, svc, deps, time.Date(2025, time.January, 20, 9, 0, 0, 0, time.UTC))
+	if deps.transfers.transfers != 0 {
+		t.Fatalf("expected no transfers while paused, got %d", deps.transfers.transfers)
+	}
+
+	resumed, err := svc.ResumeStandingOrder(ctx, deps.userID, order.ID)
+	if err != nil {
+		t.Fatalf("ResumeStandingOrder failed: %v", err)
+	}
+	if !resumed.NextRunDate.Equal(date(2025, time.February, 15)) {
+		t.Errorf("expected next payment on 2025-02-15 after resume, got %s", resumed.NextRunDate.Format(time.DateOnly))
+	}
+
+	if _, err := svc.GetStandingOrder(ctx, deps.userID+1, order.ID); err == nil {
+		t.Error("expected access denied for another user")
+	}
+
+	cancelled, err := svc.CancelStandingOrder(ctx, deps.userID, order.ID)
+	if err != nil {
+		t.Fatalf("CancelStandingOrder failed: %v", err)
+	}
+	if cancelled.Status != domain.StandingOrderStatusCancelled || cancelled.NextRunDate != nil {
+		t.Errorf("expected cancelled order without next payment, got %s %v", cancelled.Status, cancelled.NextRunDate)
+	}
+
+	_, err = svc.UpdateStandingOrder(ctx, deps.userID, order.ID, domain.StandingOrderRequest{
+		FromAccountID: 10, To: domain.RecipientLookup{AccountID: 20}, Amount: 2000,
+		Schedule: domain.StandingOrderScheduleMonthly, StartDate: date(2025, time.March, 1),
+	})
+	if !errors.Is(err, domain.ErrStandingOrderFinished) {
+		t.Errorf("expected ErrStandingOrderFinished, got %v", err)
+	}
+}
+
+func ptrTime(t time.Time) *time.Time {
+	return &t
+}
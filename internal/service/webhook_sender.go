@@ -0,0 +1,94 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/vterdunov/learn-bank-app/internal/config"
+	"github.com/vterdunov/learn-bank-app/internal/domain"
+	"github.com/vterdunov/learn-bank-app/internal/repository"
+	"github.com/vterdunov/learn-bank-app/internal/utils"
+)
+
+// Заголовки запроса webhook
+const (
+	WebhookHeaderEvent     = "X-Bank-Event"
+	WebhookHeaderDelivery  = "X-Bank-Delivery"
+	WebhookHeaderTimestamp = "X-Bank-Timestamp"
+	WebhookHeaderSignature = "X-Bank-Signature"
+)
+
+// ErrWebhookRecipientUnknown сообщение webhook не привязано к пользователю
+var ErrWebhookRecipientUnknown = errors.New("webhook message has no user")
+
+// WebhookSender доставляет уведомления на webhook пользователя.
+// Тело подписывается HMAC-SHA256 секретом endpoint: подпись считается от "<timestamp>.<body>".
+type WebhookSender struct {
+	client           *http.Client
+	notificationRepo repository.NotificationRepository
+	logger           *slog.Logger
+}
+
+// NewWebhookSender создает новый экземпляр ChannelSender для webhook
+func NewWebhookSender(cfg *config.Config, notificationRepo repository.NotificationRepository, logger *slog.Logger) ChannelSender {
+	return &WebhookSender{
+		client:           &http.Client{Timeout: cfg.Notify.WebhookTimeout},
+		notificationRepo: notificationRepo,
+		logger:           logger,
+	}
+}
+
+// Send отправляет payload на webhook. Секрет и URL читаются при каждой попытке,
+// поэтому повтор после смены секрета подписывается новым ключом.
+func (s *WebhookSender) Send(ctx context.Context, message *domain.OutboxMessage) error {
+	if message.UserID == nil {
+		return ErrWebhookRecipientUnknown
+	}
+
+	endpoint, err := s.notificationRepo.GetEndpoint(ctx, *message.UserID, domain.NotificationChannelWebhook)
+	if err != nil {
+		return fmt.Errorf("failed to get webhook endpoint: %w", err)
+	}
+
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint.Address, bytes.NewBufferString(message.Body))
+	if err != nil {
+		return fmt.Errorf("failed to create webhook request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(WebhookHeaderEvent, message.EventType)
+	req.Header.Set(WebhookHeaderDelivery, strconv.FormatInt(message.ID, 10))
+	req.Header.Set(WebhookHeaderTimestamp, timestamp)
+	req.Header.Set(WebhookHeaderSignature, "sha256="+SignWebhookPayload(timestamp, message.Body, endpoint.Secret))
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("webhook request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 4096))
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook returned status %d", resp.StatusCode)
+	}
+
+	s.logger.Info("Webhook delivered",
+		"outbox_id", message.ID,
+		"user_id", *message.UserID,
+		"event_type", message.EventType)
+
+	return nil
+}
+
+// SignWebhookPayload вычисляет подпись webhook (hex HMAC-SHA256 от "<timestamp>.<body>")
+func SignWebhookPayload(timestamp, body, secret string) string {
+	return utils.ComputeHMAC(timestamp+"."+body, []byte(secret))
+}
@@ -11,8 +11,12 @@ import (
 
 // Константы для карт
 const (
-	// LearnBankPrefix префикс для карт LearnBank
+	// LearnBankPrefix префикс (BIN) для физических карт LearnBank
 	LearnBankPrefix = "7777"
+	// LearnBankVirtualPrefix префикс для виртуальных карт
+	LearnBankVirtualPrefix = "7778"
+	// LearnBankSingleUsePrefix префикс для одноразовых карт
+	LearnBankSingleUsePrefix = "7779"
 	// CardNumberLength длина номера карты
 	CardNumberLength = 16
 	// CVVLength длина CVV
@@ -27,13 +31,12 @@ const (
 
 // Ошибки карт
 var (
-	ErrCardExpired = errors.New("card expired")
+	ErrCardExpired     = errors.New("card expired")
+	ErrInvalidPINBlock = errors.New("invalid PIN or card number for PIN block")
 )
 
-// GenerateCardNumber генерирует номер карты по алгоритму Луна
-func GenerateCardNumber() (string, error) {
-	prefix := LearnBankPrefix
-
+// GenerateCardNumber генерирует номер карты с префиксом prefix по алгоритму Луна
+func GenerateCardNumber(prefix string) (string, error) {
 	// Генерируем первые 15 цифр
 	cardNumber := prefix
 	for len(cardNumber) < CardNumberLength-1 {
@@ -80,8 +83,8 @@ func GetCardType(cardNumber string) string {
 		return CardTypeUnknown
 	}
 
-	prefix := cardNumber[:4]
-	if prefix == LearnBankPrefix {
+	switch cardNumber[:4] {
+	case LearnBankPrefix, LearnBankVirtualPrefix, LearnBankSingleUsePrefix:
 		return CardTypeLearnBank
 	}
 
@@ -144,6 +147,39 @@ func ValidateExpiryDateCard(expiryDate string) error {
 	return nil
 }
 
+// BuildPINBlock формирует PIN-блок ISO 9564 формата 0: поле PIN (0, длина, PIN, заполнитель F)
+// складывается по XOR с полем номера (0000 и 12 правых цифр номера без контрольной).
+// Результат — 16 шестнадцатеричных символов в верхнем регистре.
+func BuildPINBlock(pin, pan string) (string, error) {
+	if len(pin) < 4 || len(pin) > 12 || len(pan) < 13 || !isDigits(pin) || !isDigits(pan) {
+		return "", ErrInvalidPINBlock
+	}
+
+	pinField := fmt.Sprintf("0%X%s", len(pin), pin)
+	for len(pinField) < 16 {
+		pinField += "F"
+	}
+	panField := "0000" + pan[len(pan)-13:len(pan)-1]
+
+	block := make([]byte, 16)
+	for i := 0; i < 16; i++ {
+		p, _ := strconv.ParseUint(pinField[i:i+1], 16, 8)
+		a, _ := strconv.ParseUint(panField[i:i+1], 16, 8)
+		block[i] = "0123456789ABCDEF"[p^a]
+	}
+
+	return string(block), nil
+}
+
+func isDigits(s string) bool {
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
 // MaskCardNumber маскирует номер карты для отображения
 func MaskCardNumber(cardNumber string) string {
 	if len(cardNumber) != CardNumberLength {
@@ -0,0 +1,63 @@
+package utils
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestBuildPINBlock(t *testing.T) {
+	tests := []struct {
+		name    string
+		pin     string
+		pan     string
+		want    string
+		wantErr bool
+	}{
+		{name: "4 digits", pin: "1234", pan: "4111111111111111", want: "041225EEEEEEEEEE"},
+		{name: "6 digits", pin: "123456", pan: "7777001234567890", want: "06124457DCBA9876"},
+		{name: "letters in PIN", pin: "12a4", pan: "4111111111111111", wantErr: true},
+		{name: "short PIN", pin: "123", pan: "4111111111111111", wantErr: true},
+		{name: "short PAN", pin: "1234", pan: "411111111111", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := BuildPINBlock(tt.pin, tt.pan)
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidPINBlock) {
+					t.Fatalf("expected ErrInvalidPINBlock, got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("BuildPINBlock failed: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("expected %s, got %s", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestHashPINBlock(t *testing.T) {
+	block, err := BuildPINBlock("4821", "7777001234567890")
+	if err != nil {
+		t.Fatalf("BuildPINBlock failed: %v", err)
+	}
+
+	hash, err := HashPINBlock(block, "salt-1")
+	if err != nil {
+		t.Fatalf("HashPINBlock failed: %v", err)
+	}
+	if err := VerifyPINBlock(hash, block, "salt-1"); err != nil {
+		t.Errorf("expected PIN block to verify, got %v", err)
+	}
+	if err := VerifyPINBlock(hash, block, "salt-2"); err == nil {
+		t.Error("expected PIN block with another salt to be rejected")
+	}
+
+	other, _ := BuildPINBlock("4821", "7777001234567808")
+	if err := VerifyPINBlock(hash, other, "salt-1"); err == nil {
+		t.Error("expected the same PIN for another card number to be rejected")
+	}
+}
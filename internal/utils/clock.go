@@ -0,0 +1,16 @@
+package utils
+
+import "time"
+
+// Clock источник текущего времени; в тестах подменяется, чтобы моделировать течение дней
+type Clock interface {
+	Now() time.Time
+}
+
+// SystemClock системные часы
+type SystemClock struct{}
+
+// Now возвращает текущее время
+func (SystemClock) Now() time.Time {
+	return time.Now()
+}
@@ -0,0 +1,196 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrInvalidCronSchedule некорректное расписание
+var ErrInvalidCronSchedule = errors.New("invalid cron schedule")
+
+// cronSearchLimit максимальный горизонт поиска следующего запуска
+const cronSearchLimit = 5 * 366 * 24 * time.Hour
+
+// CronSchedule расписание запуска задачи
+type CronSchedule interface {
+	// Next возвращает ближайшее время запуска строго после t
+	Next(t time.Time) time.Time
+}
+
+// everySchedule расписание с фиксированным интервалом (@every 12h)
+type everySchedule struct {
+	interval time.Duration
+}
+
+func (s everySchedule) Next(t time.Time) time.Time {
+	return t.Add(s.interval)
+}
+
+// cronField допустимые значения одного поля cron выражения
+type cronField map[int]bool
+
+// fieldSchedule расписание в формате cron из пяти полей: минута час день месяц день_недели
+type fieldSchedule struct {
+	minute, hour, dom, month, dow cronField
+	// domAny и dowAny: поле задано как "*", важно для правила объединения дня месяца и дня недели
+	domAny, dowAny bool
+}
+
+// cronDescriptors сокращения стандартных расписаний
+var cronDescriptors = map[string]string{
+	"@hourly":   "0 * * * *",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@weekly":   "0 0 * * 0",
+	"@monthly":  "0 0 1 * *",
+	"@yearly":   "0 0 1 1 *",
+}
+
+// ParseCronSchedule разбирает cron выражение ("*/15 * * * *"), сокращение (@daily)
+// или фиксированный интервал (@every 1h30m). Время вычисляется в зоне переданного момента.
+func ParseCronSchedule(spec string) (CronSchedule, error) {
+	spec = strings.TrimSpace(spec)
+
+	if rest, ok := strings.CutPrefix(spec, "@every "); ok {
+		interval, err := time.ParseDuration(strings.TrimSpace(rest))
+		if err != nil || interval <= 0 {
+			return nil, fmt.Errorf("%w: %q", ErrInvalidCronSchedule, spec)
+		}
+		return everySchedule{interval: interval}, nil
+	}
+
+	if expanded, ok := cronDescriptors[spec]; ok {
+		spec = expanded
+	}
+
+	fields := strings.Fields(spec)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("%w: %q: expected 5 fields", ErrInvalidCronSchedule, spec)
+	}
+
+	bounds := [5][2]int{{0, 59}, {0, 23}, {1, 31}, {1, 12}, {0, 7}}
+	parsed := make([]cronField, 5)
+	for i, field := range fields {
+		values, err := parseCronField(field, bounds[i][0], bounds[i][1])
+		if err != nil {
+			return nil, fmt.Errorf("%w: %q: %v", ErrInvalidCronSchedule, spec, err)
+		}
+		parsed[i] = values
+	}
+
+	// Воскресенье допускается как 0 и как 7
+	if parsed[4][7] {
+		parsed[4][0] = true
+	}
+
+	schedule := &fieldSchedule{
+		minute: parsed[0],
+		hour:   parsed[1],
+		dom:    parsed[2],
+		month:  parsed[3],
+		dow:    parsed[4],
+		domAny: fields[2] == "*",
+		dowAny: fields[4] == "*",
+	}
+
+	// Отсекаем расписания, которые никогда не срабатывают (например, 30 февраля)
+	if schedule.Next(time.Date(2000, time.January, 1, 0, 0, 0, 0, time.UTC)).IsZero() {
+		return nil, fmt.Errorf("%w: %q never fires", ErrInvalidCronSchedule, spec)
+	}
+
+	return schedule, nil
+}
+
+// parseCronField разбирает поле: *, число, диапазон a-b, список через запятую и шаг /n
+func parseCronField(field string, min, max int) (cronField, error) {
+	values := make(cronField)
+
+	for _, part := range strings.Split(field, ",") {
+		rangePart, stepPart, hasStep := strings.Cut(part, "/")
+
+		step := 1
+		if hasStep {
+			parsed, err := strconv.Atoi(stepPart)
+			if err != nil || parsed <= 0 {
+				return nil, fmt.Errorf("invalid step %q", part)
+			}
+			step = parsed
+		}
+
+		low, high := min, max
+		switch {
+		case rangePart == "*":
+		case strings.Contains(rangePart, "-"):
+			from, to, _ := strings.Cut(rangePart, "-")
+			var err error
+			if low, err = strconv.Atoi(from); err != nil {
+				return nil, fmt.Errorf("invalid range %q", part)
+			}
+			if high, err = strconv.Atoi(to); err != nil {
+				return nil, fmt.Errorf("invalid range %q", part)
+			}
+		default:
+			value, err := strconv.Atoi(rangePart)
+			if err != nil {
+				return nil, fmt.Errorf("invalid value %q", part)
+			}
+			low = value
+			high = value
+			if hasStep {
+				high = max
+			}
+		}
+
+		if low < min || high > max || low > high {
+			return nil, fmt.Errorf("value out of range %q", part)
+		}
+
+		for v := low; v <= high; v += step {
+			values[v] = true
+		}
+	}
+
+	return values, nil
+}
+
+// Next ищет ближайшую подходящую минуту перебором (с пропуском неподходящих месяцев, дней и часов)
+func (s *fieldSchedule) Next(t time.Time) time.Time {
+	t = t.Truncate(time.Minute).Add(time.Minute)
+	limit := t.Add(cronSearchLimit)
+
+	for t.Before(limit) {
+		if !s.month[int(t.Month())] {
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, t.Location())
+			continue
+		}
+		if !s.dayMatches(t) {
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, t.Location())
+			continue
+		}
+		if !s.hour[t.Hour()] {
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, t.Location())
+			continue
+		}
+		if !s.minute[t.Minute()] {
+			t = t.Add(time.Minute)
+			continue
+		}
+		return t
+	}
+
+	return time.Time{}
+}
+
+// dayMatches как в cron: если заданы и день месяца, и день недели, достаточно совпадения любого
+func (s *fieldSchedule) dayMatches(t time.Time) bool {
+	domMatch := s.dom[t.Day()]
+	dowMatch := s.dow[int(t.Weekday())]
+
+	if s.domAny || s.dowAny {
+		return domMatch && dowMatch
+	}
+	return domMatch || dowMatch
+}
@@ -0,0 +1,59 @@
+package utils
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestParseCronSchedule_Next(t *testing.T) {
+	// Среда, 15 января 2025, 10:07
+	from := time.Date(2025, time.January, 15, 10, 7, 30, 0, time.UTC)
+
+	tests := []struct {
+		spec string
+		want time.Time
+	}{
+		{"*/15 * * * *", time.Date(2025, time.January, 15, 10, 15, 0, 0, time.UTC)},
+		{"0 3 * * *", time.Date(2025, time.January, 16, 3, 0, 0, 0, time.UTC)},
+		{"30 9-17 * * 1-5", time.Date(2025, time.January, 15, 10, 30, 0, 0, time.UTC)},
+		{"0 0 1 * *", time.Date(2025, time.February, 1, 0, 0, 0, 0, time.UTC)},
+		{"0 0 * * 7", time.Date(2025, time.January, 19, 0, 0, 0, 0, time.UTC)},
+		{"0 12 1 * 5", time.Date(2025, time.January, 17, 12, 0, 0, 0, time.UTC)},
+		{"@daily", time.Date(2025, time.January, 16, 0, 0, 0, 0, time.UTC)},
+		{"@every 90m", from.Add(90 * time.Minute)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			schedule, err := ParseCronSchedule(tt.spec)
+			if err != nil {
+				t.Fatalf("Failed to parse %q: %v", tt.spec, err)
+			}
+			if got := schedule.Next(from); !got.Equal(tt.want) {
+				t.Errorf("Next(%s) = %s, want %s", from, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseCronSchedule_Invalid(t *testing.T) {
+	specs := []string{
+		"",
+		"* * * *",
+		"60 * * * *",
+		"* 24 * * *",
+		"*/0 * * * *",
+		"5-1 * * * *",
+		"0 0 30 2 *",
+		"@every",
+		"@every -1h",
+		"@sometimes",
+	}
+
+	for _, spec := range specs {
+		if _, err := ParseCronSchedule(spec); !errors.Is(err, ErrInvalidCronSchedule) {
+			t.Errorf("Expected ErrInvalidCronSchedule for %q, got %v", spec, err)
+		}
+	}
+}
@@ -91,6 +91,37 @@ func VerifyCVV(hashedCVV, cvv string) error {
 	return nil
 }
 
+// HashPINBlock хеширует PIN-блок вместе с солью карты с использованием bcrypt
+func HashPINBlock(pinBlock, salt string) (string, error) {
+	if len(pinBlock) != 16 || salt == "" {
+		return "", errors.New("invalid PIN block")
+	}
+
+	hashedBytes, err := bcrypt.GenerateFromPassword([]byte(salt+":"+pinBlock), BCryptCost)
+	if err != nil {
+		return "", fmt.Errorf("failed to hash PIN block: %w", err)
+	}
+
+	return string(hashedBytes), nil
+}
+
+// VerifyPINBlock проверяет PIN-блок против хеша с солью карты
+func VerifyPINBlock(hashedPINBlock, pinBlock, salt string) error {
+	if len(hashedPINBlock) == 0 {
+		return errors.New("invalid PIN")
+	}
+
+	err := bcrypt.CompareHashAndPassword([]byte(hashedPINBlock), []byte(salt+":"+pinBlock))
+	if err != nil {
+		if errors.Is(err, bcrypt.ErrMismatchedHashAndPassword) {
+			return errors.New("invalid PIN")
+		}
+		return fmt.Errorf("failed to verify PIN: %w", err)
+	}
+
+	return nil
+}
+
 // GenerateRandomKey генерирует случайный ключ заданного размера
 func GenerateRandomKey(size int) ([]byte, error) {
 	key := make([]byte, size)
@@ -36,20 +36,30 @@ type JWTClaims struct {
 
 // JWTManager управляет JWT токенами
 type JWTManager struct {
-	secretKey     []byte
+	keys          *KeyRing
 	tokenExpiry   time.Duration
 	refreshExpiry time.Duration
 }
 
-// NewJWTManager создает новый менеджер JWT
+// NewJWTManager создает новый менеджер JWT с HMAC подписью
 func NewJWTManager(secretKey string) *JWTManager {
+	return NewJWTManagerWithKeyRing(NewHMACKeyRing(secretKey))
+}
+
+// NewJWTManagerWithKeyRing создает менеджер JWT поверх набора ключей (RS256/EdDSA с ротацией)
+func NewJWTManagerWithKeyRing(keys *KeyRing) *JWTManager {
 	return &JWTManager{
-		secretKey:     []byte(secretKey),
+		keys:          keys,
 		tokenExpiry:   DefaultTokenExpiry,
 		refreshExpiry: RefreshTokenExpiry,
 	}
 }
 
+// KeyRing возвращает набор ключей менеджера
+func (j *JWTManager) KeyRing() *KeyRing {
+	return j.keys
+}
+
 // GenerateToken генерирует JWT токен для пользователя
 func (j *JWTManager) GenerateToken(userID int, username, email string) (string, error) {
 	claims := &JWTClaims{
@@ -66,8 +76,7 @@ func (j *JWTManager) GenerateToken(userID int, username, email string) (string,
 		},
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	tokenString, err := token.SignedString(j.secretKey)
+	tokenString, err := j.keys.Sign(claims)
 	if err != nil {
 		return "", fmt.Errorf("failed to sign token: %w", err)
 	}
@@ -86,8 +95,7 @@ func (j *JWTManager) GenerateRefreshToken(userID int) (string, error) {
 		Audience:  []string{"learn-bank-app-refresh"},
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	tokenString, err := token.SignedString(j.secretKey)
+	tokenString, err := j.keys.Sign(claims)
 	if err != nil {
 		return "", fmt.Errorf("failed to sign refresh token: %w", err)
 	}
@@ -97,13 +105,9 @@ func (j *JWTManager) GenerateRefreshToken(userID int) (string, error) {
 
 // ValidateToken проверяет и парсит JWT токен
 func (j *JWTManager) ValidateToken(tokenString string) (*JWTClaims, error) {
-	token, err := jwt.ParseWithClaims(tokenString, &JWTClaims{}, func(token *jwt.Token) (interface{}, error) {
-		// Проверяем метод подписи
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
-		}
-		return j.secretKey, nil
-	})
+	// Метод подписи проверяется по kid в KeyRing.Keyfunc
+	token, err := jwt.ParseWithClaims(tokenString, &JWTClaims{}, j.keys.Keyfunc(),
+		jwt.WithValidMethods(j.keys.ValidMethods()))
 
 	if err != nil {
 		if errors.Is(err, jwt.ErrTokenExpired) {
@@ -130,12 +134,8 @@ func (j *JWTManager) ValidateToken(tokenString string) (*JWTClaims, error) {
 
 // ValidateRefreshToken проверяет refresh токен
 func (j *JWTManager) ValidateRefreshToken(tokenString string) (int, error) {
-	token, err := jwt.ParseWithClaims(tokenString, &jwt.RegisteredClaims{}, func(token *jwt.Token) (interface{}, error) {
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
-		}
-		return j.secretKey, nil
-	})
+	token, err := jwt.ParseWithClaims(tokenString, &jwt.RegisteredClaims{}, j.keys.Keyfunc(),
+		jwt.WithValidMethods(j.keys.ValidMethods()))
 
 	if err != nil {
 		if errors.Is(err, jwt.ErrTokenExpired) {
@@ -162,9 +162,7 @@ func (j *JWTManager) ValidateRefreshToken(tokenString string) (int, error) {
 
 // ExtractUserIDFromToken извлекает ID пользователя из токена без полной валидации
 func (j *JWTManager) ExtractUserIDFromToken(tokenString string) (int, error) {
-	token, err := jwt.ParseWithClaims(tokenString, &JWTClaims{}, func(token *jwt.Token) (interface{}, error) {
-		return j.secretKey, nil
-	}, jwt.WithoutClaimsValidation())
+	token, err := jwt.ParseWithClaims(tokenString, &JWTClaims{}, j.keys.Keyfunc(), jwt.WithoutClaimsValidation())
 
 	if err != nil {
 		return 0, fmt.Errorf("failed to parse token: %w", err)
@@ -233,6 +231,11 @@ func InitJWT(secretKey string) {
 	defaultJWTManager = NewJWTManager(secretKey)
 }
 
+// InitJWTWithKeyRing инициализирует глобальный JWT менеджер набором ключей
+func InitJWTWithKeyRing(keys *KeyRing) {
+	defaultJWTManager = NewJWTManagerWithKeyRing(keys)
+}
+
 // GenerateJWT генерирует JWT токен (упрощенная версия)
 func GenerateJWT(userID string) (string, error) {
 	if defaultJWTManager == nil {
@@ -0,0 +1,426 @@
+package utils
+
+import (
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// Алгоритмы подписи JWT
+const (
+	JWTAlgRS256 = "RS256"
+	JWTAlgEdDSA = "EdDSA"
+	JWTAlgHS256 = "HS256"
+)
+
+// Суффиксы файлов ключей в директории JWT_KEYS_DIR
+const (
+	// privateKeyFileSuffix файл с приватным ключом: <kid>.pem
+	privateKeyFileSuffix = ".pem"
+	// publicKeyFileSuffix файл только с публичным ключом (ключ только для проверки): <kid>.pub.pem
+	publicKeyFileSuffix = ".pub.pem"
+	// legacyHMACKeyID идентификатор HMAC ключа из JWT_SECRET
+	legacyHMACKeyID = "hs256"
+)
+
+// Ошибки ключей JWT
+var (
+	ErrNoSigningKey       = errors.New("no signing key configured")
+	ErrUnknownKeyID       = errors.New("unknown key id")
+	ErrUnsupportedKeyType = errors.New("unsupported key type")
+	ErrMissingKeyID       = errors.New("token has no key id")
+	ErrKeyExpired         = errors.New("key is no longer accepted")
+)
+
+// JWTKey ключ подписи/проверки JWT
+type JWTKey struct {
+	KID       string
+	Algorithm string
+	// signKey приватный ключ (nil для ключей только на проверку)
+	signKey interface{}
+	// verifyKey публичный ключ (или секрет для HMAC)
+	verifyKey interface{}
+	// notAfter момент, с которого ключ не принимается для проверки (нулевой — без ограничения)
+	notAfter time.Time
+}
+
+// CanSign проверяет наличие приватной части ключа
+func (k *JWTKey) CanSign() bool {
+	return k.signKey != nil
+}
+
+// SigningMethod возвращает метод подписи jwt для ключа
+func (k *JWTKey) SigningMethod() jwt.SigningMethod {
+	switch k.Algorithm {
+	case JWTAlgRS256:
+		return jwt.SigningMethodRS256
+	case JWTAlgEdDSA:
+		return jwt.SigningMethodEdDSA
+	default:
+		return jwt.SigningMethodHS256
+	}
+}
+
+// JWK публичный ключ в формате RFC 7517
+type JWK struct {
+	KID string `json:"kid"`
+	Kty string `json:"kty"`
+	Alg string `json:"alg"`
+	Use string `json:"use"`
+	// RSA
+	N string `json:"n,omitempty"`
+	E string `json:"e,omitempty"`
+	// OKP (Ed25519)
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+}
+
+// JWKSet набор публичных ключей для /.well-known/jwks.json
+type JWKSet struct {
+	Keys []JWK `json:"keys"`
+}
+
+// KeyRing набор ключей JWT с поддержкой ротации.
+// Подписывает токены активным ключом, проверяет любым известным ключом по kid.
+type KeyRing struct {
+	mu         sync.RWMutex
+	keys       map[string]*JWTKey
+	signingKID string
+
+	// Источник ключей для перезагрузки
+	dir          string
+	preferredKID string
+	hmacSecret   string
+	hmacUntil    time.Time
+
+	clock Clock
+}
+
+// NewHMACKeyRing создает набор из одного HMAC ключа (режим совместимости с JWT_SECRET)
+func NewHMACKeyRing(secret string) *KeyRing {
+	ring := &KeyRing{
+		keys:       make(map[string]*JWTKey),
+		hmacSecret: secret,
+		clock:      SystemClock{},
+	}
+	ring.keys[legacyHMACKeyID] = newHMACKey(secret)
+	ring.signingKID = legacyHMACKeyID
+	return ring
+}
+
+// LoadKeyRing загружает ключи из директории.
+// signingKID задает активный ключ; если пуст — выбирается последний по имени kid.
+// hmacSecret (опционально) добавляет HMAC ключ только для проверки старых токенов на время миграции:
+// до hmacUntil принимаются HS256 токены и токены без kid, после — отклоняются.
+func LoadKeyRing(dir, signingKID, hmacSecret string, hmacUntil time.Time) (*KeyRing, error) {
+	if hmacSecret != "" && hmacUntil.IsZero() {
+		return nil, errors.New("legacy HMAC verification requires a cutoff time")
+	}
+
+	ring := &KeyRing{
+		dir:          dir,
+		preferredKID: signingKID,
+		hmacSecret:   hmacSecret,
+		hmacUntil:    hmacUntil,
+		clock:        SystemClock{},
+	}
+
+	if err := ring.Reload(); err != nil {
+		return nil, err
+	}
+
+	return ring, nil
+}
+
+// Reload перечитывает ключи из директории (для ротации без перезапуска)
+func (r *KeyRing) Reload() error {
+	if r.dir == "" {
+		return nil
+	}
+
+	entries, err := os.ReadDir(r.dir)
+	if err != nil {
+		return fmt.Errorf("failed to read keys dir: %w", err)
+	}
+
+	keys := make(map[string]*JWTKey)
+	var signable []string
+
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+
+		name := entry.Name()
+		path := filepath.Join(r.dir, name)
+
+		switch {
+		case strings.HasSuffix(name, publicKeyFileSuffix):
+			kid := strings.TrimSuffix(name, publicKeyFileSuffix)
+			key, err := loadPublicKeyFile(kid, path)
+			if err != nil {
+				return fmt.Errorf("failed to load public key %s: %w", name, err)
+			}
+			// Приватный ключ с тем же kid имеет приоритет
+			if _, exists := keys[kid]; !exists {
+				keys[kid] = key
+			}
+		case strings.HasSuffix(name, privateKeyFileSuffix):
+			kid := strings.TrimSuffix(name, privateKeyFileSuffix)
+			key, err := loadPrivateKeyFile(kid, path)
+			if err != nil {
+				return fmt.Errorf("failed to load private key %s: %w", name, err)
+			}
+			keys[kid] = key
+			signable = append(signable, kid)
+		}
+	}
+
+	if r.hmacSecret != "" {
+		hmacKey := newHMACKey(r.hmacSecret)
+		// HMAC ключ из файловой конфигурации используется только для проверки и только до hmacUntil
+		hmacKey.signKey = nil
+		hmacKey.notAfter = r.hmacUntil
+		keys[legacyHMACKeyID] = hmacKey
+	}
+
+	signingKID := r.preferredKID
+	if signingKID == "" {
+		sort.Strings(signable)
+		if len(signable) > 0 {
+			signingKID = signable[len(signable)-1]
+		}
+	}
+
+	active, ok := keys[signingKID]
+	if !ok || !active.CanSign() {
+		return fmt.Errorf("%w: kid %q", ErrNoSigningKey, signingKID)
+	}
+
+	r.mu.Lock()
+	r.keys = keys
+	r.signingKID = signingKID
+	r.mu.Unlock()
+
+	return nil
+}
+
+// SigningKey возвращает активный ключ подписи
+func (r *KeyRing) SigningKey() (*JWTKey, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	key, ok := r.keys[r.signingKID]
+	if !ok || !key.CanSign() {
+		return nil, ErrNoSigningKey
+	}
+	return key, nil
+}
+
+// Key возвращает ключ по kid
+func (r *KeyRing) Key(kid string) (*JWTKey, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	key, ok := r.keys[kid]
+	return key, ok
+}
+
+// Sign подписывает claims активным ключом и проставляет kid в заголовок
+func (r *KeyRing) Sign(claims jwt.Claims) (string, error) {
+	key, err := r.SigningKey()
+	if err != nil {
+		return "", err
+	}
+
+	token := jwt.NewWithClaims(key.SigningMethod(), claims)
+	token.Header["kid"] = key.KID
+
+	return token.SignedString(key.signKey)
+}
+
+// Keyfunc возвращает функцию выбора ключа проверки для jwt.Parse.
+// Ключ выбирается по kid, алгоритм токена обязан совпадать с алгоритмом ключа.
+// Токены без kid принимаются только как старые HS256 токены: в режиме JWT_SECRET — всегда,
+// при ротации ключей — пока действует HMAC ключ миграции.
+func (r *KeyRing) Keyfunc() jwt.Keyfunc {
+	return func(token *jwt.Token) (interface{}, error) {
+		now := r.clock.Now()
+
+		kid, _ := token.Header["kid"].(string)
+		if kid == "" {
+			// Токены, выпущенные до ротации, не содержат kid
+			if !r.acceptsTokenWithoutKID(now) {
+				return nil, ErrMissingKeyID
+			}
+			kid = legacyHMACKeyID
+		}
+
+		key, ok := r.Key(kid)
+		if !ok {
+			return nil, fmt.Errorf("%w: %s", ErrUnknownKeyID, kid)
+		}
+		if !key.notAfter.IsZero() && !now.Before(key.notAfter) {
+			return nil, fmt.Errorf("%w: %s", ErrKeyExpired, kid)
+		}
+
+		if token.Method.Alg() != key.Algorithm {
+			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+		}
+
+		return key.verifyKey, nil
+	}
+}
+
+// acceptsTokenWithoutKID сообщает, действует ли HMAC ключ для токенов без kid.
+// В режиме JWT_SECRET HMAC ключ единственный и подписывающий: токены, выпущенные до появления kid,
+// остаются действительными до истечения своего срока, иначе обновление разлогинит всех пользователей.
+func (r *KeyRing) acceptsTokenWithoutKID(now time.Time) bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	legacy, ok := r.keys[legacyHMACKeyID]
+	if !ok {
+		return false
+	}
+	if r.signingKID == legacyHMACKeyID {
+		return true
+	}
+	return !legacy.notAfter.IsZero() && now.Before(legacy.notAfter)
+}
+
+// ValidMethods возвращает список алгоритмов, допустимых для проверки
+func (r *KeyRing) ValidMethods() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	seen := make(map[string]bool)
+	var methods []string
+	for _, key := range r.keys {
+		if !seen[key.Algorithm] {
+			seen[key.Algorithm] = true
+			methods = append(methods, key.Algorithm)
+		}
+	}
+	sort.Strings(methods)
+	return methods
+}
+
+// JWKS возвращает публичные асимметричные ключи (HMAC ключи не публикуются)
+func (r *KeyRing) JWKS() JWKSet {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	set := JWKSet{Keys: []JWK{}}
+	for _, key := range r.keys {
+		switch pub := key.verifyKey.(type) {
+		case *rsa.PublicKey:
+			set.Keys = append(set.Keys, JWK{
+				KID: key.KID,
+				Kty: "RSA",
+				Alg: key.Algorithm,
+				Use: "sig",
+				N:   base64.RawURLEncoding.EncodeToString(pub.N.Bytes()),
+				E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pub.E)).Bytes()),
+			})
+		case ed25519.PublicKey:
+			set.Keys = append(set.Keys, JWK{
+				KID: key.KID,
+				Kty: "OKP",
+				Alg: key.Algorithm,
+				Use: "sig",
+				Crv: "Ed25519",
+				X:   base64.RawURLEncoding.EncodeToString(pub),
+			})
+		}
+	}
+
+	sort.Slice(set.Keys, func(i, j int) bool { return set.Keys[i].KID < set.Keys[j].KID })
+	return set
+}
+
+// newHMACKey создает HMAC ключ из секрета
+func newHMACKey(secret string) *JWTKey {
+	return &JWTKey{
+		KID:       legacyHMACKeyID,
+		Algorithm: JWTAlgHS256,
+		signKey:   []byte(secret),
+		verifyKey: []byte(secret),
+	}
+}
+
+// loadPrivateKeyFile загружает приватный ключ RSA или Ed25519 из PEM файла
+func loadPrivateKeyFile(kid, path string) (*JWTKey, error) {
+	data, err := os.ReadFile(path) //nolint:gosec // путь формируется из сконфигурированной директории
+	if err != nil {
+		return nil, err
+	}
+
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("invalid PEM data")
+	}
+
+	var parsed interface{}
+	switch block.Type {
+	case "RSA PRIVATE KEY":
+		parsed, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	default:
+		parsed, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	switch key := parsed.(type) {
+	case *rsa.PrivateKey:
+		if key.N.BitLen() < 2048 {
+			return nil, errors.New("RSA key must be at least 2048 bits")
+		}
+		return &JWTKey{KID: kid, Algorithm: JWTAlgRS256, signKey: key, verifyKey: &key.PublicKey}, nil
+	case ed25519.PrivateKey:
+		return &JWTKey{KID: kid, Algorithm: JWTAlgEdDSA, signKey: key, verifyKey: key.Public()}, nil
+	default:
+		return nil, ErrUnsupportedKeyType
+	}
+}
+
+// loadPublicKeyFile загружает публичный ключ RSA или Ed25519 из PEM файла
+func loadPublicKeyFile(kid, path string) (*JWTKey, error) {
+	data, err := os.ReadFile(path) //nolint:gosec // путь формируется из сконфигурированной директории
+	if err != nil {
+		return nil, err
+	}
+
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("invalid PEM data")
+	}
+
+	parsed, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+
+	switch key := parsed.(type) {
+	case *rsa.PublicKey:
+		return &JWTKey{KID: kid, Algorithm: JWTAlgRS256, verifyKey: key}, nil
+	case ed25519.PublicKey:
+		return &JWTKey{KID: kid, Algorithm: JWTAlgEdDSA, verifyKey: key}, nil
+	default:
+		return nil, ErrUnsupportedKeyType
+	}
+}
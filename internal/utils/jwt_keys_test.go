@@ -0,0 +1,260 @@
+package utils
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// writePrivateKey сохраняет приватный ключ в PKCS8 PEM
+func writePrivateKey(t *testing.T, dir, kid string, key interface{}) {
+	t.Helper()
+
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		t.Fatalf("Failed to marshal key: %v", err)
+	}
+
+	data := pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})
+	if err := os.WriteFile(filepath.Join(dir, kid+privateKeyFileSuffix), data, 0o600); err != nil {
+		t.Fatalf("Failed to write key: %v", err)
+	}
+}
+
+// writePublicKey сохраняет публичный ключ в PKIX PEM
+func writePublicKey(t *testing.T, dir, kid string, key interface{}) {
+	t.Helper()
+
+	der, err := x509.MarshalPKIXPublicKey(key)
+	if err != nil {
+		t.Fatalf("Failed to marshal key: %v", err)
+	}
+
+	data := pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der})
+	if err := os.WriteFile(filepath.Join(dir, kid+publicKeyFileSuffix), data, 0o600); err != nil {
+		t.Fatalf("Failed to write key: %v", err)
+	}
+}
+
+func TestKeyRing_SignAndValidate(t *testing.T) {
+	dir := t.TempDir()
+
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("Failed to generate RSA key: %v", err)
+	}
+	_, edKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("Failed to generate Ed25519 key: %v", err)
+	}
+
+	writePrivateKey(t, dir, "2025-01", rsaKey)
+	writePrivateKey(t, dir, "2025-02", edKey)
+
+	t.Run("signs with latest key by default", func(t *testing.T) {
+		ring, err := LoadKeyRing(dir, "", "", time.Time{})
+		if err != nil {
+			t.Fatalf("Failed to load key ring: %v", err)
+		}
+
+		key, err := ring.SigningKey()
+		if err != nil {
+			t.Fatalf("Expected signing key, got %v", err)
+		}
+		if key.KID != "2025-02" || key.Algorithm != JWTAlgEdDSA {
+			t.Errorf("Expected EdDSA key 2025-02, got %s %s", key.Algorithm, key.KID)
+		}
+
+		manager := NewJWTManagerWithKeyRing(ring)
+		token, err := manager.GenerateToken(42, "user", "user@example.com")
+		if err != nil {
+			t.Fatalf("Failed to generate token: %v", err)
+		}
+
+		claims, err := manager.ValidateToken(token)
+		if err != nil {
+			t.Fatalf("Expected valid token, got %v", err)
+		}
+		if claims.UserID != 42 {
+			t.Errorf("Expected user ID 42, got %d", claims.UserID)
+		}
+	})
+
+	t.Run("rotation keeps old tokens valid", func(t *testing.T) {
+		ring, err := LoadKeyRing(dir, "2025-01", "", time.Time{})
+		if err != nil {
+			t.Fatalf("Failed to load key ring: %v", err)
+		}
+		manager := NewJWTManagerWithKeyRing(ring)
+
+		oldToken, err := manager.GenerateToken(1, "user", "user@example.com")
+		if err != nil {
+			t.Fatalf("Failed to generate token: %v", err)
+		}
+
+		// Старый ключ остается только для проверки
+		if err := os.Remove(filepath.Join(dir, "2025-01"+privateKeyFileSuffix)); err != nil {
+			t.Fatalf("Failed to remove key: %v", err)
+		}
+		writePublicKey(t, dir, "2025-01", &rsaKey.PublicKey)
+		ring.preferredKID = ""
+		if err := ring.Reload(); err != nil {
+			t.Fatalf("Failed to reload key ring: %v", err)
+		}
+
+		if _, err := manager.ValidateToken(oldToken); err != nil {
+			t.Errorf("Expected old token to stay valid, got %v", err)
+		}
+
+		key, _ := ring.SigningKey()
+		if key.KID != "2025-02" {
+			t.Errorf("Expected signing key 2025-02 after rotation, got %s", key.KID)
+		}
+	})
+
+	t.Run("unknown kid is rejected", func(t *testing.T) {
+		otherDir := t.TempDir()
+		_, otherKey, _ := ed25519.GenerateKey(rand.Reader)
+		writePrivateKey(t, otherDir, "2025-02", otherKey)
+
+		other, err := LoadKeyRing(otherDir, "", "", time.Time{})
+		if err != nil {
+			t.Fatalf("Failed to load key ring: %v", err)
+		}
+		token, _ := NewJWTManagerWithKeyRing(other).GenerateToken(1, "user", "user@example.com")
+
+		ring, _ := LoadKeyRing(dir, "", "", time.Time{})
+		if _, err := NewJWTManagerWithKeyRing(ring).ValidateToken(token); err == nil {
+			t.Error("Expected token signed by foreign key to be rejected")
+		}
+	})
+}
+
+// fixedClock часы, стоящие на месте
+type fixedClock struct {
+	now time.Time
+}
+
+func (c fixedClock) Now() time.Time { return c.now }
+
+// signLegacyToken подписывает токен секретом HS256 без kid, как до ротации ключей
+func signLegacyToken(t *testing.T, secret string) string {
+	t.Helper()
+
+	claims := &JWTClaims{
+		UserID: 7,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour)),
+		},
+	}
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(secret))
+	if err != nil {
+		t.Fatalf("Failed to sign legacy token: %v", err)
+	}
+	return token
+}
+
+func TestKeyRing_LegacyHMAC(t *testing.T) {
+	dir := t.TempDir()
+	_, edKey, _ := ed25519.GenerateKey(rand.Reader)
+	writePrivateKey(t, dir, "2025-01", edKey)
+
+	// Токен с kid "hs256", выпущенный в режиме JWT_SECRET, и токен без kid
+	hmacToken, err := NewJWTManager("legacy-secret").GenerateToken(7, "user", "user@example.com")
+	if err != nil {
+		t.Fatalf("Failed to generate token: %v", err)
+	}
+	noKIDToken := signLegacyToken(t, "legacy-secret")
+
+	cutoff := time.Now().Add(24 * time.Hour)
+
+	tests := []struct {
+		name      string
+		secret    string
+		until     time.Time
+		now       time.Time
+		wantHMAC  error
+		wantNoKID error
+	}{
+		{name: "before cutoff", secret: "legacy-secret", until: cutoff, now: cutoff.Add(-time.Second)},
+		{name: "after cutoff", secret: "legacy-secret", until: cutoff, now: cutoff,
+			wantHMAC: ErrKeyExpired, wantNoKID: ErrMissingKeyID},
+		// Без HMAC ключа алгоритм HS256 не входит в допустимые
+		{name: "legacy HMAC not enabled", now: cutoff.Add(-time.Second),
+			wantHMAC: jwt.ErrTokenSignatureInvalid, wantNoKID: jwt.ErrTokenSignatureInvalid},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ring, err := LoadKeyRing(dir, "", tt.secret, tt.until)
+			if err != nil {
+				t.Fatalf("Failed to load key ring: %v", err)
+			}
+			ring.clock = fixedClock{now: tt.now}
+			manager := NewJWTManagerWithKeyRing(ring)
+
+			if _, err := manager.ValidateToken(hmacToken); !errorIsOrNil(err, tt.wantHMAC) {
+				t.Errorf("HS256 token: expected %v, got %v", tt.wantHMAC, err)
+			}
+			if _, err := manager.ValidateToken(noKIDToken); !errorIsOrNil(err, tt.wantNoKID) {
+				t.Errorf("token without kid: expected %v, got %v", tt.wantNoKID, err)
+			}
+
+			// HMAC ключ не публикуется и не используется для подписи
+			set := ring.JWKS()
+			if len(set.Keys) != 1 || set.Keys[0].Kty != "OKP" {
+				t.Errorf("Expected only Ed25519 key in JWKS, got %+v", set.Keys)
+			}
+			if key, _ := ring.SigningKey(); key.Algorithm != JWTAlgEdDSA {
+				t.Errorf("Expected EdDSA signing key, got %s", key.Algorithm)
+			}
+		})
+	}
+}
+
+func TestKeyRing_LegacyHMACRequiresCutoff(t *testing.T) {
+	dir := t.TempDir()
+	_, edKey, _ := ed25519.GenerateKey(rand.Reader)
+	writePrivateKey(t, dir, "2025-01", edKey)
+
+	if _, err := LoadKeyRing(dir, "", "legacy-secret", time.Time{}); err == nil {
+		t.Error("Expected error for legacy HMAC secret without cutoff")
+	}
+}
+
+func TestKeyRing_HMACModeAcceptsTokenWithoutKID(t *testing.T) {
+	manager := NewJWTManager("secret")
+
+	token, err := manager.GenerateToken(7, "user", "user@example.com")
+	if err != nil {
+		t.Fatalf("Failed to generate token: %v", err)
+	}
+	if _, err := manager.ValidateToken(token); err != nil {
+		t.Errorf("Expected token with kid to be valid, got %v", err)
+	}
+
+	// Токены, выпущенные до появления kid, остаются действительными в режиме JWT_SECRET
+	if _, err := manager.ValidateToken(signLegacyToken(t, "secret")); err != nil {
+		t.Errorf("Expected token without kid to be valid, got %v", err)
+	}
+	if _, err := manager.ValidateToken(signLegacyToken(t, "other-secret")); !errors.Is(err, ErrInvalidSignature) {
+		t.Errorf("Expected ErrInvalidSignature, got %v", err)
+	}
+}
+
+// errorIsOrNil проверяет, что err соответствует want, а при want == nil — что ошибки нет
+func errorIsOrNil(err, want error) bool {
+	if want == nil {
+		return err == nil
+	}
+	return errors.Is(err, want)
+}
@@ -0,0 +1,156 @@
+package utils
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+var (
+	// ErrInvalidINN возвращается при некорректном ИНН
+	ErrInvalidINN = errors.New("invalid INN")
+	// ErrInvalidSNILS возвращается при некорректном СНИЛС
+	ErrInvalidSNILS = errors.New("invalid SNILS")
+	// ErrInvalidPassport возвращается при некорректных серии, номере или коде подразделения паспорта
+	ErrInvalidPassport = errors.New("invalid passport data")
+	// ErrInvalidPhone возвращается при некорректном номере телефона
+	ErrInvalidPhone = errors.New("invalid phone number")
+)
+
+// passportSeriesRegex серия паспорта РФ (4 цифры)
+var passportSeriesRegex = regexp.MustCompile(`^\d{4}$`)
+
+// passportNumberRegex номер паспорта РФ (6 цифр)
+var passportNumberRegex = regexp.MustCompile(`^\d{6}$`)
+
+// divisionCodeRegex код подразделения, выдавшего паспорт (XXX-XXX)
+var divisionCodeRegex = regexp.MustCompile(`^\d{3}-\d{3}$`)
+
+// innWeights10 и innWeights11/12 веса контрольных цифр ИНН юридического и физического лица
+var (
+	innWeights10 = []int{2, 4, 10, 3, 5, 9, 4, 6, 8}
+	innWeights11 = []int{7, 2, 4, 10, 3, 5, 9, 4, 6, 8}
+	innWeights12 = []int{3, 7, 2, 4, 10, 3, 5, 9, 4, 6, 8}
+)
+
+// DigitsOnly оставляет в строке только цифры (для номеров, введенных с пробелами и дефисами)
+func DigitsOnly(s string) string {
+	return strings.Map(func(r rune) rune {
+		if r >= '0' && r <= '9' {
+			return r
+		}
+		return -1
+	}, s)
+}
+
+// ValidateINN проверяет ИНН: 10 цифр (организация) или 12 цифр (физическое лицо) с контрольными цифрами
+func ValidateINN(inn string) error {
+	if strings.TrimSpace(inn) == "" {
+		return ErrEmptyField
+	}
+	if DigitsOnly(inn) != inn {
+		return ErrInvalidINN
+	}
+
+	digits := []byte(inn)
+	switch len(digits) {
+	case 10:
+		if innCheckDigit(digits, innWeights10) != digits[9]-'0' {
+			return ErrInvalidINN
+		}
+	case 12:
+		if innCheckDigit(digits, innWeights11) != digits[10]-'0' || innCheckDigit(digits, innWeights12) != digits[11]-'0' {
+			return ErrInvalidINN
+		}
+	default:
+		return ErrInvalidINN
+	}
+
+	return nil
+}
+
+// innCheckDigit вычисляет контрольную цифру ИНН по весам
+func innCheckDigit(digits []byte, weights []int) byte {
+	sum := 0
+	for i, weight := range weights {
+		sum += int(digits[i]-'0') * weight
+	}
+	return byte(sum % 11 % 10)
+}
+
+// ValidateSNILS проверяет СНИЛС из 11 цифр (без разделителей) по контрольному числу
+func ValidateSNILS(snils string) error {
+	if strings.TrimSpace(snils) == "" {
+		return ErrEmptyField
+	}
+	if len(snils) != 11 || DigitsOnly(snils) != snils {
+		return ErrInvalidSNILS
+	}
+
+	sum := 0
+	for i := 0; i < 9; i++ {
+		sum += int(snils[i]-'0') * (9 - i)
+	}
+
+	// Контрольное число: сумма меньше 100 — сама сумма, 100 и 101 — 00, больше — остаток от деления на 101
+	check := sum
+	if sum >= 100 {
+		check = sum % 101
+		if check == 100 {
+			check = 0
+		}
+	}
+
+	if int(snils[9]-'0')*10+int(snils[10]-'0') != check {
+		return ErrInvalidSNILS
+	}
+
+	return nil
+}
+
+// FormatSNILS форматирует СНИЛС из 11 цифр как XXX-XXX-XXX YY
+func FormatSNILS(snils string) string {
+	if len(snils) != 11 {
+		return snils
+	}
+	return snils[0:3] + "-" + snils[3:6] + "-" + snils[6:9] + " " + snils[9:11]
+}
+
+// ValidatePassport проверяет серию, номер и код подразделения паспорта гражданина РФ
+func ValidatePassport(series, number, divisionCode string) error {
+	if !passportSeriesRegex.MatchString(series) || !passportNumberRegex.MatchString(number) {
+		return ErrInvalidPassport
+	}
+	if !divisionCodeRegex.MatchString(divisionCode) {
+		return ErrInvalidPassport
+	}
+	return nil
+}
+
+// NormalizePhone приводит российский номер телефона к виду +7XXXXXXXXXX.
+// Допускаются номера, начинающиеся с +7, 7 или 8, и 10-значные номера без кода страны.
+func NormalizePhone(phone string) (string, error) {
+	if strings.TrimSpace(phone) == "" {
+		return "", ErrEmptyField
+	}
+	for _, r := range phone {
+		if !unicode.IsDigit(r) && !strings.ContainsRune("+-() ", r) {
+			return "", ErrInvalidPhone
+		}
+	}
+
+	digits := DigitsOnly(phone)
+	international := strings.HasPrefix(strings.TrimSpace(phone), "+")
+	switch {
+	case international && (len(digits) != 11 || digits[0] != '7'):
+		return "", ErrInvalidPhone
+	case len(digits) == 11 && (digits[0] == '7' || digits[0] == '8'):
+		digits = digits[1:]
+	case len(digits) == 10:
+	default:
+		return "", ErrInvalidPhone
+	}
+
+	return "+7" + digits, nil
+}
@@ -0,0 +1,96 @@
+package utils
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestValidateINN(t *testing.T) {
+	tests := []struct {
+		inn     string
+		wantErr error
+	}{
+		{"500100732259", nil},
+		{"7707083893", nil},
+		{"500100732258", ErrInvalidINN},
+		{"500100732269", ErrInvalidINN},
+		{"7707083894", ErrInvalidINN},
+		{"50010073225", ErrInvalidINN},
+		{"5001 0073 2259", ErrInvalidINN},
+		{"", ErrEmptyField},
+	}
+
+	for _, tt := range tests {
+		if err := ValidateINN(tt.inn); !errors.Is(err, tt.wantErr) {
+			t.Errorf("ValidateINN(%q) = %v, want %v", tt.inn, err, tt.wantErr)
+		}
+	}
+}
+
+func TestValidateSNILS(t *testing.T) {
+	tests := []struct {
+		snils   string
+		wantErr error
+	}{
+		{"11223344595", nil},
+		{"12345678964", nil},
+		// Сумма больше 101: контрольное число - остаток от деления на 101
+		{"08765430300", nil},
+		{"11223344596", ErrInvalidSNILS},
+		{"112-233-445 95", ErrInvalidSNILS},
+		{"1122334459", ErrInvalidSNILS},
+		{"", ErrEmptyField},
+	}
+
+	for _, tt := range tests {
+		if err := ValidateSNILS(tt.snils); !errors.Is(err, tt.wantErr) {
+			t.Errorf("ValidateSNILS(%q) = %v, want %v", tt.snils, err, tt.wantErr)
+		}
+	}
+
+	if got := FormatSNILS(DigitsOnly("112-233-445 95")); got != "112-233-445 95" {
+		t.Errorf("FormatSNILS() = %q", got)
+	}
+}
+
+func TestValidatePassport(t *testing.T) {
+	tests := []struct {
+		series, number, division string
+		valid                    bool
+	}{
+		{"4510", "123456", "770-001", true},
+		{"451", "123456", "770-001", false},
+		{"4510", "12345a", "770-001", false},
+		{"4510", "123456", "770001", false},
+	}
+
+	for _, tt := range tests {
+		err := ValidatePassport(tt.series, tt.number, tt.division)
+		if tt.valid != (err == nil) {
+			t.Errorf("ValidatePassport(%q, %q, %q) = %v", tt.series, tt.number, tt.division, err)
+		}
+	}
+}
+
+func TestNormalizePhone(t *testing.T) {
+	tests := []struct {
+		phone   string
+		want    string
+		wantErr error
+	}{
+		{"+7 (916) 123-45-67", "+79161234567", nil},
+		{"89161234567", "+79161234567", nil},
+		{"9161234567", "+79161234567", nil},
+		{"+1 916 123 45 67", "", ErrInvalidPhone},
+		{"+7 916 123 45 6", "", ErrInvalidPhone},
+		{"+7 916 CALL-ME", "", ErrInvalidPhone},
+		{" ", "", ErrEmptyField},
+	}
+
+	for _, tt := range tests {
+		got, err := NormalizePhone(tt.phone)
+		if !errors.Is(err, tt.wantErr) || got != tt.want {
+			t.Errorf("NormalizePhone(%q) = %q, %v; want %q, %v", tt.phone, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
@@ -0,0 +1,167 @@
+package utils
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// cyrillicToLatin транслитерация кириллицы в латиницу (близко к ГОСТ Р 52535.1-2006, как в загранпаспортах)
+var cyrillicToLatin = map[rune]string{
+	'а': "a", 'б': "b", 'в': "v", 'г': "g", 'д': "d", 'е': "e", 'ё': "e", 'ж': "zh",
+	'з': "z", 'и': "i", 'й': "i", 'к': "k", 'л': "l", 'м': "m", 'н': "n", 'о': "o",
+	'п': "p", 'р': "r", 'с': "s", 'т': "t", 'у': "u", 'ф': "f", 'х': "kh", 'ц': "ts",
+	'ч': "ch", 'ш': "sh", 'щ': "shch", 'ъ': "", 'ы': "y", 'ь': "", 'э': "e", 'ю': "iu",
+	'я': "ia", 'і': "i", 'ї': "i", 'є': "e", 'ґ': "g", 'ў': "u",
+}
+
+// latinFolds сводит разные системы латинской транслитерации к одному написанию:
+// Yuriy, Iurii и Jurij, Khodorkovsky и Hodorkovskiy совпадают после свертки
+var latinFolds = strings.NewReplacer(
+	"shch", "sh", "sch", "sh", "tch", "ch",
+	"kh", "h", "ck", "k", "ph", "f", "tz", "c", "ts", "c",
+	"yu", "iu", "ju", "iu", "ya", "ia", "ja", "ia", "yo", "e", "jo", "e", "ye", "e",
+	"w", "v", "q", "k", "x", "ks", "y", "i", "j", "i",
+)
+
+// NormalizeName разбивает имя на слова в латинице для нечеткого сравнения.
+// Кириллица транслитерируется, варианты транслитерации сворачиваются, повторы букв схлопываются;
+// цифры, знаки и однобуквенные слова (инициалы) отбрасываются.
+func NormalizeName(name string) []string {
+	var tokens []string
+	for _, word := range strings.FieldsFunc(strings.ToLower(name), func(r rune) bool { return !unicode.IsLetter(r) }) {
+		var latin strings.Builder
+		for _, r := range word {
+			if translit, ok := cyrillicToLatin[r]; ok {
+				latin.WriteString(translit)
+			} else {
+				latin.WriteRune(r)
+			}
+		}
+
+		token := collapseRepeats(latinFolds.Replace(latin.String()))
+		if len([]rune(token)) > 1 {
+			tokens = append(tokens, token)
+		}
+	}
+	return tokens
+}
+
+// NameSimilarity оценивает похожесть двух имен от 0 до 1 независимо от порядка слов.
+// Слова короткого имени сопоставляются словам длинного по Джаро-Винклеру; для имен
+// из нескольких слов совпадения одного слова недостаточно (Ivan не совпадает с Ivan Petrov).
+func NameSimilarity(a, b string) float64 {
+	return TokensSimilarity(NormalizeName(a), NormalizeName(b))
+}
+
+// TokensSimilarity то же, что NameSimilarity, для уже нормализованных имен
+func TokensSimilarity(a, b []string) float64 {
+	if len(a) == 0 || len(b) == 0 {
+		return 0
+	}
+	short, long := a, b
+	if len(short) > len(long) {
+		short, long = long, short
+	}
+
+	// Жадное сопоставление: сначала самые похожие пары слов
+	type pair struct {
+		i, j  int
+		score float64
+	}
+	pairs := make([]pair, 0, len(short)*len(long))
+	for i, s := range short {
+		for j, l := range long {
+			pairs = append(pairs, pair{i: i, j: j, score: JaroWinkler(s, l)})
+		}
+	}
+	sort.SliceStable(pairs, func(x, y int) bool { return pairs[x].score > pairs[y].score })
+
+	usedShort := make([]bool, len(short))
+	usedLong := make([]bool, len(long))
+	var total float64
+	for _, p := range pairs {
+		if usedShort[p.i] || usedLong[p.j] {
+			continue
+		}
+		usedShort[p.i], usedLong[p.j] = true, true
+		total += p.score
+	}
+
+	denominator := len(short)
+	if len(long) > 1 && denominator < 2 {
+		denominator = 2
+	}
+	return total / float64(denominator)
+}
+
+// JaroWinkler вычисляет сходство строк по Джаро-Винклеру (1 — совпадают)
+func JaroWinkler(a, b string) float64 {
+	s1, s2 := []rune(a), []rune(b)
+	if len(s1) == 0 && len(s2) == 0 {
+		return 1
+	}
+	if len(s1) == 0 || len(s2) == 0 {
+		return 0
+	}
+
+	window := max(len(s1), len(s2))/2 - 1
+	if window < 0 {
+		window = 0
+	}
+
+	matched1 := make([]bool, len(s1))
+	matched2 := make([]bool, len(s2))
+	matches := 0
+	for i := range s1 {
+		for j := max(0, i-window); j < min(len(s2), i+window+1); j++ {
+			if !matched2[j] && s1[i] == s2[j] {
+				matched1[i], matched2[j] = true, true
+				matches++
+				break
+			}
+		}
+	}
+	if matches == 0 {
+		return 0
+	}
+
+	transpositions := 0
+	k := 0
+	for i := range s1 {
+		if !matched1[i] {
+			continue
+		}
+		for !matched2[k] {
+			k++
+		}
+		if s1[i] != s2[k] {
+			transpositions++
+		}
+		k++
+	}
+
+	m := float64(matches)
+	jaro := (m/float64(len(s1)) + m/float64(len(s2)) + (m-float64(transpositions)/2)/m) / 3
+
+	// Бонус за общий префикс до 4 символов
+	prefix := 0
+	for prefix < min(4, len(s1), len(s2)) && s1[prefix] == s2[prefix] {
+		prefix++
+	}
+	return jaro + float64(prefix)*0.1*(1-jaro)
+}
+
+// collapseRepeats схлопывает подряд идущие одинаковые буквы
+func collapseRepeats(s string) string {
+	var b strings.Builder
+	var prev rune
+	for i, r := range s {
+		if i > 0 && r == prev {
+			continue
+		}
+		b.WriteRune(r)
+		prev = r
+	}
+	return b.String()
+}
@@ -0,0 +1,74 @@
+package utils
+
+import (
+	"math"
+	"reflect"
+	"testing"
+)
+
+func TestNormalizeName(t *testing.T) {
+	tests := []struct {
+		name string
+		want []string
+	}{
+		{"Юрий Ходорковский", []string{"iuri", "hodorkovski"}},
+		{"Yuriy KHODORKOVSKY", []string{"iuri", "hodorkovski"}},
+		{"Jurij Hodorkovskij", []string{"iuri", "hodorkovski"}},
+		{"ivan_petrov42", []string{"ivan", "petrov"}},
+		{"V. Putin", []string{"putin"}},
+		{"Щукин Александр", []string{"shukin", "aleksandr"}},
+		{"Shchukin Alexander", []string{"shukin", "aleksander"}},
+		{"", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := NormalizeName(tt.name); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("NormalizeName(%q) = %v, want %v", tt.name, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestJaroWinkler(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want float64
+	}{
+		{"martha", "marhta", 0.961},
+		{"dixon", "dicksonx", 0.813},
+		{"abc", "abc", 1},
+		{"abc", "xyz", 0},
+		{"", "abc", 0},
+	}
+
+	for _, tt := range tests {
+		if got := JaroWinkler(tt.a, tt.b); math.Abs(got-tt.want) > 0.001 {
+			t.Errorf("JaroWinkler(%q, %q) = %.3f, want %.3f", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestNameSimilarity(t *testing.T) {
+	tests := []struct {
+		a, b     string
+		min, max float64
+	}{
+		// Транслитерация и порядок слов
+		{"Иванов Иван", "Ivan Ivanov", 1, 1},
+		{"Aleksandr Shchukin", "Alexander Schukin", 0.9, 1},
+		// Отчество в списке не мешает совпадению
+		{"Ivan Petrov", "Петров Иван Сергеевич", 1, 1},
+		// Одного общего имени недостаточно
+		{"Ivan", "Ivan Petrov", 0, 0.5},
+		{"Ivan Petrov", "Maria Sidorova", 0, 0.7},
+		{"", "Ivan Petrov", 0, 0},
+	}
+
+	for _, tt := range tests {
+		got := NameSimilarity(tt.a, tt.b)
+		if got < tt.min || got > tt.max {
+			t.Errorf("NameSimilarity(%q, %q) = %.3f, want [%.2f, %.2f]", tt.a, tt.b, got, tt.min, tt.max)
+		}
+	}
+}
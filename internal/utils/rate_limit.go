@@ -0,0 +1,75 @@
+package utils
+
+import (
+	"math"
+	"time"
+)
+
+// RateLimit лимит token bucket: корзина вмещает Requests запросов и полностью восполняется за Period
+type RateLimit struct {
+	Requests int
+	Period   time.Duration
+}
+
+// Enabled сообщает, задан ли лимит
+func (l RateLimit) Enabled() bool {
+	return l.Requests > 0 && l.Period > 0
+}
+
+// refillTime время восполнения tokens токенов
+func (l RateLimit) refillTime(tokens float64) time.Duration {
+	if tokens <= 0 {
+		return 0
+	}
+	return time.Duration(math.Ceil(tokens * float64(l.Period) / float64(l.Requests)))
+}
+
+// TokenBucket состояние корзины токенов
+type TokenBucket struct {
+	Tokens    float64
+	UpdatedAt time.Time
+}
+
+// NewTokenBucket возвращает полную корзину
+func NewTokenBucket(limit RateLimit, now time.Time) TokenBucket {
+	return TokenBucket{Tokens: float64(limit.Requests), UpdatedAt: now}
+}
+
+// RateLimitDecision результат попытки взять токен
+type RateLimitDecision struct {
+	Allowed bool
+	// Remaining целое число оставшихся токенов
+	Remaining int
+	// Reset время до полного восполнения корзины
+	Reset time.Duration
+	// RetryAfter время до появления следующего токена; 0, если запрос разрешен
+	RetryAfter time.Duration
+}
+
+// Take восполняет корзину по прошедшему с UpdatedAt времени и пытается взять один токен
+func (b *TokenBucket) Take(limit RateLimit, now time.Time) RateLimitDecision {
+	capacity := float64(limit.Requests)
+
+	// Часы другого экземпляра могут отставать: время назад не откатываем
+	if elapsed := now.Sub(b.UpdatedAt); elapsed > 0 {
+		b.Tokens = math.Min(capacity, b.Tokens+elapsed.Seconds()*capacity/limit.Period.Seconds())
+		b.UpdatedAt = now
+	}
+
+	decision := RateLimitDecision{}
+	if b.Tokens >= 1 {
+		b.Tokens--
+		decision.Allowed = true
+	} else {
+		decision.RetryAfter = limit.refillTime(1 - b.Tokens)
+	}
+
+	decision.Remaining = int(math.Floor(b.Tokens))
+	decision.Reset = limit.refillTime(capacity - b.Tokens)
+	return decision
+}
+
+// FullAt момент, когда корзина восполнится полностью; после него состояние можно не хранить
+func (b TokenBucket) FullAt(limit RateLimit) time.Time {
+	return b.UpdatedAt.Add(limit.refillTime(float64(limit.Requests) - b.Tokens))
+}
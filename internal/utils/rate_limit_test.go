@@ -0,0 +1,87 @@
+package utils
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTokenBucketTake(t *testing.T) {
+	limit := RateLimit{Requests: 3, Period: 30 * time.Second}
+	now := time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+	bucket := NewTokenBucket(limit, now)
+
+	for i := 2; i >= 0; i-- {
+		decision := bucket.Take(limit, now)
+		if !decision.Allowed || decision.Remaining != i {
+			t.Fatalf("request %d: got allowed=%v remaining=%d, want allowed remaining=%d",
+				3-i, decision.Allowed, decision.Remaining, i)
+		}
+	}
+
+	decision := bucket.Take(limit, now)
+	if decision.Allowed {
+		t.Fatal("fourth request should be rejected")
+	}
+	if decision.RetryAfter != 10*time.Second {
+		t.Errorf("RetryAfter = %v, want 10s", decision.RetryAfter)
+	}
+	if decision.Reset != 30*time.Second {
+		t.Errorf("Reset = %v, want 30s", decision.Reset)
+	}
+
+	// Через 10 секунд восполняется ровно один токен
+	decision = bucket.Take(limit, now.Add(10*time.Second))
+	if !decision.Allowed || decision.Remaining != 0 {
+		t.Errorf("after refill: got allowed=%v remaining=%d, want allowed remaining=0", decision.Allowed, decision.Remaining)
+	}
+
+	// Корзина не переполняется сверх емкости
+	decision = bucket.Take(limit, now.Add(time.Hour))
+	if !decision.Allowed || decision.Remaining != 2 {
+		t.Errorf("after idle: got allowed=%v remaining=%d, want allowed remaining=2", decision.Allowed, decision.Remaining)
+	}
+}
+
+func TestTokenBucketPartialRefill(t *testing.T) {
+	limit := RateLimit{Requests: 2, Period: 10 * time.Second}
+	now := time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+	bucket := TokenBucket{Tokens: 0, UpdatedAt: now}
+
+	decision := bucket.Take(limit, now.Add(2*time.Second))
+	if decision.Allowed {
+		t.Fatal("request with 0.4 tokens should be rejected")
+	}
+	if decision.RetryAfter != 3*time.Second {
+		t.Errorf("RetryAfter = %v, want 3s", decision.RetryAfter)
+	}
+	if got, want := bucket.FullAt(limit), now.Add(10*time.Second); !got.Equal(want) {
+		t.Errorf("FullAt = %v, want %v", got, want)
+	}
+}
+
+func TestTokenBucketClockSkew(t *testing.T) {
+	limit := RateLimit{Requests: 1, Period: time.Minute}
+	now := time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+	bucket := TokenBucket{Tokens: 0.5, UpdatedAt: now}
+
+	// Экземпляр с отстающими часами не должен ни списывать, ни начислять токены за "отрицательное" время
+	decision := bucket.Take(limit, now.Add(-time.Minute))
+	if decision.Allowed {
+		t.Fatal("request should be rejected")
+	}
+	if bucket.Tokens != 0.5 || !bucket.UpdatedAt.Equal(now) {
+		t.Errorf("bucket changed: %+v", bucket)
+	}
+}
+
+func TestRateLimitEnabled(t *testing.T) {
+	if (RateLimit{Requests: 0, Period: time.Minute}).Enabled() {
+		t.Error("zero requests should disable the limit")
+	}
+	if (RateLimit{Requests: 10}).Enabled() {
+		t.Error("zero period should disable the limit")
+	}
+	if !(RateLimit{Requests: 10, Period: time.Minute}).Enabled() {
+		t.Error("limit should be enabled")
+	}
+}
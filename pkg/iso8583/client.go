@@ -0,0 +1,90 @@
+package iso8583
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"os"
+	"time"
+)
+
+// Client отправляет сообщения шлюзу по TCP и ждет ответа на каждое.
+// Не предназначен для параллельного использования.
+type Client struct {
+	conn    net.Conn
+	timeout time.Duration
+	macKey  []byte
+}
+
+// Dial подключается к шлюзу; сообщения подписываются и ответы проверяются ключом MAC шлюза
+func Dial(addr string, timeout time.Duration, macKey []byte) (*Client, error) {
+	conn, err := net.DialTimeout("tcp", addr, timeout)
+	if err != nil {
+		return nil, err
+	}
+	return &Client{conn: conn, timeout: timeout, macKey: macKey}, nil
+}
+
+// Send отправляет сообщение и возвращает ответ шлюза
+func (c *Client) Send(m *Message) (*Message, error) {
+	packed, err := Sign(m, c.macKey)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := c.conn.SetDeadline(time.Now().Add(c.timeout)); err != nil {
+		return nil, err
+	}
+	if err := WriteFrame(c.conn, packed); err != nil {
+		return nil, fmt.Errorf("failed to send message: %w", err)
+	}
+
+	frame, err := ReadFrame(c.conn)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+	response, err := Unpack(frame)
+	if err != nil {
+		return nil, err
+	}
+	if err := Verify(response, c.macKey); err != nil {
+		return nil, fmt.Errorf("failed to verify response: %w", err)
+	}
+	return response, nil
+}
+
+// Close закрывает соединение
+func (c *Client) Close() error {
+	return c.conn.Close()
+}
+
+// Fixture сообщение для воспроизведения и ожидаемые поля ответа
+type Fixture struct {
+	Name    string         `json:"name"`
+	Request *Message       `json:"request"`
+	Expect  map[int]string `json:"expect"`
+}
+
+// LoadFixtures читает JSON-массив фикстур из файла
+func LoadFixtures(path string) ([]Fixture, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var fixtures []Fixture
+	if err := json.Unmarshal(data, &fixtures); err != nil {
+		return nil, fmt.Errorf("failed to parse fixtures %s: %w", path, err)
+	}
+	return fixtures, nil
+}
+
+// Check сравнивает ответ с ожидаемыми полями фикстуры
+func (f *Fixture) Check(response *Message) error {
+	for field, expected := range f.Expect {
+		if actual := response.Get(field); actual != expected {
+			return fmt.Errorf("%s: field %d = %q, expected %q", f.Name, field, actual, expected)
+		}
+	}
+	return nil
+}
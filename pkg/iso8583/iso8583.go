@@ -0,0 +1,328 @@
+// Package iso8583 реализует упрощенный ASCII-вариант сообщений ISO 8583:
+// MTI из четырех цифр, двоичные битовые карты (первичная и вторичная) и поля в ASCII.
+// По TCP сообщения передаются кадрами с двухбайтовым префиксом длины (big-endian).
+// Подлинность сообщения подтверждается MAC в поле 128 на общем ключе (Sign, Verify).
+package iso8583
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Поля сообщения
+const (
+	FieldPAN                  = 2
+	FieldProcessingCode       = 3
+	FieldAmount               = 4
+	FieldTransmissionDateTime = 7
+	FieldSTAN                 = 11
+	FieldLocalTime            = 12
+	FieldLocalDate            = 13
+	FieldExpiryDate           = 14
+	FieldPOSEntryMode         = 22
+	FieldRRN                  = 37
+	FieldAuthCode             = 38
+	FieldResponseCode         = 39
+	FieldTerminalID           = 41
+	FieldMerchantID           = 42
+	FieldMerchantName         = 43
+	FieldAdditionalData       = 48 // CVV2 карты
+	FieldCurrencyCode         = 49
+	FieldOriginalData         = 90
+	FieldMAC                  = 128 // MAC сообщения, см. Sign
+)
+
+// Типы сообщений (MTI)
+const (
+	MTIAuthorizationRequest  = "0100"
+	MTIAuthorizationResponse = "0110"
+	MTIFinancialRequest      = "0200"
+	MTIFinancialResponse     = "0210"
+	MTIReversalRequest       = "0400"
+	MTIReversalResponse      = "0410"
+)
+
+// maxFrameSize максимальный размер сообщения в кадре
+const maxFrameSize = 1<<16 - 1
+
+var (
+	ErrInvalidMTI       = errors.New("iso8583: invalid MTI")
+	ErrUnsupportedField = errors.New("iso8583: unsupported field")
+	ErrInvalidField     = errors.New("iso8583: invalid field value")
+	ErrShortMessage     = errors.New("iso8583: message is truncated")
+	ErrFrameTooLarge    = errors.New("iso8583: frame is too large")
+)
+
+// Message сообщение ISO 8583. В JSON поля передаются объектом с номерами полей в качестве ключей.
+type Message struct {
+	MTI    string         `json:"mti"`
+	Fields map[int]string `json:"fields"`
+}
+
+// NewMessage создает пустое сообщение указанного типа
+func NewMessage(mti string) *Message {
+	return &Message{MTI: mti, Fields: make(map[int]string)}
+}
+
+// Get возвращает значение поля без выравнивающих пробелов
+func (m *Message) Get(field int) string {
+	return strings.TrimSpace(m.Fields[field])
+}
+
+// Has проверяет наличие непустого поля
+func (m *Message) Has(field int) bool {
+	return m.Get(field) != ""
+}
+
+// Set устанавливает значение поля
+func (m *Message) Set(field int, value string) {
+	if m.Fields == nil {
+		m.Fields = make(map[int]string)
+	}
+	m.Fields[field] = value
+}
+
+// Response создает ответ на сообщение, копируя перечисленные поля запроса
+func (m *Message) Response(echo ...int) (*Message, error) {
+	mti, err := ResponseMTI(m.MTI)
+	if err != nil {
+		return nil, err
+	}
+
+	response := NewMessage(mti)
+	for _, field := range echo {
+		if value, ok := m.Fields[field]; ok {
+			response.Fields[field] = value
+		}
+	}
+	return response, nil
+}
+
+// ResponseMTI возвращает тип ответа на запрос или уведомление: 0100 -> 0110, 0420 -> 0430
+func ResponseMTI(mti string) (string, error) {
+	if !isMTI(mti) || (mti[2] != '0' && mti[2] != '2') {
+		return "", ErrInvalidMTI
+	}
+	return mti[:2] + string(mti[2]+1) + mti[3:], nil
+}
+
+// fieldSpec формат поля: фиксированной длины или с префиксом длины из 2 (LLVAR) или 3 (LLLVAR) цифр
+type fieldSpec struct {
+	length  int
+	prefix  int
+	numeric bool
+}
+
+var fieldSpecs = map[int]fieldSpec{
+	FieldPAN:                  {length: 19, prefix: 2, numeric: true},
+	FieldProcessingCode:       {length: 6, numeric: true},
+	FieldAmount:               {length: 12, numeric: true},
+	FieldTransmissionDateTime: {length: 10, numeric: true},
+	FieldSTAN:                 {length: 6, numeric: true},
+	FieldLocalTime:            {length: 6, numeric: true},
+	FieldLocalDate:            {length: 4, numeric: true},
+	FieldExpiryDate:           {length: 4, numeric: true},
+	FieldPOSEntryMode:         {length: 3, numeric: true},
+	FieldRRN:                  {length: 12},
+	FieldAuthCode:             {length: 6},
+	FieldResponseCode:         {length: 2},
+	FieldTerminalID:           {length: 8},
+	FieldMerchantID:           {length: 15},
+	FieldMerchantName:         {length: 40},
+	FieldAdditionalData:       {length: 999, prefix: 3},
+	FieldCurrencyCode:         {length: 3, numeric: true},
+	FieldOriginalData:         {length: 42, numeric: true},
+	FieldMAC:                  {length: macLength},
+}
+
+// Pack кодирует сообщение. Числовые поля фиксированной длины дополняются нулями слева,
+// текстовые - пробелами справа.
+func Pack(m *Message) ([]byte, error) {
+	if !isMTI(m.MTI) {
+		return nil, ErrInvalidMTI
+	}
+
+	fields := make([]int, 0, len(m.Fields))
+	secondary := false
+	for field := range m.Fields {
+		if _, ok := fieldSpecs[field]; !ok {
+			return nil, fmt.Errorf("%w: %d", ErrUnsupportedField, field)
+		}
+		if field > 64 {
+			secondary = true
+		}
+		fields = append(fields, field)
+	}
+	sort.Ints(fields)
+
+	bitmap := make([]byte, 8)
+	if secondary {
+		bitmap = make([]byte, 16)
+		bitmap[0] |= 0x80
+	}
+
+	var body strings.Builder
+	for _, field := range fields {
+		encoded, err := encodeField(field, fieldSpecs[field], m.Fields[field])
+		if err != nil {
+			return nil, err
+		}
+		bitmap[(field-1)/8] |= 0x80 >> ((field - 1) % 8)
+		body.WriteString(encoded)
+	}
+
+	packed := make([]byte, 0, 4+len(bitmap)+body.Len())
+	packed = append(packed, m.MTI...)
+	packed = append(packed, bitmap...)
+	packed = append(packed, body.String()...)
+	return packed, nil
+}
+
+// Unpack декодирует сообщение
+func Unpack(data []byte) (*Message, error) {
+	if len(data) < 12 {
+		return nil, ErrShortMessage
+	}
+
+	m := NewMessage(string(data[:4]))
+	if !isMTI(m.MTI) {
+		return nil, ErrInvalidMTI
+	}
+
+	bitmap := data[4:12]
+	pos := 12
+	if bitmap[0]&0x80 != 0 {
+		if len(data) < 20 {
+			return nil, ErrShortMessage
+		}
+		bitmap = data[4:20]
+		pos = 20
+	}
+
+	for field := 2; field <= len(bitmap)*8; field++ {
+		if bitmap[(field-1)/8]&(0x80>>((field-1)%8)) == 0 {
+			continue
+		}
+		spec, ok := fieldSpecs[field]
+		if !ok {
+			return nil, fmt.Errorf("%w: %d", ErrUnsupportedField, field)
+		}
+
+		value, next, err := decodeField(field, spec, data, pos)
+		if err != nil {
+			return nil, err
+		}
+		m.Fields[field] = value
+		pos = next
+	}
+
+	if pos != len(data) {
+		return nil, fmt.Errorf("%w: %d trailing bytes", ErrInvalidField, len(data)-pos)
+	}
+
+	return m, nil
+}
+
+// ReadFrame читает одно сообщение с двухбайтовым префиксом длины
+func ReadFrame(r io.Reader) ([]byte, error) {
+	var header [2]byte
+	if _, err := io.ReadFull(r, header[:]); err != nil {
+		return nil, err
+	}
+
+	frame := make([]byte, binary.BigEndian.Uint16(header[:]))
+	if _, err := io.ReadFull(r, frame); err != nil {
+		return nil, err
+	}
+	return frame, nil
+}
+
+// WriteFrame пишет сообщение с двухбайтовым префиксом длины
+func WriteFrame(w io.Writer, data []byte) error {
+	if len(data) > maxFrameSize {
+		return ErrFrameTooLarge
+	}
+
+	frame := make([]byte, 2+len(data))
+	binary.BigEndian.PutUint16(frame, uint16(len(data)))
+	copy(frame[2:], data)
+
+	_, err := w.Write(frame)
+	return err
+}
+
+// FormatAmount переводит сумму в рублях в поле 4 (копейки, 12 цифр)
+func FormatAmount(amount float64) string {
+	return fmt.Sprintf("%012d", int64(amount*100+0.5))
+}
+
+// ParseAmount переводит поле 4 (копейки) в сумму в рублях
+func ParseAmount(value string) (float64, error) {
+	minor, err := strconv.ParseInt(strings.TrimSpace(value), 10, 64)
+	if err != nil || minor < 0 {
+		return 0, fmt.Errorf("%w: amount", ErrInvalidField)
+	}
+	return float64(minor) / 100, nil
+}
+
+func encodeField(field int, spec fieldSpec, value string) (string, error) {
+	if spec.numeric && !isDigits(value) {
+		return "", fmt.Errorf("%w: field %d must be numeric", ErrInvalidField, field)
+	}
+	if len(value) > spec.length {
+		return "", fmt.Errorf("%w: field %d exceeds %d characters", ErrInvalidField, field, spec.length)
+	}
+
+	switch {
+	case spec.prefix > 0:
+		return fmt.Sprintf("%0*d", spec.prefix, len(value)) + value, nil
+	case spec.numeric:
+		return strings.Repeat("0", spec.length-len(value)) + value, nil
+	default:
+		return value + strings.Repeat(" ", spec.length-len(value)), nil
+	}
+}
+
+func decodeField(field int, spec fieldSpec, data []byte, pos int) (string, int, error) {
+	length := spec.length
+	if spec.prefix > 0 {
+		if pos+spec.prefix > len(data) {
+			return "", 0, ErrShortMessage
+		}
+		prefix := string(data[pos : pos+spec.prefix])
+		parsed, err := strconv.Atoi(prefix)
+		if err != nil || !isDigits(prefix) || parsed > spec.length {
+			return "", 0, fmt.Errorf("%w: field %d length", ErrInvalidField, field)
+		}
+		length = parsed
+		pos += spec.prefix
+	}
+
+	if pos+length > len(data) {
+		return "", 0, ErrShortMessage
+	}
+	value := string(data[pos : pos+length])
+	if spec.numeric && !isDigits(value) {
+		return "", 0, fmt.Errorf("%w: field %d must be numeric", ErrInvalidField, field)
+	}
+
+	return value, pos + length, nil
+}
+
+func isMTI(mti string) bool {
+	return len(mti) == 4 && isDigits(mti)
+}
+
+func isDigits(value string) bool {
+	for _, r := range value {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,233 @@
+package iso8583
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+)
+
+// testMessage запрос авторизации со всеми видами полей: числовыми, текстовыми, LLVAR и LLLVAR
+func testMessage() *Message {
+	m := NewMessage(MTIAuthorizationRequest)
+	m.Set(FieldPAN, "4000001234567899")
+	m.Set(FieldAmount, "15000")
+	m.Set(FieldSTAN, "000001")
+	m.Set(FieldRRN, "000000000001")
+	m.Set(FieldTerminalID, "TERM01")
+	m.Set(FieldAdditionalData, "123")
+	return m
+}
+
+func TestPackUnpack_RoundTrip(t *testing.T) {
+	packed, err := Pack(testMessage())
+	if err != nil {
+		t.Fatalf("Pack failed: %v", err)
+	}
+
+	// MTI, первичная карта с полями 2, 4, 11, 37, 41, 48 и поля по порядку
+	want := "0100" + "\x50\x20\x00\x00\x08\x81\x00\x00" +
+		"164000001234567899" + "000000015000" + "000001" + "000000000001" + "TERM01  " + "003123"
+	if string(packed) != want {
+		t.Fatalf("unexpected packed message:\n got %q\nwant %q", packed, want)
+	}
+
+	m, err := Unpack(packed)
+	if err != nil {
+		t.Fatalf("Unpack failed: %v", err)
+	}
+	if m.MTI != MTIAuthorizationRequest || m.Get(FieldAmount) != "000000015000" || m.Get(FieldTerminalID) != "TERM01" ||
+		m.Fields[FieldTerminalID] != "TERM01  " || m.Get(FieldAdditionalData) != "123" {
+		t.Errorf("unexpected unpacked message: %+v", m)
+	}
+
+	repacked, err := Pack(m)
+	if err != nil || !bytes.Equal(repacked, packed) {
+		t.Errorf("expected repacking to reproduce the message, got %q (%v)", repacked, err)
+	}
+}
+
+func TestPackUnpack_SecondaryBitmap(t *testing.T) {
+	m := NewMessage(MTIReversalRequest)
+	m.Set(FieldSTAN, "000002")
+	m.Set(FieldOriginalData, "0100")
+
+	packed, err := Pack(m)
+	if err != nil {
+		t.Fatalf("Pack failed: %v", err)
+	}
+	if len(packed) != 4+16+6+42 || packed[4]&0x80 == 0 {
+		t.Fatalf("expected secondary bitmap, got %q", packed)
+	}
+
+	unpacked, err := Unpack(packed)
+	if err != nil {
+		t.Fatalf("Unpack failed: %v", err)
+	}
+	if unpacked.Fields[FieldOriginalData] != strings.Repeat("0", 38)+"0100" {
+		t.Errorf("unexpected field 90: %q", unpacked.Fields[FieldOriginalData])
+	}
+}
+
+func TestPack_Errors(t *testing.T) {
+	tests := []struct {
+		name  string
+		mti   string
+		field int
+		value string
+		want  error
+	}{
+		{name: "invalid MTI", mti: "01A0", field: FieldSTAN, value: "1", want: ErrInvalidMTI},
+		{name: "short MTI", mti: "100", field: FieldSTAN, value: "1", want: ErrInvalidMTI},
+		{name: "unsupported field", mti: "0100", field: 5, value: "1", want: ErrUnsupportedField},
+		{name: "numeric field with letters", mti: "0100", field: FieldAmount, value: "10a", want: ErrInvalidField},
+		{name: "fixed field too long", mti: "0100", field: FieldTerminalID, value: "TERMINAL1", want: ErrInvalidField},
+		{name: "LLVAR field too long", mti: "0100", field: FieldPAN, value: strings.Repeat("4", 20), want: ErrInvalidField},
+		{name: "LLLVAR field too long", mti: "0100", field: FieldAdditionalData, value: strings.Repeat("x", 1000), want: ErrInvalidField},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := NewMessage(tt.mti)
+			m.Set(tt.field, tt.value)
+			if _, err := Pack(m); !errors.Is(err, tt.want) {
+				t.Errorf("expected %v, got %v", tt.want, err)
+			}
+		})
+	}
+}
+
+func TestUnpack_Errors(t *testing.T) {
+	packed, err := Pack(testMessage())
+	if err != nil {
+		t.Fatalf("Pack failed: %v", err)
+	}
+	withBitmap := func(bitmap ...byte) []byte {
+		return append([]byte("0100"), bitmap...)
+	}
+
+	tests := []struct {
+		name string
+		data []byte
+		want error
+	}{
+		{name: "empty", data: nil, want: ErrShortMessage},
+		{name: "no bitmap", data: []byte("0100"), want: ErrShortMessage},
+		{name: "invalid MTI", data: append([]byte("01X0"), packed[4:]...), want: ErrInvalidMTI},
+		{name: "truncated secondary bitmap", data: withBitmap(0x80, 0, 0, 0, 0, 0, 0, 0, 0x10), want: ErrShortMessage},
+		{name: "unsupported field in bitmap", data: withBitmap(0x08, 0, 0, 0, 0, 0, 0, 0), want: ErrUnsupportedField},
+		{name: "field missing after bitmap", data: withBitmap(0x00, 0x20, 0, 0, 0, 0, 0, 0), want: ErrShortMessage},
+		{name: "truncated fixed field", data: packed[:len(packed)-10], want: ErrShortMessage},
+		{name: "truncated LLLVAR value", data: packed[:len(packed)-1], want: ErrShortMessage},
+		{name: "truncated LLVAR prefix", data: append(withBitmap(0x40, 0, 0, 0, 0, 0, 0, 0), '1'), want: ErrShortMessage},
+		{name: "LLVAR length over limit", data: append(withBitmap(0x40, 0, 0, 0, 0, 0, 0, 0), "20"+strings.Repeat("4", 20)...), want: ErrInvalidField},
+		{name: "LLVAR length not digits", data: append(withBitmap(0x40, 0, 0, 0, 0, 0, 0, 0), "1a4000"...), want: ErrInvalidField},
+		{name: "numeric field with letters", data: append(withBitmap(0x00, 0x20, 0, 0, 0, 0, 0, 0), "00000A"...), want: ErrInvalidField},
+		{name: "trailing bytes", data: append(append([]byte{}, packed...), 'x'), want: ErrInvalidField},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Unpack(tt.data); !errors.Is(err, tt.want) {
+				t.Errorf("expected %v, got %v", tt.want, err)
+			}
+		})
+	}
+}
+
+func TestFrames(t *testing.T) {
+	var buf bytes.Buffer
+	for _, data := range [][]byte{[]byte("first"), {}, bytes.Repeat([]byte("x"), maxFrameSize)} {
+		if err := WriteFrame(&buf, data); err != nil {
+			t.Fatalf("WriteFrame failed for %d bytes: %v", len(data), err)
+		}
+	}
+	if got := buf.Bytes()[:7]; !bytes.Equal(got, []byte("\x00\x05first")) {
+		t.Fatalf("expected big-endian length prefix, got %q", got)
+	}
+
+	for _, want := range []int{5, 0, maxFrameSize} {
+		frame, err := ReadFrame(&buf)
+		if err != nil || len(frame) != want {
+			t.Fatalf("expected frame of %d bytes, got %d (%v)", want, len(frame), err)
+		}
+	}
+	if _, err := ReadFrame(&buf); !errors.Is(err, io.EOF) {
+		t.Errorf("expected io.EOF after last frame, got %v", err)
+	}
+}
+
+func TestWriteFrame_TooLarge(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteFrame(&buf, make([]byte, maxFrameSize+1)); !errors.Is(err, ErrFrameTooLarge) {
+		t.Errorf("expected ErrFrameTooLarge, got %v", err)
+	}
+	if buf.Len() != 0 {
+		t.Errorf("expected nothing written, got %d bytes", buf.Len())
+	}
+}
+
+func TestReadFrame_Truncated(t *testing.T) {
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{name: "partial length prefix", data: []byte{0x00}},
+		{name: "partial frame", data: []byte("\x00\x05abc")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := ReadFrame(bytes.NewReader(tt.data)); !errors.Is(err, io.ErrUnexpectedEOF) {
+				t.Errorf("expected io.ErrUnexpectedEOF, got %v", err)
+			}
+		})
+	}
+}
+
+func TestSignVerify(t *testing.T) {
+	key := []byte("gateway-key")
+
+	m := testMessage()
+	packed, err := Sign(m, key)
+	if err != nil {
+		t.Fatalf("Sign failed: %v", err)
+	}
+	if mac := m.Fields[FieldMAC]; len(mac) != macLength || !bytes.HasSuffix(packed, []byte(mac)) {
+		t.Fatalf("expected MAC in field 128 at the end of the message, got %q", mac)
+	}
+
+	// Получатель проверяет сообщение, разобранное из кадра
+	unpacked, err := Unpack(packed)
+	if err != nil {
+		t.Fatalf("Unpack failed: %v", err)
+	}
+	if err := Verify(unpacked, key); err != nil {
+		t.Errorf("expected valid MAC, got %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		key    []byte
+		modify func(m *Message)
+	}{
+		{name: "wrong key", key: []byte("other-key"), modify: func(m *Message) {}},
+		{name: "amount changed", key: key, modify: func(m *Message) { m.Set(FieldAmount, "000000099999") }},
+		{name: "field added", key: key, modify: func(m *Message) { m.Set(FieldMerchantID, "MERCH1") }},
+		{name: "MAC missing", key: key, modify: func(m *Message) { delete(m.Fields, FieldMAC) }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			forged, err := Unpack(packed)
+			if err != nil {
+				t.Fatalf("Unpack failed: %v", err)
+			}
+			tt.modify(forged)
+			if err := Verify(forged, tt.key); !errors.Is(err, ErrInvalidMAC) {
+				t.Errorf("expected ErrInvalidMAC, got %v", err)
+			}
+		})
+	}
+}
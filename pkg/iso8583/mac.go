@@ -0,0 +1,55 @@
+package iso8583
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"strings"
+)
+
+// macLength длина поля MAC: первые 8 байт HMAC-SHA256 в hex
+const macLength = 16
+
+var ErrInvalidMAC = errors.New("iso8583: invalid MAC")
+
+// Sign записывает в поле 128 MAC сообщения и возвращает упакованное сообщение.
+// MAC считается ключом key по упакованному сообщению без значения поля 128: оно всегда последнее.
+func Sign(m *Message, key []byte) ([]byte, error) {
+	m.Set(FieldMAC, strings.Repeat("0", macLength))
+	packed, err := Pack(m)
+	if err != nil {
+		return nil, err
+	}
+
+	mac := computeMAC(key, packed[:len(packed)-macLength])
+	copy(packed[len(packed)-macLength:], mac)
+	m.Set(FieldMAC, mac)
+	return packed, nil
+}
+
+// Verify проверяет MAC в поле 128. Упаковка однозначна, поэтому сообщение из кадра и из JSON
+// проверяется одинаково.
+func Verify(m *Message, key []byte) error {
+	mac, ok := m.Fields[FieldMAC]
+	if !ok {
+		return ErrInvalidMAC
+	}
+
+	packed, err := Pack(m)
+	if err != nil {
+		return err
+	}
+
+	expected := computeMAC(key, packed[:len(packed)-macLength])
+	if !hmac.Equal([]byte(mac), []byte(expected)) {
+		return ErrInvalidMAC
+	}
+	return nil
+}
+
+func computeMAC(key, data []byte) string {
+	h := hmac.New(sha256.New, key)
+	h.Write(data)
+	return strings.ToUpper(hex.EncodeToString(h.Sum(nil)[:macLength/2]))
+}
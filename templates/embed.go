@@ -0,0 +1,9 @@
+// Package templates содержит шаблоны, встроенные в бинарник
+package templates
+
+import "embed"
+
+// Email шаблоны писем: email/<версия>/<локаль>/<имя>.{subject,txt,html}.tmpl
+//
+//go:embed email
+var Email embed.FS